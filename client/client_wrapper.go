@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/cometbft/cometbft/abci/types"
 	cometcrypto "github.com/cometbft/cometbft/crypto"
@@ -30,16 +31,59 @@ import (
 
 // RPCClient wraps our slimmed down CometBFT client and converts the returned types to the upstream CometBFT types.
 // This is useful so that it can be used in any function calls that expect the upstream types.
+// It may wrap an upstream CometBFT client instead, e.g. one whose requests are customized with TLS settings or
+// auth headers, which the slimmed down client does not support.
+// The wrapped client can be replaced at runtime with SetClient, which applies to every copy of the RPCClient.
 type RPCClient struct {
-	c *client.Client
+	b *atomic.Pointer[backend]
+}
+
+// backend is the client an RPCClient makes requests with, either slim or comet.
+type backend struct {
+	slim  *client.Client
+	comet rpcclient.Client
 }
 
 func NewRPCClient(c *client.Client) RPCClient {
-	return RPCClient{c: c}
+	r := RPCClient{b: new(atomic.Pointer[backend])}
+	r.SetClient(c)
+	return r
+}
+
+// NewCometRPCClient returns an RPCClient which wraps the upstream CometBFT client c. The block results of chains
+// running CometBFT versions older than v0.38 are not decoded correctly by the upstream client.
+func NewCometRPCClient(c rpcclient.Client) RPCClient {
+	r := RPCClient{b: new(atomic.Pointer[backend])}
+	r.b.Store(&backend{comet: c})
+	return r
+}
+
+// SetClient replaces the wrapped client, e.g. to switch to another RPC endpoint.
+// Requests in flight complete on the previous client.
+func (r RPCClient) SetClient(c *client.Client) {
+	r.b.Store(&backend{slim: c})
+}
+
+// SetClientOf replaces the wrapped client with the client wrapped by other, as SetClient does.
+func (r RPCClient) SetClientOf(other RPCClient) {
+	r.b.Store(other.b.Load())
+}
+
+func (r RPCClient) client() *client.Client {
+	return r.b.Load().slim
+}
+
+// comet returns the wrapped upstream CometBFT client, or nil if the slimmed down client is wrapped.
+func (r RPCClient) comet() rpcclient.Client {
+	return r.b.Load().comet
 }
 
 func (r RPCClient) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
-	res, err := r.c.ABCIInfo(ctx)
+	if c := r.comet(); c != nil {
+		return c.ABCIInfo(ctx)
+	}
+
+	res, err := r.client().ABCIInfo(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +104,11 @@ func (r RPCClient) ABCIQuery(
 	path string,
 	data bytes.HexBytes,
 ) (*coretypes.ResultABCIQuery, error) {
-	res, err := r.c.ABCIQuery(ctx, path, slbytes.HexBytes(data))
+	if c := r.comet(); c != nil {
+		return c.ABCIQuery(ctx, path, data)
+	}
+
+	res, err := r.client().ABCIQuery(ctx, path, slbytes.HexBytes(data))
 	if err != nil {
 		return nil, err
 	}
@@ -74,12 +122,16 @@ func (r RPCClient) ABCIQueryWithOptions(
 	data bytes.HexBytes,
 	opts rpcclient.ABCIQueryOptions,
 ) (*coretypes.ResultABCIQuery, error) {
+	if c := r.comet(); c != nil {
+		return c.ABCIQueryWithOptions(ctx, path, data, opts)
+	}
+
 	o := slclient.ABCIQueryOptions{
 		Height: opts.Height,
 		Prove:  opts.Prove,
 	}
 
-	res, err := r.c.ABCIQueryWithOptions(ctx, path, slbytes.HexBytes(data), o)
+	res, err := r.client().ABCIQueryWithOptions(ctx, path, slbytes.HexBytes(data), o)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +140,11 @@ func (r RPCClient) ABCIQueryWithOptions(
 }
 
 func (r RPCClient) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTxCommit, error) {
-	res, err := r.c.BroadcastTxCommit(ctx, types2.Tx(tx))
+	if c := r.comet(); c != nil {
+		return c.BroadcastTxCommit(ctx, tx)
+	}
+
+	res, err := r.client().BroadcastTxCommit(ctx, types2.Tx(tx))
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +176,11 @@ func (r RPCClient) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*coret
 }
 
 func (r RPCClient) BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
-	res, err := r.c.BroadcastTxAsync(ctx, types2.Tx(tx))
+	if c := r.comet(); c != nil {
+		return c.BroadcastTxAsync(ctx, tx)
+	}
+
+	res, err := r.client().BroadcastTxAsync(ctx, types2.Tx(tx))
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +195,11 @@ func (r RPCClient) BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (*corety
 }
 
 func (r RPCClient) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
-	res, err := r.c.BroadcastTxSync(ctx, types2.Tx(tx))
+	if c := r.comet(); c != nil {
+		return c.BroadcastTxSync(ctx, tx)
+	}
+
+	res, err := r.client().BroadcastTxSync(ctx, types2.Tx(tx))
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +218,11 @@ func (r RPCClient) Validators(
 	height *int64,
 	page, perPage *int,
 ) (*coretypes.ResultValidators, error) {
-	res, err := r.c.Validators(ctx, height, page, perPage)
+	if c := r.comet(); c != nil {
+		return c.Validators(ctx, height, page, perPage)
+	}
+
+	res, err := r.client().Validators(ctx, height, page, perPage)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +246,11 @@ func (r RPCClient) Validators(
 }
 
 func (r RPCClient) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
-	res, err := r.c.Status(ctx)
+	if c := r.comet(); c != nil {
+		return c.Status(ctx)
+	}
+
+	res, err := r.client().Status(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +293,11 @@ func (r RPCClient) Status(ctx context.Context) (*coretypes.ResultStatus, error)
 }
 
 func (r RPCClient) Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error) {
-	res, err := r.c.Block(ctx, height)
+	if c := r.comet(); c != nil {
+		return c.Block(ctx, height)
+	}
+
+	res, err := r.client().Block(ctx, height)
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +309,11 @@ func (r RPCClient) Block(ctx context.Context, height *int64) (*coretypes.ResultB
 }
 
 func (r RPCClient) BlockByHash(ctx context.Context, hash []byte) (*coretypes.ResultBlock, error) {
-	res, err := r.c.BlockByHash(ctx, hash)
+	if c := r.comet(); c != nil {
+		return c.BlockByHash(ctx, hash)
+	}
+
+	res, err := r.client().BlockByHash(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +325,11 @@ func (r RPCClient) BlockByHash(ctx context.Context, hash []byte) (*coretypes.Res
 }
 
 func (r RPCClient) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
-	res, err := r.c.BlockResults(ctx, height)
+	if c := r.comet(); c != nil {
+		return c.BlockResults(ctx, height)
+	}
+
+	res, err := r.client().BlockResults(ctx, height)
 	if err != nil {
 		return nil, err
 	}
@@ -278,7 +362,11 @@ func (r RPCClient) BlockchainInfo(
 	ctx context.Context,
 	minHeight, maxHeight int64,
 ) (*coretypes.ResultBlockchainInfo, error) {
-	res, err := r.c.BlockchainInfo(ctx, minHeight, maxHeight)
+	if c := r.comet(); c != nil {
+		return c.BlockchainInfo(ctx, minHeight, maxHeight)
+	}
+
+	res, err := r.client().BlockchainInfo(ctx, minHeight, maxHeight)
 	if err != nil {
 		return nil, err
 	}
@@ -306,7 +394,11 @@ func (r RPCClient) BlockchainInfo(
 }
 
 func (r RPCClient) Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error) {
-	res, err := r.c.Commit(ctx, height)
+	if c := r.comet(); c != nil {
+		return c.Commit(ctx, height)
+	}
+
+	res, err := r.client().Commit(ctx, height)
 	if err != nil {
 		return nil, err
 	}
@@ -337,7 +429,11 @@ func (r RPCClient) Commit(ctx context.Context, height *int64) (*coretypes.Result
 }
 
 func (r RPCClient) Tx(ctx context.Context, hash []byte, prove bool) (*coretypes.ResultTx, error) {
-	res, err := r.c.Tx(ctx, hash, prove)
+	if c := r.comet(); c != nil {
+		return c.Tx(ctx, hash, prove)
+	}
+
+	res, err := r.client().Tx(ctx, hash, prove)
 	if err != nil {
 		return nil, err
 	}
@@ -352,7 +448,11 @@ func (r RPCClient) TxSearch(
 	page, perPage *int,
 	orderBy string,
 ) (*coretypes.ResultTxSearch, error) {
-	res, err := r.c.TxSearch(ctx, query, prove, page, perPage, orderBy)
+	if c := r.comet(); c != nil {
+		return c.TxSearch(ctx, query, prove, page, perPage, orderBy)
+	}
+
+	res, err := r.client().TxSearch(ctx, query, prove, page, perPage, orderBy)
 	if err != nil {
 		return nil, err
 	}
@@ -374,7 +474,11 @@ func (r RPCClient) BlockSearch(
 	page, perPage *int,
 	orderBy string,
 ) (*coretypes.ResultBlockSearch, error) {
-	res, err := r.c.BlockSearch(ctx, query, page, perPage, orderBy)
+	if c := r.comet(); c != nil {
+		return c.BlockSearch(ctx, query, page, perPage, orderBy)
+	}
+
+	res, err := r.client().BlockSearch(ctx, query, page, perPage, orderBy)
 	if err != nil {
 		return nil, err
 	}
@@ -9,9 +9,17 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"time"
 
 	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/audit"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/dedup"
+	"github.com/cosmos/relayer/v2/relayer/gossip"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
 	"github.com/gofrs/flock"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
@@ -29,6 +37,19 @@ type appState struct {
 	homePath string
 	debug    bool
 	config   *Config
+
+	// debugLogging enables the debug logs of chains and paths at runtime, through the control API.
+	debugLogging *relayer.DebugLogging
+
+	// the references to secrets of the config file, which are written back in place of the secrets.
+	configSecrets []secretRef
+
+	// the audit log shared by the chain providers of every load of the config, and its config.
+	auditLog       *audit.Log
+	auditLogConfig audit.Config
+
+	// the accounting database shared by the chain providers recording their txs.
+	accountingStore *accounting.Store
 }
 
 func (a *appState) initLogger(configLogLevel string) error {
@@ -38,7 +59,7 @@ func (a *appState) initLogger(configLogLevel string) error {
 	} else if logLevel == "" {
 		logLevel = configLogLevel
 	}
-	log, err := newRootLogger(a.viper.GetString("log-format"), logLevel)
+	log, err := newRootLogger(a.viper.GetString("log-format"), logLevel, a.debugLogging)
 	if err != nil {
 		return err
 	}
@@ -51,6 +72,68 @@ func (a *appState) configPath() string {
 	return path.Join(a.homePath, "config", "config.yaml")
 }
 
+func (a *appState) accountingDBPath() string {
+	return path.Join(a.homePath, accounting.DefaultDBFile)
+}
+
+func (a *appState) indexDBPath() string {
+	return path.Join(a.homePath, indexer.DefaultDBFile)
+}
+
+func (a *appState) dedupDBPath() string {
+	return path.Join(a.homePath, dedup.DefaultDBFile)
+}
+
+func (a *appState) gossipKeyPath() string {
+	return path.Join(a.homePath, gossip.DefaultKeyFile)
+}
+
+// openAuditLog returns the audit log configured by cfg. It is opened once, rather than on every load of the config,
+// unless its config changed.
+func (a *appState) openAuditLog(cfg audit.Config) (*audit.Log, error) {
+	if a.auditLog != nil && a.auditLogConfig == cfg {
+		return a.auditLog, nil
+	}
+
+	auditLog, err := audit.Open(a.homePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if a.auditLog != nil {
+		_ = a.auditLog.Close()
+	}
+	a.auditLog, a.auditLogConfig = auditLog, cfg
+	return auditLog, nil
+}
+
+// openAccountingStore returns the accounting database, which is opened once for every chain provider.
+func (a *appState) openAccountingStore() (*accounting.Store, error) {
+	if a.accountingStore != nil {
+		return a.accountingStore, nil
+	}
+
+	store, err := accounting.OpenStore(a.accountingDBPath())
+	if err != nil {
+		return nil, err
+	}
+	a.accountingStore = store
+	return store, nil
+}
+
+// recordSpend records the txs broadcast on the chains to the accounting database.
+func (a *appState) recordSpend(chains relayer.Chains) error {
+	store, err := a.openAccountingStore()
+	if err != nil {
+		return err
+	}
+	for _, chain := range chains {
+		if cp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+			cp.SetTxRecorder(store)
+		}
+	}
+	return nil
+}
+
 // loadConfigFile reads config file into a.Config if file is present.
 func (a *appState) loadConfigFile(ctx context.Context) error {
 	cfgPath := a.configPath()
@@ -69,17 +152,38 @@ func (a *appState) loadConfigFile(ctx context.Context) error {
 		return fmt.Errorf("error reading file: %w", err)
 	}
 
+	// migrate configs of older versions in memory, until they are migrated on disk.
+	file, version, err := migrateConfig(file)
+	if err != nil {
+		return configFileError(cfgPath, err)
+	}
+
+	// resolve the secrets referenced by the config file, e.g. from environment variables or vault.
+	file, secrets, err := resolveConfigSecrets(ctx, file)
+	if err != nil {
+		return err
+	}
+
 	// unmarshall them into the wrapper struct
 	cfgWrapper := &ConfigInputWrapper{}
 	err = yaml.Unmarshal(file, cfgWrapper)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling config: %w", err)
+		return configFileError(cfgPath, fmt.Errorf("error unmarshalling config: %w", err))
 	}
 
 	if a.log == nil {
 		a.initLogger(cfgWrapper.Global.LogLevel)
 	}
 
+	if version < configVersion {
+		a.log.Warn(
+			fmt.Sprintf("Config file is of an older version, run '%s config migrate' to migrate it", appName),
+			zap.String("config_file", cfgPath),
+			zap.Int("version", version),
+			zap.Int("latest_version", configVersion),
+		)
+	}
+
 	// retrieve the runtime configuration from the disk configuration.
 	newCfg, err := cfgWrapper.RuntimeConfig(ctx, a)
 	if err != nil {
@@ -93,10 +197,17 @@ func (a *appState) loadConfigFile(ctx context.Context) error {
 
 	// save runtime configuration in app state
 	a.config = newCfg
+	a.configSecrets = secrets
 
 	return nil
 }
 
+// wrappedConfig returns the config to write to disk or show, with the references to secrets
+// of the config file in place of the secrets.
+func (a *appState) wrappedConfig() (any, error) {
+	return withSecretRefs(a.config.Wrapped(), a.configSecrets)
+}
+
 // addPathFromFile modifies a.config.Paths to include the content stored in the given file.
 // If a non-nil error is returned, a.config.Paths is not modified.
 func (a *appState) addPathFromFile(ctx context.Context, stderr io.Writer, file, name string) error {
@@ -196,20 +307,30 @@ func (a *appState) addPathFromUserInput(
 	return a.config.AddPath(name, path)
 }
 
-func (a *appState) performConfigLockingOperation(ctx context.Context, operation func() error) error {
+// lockConfig acquires the lock of the config file, which must be held to write the config file,
+// and returns a function releasing it.
+func (a *appState) lockConfig() (func(), error) {
 	lockFilePath := path.Join(a.homePath, "config", "config.lock")
 	fileLock := flock.New(lockFilePath)
 	_, err := fileLock.TryLock()
 	if err != nil {
-		return fmt.Errorf("failed to acquire config lock: %w", err)
+		return nil, fmt.Errorf("failed to acquire config lock: %w", err)
 	}
-	defer func() {
+	return func() {
 		if err := fileLock.Unlock(); err != nil {
 			a.log.Error("error unlocking config file lock, please manually delete",
 				zap.String("filepath", lockFilePath),
 			)
 		}
-	}()
+	}, nil
+}
+
+func (a *appState) performConfigLockingOperation(ctx context.Context, operation func() error) error {
+	unlock, err := a.lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// load config from file and validate it. don't want to miss
 	// any changes that may have been made while unlocked.
@@ -228,19 +349,33 @@ func (a *appState) performConfigLockingOperation(ctx context.Context, operation
 	}
 
 	// marshal the new config
-	out, err := yaml.Marshal(a.config.Wrapped())
+	wrapped, err := a.wrappedConfig()
 	if err != nil {
 		return err
 	}
-
-	cfgPath := a.configPath()
-
-	// Overwrite the config file.
-	if err := os.WriteFile(cfgPath, out, 0600); err != nil {
-		return fmt.Errorf("failed to write config file at %s: %w", cfgPath, err)
+	out, err := yaml.Marshal(wrapped)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	// Overwrite the config file, backing up the previous one.
+	return writeConfigFile(a.configPath(), out)
+}
+
+// service returns a relayer.Service for the chains and paths of the config,
+// which persists the identifiers it sets on paths to the config file.
+func (a *appState) service(cmd *cobra.Command) *relayer.Service {
+	return relayer.NewService(relayer.ServiceConfig{
+		Log:             a.log,
+		Chains:          a.config.Chains,
+		Paths:           a.config.Paths,
+		Memo:            a.config.memo(cmd),
+		MaxReceiverSize: a.config.Global.MaxReceiverSize,
+		ICS20MemoLimit:  a.config.Global.ICS20MemoLimit,
+		OnPathUpdated: func(ctx context.Context, name string, p *relayer.Path) error {
+			return a.updatePathConfig(ctx, name, p.Src.ClientID, p.Dst.ClientID, p.Src.ConnectionID, p.Dst.ConnectionID)
+		},
+	})
 }
 
 // updatePathConfig overwrites the config file concurrently,
@@ -276,6 +411,77 @@ func (a *appState) updatePathConfig(
 	})
 }
 
+// replacePathEnds overwrites the path ends of a path in the config file, recording the previous
+// identifiers in the path history for audit purposes. Channels in the path's channel filter are
+// replaced by their counterparts in channels, if present.
+func (a *appState) replacePathEnds(
+	ctx context.Context,
+	pathName string,
+	src, dst relayer.PathEnd,
+	channels map[string]string,
+	reason string,
+) error {
+	if pathName == "" {
+		return errors.New("empty path name not allowed")
+	}
+
+	return a.performConfigLockingOperation(ctx, func() error {
+		path, ok := a.config.Paths[pathName]
+		if !ok {
+			return fmt.Errorf("config does not exist for that path: %s", pathName)
+		}
+
+		path.History = append(path.History, relayer.PathRecord{
+			Src:         *path.Src,
+			Dst:         *path.Dst,
+			ChannelList: path.Filter.ChannelList,
+			ReplacedAt:  time.Now().UTC(),
+			Reason:      reason,
+		})
+
+		setPathEnds(path, src, dst, channels)
+		return nil
+	})
+}
+
+// updatePathEnds sets the path ends of the path, and replaces the channels in its channel filter by their new
+// counterparts in channels, without recording the previous path ends in its history. It is used to persist each
+// step of a replacement already recorded by replacePathEnds.
+func (a *appState) updatePathEnds(
+	ctx context.Context,
+	pathName string,
+	src, dst relayer.PathEnd,
+	channels map[string]string,
+) error {
+	if pathName == "" {
+		return errors.New("empty path name not allowed")
+	}
+
+	return a.performConfigLockingOperation(ctx, func() error {
+		path, ok := a.config.Paths[pathName]
+		if !ok {
+			return fmt.Errorf("config does not exist for that path: %s", pathName)
+		}
+
+		setPathEnds(path, src, dst, channels)
+		return nil
+	})
+}
+
+func setPathEnds(path *relayer.Path, src, dst relayer.PathEnd, channels map[string]string) {
+	path.Src = &src
+	path.Dst = &dst
+
+	channelList := make([]string, len(path.Filter.ChannelList))
+	for i, channelID := range path.Filter.ChannelList {
+		if newChannelID, ok := channels[channelID]; ok {
+			channelID = newChannelID
+		}
+		channelList[i] = channelID
+	}
+	path.Filter.ChannelList = channelList
+}
+
 func (a *appState) useKey(chainName, key string) error {
 
 	chain, exists := a.config.Chains[chainName]
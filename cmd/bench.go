@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/spf13/cobra"
+)
+
+func benchCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark relaying between two local chains and report throughput, latency and queries",
+		Long: strings.TrimSpace(fmt.Sprintf(`Start two in-memory chains, link them with new clients, a connection and a
+transfer channel, then run the relayer while sending --%s transfers, --%s per tx every --%s.
+Once the acknowledgements of the transfers are relayed back, or after --%s, print a JSON report
+of the throughput in packets per minute, the average and max latency from sending a packet to
+relaying its acknowledgement, and the queries of the relayer to each chain, in total and per packet.
+No configuration, keys or network access is needed, which makes runs comparable across versions.`,
+			flagPackets, flagBatchSize, flagInterval, flagMaxDuration,
+		)),
+		Args: withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s bench
+$ %s bench --packets 2000 --batch-size 50 --interval 50ms
+$ %s bench --block-time 1s --max-msgs 10`,
+			appName, appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				opts relayer.BenchOptions
+				err  error
+			)
+			if opts.Packets, err = cmd.Flags().GetInt(flagPackets); err != nil {
+				return err
+			}
+			if opts.BatchSize, err = cmd.Flags().GetInt(flagBatchSize); err != nil {
+				return err
+			}
+			if opts.Interval, err = cmd.Flags().GetDuration(flagInterval); err != nil {
+				return err
+			}
+			if opts.BlockTime, err = cmd.Flags().GetDuration(flagBlockTime); err != nil {
+				return err
+			}
+			if opts.MaxDuration, err = cmd.Flags().GetDuration(flagMaxDuration); err != nil {
+				return err
+			}
+			if opts.MaxMsgLength, err = cmd.Flags().GetUint64(flagMaxMsgLength); err != nil {
+				return err
+			}
+
+			res, err := relayer.Bench(cmd.Context(), a.log, opts)
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+
+			if res.Relayed < res.Packets {
+				return fmt.Errorf("relayed %d of %d packets within %s", res.Relayed, res.Packets, opts.MaxDuration)
+			}
+			return nil
+		},
+	}
+
+	cmd = strategyFlag(a.viper, cmd)
+	cmd = benchFlags(a.viper, cmd)
+	return cmd
+}
@@ -41,6 +41,7 @@ func chainsCmd(a *appState) *cobra.Command {
 		chainsAddDirCmd(a),
 		cmdChainsConfigure(a),
 		cmdChainsUseRpcAddr(a),
+		chainsRenameIDCmd(a),
 	)
 
 	return cmd
@@ -116,12 +117,24 @@ $ %s ch s ibc-0 --yaml`, appName, appName, appName, appName)),
 			if err != nil {
 				return err
 			}
+
+			// print the references to secrets of the chain config rather than the secrets.
+			var pcfgw any = &ProviderConfigWrapper{
+				Type:  c.ChainProvider.Type(),
+				Value: c.ChainProvider.ProviderConfig(),
+			}
+			wrapped, err := a.wrappedConfig()
+			if err != nil {
+				return err
+			}
+			if withRefs, ok := wrapped.(map[string]any); ok {
+				if chains, ok := withRefs["chains"].(map[string]any); ok {
+					pcfgw = chains[args[0]]
+				}
+			}
+
 			switch {
 			case jsn:
-				pcfgw := &ProviderConfigWrapper{
-					Type:  c.ChainProvider.Type(),
-					Value: c.ChainProvider.ProviderConfig(),
-				}
 				out, err := json.Marshal(pcfgw)
 				if err != nil {
 					return err
@@ -129,10 +142,6 @@ $ %s ch s ibc-0 --yaml`, appName, appName, appName, appName)),
 				fmt.Fprintln(cmd.OutOrStdout(), string(out))
 				return nil
 			default:
-				pcfgw := &ProviderConfigWrapper{
-					Type:  c.ChainProvider.Type(),
-					Value: c.ChainProvider.ProviderConfig(),
-				}
 				out, err := yaml.Marshal(pcfgw)
 				if err != nil {
 					return err
@@ -177,6 +186,7 @@ func cmdChainsConfigure(a *appState) *cobra.Command {
 
 	cmd.AddCommand(
 		feegrantConfigureBaseCmd(a),
+		feeGranterConfigureCmd(a),
 	)
 
 	return cmd
@@ -252,11 +262,20 @@ $ %s ch l`, appName, appName)),
 				return err
 			}
 
-			configs := a.config.Wrapped().ProviderConfigs
-			if len(configs) == 0 {
+			if len(a.config.Chains) == 0 {
 				fmt.Fprintln(cmd.ErrOrStderr(), "warning: no chains found (do you need to run 'rly chains add'?)")
 			}
 
+			// print the references to secrets of the chain configs rather than the secrets.
+			var configs any = a.config.Wrapped().ProviderConfigs
+			wrapped, err := a.wrappedConfig()
+			if err != nil {
+				return err
+			}
+			if withRefs, ok := wrapped.(map[string]any); ok {
+				configs = withRefs["chains"]
+			}
+
 			switch {
 			case yml && jsn:
 				return fmt.Errorf("can't pass both --json and --yaml, must pick one")
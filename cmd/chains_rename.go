@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// Command for renaming the chain-id of a chain which relaunched with a new chain-id
+func chainsRenameIDCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename-id old_chain_id new_chain_id",
+		Short: "Renames the chain-id of a chain after it relaunched with a new chain-id",
+		Long: strings.TrimSpace(fmt.Sprintf(`Rename the chain-id of a configured chain after it relaunched with a new chain-id, e.g. to recover
+from a halt or a fork. The chain-id is renamed in the config of the chain and in the path ends and history of its
+paths, the keys of the chain are moved to the key directory of the new chain-id, and the transactions and packet
+events recorded for the chain by %s are attributed to the new chain-id. Client trust options are set per path,
+so they carry over as they are.
+
+The clients of the paths of the chain are then re-validated against the chain. Counterparty clients which still
+track the old chain-id must be upgraded, e.g. with '%s tx upgrade-clients', or recovered through governance
+before packets can be relayed again.`, appName, appName)),
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s chains rename-id ibc-0 ibc-1
+$ %s ch rename-id stride-1 stride-2`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldChainID, newChainID := args[0], args[1]
+			if oldChainID == newChainID {
+				return fmt.Errorf("old and new chain-id are both %s", oldChainID)
+			}
+			ctx := cmd.Context()
+			stderr := cmd.ErrOrStderr()
+
+			oldKeysDir, newKeysDir := a.keysDir(oldChainID), a.keysDir(newChainID)
+			if _, err := os.Stat(newKeysDir); err == nil {
+				return fmt.Errorf("key directory %s of chain-id %s already exists", newKeysDir, newChainID)
+			}
+
+			paths, err := a.renameChainID(ctx, oldChainID, newChainID)
+			if err != nil {
+				return err
+			}
+
+			if err := a.renameChainState(ctx, oldChainID, newChainID); err != nil {
+				return fmt.Errorf("renamed chain-id %s to %s in the config, but failed to rename its state: %w",
+					oldChainID, newChainID, err)
+			}
+			if err := os.Rename(oldKeysDir, newKeysDir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("renamed chain-id %s to %s in the config, but failed to move its keys from %s to %s: %w",
+					oldChainID, newChainID, oldKeysDir, newKeysDir, err)
+			}
+			fmt.Fprintf(stderr, "Renamed chain-id %s to %s\n", oldChainID, newChainID)
+
+			// reload the config, so that the chain is initialized with its new chain-id and key directory.
+			if err := a.loadConfigFile(ctx); err != nil {
+				return err
+			}
+			a.validateRenamedChain(ctx, stderr, newChainID, paths)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// keysDir returns the directory of the keys of the chain with chainID, as chain providers derive it.
+func (a *appState) keysDir(chainID string) string {
+	return path.Join(a.homePath, "keys", chainID)
+}
+
+// renameChainID renames the chain-id of the chain with oldChainID to newChainID in the config file, along with
+// the path ends and history of the paths of the chain. It returns the names of the paths of the chain.
+func (a *appState) renameChainID(ctx context.Context, oldChainID, newChainID string) ([]string, error) {
+	var paths []string
+	err := a.performConfigLockingOperation(ctx, func() error {
+		if _, err := a.config.Chains.Get(newChainID); err == nil {
+			return fmt.Errorf("chain with ID %s is already configured", newChainID)
+		}
+		var chainName string
+		for name, chain := range a.config.Chains {
+			if chain.ChainProvider.ChainId() == oldChainID {
+				chainName = name
+			}
+		}
+		if chainName == "" {
+			return fmt.Errorf("chain with ID %s is not configured", oldChainID)
+		}
+		for _, ref := range a.configSecrets {
+			// chains configured by reference as a whole are never written to the config file.
+			if isChainConfigPath(ref.path) && ref.path[1] == chainName {
+				return fmt.Errorf("chain %s is configured by the reference %s, rename its chain-id there", chainName, ref.ref)
+			}
+		}
+
+		chain := a.config.Chains[chainName]
+		if err := chain.ChainProvider.SetChainId(newChainID); err != nil {
+			return err
+		}
+		if pcfg, ok := chain.ChainProvider.ProviderConfig().(cosmos.CosmosProviderConfig); ok &&
+			pcfg.KeyringBackend != keyring.BackendTest && pcfg.KeyringBackend != keyring.BackendFile {
+			a.log.Warn(
+				"Keys of the keyring backend are stored by chain-id, restore them for the new chain-id",
+				zap.String("chain_name", chainName),
+				zap.String("chain_id", newChainID),
+				zap.String("keyring_backend", pcfg.KeyringBackend),
+			)
+		}
+
+		for name, p := range a.config.Paths {
+			if renamePathEnd(p.Src, oldChainID, newChainID) || renamePathEnd(p.Dst, oldChainID, newChainID) {
+				paths = append(paths, name)
+			}
+			for i := range p.History {
+				renamePathEnd(&p.History[i].Src, oldChainID, newChainID)
+				renamePathEnd(&p.History[i].Dst, oldChainID, newChainID)
+			}
+		}
+		sort.Strings(paths)
+		return nil
+	})
+	return paths, err
+}
+
+// renamePathEnd renames the chain-id of the path end, and returns true if it was that of the renamed chain.
+func renamePathEnd(pe *relayer.PathEnd, oldChainID, newChainID string) bool {
+	if pe.ChainID != oldChainID {
+		return false
+	}
+	pe.ChainID = newChainID
+	return true
+}
+
+// renameChainState attributes the transactions and packet events recorded for the chain with oldChainID
+// to newChainID. Databases which do not exist are not created.
+func (a *appState) renameChainState(ctx context.Context, oldChainID, newChainID string) error {
+	if _, err := os.Stat(a.accountingDBPath()); err == nil {
+		store, err := accounting.OpenStore(a.accountingDBPath())
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if err := store.RenameChain(ctx, oldChainID, newChainID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(a.indexDBPath()); err == nil {
+		index, err := indexer.OpenStore(a.indexDBPath())
+		if err != nil {
+			return err
+		}
+		defer index.Close()
+		if err := index.RenameChain(ctx, oldChainID, newChainID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRenamedChain re-validates the clients of the paths of the chain, renamed to chainID, against the chain.
+// It warns of path ends which fail validation on the chain, and of counterparty clients which still track another
+// chain-id, since packets can't be relayed on them until they are upgraded or recovered.
+func (a *appState) validateRenamedChain(ctx context.Context, stderr io.Writer, chainID string, paths []string) {
+	for _, name := range paths {
+		p := a.config.Paths[name]
+		pe, counterparty := p.Src, p.Dst
+		if pe.ChainID != chainID {
+			pe, counterparty = p.Dst, p.Src
+		}
+
+		if err := a.config.ValidatePathEnd(ctx, stderr, pe); err != nil {
+			fmt.Fprintf(stderr, "Path %s: client %s of chain %s failed validation: %v\n", name, pe.ClientID, chainID, err)
+		}
+
+		if counterparty.ClientID == "" {
+			continue
+		}
+		c, err := a.config.Chains.Get(counterparty.ChainID)
+		if err != nil {
+			fmt.Fprintf(stderr, "Chain %s is not currently configured.\n", counterparty.ChainID)
+			continue
+		}
+		clientChainID, err := trackedChainID(ctx, c, counterparty.ClientID)
+		if err != nil {
+			fmt.Fprintf(stderr, "Path %s: failed to query client %s on chain %s: %v\n", name, counterparty.ClientID, counterparty.ChainID, err)
+			continue
+		}
+		if clientChainID != chainID {
+			fmt.Fprintf(stderr, "Path %s: client %s on chain %s tracks chain-id %s rather than %s, "+
+				"upgrade it with '%s tx upgrade-clients %s %s' or recover it through governance\n",
+				name, counterparty.ClientID, counterparty.ChainID, clientChainID, chainID, appName, name, counterparty.ChainID)
+			continue
+		}
+		fmt.Fprintf(stderr, "Path %s: client %s on chain %s tracks chain-id %s\n", name, counterparty.ClientID, counterparty.ChainID, chainID)
+	}
+}
+
+// trackedChainID returns the chain-id of the chain tracked by the client with clientID on chain c.
+func trackedChainID(ctx context.Context, c *relayer.Chain, clientID string) (string, error) {
+	height, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.ChainProvider.QueryClientStateResponse(ctx, height, clientID)
+	if err != nil {
+		return "", err
+	}
+	info, err := relayer.ClientInfoFromClientState(res.ClientState)
+	if err != nil {
+		return "", err
+	}
+	return info.ChainID, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/cosmos/relayer/v2/internal/relayertest"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestChainsList_Empty(t *testing.T) {
@@ -121,3 +122,43 @@ func TestChainsAdd_Delete(t *testing.T) {
 	require.Empty(t, res.Stdout.String())
 	require.Contains(t, res.Stderr.String(), "no chains found")
 }
+
+func TestChainsRenameID(t *testing.T) {
+	t.Parallel()
+
+	sys := relayertest.NewSystem(t)
+
+	_ = sys.MustRun(t, "config", "init")
+
+	slip44 := 118
+	for _, chainID := range []string{"testcosmos-1", "othercosmos"} {
+		sys.MustAddChain(t, chainID, cmd.ProviderConfigWrapper{
+			Type: "cosmos",
+			Value: cosmos.CosmosProviderConfig{
+				AccountPrefix:  "cosmos",
+				ChainID:        chainID,
+				KeyringBackend: "test",
+				Timeout:        "10s",
+				Slip44:         &slip44,
+			},
+		})
+	}
+	_ = sys.MustRun(t, "keys", "restore", "testcosmos-1", "default", relayertest.ZeroMnemonic)
+	_ = sys.MustRun(t, "paths", "new", "testcosmos-1", "othercosmos", "demo-path")
+
+	res := sys.MustRun(t, "chains", "rename-id", "testcosmos-1", "testcosmos-2")
+	require.Contains(t, res.Stderr.String(), "Renamed chain-id testcosmos-1 to testcosmos-2")
+
+	// the chain keeps its name, and its paths and keys follow the new chain-id.
+	cfg := sys.MustGetConfig(t)
+	pcfg := cfg.ProviderConfigs["testcosmos-1"].Value.(*cosmos.CosmosProviderConfig)
+	require.Equal(t, "testcosmos-2", pcfg.ChainID)
+	require.Equal(t, "testcosmos-2", cfg.Paths["demo-path"].Src.ChainID)
+	require.Equal(t, "othercosmos", cfg.Paths["demo-path"].Dst.ChainID)
+
+	res = sys.MustRun(t, "keys", "list", "testcosmos-1")
+	require.Equal(t, "key(default) -> "+relayertest.ZeroCosmosAddr+"\n", res.Stdout.String())
+
+	res = sys.Run(zaptest.NewLogger(t), "chains", "rename-id", "testcosmos-2", "othercosmos")
+	require.ErrorContains(t, res.Err, "chain with ID othercosmos is already configured")
+}
@@ -30,9 +30,13 @@ import (
 	"strings"
 	"time"
 
+	cmtmath "github.com/cometbft/cometbft/libs/math"
 	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/audit"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	"github.com/cosmos/relayer/v2/relayer/chains/penumbra"
+	"github.com/cosmos/relayer/v2/relayer/metrics"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -49,6 +53,8 @@ func configCmd(a *appState) *cobra.Command {
 	cmd.AddCommand(
 		configShowCmd(a),
 		configInitCmd(a),
+		configMigrateCmd(a),
+		configRestoreCmd(a),
 	)
 	return cmd
 }
@@ -85,18 +91,22 @@ $ %s cfg list`, appName, defaultHome, appName)),
 			if err != nil {
 				return err
 			}
+			wrapped, err := a.wrappedConfig()
+			if err != nil {
+				return err
+			}
 			switch {
 			case yml && jsn:
 				return fmt.Errorf("can't pass both --json and --yaml, must pick one")
 			case jsn:
-				out, err := json.Marshal(a.config.Wrapped())
+				out, err := json.Marshal(wrapped)
 				if err != nil {
 					return err
 				}
 				fmt.Fprintln(cmd.OutOrStdout(), string(out))
 				return nil
 			default:
-				out, err := yaml.Marshal(a.config.Wrapped())
+				out, err := yaml.Marshal(wrapped)
 				if err != nil {
 					return err
 				}
@@ -145,22 +155,10 @@ $ %s cfg i`, appName, defaultHome, appName)),
 					}
 				}
 
-				// Then create the file...
-				f, err := os.Create(cfgPath)
-				if err != nil {
-					return err
-				}
-				defer f.Close()
-
 				memo, _ := cmd.Flags().GetString(flagMemo)
 
-				// And write the default config to that location...
-				if _, err = f.Write(defaultConfigYAML(memo)); err != nil {
-					return err
-				}
-
-				// And return no error...
-				return nil
+				// Then write the default config to that location...
+				return writeFileAtomic(cfgPath, defaultConfigYAML(memo))
 			}
 
 			// Otherwise, the config file exists, and an error is returned...
@@ -279,7 +277,7 @@ func (c *Config) Wrapped() *ConfigOutputWrapper {
 		}
 		providers[chain.ChainProvider.ChainName()] = pcfgw
 	}
-	return &ConfigOutputWrapper{Global: c.Global, ProviderConfigs: providers, Paths: c.Paths}
+	return &ConfigOutputWrapper{Version: configVersion, Global: c.Global, ProviderConfigs: providers, Paths: c.Paths, Routes: c.Routes}
 }
 
 // rlyMemo returns a formatted message memo string
@@ -308,25 +306,117 @@ func (c *Config) memo(cmd *cobra.Command) string {
 	return rlyMemo(c.Global.Memo)
 }
 
+// retryPolicy returns the retry policy of the path, with the handshake retries
+// and handshake tx timeout overridden by the max-retries and timeout flags when they are set.
+func (c *Config) retryPolicy(cmd *cobra.Command, pathName string) (*relayer.RetryPolicy, error) {
+	var retryPolicy relayer.RetryPolicy
+	if pth, err := c.Paths.Get(pathName); err == nil && pth.RetryPolicy != nil {
+		retryPolicy = *pth.RetryPolicy
+	}
+
+	if cmd.Flags().Changed(flagMaxRetries) {
+		retries, err := cmd.Flags().GetUint64(flagMaxRetries)
+		if err != nil {
+			return nil, err
+		}
+		retryPolicy.HandshakeRetries = retries
+	}
+
+	if cmd.Flags().Changed(flagTimeout) {
+		timeout, err := getTimeout(cmd)
+		if err != nil {
+			return nil, err
+		}
+		retryPolicy.HandshakeTxTimeout = timeout.String()
+	}
+
+	if err := retryPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid retry policy for path %s: %w", pathName, err)
+	}
+	return &retryPolicy, nil
+}
+
+// clientTrustOptions returns the trusting period, trusting period percentage and max clock drift
+// for the clients of a path, which are the client-trust settings of the path overridden by the flags set on cmd.
+func (c *Config) clientTrustOptions(cmd *cobra.Command, pathName string) (time.Duration, int64, time.Duration, error) {
+	trustingPeriod, err := cmd.Flags().GetDuration(flagClientTrustingPeriod)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	percentage, err := cmd.Flags().GetInt64(flagClientTrustingPeriodPercentage)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	maxClockDrift, err := cmd.Flags().GetDuration(flagMaxClockDrift)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	pth, err := c.Paths.Get(pathName)
+	if err != nil || pth.ClientTrust == nil {
+		return trustingPeriod, percentage, maxClockDrift, nil
+	}
+
+	pathTrustingPeriod, pathMaxClockDrift, err := pth.ClientTrust.Durations()
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid client trust options for path %s: %w", pathName, err)
+	}
+
+	if pathTrustingPeriod != 0 && !cmd.Flags().Changed(flagClientTrustingPeriod) {
+		trustingPeriod = pathTrustingPeriod
+	}
+	if pth.ClientTrust.TrustingPeriodPercentage != 0 && !cmd.Flags().Changed(flagClientTrustingPeriodPercentage) {
+		percentage = pth.ClientTrust.TrustingPeriodPercentage
+	}
+	if pathMaxClockDrift != 0 && !cmd.Flags().Changed(flagMaxClockDrift) {
+		maxClockDrift = pathMaxClockDrift
+	}
+
+	return trustingPeriod, percentage, maxClockDrift, nil
+}
+
+// clientTrustLevel returns the trust level for the clients of a path, which is the trust-level of the client-trust
+// settings of the path overridden by the flag set on cmd.
+func (c *Config) clientTrustLevel(cmd *cobra.Command, pathName string) (cmtmath.Fraction, error) {
+	trustLevel, err := cmd.Flags().GetString(flagTrustLevel)
+	if err != nil {
+		return cmtmath.Fraction{}, err
+	}
+
+	if pth, err := c.Paths.Get(pathName); err == nil && pth.ClientTrust != nil &&
+		pth.ClientTrust.TrustLevel != "" && !cmd.Flags().Changed(flagTrustLevel) {
+		trustLevel = pth.ClientTrust.TrustLevel
+	}
+
+	return relayer.ParseTrustLevel(trustLevel)
+}
+
 // Config represents the config file for the relayer
 type Config struct {
 	Global GlobalConfig   `yaml:"global" json:"global"`
 	Chains relayer.Chains `yaml:"chains" json:"chains"`
 	Paths  relayer.Paths  `yaml:"paths" json:"paths"`
+	Routes relayer.Routes `yaml:"routes,omitempty" json:"routes,omitempty"`
 }
 
 // ConfigOutputWrapper is an intermediary type for writing the config to disk and stdout
 type ConfigOutputWrapper struct {
+	Version         int             `yaml:"version" json:"version"`
 	Global          GlobalConfig    `yaml:"global" json:"global"`
 	ProviderConfigs ProviderConfigs `yaml:"chains" json:"chains"`
 	Paths           relayer.Paths   `yaml:"paths" json:"paths"`
+	Routes          relayer.Routes  `yaml:"routes,omitempty" json:"routes,omitempty"`
 }
 
 // ConfigInputWrapper is an intermediary type for parsing the config.yaml file
 type ConfigInputWrapper struct {
+	Version         int                                   `yaml:"version"`
 	Global          GlobalConfig                          `yaml:"global"`
 	ProviderConfigs map[string]*ProviderConfigYAMLWrapper `yaml:"chains"`
 	Paths           relayer.Paths                         `yaml:"paths"`
+	Routes          relayer.Routes                        `yaml:"routes"`
 }
 
 // RuntimeConfig converts the input disk config into the relayer runtime config.
@@ -350,10 +440,41 @@ func (c *ConfigInputWrapper) RuntimeConfig(ctx context.Context, a *appState) (*C
 		chains[chainName] = chain
 	}
 
+	if c.Global.AuditLog != nil {
+		auditLog, err := a.openAuditLog(*c.Global.AuditLog)
+		if err != nil {
+			return nil, err
+		}
+		for _, chain := range chains {
+			if cp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+				cp.SetAuditLog(auditLog)
+			}
+		}
+	}
+
+	if c.Global.RecordSpend {
+		if err := a.recordSpend(chains); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Global.QueryCache != nil {
+		for _, chain := range chains {
+			if cp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+				cache, err := provider.NewQueryCache(*c.Global.QueryCache)
+				if err != nil {
+					return nil, err
+				}
+				cp.SetQueryCache(cache)
+			}
+		}
+	}
+
 	return &Config{
 		Global: c.Global,
 		Chains: chains,
 		Paths:  c.Paths,
+		Routes: c.Routes,
 	}, nil
 }
 
@@ -475,7 +596,11 @@ func (c Config) MustYAML() []byte {
 }
 
 func defaultConfigYAML(memo string) []byte {
-	return DefaultConfig(memo).MustYAML()
+	out, err := yaml.Marshal(DefaultConfig(memo).Wrapped())
+	if err != nil {
+		panic(err)
+	}
+	return out
 }
 
 func DefaultConfig(memo string) *Config {
@@ -495,6 +620,22 @@ type GlobalConfig struct {
 	LogLevel        string `yaml:"log-level" json:"log-level"`
 	ICS20MemoLimit  int    `yaml:"ics20-memo-limit" json:"ics20-memo-limit"`
 	MaxReceiverSize int    `yaml:"max-receiver-size" json:"max-receiver-size"`
+
+	// AuditLog optionally configures an append-only log of every transaction signed and broadcast.
+	AuditLog *audit.Config `yaml:"audit-log,omitempty" json:"audit-log,omitempty"`
+
+	// RecordSpend records every transaction broadcast by any command to the accounting database,
+	// as rly start --record-spend does for the transactions broadcast while relaying.
+	RecordSpend bool `yaml:"record-spend,omitempty" json:"record-spend,omitempty"`
+
+	// Notifications optionally configures webhooks notified of conditions which need the attention of the operator.
+	Notifications *notify.Config `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+
+	// QueryCache optionally caches the results of client, connection and channel queries for a TTL per query type.
+	QueryCache *provider.QueryCacheConfig `yaml:"query-cache,omitempty" json:"query-cache,omitempty"`
+
+	// Metrics optionally selects the backend the metrics are exported to, Prometheus by default.
+	Metrics *metrics.Config `yaml:"metrics,omitempty" json:"metrics,omitempty"`
 }
 
 // newDefaultGlobalConfig returns a global config with defaults set
@@ -588,11 +729,60 @@ func (c *Config) validateConfig() error {
 		return fmt.Errorf("did you remember to run 'rly config init' error:%w", err)
 	}
 
+	if err := c.Global.AuditLog.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Global.Notifications.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Global.QueryCache.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Global.Metrics.Validate(); err != nil {
+		return err
+	}
+
 	// verify that the channel filter rule is valid for every path in the config
 	for _, p := range c.Paths {
 		if err := p.ValidateChannelFilterRule(); err != nil {
 			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
 		}
+		if err := p.RetryPolicy.Validate(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+		}
+		if err := p.Concurrency.Validate(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+		}
+		if err := p.ClientTrust.Validate(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+		}
+		if err := p.DenomPolicy.Validate(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+		}
+		if err := p.MemoFilter.Validate(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+		}
+		if err := p.ValidateLocalhost(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+		}
+		for _, pe := range []*relayer.PathEnd{p.Src, p.Dst} {
+			if err := pe.VclientType(); err != nil {
+				return fmt.Errorf("error initializing the relayer config for path %s: %w", p.String(), err)
+			}
+		}
+		if p.ICS20MemoLimit != nil && *p.ICS20MemoLimit < 0 {
+			return fmt.Errorf("error initializing the relayer config for path %s: invalid ics20-memo-limit: %d",
+				p.String(), *p.ICS20MemoLimit)
+		}
+	}
+
+	for name, r := range c.Routes {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("error initializing the relayer config for route %s: %w", name, err)
+		}
 	}
 
 	return nil
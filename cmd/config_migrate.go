@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configVersion is the version of the layout of the config file written by this relayer.
+// It must be bumped whenever a migration is appended to configMigrations.
+// Config files without a version are of version 0.
+const configVersion = 1
+
+// configMigration migrates a config file, decoded as raw YAML, from one version to the next.
+type configMigration struct {
+	description string
+	migrate     func(cfg map[string]any) error
+}
+
+// configMigrations migrate config files from the version of their index to the next version, in order.
+// Migrations only ever get appended, so that config files of any version can be migrated to the latest.
+var configMigrations = []configMigration{
+	{
+		description: "wrap chains in the type/value layout of chain providers, keyed by chain name",
+		migrate:     migrateChainProviderLayout,
+	},
+}
+
+// migrateConfig migrates the config file to the latest version. It returns the version of the file before
+// the migration, and the file unchanged if it is already of the latest version.
+func migrateConfig(file []byte) ([]byte, int, error) {
+	var cfg map[string]any
+	if err := yaml.Unmarshal(file, &cfg); err != nil {
+		return nil, 0, fmt.Errorf("error unmarshalling config: %w", err)
+	}
+	if cfg == nil {
+		cfg = make(map[string]any)
+	}
+
+	version := 0
+	if v, ok := cfg["version"]; ok {
+		if version, ok = v.(int); !ok || version < 0 {
+			return nil, 0, fmt.Errorf("invalid config version: %v", v)
+		}
+	}
+	if version > configVersion {
+		return nil, version, fmt.Errorf("config version %d is newer than the latest version %d known to this %s, "+
+			"upgrade %s to use this config", version, configVersion, appName, appName)
+	}
+	if version == configVersion {
+		return file, version, nil
+	}
+
+	for i, m := range configMigrations[version:] {
+		if err := m.migrate(cfg); err != nil {
+			return nil, version, fmt.Errorf("failed to migrate config from version %d to %d: %w", version+i, version+i+1, err)
+		}
+	}
+	cfg["version"] = configVersion
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, version, err
+	}
+	return out, version, nil
+}
+
+// migrateChainProviderLayout migrates chains configured before chain providers, either as a list of chains
+// or keyed by name, to the layout of a provider type and the config of the provider as its value.
+// All chains of such configs are cosmos chains.
+func migrateChainProviderLayout(cfg map[string]any) error {
+	switch chains := cfg["chains"].(type) {
+	case nil:
+	case []any:
+		migrated := make(map[string]any, len(chains))
+		for i, c := range chains {
+			chain, ok := c.(map[string]any)
+			if !ok {
+				return fmt.Errorf("chain %d is not a mapping", i)
+			}
+			name, _ := chain["chain-id"].(string)
+			if name == "" {
+				return fmt.Errorf("chain %d has no chain-id", i)
+			}
+			if _, ok := migrated[name]; ok {
+				return fmt.Errorf("chain %s is configured more than once", name)
+			}
+			migrated[name] = wrapChainProviderConfig(chain)
+		}
+		cfg["chains"] = migrated
+	case map[string]any:
+		for name, c := range chains {
+			chain, ok := c.(map[string]any)
+			if !ok {
+				return fmt.Errorf("chain %s is not a mapping", name)
+			}
+			if _, ok := chain["value"]; ok {
+				// already in the layout of chain providers.
+				continue
+			}
+			chains[name] = wrapChainProviderConfig(chain)
+		}
+	default:
+		return fmt.Errorf("chains must be a list or a mapping")
+	}
+	return nil
+}
+
+func wrapChainProviderConfig(chain map[string]any) map[string]any {
+	return map[string]any{
+		"type":  "cosmos",
+		"value": chain,
+	}
+}
+
+// Command for migrating the config file to the latest version
+func configMigrateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrates the config file to the latest version",
+		Long: strings.TrimSpace(fmt.Sprintf(`Migrate the config file to the latest version of its layout, so that it is read as intended by this
+version of %s. Config files of older versions are migrated in memory whenever they are read, with a warning,
+until they are migrated with this command. The config file is backed up with the suffix of its version first.`,
+			appName,
+		)),
+		Args: withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s config migrate
+$ %s cfg migrate --dry-run`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
+			if err != nil {
+				return err
+			}
+
+			cfgPath := a.configPath()
+			file, err := os.ReadFile(cfgPath)
+			if err != nil {
+				return fmt.Errorf("error reading file: %w", err)
+			}
+			_, version, err := migrateConfig(file)
+			if err != nil {
+				return err
+			}
+			if version == configVersion {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Config is already at the latest version %d\n", configVersion)
+				return nil
+			}
+
+			for i, m := range configMigrations[version:] {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Version %d to %d: %s\n", version+i, version+i+1, m.description)
+			}
+			if dryRun {
+				return nil
+			}
+
+			backup := fmt.Sprintf("%s.v%d.bak", cfgPath, version)
+			if err := writeFileAtomic(backup, file); err != nil {
+				return fmt.Errorf("failed to back up config file to %s: %w", backup, err)
+			}
+
+			// the config was migrated in memory once loaded, so it only needs to be written.
+			if err := a.performConfigLockingOperation(cmd.Context(), func() error { return nil }); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Migrated config to version %d, backed up to %s\n", configVersion, backup)
+			return nil
+		},
+	}
+	return dryRunFlag(a.viper, cmd)
+}
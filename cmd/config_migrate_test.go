@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigMigrationsMatchVersion(t *testing.T) {
+	require.Len(t, configMigrations, configVersion)
+}
+
+func TestMigrateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "list of chains",
+			config: `
+global:
+  timeout: 10s
+chains:
+  - key: default
+    chain-id: ibc-0
+    rpc-addr: http://localhost:26657
+paths: {}
+`,
+		},
+		{
+			name: "chains by name",
+			config: `
+global:
+  timeout: 10s
+chains:
+  ibc-0:
+    key: default
+    chain-id: ibc-0
+    rpc-addr: http://localhost:26657
+paths: {}
+`,
+		},
+		{
+			name: "chain providers",
+			config: `
+global:
+  timeout: 10s
+chains:
+  ibc-0:
+    type: cosmos
+    value:
+      key: default
+      chain-id: ibc-0
+      rpc-addr: http://localhost:26657
+paths: {}
+`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			out, version, err := migrateConfig([]byte(tc.config))
+			require.NoError(t, err)
+			require.Zero(t, version)
+
+			var cfg ConfigInputWrapper
+			require.NoError(t, yaml.Unmarshal(out, &cfg))
+			require.Equal(t, configVersion, cfg.Version)
+			require.Equal(t, "10s", cfg.Global.Timeout)
+			require.Len(t, cfg.ProviderConfigs, 1)
+			require.Equal(t, "cosmos", cfg.ProviderConfigs["ibc-0"].Type)
+			pcfg := cfg.ProviderConfigs["ibc-0"].Value.(*cosmos.CosmosProviderConfig)
+			require.Equal(t, "ibc-0", pcfg.ChainID)
+			require.Equal(t, "http://localhost:26657", pcfg.RPCAddr)
+
+			// migrated configs are not migrated again.
+			again, version, err := migrateConfig(out)
+			require.NoError(t, err)
+			require.Equal(t, configVersion, version)
+			require.Equal(t, out, again)
+		})
+	}
+}
+
+func TestMigrateConfigVersion(t *testing.T) {
+	_, _, err := migrateConfig([]byte("version: 100\nglobal: {}\n"))
+	require.ErrorContains(t, err, "newer than the latest version")
+
+	_, _, err = migrateConfig([]byte("version: latest\n"))
+	require.ErrorContains(t, err, "invalid config version")
+
+	_, _, err = migrateConfig([]byte("chains:\n  - key: default\n"))
+	require.ErrorContains(t, err, "no chain-id")
+}
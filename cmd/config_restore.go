@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// annotationSkipConfigLoad annotates commands which do not load the config file before they run,
+// e.g. to restore a config file which does not load.
+const annotationSkipConfigLoad = "skip-config-load"
+
+// configBackupSuffix is the suffix of the backup of the config file, which holds the config as it was before
+// it was last written.
+const configBackupSuffix = ".bak"
+
+// writeConfigFile writes the config file at cfgPath atomically, by writing a temporary file next to it and renaming
+// it over the config file, so that a crash while writing leaves either the old or the new config and never a
+// truncated one. The old config is backed up first, unless it is not a config file, so that a corrupted config
+// does not replace the last good backup.
+func writeConfigFile(cfgPath string, out []byte) error {
+	old, err := os.ReadFile(cfgPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("error reading file: %w", err)
+	case checkConfigFile(old) == nil && !bytes.Equal(old, out):
+		if err := writeFileAtomic(cfgPath+configBackupSuffix, old); err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(cfgPath, out); err != nil {
+		return fmt.Errorf("failed to write config file at %s: %w", cfgPath, err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at name with data, readable by the owner only.
+func writeFileAtomic(name string, data []byte) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if err := f.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	// the data must reach the disk before the rename does, or a crash may leave an empty file behind the name.
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// checkConfigFile returns an error if file is not a config file of a version known to this relayer.
+func checkConfigFile(file []byte) error {
+	if len(bytes.TrimSpace(file)) == 0 {
+		return errors.New("config file is empty")
+	}
+	_, _, err := migrateConfig(file)
+	return err
+}
+
+// configFileError adds how to restore the config file to an error reading it, if it has a backup.
+func configFileError(cfgPath string, err error) error {
+	if _, statErr := os.Stat(cfgPath + configBackupSuffix); statErr != nil {
+		return err
+	}
+	return fmt.Errorf("%w; the config as it was before it was last written is backed up at %s, "+
+		"run '%s config restore' to restore it", err, cfgPath+configBackupSuffix, appName)
+}
+
+// Command for restoring the config file from a backup
+func configRestoreCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [backup_file]?",
+		Short: "Restores the config file from its backup",
+		Long: strings.TrimSpace(fmt.Sprintf(`Restore the config file from the backup taken whenever the config file is written,
+which holds the config as it was before it was last written, or from the given backup file,
+e.g. one taken by '%s config migrate'. The backup must be a config file. The config file being
+replaced becomes the new backup, unless it is not a config file, so a restore can be undone
+by restoring again.`,
+			appName,
+		)),
+		Args:        withUsage(cobra.RangeArgs(0, 1)),
+		Annotations: map[string]string{annotationSkipConfigLoad: ""},
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s config restore
+$ %s cfg restore ~/.relayer/config/config.yaml.v0.bak`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgPath := a.configPath()
+			backup := cfgPath + configBackupSuffix
+			if len(args) > 0 {
+				backup = args[0]
+			}
+
+			file, err := os.ReadFile(backup)
+			if err != nil {
+				return fmt.Errorf("error reading backup: %w", err)
+			}
+			if err := checkConfigFile(file); err != nil {
+				return fmt.Errorf("backup %s is not a valid config file: %w", backup, err)
+			}
+
+			unlock, err := a.lockConfig()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			if err := writeConfigFile(cfgPath, file); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Restored config file %s from %s\n", cfgPath, backup)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,71 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/relayer/v2/cmd"
+	"github.com/cosmos/relayer/v2/internal/relayertest"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestConfigRestore(t *testing.T) {
+	t.Parallel()
+
+	sys := relayertest.NewSystem(t)
+
+	_ = sys.MustRun(t, "config", "init")
+
+	addChain := func(chainID string) {
+		sys.MustAddChain(t, chainID, cmd.ProviderConfigWrapper{
+			Type: "cosmos",
+			Value: cosmos.CosmosProviderConfig{
+				ChainID:        chainID,
+				KeyringBackend: "test",
+				Timeout:        "10s",
+			},
+		})
+	}
+	addChain("testcosmos-a")
+	addChain("testcosmos-b")
+	require.Len(t, sys.MustGetConfig(t).ProviderConfigs, 2)
+
+	// no temporary files are left behind by the writes.
+	entries, err := os.ReadDir(filepath.Join(sys.HomeDir, "config"))
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	require.ElementsMatch(t, []string{"config.yaml", "config.yaml.bak", "config.lock"}, names)
+
+	// a corrupted config is not loaded, and is not backed up over the last good config when restoring.
+	require.NoError(t, sys.WriteConfig(t, []byte("chains: [\n")))
+	res := sys.Run(zaptest.NewLogger(t), "chains", "list")
+	require.Error(t, res.Err)
+	require.ErrorContains(t, res.Err, "config restore")
+
+	res = sys.MustRun(t, "config", "restore")
+	require.Contains(t, res.Stderr.String(), "Restored config file")
+	cfg := sys.MustGetConfig(t)
+	require.Len(t, cfg.ProviderConfigs, 1)
+	require.Contains(t, cfg.ProviderConfigs, "testcosmos-a")
+
+	// the config replaced by a restore becomes the backup, so restoring again undoes the restore.
+	addChain("testcosmos-c")
+	_ = sys.MustRun(t, "config", "restore")
+	require.Len(t, sys.MustGetConfig(t).ProviderConfigs, 1)
+	_ = sys.MustRun(t, "config", "restore")
+	cfg = sys.MustGetConfig(t)
+	require.Len(t, cfg.ProviderConfigs, 2)
+	require.Contains(t, cfg.ProviderConfigs, "testcosmos-c")
+
+	// backups which are not config files are not restored.
+	empty := filepath.Join(t.TempDir(), "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, nil, 0600))
+	res = sys.Run(zaptest.NewLogger(t), "config", "restore", empty)
+	require.ErrorContains(t, res.Err, "not a valid config file")
+}
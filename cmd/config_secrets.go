@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// vaultRequestTimeout is the timeout of requests to Vault for secrets referenced by the config file.
+const vaultRequestTimeout = 10 * time.Second
+
+// secretRefPattern matches references to secrets in the string values of the config file: environment variables,
+// e.g. ${env:RPC_API_KEY}, files such as mounted kubernetes secrets, e.g. ${file:/run/secrets/rpc-addr}, and
+// secrets of HashiCorp Vault with an optional field, e.g. ${vault:secret/data/relayer#rpc-addr}.
+var secretRefPattern = regexp.MustCompile(`\$\{(env|file|vault):([^}]+)\}`)
+
+// secretRef is a value of the config file which references secrets. The secrets are resolved in memory when the
+// config is loaded, and the reference is written back in place of the secrets whenever the config is written.
+type secretRef struct {
+	// path is the keys of the value in the config, with list indexes as keys.
+	path []string
+
+	// ref is the value as written in the config file.
+	ref string
+
+	// resolved is the value with the secrets resolved.
+	resolved any
+}
+
+// secretResolver resolves the references to secrets of a config file, reading each secret of Vault once.
+type secretResolver struct {
+	ctx   context.Context
+	http  *http.Client
+	vault map[string]map[string]any
+	refs  []secretRef
+}
+
+// resolveConfigSecrets resolves the references to secrets of the config file. It returns the config file with
+// the secrets in place of the references, and the references to write back, or the file unchanged if it has none.
+func resolveConfigSecrets(ctx context.Context, file []byte) ([]byte, []secretRef, error) {
+	if !secretRefPattern.Match(file) {
+		return file, nil, nil
+	}
+
+	var cfg map[string]any
+	if err := yaml.Unmarshal(file, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("error unmarshalling config: %w", err)
+	}
+	r := &secretResolver{
+		ctx:   ctx,
+		http:  &http.Client{Timeout: vaultRequestTimeout},
+		vault: make(map[string]map[string]any),
+	}
+	resolved, err := r.resolve(cfg, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := yaml.Marshal(resolved)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, r.refs, nil
+}
+
+// resolve resolves the references to secrets of the node of the config at path, and those of its children.
+func (r *secretResolver) resolve(node any, path []string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		for k, v := range n {
+			resolved, err := r.resolve(v, append(path, k))
+			if err != nil {
+				return nil, err
+			}
+			n[k] = resolved
+		}
+	case []any:
+		for i, v := range n {
+			resolved, err := r.resolve(v, append(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			n[i] = resolved
+		}
+	case string:
+		if !secretRefPattern.MatchString(n) {
+			return n, nil
+		}
+		resolved, err := r.resolveString(n, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s of config at %s: %w", n, strings.Join(path, "."), err)
+		}
+		r.refs = append(r.refs, secretRef{path: slices.Clone(path), ref: n, resolved: resolved})
+		return resolved, nil
+	}
+	return node, nil
+}
+
+// resolveString resolves the references to secrets of a string value. A chain, or the config of its provider,
+// may be referenced as a whole, in YAML or JSON, or as a secret of Vault without a field.
+func (r *secretResolver) resolveString(s string, path []string) (any, error) {
+	if isChainConfigPath(path) {
+		m := secretRefPattern.FindStringSubmatch(s)
+		if m == nil || m[0] != s {
+			return nil, fmt.Errorf("a chain config must be a single reference")
+		}
+		secret, err := r.secret(m[1], m[2])
+		if err != nil {
+			return nil, err
+		}
+		if str, ok := secret.(string); ok {
+			var chain map[string]any
+			if err := yaml.Unmarshal([]byte(str), &chain); err != nil {
+				return nil, fmt.Errorf("invalid chain config: %w", err)
+			}
+			return chain, nil
+		}
+		return secret, nil
+	}
+
+	var err error
+	resolved := secretRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := secretRefPattern.FindStringSubmatch(ref)
+		secret, serr := r.secret(m[1], m[2])
+		if serr != nil {
+			err = serr
+			return ""
+		}
+		if str, ok := secret.(string); ok {
+			return str
+		}
+		return fmt.Sprint(secret)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// isChainConfigPath returns true if the path is that of a chain, or of the config of its provider.
+func isChainConfigPath(path []string) bool {
+	return (len(path) == 2 && path[0] == "chains") || (len(path) == 3 && path[0] == "chains" && path[2] == "value")
+}
+
+// secret returns the secret of the source with the name: a string, or the data of a secret of Vault without a field.
+func (r *secretResolver) secret(source, name string) (any, error) {
+	switch source {
+	case "env":
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case "file":
+		bz, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return strings.TrimRight(string(bz), "\r\n"), nil
+	case "vault":
+		secretPath, field, hasField := strings.Cut(name, "#")
+		data, err := r.vaultSecret(secretPath)
+		if err != nil {
+			return nil, err
+		}
+		if !hasField {
+			return data, nil
+		}
+		v, ok := data[field]
+		if !ok {
+			return nil, fmt.Errorf("vault secret %s has no field %s", secretPath, field)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown secret source %s", source)
+}
+
+// vaultSecret reads the data of the secret at the path from Vault, as configured by the standard VAULT_ADDR,
+// VAULT_TOKEN and VAULT_NAMESPACE environment variables.
+func (r *secretResolver) vaultSecret(path string) (map[string]any, error) {
+	if data, ok := r.vault[path]; ok {
+		return data, nil
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR must be set to read secret %s from vault", path)
+	}
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet,
+		strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	res, err := r.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s from vault: %w", path, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return nil, fmt.Errorf("failed to read secret %s from vault: %s: %s", path, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&secret); err != nil {
+		return nil, fmt.Errorf("invalid secret %s from vault: %w", path, err)
+	}
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]any); ok && data["metadata"] != nil {
+		// secrets of the version 2 KV secrets engine are nested along with their metadata.
+		data = inner
+	}
+	data = jsonNumbers(data).(map[string]any)
+
+	r.vault[path] = data
+	return data, nil
+}
+
+// jsonNumbers converts the JSON numbers of v to integers, or else floats, so that they are decoded
+// as numbers rather than strings once in the config.
+func jsonNumbers(v any) any {
+	switch n := v.(type) {
+	case map[string]any:
+		for k, v := range n {
+			n[k] = jsonNumbers(v)
+		}
+	case []any:
+		for i, v := range n {
+			n[i] = jsonNumbers(v)
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		f, _ := n.Float64()
+		return f
+	}
+	return v
+}
+
+// withSecretRefs returns the config with the references to secrets it was loaded with in place of the secrets,
+// so that secrets are neither written to disk nor shown. A value which was changed since the config was loaded
+// is kept rather than replaced, except for chains configured by reference as a whole, which are never written.
+func withSecretRefs(cfg *ConfigOutputWrapper, refs []secretRef) (any, error) {
+	if len(refs) == 0 {
+		return cfg, nil
+	}
+
+	bz, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := yaml.Unmarshal(bz, &out); err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		restoreSecretRef(out, ref)
+	}
+	return out, nil
+}
+
+// restoreSecretRef writes the reference in place of its resolved value in the config, if the value is unchanged.
+func restoreSecretRef(cfg map[string]any, ref secretRef) {
+	var node any = cfg
+	for i, key := range ref.path {
+		last := i == len(ref.path)-1
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[key]
+			if !ok {
+				return
+			}
+			if last {
+				if isChainConfigPath(ref.path) || v == ref.resolved {
+					n[key] = ref.ref
+				}
+				return
+			}
+			node = v
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx >= len(n) {
+				return
+			}
+			if last {
+				if n[idx] == ref.resolved {
+					n[idx] = ref.ref
+				}
+				return
+			}
+			node = n[idx]
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveConfigSecrets(t *testing.T) {
+	t.Setenv("RLY_TEST_API_KEY", "s3cr3t")
+	grpcAddrFile := filepath.Join(t.TempDir(), "grpc-addr")
+	require.NoError(t, os.WriteFile(grpcAddrFile, []byte("localhost:9090\n"), 0600))
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/secret/data/relayer":
+			_, _ = w.Write([]byte(`{"data":{"data":{"key":"relayer-key"},"metadata":{"version":1}}}`))
+		case "/v1/secret/data/ibc-1":
+			_, _ = w.Write([]byte(`{"data":{"data":{"type":"cosmos","value":{"chain-id":"ibc-1","gas-adjustment":1.5,"max-gas-amount":1000000}},"metadata":{"version":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	file := []byte(`
+chains:
+  ibc-0:
+    type: cosmos
+    value:
+      key: ${vault:secret/data/relayer#key}
+      chain-id: ibc-0
+      rpc-addr: https://rpc.example.com/${env:RLY_TEST_API_KEY}
+      grpc-addr: ${file:` + grpcAddrFile + `}
+  ibc-1: ${vault:secret/data/ibc-1}
+`)
+	out, refs, err := resolveConfigSecrets(context.Background(), file)
+	require.NoError(t, err)
+	require.Len(t, refs, 4)
+
+	var cfg ConfigInputWrapper
+	require.NoError(t, yaml.Unmarshal(out, &cfg))
+	require.Len(t, cfg.ProviderConfigs, 2)
+	ibc0 := cfg.ProviderConfigs["ibc-0"].Value.(*cosmos.CosmosProviderConfig)
+	require.Equal(t, "relayer-key", ibc0.Key)
+	require.Equal(t, "https://rpc.example.com/s3cr3t", ibc0.RPCAddr)
+	require.Equal(t, "localhost:9090", ibc0.GRPCAddr)
+	ibc1 := cfg.ProviderConfigs["ibc-1"].Value.(*cosmos.CosmosProviderConfig)
+	require.Equal(t, "ibc-1", ibc1.ChainID)
+	require.Equal(t, 1.5, ibc1.GasAdjustment)
+	require.Equal(t, uint64(1000000), ibc1.MaxGasAmount)
+
+	// the references are written back in place of the secrets, unless the value was changed.
+	var written map[string]any
+	require.NoError(t, yaml.Unmarshal(out, &written))
+	chains := written["chains"].(map[string]any)
+	chains["ibc-0"].(map[string]any)["value"].(map[string]any)["grpc-addr"] = "localhost:9091"
+	for _, ref := range refs {
+		restoreSecretRef(written, ref)
+	}
+	ibc0Value := chains["ibc-0"].(map[string]any)["value"].(map[string]any)
+	require.Equal(t, "${vault:secret/data/relayer#key}", ibc0Value["key"])
+	require.Equal(t, "https://rpc.example.com/${env:RLY_TEST_API_KEY}", ibc0Value["rpc-addr"])
+	require.Equal(t, "localhost:9091", ibc0Value["grpc-addr"])
+	require.Equal(t, "${vault:secret/data/ibc-1}", chains["ibc-1"])
+}
+
+func TestResolveConfigSecretsErrors(t *testing.T) {
+	file, refs, err := resolveConfigSecrets(context.Background(), []byte("global:\n  memo: plain\n"))
+	require.NoError(t, err)
+	require.Nil(t, refs)
+	require.Equal(t, "global:\n  memo: plain\n", string(file))
+
+	_, _, err = resolveConfigSecrets(context.Background(), []byte("global:\n  memo: ${env:RLY_TEST_UNSET}\n"))
+	require.ErrorContains(t, err, "environment variable RLY_TEST_UNSET is not set")
+
+	t.Setenv("VAULT_ADDR", "")
+	_, _, err = resolveConfigSecrets(context.Background(), []byte("global:\n  memo: ${vault:secret/memo#memo}\n"))
+	require.ErrorContains(t, err, "VAULT_ADDR must be set")
+
+	_, _, err = resolveConfigSecrets(context.Background(), []byte("chains:\n  ibc-0: chain-${env:HOME}\n"))
+	require.ErrorContains(t, err, "must be a single reference")
+}
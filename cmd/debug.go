@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/spf13/cobra"
+)
+
+// controlRequestTimeout is how long a request to the control API of a running relayer may take.
+const controlRequestTimeout = 30 * time.Second
+
+// debugCmd returns the commands to debug a running relayer.
+func debugCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Commands to debug a running relayer",
+	}
+
+	cmd.AddCommand(
+		debugShellCmd(a),
+	)
+
+	return cmd
+}
+
+func debugShellCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell [path_name]",
+		Short: "Interactive shell to inspect and intervene in the relaying of a running relayer",
+		Long: fmt.Sprintf(`Connect to the control API of a relayer started with 'rly start --%s' and run commands against one of its paths.
+
+Commands:
+  paths                                            list the paths being relayed
+  use <path_name>                                  select the path to run commands against
+  packets                                          list the packets yet to be relayed
+  quarantined                                      list the packets quarantined after failing on their own
+  update-clients                                   force an update of the clients of the path
+  retry <chain_id> <channel_id> <port_id> <seq>    relay the packet sent with seq on the channel of chain_id again
+  txs [n]                                          show the results of the last n transactions with full logs (default %d)
+  help                                             show this help
+  exit                                             leave the shell`, flagControlAPI, defaultShellTxResults),
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s debug shell demo-path
+$ %s debug shell demo-path --debug-addr localhost:7597`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			debugAddr, err := a.debugAddr(cmd)
+			if err != nil {
+				return err
+			}
+			if debugAddr == "" {
+				return fmt.Errorf("no debug address, set --%s", flagDebugAddr)
+			}
+
+			client, err := newControlClient(debugAddr)
+			if err != nil {
+				return err
+			}
+
+			var pathName string
+			if len(args) > 0 {
+				pathName = args[0]
+			}
+
+			return runDebugShell(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), client, pathName)
+		},
+	}
+
+	return debugServerFlags(a.viper, cmd)
+}
+
+// defaultShellTxResults is how many tx results the txs command of the debug shell shows by default.
+const defaultShellTxResults = 10
+
+// controlClient is a client of the control API of a running relayer.
+type controlClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// newControlClient returns a controlClient for the relayer with its debug server listening on debugAddr.
+func newControlClient(debugAddr string) (*controlClient, error) {
+	host, port, err := net.SplitHostPort(debugAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid debug address %q: %w", debugAddr, err)
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return &controlClient{
+		baseURL: "http://" + net.JoinHostPort(host, port) + relayer.ControlAPIPrefix,
+		http:    &http.Client{Timeout: controlRequestTimeout},
+	}, nil
+}
+
+func (c *controlClient) do(ctx context.Context, method, path string, query url.Values, out any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the control API, is the relayer running with --%s? %w", flagControlAPI, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func (c *controlClient) paths(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := c.do(ctx, http.MethodGet, "paths", nil, &paths)
+	return paths, err
+}
+
+func (c *controlClient) packets(ctx context.Context, pathName string) ([]processor.QueuedPacket, error) {
+	var packets []processor.QueuedPacket
+	err := c.do(ctx, http.MethodGet, "paths/"+url.PathEscape(pathName)+"/packets", nil, &packets)
+	return packets, err
+}
+
+func (c *controlClient) quarantined(ctx context.Context, pathName string) ([]processor.QuarantinedPacket, error) {
+	var packets []processor.QuarantinedPacket
+	err := c.do(ctx, http.MethodGet, "paths/"+url.PathEscape(pathName)+"/quarantined", nil, &packets)
+	return packets, err
+}
+
+func (c *controlClient) updateClients(ctx context.Context, pathName string) error {
+	return c.do(ctx, http.MethodPost, "paths/"+url.PathEscape(pathName)+"/update-clients", nil, nil)
+}
+
+func (c *controlClient) retry(ctx context.Context, pathName, chainID, channelID, portID string, sequence uint64) error {
+	query := url.Values{
+		"chain_id":   {chainID},
+		"channel_id": {channelID},
+		"port_id":    {portID},
+		"sequence":   {strconv.FormatUint(sequence, 10)},
+	}
+	return c.do(ctx, http.MethodPost, "paths/"+url.PathEscape(pathName)+"/retry", query, nil)
+}
+
+func (c *controlClient) txs(ctx context.Context, pathName string, limit int) ([]processor.TxResult, error) {
+	var txs []processor.TxResult
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	err := c.do(ctx, http.MethodGet, "paths/"+url.PathEscape(pathName)+"/txs", query, &txs)
+	return txs, err
+}
+
+func (c *controlClient) stats(ctx context.Context, pathName string) (processor.SLAStats, error) {
+	var stats processor.SLAStats
+	err := c.do(ctx, http.MethodGet, "paths/"+url.PathEscape(pathName)+"/stats", nil, &stats)
+	return stats, err
+}
+
+func (c *controlClient) status(ctx context.Context, pathName string) (processor.PathStatus, error) {
+	var status processor.PathStatus
+	err := c.do(ctx, http.MethodGet, "paths/"+url.PathEscape(pathName)+"/status", nil, &status)
+	return status, err
+}
+
+// runDebugShell reads commands from in until it is exhausted or the exit command is given,
+// and writes their output to out. Errors of commands are written to out rather than ending the shell.
+func runDebugShell(ctx context.Context, in io.Reader, out io.Writer, client *controlClient, pathName string) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(out, "%s> ", pathName)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		command, args := fields[0], fields[1:]
+
+		if command == "exit" || command == "quit" {
+			return nil
+		}
+
+		var err error
+		pathName, err = runDebugShellCommand(ctx, out, client, pathName, command, args)
+		if err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+}
+
+// runDebugShellCommand runs a single command of the debug shell and returns the selected path.
+func runDebugShellCommand(
+	ctx context.Context,
+	out io.Writer,
+	client *controlClient,
+	pathName, command string,
+	args []string,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, controlRequestTimeout)
+	defer cancel()
+
+	switch command {
+	case "help":
+		fmt.Fprintln(out, "Commands: paths, use <path_name>, packets, quarantined, update-clients, "+
+			"retry <chain_id> <channel_id> <port_id> <seq>, txs [n], help, exit")
+		return pathName, nil
+	case "paths":
+		paths, err := client.paths(ctx)
+		if err != nil {
+			return pathName, err
+		}
+		for _, p := range paths {
+			marker := " "
+			if p == pathName {
+				marker = "*"
+			}
+			fmt.Fprintf(out, "%s %s\n", marker, p)
+		}
+		return pathName, nil
+	case "use":
+		if len(args) != 1 {
+			return pathName, fmt.Errorf("usage: use <path_name>")
+		}
+		return args[0], nil
+	}
+
+	if pathName == "" {
+		return pathName, fmt.Errorf("no path selected, select one with: use <path_name>")
+	}
+
+	switch command {
+	case "packets":
+		packets, err := client.packets(ctx, pathName)
+		if err != nil {
+			return pathName, err
+		}
+		if len(packets) == 0 {
+			fmt.Fprintln(out, "No queued packets")
+		}
+		for _, p := range packets {
+			fmt.Fprintf(out, "%s %s seq=%d height=%d %s/%s -> %s/%s\n",
+				p.ChainID, p.EventType, p.Sequence, p.Height,
+				p.SourcePortID, p.SourceChannelID, p.DestPortID, p.DestChannelID,
+			)
+		}
+	case "quarantined":
+		packets, err := client.quarantined(ctx, pathName)
+		if err != nil {
+			return pathName, err
+		}
+		if len(packets) == 0 {
+			fmt.Fprintln(out, "No quarantined packets")
+		}
+		for _, p := range packets {
+			fmt.Fprintf(out, "%s %s seq=%d %s/%s -> %s/%s since %s: %s\n",
+				p.ChainID, p.EventType, p.Sequence,
+				p.SourcePortID, p.SourceChannelID, p.DestPortID, p.DestChannelID,
+				p.Time.Format(time.RFC3339), p.Error,
+			)
+		}
+	case "update-clients":
+		if err := client.updateClients(ctx, pathName); err != nil {
+			return pathName, err
+		}
+		fmt.Fprintln(out, "Client updates will be sent with the next messages of the path")
+	case "retry":
+		if len(args) != 4 {
+			return pathName, fmt.Errorf("usage: retry <chain_id> <channel_id> <port_id> <seq>")
+		}
+		sequence, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil {
+			return pathName, fmt.Errorf("invalid sequence %s: %w", args[3], err)
+		}
+		if err := client.retry(ctx, pathName, args[0], args[1], args[2], sequence); err != nil {
+			return pathName, err
+		}
+		fmt.Fprintf(out, "Packet %d will be relayed again\n", sequence)
+	case "txs":
+		limit := defaultShellTxResults
+		if len(args) > 0 {
+			var err error
+			if limit, err = strconv.Atoi(args[0]); err != nil {
+				return pathName, fmt.Errorf("invalid number of txs %s: %w", args[0], err)
+			}
+		}
+		txs, err := client.txs(ctx, pathName, limit)
+		if err != nil {
+			return pathName, err
+		}
+		if len(txs) == 0 {
+			fmt.Fprintln(out, "No txs broadcast yet")
+			return pathName, nil
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(txs); err != nil {
+			return pathName, err
+		}
+	default:
+		return pathName, fmt.Errorf("unknown command %q, see help", command)
+	}
+
+	return pathName, nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cosmos/relayer/v2/internal/testnet"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/spf13/cobra"
+)
+
+// devCmd returns the commands for developing against the relayer.
+func devCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Commands for contributors and app developers to reproduce relaying scenarios locally",
+	}
+
+	cmd.AddCommand(
+		devTestnetCmd(a),
+		devVerifyProofCmd(a),
+	)
+
+	return cmd
+}
+
+func devTestnetCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "testnet",
+		Short: "Scaffold a local testnet of chains run with docker compose, with the paths between them",
+		Long: strings.TrimSpace(`Scaffold a docker compose project of local single validator chains, along with the relayer
+chain and path configs for them and a setup script which adds them to the relayer config, restores
+a relayer key funded in the genesis of every chain and links the paths. The genesis of each chain
+is generated the first time it is started.`),
+		Args: withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s dev testnet
+$ %s dev testnet --chains 3 --dir /tmp/testnet
+$ %s dev testnet --image ghcr.io/strangelove-ventures/heighliner/osmosis:v25.0.0 --binary osmosisd`,
+			appName, appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chains, err := cmd.Flags().GetInt(flagTestnetChains)
+			if err != nil {
+				return err
+			}
+			dir, err := cmd.Flags().GetString(flagTestnetDir)
+			if err != nil {
+				return err
+			}
+			image, err := cmd.Flags().GetString(flagTestnetImage)
+			if err != nil {
+				return err
+			}
+			binary, err := cmd.Flags().GetString(flagTestnetBinary)
+			if err != nil {
+				return err
+			}
+
+			mnemonic, err := cosmos.CreateMnemonic()
+			if err != nil {
+				return err
+			}
+
+			t, err := testnet.Scaffold(dir, testnet.Config{
+				Chains:   chains,
+				Image:    image,
+				Binary:   binary,
+				Mnemonic: mnemonic,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Scaffolded a testnet of %d chains in %s\n\n", len(t.Chains), t.Dir)
+			for _, c := range t.Chains {
+				fmt.Fprintf(out, "  %s  rpc: http://localhost:%d  grpc: localhost:%d\n", c.ChainID, c.RPCPort, c.GRPCPort)
+			}
+			fmt.Fprintf(out, "\nStart the chains and link the paths %s with:\n\n", strings.Join(t.Paths, ", "))
+			fmt.Fprintf(out, "  docker compose -f %s up -d\n", filepath.Join(t.Dir, "docker-compose.yml"))
+			fmt.Fprintf(out, "  RLY=\"%s --home %s\" %s\n\n", appName, a.homePath, filepath.Join(t.Dir, "setup.sh"))
+			fmt.Fprintf(out, "then relay with '%s start'.\n", appName)
+			return nil
+		},
+	}
+
+	return testnetFlags(a.viper, cmd)
+}
+
+func devVerifyProofCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-proof chain_name key client_chain_name client_id [height]",
+		Short: "Verify the ICS-23 proof of a store key against the consensus state of a client",
+		Long: strings.TrimSpace(fmt.Sprintf(`Query the proof of a key in a store of chain_name, as 'rly query proof' does, and verify it
+against the commitment root of the consensus state at the proof height of the client with client_id on
+client_chain_name, which must track chain_name. The root is also checked against the header of chain_name
+at that height, to tell a client which was updated with a conflicting header from a proof which does not
+verify, when diagnosing proof-related tx failures. The client must have a consensus state at the proof
+height, e.g. after '%s tx update-clients'.`, appName)),
+		Args: withUsage(cobra.RangeArgs(4, 5)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s dev verify-proof ibc-0 clients/07-tendermint-0/clientState ibc-1 07-tendermint-0 1200
+$ %s dev verify-proof ibc-0 receipts/ports/transfer/channels/channel-0/sequences/7 ibc-1 07-tendermint-0`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			clientChain, ok := a.config.Chains[args[2]]
+			if !ok {
+				return errChainNotFound(args[2])
+			}
+
+			proof, err := queryStoreProofFromArgs(cmd, chain, args[1], args[4:])
+			if err != nil {
+				return err
+			}
+
+			verification, err := relayer.VerifyStoreProof(cmd.Context(), chain, clientChain, args[3], proof)
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(struct {
+				Proof        *relayer.StoreProof        `json:"proof"`
+				Verification *relayer.ProofVerification `json:"verification"`
+			}{proof, verification}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+
+			if !verification.Verified {
+				return fmt.Errorf("proof verification failed: %s", verification.Error)
+			}
+			return nil
+		},
+	}
+
+	return storeFlag(a.viper, cmd)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	sdkflags "github.com/cosmos/cosmos-sdk/client/flags"
 
@@ -179,6 +180,79 @@ func feegrantConfigureBasicCmd(a *appState) *cobra.Command {
 	return cmd
 }
 
+// feeGranterConfigureCmd returns the command to configure a sponsor account which pays the fees of the TXs of a chain.
+func feeGranterConfigureCmd(a *appState) *cobra.Command {
+	var delete bool
+
+	cmd := &cobra.Command{
+		Use:   "fee-granter chain-name [granter]",
+		Short: "Pay the fees of TXs signed by the chain's key from the feegrant allowance of a sponsor account",
+		Long: "Pay the fees of TXs signed by the chain's key from the allowance which the granter, a bech32 address or key name, " +
+			"granted to the key via the feegrant module. The allowance is verified on chain, and alerts are logged by rly start " +
+			"when it is nearly exhausted or about to expire.",
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s chains configure fee-granter cosmoshub cosmos1sponsor...
+$ %s chains configure fee-granter cosmoshub --delete`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := args[0]
+			chain, ok := a.config.Chains[chainName]
+			if !ok {
+				return errChainNotFound(chainName)
+			}
+
+			prov, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+			if !ok {
+				return errors.New("only CosmosProvider can be feegranted")
+			}
+
+			var granter string
+			switch {
+			case delete && len(args) > 1:
+				return errors.New("a granter cannot be given along with --delete")
+			case !delete && len(args) < 2:
+				return errors.New("a granter must be given unless --delete is set")
+			case !delete:
+				granter = args[1]
+				if prov.PCfg.FeeGrants != nil {
+					return fmt.Errorf("chain %s is configured with round-robin feegrants, which cannot be used along with a fee-granter", chainName)
+				}
+
+				// verify that the allowance exists before TXs are paid from it.
+				prev := prov.PCfg.FeeGranter
+				prov.PCfg.FeeGranter = granter
+				spendLimit, expiration, err := prov.QueryFeeGranterAllowance(cmd.Context())
+				prov.PCfg.FeeGranter = prev
+				if err != nil {
+					return err
+				}
+				fields := []zap.Field{zap.String("chain", chainName), zap.String("fee_granter", granter)}
+				if spendLimit != nil {
+					fields = append(fields, zap.String("spend_limit", spendLimit.String()))
+				}
+				if expiration != nil {
+					fields = append(fields, zap.Time("expiration", *expiration))
+				}
+				a.log.Info("Fee grant allowance found", fields...)
+			}
+
+			return a.performConfigLockingOperation(cmd.Context(), func() error {
+				chain, ok := a.config.Chains[chainName]
+				if !ok {
+					return errChainNotFound(chainName)
+				}
+				chain.ChainProvider.(*cosmos.CosmosProvider).PCfg.FeeGranter = granter
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&delete, "delete", false, "stop paying fees from the allowance of the fee-granter")
+	return cmd
+}
+
 func feegrantBasicGrantsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "basic chain-name [granter]",
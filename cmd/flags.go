@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/internal/testnet"
 	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/gossip"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -50,23 +54,97 @@ const (
 	flagInitialBlockHistory            = "block-history"
 	flagFlushInterval                  = "flush-interval"
 	flagMemo                           = "memo"
+	flagPacketMemo                     = "packet-memo"
+	flagICS20MemoLimit                 = "ics20-memo-limit"
 	flagKeyName                        = "key-name"
 	flagFilterRule                     = "filter-rule"
 	flagFilterChannels                 = "filter-channels"
+	flagPriorityChannels               = "priority-channels"
 	flagSrcChainID                     = "src-chain-id"
 	flagDstChainID                     = "dst-chain-id"
 	flagSrcClientID                    = "src-client-id"
 	flagDstClientID                    = "dst-client-id"
 	flagSrcConnID                      = "src-connection-id"
 	flagDstConnID                      = "dst-connection-id"
+	flagSrcConnHops                    = "src-connection-hops"
+	flagDstConnHops                    = "dst-connection-hops"
 	flagOutput                         = "output"
+	flagBalances                       = "balances"
 	flagStuckPacketChainID             = "stuck-packet-chain-id"
 	flagStuckPacketHeightStart         = "stuck-packet-height-start"
 	flagStuckPacketHeightEnd           = "stuck-packet-height-end"
+	flagAuditProofHeights              = "audit-proof-heights"
+	flagVerifyProofs                   = "verify-proofs"
+	flagAmount                         = "amount"
+	flagReceiver                       = "receiver"
+	flagMsg                            = "msg"
+	flagRecordSpend                    = "record-spend"
+	flagIndexEvents                    = "index-events"
+	flagIndexRetention                 = "index-retention-blocks"
+	flagFromIndex                      = "from-index"
+	flagSince                          = "since"
+	flagAll                            = "all"
+	flagStale                          = "stale"
+	flagUpdatePath                     = "update-path"
+	flagNoTx                           = "no-tx"
+	flagSkipRelayed                    = "skip-relayed"
+	flagUnwind                         = "unwind"
+	flagRPCDiscovery                   = "rpc-discovery"
+	flagRPCDiscoveryInterval           = "rpc-discovery-interval"
+	flagControlAPI                     = "control-api"
+	flagTestnetChains                  = "chains"
+	flagTestnetDir                     = "dir"
+	flagTestnetImage                   = "image"
+	flagTestnetBinary                  = "binary"
+	flagRefreshInterval                = "refresh-interval"
+	flagTrustLevel                     = "trust-level"
+	flagForceBisection                 = "force-bisection"
+	flagSrcChannel                     = "src-channel"
+	flagDstChannel                     = "dst-channel"
+	flagMaxInFlightTxs                 = "max-in-flight-txs"
+	flagWorkersPerPath                 = "workers-per-path"
+	flagQueryConcurrency               = "query-concurrency"
+	flagWeight                         = "weight"
+	flagFromHeight                     = "from-height"
+	flagRoute                          = "route"
+	flagSequential                     = "sequential"
+	flagStore                          = "store"
+	flagDryRun                         = "dry-run"
+	flagSameBlockAcks                  = "same-block-acks"
+	flagValidators                     = "validators"
+	flagClientParamsInterval           = "client-params-check-interval"
+	flagPort                           = "port"
+	flagTxsPerBlock                    = "txs-per-block"
+	flagDedupEvents                    = "dedup-events"
+	flagDedupRetention                 = "dedup-retention-blocks"
+	flagProfile                        = "profile"
+	flagStandby                        = "standby"
+	flagRelayEventsSocket              = "relay-events-socket"
+	flagGovChain                       = "gov-chain"
+	flagGovProposal                    = "gov-proposal"
+	flagGovDeposit                     = "gov-deposit"
+	flagGossipAddr                     = "gossip-addr"
+	flagGossipURL                      = "gossip-url"
+	flagGossipBootstrap                = "gossip-bootstrap"
+	flagGossipClaimTTL                 = "gossip-claim-ttl"
+	flagGossipKey                      = "gossip-key"
+	flagGossipTrustedPeers             = "gossip-trusted-peers"
+	flagFeeClaimInterval               = "fee-claim-interval"
+	flagMaxDuration                    = "max-duration"
+	flagGenerateOnly                   = "generate-only"
+	flagSrcClientType                  = "src-client-type"
+	flagDstClientType                  = "dst-client-type"
+	flagPackets                        = "packets"
+	flagBatchSize                      = "batch-size"
+	flagInterval                       = "interval"
+	flagBlockTime                      = "block-time"
 )
 
 const blankValue = "blank"
 
+// rpcDiscoveryChainRegistry resolves RPC endpoints from the cosmos chain registry.
+const rpcDiscoveryChainRegistry = "chain-registry"
+
 func ibcDenomFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().BoolP(flagIBCDenoms, "i", false, "Display IBC denominations for sending tokens back to other chains")
 	if err := v.BindPFlag(flagIBCDenoms, cmd.Flags().Lookup(flagIBCDenoms)); err != nil {
@@ -201,6 +279,32 @@ func pathFilterFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	if err := v.BindPFlag(flagFilterRule, flags.Lookup(flagFilterRule)); err != nil {
 		panic(err)
 	}
+	flags.String(flagPriorityChannels, blankValue, "channels from source chain perspective whose packets are relayed first")
+	if err := v.BindPFlag(flagPriorityChannels, flags.Lookup(flagPriorityChannels)); err != nil {
+		panic(err)
+	}
+	flags.Int(flagICS20MemoLimit, 0, "limit of the size of the memo of ICS-20 packets relayed on the path, "+
+		"overriding the global limit (0 for no limit)")
+	if err := v.BindPFlag(flagICS20MemoLimit, flags.Lookup(flagICS20MemoLimit)); err != nil {
+		panic(err)
+	}
+	flags.Int(flagMaxInFlightTxs, 0, "how many transactions broadcast to each chain of the path may await inclusion at once (0 for no limit)")
+	if err := v.BindPFlag(flagMaxInFlightTxs, flags.Lookup(flagMaxInFlightTxs)); err != nil {
+		panic(err)
+	}
+	flags.Int(flagWorkersPerPath, 0, "how many messages of the path are assembled at once (0 for no limit)")
+	if err := v.BindPFlag(flagWorkersPerPath, flags.Lookup(flagWorkersPerPath)); err != nil {
+		panic(err)
+	}
+	flags.Int(flagQueryConcurrency, 0, "how many queries are run at once when flushing the path (0 for no limit)")
+	if err := v.BindPFlag(flagQueryConcurrency, flags.Lookup(flagQueryConcurrency)); err != nil {
+		panic(err)
+	}
+	flags.Int(flagWeight, 0, "share of the path in the transactions broadcast per block to a chain shared with other paths, "+
+		"relative to their weights, when started with --"+flagTxsPerBlock+" (0 for a weight of 1)")
+	if err := v.BindPFlag(flagWeight, flags.Lookup(flagWeight)); err != nil {
+		panic(err)
+	}
 	flags.String(flagSrcChainID, "", "chain ID for source chain")
 	if err := v.BindPFlag(flagSrcChainID, flags.Lookup(flagSrcChainID)); err != nil {
 		panic(err)
@@ -225,11 +329,30 @@ func pathFilterFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	if err := v.BindPFlag(flagDstConnID, flags.Lookup(flagDstConnID)); err != nil {
 		panic(err)
 	}
+	flags.String(flagSrcClientType, "", "light client type on source chain (tendermint, wasm, solomachine, localhost)")
+	if err := v.BindPFlag(flagSrcClientType, flags.Lookup(flagSrcClientType)); err != nil {
+		panic(err)
+	}
+	flags.String(flagDstClientType, "", "light client type on destination chain (tendermint, wasm, solomachine, localhost)")
+	if err := v.BindPFlag(flagDstClientType, flags.Lookup(flagDstClientType)); err != nil {
+		panic(err)
+	}
+	flags.String(flagSrcConnHops, blankValue, "connection hops of multihop channels opened on source chain, "+
+		`starting with its connection ID (or "" for single hop channels)`)
+	if err := v.BindPFlag(flagSrcConnHops, flags.Lookup(flagSrcConnHops)); err != nil {
+		panic(err)
+	}
+	flags.String(flagDstConnHops, blankValue, "connection hops of multihop channels opened on destination chain, "+
+		`starting with its connection ID (or "" for single hop channels)`)
+	if err := v.BindPFlag(flagDstConnHops, flags.Lookup(flagDstConnHops)); err != nil {
+		panic(err)
+	}
 	return cmd
 }
 
 func timeoutFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().StringP(flagTimeout, "t", "10s", "timeout between relayer runs")
+	cmd.Flags().StringP(flagTimeout, "t", relayer.DefaultHandshakeTxTimeout.String(),
+		"timeout for each message, overrides the handshake-tx-timeout of the path's retry-policy")
 	if err := v.BindPFlag(flagTimeout, cmd.Flags().Lookup(flagTimeout)); err != nil {
 		panic(err)
 	}
@@ -298,7 +421,8 @@ func getAddInputs(cmd *cobra.Command) (file string, url string, forceAdd bool, t
 }
 
 func retryFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().Uint64P(flagMaxRetries, "r", 3, "maximum retries after failed message send")
+	cmd.Flags().Uint64P(flagMaxRetries, "r", relayer.DefaultHandshakeRetries,
+		"maximum retries after failed message send, overrides the handshake-retries of the path's retry-policy")
 	if err := v.BindPFlag(flagMaxRetries, cmd.Flags().Lookup(flagMaxRetries)); err != nil {
 		panic(err)
 	}
@@ -335,6 +459,9 @@ func clientParameterFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	)
 	cmd.Flags().Duration(flagMaxClockDrift, (10 * time.Minute),
 		"custom max clock drift for client(s)")
+	cmd.Flags().String(flagTrustLevel, "1/3",
+		"trust level of the client(s), the fraction of the voting power of the trusted validators "+
+			"which must sign a header to update them, e.g. 1/3 or 2/3")
 
 	if err := v.BindPFlag(flagUpdateAfterExpiry, cmd.Flags().Lookup(flagUpdateAfterExpiry)); err != nil {
 		panic(err)
@@ -354,6 +481,9 @@ func clientParameterFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	if err := v.BindPFlag(flagClientTrustingPeriodPercentage, cmd.Flags().Lookup(flagClientTrustingPeriodPercentage)); err != nil {
 		panic(err)
 	}
+	if err := v.BindPFlag(flagTrustLevel, cmd.Flags().Lookup(flagTrustLevel)); err != nil {
+		panic(err)
+	}
 
 	return cmd
 }
@@ -362,6 +492,19 @@ func channelParameterFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	return srcPortFlag(v, dstPortFlag(v, versionFlag(v, orderFlag(v, cmd))))
 }
 
+// resumeChannelFlags names the channel ends of an interrupted channel handshake of the path to resume.
+func resumeChannelFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagSrcChannel, "", "channel on the src chain of an interrupted handshake of the path to resume")
+	cmd.Flags().String(flagDstChannel, "", "channel on the dst chain of an interrupted handshake of the path to resume")
+	if err := v.BindPFlag(flagSrcChannel, cmd.Flags().Lookup(flagSrcChannel)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagDstChannel, cmd.Flags().Lookup(flagDstChannel)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func clientUnbondingPeriodFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().Duration(
 		flagClientUnbondingPeriod,
@@ -378,7 +521,7 @@ func clientUnbondingPeriodFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Comman
 }
 
 func overrideFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().Bool(flagOverride, false, "option to not reuse existing client or channel")
+	cmd.Flags().Bool(flagOverride, false, "option to not reuse existing client, connection or channel")
 	if err := v.BindPFlag(flagOverride, cmd.Flags().Lookup(flagOverride)); err != nil {
 		panic(err)
 	}
@@ -432,6 +575,38 @@ func debugServerFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func controlAPIFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagControlAPI, false, "serve the control API used by 'rly debug shell' on the debug server, "+
+		"which allows to force client updates and packet retries; do not expose the debug address publicly")
+	if err := v.BindPFlag(flagControlAPI, cmd.Flags().Lookup(flagControlAPI)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func relayEventsSocketFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagRelayEventsSocket, "", "unix socket on which to stream the lifecycle of relayed packets "+
+		"and messages as newline delimited JSON, which is also streamed by the control API if enabled")
+	if err := v.BindPFlag(flagRelayEventsSocket, cmd.Flags().Lookup(flagRelayEventsSocket)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func govChannelFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagGovChain, "", "chain ID of the end of the path on which only governance may open channels, "+
+		"for which a proposal opening the channel is generated before continuing the handshake")
+	cmd.Flags().String(flagGovProposal, "", "file to write the channel open proposal to, instead of stdout")
+	cmd.Flags().String(flagGovDeposit, "", "deposit with which to submit the channel open proposal, e.g. 10000000uatom, "+
+		"otherwise the proposal is left to be submitted by the operator")
+	for _, flag := range []string{flagGovChain, flagGovProposal, flagGovDeposit} {
+		if err := v.BindPFlag(flag, cmd.Flags().Lookup(flag)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
 func processorFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().StringP(flagProcessor, "p", relayer.ProcessorEvents, "which relayer processor to use")
 	if err := v.BindPFlag(flagProcessor, cmd.Flags().Lookup(flagProcessor)); err != nil {
@@ -456,11 +631,11 @@ func initBlockFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 }
 
 func flushIntervalFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().DurationP(
+	cmd.Flags().StringP(
 		flagFlushInterval,
 		"i",
-		relayer.DefaultFlushInterval,
-		"how frequently should a flush routine be run",
+		relayer.DefaultFlushInterval.String(),
+		"how frequently should a flush routine be run, or 'auto' to flush every 50 blocks of the slower chain of the path",
 	)
 
 	if err := v.BindPFlag(flagFlushInterval, cmd.Flags().Lookup(flagFlushInterval)); err != nil {
@@ -470,6 +645,22 @@ func flushIntervalFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func packetSendFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagAmount, "", "amount to send in a MsgTransfer on the given port and channel, e.g. 100stake")
+	cmd.Flags().String(flagReceiver, "", "receiver address on the destination chain for a MsgTransfer, prefix with raw: to skip encoding")
+	cmd.Flags().String(flagMsg, "", "JSON encoded message which sends the packet, or @file to read it from a file; overrides --amount and --receiver")
+	if err := v.BindPFlag(flagAmount, cmd.Flags().Lookup(flagAmount)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagReceiver, cmd.Flags().Lookup(flagReceiver)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagMsg, cmd.Flags().Lookup(flagMsg)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func memoFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().String(flagMemo, "", "a memo to include in relayed packets")
 	if err := v.BindPFlag(flagMemo, cmd.Flags().Lookup(flagMemo)); err != nil {
@@ -503,6 +694,22 @@ func addOutputFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func balancesFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagBalances, false, "query the balance, account number and sequence of every key")
+	if err := v.BindPFlag(flagBalances, cmd.Flags().Lookup(flagBalances)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func snapshotOutputFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringP(flagOutput, "o", formatJson, "Specify the output format. Can be 'json' or 'yaml'.")
+	if err := v.BindPFlag(flagOutput, cmd.Flags().Lookup(flagOutput)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func stuckPacketFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().String(flagStuckPacketChainID, "", "chain ID with the stuck packet(s)")
 	if err := v.BindPFlag(flagStuckPacketChainID, cmd.Flags().Lookup(flagStuckPacketChainID)); err != nil {
@@ -519,6 +726,367 @@ func stuckPacketFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func fromHeightFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringToString(flagFromHeight, nil, "replay the events of chains from the given heights, "+
+		"e.g. chain-a=1200,chain-b=3400, overriding the initial block history")
+	if err := v.BindPFlag(flagFromHeight, cmd.Flags().Lookup(flagFromHeight)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func routeFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagRoute, "", "name of a configured route, or its chains, e.g. chain-a->chain-b->chain-c")
+	if err := v.BindPFlag(flagRoute, cmd.Flags().Lookup(flagRoute)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func sequentialFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagSequential, false, "transfer over a route one hop at a time through the relayer's own "+
+		"account on each intermediate chain, rather than with packet forward middleware")
+	if err := v.BindPFlag(flagSequential, cmd.Flags().Lookup(flagSequential)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func storeFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagStore, ibcexported.StoreKey, "store of the key, e.g. ibc, bank or acc")
+	if err := v.BindPFlag(flagStore, cmd.Flags().Lookup(flagStore)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func dryRunFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagDryRun, false, "print the changes without applying them")
+	if err := v.BindPFlag(flagDryRun, cmd.Flags().Lookup(flagDryRun)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func updateAllFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagAll, false, "update the clients of every configured path")
+	cmd.Flags().Duration(flagStale, 0, "only update the clients of paths with a client last updated longer ago than this, e.g. 12h")
+	if err := v.BindPFlag(flagAll, cmd.Flags().Lookup(flagAll)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagStale, cmd.Flags().Lookup(flagStale)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func updatePathFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagUpdatePath, false, "also open a new connection and channels on the new clients")
+	if err := v.BindPFlag(flagUpdatePath, cmd.Flags().Lookup(flagUpdatePath)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func recordSpendFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagRecordSpend, false, "record every broadcast tx to the local accounting database for use with 'rly q spend-report'")
+	if err := v.BindPFlag(flagRecordSpend, cmd.Flags().Lookup(flagRecordSpend)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func indexEventsFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagIndexEvents, false, "store observed packet events in the local index database and serve packet queries from it")
+	cmd.Flags().Uint64(flagIndexRetention, 0, "how many of the most recent blocks of a chain the packet events are "+
+		"kept in the index for when --"+flagIndexEvents+" is set, all of them if 0")
+	if err := v.BindPFlag(flagIndexEvents, cmd.Flags().Lookup(flagIndexEvents)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagIndexRetention, cmd.Flags().Lookup(flagIndexRetention)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func fromIndexFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagFromIndex, false, "query the local index database written by 'rly start --index-events' instead of the chains")
+	if err := v.BindPFlag(flagFromIndex, cmd.Flags().Lookup(flagFromIndex)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func noTxFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagNoTx, false, "monitor paths without signing or broadcasting any txs; keys are not required")
+	if err := v.BindPFlag(flagNoTx, cmd.Flags().Lookup(flagNoTx)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func skipRelayedFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagSkipRelayed, false, "check packet state on the destination chain immediately before broadcast "+
+		"and skip packets already relayed by another relayer, for redundant relayers serving the same path")
+	if err := v.BindPFlag(flagSkipRelayed, cmd.Flags().Lookup(flagSkipRelayed)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func sameBlockAcksFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagSameBlockAcks, false, "relay acknowledgements as soon as they can be proven, by querying "+
+		"the block after a packet is received as soon as it is produced, for the lowest end-to-end transfer time")
+	if err := v.BindPFlag(flagSameBlockAcks, cmd.Flags().Lookup(flagSameBlockAcks)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func txsPerBlockFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Int(flagTxsPerBlock, 0, "how many transactions are broadcast to each chain per block, allocated among "+
+		"the paths sharing the signer of the chain by their weights and packet backlogs (0 for no limit)")
+	if err := v.BindPFlag(flagTxsPerBlock, cmd.Flags().Lookup(flagTxsPerBlock)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func dedupEventsFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagDedupEvents, false, "remember the packet messages relayed in a database in the home directory, "+
+		"so that they are not relayed again after a restart")
+	cmd.Flags().Uint64(flagDedupRetention, processor.DefaultProcessedRetentionBlocks, "how many blocks of a chain "+
+		"a packet message relayed to the chain is remembered for when --"+flagDedupEvents+" is set")
+	if err := v.BindPFlag(flagDedupEvents, cmd.Flags().Lookup(flagDedupEvents)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagDedupRetention, cmd.Flags().Lookup(flagDedupRetention)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func standbyFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagStandby, false, "wait for another instance relaying the same paths from the same home "+
+		"directory to stop and take over, rather than refusing to start")
+	if err := v.BindPFlag(flagStandby, cmd.Flags().Lookup(flagStandby)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func validatorsFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagValidators, false, "include the validator sets of the headers")
+	if err := v.BindPFlag(flagValidators, cmd.Flags().Lookup(flagValidators)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func rpcDiscoveryFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagRPCDiscovery, "", fmt.Sprintf("source of RPC endpoints to switch a chain to when its "+
+		"RPC endpoint becomes unhealthy; supported: %s (default: disabled)", rpcDiscoveryChainRegistry))
+	cmd.Flags().Duration(flagRPCDiscoveryInterval, relayer.DefaultEndpointRefreshInterval,
+		"how often the health of the RPC endpoint of each chain is checked when --"+flagRPCDiscovery+" is set")
+	if err := v.BindPFlag(flagRPCDiscovery, cmd.Flags().Lookup(flagRPCDiscovery)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagRPCDiscoveryInterval, cmd.Flags().Lookup(flagRPCDiscoveryInterval)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func gossipFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagGossipAddr, "", "address to serve gossip with the relayer set on, enabling gossip of the "+
+		"packets each relayer intends to relay to avoid duplicate txs (default: disabled)")
+	cmd.Flags().String(flagGossipURL, "", "URL of the gossip server of this relayer advertised to the relayer set, "+
+		"e.g. http://relayer-2:5184 (default: http://<hostname>:<port of --"+flagGossipAddr+">)")
+	cmd.Flags().StringSlice(flagGossipBootstrap, nil, "URLs of the gossip servers of relayers from which the relayer set "+
+		"is discovered, e.g. http://relayer-1:5184")
+	cmd.Flags().Duration(flagGossipClaimTTL, gossip.DefaultClaimTTL, "how long packets claimed by a relayer are "+
+		"left to it by the relayer set")
+	cmd.Flags().String(flagGossipKey, "", "file holding the ed25519 key which identifies this relayer and signs its "+
+		"packet claims, generated if it does not exist (default: "+gossip.DefaultKeyFile+" in the home directory)")
+	cmd.Flags().StringSlice(flagGossipTrustedPeers, nil, "IDs of the relayers of the relayer set, whose packet claims "+
+		"and gossip servers are accepted, i.e. their hex encoded ed25519 public keys (required with --"+flagGossipAddr+")")
+	for _, flag := range []string{flagGossipAddr, flagGossipURL, flagGossipBootstrap, flagGossipClaimTTL, flagGossipKey, flagGossipTrustedPeers} {
+		if err := v.BindPFlag(flag, cmd.Flags().Lookup(flag)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
+func clientParamsIntervalFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagClientParamsInterval, relayer.DefaultClientParamsCheckInterval, "how often the trust "+
+		"level, trusting period and unbonding period of the clients of the paths are checked against the chains "+
+		"they track and the path config, warning of unsafe or drifted client parameters; 0 to disable")
+	if err := v.BindPFlag(flagClientParamsInterval, cmd.Flags().Lookup(flagClientParamsInterval)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func feeClaimIntervalFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagFeeClaimInterval, relayer.DefaultFeeClaimInterval, "how often the ICS-29 fees paid "+
+		"to the fee-payee-key of each chain are claimed to the key of the chain; 0 to disable")
+	if err := v.BindPFlag(flagFeeClaimInterval, cmd.Flags().Lookup(flagFeeClaimInterval)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func maxDurationFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagMaxDuration, relayer.DefaultRelayOnceMaxDuration, "how long to relay before giving up "+
+		"on the pending messages; 0 to relay until they are all relayed")
+	if err := v.BindPFlag(flagMaxDuration, cmd.Flags().Lookup(flagMaxDuration)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func generateOnlyFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagGenerateOnly, false, "write the first tx to stdout unsigned, as JSON, instead of signing "+
+		"and broadcasting it, to be signed offline and broadcast with 'rly tx broadcast-signed'")
+	if err := v.BindPFlag(flagGenerateOnly, cmd.Flags().Lookup(flagGenerateOnly)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func benchFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Int(flagPackets, relayer.DefaultBenchPackets, "number of packets to send")
+	cmd.Flags().Int(flagBatchSize, relayer.DefaultBenchBatchSize, "number of packets to send per tx")
+	cmd.Flags().Duration(flagInterval, relayer.DefaultBenchInterval, "interval between the txs sending packets")
+	cmd.Flags().Duration(flagBlockTime, relayer.DefaultBenchBlockTime, "block time of the chains")
+	cmd.Flags().Duration(flagMaxDuration, relayer.DefaultBenchMaxDuration, "how long to relay the packets for, "+
+		"from the first one sent")
+	for _, flag := range []string{flagPackets, flagBatchSize, flagInterval, flagBlockTime, flagMaxDuration} {
+		if err := v.BindPFlag(flag, cmd.Flags().Lookup(flag)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
+func portFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagPort, "transfer", "port of the channel")
+	if err := v.BindPFlag(flagPort, cmd.Flags().Lookup(flagPort)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func packetMemoFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagPacketMemo, "", "a memo to include in the ICS-20 packet, e.g. for packet forward "+
+		"middleware or IBC hooks, unlike --memo which is the memo of the transaction")
+	if err := v.BindPFlag(flagPacketMemo, cmd.Flags().Lookup(flagPacketMemo)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func unwindFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagUnwind, false, "return an IBC denom to its origin chain along the reverse of its denom trace, "+
+		"forwarding with a packet forward middleware memo over multiple hops")
+	if err := v.BindPFlag(flagUnwind, cmd.Flags().Lookup(flagUnwind)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func sinceFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagSince, "30d", "report on txs broadcast within this period, e.g. 12h, 7d")
+	if err := v.BindPFlag(flagSince, cmd.Flags().Lookup(flagSince)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func testnetFlags(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Int(flagTestnetChains, 2, "number of chains of the testnet, with a path between each consecutive pair")
+	cmd.Flags().String(flagTestnetDir, "testnet", "directory to scaffold the testnet in, which must be empty")
+	cmd.Flags().String(flagTestnetImage, testnet.DefaultImage, "docker image of the chains")
+	cmd.Flags().String(flagTestnetBinary, testnet.DefaultBinary, "chain binary in the docker image")
+	if err := v.BindPFlag(flagTestnetChains, cmd.Flags().Lookup(flagTestnetChains)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagTestnetDir, cmd.Flags().Lookup(flagTestnetDir)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagTestnetImage, cmd.Flags().Lookup(flagTestnetImage)); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlag(flagTestnetBinary, cmd.Flags().Lookup(flagTestnetBinary)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func forceBisectionFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagForceBisection, false, "update clients which cannot be updated to the latest height "+
+		"in one update, because the validator set rotated too much since their trusted height, "+
+		"through intermediate heights")
+	if err := v.BindPFlag(flagForceBisection, cmd.Flags().Lookup(flagForceBisection)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func refreshIntervalFlag(v *viper.Viper, cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagRefreshInterval, 5*time.Second, "how often to refresh the dashboard")
+	if err := v.BindPFlag(flagRefreshInterval, cmd.Flags().Lookup(flagRefreshInterval)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// parseFromHeightsFromFlags returns the heights by chain ID from which to replay the events of the chains,
+// which must be among the relayed chains.
+func parseFromHeightsFromFlags(cmd *cobra.Command, chains map[string]*relayer.Chain) (map[string]uint64, error) {
+	fromHeights, err := cmd.Flags().GetStringToString(flagFromHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	heights := make(map[string]uint64, len(fromHeights))
+	for chainID, h := range fromHeights {
+		if _, ok := chains[chainID]; !ok {
+			return nil, fmt.Errorf("invalid --%s: chain %s is not relayed", flagFromHeight, chainID)
+		}
+		height, err := strconv.ParseUint(h, 10, 64)
+		if err != nil || height == 0 {
+			return nil, fmt.Errorf("invalid --%s height for chain %s: %q", flagFromHeight, chainID, h)
+		}
+		heights[chainID] = height
+	}
+	return heights, nil
+}
+
+// flushIntervalAuto is the value of the flush interval flag which selects processor.AdaptiveFlushInterval.
+const flushIntervalAuto = "auto"
+
+func parseFlushIntervalFromFlags(cmd *cobra.Command) (time.Duration, error) {
+	flushInterval, err := cmd.Flags().GetString(flagFlushInterval)
+	if err != nil {
+		return 0, err
+	}
+
+	if flushInterval == flushIntervalAuto {
+		return processor.AdaptiveFlushInterval, nil
+	}
+
+	d, err := time.ParseDuration(flushInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid flush interval %q: %w", flushInterval, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid flush interval %q: must not be negative", flushInterval)
+	}
+	return d, nil
+}
+
 func parseStuckPacketFromFlags(cmd *cobra.Command) (*processor.StuckPacket, error) {
 	stuckPacketChainID, err := cmd.Flags().GetString(flagStuckPacketChainID)
 	if err != nil {
@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +24,57 @@ func TestFlagEqualityAgainstSDK(t *testing.T) {
 	require.Equal(t, flagCountTotal, flags.FlagCountTotal)
 	require.Equal(t, flagReverse, flags.FlagReverse)
 }
+
+func TestParseFromHeights(t *testing.T) {
+	chains := map[string]*relayer.Chain{"chain-a": nil, "chain-b": nil}
+	parse := func(args ...string) (map[string]uint64, error) {
+		cmd := fromHeightFlag(viper.New(), &cobra.Command{})
+		require.NoError(t, cmd.ParseFlags(args))
+		return parseFromHeightsFromFlags(cmd, chains)
+	}
+
+	heights, err := parse()
+	require.NoError(t, err)
+	require.Empty(t, heights)
+
+	heights, err = parse("--from-height", "chain-a=1200,chain-b=3400")
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"chain-a": 1200, "chain-b": 3400}, heights)
+
+	_, err = parse("--from-height", "chain-c=1200")
+	require.ErrorContains(t, err, "chain chain-c is not relayed")
+
+	for _, invalid := range []string{"chain-a=0", "chain-a=-5", "chain-a=latest"} {
+		_, err = parse("--from-height", invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestParseFlushInterval(t *testing.T) {
+	parse := func(args ...string) (time.Duration, error) {
+		cmd := flushIntervalFlag(viper.New(), &cobra.Command{})
+		require.NoError(t, cmd.ParseFlags(args))
+		return parseFlushIntervalFromFlags(cmd)
+	}
+
+	d, err := parse()
+	require.NoError(t, err)
+	require.Equal(t, relayer.DefaultFlushInterval, d)
+
+	d, err = parse("--flush-interval", "90s")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, d)
+
+	d, err = parse("--flush-interval", "0")
+	require.NoError(t, err)
+	require.Zero(t, d)
+
+	d, err = parse("--flush-interval", "auto")
+	require.NoError(t, err)
+	require.Equal(t, processor.AdaptiveFlushInterval, d)
+
+	for _, invalid := range []string{"-1m", "5", "often"} {
+		_, err = parse("--flush-interval", invalid)
+		require.Error(t, err, invalid)
+	}
+}
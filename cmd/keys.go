@@ -17,13 +17,23 @@ limitations under the License.
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+	"text/tabwriter"
 
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/input"
+	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -33,6 +43,7 @@ const (
 	flagCoinType           = "coin-type"
 	flagAlgo               = "signing-algorithm"
 	flagRestoreAll         = "restore-all"
+	flagArmor              = "armor"
 	defaultCoinType uint32 = sdk.CoinType
 )
 
@@ -51,7 +62,10 @@ func keysCmd(a *appState) *cobra.Command {
 		keysDeleteCmd(a),
 		keysListCmd(a),
 		keysExportCmd(a),
+		keysImportCmd(a),
 		keysShowCmd(a),
+		keysProveCmd(a),
+		keysVerifyProofCmd(a),
 	)
 
 	return cmd
@@ -103,8 +117,8 @@ $ %s k a cosmoshub testkey`, appName, appName, appName)),
 			}
 
 			if coinType < 0 {
-				if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok && ccp.PCfg.Slip44 != nil {
-					coinType = int32(*ccp.PCfg.Slip44)
+				if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+					coinType = int32(ccp.PCfg.DefaultCoinType())
 				} else {
 					coinType = int32(defaultCoinType)
 				}
@@ -138,7 +152,7 @@ $ %s k a cosmoshub testkey`, appName, appName, appName)),
 		},
 	}
 	cmd.Flags().Int32(flagCoinType, -1, "coin type number for HD derivation")
-	cmd.Flags().String(flagAlgo, "", "signing algorithm for key (secp256k1, sr25519)")
+	cmd.Flags().String(flagAlgo, "", "signing algorithm for key (secp256k1, sr25519, eth_secp256k1)")
 
 	return cmd
 }
@@ -181,8 +195,8 @@ $ %s k r demo-key "[mnemonic-words]" --restore-all`, appName, appName, appName))
 				}
 
 				if coinType < 0 {
-					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok && ccp.PCfg.Slip44 != nil {
-						coinType = int32(*ccp.PCfg.Slip44)
+					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+						coinType = int32(ccp.PCfg.DefaultCoinType())
 					} else {
 						coinType = int32(defaultCoinType)
 					}
@@ -227,8 +241,8 @@ $ %s k r demo-key "[mnemonic-words]" --restore-all`, appName, appName, appName))
 				}
 
 				if coinType < 0 {
-					if ccp, ok := c.ChainProvider.(*cosmos.CosmosProvider); ok && ccp.PCfg.Slip44 != nil {
-						coinType = int32(*ccp.PCfg.Slip44)
+					if ccp, ok := c.ChainProvider.(*cosmos.CosmosProvider); ok {
+						coinType = int32(ccp.PCfg.DefaultCoinType())
 					} else {
 						coinType = int32(defaultCoinType)
 					}
@@ -259,7 +273,7 @@ $ %s k r demo-key "[mnemonic-words]" --restore-all`, appName, appName, appName))
 		},
 	}
 	cmd.Flags().Int32(flagCoinType, -1, "coin type number for HD derivation")
-	cmd.Flags().String(flagAlgo, "", "signing algorithm for key (secp256k1, sr25519)")
+	cmd.Flags().String(flagAlgo, "", "signing algorithm for key (secp256k1, sr25519, eth_secp256k1)")
 	cmd.Flags().Bool(flagRestoreAll, false, "restores keys for all configured chains with a single mnemonic")
 
 	return cmd
@@ -329,51 +343,202 @@ func askForConfirmation(a *appState, stdin io.Reader, stderr io.Writer) bool {
 // keysListCmd represents the `keys list` command
 func keysListCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "list chain_name",
+		Use:     "list [chain_name]",
 		Aliases: []string{"l"},
-		Short:   "Lists keys from the keychain associated with a particular chain",
-		Args:    withUsage(cobra.ExactArgs(1)),
+		Short:   "Lists keys from the keychain associated with a particular chain, or with every chain",
+		Long: `Lists keys from the keychain associated with a particular chain, or with every configured chain
+if no chain is given. With --balances, the balance, account number and sequence of every key are
+queried from the chains concurrently, e.g. for an overview of the wallets of the relayer.`,
+		Args: withUsage(cobra.RangeArgs(0, 1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s keys list ibc-0
-$ %s k l ibc-1`, appName, appName)),
+$ %s k l ibc-1
+$ %s keys list --balances
+$ %s keys list --balances --output json`, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			chainName := args[0]
-
-			chain, ok := a.config.Chains[chainName]
-			if !ok {
-				return errChainNotFound(chainName)
+			var chainNames []string
+			if len(args) == 1 {
+				if _, ok := a.config.Chains[args[0]]; !ok {
+					return errChainNotFound(args[0])
+				}
+				chainNames = args
+			} else {
+				for chainName := range a.config.Chains {
+					chainNames = append(chainNames, chainName)
+				}
+				sort.Strings(chainNames)
 			}
 
-			info, err := chain.ChainProvider.ListAddresses()
+			balances, err := cmd.Flags().GetBool(flagBalances)
 			if err != nil {
 				return err
 			}
+			output, _ := cmd.Flags().GetString(flagOutput)
+
+			if len(args) == 1 && !balances && output != formatJson {
+				return printKeys(cmd, args[0], a.config.Chains[args[0]])
+			}
 
-			if len(info) == 0 {
-				fmt.Fprintf(cmd.ErrOrStderr(), "warning: no keys found for chain %s (do you need to run 'rly keys add %s'?)\n", chainName, chainName)
+			overviews, err := keysOverview(cmd, a.config.Chains, chainNames, balances)
+			if err != nil {
+				return err
 			}
 
-			for key, val := range info {
-				fmt.Fprintf(cmd.OutOrStdout(), "key(%s) -> %s\n", key, val)
+			if output == formatJson {
+				out, err := json.Marshal(overviews)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
 			}
 
-			return nil
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			if balances {
+				fmt.Fprintln(tw, "CHAIN\tKEY\tADDRESS\tACCOUNT\tSEQUENCE\tBALANCE")
+			} else {
+				fmt.Fprintln(tw, "CHAIN\tKEY\tADDRESS")
+			}
+			for _, o := range overviews {
+				switch {
+				case !balances:
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", o.Chain, o.Key, o.Address)
+				case o.keyAccount == nil:
+					fmt.Fprintf(tw, "%s\t%s\t%s\t-\t-\terror: %s\n", o.Chain, o.Key, o.Address, o.Error)
+				default:
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n",
+						o.Chain, o.Key, o.Address, o.AccountNumber, o.Sequence, o.Balance)
+				}
+			}
+			return tw.Flush()
 		},
 	}
 
+	cmd = balancesFlag(a.viper, cmd)
+	cmd = addOutputFlag(a.viper, cmd)
 	return cmd
 }
 
+// printKeys prints the keys of a chain in the legacy format.
+func printKeys(cmd *cobra.Command, chainName string, chain *relayer.Chain) error {
+	info, err := chain.ChainProvider.ListAddresses()
+	if err != nil {
+		return err
+	}
+
+	if len(info) == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: no keys found for chain %s (do you need to run 'rly keys add %s'?)\n", chainName, chainName)
+	}
+
+	for key, val := range info {
+		fmt.Fprintf(cmd.OutOrStdout(), "key(%s) -> %s\n", key, val)
+	}
+
+	return nil
+}
+
+// keyOverview is a key of a chain, along with the state of its account if queried.
+type keyOverview struct {
+	Chain   string `json:"chain"`
+	Key     string `json:"key"`
+	Address string `json:"address"`
+
+	*keyAccount
+
+	Error string `json:"error,omitempty"`
+}
+
+// keyAccount is the state of the account of a key on its chain.
+type keyAccount struct {
+	Balance       string `json:"balance"`
+	AccountNumber uint64 `json:"account_number"`
+	// Sequence is the sequence of the next tx signed by the account.
+	Sequence uint64 `json:"sequence"`
+}
+
+// keysOverview lists the keys of the chains, sorted by chain and key name. With balances, the accounts of the keys
+// are queried from the chains concurrently. Accounts which fail to be queried are reported with an error.
+func keysOverview(cmd *cobra.Command, chains relayer.Chains, chainNames []string, balances bool) ([]keyOverview, error) {
+	perChain := make([][]keyOverview, len(chainNames))
+	for i, chainName := range chainNames {
+		info, err := chains[chainName].ChainProvider.ListAddresses()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list keys of chain %s: %w", chainName, err)
+		}
+		if len(info) == 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: no keys found for chain %s (do you need to run 'rly keys add %s'?)\n", chainName, chainName)
+		}
+		for key, addr := range info {
+			perChain[i] = append(perChain[i], keyOverview{Chain: chainName, Key: key, Address: addr})
+		}
+		sort.Slice(perChain[i], func(j, k int) bool { return perChain[i][j].Key < perChain[i][k].Key })
+	}
+
+	if balances {
+		var wg sync.WaitGroup
+		for i, chainName := range chainNames {
+			wg.Add(1)
+			go func(chain *relayer.Chain, overviews []keyOverview) {
+				defer wg.Done()
+				for j := range overviews {
+					account, err := queryKeyAccount(cmd.Context(), chain, overviews[j].Address)
+					if err != nil {
+						overviews[j].Error = err.Error()
+						continue
+					}
+					overviews[j].keyAccount = account
+				}
+			}(chains[chainName], perChain[i])
+		}
+		wg.Wait()
+	}
+
+	var overviews []keyOverview
+	for _, o := range perChain {
+		overviews = append(overviews, o...)
+	}
+	return overviews, nil
+}
+
+// queryKeyAccount queries the balance of the account with address, and for cosmos chains its account number and
+// sequence.
+func queryKeyAccount(ctx context.Context, chain *relayer.Chain, address string) (*keyAccount, error) {
+	coins, err := chain.ChainProvider.QueryBalanceWithAddress(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balance: %w", err)
+	}
+	account := &keyAccount{Balance: coins.String()}
+
+	ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return account, nil
+	}
+	addr, err := ccp.DecodeBech32AccAddr(address)
+	if err != nil {
+		return nil, err
+	}
+	account.AccountNumber, account.Sequence, err = ccp.GetAccountNumberSequence(client.Context{}, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account: %w", err)
+	}
+	return account, nil
+}
+
 // keysExportCmd represents the `keys export` command
 func keysExportCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "export chain_name key_name",
 		Aliases: []string{"e"},
 		Short:   "Exports a privkey from the keychain associated with a particular chain",
-		Args:    withUsage(cobra.ExactArgs(2)),
+		Long: `Exports a privkey from the keychain associated with a particular chain in the encrypted ASCII armor format
+of the Cosmos SDK keyring. Unless --armor is set, the armor is encrypted with the default passphrase "` + ckeys.DefaultKeyPass + `".
+With --armor, a passphrase to encrypt it with is read from the terminal, and the armor can be imported
+with the same passphrase by rly keys import, or e.g. gaiad keys import.`,
+		Args: withUsage(cobra.ExactArgs(2)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s keys export ibc-0 testkey
-$ %s k e cosmoshub testkey`, appName, appName)),
+$ %s k e cosmoshub testkey
+$ %s keys export cosmoshub testkey --armor > testkey.armor`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			keyName := args[1]
 			chain, ok := a.config.Chains[args[0]]
@@ -385,7 +550,16 @@ $ %s k e cosmoshub testkey`, appName, appName)),
 				return errKeyDoesntExist(keyName)
 			}
 
-			info, err := chain.ChainProvider.ExportPrivKeyArmor(keyName)
+			passphrase := ckeys.DefaultKeyPass
+			if armor, _ := cmd.Flags().GetBool(flagArmor); armor {
+				var err error
+				passphrase, err = readPassphrase(bufio.NewReader(cmd.InOrStdin()), true)
+				if err != nil {
+					return err
+				}
+			}
+
+			info, err := chain.ChainProvider.ExportPrivKeyArmor(keyName, passphrase)
 			if err != nil {
 				return err
 			}
@@ -394,10 +568,165 @@ $ %s k e cosmoshub testkey`, appName, appName)),
 			return nil
 		},
 	}
+	cmd.Flags().Bool(flagArmor, false, "encrypt the exported armor with a passphrase read from the terminal")
+
+	return cmd
+}
+
+// keysImportCmd represents the `keys import` command
+func keysImportCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "import chain_name key_name armor_file",
+		Aliases: []string{"i"},
+		Short:   "Imports a privkey in encrypted ASCII armor format to the keychain associated with a particular chain",
+		Long: `Imports a privkey in the encrypted ASCII armor format of the Cosmos SDK keyring, e.g. exported by
+rly keys export or gaiad keys export, to the keychain associated with a particular chain.
+The passphrase the armor is encrypted with is read from the terminal.`,
+		Args: withUsage(cobra.ExactArgs(3)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys import cosmoshub testkey testkey.armor
+$ %s k i osmosis testkey testkey.armor`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyName := args[1]
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+
+			if chain.ChainProvider.KeyExists(keyName) {
+				return errKeyExists(keyName)
+			}
+
+			armor, err := os.ReadFile(args[2])
+			if err != nil {
+				return fmt.Errorf("failed to read armor file: %w", err)
+			}
+
+			passphrase, err := readPassphrase(bufio.NewReader(cmd.InOrStdin()), false)
+			if err != nil {
+				return err
+			}
+
+			address, err := chain.ChainProvider.ImportPrivKeyArmor(keyName, string(armor), passphrase)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), address)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// keysProveCmd represents the `keys prove` command
+func keysProveCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prove chain_name challenge [key_name]",
+		Short: "Proves the ownership of the address of a key by signing a challenge with it",
+		Long: `Signs a challenge, e.g. issued by a counterparty team or an incentive program, with the configured key of
+the chain, or the given key, and outputs a proof of the ownership of its address as JSON. The signature is an ADR-036
+off-chain signature, which can be verified with rly keys verify-proof, or by wallets and libraries supporting them.`,
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys prove cosmoshub "relayer-registration-2f9c1a" > proof.json
+$ %s keys prove osmosis "relayer-registration-2f9c1a" testkey`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			cp, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+			if !ok {
+				return fmt.Errorf("address proofs are only supported on cosmos chains")
+			}
+
+			keyName := cp.Key()
+			if len(args) == 3 {
+				keyName = args[2]
+			}
+			if !cp.KeyExists(keyName) {
+				return errKeyDoesntExist(keyName)
+			}
+
+			proof, err := cp.ProveAddress(keyName, args[1])
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(proof, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
 
 	return cmd
 }
 
+// keysVerifyProofCmd represents the `keys verify-proof` command
+func keysVerifyProofCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-proof chain_name proof_file",
+		Short: "Verifies a proof of the ownership of an address output by rly keys prove",
+		Args:  withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys verify-proof cosmoshub proof.json`, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			cp, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+			if !ok {
+				return fmt.Errorf("address proofs are only supported on cosmos chains")
+			}
+
+			bz, err := os.ReadFile(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to read proof file: %w", err)
+			}
+			var proof cosmos.AddressProof
+			if err := json.Unmarshal(bz, &proof); err != nil {
+				return fmt.Errorf("failed to parse proof file: %w", err)
+			}
+
+			if err := cp.VerifyAddressProof(proof); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s owns %s\n", proof.Address, args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// readPassphrase reads a passphrase for an armored key from the terminal, or from in if stdin is not a terminal.
+// If confirm is set, the passphrase is read twice and must match.
+func readPassphrase(in *bufio.Reader, confirm bool) (string, error) {
+	passphrase, err := input.GetPassword("Enter the passphrase of the armor:", in)
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return passphrase, nil
+	}
+
+	repeated, err := input.GetPassword("Repeat the passphrase:", in)
+	if err != nil {
+		return "", err
+	}
+	if passphrase != repeated {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
 // ShowAddressByChainAndKey represents the logic for showing relayer address by chain_name and key_name
 func (a *appState) showAddressByChainAndKey(cmd *cobra.Command, args []string) error {
 	chain, ok := a.config.Chains[args[0]]
@@ -1,6 +1,9 @@
 package cmd_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/cosmos/cosmos-sdk/client/keys"
@@ -12,6 +15,7 @@ import (
 	"github.com/cosmos/relayer/v2/internal/relayertest"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestKeysList_Empty(t *testing.T) {
@@ -117,6 +121,47 @@ func TestKeysExport(t *testing.T) {
 	// TODO: confirm the imported address matches?
 }
 
+func TestKeysImportArmor(t *testing.T) {
+	t.Parallel()
+
+	sys := relayertest.NewSystem(t)
+
+	_ = sys.MustRun(t, "config", "init")
+
+	for chainName, chainID := range map[string]string{"testChain": "testcosmos-1", "testChain2": "testcosmos-2"} {
+		sys.MustAddChain(t, chainName, cmd.ProviderConfigWrapper{
+			Type: "cosmos",
+			Value: cosmos.CosmosProviderConfig{
+				AccountPrefix:  "cosmos",
+				ChainID:        chainID,
+				KeyringBackend: "test",
+				Timeout:        "10s",
+			},
+		})
+	}
+
+	res := sys.MustRun(t, "keys", "restore", "testChain", "default", relayertest.ZeroMnemonic)
+	require.Equal(t, res.Stdout.String(), relayertest.ZeroCosmosAddr+"\n")
+
+	// Export the key encrypted with a chosen passphrase.
+	res = sys.MustRunWithInput(t, strings.NewReader("correct horse\ncorrect horse\n"), "keys", "export", "testChain", "default", "--armor")
+	armorOut := res.Stdout.String()
+	require.Contains(t, armorOut, "BEGIN TENDERMINT PRIVATE KEY")
+
+	armorFile := filepath.Join(t.TempDir(), "default.armor")
+	require.NoError(t, os.WriteFile(armorFile, []byte(armorOut), 0600))
+
+	// The armor cannot be imported with the wrong passphrase.
+	res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader("wrong passphrase\n"), "keys", "import", "testChain2", "default", armorFile)
+	require.Error(t, res.Err)
+
+	res = sys.MustRunWithInput(t, strings.NewReader("correct horse\n"), "keys", "import", "testChain2", "default", armorFile)
+	require.Equal(t, res.Stdout.String(), relayertest.ZeroCosmosAddr+"\n")
+
+	res = sys.MustRun(t, "keys", "show", "testChain2", "default")
+	require.Equal(t, res.Stdout.String(), relayertest.ZeroCosmosAddr+"\n")
+}
+
 func TestKeysDefaultCoinType(t *testing.T) {
 	t.Parallel()
 
@@ -240,6 +285,17 @@ func TestKeysRestoreAll_Delete(t *testing.T) {
 	require.Equal(t, res.Stdout.String(), "key(default) -> "+relayertest.ZeroCosmosAddr+"\n")
 	require.Empty(t, res.Stderr.String())
 
+	// Without a chain, the keys of every chain are listed.
+	res = sys.MustRun(t, "keys", "list")
+	require.Equal(t, res.Stdout.String(), "CHAIN       KEY      ADDRESS\n"+
+		"testChain   default  "+relayertest.ZeroCosmosAddr+"\n"+
+		"testChain2  default  "+relayertest.ZeroCosmosAddr+"\n"+
+		"testChain3  default  "+relayertest.ZeroCosmosAddr+"\n")
+	require.Empty(t, res.Stderr.String())
+
+	res = sys.MustRun(t, "keys", "list", "testChain2", "--output", "json")
+	require.JSONEq(t, `[{"chain":"testChain2","key":"default","address":"`+relayertest.ZeroCosmosAddr+`"}]`, res.Stdout.String())
+
 	// Deleting the key must succeed.
 	res = sys.MustRun(t, "keys", "delete", "testChain", "default", "-y")
 	require.Empty(t, res.Stdout.String())
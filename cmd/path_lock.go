@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/gofrs/flock"
+	"go.uber.org/zap"
+)
+
+// standbyRetryInterval is how often a standby instance tries to take over the paths.
+const standbyRetryInterval = 5 * time.Second
+
+// pathLocks are the locks of the paths which an instance relays, held while it runs so that a second instance
+// started against the same home directory does not relay the same paths twice.
+type pathLocks struct {
+	locks []*flock.Flock
+}
+
+func (a *appState) pathLockPath(pathName string) string {
+	return path.Join(a.homePath, "locks", url.PathEscape(pathName)+".lock")
+}
+
+// tryLockPaths takes the locks of all the paths, or none of them. It returns the paths locked by another instance,
+// with the owner of their lock, if any.
+func (a *appState) tryLockPaths(paths []relayer.NamedPath) (*pathLocks, []string, error) {
+	if err := os.MkdirAll(path.Join(a.homePath, "locks"), 0o700); err != nil {
+		return nil, nil, err
+	}
+
+	l := &pathLocks{}
+	var held []string
+	for _, p := range paths {
+		lock := flock.New(a.pathLockPath(p.Name))
+		locked, err := lock.TryLock()
+		if err != nil {
+			l.unlock()
+			return nil, nil, fmt.Errorf("failed to lock path %s: %w", p.Name, err)
+		}
+		if !locked {
+			held = append(held, fmt.Sprintf("%s (%s)", p.Name, lockOwner(lock.Path())))
+			continue
+		}
+		l.locks = append(l.locks, lock)
+	}
+	if len(held) > 0 {
+		l.unlock()
+		return nil, held, nil
+	}
+
+	for _, lock := range l.locks {
+		a.writeLockOwner(lock.Path())
+	}
+	return l, nil, nil
+}
+
+// lockPaths takes the locks of all the paths. Unless standby is set, it fails if another instance holds any of them.
+// Otherwise, it waits for the other instance to stop until ctx is done.
+func (a *appState) lockPaths(ctx context.Context, paths []relayer.NamedPath, standby bool) (*pathLocks, error) {
+	ticker := time.NewTicker(standbyRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		l, held, err := a.tryLockPaths(paths)
+		if err != nil {
+			return nil, err
+		}
+		if l != nil {
+			return l, nil
+		}
+		if !standby {
+			return nil, fmt.Errorf("paths are relayed by another instance from home %s: %s, "+
+				"stop it or start this instance with --%s to take over once it stops",
+				a.homePath, strings.Join(held, ", "), flagStandby)
+		}
+
+		a.log.Info(
+			"Waiting for another instance to stop relaying paths",
+			zap.Strings("paths", held),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// unlock releases the locks of the paths.
+func (l *pathLocks) unlock() {
+	for _, lock := range l.locks {
+		_ = os.Remove(lockOwnerPath(lock.Path()))
+		_ = lock.Unlock()
+	}
+	l.locks = nil
+}
+
+func lockOwnerPath(lockPath string) string {
+	return strings.TrimSuffix(lockPath, ".lock") + ".owner"
+}
+
+// writeLockOwner records the instance holding the lock, for the errors of other instances.
+// The lock itself is held by the flock, so failing to record its owner is only logged.
+func (a *appState) writeLockOwner(lockPath string) {
+	owner := fmt.Sprintf("pid %d since %s", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(lockOwnerPath(lockPath), []byte(owner), 0o600); err != nil {
+		a.log.Warn(
+			"Failed to record owner of path lock",
+			zap.String("filepath", lockPath),
+			zap.Error(err),
+		)
+	}
+}
+
+func lockOwner(lockPath string) string {
+	bz, err := os.ReadFile(lockOwnerPath(lockPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "unknown owner"
+		}
+		return err.Error()
+	}
+	return strings.TrimSpace(string(bz))
+}
@@ -236,10 +236,14 @@ func pathsNewCmd(a *appState) *cobra.Command {
 		Use:     "new src_chain_id dst_chain_id path_name",
 		Aliases: []string{"n"},
 		Short:   "Create a new blank path to be used in generating a new path (connection & client) between two chains",
-		Args:    withUsage(cobra.ExactArgs(3)),
+		Long: strings.TrimSpace(`Create a new blank path to be used in generating a new path (connection & client)
+between two chains. A path from a chain to itself relays between the modules of the chain over its 09-localhost
+client and connection, which need not be created.`),
+		Args: withUsage(cobra.ExactArgs(3)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s paths new ibc-0 ibc-1 demo-path
-$ %s pth n ibc-0 ibc-1 demo-path`, appName, appName)),
+$ %s pth n ibc-0 ibc-1 demo-path
+$ %s paths new ibc-0 ibc-0 localhost-path # localhost path`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			src, dst := args[0], args[1]
 
@@ -253,6 +257,9 @@ $ %s pth n ibc-0 ibc-1 demo-path`, appName, appName)),
 					Src: &relayer.PathEnd{ChainID: src},
 					Dst: &relayer.PathEnd{ChainID: dst},
 				}
+				if src == dst {
+					p = relayer.LocalhostPath(src)
+				}
 
 				name := args[2]
 				if err = a.config.AddPath(name, p); err != nil {
@@ -269,15 +276,20 @@ func pathsUpdateCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "update path_name",
 		Aliases: []string{"n"},
-		Short:   `Update a path such as the filter rule ("allowlist", "denylist", or "" for no filtering), filter channels, and src/dst chain, client, or connection IDs`,
+		Short:   `Update a path such as the filter rule ("allowlist", "denylist", or "" for no filtering), filter channels, priority channels, ICS-20 memo limit, and src/dst chain, client, or connection IDs`,
 		Args:    withUsage(cobra.ExactArgs(1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s paths update demo-path --filter-rule allowlist --filter-channels channel-0,channel-1
 $ %s paths update demo-path --filter-rule denylist --filter-channels channel-0,channel-1
+$ %s paths update demo-path --priority-channels channel-0
+$ %s paths update demo-path --ics20-memo-limit 256
+$ %s paths update demo-path --max-in-flight-txs 4 --workers-per-path 8 --query-concurrency 16
+$ %s paths update demo-path --weight 3
 $ %s paths update demo-path --src-chain-id chain-1 --dst-chain-id chain-2
 $ %s paths update demo-path --src-client-id 07-tendermint-02 --dst-client-id 07-tendermint-04
-$ %s paths update demo-path --src-connection-id connection-02 --dst-connection-id connection-04`,
-			appName, appName, appName, appName, appName)),
+$ %s paths update demo-path --src-connection-id connection-02 --dst-connection-id connection-04
+$ %s paths update demo-path --src-connection-hops connection-02,connection-7 --dst-connection-hops connection-04,connection-9`,
+			appName, appName, appName, appName, appName, appName, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 
@@ -311,6 +323,18 @@ $ %s paths update demo-path --src-connection-id connection-02 --dst-connection-i
 					actionTaken = true
 				}
 
+				priorityChannels, _ := flags.GetString(flagPriorityChannels)
+				if priorityChannels != blankValue {
+					var channelList []string
+
+					if priorityChannels != "" {
+						channelList = strings.Split(priorityChannels, ",")
+					}
+
+					p.PriorityChannels = channelList
+					actionTaken = true
+				}
+
 				srcChainID, _ := flags.GetString(flagSrcChainID)
 				if srcChainID != "" {
 					p.Src.ChainID = srcChainID
@@ -347,10 +371,81 @@ $ %s paths update demo-path --src-connection-id connection-02 --dst-connection-i
 					actionTaken = true
 				}
 
+				srcClientType, _ := flags.GetString(flagSrcClientType)
+				if srcClientType != "" {
+					p.Src.ClientType = srcClientType
+					actionTaken = true
+				}
+
+				dstClientType, _ := flags.GetString(flagDstClientType)
+				if dstClientType != "" {
+					p.Dst.ClientType = dstClientType
+					actionTaken = true
+				}
+
+				srcConnHops, _ := flags.GetString(flagSrcConnHops)
+				if srcConnHops != blankValue {
+					p.Src.ConnectionHops = connectionHops(srcConnHops)
+					actionTaken = true
+				}
+
+				dstConnHops, _ := flags.GetString(flagDstConnHops)
+				if dstConnHops != blankValue {
+					p.Dst.ConnectionHops = connectionHops(dstConnHops)
+					actionTaken = true
+				}
+
+				if flags.Changed(flagICS20MemoLimit) {
+					memoLimit, _ := flags.GetInt(flagICS20MemoLimit)
+					if memoLimit < 0 {
+						return fmt.Errorf("invalid ics20 memo limit: %d", memoLimit)
+					}
+					p.ICS20MemoLimit = &memoLimit
+					actionTaken = true
+				}
+
+				for _, f := range []struct {
+					name  string
+					limit func(*relayer.Concurrency) *int
+				}{
+					{flagMaxInFlightTxs, func(c *relayer.Concurrency) *int { return &c.MaxInFlightTxs }},
+					{flagWorkersPerPath, func(c *relayer.Concurrency) *int { return &c.WorkersPerPath }},
+					{flagQueryConcurrency, func(c *relayer.Concurrency) *int { return &c.QueryConcurrency }},
+					{flagWeight, func(c *relayer.Concurrency) *int { return &c.Weight }},
+				} {
+					if !flags.Changed(f.name) {
+						continue
+					}
+					if p.Concurrency == nil {
+						p.Concurrency = new(relayer.Concurrency)
+					}
+					*f.limit(p.Concurrency), _ = flags.GetInt(f.name)
+					actionTaken = true
+				}
+				if err := p.Concurrency.Validate(); err != nil {
+					return err
+				}
+				if err := p.ValidateLocalhost(); err != nil {
+					return err
+				}
+
 				if !actionTaken {
 					return fmt.Errorf("at least one flag must be provided")
 				}
 
+				if err := p.Src.Vhops(); err != nil {
+					return fmt.Errorf("invalid src connection hops: %w", err)
+				}
+				if err := p.Dst.Vhops(); err != nil {
+					return fmt.Errorf("invalid dst connection hops: %w", err)
+				}
+				if err := p.Src.VclientType(); err != nil {
+					return fmt.Errorf("invalid src client type: %w", err)
+				}
+				if err := p.Dst.VclientType(); err != nil {
+					return fmt.Errorf("invalid dst client type: %w", err)
+				}
+
 				return nil
 			})
 		},
@@ -359,6 +454,14 @@ $ %s paths update demo-path --src-connection-id connection-02 --dst-connection-i
 	return cmd
 }
 
+// connectionHops parses a comma separated list of connection hops, where an empty list means single hop channels.
+func connectionHops(hops string) []string {
+	if hops == "" {
+		return nil
+	}
+	return strings.Split(hops, ",")
+}
+
 // pathsFetchCmd attempts to fetch the json files containing the path metadata, for each configured chain, from GitHub
 func pathsFetchCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
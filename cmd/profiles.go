@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultProfilesFile stores the profiles, in the default home directory so that it is found regardless of --home.
+var defaultProfilesFile = filepath.Join(defaultHome, "profiles.yaml")
+
+// profiles are named home directories, e.g. of mainnet and testnet relayers, which commands are run against
+// with --profile, or by default once a profile is in use.
+type profiles struct {
+	// Current is the profile in use when neither --home nor --profile is given.
+	Current string `yaml:"current,omitempty"`
+
+	// Homes are the home directories of the profiles, by name.
+	Homes map[string]string `yaml:"profiles"`
+}
+
+// loadProfiles reads the profiles from file, which need not exist.
+func loadProfiles(file string) (*profiles, error) {
+	p := &profiles{Homes: make(map[string]string)}
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return p, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles file %s: %w", file, err)
+	}
+	if err := yaml.Unmarshal(bz, p); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles file %s: %w", file, err)
+	}
+	if p.Homes == nil {
+		p.Homes = make(map[string]string)
+	}
+	return p, nil
+}
+
+// save writes the profiles to file.
+func (p *profiles) save(file string) error {
+	bz, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, bz, 0o600); err != nil {
+		return fmt.Errorf("failed to write profiles file %s: %w", file, err)
+	}
+	return nil
+}
+
+// home returns the home directory of the named profile, or of the current profile if name is empty.
+// It returns false if name is empty and no profile is in use.
+func (p *profiles) home(name string) (string, bool, error) {
+	if name == "" {
+		// a current profile which was removed from the file by hand falls back to the default home.
+		home, ok := p.Homes[p.Current]
+		return home, ok, nil
+	}
+	home, ok := p.Homes[name]
+	if !ok {
+		return "", false, fmt.Errorf("profile %s not found, add it with '%s profiles add'", name, appName)
+	}
+	return home, true, nil
+}
+
+// resolveProfile sets the home directory to the home of the profile given by --profile, or of the profile in use,
+// unless --home is given.
+func (a *appState) resolveProfile(cmd *cobra.Command) error {
+	profile := a.viper.GetString(flagProfile)
+	if cmd.Flags().Changed(flagHome) {
+		if profile != "" {
+			return fmt.Errorf("--%s and --%s cannot be given together", flagHome, flagProfile)
+		}
+		return nil
+	}
+
+	p, err := loadProfiles(defaultProfilesFile)
+	if err != nil {
+		return err
+	}
+	home, ok, err := p.home(profile)
+	if err != nil || !ok {
+		return err
+	}
+	a.homePath = home
+	return nil
+}
+
+func profilesCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profiles",
+		Aliases: []string{"prof"},
+		Short:   "Manage named home directories, e.g. of mainnet and testnet relayers",
+		Long: strings.TrimSpace(fmt.Sprintf(`Profiles name home directories, so that commands can be run against
+them with --profile instead of --home. Once a profile is in use, commands run against its home directory unless
+--home or --profile is given. Profiles are stored in %s.`, defaultProfilesFile)),
+	}
+
+	cmd.AddCommand(
+		profilesListCmd(a),
+		profilesAddCmd(a),
+		profilesRemoveCmd(a),
+		profilesUseCmd(a),
+	)
+
+	return cmd
+}
+
+func profilesListCmd(a *appState) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l", "ls"},
+		Short:   "List the profiles, marking the profile in use with *",
+		Args:    withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s profiles list
+$ %s prof ls`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := loadProfiles(defaultProfilesFile)
+			if err != nil {
+				return err
+			}
+			if len(p.Homes) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "no profiles found, add one with '%s profiles add'\n", appName)
+				return nil
+			}
+
+			names := make([]string, 0, len(p.Homes))
+			for name := range p.Homes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				marker := " "
+				if name == p.Current {
+					marker = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s -> %s\n", marker, name, p.Homes[name])
+			}
+			return nil
+		},
+	}
+}
+
+func profilesAddCmd(a *appState) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add profile_name home_dir",
+		Short: "Add a profile for a home directory",
+		Args:  withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s profiles add mainnet ~/.relayer
+$ %s profiles add testnet ~/.relayer-testnet`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			home, err := filepath.Abs(args[1])
+			if err != nil {
+				return err
+			}
+
+			p, err := loadProfiles(defaultProfilesFile)
+			if err != nil {
+				return err
+			}
+			if existing, ok := p.Homes[name]; ok {
+				return fmt.Errorf("profile %s already exists for home %s", name, existing)
+			}
+			p.Homes[name] = home
+			return p.save(defaultProfilesFile)
+		},
+	}
+}
+
+func profilesRemoveCmd(a *appState) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove profile_name",
+		Aliases: []string{"rm"},
+		Short:   "Remove a profile, leaving its home directory as it is",
+		Args:    withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s profiles remove testnet`, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			p, err := loadProfiles(defaultProfilesFile)
+			if err != nil {
+				return err
+			}
+			if _, ok := p.Homes[name]; !ok {
+				return fmt.Errorf("profile %s not found", name)
+			}
+			delete(p.Homes, name)
+			if p.Current == name {
+				p.Current = ""
+			}
+			return p.save(defaultProfilesFile)
+		},
+	}
+}
+
+func profilesUseCmd(a *appState) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use [profile_name]",
+		Short: "Use a profile when neither --home nor --profile is given, or the default home if none is given",
+		Args:  withUsage(cobra.MaximumNArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s profiles use testnet
+$ %s profiles use # back to the default home`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := loadProfiles(defaultProfilesFile)
+			if err != nil {
+				return err
+			}
+			p.Current = ""
+			if len(args) == 1 {
+				if _, ok := p.Homes[args[0]]; !ok {
+					return fmt.Errorf("profile %s not found", args[0])
+				}
+				p.Current = args[0]
+			}
+			return p.save(defaultProfilesFile)
+		},
+	}
+}
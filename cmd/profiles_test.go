@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProfiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "profiles.yaml")
+
+	// a missing profiles file has no profiles.
+	p, err := loadProfiles(file)
+	require.NoError(t, err)
+	home, ok, err := p.home("")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, home)
+
+	p.Homes["mainnet"] = "/data/mainnet"
+	p.Homes["testnet"] = "/data/testnet"
+	p.Current = "testnet"
+	require.NoError(t, p.save(file))
+
+	p, err = loadProfiles(file)
+	require.NoError(t, err)
+
+	// the profile in use is the default, unless another is named.
+	home, ok, err = p.home("")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "/data/testnet", home)
+
+	home, ok, err = p.home("mainnet")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "/data/mainnet", home)
+
+	_, _, err = p.home("devnet")
+	require.Error(t, err)
+}
+
+func TestPathLocks(t *testing.T) {
+	ctx := context.Background()
+	home := t.TempDir()
+	paths := []relayer.NamedPath{{Name: "demo-path"}, {Name: "other/path"}}
+
+	a := &appState{log: zap.NewNop(), homePath: home}
+	b := &appState{log: zap.NewNop(), homePath: home}
+
+	locks, err := a.lockPaths(ctx, paths, false)
+	require.NoError(t, err)
+
+	// a second instance refuses to relay any of the paths.
+	_, err = b.lockPaths(ctx, paths[1:], false)
+	require.ErrorContains(t, err, "other/path (pid")
+
+	// a standby instance waits until it is stopped.
+	ctx2, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = b.lockPaths(ctx2, paths, true)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// paths which are not relayed yet can be relayed by another instance.
+	other, err := b.lockPaths(ctx, []relayer.NamedPath{{Name: "third-path"}}, false)
+	require.NoError(t, err)
+	other.unlock()
+
+	locks.unlock()
+	locks, err = b.lockPaths(ctx, paths, false)
+	require.NoError(t, err)
+	locks.unlock()
+}
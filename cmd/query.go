@@ -1,23 +1,37 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/gogoproto/proto"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
 	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/chains/penumbra"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/provider"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	formatJson   = "json"
 	formatLegacy = "legacy"
+	formatYaml   = "yaml"
 )
 
 // queryCmd represents the chain command
@@ -32,6 +46,10 @@ func queryCmd(a *appState) *cobra.Command {
 	cmd.AddCommand(
 		queryUnrelayedPackets(a),
 		queryUnrelayedAcknowledgements(a),
+		queryBacklog(a),
+		queryFailedAcks(a),
+		queryStats(a),
+		queryQuarantined(a),
 		lineBreakCommand(),
 		queryBalanceCmd(a),
 		queryBalancesCmd(a),
@@ -43,6 +61,8 @@ func queryCmd(a *appState) *cobra.Command {
 		queryClientCmd(a),
 		queryClientsCmd(a),
 		queryClientsExpiration(a),
+		queryClientMatches(a),
+		queryClientHistory(a),
 		queryConnection(a),
 		queryConnections(a),
 		queryConnectionsUsingClient(a),
@@ -50,11 +70,16 @@ func queryCmd(a *appState) *cobra.Command {
 		queryChannels(a),
 		queryConnectionChannels(a),
 		queryPacketCommitment(a),
+		queryProof(a),
+		queryIBCSnapshot(a),
 		lineBreakCommand(),
 		queryIBCDenoms(a),
 		queryBaseDenomFromIBCDenom(a),
 		feegrantQueryCmd(a),
 		queryIBCDenomHash(a),
+		queryEscrowAudit(a),
+		lineBreakCommand(),
+		querySpendReport(a),
 	)
 
 	return cmd
@@ -399,13 +424,18 @@ $ %s query balances ibc-0 ibc-1 --key-name=test`,
 
 func queryHeaderCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "header chain_name [height]",
-		Short: "query the header of a network by chain ID at a given height or the latest height",
-		Args:  withUsage(cobra.RangeArgs(1, 2)),
+		Use:   "header chain_name [height|start_height-end_height]",
+		Short: "query the headers of a network by chain ID at a height, a range of heights or the latest height",
+		Long: strings.TrimSpace(fmt.Sprintf(`Query the headers of a network at a height, at each height of an inclusive range of at most %d
+heights, or at the latest height. Headers are printed as protobuf-JSON encoded tendermint light client headers,
+one per line with '--output json', with the validator sets of the headers if '--%s' is set.`,
+			maxHeightRange, flagValidators)),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s query header ibc-0
-$ %s query header ibc-0 1400`,
-			appName, appName,
+$ %s query header ibc-0 1400
+$ %s query header ibc-0 1400-1410 --validators --output json`,
+			appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			chain, ok := a.config.Chains[args[0]]
@@ -413,48 +443,39 @@ $ %s query header ibc-0 1400`,
 				return errChainNotFound(args[0])
 			}
 
-			var height int64
-			switch len(args) {
-			case 1:
-				var err error
-				height, err = chain.ChainProvider.QueryLatestHeight(cmd.Context())
-				if err != nil {
-					return err
-				}
-
-			case 2:
-				var err error
-				height, err = strconv.ParseInt(args[1], 10, 64)
-				if err != nil {
-					return err
-				}
-			}
-
-			header, err := chain.ChainProvider.QueryIBCHeader(cmd.Context(), height)
+			heights, err := queryHeights(cmd.Context(), chain, args[1:])
 			if err != nil {
 				return err
 			}
 
-			s, err := json.Marshal(header)
+			validators, err := cmd.Flags().GetBool(flagValidators)
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to marshal header: %v\n", err)
 				return err
 			}
-
 			output, _ := cmd.Flags().GetString(flagOutput)
-			switch output {
-			case formatJson:
-				fmt.Fprintln(cmd.OutOrStdout(), string(s))
-			case formatLegacy:
-				fallthrough
-			default:
-				fmt.Fprintln(cmd.OutOrStdout(), s)
+
+			for _, height := range heights {
+				header, err := chain.ChainProvider.QueryIBCHeader(cmd.Context(), height)
+				if err != nil {
+					return fmt.Errorf("failed to query header at height %d: %w", height, err)
+				}
+
+				h, err := headerProto(header, validators)
+				if err != nil {
+					return err
+				}
+
+				if err := printProtoJSON(cmd, chain, output, h); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Failed to marshal header: %v\n", err)
+					return err
+				}
 			}
 
 			return nil
 		},
 	}
 
+	cmd = validatorsFlag(a.viper, cmd)
 	cmd = addOutputFlag(a.viper, cmd)
 	return cmd
 }
@@ -463,13 +484,17 @@ $ %s query header ibc-0 1400`,
 // the chain as defined in https://github.com/cosmos/ics/tree/master/spec/ics-002-client-semantics#query
 func queryNodeStateCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "node-state chain_name",
-		Short: "query the consensus state of a network by chain ID",
-		Args:  withUsage(cobra.ExactArgs(1)),
+		Use:   "node-state chain_name [height|start_height-end_height]",
+		Short: "query the consensus state of a network by chain ID at a height, a range of heights or the latest height",
+		Long: strings.TrimSpace(fmt.Sprintf(`Query the consensus state of a network at a height, at each height of an inclusive range of at
+most %d heights, or at the latest height. Consensus states are printed as protobuf-JSON, one per line with
+'--output json'.`, maxHeightRange)),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s query node-state ibc-0
-$ %s q node-state ibc-1`,
-			appName, appName,
+$ %s q node-state ibc-1 1400
+$ %s q node-state ibc-1 1400-1410 --output json`,
+			appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			chain, ok := a.config.Chains[args[0]]
@@ -477,23 +502,25 @@ $ %s q node-state ibc-1`,
 				return errChainNotFound(args[0])
 			}
 
-			height, err := chain.ChainProvider.QueryLatestHeight(cmd.Context())
+			heights, err := queryHeights(cmd.Context(), chain, args[1:])
 			if err != nil {
 				return err
 			}
 
-			csRes, _, err := chain.ChainProvider.QueryConsensusState(cmd.Context(), height)
-			if err != nil {
-				return err
-			}
+			output, _ := cmd.Flags().GetString(flagOutput)
 
-			s, err := chain.ChainProvider.Sprint(csRes)
-			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to marshal consensus state: %v\n", err)
-				return err
+			for _, height := range heights {
+				csRes, _, err := chain.ChainProvider.QueryConsensusState(cmd.Context(), height)
+				if err != nil {
+					return fmt.Errorf("failed to query consensus state at height %d: %w", height, err)
+				}
+
+				if err := printProtoJSON(cmd, chain, output, csRes); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Failed to marshal consensus state: %v\n", err)
+					return err
+				}
 			}
 
-			fmt.Fprintln(cmd.OutOrStdout(), s)
 			return nil
 		},
 	}
@@ -501,6 +528,117 @@ $ %s q node-state ibc-1`,
 	return cmd
 }
 
+// maxHeightRange limits the number of heights of a range of heights queried by a single command.
+const maxHeightRange = 1000
+
+// queryHeights returns the heights given by the optional height argument, either a single height or an inclusive
+// range of heights start_height-end_height, or the latest height of the chain if there is no height argument.
+func queryHeights(ctx context.Context, chain *relayer.Chain, args []string) ([]int64, error) {
+	if len(args) == 0 {
+		height, err := chain.ChainProvider.QueryLatestHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []int64{height}, nil
+	}
+
+	start, end, err := parseHeightRange(args[0])
+	if err != nil {
+		return nil, err
+	}
+	heights := make([]int64, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		heights = append(heights, height)
+	}
+	return heights, nil
+}
+
+// parseHeightRange parses a height, or an inclusive range of heights start_height-end_height.
+func parseHeightRange(arg string) (start, end int64, err error) {
+	startArg, endArg, isRange := strings.Cut(arg, "-")
+	if start, err = strconv.ParseInt(startArg, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid height %s: %w", startArg, err)
+	}
+	end = start
+	if isRange {
+		if end, err = strconv.ParseInt(endArg, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid height %s: %w", endArg, err)
+		}
+	}
+
+	switch {
+	case start <= 0:
+		return 0, 0, fmt.Errorf("height must be greater than zero, got %d", start)
+	case end < start:
+		return 0, 0, fmt.Errorf("end height %d is lower than start height %d", end, start)
+	case end-start >= maxHeightRange:
+		return 0, 0, fmt.Errorf("height range %s spans more than %d heights", arg, maxHeightRange)
+	}
+	return start, end, nil
+}
+
+// headerProto returns the header as a tendermint light client header, so that it can be encoded as protobuf-JSON.
+// The validator sets of the header are only included if validators is true.
+func headerProto(header provider.IBCHeader, validators bool) (*tmclient.Header, error) {
+	var (
+		signedHeader          *tmtypes.SignedHeader
+		valSet, trustedValSet *tmtypes.ValidatorSet
+		trustedHeight         clienttypes.Height
+	)
+	switch h := header.(type) {
+	case provider.TendermintIBCHeader:
+		signedHeader, valSet, trustedValSet, trustedHeight = h.SignedHeader, h.ValidatorSet, h.TrustedValidators, h.TrustedHeight
+	case penumbra.PenumbraIBCHeader:
+		signedHeader, valSet = h.SignedHeader, h.ValidatorSet
+	default:
+		return nil, fmt.Errorf("unsupported header type %T", header)
+	}
+
+	h := &tmclient.Header{
+		SignedHeader:  signedHeader.ToProto(),
+		TrustedHeight: trustedHeight,
+	}
+	if !validators {
+		return h, nil
+	}
+
+	var err error
+	if valSet != nil {
+		if h.ValidatorSet, err = valSet.ToProto(); err != nil {
+			return nil, err
+		}
+	}
+	if trustedValSet != nil {
+		if h.TrustedValidators, err = trustedValSet.ToProto(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// printProtoJSON prints the protobuf-JSON encoding of msg by the codec of the chain, on a single line with the json
+// output format, or indented otherwise.
+func printProtoJSON(cmd *cobra.Command, chain *relayer.Chain, output string, msg proto.Message) error {
+	s, err := chain.ChainProvider.Sprint(msg)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case formatJson:
+		fmt.Fprintln(cmd.OutOrStdout(), s)
+	case formatLegacy:
+		fallthrough
+	default:
+		var out bytes.Buffer
+		if err := json.Indent(&out, []byte(s), "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), out.String())
+	}
+	return nil
+}
+
 func queryClientCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "client chain_name client_id",
@@ -1117,6 +1255,135 @@ $ %s q packet-commit ibc-1 ibconechannel transfer 31`,
 	return cmd
 }
 
+func queryProof(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proof chain_name key [height]",
+		Short: "query the ICS-23 proof of a store key on a network, at a height or the latest height",
+		Long: strings.TrimSpace(`Query the value of a key in a store of a network along with its ICS-23 proof, or the proof
+of its absence. The key is a string, e.g. an ICS-24 path of the ibc store such as clients/07-tendermint-0/clientState,
+or hex with a "hex:" prefix. The proof verifies against the consensus state at the proof height of a client of the
+network, which 'rly dev verify-proof' checks.`),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query proof ibc-0 clients/07-tendermint-0/clientState
+$ %s q proof ibc-0 commitments/ports/transfer/channels/channel-0/sequences/1 1200
+$ %s q proof ibc-0 hex:0114c0ffee --store acc`,
+			appName, appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+
+			proof, err := queryStoreProofFromArgs(cmd, chain, args[1], args[2:])
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(proof, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
+	return storeFlag(a.viper, cmd)
+}
+
+// queryStoreProofFromArgs queries the proof of the key in the store of the store flag on the chain,
+// at the height of the optional height arg.
+func queryStoreProofFromArgs(cmd *cobra.Command, chain *relayer.Chain, key string, heightArg []string) (*relayer.StoreProof, error) {
+	keyBz, err := relayer.ParseStoreKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var height int64
+	if len(heightArg) > 0 {
+		if height, err = strconv.ParseInt(heightArg[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid height %s: %w", heightArg[0], err)
+		}
+	}
+
+	store, err := cmd.Flags().GetString(flagStore)
+	if err != nil {
+		return nil, err
+	}
+
+	return relayer.QueryStoreProof(cmd.Context(), chain, store, keyBz, height)
+}
+
+func queryIBCSnapshot(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ibc-snapshot chain_name",
+		Short: "export all IBC clients, connections, channels and packet state on a network",
+		Long: strings.TrimSpace(`Export all clients, connections and channels on a network, along with the pending
+packet commitments and the acknowledgements of every channel, into a single JSON or YAML document
+for debugging, audits and offline analysis of relay backlogs.`),
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query ibc-snapshot ibc-0 > ibc-0.json
+$ %s q ibc-snapshot ibc-1 --output yaml`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			if output != formatJson && output != formatYaml {
+				return fmt.Errorf("invalid output format %s, expected %s or %s", output, formatJson, formatYaml)
+			}
+
+			snapshot, err := relayer.QueryIBCSnapshot(cmd.Context(), chain)
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(snapshot, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if output == formatYaml {
+				if out, err = jsonToYAML(out); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(out)))
+			return nil
+		},
+	}
+	cmd = snapshotOutputFlag(a.viper, cmd)
+	return cmd
+}
+
+// jsonToYAML converts a JSON document to YAML, preserving the order of keys and the representation of values,
+// e.g. of states encoded by the codec of a chain.
+func jsonToYAML(in []byte) ([]byte, error) {
+	// JSON is valid YAML, so it is parsed as is and only the flow style of JSON is cleared.
+	var node yaml.Node
+	if err := yaml.Unmarshal(in, &node); err != nil {
+		return nil, err
+	}
+
+	var clearStyle func(n *yaml.Node)
+	clearStyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			clearStyle(c)
+		}
+	}
+	clearStyle(&node)
+
+	return yaml.Marshal(&node)
+}
+
 func queryUnrelayedPackets(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "unrelayed-packets path src_channel_id",
@@ -1126,8 +1393,9 @@ func queryUnrelayedPackets(a *appState) *cobra.Command {
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s q unrelayed-packets demo-path channel-0
 $ %s query unrelayed-packets demo-path channel-0
-$ %s query unrelayed-pkts demo-path channel-0`,
-			appName, appName, appName,
+$ %s query unrelayed-pkts demo-path channel-0
+$ %s query unrelayed-pkts demo-path channel-0 --from-index`,
+			appName, appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path, err := a.config.Paths.Get(args[0])
@@ -1155,7 +1423,24 @@ $ %s query unrelayed-pkts demo-path channel-0`,
 				return err
 			}
 
-			sp := relayer.UnrelayedSequences(cmd.Context(), c[src], c[dst], channel)
+			fromIndex, err := cmd.Flags().GetBool(flagFromIndex)
+			if err != nil {
+				return err
+			}
+
+			var sp relayer.RelaySequences
+			if fromIndex {
+				index, err := indexer.OpenStore(a.indexDBPath())
+				if err != nil {
+					return err
+				}
+				defer index.Close()
+				if sp, err = relayer.QueryIndexedUnrelayedSequences(cmd.Context(), c[src], c[dst], channel, index); err != nil {
+					return err
+				}
+			} else {
+				sp = relayer.UnrelayedSequences(cmd.Context(), c[src], c[dst], channel)
+			}
 
 			out, err := json.Marshal(sp)
 			if err != nil {
@@ -1167,6 +1452,7 @@ $ %s query unrelayed-pkts demo-path channel-0`,
 		},
 	}
 	cmd = addOutputFlag(a.viper, cmd)
+	cmd = fromIndexFlag(a.viper, cmd)
 	return cmd
 }
 
@@ -1179,8 +1465,9 @@ func queryUnrelayedAcknowledgements(a *appState) *cobra.Command {
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s q unrelayed-acknowledgements demo-path channel-0
 $ %s query unrelayed-acknowledgements demo-path channel-0
-$ %s query unrelayed-acks demo-path channel-0`,
-			appName, appName, appName,
+$ %s query unrelayed-acks demo-path channel-0
+$ %s query unrelayed-acks demo-path channel-0 --from-index`,
+			appName, appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path, err := a.config.Paths.Get(args[0])
@@ -1207,7 +1494,24 @@ $ %s query unrelayed-acks demo-path channel-0`,
 				return err
 			}
 
-			sp := relayer.UnrelayedAcknowledgements(cmd.Context(), c[src], c[dst], channel)
+			fromIndex, err := cmd.Flags().GetBool(flagFromIndex)
+			if err != nil {
+				return err
+			}
+
+			var sp relayer.RelaySequences
+			if fromIndex {
+				index, err := indexer.OpenStore(a.indexDBPath())
+				if err != nil {
+					return err
+				}
+				defer index.Close()
+				if sp, err = relayer.QueryIndexedUnrelayedAcknowledgements(cmd.Context(), c[src], c[dst], channel, index); err != nil {
+					return err
+				}
+			} else {
+				sp = relayer.UnrelayedAcknowledgements(cmd.Context(), c[src], c[dst], channel)
+			}
 
 			out, err := json.Marshal(sp)
 			if err != nil {
@@ -1219,18 +1523,24 @@ $ %s query unrelayed-acks demo-path channel-0`,
 		},
 	}
 	cmd = addOutputFlag(a.viper, cmd)
+	cmd = fromIndexFlag(a.viper, cmd)
 	return cmd
 }
 
-func queryClientsExpiration(a *appState) *cobra.Command {
+func queryBacklog(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "clients-expiration path",
-		Aliases: []string{"ce"},
-		Short:   "query for light clients expiration date",
-		Args:    withUsage(cobra.ExactArgs(1)),
+		Use:   "backlog path",
+		Short: "query the unrelayed packets and acknowledgements on all channels of a path",
+		Long: strings.TrimSpace(`Query the sequences of the packets and acknowledgements which remain to be relayed in both directions
+on the open channels of a path allowed by its channel filter. The backlog is detected by diffing the packet commitments
+on each chain against the packet receipts and acknowledgements of the counterparty, with state queries only, so it is
+found in full for packets of any age, even from nodes which have tx indexing disabled. Received packets which are
+acknowledged asynchronously are not listed as unrelayed acks until their acknowledgement is written.`),
+		Args: withUsage(cobra.ExactArgs(1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
-$ %s query clients-expiration demo-path`,
-			appName,
+$ %s query backlog demo-path
+$ %s q backlog demo-path --output json`,
+			appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path, err := a.config.Paths.Get(args[0])
@@ -1238,6 +1548,7 @@ $ %s query clients-expiration demo-path`,
 				return err
 			}
 			src, dst := path.Src.ChainID, path.Dst.ChainID
+
 			c, err := a.config.Chains.Gets(src, dst)
 			if err != nil {
 				return err
@@ -1250,35 +1561,608 @@ $ %s query clients-expiration demo-path`,
 				return err
 			}
 
-			srcExpiration, srcClientInfo, errSrc := relayer.QueryClientExpiration(cmd.Context(), c[src], c[dst])
-			if errSrc != nil && !strings.Contains(errSrc.Error(), "light client not found") {
-				return errSrc
-			}
-			dstExpiration, dstClientInfo, errDst := relayer.QueryClientExpiration(cmd.Context(), c[dst], c[src])
-			if errDst != nil && !strings.Contains(errDst.Error(), "light client not found") {
-				return errDst
+			backlogs, err := relayer.QueryPacketBacklog(cmd.Context(), c[src], c[dst], path.Filter)
+			if err != nil {
+				return err
 			}
 
 			output, _ := cmd.Flags().GetString(flagOutput)
+			if output == formatJson {
+				out, err := json.Marshal(backlogs)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
+			}
+			for _, b := range backlogs {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s/%s -> %s %s/%s: %d unrelayed packets, %d unrelayed acks\n",
+					b.ChainID, b.PortID, b.ChannelID, b.CounterpartyChainID, b.CounterpartyPortID, b.CounterpartyChannelID,
+					len(b.UnrelayedPackets), len(b.UnrelayedAcks))
+			}
+			return nil
+		},
+	}
+	cmd = addOutputFlag(a.viper, cmd)
+	return cmd
+}
 
-			srcClientExpiration := relayer.SPrintClientExpiration(c[src], srcExpiration, srcClientInfo)
-			dstClientExpiration := relayer.SPrintClientExpiration(c[dst], dstExpiration, dstClientInfo)
+func queryStats(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats [path]",
+		Short: "query the SLA statistics of the paths of a running relayer",
+		Long: strings.TrimSpace(fmt.Sprintf(`Query the rolling statistics over the last day of how promptly a relayer started with
+'rly start --%s' relays packets: the median latency from observing a packet to observing its
+acknowledgement or timeout, the percentage of packets relayed within one minute, and the longest
+period during which packets were pending but none were relayed. All paths are queried unless one is given.`,
+			flagControlAPI,
+		)),
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query stats
+$ %s q stats demo-path --debug-addr localhost:7597`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			debugAddr, err := a.debugAddr(cmd)
+			if err != nil {
+				return err
+			}
+			if debugAddr == "" {
+				return fmt.Errorf("no debug address, set --%s", flagDebugAddr)
+			}
 
-			if output == formatJson {
-				srcClientExpiration = relayer.SPrintClientExpirationJson(c[src], srcExpiration, srcClientInfo)
-				dstClientExpiration = relayer.SPrintClientExpirationJson(c[dst], dstExpiration, dstClientInfo)
+			client, err := newControlClient(debugAddr)
+			if err != nil {
+				return err
 			}
 
-			if errSrc == nil {
-				fmt.Fprintln(cmd.OutOrStdout(), srcClientExpiration)
+			ctx := cmd.Context()
+			paths := args
+			if len(paths) == 0 {
+				if paths, err = client.paths(ctx); err != nil {
+					return err
+				}
 			}
 
-			if errDst == nil {
-				fmt.Fprintln(cmd.OutOrStdout(), dstClientExpiration)
+			for _, pathName := range paths {
+				stats, err := client.stats(ctx, pathName)
+				if err != nil {
+					return err
+				}
+
+				out, err := json.Marshal(stats)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
 			}
 			return nil
 		},
 	}
-	cmd = addOutputFlag(a.viper, cmd)
-	return cmd
+	return debugServerFlags(a.viper, cmd)
+}
+
+func queryQuarantined(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quarantined [path]",
+		Short: "query the packets quarantined by a running relayer after they failed on their own",
+		Long: strings.TrimSpace(fmt.Sprintf(`Query the packets which a relayer started with 'rly start --%s' quarantined after
+isolating them, by bisecting a batch tx which failed without the chain reporting which message caused it,
+as failing on their own. Quarantined packets are not relayed until they are retried with the retry command
+of 'rly debug shell'. All paths are queried unless one is given.`,
+			flagControlAPI,
+		)),
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query quarantined
+$ %s q quarantined demo-path --debug-addr localhost:7597`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			debugAddr, err := a.debugAddr(cmd)
+			if err != nil {
+				return err
+			}
+			if debugAddr == "" {
+				return fmt.Errorf("no debug address, set --%s", flagDebugAddr)
+			}
+
+			client, err := newControlClient(debugAddr)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			paths := args
+			if len(paths) == 0 {
+				if paths, err = client.paths(ctx); err != nil {
+					return err
+				}
+			}
+
+			for _, pathName := range paths {
+				packets, err := client.quarantined(ctx, pathName)
+				if err != nil {
+					return err
+				}
+				for _, p := range packets {
+					out, err := json.Marshal(struct {
+						PathName string `json:"path_name"`
+						processor.QuarantinedPacket
+					}{pathName, p})
+					if err != nil {
+						return err
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				}
+			}
+			return nil
+		},
+	}
+	return debugServerFlags(a.viper, cmd)
+}
+
+func queryFailedAcks(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "failed-acks path",
+		Short: "query for error acknowledgements written on either chain of a path for rejected packets",
+		Long: strings.TrimSpace(`Search both chains of a path for acknowledgements written for packets received
+on the path's connection which are ICS-4 error acknowledgements, e.g. for ICS-20 transfers which were
+rejected on the destination chain and refunded to the sender. For transfers which executed a contract through
+the ibc-hooks middleware, the contract and the full error emitted by the middleware are reported as well. With
+--from-index, the acknowledgements indexed by a relayer started with --index-events are searched instead of
+the txs of the chains.`),
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s q failed-acks demo-path
+$ %s query failed-acks demo-path --page 2 --limit 50 --output json
+$ %s query failed-acks demo-path --from-index`,
+			appName, appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := a.config.Paths.Get(args[0])
+			if err != nil {
+				return err
+			}
+			src, dst := path.Src.ChainID, path.Dst.ChainID
+
+			c, err := a.config.Chains.Gets(src, dst)
+			if err != nil {
+				return err
+			}
+
+			if err = c[src].SetPath(path.Src); err != nil {
+				return err
+			}
+			if err = c[dst].SetPath(path.Dst); err != nil {
+				return err
+			}
+
+			page, err := cmd.Flags().GetUint64(flagPage)
+			if err != nil {
+				return err
+			}
+
+			limit, err := cmd.Flags().GetUint64(flagLimit)
+			if err != nil {
+				return err
+			}
+
+			fromIndex, err := cmd.Flags().GetBool(flagFromIndex)
+			if err != nil {
+				return err
+			}
+
+			var index *indexer.Store
+			if fromIndex {
+				index, err = indexer.OpenStore(a.indexDBPath())
+				if err != nil {
+					return err
+				}
+				defer index.Close()
+			}
+
+			var failed []relayer.FailedAck
+			for _, chain := range []*relayer.Chain{c[src], c[dst]} {
+				var chainFailed []relayer.FailedAck
+				if index != nil {
+					chainFailed, err = relayer.QueryIndexedFailedAcks(cmd.Context(), chain, index, int(page), int(limit))
+				} else {
+					chainFailed, err = relayer.QueryFailedAcks(cmd.Context(), chain, int(page), int(limit))
+				}
+				if err != nil {
+					return err
+				}
+				failed = append(failed, chainFailed...)
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			switch output {
+			case formatJson:
+				out, err := json.Marshal(failed)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			case formatLegacy:
+				fallthrough
+			default:
+				if len(failed) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no failed acknowledgements found")
+					return nil
+				}
+				for _, f := range failed {
+					line := fmt.Sprintf("chain {%s} height {%d} packet {%s/%s -> %s/%s} sequence {%d} error {%s}",
+						f.ChainID, f.Height, f.SrcPort, f.SrcChannel, f.DstPort, f.DstChannel, f.Sequence, f.Error)
+					if f.WasmContract != "" {
+						line += fmt.Sprintf(" wasm-contract {%s}", f.WasmContract)
+					}
+					if f.HookError != "" {
+						line += fmt.Sprintf(" hook-error {%s}", f.HookError)
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), line)
+				}
+			}
+			return nil
+		},
+	}
+	cmd = addOutputFlag(a.viper, cmd)
+	cmd = paginationFlags(a.viper, cmd, "acknowledgement txs")
+	cmd = fromIndexFlag(a.viper, cmd)
+	return cmd
+}
+
+func queryEscrowAudit(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "escrow-audit path [src_channel_id]",
+		Short: "compare the tokens escrowed for the ICS-20 channels of a path with the supply of their vouchers",
+		Long: strings.TrimSpace(`Compare, for each denom sent through the open ICS-20 channels of a path, or only
+through the given channel, the balance of the escrow account of the channel on the sending chain with the total
+supply of the vouchers of the denom on the receiving chain, in both directions. A supply of vouchers exceeding
+the escrowed amount should never happen and indicates a bug or an exploit, in which case the command fails.
+More escrowed than the supply of vouchers is expected while transfers are in flight.`),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s q escrow-audit demo-path
+$ %s query escrow-audit demo-path channel-0 --output json`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := a.config.Paths.Get(args[0])
+			if err != nil {
+				return err
+			}
+			src, dst := path.Src.ChainID, path.Dst.ChainID
+
+			c, err := a.config.Chains.Gets(src, dst)
+			if err != nil {
+				return err
+			}
+
+			if err = c[src].SetPath(path.Src); err != nil {
+				return err
+			}
+			if err = c[dst].SetPath(path.Dst); err != nil {
+				return err
+			}
+
+			var channelID string
+			if len(args) > 1 {
+				channelID = args[1]
+			}
+
+			audits, err := relayer.AuditEscrows(cmd.Context(), c[src], c[dst], channelID)
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			switch output {
+			case formatJson:
+				out, err := json.Marshal(audits)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			case formatLegacy:
+				fallthrough
+			default:
+				if len(audits) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no escrowed tokens found")
+				}
+				for _, e := range audits {
+					fmt.Fprintf(cmd.OutOrStdout(),
+						"chain {%s} channel {%s/%s} denom {%s} escrowed {%s} counterparty {%s} voucher {%s} supply {%s} status {%s}\n",
+						e.ChainID, e.PortID, e.ChannelID, e.Trace, e.Escrowed, e.CounterpartyChainID, e.Voucher,
+						e.VoucherSupply, e.Status)
+				}
+			}
+
+			var unbacked int
+			for _, e := range audits {
+				if e.Status == relayer.EscrowAuditUnbackedSupply {
+					unbacked++
+				}
+			}
+			if unbacked > 0 {
+				return fmt.Errorf("found %d denoms with a supply of vouchers exceeding their escrow", unbacked)
+			}
+			return nil
+		},
+	}
+	cmd = addOutputFlag(a.viper, cmd)
+	return cmd
+}
+
+func queryClientsExpiration(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "clients-expiration path",
+		Aliases: []string{"ce"},
+		Short:   "query for light clients expiration date",
+		Args:    withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query clients-expiration demo-path`,
+			appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := a.config.Paths.Get(args[0])
+			if err != nil {
+				return err
+			}
+			src, dst := path.Src.ChainID, path.Dst.ChainID
+			c, err := a.config.Chains.Gets(src, dst)
+			if err != nil {
+				return err
+			}
+
+			if err = c[src].SetPath(path.Src); err != nil {
+				return err
+			}
+			if err = c[dst].SetPath(path.Dst); err != nil {
+				return err
+			}
+
+			srcExpiration, srcClientInfo, errSrc := relayer.QueryClientExpiration(cmd.Context(), c[src], c[dst])
+			if errSrc != nil && !strings.Contains(errSrc.Error(), "light client not found") {
+				return errSrc
+			}
+			dstExpiration, dstClientInfo, errDst := relayer.QueryClientExpiration(cmd.Context(), c[dst], c[src])
+			if errDst != nil && !strings.Contains(errDst.Error(), "light client not found") {
+				return errDst
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+
+			srcClientExpiration := relayer.SPrintClientExpiration(c[src], srcExpiration, srcClientInfo)
+			dstClientExpiration := relayer.SPrintClientExpiration(c[dst], dstExpiration, dstClientInfo)
+
+			if output == formatJson {
+				srcClientExpiration = relayer.SPrintClientExpirationJson(c[src], srcExpiration, srcClientInfo)
+				dstClientExpiration = relayer.SPrintClientExpirationJson(c[dst], dstExpiration, dstClientInfo)
+			}
+
+			if errSrc == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), srcClientExpiration)
+			}
+
+			if errDst == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), dstClientExpiration)
+			}
+			return nil
+		},
+	}
+	cmd = addOutputFlag(a.viper, cmd)
+	return cmd
+}
+
+func queryClientMatches(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "client-matches chain_name client_id counterparty_chain_name",
+		Short: "check that an existing light client tracks a counterparty chain",
+		Long: `Check that an existing light client on a chain tracks the counterparty chain, before using it for a path:
+that it is a client of the chain ID of the counterparty, neither frozen nor expired, whose latest height is not
+ahead of the counterparty, whose trusting period is shorter than the unbonding period of the counterparty,
+and whose latest consensus state matches the header of the counterparty at that height.`,
+		Args: withUsage(cobra.ExactArgs(3)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query client-matches cosmoshub 07-tendermint-259 osmosis
+$ %s query client-matches cosmoshub 07-tendermint-259 osmosis --output json`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			counterparty, ok := a.config.Chains[args[2]]
+			if !ok {
+				return errChainNotFound(args[2])
+			}
+
+			m, err := relayer.MatchClient(cmd.Context(), chain, counterparty, args[1])
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			if output == formatJson {
+				out, err := json.Marshal(m)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "client %s on %s tracking %s: latest height %s, trusting period %s, unbonding period %s\n",
+					m.ClientID, m.ChainID, m.TrackedChainID, m.LatestHeight, m.TrustingPeriod, m.UnbondingPeriod)
+				for _, problem := range m.Problems {
+					fmt.Fprintf(cmd.OutOrStdout(), "  problem: %s\n", problem)
+				}
+				for _, warning := range m.Warnings {
+					fmt.Fprintf(cmd.OutOrStdout(), "  warning: %s\n", warning)
+				}
+				if len(m.Problems) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "  matches %s\n", m.CounterpartyChainID)
+				}
+			}
+
+			return m.Err()
+		},
+	}
+	cmd = addOutputFlag(a.viper, cmd)
+	return cmd
+}
+
+func queryClientHistory(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "client-history chain_name client_id",
+		Short: "list the consensus states, updates, submitters and misbehaviour of a light client",
+		Long: `List the history of a light client on a chain for forensics after an incident: its status and frozen height,
+the consensus states it stores, and the txs which created, updated, upgraded or submitted misbehaviour for it,
+with the heights of the consensus states they added and the addresses which submitted them. The txs are searched
+on the node of the chain, so txs pruned from the node, and events emitted outside of txs, e.g. by governance
+recovering the client, are missing.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query client-history cosmoshub 07-tendermint-259
+$ %s query client-history cosmoshub 07-tendermint-259 --output json`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			cc, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+			if !ok {
+				return fmt.Errorf("client history is only supported for cosmos chains")
+			}
+
+			h, err := cc.QueryClientHistory(cmd.Context(), args[1])
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			if output == formatJson {
+				out, err := json.Marshal(h)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
+			}
+
+			w := cmd.OutOrStdout()
+			fmt.Fprintf(w, "client %s (%s) on %s: status %s, latest height %s\n",
+				h.ClientID, h.ClientType, h.ChainID, h.Status, h.LatestHeight)
+			if h.FrozenHeight != "" {
+				fmt.Fprintf(w, "  frozen at height %s\n", h.FrozenHeight)
+			}
+			fmt.Fprintf(w, "consensus states (%d):\n", len(h.ConsensusStates))
+			for _, c := range h.ConsensusStates {
+				fmt.Fprintf(w, "  %s  %s", c.Height, c.Timestamp.Format(time.RFC3339))
+				if c.Root != "" {
+					fmt.Fprintf(w, "  root %s  next validators %s", c.Root, c.NextValidatorsHash)
+				}
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "events (%d):\n", len(h.Events))
+			for _, e := range h.Events {
+				fmt.Fprintf(w, "  %d  %s  tx %s  submitter %s", e.Height, e.Type, e.TxHash, e.Submitter)
+				if len(e.ConsensusHeights) > 0 {
+					fmt.Fprintf(w, "  consensus heights %s", strings.Join(e.ConsensusHeights, ","))
+				}
+				fmt.Fprintln(w)
+			}
+			return nil
+		},
+	}
+	cmd = addOutputFlag(a.viper, cmd)
+	return cmd
+}
+
+func querySpendReport(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spend-report",
+		Short: "summarize fees spent relaying per path per chain",
+		Long: strings.TrimSpace(`Summarize the txs, gas used and fees spent per path per chain from the local
+accounting database. Txs are only recorded while the relayer is started with --record-spend, or by any
+command if record-spend is set in the global config. Txs broadcast outside of a path, e.g. transfers, are
+summarized under an empty path name.`),
+		Args: withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query spend-report
+$ %s query spend-report --since 7d --output json`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceStr, err := cmd.Flags().GetString(flagSince)
+			if err != nil {
+				return err
+			}
+
+			since, err := parseSince(sinceStr)
+			if err != nil {
+				return err
+			}
+
+			store, err := accounting.OpenStore(a.accountingDBPath())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			report, err := store.SpendReport(cmd.Context(), time.Now().Add(-since))
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			switch output {
+			case formatJson:
+				out, err := json.Marshal(report)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			case formatLegacy:
+				fallthrough
+			default:
+				if len(report.Paths) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "no txs recorded since %s\n", report.Since.Format(time.RFC3339))
+					return nil
+				}
+				for _, p := range report.Paths {
+					fmt.Fprintf(cmd.OutOrStdout(), "path {%s} chain {%s} txs {%d} failed {%d} msgs {%d} gas-used {%d} fees {%s}\n",
+						p.PathName, p.ChainID, p.Txs, p.Failed, p.Msgs, p.GasUsed, p.Fees)
+				}
+			}
+			return nil
+		},
+	}
+	cmd = sinceFlag(a.viper, cmd)
+	cmd = addOutputFlag(a.viper, cmd)
+	return cmd
+}
+
+// parseSince parses a duration which, in addition to the units supported by time.ParseDuration,
+// may be given in whole days with a "d" suffix, e.g. 30d.
+func parseSince(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseUint(days, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q: %w", s, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative: %s", s)
+	}
+	return d, nil
 }
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeightRange(t *testing.T) {
+	start, end, err := parseHeightRange("1400")
+	require.NoError(t, err)
+	require.Equal(t, int64(1400), start)
+	require.Equal(t, int64(1400), end)
+
+	start, end, err = parseHeightRange("1400-1410")
+	require.NoError(t, err)
+	require.Equal(t, int64(1400), start)
+	require.Equal(t, int64(1410), end)
+
+	_, _, err = parseHeightRange("1")
+	require.NoError(t, err)
+
+	_, _, err = parseHeightRange("0")
+	require.Error(t, err)
+
+	_, _, err = parseHeightRange("1410-1400")
+	require.Error(t, err)
+
+	_, _, err = parseHeightRange("1400-")
+	require.Error(t, err)
+
+	_, _, err = parseHeightRange("latest")
+	require.Error(t, err)
+
+	_, _, err = parseHeightRange("1-1000")
+	require.NoError(t, err)
+
+	_, _, err = parseHeightRange("1-1001")
+	require.Error(t, err)
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/spf13/cobra"
+)
+
+// Placeholders which are substituted in a --msg template before it is decoded.
+const (
+	msgTemplateSender                = "$SENDER"
+	msgTemplateSourcePort            = "$SOURCE_PORT"
+	msgTemplateSourceChannel         = "$SOURCE_CHANNEL"
+	msgTemplateTimeoutRevisionNumber = "$TIMEOUT_REVISION_NUMBER"
+	msgTemplateTimeoutRevisionHeight = "$TIMEOUT_REVISION_HEIGHT"
+	msgTemplateTimeoutTimestamp      = "$TIMEOUT_TIMESTAMP"
+)
+
+// rawCmd returns a parent command for lower level transactions,
+// intended for app developers testing custom IBC modules.
+func rawCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "raw",
+		Short: "raw IBC transaction commands",
+		Long: strings.TrimSpace(`Commands to build and broadcast lower level IBC transactions,
+such as sending packets on arbitrary ports and channels.`),
+	}
+
+	cmd.AddCommand(
+		packetSendCmd(a),
+	)
+
+	return cmd
+}
+
+func packetSendCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packet-send src_chain_name dst_chain_name src_port_id src_channel_id",
+		Short: "send a packet on an arbitrary port and channel",
+		Long: strings.TrimSpace(`Send an IBC packet on an arbitrary port and channel.
+
+By default a MsgTransfer is built for the given port and channel using --amount and --receiver,
+which allows sending ICS-20 packets through ports other than "transfer", e.g. middleware or forks.
+
+To send a packet through a custom IBC module, pass the JSON encoded message that causes the
+module to send the packet with --msg, either inline or as @path/to/msg.json. The message type
+must be registered with the chain's codec. The following placeholders are substituted in the
+message before it is decoded:
+
+  $SENDER                   address of the configured key on the source chain
+  $SOURCE_PORT              src_port_id argument
+  $SOURCE_CHANNEL           src_channel_id argument
+  $TIMEOUT_REVISION_NUMBER  revision number of the computed timeout height
+  $TIMEOUT_REVISION_HEIGHT  revision height of the computed timeout height
+  $TIMEOUT_TIMESTAMP        computed timeout timestamp in nanoseconds`),
+		Args: withUsage(cobra.ExactArgs(4)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx raw packet-send ibc-0 ibc-1 transfer channel-0 --amount 100stake --receiver cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk
+$ %s tx raw packet-send ibc-0 ibc-1 wasm.cosmos14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s4hmalr channel-3 --amount 1stake --receiver raw:0xabc -y 10
+$ %s tx raw packet-send ibc-0 ibc-1 mymodule channel-2 --msg @msg.json -c 10m`,
+			appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+
+			dst, ok := a.config.Chains[args[1]]
+			if !ok {
+				return errChainNotFound(args[1])
+			}
+
+			pathString, err := cmd.Flags().GetString(flagPath)
+			if err != nil {
+				return err
+			}
+
+			if _, err = setPathsFromArgs(a, src, dst, pathString); err != nil {
+				return err
+			}
+
+			srcPortID, srcChannelID := args[2], args[3]
+
+			if err := host.PortIdentifierValidator(srcPortID); err != nil {
+				return err
+			}
+			if err := host.ChannelIdentifierValidator(srcChannelID); err != nil {
+				return err
+			}
+
+			srch, err := src.ChainProvider.QueryLatestHeight(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			channel, err := src.ChainProvider.QueryChannel(cmd.Context(), srch, srcChannelID, srcPortID)
+			if err != nil {
+				return fmt.Errorf("failed to query channel{%s} on port{%s} for chain{%s}: %w",
+					srcChannelID, srcPortID, src.ChainID(), err)
+			}
+
+			if channel.Channel.State != chantypes.OPEN {
+				return fmt.Errorf("channel{%s} on port{%s} for chain{%s} is not open: %s",
+					srcChannelID, srcPortID, src.ChainID(), channel.Channel.State)
+			}
+
+			toHeightOffset, err := cmd.Flags().GetUint64(flagTimeoutHeightOffset)
+			if err != nil {
+				return err
+			}
+
+			toTimeOffset, err := cmd.Flags().GetDuration(flagTimeoutTimeOffset)
+			if err != nil {
+				return err
+			}
+
+			timeoutHeight, timeoutTimestamp, err := src.PacketTimeout(cmd.Context(), dst, toHeightOffset, toTimeOffset)
+			if err != nil {
+				return err
+			}
+
+			msgTemplate, err := cmd.Flags().GetString(flagMsg)
+			if err != nil {
+				return err
+			}
+
+			var msg provider.RelayerMessage
+			if msgTemplate != "" {
+				sender, err := src.ChainProvider.Address()
+				if err != nil {
+					return err
+				}
+
+				msgJSON, err := readMsgTemplate(msgTemplate)
+				if err != nil {
+					return err
+				}
+
+				msgJSON = expandMsgTemplate(msgJSON, map[string]string{
+					msgTemplateSender:                sender,
+					msgTemplateSourcePort:            srcPortID,
+					msgTemplateSourceChannel:         srcChannelID,
+					msgTemplateTimeoutRevisionNumber: strconv.FormatUint(timeoutHeight.RevisionNumber, 10),
+					msgTemplateTimeoutRevisionHeight: strconv.FormatUint(timeoutHeight.RevisionHeight, 10),
+					msgTemplateTimeoutTimestamp:      strconv.FormatUint(timeoutTimestamp, 10),
+				})
+
+				cp, ok := src.ChainProvider.(*cosmos.CosmosProvider)
+				if !ok {
+					return fmt.Errorf("--%s is not supported for chain type: %s", flagMsg, src.ChainProvider.Type())
+				}
+
+				if msg, err = cp.MsgFromJSON([]byte(msgJSON)); err != nil {
+					return err
+				}
+			} else {
+				amountStr, err := cmd.Flags().GetString(flagAmount)
+				if err != nil {
+					return err
+				}
+
+				receiver, err := cmd.Flags().GetString(flagReceiver)
+				if err != nil {
+					return err
+				}
+
+				if amountStr == "" || receiver == "" {
+					return fmt.Errorf("either --%s or both --%s and --%s must be provided", flagMsg, flagAmount, flagReceiver)
+				}
+
+				amount, err := sdk.ParseCoinNormalized(amountStr)
+				if err != nil {
+					return err
+				}
+
+				// If the receiver begins with "raw:" then use the suffix directly.
+				receiver = strings.TrimPrefix(receiver, "raw:")
+
+				msg, err = src.ChainProvider.MsgTransfer(receiver, amount, "", provider.PacketInfo{
+					SourcePort:       srcPortID,
+					SourceChannel:    srcChannelID,
+					TimeoutHeight:    timeoutHeight,
+					TimeoutTimestamp: timeoutTimestamp,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			return src.SendPacketMsg(cmd.Context(), a.log, dst, msg, a.config.memo(cmd))
+		},
+	}
+
+	cmd = packetSendFlags(a.viper, cmd)
+	cmd = generateOnlyFlag(a.viper, cmd)
+	cmd = memoFlag(a.viper, cmd)
+	return timeoutFlags(a.viper, pathFlag(a.viper, cmd))
+}
+
+// readMsgTemplate returns the message template itself,
+// or the contents of the file it references if prefixed with '@'.
+func readMsgTemplate(msgTemplate string) (string, error) {
+	file, ok := strings.CutPrefix(msgTemplate, "@")
+	if !ok {
+		return msgTemplate, nil
+	}
+
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message file %s: %w", file, err)
+	}
+
+	if len(bz) == 0 {
+		return "", errors.New("message file is empty")
+	}
+
+	return string(bz), nil
+}
+
+// expandMsgTemplate substitutes each placeholder key in msgTemplate with its value.
+func expandMsgTemplate(msgTemplate string, values map[string]string) string {
+	oldnew := make([]string, 0, 2*len(values))
+	for k, v := range values {
+		oldnew = append(oldnew, k, v)
+	}
+	return strings.NewReplacer(oldnew...).Replace(msgTemplate)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+const testMsgTemplate = `{
+	"@type": "/ibc.applications.transfer.v1.MsgTransfer",
+	"source_port": "$SOURCE_PORT",
+	"source_channel": "$SOURCE_CHANNEL",
+	"token": {"denom": "stake", "amount": "1"},
+	"sender": "$SENDER",
+	"receiver": "raw:0xabc",
+	"timeout_height": {"revision_number": "$TIMEOUT_REVISION_NUMBER", "revision_height": "$TIMEOUT_REVISION_HEIGHT"},
+	"timeout_timestamp": "$TIMEOUT_TIMESTAMP"
+}`
+
+func TestReadMsgTemplate(t *testing.T) {
+	dir := t.TempDir()
+	msgFile := filepath.Join(dir, "msg.json")
+	require.NoError(t, os.WriteFile(msgFile, []byte(testMsgTemplate), 0o600))
+	emptyFile := filepath.Join(dir, "empty.json")
+	require.NoError(t, os.WriteFile(emptyFile, nil, 0o600))
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  string
+	}{
+		{name: "inline", template: testMsgTemplate, want: testMsgTemplate},
+		{name: "file", template: "@" + msgFile, want: testMsgTemplate},
+		{name: "empty file", template: "@" + emptyFile, wantErr: "message file is empty"},
+		{name: "missing file", template: "@" + filepath.Join(dir, "missing.json"), wantErr: "failed to read message file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMsgTemplate(tt.template)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpandMsgTemplate(t *testing.T) {
+	values := map[string]string{
+		msgTemplateSender:                "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+		msgTemplateSourcePort:            "mymodule",
+		msgTemplateSourceChannel:         "channel-2",
+		msgTemplateTimeoutRevisionNumber: "1",
+		msgTemplateTimeoutRevisionHeight: "500",
+		msgTemplateTimeoutTimestamp:      "1700000000000000000",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "no placeholders", template: `{"port": "transfer"}`, want: `{"port": "transfer"}`},
+		{name: "single placeholder", template: `{"port": "$SOURCE_PORT"}`, want: `{"port": "mymodule"}`},
+		{
+			name:     "repeated placeholders",
+			template: `"$SOURCE_CHANNEL/$SOURCE_CHANNEL"`,
+			want:     `"channel-2/channel-2"`,
+		},
+		{
+			name:     "placeholder prefixes another",
+			template: `"$TIMEOUT_REVISION_NUMBER-$TIMEOUT_REVISION_HEIGHT-$TIMEOUT_TIMESTAMP"`,
+			want:     `"1-500-1700000000000000000"`,
+		},
+		{name: "unknown placeholder", template: `"$RECEIVER"`, want: `"$RECEIVER"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, expandMsgTemplate(tt.template, values))
+		})
+	}
+
+	// the expanded template decodes into the message with the substituted values.
+	cp := &cosmos.CosmosProvider{
+		PCfg: cosmos.CosmosProviderConfig{AccountPrefix: "cosmos"},
+		Cdc:  cosmos.MakeCodec(cosmos.ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+	msg, err := cp.MsgFromJSON([]byte(expandMsgTemplate(testMsgTemplate, values)))
+	require.NoError(t, err)
+
+	transfer, ok := msg.(cosmos.CosmosMessage).Msg.(*transfertypes.MsgTransfer)
+	require.True(t, ok)
+	require.Equal(t, "mymodule", transfer.SourcePort)
+	require.Equal(t, "channel-2", transfer.SourceChannel)
+	require.Equal(t, values[msgTemplateSender], transfer.Sender)
+	require.Equal(t, clienttypes.NewHeight(1, 500), transfer.TimeoutHeight)
+	require.Equal(t, uint64(1700000000000000000), transfer.TimeoutTimestamp)
+}
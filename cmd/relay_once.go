@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/spf13/cobra"
+)
+
+func relayOnceCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relay-once [path_name]?",
+		Short: "Relay everything pending on the paths once, update clients if needed, and exit",
+		Long: strings.TrimSpace(fmt.Sprintf(`Relay the packets and acknowledgements pending on a path, or on all paths,
+in both directions, then update the clients which are past --%s or 2/3 of their
+trusting period, and exit with a JSON summary of the relayed messages, txs and client updates.
+Nothing is kept between runs, which suits cron jobs and scheduled serverless functions.
+The command fails if it cannot finish within --%s, after printing the summary.`,
+			flagThresholdTime, flagMaxDuration,
+		)),
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s relay-once
+$ %s relay-once demo-path --max-duration 60s
+$ %s relay-once demo-path --time-threshold 12h`,
+			appName, appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				paths []string
+				opts  relayer.RelayOnceOptions
+				err   error
+			)
+			if len(args) > 0 {
+				paths = append(paths, args[0])
+			}
+
+			if opts.MaxMsgLength, err = cmd.Flags().GetUint64(flagMaxMsgLength); err != nil {
+				return err
+			}
+			if opts.MaxDuration, err = cmd.Flags().GetDuration(flagMaxDuration); err != nil {
+				return err
+			}
+			if opts.ClientUpdateThreshold, err = cmd.Flags().GetDuration(flagThresholdTime); err != nil {
+				return err
+			}
+
+			summary, err := a.service(cmd).RelayOnce(cmd.Context(), paths, opts)
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(summary, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+
+			if !summary.Complete {
+				return errors.New("relaying did not complete, see the summary")
+			}
+			return nil
+		},
+	}
+
+	cmd = strategyFlag(a.viper, cmd)
+	cmd = updateTimeFlags(a.viper, cmd)
+	cmd = maxDurationFlag(a.viper, cmd)
+	cmd = memoFlag(a.viper, cmd)
+	return cmd
+}
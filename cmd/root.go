@@ -29,6 +29,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	zaplogfmt "github.com/jsternberg/zap-logfmt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -53,7 +55,8 @@ func NewRootCmd(log *zap.Logger) *cobra.Command {
 	// Use a local app state instance scoped to the new root command,
 	// so that tests don't concurrently access the state.
 	a := &appState{
-		viper: viper.New(),
+		viper:        viper.New(),
+		debugLogging: relayer.NewDebugLogging(),
 
 		log: log,
 	}
@@ -72,15 +75,41 @@ func NewRootCmd(log *zap.Logger) *cobra.Command {
 	}
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
-		// Inside persistent pre-run because this takes effect after flags are parsed.
-		// reads `homeDir/config/config.yaml` into `a.Config`
-		if err := a.loadConfigFile(rootCmd.Context()); err != nil {
+		// selects the home directory of the profile, if any, before anything is read from it.
+		if err := a.resolveProfile(cmd); err != nil {
 			return err
 		}
 		// Inside persistent pre-run because this takes effect after flags are parsed.
+		// reads `homeDir/config/config.yaml` into `a.Config`, unless the command must run without a valid config.
+		if _, skip := cmd.Annotations[annotationSkipConfigLoad]; !skip {
+			if err := a.loadConfigFile(rootCmd.Context()); err != nil {
+				return err
+			}
+		}
+		// Inside persistent pre-run because this takes effect after flags are parsed.
 		if a.log == nil {
 			a.initLogger("")
 		}
+		if a.config != nil && a.viper.GetBool(flagAuditProofHeights) {
+			for _, c := range a.config.Chains {
+				c.SetProofHeightAudit(true)
+			}
+		}
+		if a.config != nil && a.viper.GetBool(flagVerifyProofs) {
+			for _, c := range a.config.Chains {
+				// the proofs of other types of chains are not ICS-23 proofs of the cosmos SDK stores.
+				c.SetProofVerification(c.ChainProvider.Type() == "cosmos")
+			}
+		}
+		if generateOnly, _ := cmd.Flags().GetBool(flagGenerateOnly); generateOnly && a.config != nil {
+			for _, c := range a.config.Chains {
+				cp, ok := c.ChainProvider.(*cosmos.CosmosProvider)
+				if !ok {
+					return fmt.Errorf("--%s is not supported for chain type: %s", flagGenerateOnly, c.ChainProvider.Type())
+				}
+				cp.SetGenerateOnly(cmd.OutOrStdout())
+			}
+		}
 		return nil
 	}
 
@@ -95,6 +124,12 @@ func NewRootCmd(log *zap.Logger) *cobra.Command {
 		panic(err)
 	}
 
+	// Register --profile flag
+	rootCmd.PersistentFlags().String(flagProfile, "", "use the home directory of a profile, see 'rly profiles'")
+	if err := a.viper.BindPFlag(flagProfile, rootCmd.PersistentFlags().Lookup(flagProfile)); err != nil {
+		panic(err)
+	}
+
 	// Register --debug flag
 	rootCmd.PersistentFlags().BoolVarP(&a.debug, "debug", "d", false, "debug output")
 	if err := a.viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug")); err != nil {
@@ -112,6 +147,28 @@ func NewRootCmd(log *zap.Logger) *cobra.Command {
 		panic(err)
 	}
 
+	// Register --audit-proof-heights flag
+	rootCmd.PersistentFlags().Bool(
+		flagAuditProofHeights,
+		false,
+		"log the queried, proof, and client consensus heights for every handshake and packet message, "+
+			"and report any unexpected relationship between them",
+	)
+	if err := a.viper.BindPFlag(flagAuditProofHeights, rootCmd.PersistentFlags().Lookup(flagAuditProofHeights)); err != nil {
+		panic(err)
+	}
+
+	// Register --verify-proofs flag
+	rootCmd.PersistentFlags().Bool(
+		flagVerifyProofs,
+		false,
+		"verify the proofs of handshake and packet messages against the headers of the chains they are queried from "+
+			"before sending the messages, so that invalid proofs fail locally instead of on chain",
+	)
+	if err := a.viper.BindPFlag(flagVerifyProofs, rootCmd.PersistentFlags().Lookup(flagVerifyProofs)); err != nil {
+		panic(err)
+	}
+
 	// Register subcommands
 	rootCmd.AddCommand(
 		configCmd(a),
@@ -122,6 +179,12 @@ func NewRootCmd(log *zap.Logger) *cobra.Command {
 		transactionCmd(a),
 		queryCmd(a),
 		startCmd(a),
+		relayOnceCmd(a),
+		benchCmd(a),
+		debugCmd(a),
+		tuiCmd(a),
+		devCmd(a),
+		profilesCmd(a),
 		lineBreakCommand(),
 		getVersionCmd(a),
 		addressCmd(a),
@@ -137,6 +200,8 @@ func Execute() {
 
 	rootCmd := NewRootCmd(nil)
 	rootCmd.SilenceUsage = true
+	// errors are printed below, except for the one of a command run with --generate-only.
+	rootCmd.SilenceErrors = true
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -171,11 +236,18 @@ func Execute() {
 	}()
 
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// a command run with --generate-only stops once it has written out the unsigned tx.
+		if errors.Is(err, cosmos.ErrTxGenerated) {
+			return
+		}
+		rootCmd.PrintErrln(rootCmd.ErrPrefix(), err.Error())
 		os.Exit(1)
 	}
 }
 
-func newRootLogger(format string, logLevel string) (*zap.Logger, error) {
+// newRootLogger returns a logger of the entries enabled by logLevel. If debugLogging is not nil,
+// the debug entries of the chains and paths it enables are logged too.
+func newRootLogger(format string, logLevel string, debugLogging *relayer.DebugLogging) (*zap.Logger, error) {
 	config := zap.NewProductionEncoderConfig()
 	config.EncodeTime = func(ts time.Time, encoder zapcore.PrimitiveArrayEncoder) {
 		encoder.AppendString(ts.UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
@@ -207,11 +279,14 @@ func newRootLogger(format string, logLevel string) (*zap.Logger, error) {
 	case "fatal":
 		level = zapcore.FatalLevel
 	}
-	return zap.New(zapcore.NewCore(
-		enc,
-		os.Stderr,
-		level,
-	)), nil
+	if debugLogging == nil {
+		return zap.New(zapcore.NewCore(
+			enc,
+			os.Stderr,
+			level,
+		)), nil
+	}
+	return zap.New(debugLogging.Core(zapcore.NewCore(enc, os.Stderr, zap.DebugLevel), level)), nil
 }
 
 // readLine reads one line from the given reader.
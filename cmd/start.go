@@ -21,11 +21,19 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"strings"
 
+	"github.com/cosmos/relayer/v2/cregistry"
 	"github.com/cosmos/relayer/v2/internal/relaydebug"
 	"github.com/cosmos/relayer/v2/relayer"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/dedup"
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/gossip"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
+	"github.com/cosmos/relayer/v2/relayer/metrics"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -42,12 +50,38 @@ func startCmd(a *appState) *cobra.Command {
 $ %s start           # start all configured paths
 $ %s start demo-path # start the 'demo-path' path
 $ %s start demo-path --max-msgs 3
-$ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
+$ %s start demo-path2 --max-tx-size 10
+$ %s start demo-path --no-tx # monitor only, without keys
+$ %s start demo-path --from-height chain-a=1200,chain-b=3400 # replay events after a crash or rollback
+$ %s start --route 'osmosis->cosmoshub->juno' # relay the paths of a route
+$ %s start demo-path --same-block-acks # relay acknowledgements as soon as they can be proven
+$ %s start demo-path --standby # take over once another instance relaying 'demo-path' stops`,
+			appName, appName, appName, appName, appName, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			chains := make(map[string]*relayer.Chain)
 			paths := make([]relayer.NamedPath, len(args))
 
-			if len(args) > 0 {
+			routeName, err := cmd.Flags().GetString(flagRoute)
+			if err != nil {
+				return err
+			}
+
+			if routeName != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("paths cannot be given with --%s, which relays the paths of the route", flagRoute)
+				}
+				route, err := a.config.Routes.Get(routeName)
+				if err != nil {
+					return err
+				}
+				if paths, err = route.NamedPaths(a.config.Chains, a.config.Paths); err != nil {
+					return err
+				}
+				for _, p := range paths {
+					chains[p.Path.Src.ChainID] = nil
+					chains[p.Path.Dst.ChainID] = nil
+				}
+			} else if len(args) > 0 {
 				for i, pathName := range args {
 					path := a.config.Paths.MustGet(pathName)
 					paths[i] = relayer.NamedPath{
@@ -78,15 +112,32 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 			}
 
 			// get chain configurations
-			chains, err := a.config.Chains.Gets(chainIDs...)
+			chains, err = a.config.Chains.Gets(chainIDs...)
 			if err != nil {
 				return err
 			}
 
-			if err := ensureKeysExist(chains); err != nil {
+			noTx, err := cmd.Flags().GetBool(flagNoTx)
+			if err != nil {
 				return err
 			}
 
+			// keys are only required for signing txs
+			if !noTx {
+				if err := relayer.EnsureKeysExist(chains); err != nil {
+					return err
+				}
+
+				// keys acting on behalf of an authz-granter must have been granted the messages which relay packets.
+				for _, chain := range chains {
+					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok && ccp.PCfg.AuthzGranter != "" {
+						if err := ccp.ValidateAuthzGrants(cmd.Context(), cosmos.AuthzRelayMsgTypeURLs); err != nil {
+							return err
+						}
+					}
+				}
+			}
+
 			maxMsgLength, err := cmd.Flags().GetUint64(flagMaxMsgLength)
 			if err != nil {
 				return err
@@ -94,15 +145,60 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 
 			var prometheusMetrics *processor.PrometheusMetrics
 
-			debugAddr := a.config.Global.APIListenPort
+			debugAddr, err := a.debugAddr(cmd)
+			if err != nil {
+				return err
+			}
 
-			debugAddrFlag, err := cmd.Flags().GetString(flagDebugAddr)
+			enableControlAPI, err := cmd.Flags().GetBool(flagControlAPI)
 			if err != nil {
 				return err
 			}
 
-			if debugAddrFlag != "" {
-				debugAddr = debugAddrFlag
+			var control *relayer.ControlAPI
+			if enableControlAPI {
+				if debugAddr == "" {
+					return fmt.Errorf("--%s requires a debug address", flagControlAPI)
+				}
+				control = relayer.NewControlAPI()
+				control.SetDebugLogging(a.debugLogging)
+			}
+
+			relayEventsSocket, err := cmd.Flags().GetString(flagRelayEventsSocket)
+			if err != nil {
+				return err
+			}
+
+			var relayEvents *eventstream.Stream
+			if relayEventsSocket != "" || control != nil {
+				relayEvents = eventstream.New()
+			}
+			if relayEventsSocket != "" {
+				ln, err := eventstream.ListenUnix(relayEventsSocket)
+				if err != nil {
+					return fmt.Errorf("failed to listen on relay events socket %q: %w", relayEventsSocket, err)
+				}
+				log := a.log.With(zap.String("sys", "relayevents"))
+				log.Info("Relay events socket listening", zap.String("path", relayEventsSocket))
+				go relayEvents.Serve(cmd.Context(), log, ln)
+			}
+
+			metricsConfig := a.config.Global.Metrics
+			if debugAddr != "" || metricsConfig.Pushes() {
+				prometheusMetrics = processor.NewPrometheusMetrics()
+				for _, chain := range chains {
+					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+						ccp.SetMetrics(prometheusMetrics)
+					}
+				}
+			}
+
+			if metricsConfig.Pushes() {
+				backend, err := metrics.New(cmd.Context(), *metricsConfig)
+				if err != nil {
+					return fmt.Errorf("failed to create %s metrics backend: %w", metricsConfig.Backend, err)
+				}
+				go metrics.Push(cmd.Context(), a.log.With(zap.String("sys", "metrics")), *metricsConfig, prometheusMetrics.Registry, backend)
 			}
 
 			if debugAddr == "" {
@@ -120,13 +216,7 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 				}
 				log := a.log.With(zap.String("sys", "debughttp"))
 				log.Info("Debug server listening", zap.String("addr", debugAddr))
-				prometheusMetrics = processor.NewPrometheusMetrics()
-				relaydebug.StartDebugServer(cmd.Context(), log, ln, prometheusMetrics.Registry)
-				for _, chain := range chains {
-					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
-						ccp.SetMetrics(prometheusMetrics)
-					}
-				}
+				relaydebug.StartDebugServer(cmd.Context(), log, ln, prometheusMetrics.Registry, control)
 			}
 
 			processorType, err := cmd.Flags().GetString(flagProcessor)
@@ -134,6 +224,10 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 				return err
 			}
 
+			if noTx && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagNoTx, relayer.ProcessorEvents)
+			}
+
 			initialBlockHistory, err := cmd.Flags().GetUint64(flagInitialBlockHistory)
 			if err != nil {
 				return err
@@ -144,7 +238,7 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 				return err
 			}
 
-			flushInterval, err := cmd.Flags().GetDuration(flagFlushInterval)
+			flushInterval, err := parseFlushIntervalFromFlags(cmd)
 			if err != nil {
 				return err
 			}
@@ -154,6 +248,194 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 				return err
 			}
 
+			fromHeights, err := parseFromHeightsFromFlags(cmd, chains)
+			if err != nil {
+				return err
+			}
+			if len(fromHeights) > 0 && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagFromHeight, relayer.ProcessorEvents)
+			}
+
+			recordSpend, err := cmd.Flags().GetBool(flagRecordSpend)
+			if err != nil {
+				return err
+			}
+
+			skipRelayed, err := cmd.Flags().GetBool(flagSkipRelayed)
+			if err != nil {
+				return err
+			}
+
+			forceBisection, err := cmd.Flags().GetBool(flagForceBisection)
+			if err != nil {
+				return err
+			}
+
+			if forceBisection && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagForceBisection, relayer.ProcessorEvents)
+			}
+
+			sameBlockAcks, err := cmd.Flags().GetBool(flagSameBlockAcks)
+			if err != nil {
+				return err
+			}
+
+			if sameBlockAcks && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagSameBlockAcks, relayer.ProcessorEvents)
+			}
+
+			txsPerBlock, err := cmd.Flags().GetInt(flagTxsPerBlock)
+			if err != nil {
+				return err
+			}
+
+			if txsPerBlock < 0 {
+				return fmt.Errorf("--%s must not be negative", flagTxsPerBlock)
+			}
+			if txsPerBlock > 0 && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagTxsPerBlock, relayer.ProcessorEvents)
+			}
+
+			indexEvents, err := cmd.Flags().GetBool(flagIndexEvents)
+			if err != nil {
+				return err
+			}
+
+			if indexEvents && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagIndexEvents, relayer.ProcessorEvents)
+			}
+
+			indexRetention, err := cmd.Flags().GetUint64(flagIndexRetention)
+			if err != nil {
+				return err
+			}
+
+			dedupEvents, err := cmd.Flags().GetBool(flagDedupEvents)
+			if err != nil {
+				return err
+			}
+
+			dedupRetention, err := cmd.Flags().GetUint64(flagDedupRetention)
+			if err != nil {
+				return err
+			}
+
+			if dedupEvents && processorType != relayer.ProcessorEvents {
+				return fmt.Errorf("--%s is only supported by the %s processor", flagDedupEvents, relayer.ProcessorEvents)
+			}
+
+			rpcDiscovery, err := cmd.Flags().GetString(flagRPCDiscovery)
+			if err != nil {
+				return err
+			}
+
+			rpcDiscoveryInterval, err := cmd.Flags().GetDuration(flagRPCDiscoveryInterval)
+			if err != nil {
+				return err
+			}
+
+			var endpointResolver relayer.EndpointResolver
+			switch rpcDiscovery {
+			case "":
+			case rpcDiscoveryChainRegistry:
+				endpointResolver = cregistry.NewEndpointResolver(cregistry.DefaultChainRegistry(a.log))
+			default:
+				return fmt.Errorf("unsupported --%s %q, expected %q", flagRPCDiscovery, rpcDiscovery, rpcDiscoveryChainRegistry)
+			}
+
+			if endpointResolver != nil {
+				if rpcDiscoveryInterval <= 0 {
+					return fmt.Errorf("--%s must be positive", flagRPCDiscoveryInterval)
+				}
+				go relayer.WatchEndpoints(cmd.Context(), a.log, chains, endpointResolver, rpcDiscoveryInterval)
+			}
+
+			clientParamsInterval, err := cmd.Flags().GetDuration(flagClientParamsInterval)
+			if err != nil {
+				return err
+			}
+
+			if clientParamsInterval > 0 {
+				go relayer.WatchClientParams(cmd.Context(), a.log, chains, paths, clientParamsInterval, prometheusMetrics)
+			}
+
+			feeClaimInterval, err := cmd.Flags().GetDuration(flagFeeClaimInterval)
+			if err != nil {
+				return err
+			}
+
+			if feeClaimInterval > 0 && !noTx {
+				go relayer.ScheduleFeeClaims(cmd.Context(), a.log, chains, feeClaimInterval, a.config.memo(cmd))
+			}
+
+			if recordSpend {
+				if err := a.recordSpend(chains); err != nil {
+					return err
+				}
+			}
+
+			var processedEvents *processor.ProcessedEvents
+			if dedupEvents {
+				store, err := dedup.OpenStore(a.dedupDBPath())
+				if err != nil {
+					return err
+				}
+				defer store.Close()
+				processedEvents = &processor.ProcessedEvents{Store: store, RetentionBlocks: dedupRetention}
+			}
+
+			coordinator, err := a.startGossip(cmd, processorType)
+			if err != nil {
+				return err
+			}
+
+			for _, chain := range chains {
+				if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+					defer ccp.Close()
+				}
+			}
+
+			var notifier *notify.Notifier
+			if cfg := a.config.Global.Notifications; cfg != nil {
+				notifier, err = notify.New(a.log.With(zap.String("sys", "notify")), *cfg)
+				if err != nil {
+					return err
+				}
+				go notifier.Run(cmd.Context())
+				for _, chain := range chains {
+					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+						ccp.SetNotifier(notifier)
+					}
+				}
+			}
+
+			if indexEvents {
+				index, err := indexer.OpenStore(a.indexDBPath())
+				if err != nil {
+					return err
+				}
+				defer index.Close()
+				index.SetRetention(indexRetention)
+				for _, chain := range chains {
+					if ccp, ok := chain.ChainProvider.(*cosmos.CosmosProvider); ok {
+						ccp.SetPacketIndex(index)
+					}
+				}
+			}
+
+			// instances which send txs must not relay the same paths twice, monitoring instances may.
+			if !noTx {
+				standby, err := cmd.Flags().GetBool(flagStandby)
+				if err != nil {
+					return err
+				}
+				locks, err := a.lockPaths(cmd.Context(), paths, standby)
+				if err != nil {
+					return err
+				}
+				defer locks.unlock()
+			}
+
 			rlyErrCh := relayer.StartRelayer(
 				cmd.Context(),
 				a.log,
@@ -170,6 +452,19 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 				initialBlockHistory,
 				prometheusMetrics,
 				stuckPacket,
+				relayer.StartOptions{
+					StartHeights:       fromHeights,
+					MonitorOnly:        noTx,
+					SkipRelayedPackets: skipRelayed,
+					ForceBisection:     forceBisection,
+					SameBlockAcks:      sameBlockAcks,
+					TxsPerBlock:        txsPerBlock,
+					ProcessedEvents:    processedEvents,
+					Coordinator:        coordinator,
+					Notifier:           notifier,
+					RelayEvents:        relayEvents,
+					Control:            control,
+				},
 			)
 
 			// Block until the error channel sends a message.
@@ -189,10 +484,120 @@ $ %s start demo-path2 --max-tx-size 10`, appName, appName, appName, appName)),
 	cmd = updateTimeFlags(a.viper, cmd)
 	cmd = strategyFlag(a.viper, cmd)
 	cmd = debugServerFlags(a.viper, cmd)
+	cmd = controlAPIFlag(a.viper, cmd)
 	cmd = processorFlag(a.viper, cmd)
 	cmd = initBlockFlag(a.viper, cmd)
 	cmd = flushIntervalFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
 	cmd = stuckPacketFlags(a.viper, cmd)
+	cmd = fromHeightFlag(a.viper, cmd)
+	cmd = routeFlag(a.viper, cmd)
+	cmd = recordSpendFlag(a.viper, cmd)
+	cmd = indexEventsFlag(a.viper, cmd)
+	cmd = noTxFlag(a.viper, cmd)
+	cmd = skipRelayedFlag(a.viper, cmd)
+	cmd = forceBisectionFlag(a.viper, cmd)
+	cmd = sameBlockAcksFlag(a.viper, cmd)
+	cmd = txsPerBlockFlag(a.viper, cmd)
+	cmd = dedupEventsFlags(a.viper, cmd)
+	cmd = standbyFlag(a.viper, cmd)
+	cmd = relayEventsSocketFlag(a.viper, cmd)
+	cmd = rpcDiscoveryFlags(a.viper, cmd)
+	cmd = clientParamsIntervalFlag(a.viper, cmd)
+	cmd = feeClaimIntervalFlag(a.viper, cmd)
+	cmd = gossipFlags(a.viper, cmd)
 	return cmd
 }
+
+// startGossip starts the gossip of packet claims with the relayer set if --gossip-addr is set,
+// and returns the coordinator of the PathProcessors, or nil otherwise.
+func (a *appState) startGossip(cmd *cobra.Command, processorType string) (processor.Coordinator, error) {
+	addr, err := cmd.Flags().GetString(flagGossipAddr)
+	if err != nil || addr == "" {
+		return nil, err
+	}
+	if processorType != relayer.ProcessorEvents {
+		return nil, fmt.Errorf("--%s is only supported by the %s processor", flagGossipAddr, relayer.ProcessorEvents)
+	}
+	bootstrap, err := cmd.Flags().GetStringSlice(flagGossipBootstrap)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := cmd.Flags().GetDuration(flagGossipClaimTTL)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := cmd.Flags().GetStringSlice(flagGossipTrustedPeers)
+	if err != nil {
+		return nil, err
+	}
+	if len(trusted) == 0 {
+		return nil, fmt.Errorf("--%s requires --%s, the IDs of the relayers of the relayer set", flagGossipAddr, flagGossipTrustedPeers)
+	}
+	for _, id := range trusted {
+		if _, err := gossip.ParsePeerID(id); err != nil {
+			return nil, fmt.Errorf("--%s: %w", flagGossipTrustedPeers, err)
+		}
+	}
+
+	url, err := cmd.Flags().GetString(flagGossipURL)
+	if err != nil {
+		return nil, err
+	}
+	if url == "" {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gossip address %q: %w", addr, err)
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to default --%s to the hostname: %w", flagGossipURL, err)
+		}
+		url = "http://" + net.JoinHostPort(hostname, port)
+	}
+
+	keyFile, err := cmd.Flags().GetString(flagGossipKey)
+	if err != nil {
+		return nil, err
+	}
+	if keyFile == "" {
+		keyFile = a.gossipKeyPath()
+	}
+	key, err := gossip.LoadOrCreateKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	log := a.log.With(zap.String("sys", "gossip"))
+	g, err := gossip.New(log, key, url, bootstrap, trusted, ttl)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on gossip address %q: %w", addr, err)
+	}
+	log.Info("Gossip server listening",
+		zap.String("addr", addr),
+		zap.String("url", url),
+		zap.String("relayer_id", g.ID()),
+		zap.Strings("bootstrap", bootstrap),
+	)
+
+	go g.Run(cmd.Context())
+	gossip.Serve(cmd.Context(), log, ln, g)
+	return g, nil
+}
+
+// debugAddr returns the address of the debug server, which is the --debug-addr flag if set,
+// otherwise the api-listen-addr of the global config.
+func (a *appState) debugAddr(cmd *cobra.Command) (string, error) {
+	debugAddr, err := cmd.Flags().GetString(flagDebugAddr)
+	if err != nil {
+		return "", err
+	}
+	if debugAddr == "" {
+		debugAddr = a.config.Global.APIListenPort
+	}
+	return debugAddr, nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor to the top left corner of the terminal and clears it.
+const clearScreen = "\033[H\033[2J"
+
+func tuiCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Live dashboard of the paths of a running relayer",
+		Long: strings.TrimSpace(fmt.Sprintf(`Show a terminal dashboard of the paths of a relayer started with 'rly start --%s',
+refreshed live from its control API: the expiry countdown of the client on each chain, the number of
+pending packets, the last time a transaction succeeded, the wallet balance on each chain and the most
+recent failed transactions. Press Ctrl-C to quit.`,
+			flagControlAPI,
+		)),
+		Args: withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tui
+$ %s tui --debug-addr localhost:7597 --refresh-interval 2s`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			debugAddr, err := a.debugAddr(cmd)
+			if err != nil {
+				return err
+			}
+			if debugAddr == "" {
+				return fmt.Errorf("no debug address, set --%s", flagDebugAddr)
+			}
+
+			refreshInterval, err := cmd.Flags().GetDuration(flagRefreshInterval)
+			if err != nil {
+				return err
+			}
+			if refreshInterval <= 0 {
+				return fmt.Errorf("invalid --%s: %s", flagRefreshInterval, refreshInterval)
+			}
+
+			client, err := newControlClient(debugAddr)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			ticker := time.NewTicker(refreshInterval)
+			defer ticker.Stop()
+			for {
+				var b bytes.Buffer
+				fetchDashboard(ctx, &b, client, debugAddr)
+				fmt.Fprint(cmd.OutOrStdout(), clearScreen+b.String())
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd = debugServerFlags(a.viper, cmd)
+	return refreshIntervalFlag(a.viper, cmd)
+}
+
+// fetchDashboard queries the status of every path of the relayer and renders the dashboard to w.
+// Errors are rendered rather than returned, so that the dashboard recovers once the relayer is reachable again.
+func fetchDashboard(ctx context.Context, w io.Writer, client *controlClient, debugAddr string) {
+	ctx, cancel := context.WithTimeout(ctx, controlRequestTimeout)
+	defer cancel()
+
+	now := time.Now()
+	fmt.Fprintf(w, "%s tui - %s - %s (Ctrl-C to quit)\n\n", appName, debugAddr, now.Format(time.TimeOnly))
+
+	paths, err := client.paths(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(w, "No paths are being relayed")
+		return
+	}
+
+	for _, pathName := range paths {
+		status, err := client.status(ctx, pathName)
+		if err != nil {
+			fmt.Fprintf(w, "%s\n  Error: %v\n\n", pathName, err)
+			continue
+		}
+		renderPathStatus(w, status, now)
+	}
+}
+
+// renderPathStatus renders the status of a path as of now.
+func renderPathStatus(w io.Writer, status processor.PathStatus, now time.Time) {
+	lastRelayed := "never"
+	if !status.LastRelayed.IsZero() {
+		lastRelayed = formatCountdown(now.Sub(status.LastRelayed)) + " ago"
+	}
+	fmt.Fprintf(w, "%s  pending packets: %d  last relayed: %s\n", status.PathName, status.PacketsPending, lastRelayed)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  CHAIN\tCLIENT\tSYNCED\tCLIENT EXPIRES IN\tBALANCE")
+	for _, end := range status.Ends {
+		expiresIn := "unknown"
+		if !end.ClientExpiry.IsZero() {
+			expiresIn = "EXPIRED"
+			if remaining := end.ClientExpiry.Sub(now); remaining > 0 {
+				expiresIn = formatCountdown(remaining)
+			}
+		}
+		balance := end.Balance
+		if balance == "" {
+			balance = "-"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%t\t%s\t%s\n", end.ChainID, end.ClientID, end.InSync, expiresIn, balance)
+	}
+	_ = tw.Flush()
+
+	for _, end := range status.Ends {
+		if end.Upgrade == nil {
+			continue
+		}
+		in := "unknown"
+		if !end.Upgrade.Time.IsZero() {
+			in = formatCountdown(max(end.Upgrade.Time.Sub(now), 0))
+		}
+		fmt.Fprintf(w, "  Upgrade %s of %s at height %d in %s", end.Upgrade.Name, end.ChainID, end.Upgrade.Height, in)
+		if end.Upgrade.Quiesced {
+			fmt.Fprint(w, ", relaying paused")
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(status.RecentErrors) > 0 {
+		fmt.Fprintln(w, "  Recent errors:")
+	}
+	for _, res := range status.RecentErrors {
+		msg := res.Error
+		if msg == "" {
+			msg = fmt.Sprintf("code %d (%s): %s", res.Code, res.Codespace, res.Log)
+		}
+		fmt.Fprintf(w, "    %s %s %s\n", res.Time.Local().Format(time.TimeOnly), res.ChainID, firstLine(msg))
+	}
+	fmt.Fprintln(w)
+}
+
+// formatCountdown formats d to the second, with days as the largest unit, e.g. 13d4h2m0s.
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	if days == 0 {
+		return d.String()
+	}
+	return fmt.Sprintf("%dd%s", days, d-days*24*time.Hour)
+}
+
+// firstLine returns the first line of s, so that multi-line logs do not break the layout of the dashboard.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
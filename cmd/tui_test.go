@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPathStatus(t *testing.T) {
+	now := time.Now()
+	status := processor.PathStatus{
+		PathName: "demo-path",
+		Ends: []processor.PathEndStatus{
+			{ChainID: "chain-a", ClientID: "07-tendermint-0", InSync: true, ClientExpiry: now.Add(50*time.Hour + 30*time.Second), Balance: "100uatom"},
+			{
+				ChainID:      "chain-b",
+				ClientID:     "07-tendermint-1",
+				ClientExpiry: now.Add(-time.Hour),
+				Upgrade:      &processor.ChainUpgrade{Name: "v2", Height: 1000, Time: now.Add(time.Minute), Quiesced: true},
+			},
+		},
+		PacketsPending: 3,
+		LastRelayed:    now.Add(-12 * time.Second),
+		RecentErrors: []processor.TxResult{
+			{Time: now, ChainID: "chain-b", Code: 11, Codespace: "sdk", Log: "out of gas\nin location"},
+		},
+	}
+
+	var b strings.Builder
+	renderPathStatus(&b, status, now)
+	out := b.String()
+
+	require.Contains(t, out, "demo-path  pending packets: 3  last relayed: 12s ago")
+	require.Regexp(t, `chain-a\s+07-tendermint-0\s+true\s+2d2h0m30s\s+100uatom`, out)
+	require.Regexp(t, `chain-b\s+07-tendermint-1\s+false\s+EXPIRED\s+-`, out)
+	require.Contains(t, out, "Upgrade v2 of chain-b at height 1000 in 1m0s, relaying paused\n")
+	require.NotContains(t, out, "of chain-a")
+	require.Contains(t, out, "chain-b code 11 (sdk): out of gas\n")
+	require.NotContains(t, out, "in location")
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/avast/retry-go/v4"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"github.com/cosmos/relayer/v2/relayer"
-	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -20,6 +25,10 @@ import (
 
 const flushTimeout = 10 * time.Minute
 
+// routeHopTimeout is how long a sequential transfer over a route waits for each hop to be received,
+// unless a timeout offset is given for the transfers.
+const routeHopTimeout = 10 * time.Minute
+
 // transactionCmd returns a parent transaction command handler, where all child
 // commands can submit transactions on IBC-connected networks.
 func transactionCmd(a *appState) *cobra.Command {
@@ -40,17 +49,23 @@ Most of these commands take a [path] argument. Make sure:
 		flushCmd(a),
 		relayMsgsCmd(a),
 		relayAcksCmd(a),
+		relayPacketCmd(a),
 		xfersend(a),
+		rawCmd(a),
 		lineBreakCommand(),
 		createClientsCmd(a),
 		createClientCmd(a),
 		updateClientsCmd(a),
 		upgradeClientsCmd(a),
+		recreateClientCmd(a),
 		createConnectionCmd(a),
 		createChannelCmd(a),
 		closeChannelCmd(a),
+		channelUpgradeCmd(a),
 		lineBreakCommand(),
 		registerCounterpartyCmd(a),
+		claimFeesCmd(a),
+		broadcastSignedCmd(a),
 	)
 
 	return cmd
@@ -75,29 +90,24 @@ func createClientsCmd(a *appState) *cobra.Command {
 				return err
 			}
 
-			customClientTrustingPeriod, err := cmd.Flags().GetDuration(flagClientTrustingPeriod)
+			override, err := cmd.Flags().GetBool(flagOverride)
 			if err != nil {
 				return err
 			}
 
-			maxClockDrift, err := cmd.Flags().GetDuration(flagMaxClockDrift)
-			if err != nil {
-				return err
-			}
+			path := args[0]
 
-			customClientTrustingPeriodPercentage, err := cmd.Flags().GetInt64(flagClientTrustingPeriodPercentage)
+			c, src, dst, err := a.config.ChainsFromPath(path)
 			if err != nil {
 				return err
 			}
 
-			override, err := cmd.Flags().GetBool(flagOverride)
+			customClientTrustingPeriod, customClientTrustingPeriodPercentage, maxClockDrift, err := a.config.clientTrustOptions(cmd, path)
 			if err != nil {
 				return err
 			}
 
-			path := args[0]
-
-			c, src, dst, err := a.config.ChainsFromPath(path)
+			trustLevel, err := a.config.clientTrustLevel(cmd, path)
 			if err != nil {
 				return err
 			}
@@ -119,6 +129,7 @@ func createClientsCmd(a *appState) *cobra.Command {
 				customClientTrustingPeriod,
 				maxClockDrift,
 				customClientTrustingPeriodPercentage,
+				trustLevel,
 				a.config.memo(cmd),
 			)
 			if err != nil {
@@ -137,6 +148,7 @@ func createClientsCmd(a *appState) *cobra.Command {
 
 	cmd = clientParameterFlags(a.viper, cmd)
 	cmd = overrideFlag(a.viper, cmd)
+	cmd = generateOnlyFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
 	return cmd
 }
@@ -160,16 +172,6 @@ func createClientCmd(a *appState) *cobra.Command {
 				return err
 			}
 
-			customClientTrustingPeriod, err := cmd.Flags().GetDuration(flagClientTrustingPeriod)
-			if err != nil {
-				return err
-			}
-
-			customClientTrustingPeriodPercentage, err := cmd.Flags().GetInt64(flagClientTrustingPeriodPercentage)
-			if err != nil {
-				return err
-			}
-
 			overrideUnbondingPeriod, err := cmd.Flags().GetDuration(flagClientUnbondingPeriod)
 			if err != nil {
 				return err
@@ -180,11 +182,6 @@ func createClientCmd(a *appState) *cobra.Command {
 				return err
 			}
 
-			maxClockDrift, err := cmd.Flags().GetDuration(flagMaxClockDrift)
-			if err != nil {
-				return err
-			}
-
 			src, ok := a.config.Chains[args[0]]
 			if !ok {
 				return errChainNotFound(args[0])
@@ -201,6 +198,16 @@ func createClientCmd(a *appState) *cobra.Command {
 				return err
 			}
 
+			customClientTrustingPeriod, customClientTrustingPeriodPercentage, maxClockDrift, err := a.config.clientTrustOptions(cmd, pathName)
+			if err != nil {
+				return err
+			}
+
+			trustLevel, err := a.config.clientTrustLevel(cmd, pathName)
+			if err != nil {
+				return err
+			}
+
 			src.PathEnd = path.End(src.ChainID())
 			dst.PathEnd = path.End(dst.ChainID())
 
@@ -265,6 +272,7 @@ func createClientCmd(a *appState) *cobra.Command {
 				overrideUnbondingPeriod,
 				maxClockDrift,
 				customClientTrustingPeriodPercentage,
+				trustLevel,
 				a.config.memo(cmd),
 			)
 			if err != nil {
@@ -291,41 +299,127 @@ func createClientCmd(a *appState) *cobra.Command {
 	cmd = clientParameterFlags(a.viper, cmd)
 	cmd = clientUnbondingPeriodFlag(a.viper, cmd)
 	cmd = overrideFlag(a.viper, cmd)
+	cmd = generateOnlyFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
 	return cmd
 }
 
 func updateClientsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "update-clients path_name",
+		Use:   "update-clients [path_name]",
 		Short: "update IBC clients between two configured chains with a configured path",
-		Long: `Updates IBC client for chain configured on each end of the supplied path.
+		Long: strings.TrimSpace(fmt.Sprintf(`Updates IBC client for chain configured on each end of the supplied path.
 Clients are updated by querying headers from each chain and then sending the
-corresponding update-client messages.`,
-		Args:    withUsage(cobra.ExactArgs(1)),
-		Example: strings.TrimSpace(fmt.Sprintf(`$ %s transact update-clients demo-path`, appName)),
+corresponding update-client messages.
+
+With --%s, the clients of every configured path are updated instead, e.g. from cron by operators
+who do not keep 'rly start' running. With --%s, only the paths with a client which was last
+updated longer ago than the given duration are updated. A path which fails to update does not
+stop the others from being updated.`, flagAll, flagStale)),
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s transact update-clients demo-path
+$ %s tx update-clients --all
+$ %s tx update-clients --all --stale 12h`,
+			appName, appName, appName,
+		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, src, dst, err := a.config.ChainsFromPath(args[0])
+			all, err := cmd.Flags().GetBool(flagAll)
+			if err != nil {
+				return err
+			}
+			stale, err := cmd.Flags().GetDuration(flagStale)
 			if err != nil {
 				return err
 			}
+			if all == (len(args) == 1) {
+				return fmt.Errorf("specify either a path name or --%s", flagAll)
+			}
 
-			// ensure that keys exist
-			if exists := c[src].ChainProvider.KeyExists(c[src].ChainProvider.Key()); !exists {
-				return fmt.Errorf("key %s not found on src chain %s", c[src].ChainProvider.Key(), c[src].ChainID())
+			forceBisection, err := cmd.Flags().GetBool(flagForceBisection)
+			if err != nil {
+				return err
 			}
 
-			if exists := c[dst].ChainProvider.KeyExists(c[dst].ChainProvider.Key()); !exists {
-				return fmt.Errorf("key %s not found on dst chain %s", c[dst].ChainProvider.Key(), c[dst].ChainID())
+			memo := a.config.memo(cmd)
+			if !all {
+				return updatePathClients(cmd.Context(), a, args[0], stale, forceBisection, memo)
+			}
+
+			names := make([]string, 0, len(a.config.Paths))
+			for name, pth := range a.config.Paths {
+				if pth.IsLocalhost() {
+					// localhost clients track the chain they are on and are never updated.
+					continue
+				}
+				names = append(names, name)
 			}
+			sort.Strings(names)
 
-			return relayer.UpdateClients(cmd.Context(), c[src], c[dst], a.config.memo(cmd))
+			var errs []error
+			for _, name := range names {
+				if err := updatePathClients(cmd.Context(), a, name, stale, forceBisection, memo); err != nil {
+					a.log.Warn("Failed to update clients", zap.String("path_name", name), zap.Error(err))
+					errs = append(errs, fmt.Errorf("path %s: %w", name, err))
+				}
+			}
+			return errors.Join(errs...)
 		},
 	}
 
+	cmd = updateAllFlags(a.viper, cmd)
+	cmd = forceBisectionFlag(a.viper, cmd)
+	cmd = generateOnlyFlag(a.viper, cmd)
 	return memoFlag(a.viper, cmd)
 }
 
+// updatePathClients updates the clients on both ends of the path, unless stale is positive and
+// both clients were updated within stale. If forceBisection is set, a client which cannot be updated in one update
+// is caught up through intermediate heights.
+func updatePathClients(
+	ctx context.Context,
+	a *appState,
+	pathName string,
+	stale time.Duration,
+	forceBisection bool,
+	memo string,
+) error {
+	c, src, dst, err := a.config.ChainsFromPath(pathName)
+	if err != nil {
+		return err
+	}
+
+	// ensure that keys exist
+	if exists := c[src].ChainProvider.KeyExists(c[src].ChainProvider.Key()); !exists {
+		return fmt.Errorf("key %s not found on src chain %s", c[src].ChainProvider.Key(), c[src].ChainID())
+	}
+
+	if exists := c[dst].ChainProvider.KeyExists(c[dst].ChainProvider.Key()); !exists {
+		return fmt.Errorf("key %s not found on dst chain %s", c[dst].ChainProvider.Key(), c[dst].ChainID())
+	}
+
+	if stale > 0 {
+		srcUpdated, _, err := relayer.QueryClientUpdateTime(ctx, c[src], c[dst])
+		if err != nil {
+			return err
+		}
+		dstUpdated, _, err := relayer.QueryClientUpdateTime(ctx, c[dst], c[src])
+		if err != nil {
+			return err
+		}
+		if time.Since(srcUpdated) <= stale && time.Since(dstUpdated) <= stale {
+			a.log.Info("Skipping path with recently updated clients",
+				zap.String("path_name", pathName),
+				zap.Time("src_client_updated", srcUpdated),
+				zap.Time("dst_client_updated", dstUpdated),
+			)
+			return nil
+		}
+	}
+
+	return relayer.UpdateClients(ctx, c[src], c[dst], forceBisection, memo)
+}
+
 func upgradeClientsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "upgrade-clients path_name chain_id",
@@ -365,22 +459,28 @@ func upgradeClientsCmd(a *appState) *cobra.Command {
 	}
 
 	cmd = heightFlag(a.viper, cmd)
+	cmd = generateOnlyFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
 	return cmd
 }
 
-func createConnectionCmd(a *appState) *cobra.Command {
+func recreateClientCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "connection path_name",
-		Aliases: []string{"conn"},
-		Short:   "create a connection between two configured chains with a configured path; if existing client does not exist, it will create one",
-		Long: strings.TrimSpace(`Create or repair a connection between two IBC-connected networks
-along a specific path.`,
-		),
+		Use:   "recreate-client path_name",
+		Short: "create new clients in place of the expired clients on a configured path",
+		Long: strings.TrimSpace(`Create new clients in place of the expired clients on each end of a configured path,
+as an alternative to recovering the expired clients through governance.
+
+Without --update-path, the path config is left as is, and the identifiers of the new clients are
+printed. With --update-path, the path config is rewritten to use the new clients, and the previous
+identifiers are kept in the history of the path for audit purposes. A new connection is then opened
+on the new clients, and a new channel is opened on it for each channel that was open on the previous
+connection. The path config is updated after each step, and channels in the path's channel filter
+are replaced by their new counterparts.`),
 		Args: withUsage(cobra.ExactArgs(1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
-$ %s transact connection demo-path
-$ %s tx conn demo-path --timeout 5s`,
+$ %s tx recreate-client demo-path
+$ %s tx recreate-client demo-path --update-path`,
 			appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -394,12 +494,221 @@ $ %s tx conn demo-path --timeout 5s`,
 				return err
 			}
 
-			customClientTrustingPeriod, err := cmd.Flags().GetDuration(flagClientTrustingPeriod)
+			updatePath, err := cmd.Flags().GetBool(flagUpdatePath)
+			if err != nil {
+				return err
+			}
+
+			initialBlockHistory, err := cmd.Flags().GetUint64(flagInitialBlockHistory)
+			if err != nil {
+				return err
+			}
+
+			pathName := args[0]
+
+			pth, err := a.config.Paths.Get(pathName)
+			if err != nil {
+				return err
+			}
+
+			customClientTrustingPeriod, customClientTrustingPeriodPercentage, maxClockDrift, err := a.config.clientTrustOptions(cmd, pathName)
+			if err != nil {
+				return err
+			}
+
+			trustLevel, err := a.config.clientTrustLevel(cmd, pathName)
+			if err != nil {
+				return err
+			}
+
+			retryPolicy, err := a.config.retryPolicy(cmd, pathName)
+			if err != nil {
+				return err
+			}
+
+			src, dst := pth.Src.ChainID, pth.Dst.ChainID
+			c, err := a.config.Chains.Gets(src, dst)
+			if err != nil {
+				return err
+			}
+
+			// work on copies of the path ends, which are written to the path config after each step that succeeds,
+			// so that no identifier created on chain is lost if a later step fails.
+			srcEnd, dstEnd := *pth.Src, *pth.Dst
+			c[src].PathEnd = &srcEnd
+			c[dst].PathEnd = &dstEnd
+
+			if err := relayer.ValidateClientPaths(c[src], c[dst]); err != nil {
+				return err
+			}
+
+			// ensure that keys exist
+			if exists := c[src].ChainProvider.KeyExists(c[src].ChainProvider.Key()); !exists {
+				return fmt.Errorf("key %s not found on src chain %s", c[src].ChainProvider.Key(), c[src].ChainID())
+			}
+
+			if exists := c[dst].ChainProvider.KeyExists(c[dst].ChainProvider.Key()); !exists {
+				return fmt.Errorf("key %s not found on dst chain %s", c[dst].ChainProvider.Key(), c[dst].ChainID())
+			}
+
+			srcExpired, err := relayer.ClientExpired(cmd.Context(), c[src], c[dst])
+			if err != nil {
+				return err
+			}
+
+			dstExpired, err := relayer.ClientExpired(cmd.Context(), c[dst], c[src])
+			if err != nil {
+				return err
+			}
+
+			if !srcExpired && !dstExpired {
+				return fmt.Errorf("neither client{%s} on chain{%s} nor client{%s} on chain{%s} has expired",
+					srcEnd.ClientID, src, dstEnd.ClientID, dst)
+			}
+
+			var expired []string
+			if srcExpired {
+				expired = append(expired, fmt.Sprintf("%s on %s", srcEnd.ClientID, src))
+			}
+			if dstExpired {
+				expired = append(expired, fmt.Sprintf("%s on %s", dstEnd.ClientID, dst))
+			}
+
+			// the channels on the previous connection must be queried before the path ends are replaced.
+			var channels []*chantypes.IdentifiedChannel
+			if updatePath && srcEnd.ConnectionID != "" {
+				channels, err = c[src].OpenChannelsOnConnection(cmd.Context())
+				if err != nil {
+					return err
+				}
+			}
+
+			if srcExpired {
+				srcEnd.ClientID = ""
+			}
+			if dstExpired {
+				dstEnd.ClientID = ""
+			}
+
+			memo := a.config.memo(cmd)
+
+			if _, _, err := c[src].CreateClients(
+				cmd.Context(),
+				c[dst],
+				allowUpdateAfterExpiry,
+				allowUpdateAfterMisbehaviour,
+				false,
+				customClientTrustingPeriod,
+				maxClockDrift,
+				customClientTrustingPeriodPercentage,
+				trustLevel,
+				memo,
+			); err != nil {
+				return fmt.Errorf("error creating clients: %w", err)
+			}
+
+			a.log.Info(
+				"Recreated expired clients",
+				zap.String("path_name", pathName),
+				zap.Strings("expired", expired),
+				zap.String("src_client_id", srcEnd.ClientID),
+				zap.String("dst_client_id", dstEnd.ClientID),
+			)
+
+			if !updatePath {
+				fmt.Fprintf(cmd.OutOrStdout(), "created client {%s} on chain {%s} and client {%s} on chain {%s}, "+
+					"use them with 'rly paths update %s --src-client-id %s --dst-client-id %s' "+
+					"and open a connection on them with 'rly tx connection %s'\n",
+					srcEnd.ClientID, src, dstEnd.ClientID, dst, pathName, srcEnd.ClientID, dstEnd.ClientID, pathName)
+				return nil
+			}
+
+			// the previous connection is on the expired clients.
+			srcEnd.ConnectionID = ""
+			dstEnd.ConnectionID = ""
+
+			if err := a.replacePathEnds(
+				cmd.Context(),
+				pathName,
+				srcEnd,
+				dstEnd,
+				nil,
+				"recreated expired client(s) "+strings.Join(expired, ", "),
+			); err != nil {
+				return err
+			}
+
+			if _, _, err := c[src].CreateOpenConnections(
+				cmd.Context(),
+				c[dst],
+				retryPolicy,
+				false,
+				memo,
+				initialBlockHistory,
+				pathName,
+			); err != nil {
+				// persist the connection identifiers of an interrupted handshake, so that it can be resumed.
+				if updateErr := a.updatePathEnds(cmd.Context(), pathName, srcEnd, dstEnd, nil); updateErr != nil {
+					a.log.Warn("Failed to update path config", zap.String("path_name", pathName), zap.Error(updateErr))
+				}
+				return fmt.Errorf("error creating connections on clients{%s, %s}: %w", srcEnd.ClientID, dstEnd.ClientID, err)
+			}
+
+			if err := a.updatePathEnds(cmd.Context(), pathName, srcEnd, dstEnd, nil); err != nil {
+				return err
+			}
+
+			recreatedChannels, err := c[src].RecreateChannels(cmd.Context(), c[dst], channels, retryPolicy, memo, pathName)
+
+			// the channels recreated before any failure are persisted as well.
+			if updateErr := a.updatePathEnds(cmd.Context(), pathName, srcEnd, dstEnd, recreatedChannels); updateErr != nil {
+				if err == nil {
+					return updateErr
+				}
+				a.log.Warn("Failed to update path config", zap.String("path_name", pathName), zap.Error(updateErr))
+			}
+			if err != nil {
+				return fmt.Errorf("error recreating channels on connections{%s, %s}: %w", srcEnd.ConnectionID, dstEnd.ConnectionID, err)
+			}
+
+			return nil
+		},
+	}
+	cmd = updatePathFlag(a.viper, cmd)
+	cmd = timeoutFlag(a.viper, cmd)
+	cmd = retryFlag(a.viper, cmd)
+	cmd = clientParameterFlags(a.viper, cmd)
+	cmd = generateOnlyFlag(a.viper, cmd)
+	cmd = memoFlag(a.viper, cmd)
+	cmd = initBlockFlag(a.viper, cmd)
+	return cmd
+}
+
+func createConnectionCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "connection path_name",
+		Aliases: []string{"conn"},
+		Short:   "create a connection between two configured chains with a configured path; if existing client does not exist, it will create one",
+		Long: strings.TrimSpace(`Create or repair a connection between two IBC-connected networks
+along a specific path.
+
+An incomplete handshake recorded in the path is resumed, and an open connection between the
+clients of the path is reused, instead of opening a new connection. Use --override to always
+open a new connection.`,
+		),
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s transact connection demo-path
+$ %s tx conn demo-path --timeout 5s`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			allowUpdateAfterExpiry, err := cmd.Flags().GetBool(flagUpdateAfterExpiry)
 			if err != nil {
 				return err
 			}
 
-			customClientTrustingPeriodPercentage, err := cmd.Flags().GetInt64(flagClientTrustingPeriodPercentage)
+			allowUpdateAfterMisbehaviour, err := cmd.Flags().GetBool(flagUpdateAfterMisbehaviour)
 			if err != nil {
 				return err
 			}
@@ -411,22 +720,22 @@ $ %s tx conn demo-path --timeout 5s`,
 				return err
 			}
 
-			to, err := getTimeout(cmd)
+			customClientTrustingPeriod, customClientTrustingPeriodPercentage, maxClockDrift, err := a.config.clientTrustOptions(cmd, pathName)
 			if err != nil {
 				return err
 			}
 
-			retries, err := cmd.Flags().GetUint64(flagMaxRetries)
+			trustLevel, err := a.config.clientTrustLevel(cmd, pathName)
 			if err != nil {
 				return err
 			}
 
-			override, err := cmd.Flags().GetBool(flagOverride)
+			retryPolicy, err := a.config.retryPolicy(cmd, pathName)
 			if err != nil {
 				return err
 			}
 
-			maxClockDrift, err := cmd.Flags().GetDuration(flagMaxClockDrift)
+			override, err := cmd.Flags().GetBool(flagOverride)
 			if err != nil {
 				return err
 			}
@@ -457,6 +766,7 @@ $ %s tx conn demo-path --timeout 5s`,
 				customClientTrustingPeriod,
 				maxClockDrift,
 				customClientTrustingPeriodPercentage,
+				trustLevel,
 				memo,
 			)
 			if err != nil {
@@ -472,13 +782,19 @@ $ %s tx conn demo-path --timeout 5s`,
 			connectionSrc, connectionDst, err := c[src].CreateOpenConnections(
 				cmd.Context(),
 				c[dst],
-				retries,
-				to,
+				retryPolicy,
+				override,
 				memo,
 				initialBlockHistory,
 				pathName,
 			)
 			if err != nil {
+				// persist the connection ends of an interrupted handshake, so that it can be resumed.
+				if updateErr := a.updatePathConfig(
+					cmd.Context(), pathName, "", "", c[src].PathEnd.ConnectionID, c[dst].PathEnd.ConnectionID,
+				); updateErr != nil {
+					a.log.Warn("Failed to update path config", zap.String("path_name", pathName), zap.Error(updateErr))
+				}
 				return err
 			}
 
@@ -513,8 +829,9 @@ along a specific path.`,
 		Args: withUsage(cobra.ExactArgs(1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s transact channel demo-path --src-port transfer --dst-port transfer --order unordered --version ics20-1
-$ %s tx chan demo-path --timeout 5s --max-retries 10`,
-			appName, appName,
+$ %s tx chan demo-path --timeout 5s --max-retries 10
+$ %s tx chan demo-path --src-port transfer --dst-port transfer --gov-chain neutron-1 --gov-deposit 10000000untrn`,
+			appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			pathName := args[0]
@@ -549,12 +866,17 @@ $ %s tx chan demo-path --timeout 5s --max-retries 10`,
 				return err
 			}
 
-			to, err := getTimeout(cmd)
+			srcChannel, err := cmd.Flags().GetString(flagSrcChannel)
+			if err != nil {
+				return err
+			}
+
+			dstChannel, err := cmd.Flags().GetString(flagDstChannel)
 			if err != nil {
 				return err
 			}
 
-			retries, err := cmd.Flags().GetUint64(flagMaxRetries)
+			retryPolicy, err := a.config.retryPolicy(cmd, pathName)
 			if err != nil {
 				return err
 			}
@@ -568,16 +890,24 @@ $ %s tx chan demo-path --timeout 5s --max-retries 10`,
 				return fmt.Errorf("key %s not found on dst chain %s", c[dst].ChainProvider.Key(), c[dst].ChainID())
 			}
 
+			// a resumed handshake has been started by governance already.
+			if srcChannel == "" && dstChannel == "" {
+				if srcChannel, dstChannel, err = openChannelByGovernance(cmd, a, c[src], c[dst], srcPort, dstPort, order, version); err != nil {
+					return err
+				}
+			}
+
 			// create channel if it isn't already created
 			return c[src].CreateOpenChannels(
 				cmd.Context(),
 				c[dst],
-				retries,
-				to,
+				retryPolicy,
 				srcPort,
 				dstPort,
 				order,
 				version,
+				srcChannel,
+				dstChannel,
 				override,
 				a.config.memo(cmd),
 				pathName,
@@ -589,10 +919,65 @@ $ %s tx chan demo-path --timeout 5s --max-retries 10`,
 	cmd = retryFlag(a.viper, cmd)
 	cmd = overrideFlag(a.viper, cmd)
 	cmd = channelParameterFlags(a.viper, cmd)
+	cmd = resumeChannelFlags(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
+	cmd = govChannelFlags(a.viper, cmd)
 	return cmd
 }
 
+// openChannelByGovernance starts the channel handshake with a governance proposal on the end of the path
+// named by the gov-chain flag, if any, on which only governance may open channels. It returns the channel
+// opened by the proposal on the src or dst chain of the path, so that its handshake is resumed.
+func openChannelByGovernance(
+	cmd *cobra.Command,
+	a *appState,
+	src, dst *relayer.Chain,
+	srcPort, dstPort, order, version string,
+) (srcChannel, dstChannel string, err error) {
+	govChain, err := cmd.Flags().GetString(flagGovChain)
+	if err != nil {
+		return "", "", err
+	}
+	if govChain == "" {
+		return "", "", nil
+	}
+
+	onDst := false
+	switch govChain {
+	case src.ChainID():
+	case dst.ChainID():
+		src, dst = dst, src
+		srcPort, dstPort = dstPort, srcPort
+		onDst = true
+	default:
+		return "", "", fmt.Errorf("gov chain %s is neither end of the path, %s or %s", govChain, src.ChainID(), dst.ChainID())
+	}
+
+	proposalFile, err := cmd.Flags().GetString(flagGovProposal)
+	if err != nil {
+		return "", "", err
+	}
+	depositStr, err := cmd.Flags().GetString(flagGovDeposit)
+	if err != nil {
+		return "", "", err
+	}
+	deposit, err := sdk.ParseCoinsNormalized(depositStr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid gov deposit %s: %w", depositStr, err)
+	}
+
+	channel, err := src.OpenChannelByGovernance(cmd.Context(), dst, srcPort, dstPort, order, version, relayer.GovChannelOpenOptions{
+		ProposalFile: proposalFile,
+		Out:          cmd.OutOrStdout(),
+		Deposit:      deposit,
+		Memo:         a.config.memo(cmd),
+	})
+	if onDst {
+		return "", channel, err
+	}
+	return channel, "", err
+}
+
 func closeChannelCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "channel-close path_name src_channel_id src_port_id",
@@ -613,12 +998,7 @@ $ %s tx channel-close demo-path channel-0 transfer -o 3s`,
 				return err
 			}
 
-			to, err := getTimeout(cmd)
-			if err != nil {
-				return err
-			}
-
-			retries, err := cmd.Flags().GetUint64(flagMaxRetries)
+			retryPolicy, err := a.config.retryPolicy(cmd, pathName)
 			if err != nil {
 				return err
 			}
@@ -645,7 +1025,7 @@ $ %s tx channel-close demo-path channel-0 transfer -o 3s`,
 				return err
 			}
 
-			return c[src].CloseChannel(cmd.Context(), c[dst], retries, to, channelID, portID, a.config.memo(cmd), pathName)
+			return c[src].CloseChannel(cmd.Context(), c[dst], retryPolicy, channelID, portID, a.config.memo(cmd), pathName)
 		},
 	}
 
@@ -655,176 +1035,136 @@ $ %s tx channel-close demo-path channel-0 transfer -o 3s`,
 	return cmd
 }
 
-func linkCmd(a *appState) *cobra.Command {
+func channelUpgradeCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "link path_name",
-		Aliases: []string{"connect"},
-		Short:   "create clients, connection, and channel between two configured chains with a configured path",
-		Long: strings.TrimSpace(`Create an IBC client between two IBC-enabled networks, in addition
-to creating a connection and a channel between the two networks on a configured path.`,
+		Use:   "channel-upgrade path_name src_channel_id src_port_id",
+		Short: "relay the handshake of a channel upgrade initialized on the src chain until the upgraded channel is open",
+		Long: strings.TrimSpace(`Relay the channel upgrade handshake (try, ack, confirm and open) for a channel
+upgrade which has already been initialized on the src chain, e.g. through a governance proposal.
+Cancellations and timeouts of the upgrade are relayed as well.`,
 		),
-		Args: withUsage(cobra.ExactArgs(1)),
+		Args: withUsage(cobra.ExactArgs(3)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
-$ %s transact link demo-path --src-port transfer --dst-port transfer
-$ %s tx link demo-path
-$ %s tx connect demo-path --src-port transfer --dst-port transfer --order unordered --version ics20-1`,
-			appName, appName, appName,
+$ %s transact channel-upgrade demo-path channel-0 transfer
+$ %s tx channel-upgrade demo-path channel-0 transfer --timeout 5s`,
+			appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			allowUpdateAfterExpiry, err := cmd.Flags().GetBool(flagUpdateAfterExpiry)
+			pathName := args[0]
+
+			c, src, dst, err := a.config.ChainsFromPath(pathName)
 			if err != nil {
 				return err
 			}
 
-			allowUpdateAfterMisbehaviour, err := cmd.Flags().GetBool(flagUpdateAfterMisbehaviour)
+			retryPolicy, err := a.config.retryPolicy(cmd, pathName)
 			if err != nil {
 				return err
 			}
 
-			customClientTrustingPeriod, err := cmd.Flags().GetDuration(flagClientTrustingPeriod)
-			if err != nil {
-				return err
+			channelID := args[1]
+			portID := args[2]
+
+			// ensure that keys exist
+			if exists := c[src].ChainProvider.KeyExists(c[src].ChainProvider.Key()); !exists {
+				return fmt.Errorf("key %s not found on src chain %s", c[src].ChainProvider.Key(), c[src].ChainID())
 			}
 
-			customClientTrustingPeriodPercentage, err := cmd.Flags().GetInt64(flagClientTrustingPeriodPercentage)
-			if err != nil {
-				return err
+			if exists := c[dst].ChainProvider.KeyExists(c[dst].ChainProvider.Key()); !exists {
+				return fmt.Errorf("key %s not found on dst chain %s", c[dst].ChainProvider.Key(), c[dst].ChainID())
 			}
 
-			pathName := args[0]
+			return c[src].RelayChannelUpgrade(cmd.Context(), c[dst], retryPolicy, channelID, portID, a.config.memo(cmd), pathName)
+		},
+	}
 
-			pth, err := a.config.Paths.Get(pathName)
-			if err != nil {
-				return err
-			}
+	cmd = timeoutFlag(a.viper, cmd)
+	cmd = retryFlag(a.viper, cmd)
+	cmd = memoFlag(a.viper, cmd)
+	return cmd
+}
 
-			src, dst := pth.Src.ChainID, pth.Dst.ChainID
-			c, err := a.config.Chains.Gets(src, dst)
-			if err != nil {
-				return err
-			}
+func linkCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "link path_name",
+		Aliases: []string{"connect"},
+		Short:   "create clients, connection, and channel between two configured chains with a configured path",
+		Long: strings.TrimSpace(`Create an IBC client between two IBC-enabled networks, in addition
+to creating a connection and a channel between the two networks on a configured path.`,
+		),
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s transact link demo-path --src-port transfer --dst-port transfer
+$ %s tx link demo-path
+$ %s tx connect demo-path --src-port transfer --dst-port transfer --order unordered --version ics20-1`,
+			appName, appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
 
-			c[src].PathEnd = pth.Src
-			c[dst].PathEnd = pth.Dst
+			opts := relayer.DefaultLinkOptions()
+			var err error
 
-			srcPort, err := cmd.Flags().GetString(flagSrcPort)
-			if err != nil {
+			if opts.AllowUpdateAfterExpiry, err = cmd.Flags().GetBool(flagUpdateAfterExpiry); err != nil {
 				return err
 			}
 
-			dstPort, err := cmd.Flags().GetString(flagDstPort)
-			if err != nil {
+			if opts.AllowUpdateAfterMisbehaviour, err = cmd.Flags().GetBool(flagUpdateAfterMisbehaviour); err != nil {
 				return err
 			}
 
-			order, err := cmd.Flags().GetString(flagOrder)
-			if err != nil {
+			if opts.ClientTrustingPeriod, opts.ClientTrustingPeriodPercentage, opts.MaxClockDrift, err = a.config.clientTrustOptions(cmd, pathName); err != nil {
 				return err
 			}
 
-			version, err := cmd.Flags().GetString(flagVersion)
-			if err != nil {
+			if opts.TrustLevel, err = a.config.clientTrustLevel(cmd, pathName); err != nil {
 				return err
 			}
 
-			to, err := getTimeout(cmd)
-			if err != nil {
+			if opts.SrcPortID, err = cmd.Flags().GetString(flagSrcPort); err != nil {
 				return err
 			}
 
-			retries, err := cmd.Flags().GetUint64(flagMaxRetries)
-			if err != nil {
+			if opts.DstPortID, err = cmd.Flags().GetString(flagDstPort); err != nil {
 				return err
 			}
 
-			override, err := cmd.Flags().GetBool(flagOverride)
-			if err != nil {
+			if opts.Order, err = cmd.Flags().GetString(flagOrder); err != nil {
 				return err
 			}
 
-			maxClockDrift, err := cmd.Flags().GetDuration(flagMaxClockDrift)
-			if err != nil {
+			if opts.Version, err = cmd.Flags().GetString(flagVersion); err != nil {
 				return err
 			}
 
-			// ensure that keys exist
-			if exists := c[src].ChainProvider.KeyExists(c[src].ChainProvider.Key()); !exists {
-				return fmt.Errorf("key %s not found on src chain %s", c[src].ChainProvider.Key(), c[src].ChainID())
-			}
-
-			if exists := c[dst].ChainProvider.KeyExists(c[dst].ChainProvider.Key()); !exists {
-				return fmt.Errorf("key %s not found on dst chain %s", c[dst].ChainProvider.Key(), c[dst].ChainID())
-			}
-
-			memo := a.config.memo(cmd)
-
-			initialBlockHistory, err := cmd.Flags().GetUint64(flagInitialBlockHistory)
-			if err != nil {
+			if opts.SrcChannelID, err = cmd.Flags().GetString(flagSrcChannel); err != nil {
 				return err
 			}
 
-			// create clients if they aren't already created
-			clientSrc, clientDst, err := c[src].CreateClients(
-				cmd.Context(),
-				c[dst],
-				allowUpdateAfterExpiry,
-				allowUpdateAfterMisbehaviour,
-				override,
-				customClientTrustingPeriod,
-				maxClockDrift,
-				customClientTrustingPeriodPercentage,
-				memo,
-			)
-			if err != nil {
-				return fmt.Errorf("error creating clients: %w", err)
+			if opts.DstChannelID, err = cmd.Flags().GetString(flagDstChannel); err != nil {
+				return err
 			}
 
-			if clientSrc != "" || clientDst != "" {
-				if err := a.updatePathConfig(cmd.Context(), pathName, clientSrc, clientDst, "", ""); err != nil {
-					return err
-				}
+			if opts.Override, err = cmd.Flags().GetBool(flagOverride); err != nil {
+				return err
 			}
 
-			// create connection if it isn't already created
-			connectionSrc, connectionDst, err := c[src].CreateOpenConnections(
-				cmd.Context(),
-				c[dst],
-				retries,
-				to,
-				memo,
-				initialBlockHistory,
-				pathName,
-			)
-			if err != nil {
-				return fmt.Errorf("error creating connections: %w", err)
+			if opts.InitialBlockHistory, err = cmd.Flags().GetUint64(flagInitialBlockHistory); err != nil {
+				return err
 			}
 
-			if connectionSrc != "" || connectionDst != "" {
-				if err := a.updatePathConfig(cmd.Context(), pathName, "", "", connectionSrc, connectionDst); err != nil {
-					return err
-				}
+			if opts.RetryPolicy, err = a.config.retryPolicy(cmd, pathName); err != nil {
+				return err
 			}
 
-			// create channel if it isn't already created
-			return c[src].CreateOpenChannels(
-				cmd.Context(),
-				c[dst],
-				retries,
-				to,
-				srcPort,
-				dstPort,
-				order,
-				version,
-				override,
-				memo,
-				pathName,
-			)
+			return a.service(cmd).LinkPath(cmd.Context(), pathName, opts)
 		},
 	}
 	cmd = timeoutFlag(a.viper, cmd)
 	cmd = retryFlag(a.viper, cmd)
 	cmd = clientParameterFlags(a.viper, cmd)
 	cmd = channelParameterFlags(a.viper, cmd)
+	cmd = resumeChannelFlags(a.viper, cmd)
 	cmd = overrideFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
 	cmd = initBlockFlag(a.viper, cmd)
@@ -866,6 +1206,7 @@ $ %s tx link-then-start demo-path --timeout 5s`, appName, appName)),
 	cmd = strategyFlag(a.viper, cmd)
 	cmd = clientParameterFlags(a.viper, cmd)
 	cmd = channelParameterFlags(a.viper, cmd)
+	cmd = resumeChannelFlags(a.viper, cmd)
 	cmd = overrideFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
 	cmd = debugServerFlags(a.viper, cmd)
@@ -889,92 +1230,33 @@ $ %s tx flush demo-path channel-0`,
 			appName, appName, appName,
 		)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			chains := make(map[string]*relayer.Chain)
-			var paths []relayer.NamedPath
+			var (
+				paths []string
+				opts  relayer.FlushOptions
+				err   error
+			)
 
 			if len(args) > 0 {
-				pathName := args[0]
-				path := a.config.Paths.MustGet(pathName)
-				paths = append(paths, relayer.NamedPath{
-					Name: pathName,
-					Path: path,
-				})
-
-				// collect unique chain IDs
-				chains[path.Src.ChainID] = nil
-				chains[path.Dst.ChainID] = nil
-			} else {
-				for n, path := range a.config.Paths {
-					paths = append(paths, relayer.NamedPath{
-						Name: n,
-						Path: path,
-					})
-
-					// collect unique chain IDs
-					chains[path.Src.ChainID] = nil
-					chains[path.Dst.ChainID] = nil
-				}
-			}
-
-			chainIDs := make([]string, 0, len(chains))
-			for chainID := range chains {
-				chainIDs = append(chainIDs, chainID)
+				paths = append(paths, args[0])
 			}
 
-			// get chain configurations
-			chains, err := a.config.Chains.Gets(chainIDs...)
-			if err != nil {
-				return err
-			}
-
-			if err := ensureKeysExist(chains); err != nil {
-				return err
+			if len(args) == 2 {
+				// Only allow specific channel
+				opts.SrcChannelID = args[1]
 			}
 
-			maxMsgLength, err := cmd.Flags().GetUint64(flagMaxMsgLength)
-			if err != nil {
+			if opts.MaxMsgLength, err = cmd.Flags().GetUint64(flagMaxMsgLength); err != nil {
 				return err
 			}
 
-			if len(args) == 2 {
-				// Only allow specific channel
-				paths[0].Path.Filter = relayer.ChannelFilter{
-					Rule:        processor.RuleAllowList,
-					ChannelList: []string{args[1]},
-				}
-			}
-
-			stuckPacket, err := parseStuckPacketFromFlags(cmd)
-			if err != nil {
+			if opts.StuckPacket, err = parseStuckPacketFromFlags(cmd); err != nil {
 				return err
 			}
 
 			ctx, cancel := context.WithTimeout(cmd.Context(), flushTimeout)
 			defer cancel()
 
-			rlyErrCh := relayer.StartRelayer(
-				ctx,
-				a.log,
-				chains,
-				paths,
-				maxMsgLength,
-				a.config.Global.MaxReceiverSize,
-				a.config.Global.ICS20MemoLimit,
-				a.config.memo(cmd),
-				0,
-				0,
-				&processor.FlushLifecycle{},
-				relayer.ProcessorEvents,
-				0,
-				nil,
-				stuckPacket,
-			)
-
-			// Block until the error channel sends a message.
-			// The context being canceled will cause the relayer to stop,
-			// so we don't want to separately monitor the ctx.Done channel,
-			// because we would risk returning before the relayer cleans up.
-			if err := <-rlyErrCh; err != nil && !errors.Is(err, context.Canceled) {
+			if err := a.service(cmd).Flush(ctx, paths, opts); err != nil {
 				a.log.Warn(
 					"Relayer start error",
 					zap.Error(err),
@@ -1036,19 +1318,74 @@ $ %s tx relay-acks demo-path channel-0 -l 3 -s 6`,
 	return cmd
 }
 
+func relayPacketCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relay-packet chain_name channel_id sequence",
+		Short: "relay a single packet sent on a channel of a chain, or its acknowledgement or timeout",
+		Long: strings.TrimSpace(fmt.Sprintf(`Relay the packet with the given sequence sent on the channel of the chain, or its acknowledgement
+or timeout, whichever it is waiting for, over the configured path of the connection of the channel. This relays an
+urgent packet on demand without flushing the whole path. A running '%s start' relays a packet on demand when
+requested through its control API: POST %spackets/relay?chain_id=...&channel_id=...&sequence=...`,
+			appName, relayer.ControlAPIPrefix)),
+		Args: withUsage(cobra.ExactArgs(3)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx relay-packet ibc-0 channel-0 42
+$ %s tx relay-packet ibc-1 channel-3 7 --port icahost`,
+			appName, appName,
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+
+			sequence, err := strconv.ParseUint(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid sequence %s: %w", args[2], err)
+			}
+
+			portID, err := cmd.Flags().GetString(flagPort)
+			if err != nil {
+				return err
+			}
+
+			return a.service(cmd).RelayPacket(cmd.Context(), chain.ChainID(), args[1], portID, sequence)
+		},
+	}
+
+	cmd = portFlag(a.viper, cmd)
+	cmd = memoFlag(a.viper, cmd)
+	return cmd
+}
+
 func xfersend(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "transfer src_chain_name dst_chain_name amount dst_addr src_channel_id",
+		Use:   "transfer src_chain_name dst_chain_name amount dst_addr [src_channel_id]",
 		Short: "initiate a transfer from one network to another",
 		Long: `Initiate a token transfer via IBC between two networks. The created packet
-must be relayed to the destination chain.`,
-		Args: withUsage(cobra.ExactArgs(5)),
+must be relayed to the destination chain.
+
+With --unwind, the amount's IBC denom is returned to its origin chain along the reverse of its
+denom trace and src_channel_id may be omitted. dst_chain_name is the chain the first hop is sent to,
+and dst_addr is the receiver on the origin chain. When the token has travelled more than one hop,
+the remaining hops are made by packet forward middleware, which must be enabled on each intermediate chain.
+
+With --route, the amount is transferred over the hops of a configured route from src_chain_name, its first
+chain, to dst_addr on dst_chain_name, its last chain, and src_channel_id is omitted. The hops after the first
+are made by packet forward middleware, or with --sequential, one at a time through the relayer's account on
+each intermediate chain, once the previous hop is received there.`,
+		Args: withUsage(cobra.RangeArgs(4, 5)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s tx transfer ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk channel-0 --path demo-path
 $ %s tx transfer ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk channel-0 --path demo -y 2 -c 10
 $ %s tx transfer ibc-0 ibc-1 100000stake raw:non-bech32-address channel-0 --path demo
+$ %s tx transfer ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk channel-0 --path demo --packet-memo '{"wasm":{...}}'
 $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk channel-0 --path demo -c 5
-`, appName, appName, appName, appName)),
+$ %s tx transfer ibc-1 ibc-0 100000transfer/channel-1/transfer/channel-0/stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk --unwind
+$ %s tx transfer ibc-1 ibc-0 100000ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2 cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk --unwind
+$ %s tx transfer ibc-0 ibc-2 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk --route 'ibc-0->ibc-1->ibc-2'
+$ %s tx transfer ibc-0 ibc-2 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk --route demo-route --sequential
+`, appName, appName, appName, appName, appName, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			src, ok := a.config.Chains[args[0]]
 			if !ok {
@@ -1060,6 +1397,14 @@ $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9
 				return errChainNotFound(args[1])
 			}
 
+			routeName, err := cmd.Flags().GetString(flagRoute)
+			if err != nil {
+				return err
+			}
+			if routeName != "" {
+				return routeTransfer(cmd, a, routeName, args)
+			}
+
 			pathString, err := cmd.Flags().GetString(flagPath)
 			if err != nil {
 				return err
@@ -1075,13 +1420,49 @@ $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9
 				return err
 			}
 
+			unwind, err := cmd.Flags().GetBool(flagUnwind)
+			if err != nil {
+				return err
+			}
+
+			var srcChannelID string
+			if len(args) == 5 {
+				srcChannelID = args[4]
+			} else if !unwind {
+				return fmt.Errorf("src_channel_id is required unless --%s is set", flagUnwind)
+			}
+
+			var unwindHops []relayer.TransferHop
+			if unwind {
+				var trace transfertypes.DenomTrace
+				if strings.HasPrefix(amount.Denom, "ibc/") {
+					t, err := src.ChainProvider.QueryDenomTrace(cmd.Context(), amount.Denom)
+					if err != nil {
+						return fmt.Errorf("failed to query denom trace for %s: %w", amount.Denom, err)
+					}
+					trace = *t
+				} else {
+					trace = transfertypes.ParseDenomTrace(amount.Denom)
+				}
+
+				if unwindHops, err = relayer.UnwindPath(trace); err != nil {
+					return err
+				}
+
+				if srcChannelID != "" && srcChannelID != unwindHops[0].ChannelID {
+					return fmt.Errorf("denom %s must be unwound through channel{%s}, not channel{%s}",
+						trace.GetFullDenomPath(), unwindHops[0].ChannelID, srcChannelID)
+				}
+				srcChannelID = unwindHops[0].ChannelID
+				amount = sdk.NewCoin(trace.IBCDenom(), amount.Amount)
+			}
+
 			srch, err := src.ChainProvider.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
 
 			// Query all channels for the configured connection on the src chain
-			srcChannelID := args[4]
 
 			var pathConnectionID string
 			switch {
@@ -1142,6 +1523,19 @@ $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9
 				dstAddr = rawDstAddr
 			}
 
+			packetMemo, err := cmd.Flags().GetString(flagPacketMemo)
+			if err != nil {
+				return err
+			}
+			if unwind {
+				if packetMemo != "" {
+					return fmt.Errorf("--%s cannot be used with --%s, which sets the packet memo", flagPacketMemo, flagUnwind)
+				}
+				if dstAddr, packetMemo, err = relayer.ForwardTransfer(unwindHops, dstAddr); err != nil {
+					return err
+				}
+			}
+
 			memo := a.config.memo(cmd)
 
 			return src.SendTransferMsg(
@@ -1150,6 +1544,7 @@ $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9
 				dst,
 				amount,
 				dstAddr,
+				packetMemo,
 				memo,
 				toHeightOffset,
 				toTimeOffset,
@@ -1158,10 +1553,166 @@ $ %s tx raw send ibc-0 ibc-1 100000stake cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9
 		},
 	}
 
+	cmd = generateOnlyFlag(a.viper, cmd)
 	cmd = memoFlag(a.viper, cmd)
+	cmd = packetMemoFlag(a.viper, cmd)
+	cmd = unwindFlag(a.viper, cmd)
+	cmd = routeFlag(a.viper, cmd)
+	cmd = sequentialFlag(a.viper, cmd)
 	return timeoutFlags(a.viper, pathFlag(a.viper, cmd))
 }
 
+// routeTransfer transfers the amount of args over the hops of the route from its first chain to the receiver on
+// its last chain, either with a packet forward middleware memo, or one hop at a time through the relayer's account
+// on each intermediate chain.
+func routeTransfer(cmd *cobra.Command, a *appState, routeName string, args []string) error {
+	route, err := a.config.Routes.Get(routeName)
+	if err != nil {
+		return err
+	}
+	if args[0] != route.Chains[0] || args[1] != route.Chains[len(route.Chains)-1] {
+		return fmt.Errorf("route %s does not go from %s to %s", route, args[0], args[1])
+	}
+	if len(args) == 5 {
+		return fmt.Errorf("src_channel_id cannot be given with --%s, which sends through the channels of the route", flagRoute)
+	}
+
+	unwind, err := cmd.Flags().GetBool(flagUnwind)
+	if err != nil {
+		return err
+	}
+	if unwind {
+		return fmt.Errorf("--%s cannot be used with --%s", flagUnwind, flagRoute)
+	}
+
+	sequential, err := cmd.Flags().GetBool(flagSequential)
+	if err != nil {
+		return err
+	}
+
+	packetMemo, err := cmd.Flags().GetString(flagPacketMemo)
+	if err != nil {
+		return err
+	}
+	if packetMemo != "" && !sequential {
+		return fmt.Errorf("--%s requires --%s with --%s, since packet forward middleware sets the packet memo",
+			flagPacketMemo, flagSequential, flagRoute)
+	}
+
+	amount, err := sdk.ParseCoinNormalized(args[2])
+	if err != nil {
+		return err
+	}
+
+	toHeightOffset, err := cmd.Flags().GetUint64(flagTimeoutHeightOffset)
+	if err != nil {
+		return err
+	}
+
+	toTimeOffset, err := cmd.Flags().GetDuration(flagTimeoutTimeOffset)
+	if err != nil {
+		return err
+	}
+
+	// If the argument begins with "raw:" then use the suffix directly.
+	dstAddr := strings.TrimPrefix(args[3], "raw:")
+	memo := a.config.memo(cmd)
+	ctx := cmd.Context()
+
+	channels, err := route.Channels(ctx, a.config.Chains, a.config.Paths)
+	if err != nil {
+		return err
+	}
+	chains := make([]*relayer.Chain, len(route.Chains))
+	for i, name := range route.Chains {
+		chains[i] = a.config.Chains[name]
+	}
+	setHopPaths := func(i int) error {
+		path := a.config.Paths[route.Hops[i].Path]
+		if err := chains[i].SetPath(path.End(chains[i].ChainID())); err != nil {
+			return err
+		}
+		return chains[i+1].SetPath(path.End(chains[i+1].ChainID()))
+	}
+
+	// denom is the full denom path of the token on the chain sending each hop.
+	denom := amount.Denom
+	if strings.HasPrefix(denom, "ibc/") {
+		trace, err := chains[0].ChainProvider.QueryDenomTrace(ctx, denom)
+		if err != nil {
+			return fmt.Errorf("failed to query denom trace for %s: %w", denom, err)
+		}
+		denom = trace.GetFullDenomPath()
+	}
+	coin := func(fullDenomPath string) sdk.Coin {
+		return sdk.NewCoin(transfertypes.ParseDenomTrace(fullDenomPath).IBCDenom(), amount.Amount)
+	}
+
+	if !sequential {
+		hops := make([]relayer.TransferHop, len(channels))
+		for i, channel := range channels {
+			hops[i] = relayer.TransferHop{PortID: channel.PortId, ChannelID: channel.ChannelId}
+		}
+		receiver, packetMemo, err := relayer.ForwardTransfer(hops, dstAddr)
+		if err != nil {
+			return err
+		}
+		if err := setHopPaths(0); err != nil {
+			return err
+		}
+		return chains[0].SendTransferMsg(ctx, a.log, chains[1], coin(denom), receiver, packetMemo, memo,
+			toHeightOffset, toTimeOffset, channels[0])
+	}
+
+	waitTimeout := routeHopTimeout
+	if toTimeOffset > 0 {
+		waitTimeout = toTimeOffset
+	}
+	for i, channel := range channels {
+		sent := coin(denom)
+		denom = relayer.ReceivedDenom(channel, denom)
+
+		if i == len(channels)-1 {
+			if err := setHopPaths(i); err != nil {
+				return err
+			}
+			return chains[i].SendTransferMsg(ctx, a.log, chains[i+1], sent, dstAddr, packetMemo, memo,
+				toHeightOffset, toTimeOffset, channel)
+		}
+
+		// intermediate hops are received by the relayer's account, which sends the next hop.
+		receiver, err := chains[i+1].ChainProvider.Address()
+		if err != nil {
+			return err
+		}
+		balance, err := chains[i+1].ChainProvider.QueryBalanceWithAddress(ctx, receiver)
+		if err != nil {
+			return err
+		}
+		received := coin(denom)
+		expected := received.AddAmount(balance.AmountOf(received.Denom))
+
+		if err := setHopPaths(i); err != nil {
+			return err
+		}
+		if err := chains[i].SendTransferMsg(ctx, a.log, chains[i+1], sent, receiver, "", memo,
+			toHeightOffset, toTimeOffset, channel); err != nil {
+			return err
+		}
+
+		a.log.Info(
+			"Waiting for hop of route transfer to be received",
+			zap.String("route", route.String()),
+			zap.String("chain_name", route.Chains[i+1]),
+			zap.String("amount", received.String()),
+		)
+		if err := relayer.WaitForBalance(ctx, chains[i+1], receiver, expected, waitTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func setPathsFromArgs(a *appState, src, dst *relayer.Chain, name string) (*relayer.Path, error) {
 	// find any configured paths between the chains
 	paths, err := a.config.Paths.PathsFromChains(src.ChainID(), dst.ChainID())
@@ -1203,17 +1754,6 @@ func setPathsFromArgs(a *appState, src, dst *relayer.Chain, name string) (*relay
 	return path, nil
 }
 
-// ensureKeysExist returns an error if a configured key for a given chain does not exist.
-func ensureKeysExist(chains map[string]*relayer.Chain) error {
-	for _, v := range chains {
-		if exists := v.ChainProvider.KeyExists(v.ChainProvider.Key()); !exists {
-			return fmt.Errorf("key %s not found on chain %s", v.ChainProvider.Key(), v.ChainID())
-		}
-	}
-
-	return nil
-}
-
 // registerCounterpartyCmd registers the counterparty_payee
 func registerCounterpartyCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
@@ -1252,5 +1792,92 @@ $ %s reg-cpt channel-1 cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk juno1g0ny48
 		},
 	}
 
+	cmd = generateOnlyFlag(a.viper, cmd)
 	return memoFlag(a.viper, cmd)
 }
+
+// claimFeesCmd claims the ICS-29 fees paid to the fee payee of a chain
+func claimFeesCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim-fees chain_name",
+		Short: "claim the ics-29 fees paid to the fee-payee-key of a chain to the key of the chain",
+		Long: strings.TrimSpace(`Claim the ICS-29 fees paid to the fee-payee-key of a chain, the key registered as the
+payee of the relayer key with the fee middleware, by sending its balance less the fee of the claim to the key
+of the chain.`),
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx claim-fees cosmoshub`, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			cc, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+			if !ok {
+				return fmt.Errorf("claiming fees is only supported for cosmos chains")
+			}
+
+			claimed, err := cc.ClaimFees(cmd.Context(), a.config.memo(cmd))
+			if err != nil {
+				return err
+			}
+			if claimed.IsZero() {
+				fmt.Fprintf(cmd.OutOrStdout(), "no fees to claim on %s\n", chain.ChainID())
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "claimed %s on %s\n", claimed, chain.ChainID())
+			return nil
+		},
+	}
+
+	cmd = generateOnlyFlag(a.viper, cmd)
+	return memoFlag(a.viper, cmd)
+}
+
+// broadcastSignedCmd broadcasts a tx which was generated with --generate-only and signed offline
+func broadcastSignedCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast-signed chain_name tx_file",
+		Short: "broadcast a tx signed offline and wait for it to be included in a block",
+		Long: strings.TrimSpace(`Broadcast a tx which was generated with --generate-only and signed offline, e.g. with the
+'tx sign' command of the chain's binary on an air-gapped machine, and wait for it to be included in a block.
+The signed tx is read as JSON from tx_file, or from stdin if tx_file is "-".`),
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tx client ibc-0 ibc-1 demo-path --generate-only > unsigned.json
+$ gaiad tx sign unsigned.json --from relayer --chain-id ibc-0 --offline --account-number 7 --sequence 12 > signed.json
+$ %s tx broadcast-signed ibc-0 signed.json`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, ok := a.config.Chains[args[0]]
+			if !ok {
+				return errChainNotFound(args[0])
+			}
+			cc, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+			if !ok {
+				return fmt.Errorf("broadcasting signed txs is only supported for cosmos chains")
+			}
+
+			var (
+				txJSON []byte
+				err    error
+			)
+			if args[1] == "-" {
+				txJSON, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				txJSON, err = os.ReadFile(args[1])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read signed tx: %w", err)
+			}
+
+			res, err := cc.BroadcastSignedTx(cmd.Context(), txJSON)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "tx %s included at height %d on %s\n", res.TxHash, res.Height, chain.ChainID())
+			return nil
+		},
+	}
+
+	return cmd
+}
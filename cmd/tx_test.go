@@ -0,0 +1,26 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/internal/relayertest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestUpdateClientsAll(t *testing.T) {
+	t.Parallel()
+
+	sys := relayertest.NewSystem(t)
+
+	_ = sys.MustRun(t, "config", "init")
+
+	// either a path or --all must be given.
+	res := sys.Run(zaptest.NewLogger(t), "tx", "update-clients")
+	require.ErrorContains(t, res.Err, "specify either a path name or --all")
+	res = sys.Run(zaptest.NewLogger(t), "tx", "update-clients", "demo-path", "--all")
+	require.ErrorContains(t, res.Err, "specify either a path name or --all")
+
+	// without paths, there are no clients to update.
+	_ = sys.MustRun(t, "tx", "update-clients", "--all", "--stale", "12h")
+}
@@ -0,0 +1,36 @@
+package cregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// EndpointResolver resolves the healthy RPC endpoints of chains from a ChainRegistry by their chain name,
+// falling back to the testnet registry when the mainnet entry does not match the chain ID.
+type EndpointResolver struct {
+	registry ChainRegistry
+}
+
+// NewEndpointResolver returns an EndpointResolver which looks up chains in registry.
+func NewEndpointResolver(registry ChainRegistry) *EndpointResolver {
+	return &EndpointResolver{registry: registry}
+}
+
+// ResolveRPCEndpoints returns the healthy RPC endpoints listed in the registry for the chain.
+func (r *EndpointResolver) ResolveRPCEndpoints(ctx context.Context, chainName, chainID string) ([]string, error) {
+	var errs []error
+	for _, testnet := range []bool{false, true} {
+		info, err := r.registry.GetChain(ctx, testnet, chainName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if info.ChainID != chainID {
+			errs = append(errs, fmt.Errorf("registry entry %s is for chain %s", chainName, info.ChainID))
+			continue
+		}
+		return info.GetRPCEndpoints(ctx)
+	}
+	return nil, fmt.Errorf("failed to resolve RPC endpoints of chain %s (%s) from %s: %w", chainName, chainID, r.registry.SourceLink(), errors.Join(errs...))
+}
@@ -0,0 +1,263 @@
+// Package testnet scaffolds local testnets of cosmos chains run with docker compose,
+// along with the relayer configuration to relay between them.
+package testnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+)
+
+const (
+	// DefaultImage is the docker image of the chains of a testnet, unless another is given.
+	DefaultImage = "ghcr.io/strangelove-ventures/heighliner/gaia:v14.1.0"
+
+	// DefaultBinary is the chain binary in DefaultImage.
+	DefaultBinary = "gaiad"
+
+	// KeyName is the name of the relayer key on the chains of a testnet.
+	KeyName = "testkey"
+
+	denom         = "stake"
+	accountPrefix = "cosmos"
+
+	// ports of the first chain on the host, incremented for each further chain.
+	firstRPCPort  = 26657
+	rpcPortStep   = 100
+	firstGRPCPort = 9090
+)
+
+// Config configures the testnet to scaffold.
+type Config struct {
+	// Chains is the number of chains, at least 2.
+	Chains int
+
+	// Image is the docker image of the chains, which must contain Binary and a shell.
+	Image string
+
+	// Binary is the chain binary, which must support the genesis commands of cosmos-sdk v0.47 or later.
+	Binary string
+
+	// Mnemonic of the relayer key, funded on every chain.
+	Mnemonic string
+}
+
+// Chain is a chain of a testnet.
+type Chain struct {
+	ChainID  string
+	RPCPort  int
+	GRPCPort int
+}
+
+// Testnet is a scaffolded testnet.
+type Testnet struct {
+	Dir    string
+	Chains []Chain
+
+	// Paths are the names of the paths between each consecutive pair of chains.
+	Paths []string
+}
+
+// Scaffold writes the docker compose project, chain init script, relayer chain and path configs and setup script
+// of a testnet to dir. The genesis file of each chain is generated in dir by its init script the first time the
+// chain is started, so that it matches the chain binary of the image.
+func Scaffold(dir string, cfg Config) (*Testnet, error) {
+	if cfg.Chains < 2 {
+		return nil, fmt.Errorf("a testnet needs at least 2 chains, got %d", cfg.Chains)
+	}
+	if cfg.Image == "" {
+		cfg.Image = DefaultImage
+	}
+	if cfg.Binary == "" {
+		cfg.Binary = DefaultBinary
+	}
+	if cfg.Mnemonic == "" {
+		return nil, fmt.Errorf("a mnemonic for the relayer key is required")
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("directory %s is not empty", dir)
+	}
+
+	t := &Testnet{Dir: dir}
+	for i := 0; i < cfg.Chains; i++ {
+		t.Chains = append(t.Chains, Chain{
+			ChainID:  fmt.Sprintf("testnet-%d", i+1),
+			RPCPort:  firstRPCPort + i*rpcPortStep,
+			GRPCPort: firstGRPCPort + i,
+		})
+	}
+	for i := 1; i < len(t.Chains); i++ {
+		t.Paths = append(t.Paths, pathName(t.Chains[i-1], t.Chains[i]))
+	}
+
+	for _, d := range []string{"chains", "paths"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	data := struct {
+		Image, Binary, Mnemonic, KeyName, Denom string
+
+		Chains []Chain
+		Paths  []string
+	}{cfg.Image, cfg.Binary, cfg.Mnemonic, KeyName, denom, t.Chains, t.Paths}
+
+	for _, f := range []struct {
+		name string
+		tmpl *template.Template
+		mode os.FileMode
+	}{
+		{"docker-compose.yml", composeTemplate, 0o644},
+		{"init-chain.sh", initChainTemplate, 0o755},
+		{"setup.sh", setupTemplate, 0o755},
+		{".env", envTemplate, 0o600},
+	} {
+		var b strings.Builder
+		if err := f.tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", f.name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f.name), []byte(b.String()), f.mode); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range t.Chains {
+		if err := writeJSON(filepath.Join(dir, "chains", c.ChainID+".json"), chainConfig(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i := 1; i < len(t.Chains); i++ {
+		src, dst := t.Chains[i-1], t.Chains[i]
+		path := &relayer.Path{
+			Src: &relayer.PathEnd{ChainID: src.ChainID},
+			Dst: &relayer.PathEnd{ChainID: dst.ChainID},
+		}
+		if err := writeJSON(filepath.Join(dir, "paths", pathName(src, dst)+".json"), path); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func pathName(src, dst Chain) string {
+	return src.ChainID + "_" + dst.ChainID
+}
+
+// chainConfig returns the relayer config of the chain, in the format of rly chains add-dir.
+func chainConfig(c Chain) any {
+	return struct {
+		Type  string                       `json:"type"`
+		Value *cosmos.CosmosProviderConfig `json:"value"`
+	}{
+		Type: "cosmos",
+		Value: &cosmos.CosmosProviderConfig{
+			Key:            KeyName,
+			ChainID:        c.ChainID,
+			RPCAddr:        fmt.Sprintf("http://localhost:%d", c.RPCPort),
+			AccountPrefix:  accountPrefix,
+			KeyringBackend: "test",
+			GasAdjustment:  1.5,
+			GasPrices:      "0.01" + denom,
+			Timeout:        "10s",
+			OutputFormat:   "json",
+			SignModeStr:    "direct",
+		},
+	}
+}
+
+func writeJSON(file string, v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(out, '\n'), 0o644)
+}
+
+var composeTemplate = template.Must(template.New("compose").Parse(`# Generated by rly dev testnet.
+services:
+{{- range .Chains}}
+  {{.ChainID}}:
+    image: {{$.Image}}
+    user: root
+    entrypoint: ["sh", "/testnet/init-chain.sh"]
+    environment:
+      CHAIN_ID: {{.ChainID}}
+      BINARY: {{$.Binary}}
+      DENOM: {{$.Denom}}
+      RELAYER_MNEMONIC: ${RELAYER_MNEMONIC}
+    volumes:
+      - ./init-chain.sh:/testnet/init-chain.sh:ro
+      - ./{{.ChainID}}:/testnet/home
+    ports:
+      - "{{.RPCPort}}:26657"
+      - "{{.GRPCPort}}:9090"
+{{- end}}
+`))
+
+var initChainTemplate = template.Must(template.New("init-chain").Parse(`#!/bin/sh
+# Generated by rly dev testnet.
+# Generates the genesis of a single validator chain with the relayer key funded, the first time it is started,
+# and starts the chain.
+set -e
+
+HOME_DIR=/testnet/home
+KEYRING="--keyring-backend test --home $HOME_DIR"
+
+if [ ! -f "$HOME_DIR/config/genesis.json" ]; then
+  $BINARY init "$CHAIN_ID" --chain-id "$CHAIN_ID" --default-denom "$DENOM" --home "$HOME_DIR" > /dev/null 2>&1
+  $BINARY keys add validator $KEYRING > /dev/null 2>&1
+  echo "$RELAYER_MNEMONIC" | $BINARY keys add relayer --recover $KEYRING > /dev/null 2>&1
+  for key in validator relayer; do
+    $BINARY genesis add-genesis-account "$($BINARY keys show $key -a $KEYRING)" "100000000000$DENOM" --home "$HOME_DIR"
+  done
+  $BINARY genesis gentx validator "10000000000$DENOM" --chain-id "$CHAIN_ID" $KEYRING > /dev/null 2>&1
+  $BINARY genesis collect-gentxs --home "$HOME_DIR" > /dev/null 2>&1
+  sed -i 's/timeout_commit = "5s"/timeout_commit = "1s"/' "$HOME_DIR/config/config.toml"
+  sed -i 's/timeout_propose = "3s"/timeout_propose = "1s"/' "$HOME_DIR/config/config.toml"
+fi
+
+exec $BINARY start --home "$HOME_DIR" \
+  --rpc.laddr tcp://0.0.0.0:26657 \
+  --grpc.address 0.0.0.0:9090 \
+  --minimum-gas-prices "0$DENOM" \
+  --pruning nothing
+`))
+
+var setupTemplate = template.Must(template.New("setup").Parse(`#!/bin/sh
+# Generated by rly dev testnet.
+# Adds the chains and paths of the testnet to the relayer config, restores the relayer key on every chain
+# and links the paths once the chains produce blocks. Set RLY to run another relayer binary or home, e.g.
+# RLY="rly --home /tmp/rly" ./setup.sh
+set -e
+
+cd "$(dirname "$0")"
+. ./.env
+RLY="${RLY:-rly}"
+
+$RLY config show > /dev/null 2>&1 || $RLY config init
+$RLY chains add-dir chains
+$RLY paths add-dir paths
+{{range .Chains}}
+$RLY keys restore {{.ChainID}} {{$.KeyName}} "$RELAYER_MNEMONIC"
+{{- end}}
+{{range .Chains}}
+echo "Waiting for {{.ChainID}} to produce blocks..."
+until $RLY query node-state {{.ChainID}} > /dev/null 2>&1; do sleep 1; done
+{{- end}}
+sleep 3
+{{range .Paths}}
+$RLY tx link {{.}}
+{{- end}}
+`))
+
+var envTemplate = template.Must(template.New("env").Parse(`RELAYER_MNEMONIC="{{.Mnemonic}}"
+`))
@@ -0,0 +1,66 @@
+package testnet_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/relayer/v2/internal/testnet"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "testnet")
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	tn, err := testnet.Scaffold(dir, testnet.Config{Chains: 3, Mnemonic: mnemonic})
+	require.NoError(t, err)
+	require.Len(t, tn.Chains, 3)
+	require.Equal(t, []string{"testnet-1_testnet-2", "testnet-2_testnet-3"}, tn.Paths)
+
+	// every chain is exposed on its own host ports.
+	compose, err := os.ReadFile(filepath.Join(dir, "docker-compose.yml"))
+	require.NoError(t, err)
+	for _, c := range tn.Chains {
+		require.Contains(t, string(compose), c.ChainID+":")
+	}
+	require.Contains(t, string(compose), `"26657:26657"`)
+	require.Contains(t, string(compose), `"26757:26657"`)
+	require.Contains(t, string(compose), testnet.DefaultImage)
+
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	require.Contains(t, string(env), mnemonic)
+
+	var chain struct {
+		Type  string `json:"type"`
+		Value struct {
+			ChainID string `json:"chain-id"`
+			RPCAddr string `json:"rpc-addr"`
+			Key     string `json:"key"`
+		} `json:"value"`
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "chains", "testnet-2.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &chain))
+	require.Equal(t, "cosmos", chain.Type)
+	require.Equal(t, "testnet-2", chain.Value.ChainID)
+	require.Equal(t, "http://localhost:26757", chain.Value.RPCAddr)
+	require.Equal(t, testnet.KeyName, chain.Value.Key)
+
+	var path relayer.Path
+	b, err = os.ReadFile(filepath.Join(dir, "paths", "testnet-2_testnet-3.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &path))
+	require.Equal(t, "testnet-2", path.Src.ChainID)
+	require.Equal(t, "testnet-3", path.Dst.ChainID)
+
+	// existing testnets are not overwritten.
+	_, err = testnet.Scaffold(dir, testnet.Config{Chains: 2, Mnemonic: mnemonic})
+	require.Error(t, err)
+
+	_, err = testnet.Scaffold(t.TempDir(), testnet.Config{Chains: 1, Mnemonic: mnemonic})
+	require.Error(t, err)
+}
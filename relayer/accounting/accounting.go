@@ -0,0 +1,109 @@
+// Package accounting records the transactions broadcast by the relayer so that
+// operators can report on historical fee spend for invoicing and budgeting.
+package accounting
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Tx is a single transaction broadcast by the relayer.
+type Tx struct {
+	Time      time.Time
+	ChainID   string
+	PathName  string
+	TxHash    string
+	Height    int64
+	Code      uint32
+	GasWanted int64
+	GasUsed   int64
+	Fees      sdk.Coins
+	MsgTypes  []string
+}
+
+// Recorder persists broadcast transactions.
+type Recorder interface {
+	Record(ctx context.Context, tx Tx) error
+}
+
+type pathNameKey struct{}
+
+// WithPathName returns a copy of ctx which attributes the transactions broadcast with it to the path.
+func WithPathName(ctx context.Context, pathName string) context.Context {
+	return context.WithValue(ctx, pathNameKey{}, pathName)
+}
+
+// PathName returns the name of the path which the transactions broadcast with ctx are attributed to,
+// or an empty string if they are not broadcast on behalf of a path, e.g. transfers.
+func PathName(ctx context.Context) string {
+	pathName, _ := ctx.Value(pathNameKey{}).(string)
+	return pathName
+}
+
+// PathSpend summarizes the transactions broadcast to a single chain on behalf of a single path.
+type PathSpend struct {
+	PathName string    `json:"path"`
+	ChainID  string    `json:"chain_id"`
+	Txs      uint64    `json:"txs"`
+	Failed   uint64    `json:"failed"`
+	Msgs     uint64    `json:"msgs"`
+	GasUsed  uint64    `json:"gas_used"`
+	Fees     sdk.Coins `json:"fees"`
+}
+
+// SpendReport summarizes fee spend per path per chain over a period of time.
+type SpendReport struct {
+	Since time.Time   `json:"since"`
+	Until time.Time   `json:"until"`
+	Paths []PathSpend `json:"paths"`
+}
+
+// NewSpendReport aggregates txs into a SpendReport covering since to until.
+// Paths are sorted by path name, then chain ID.
+func NewSpendReport(since, until time.Time, txs []Tx) SpendReport {
+	type key struct{ pathName, chainID string }
+
+	spend := make(map[key]*PathSpend)
+	var keys []key
+
+	for _, tx := range txs {
+		k := key{tx.PathName, tx.ChainID}
+		s, ok := spend[k]
+		if !ok {
+			s = &PathSpend{PathName: tx.PathName, ChainID: tx.ChainID, Fees: sdk.NewCoins()}
+			spend[k] = s
+			keys = append(keys, k)
+		}
+
+		s.Txs++
+		if tx.Code != 0 {
+			s.Failed++
+		}
+		s.Msgs += uint64(len(tx.MsgTypes))
+		if tx.GasUsed > 0 {
+			s.GasUsed += uint64(tx.GasUsed)
+		}
+		s.Fees = s.Fees.Add(tx.Fees...)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pathName != keys[j].pathName {
+			return keys[i].pathName < keys[j].pathName
+		}
+		return keys[i].chainID < keys[j].chainID
+	})
+
+	report := SpendReport{
+		Since: since,
+		Until: until,
+		Paths: make([]PathSpend, len(keys)),
+	}
+	for i, k := range keys {
+		report.Paths[i] = *spend[k]
+	}
+
+	return report
+}
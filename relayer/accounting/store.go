@@ -0,0 +1,146 @@
+package accounting
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer/localdb"
+)
+
+// DefaultDBFile is the name of the accounting database within the relayer home directory.
+const DefaultDBFile = "accounting.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS txs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	time       INTEGER NOT NULL,
+	chain_id   TEXT    NOT NULL,
+	path_name  TEXT    NOT NULL,
+	tx_hash    TEXT    NOT NULL,
+	height     INTEGER NOT NULL,
+	code       INTEGER NOT NULL,
+	gas_wanted INTEGER NOT NULL,
+	gas_used   INTEGER NOT NULL,
+	fees       TEXT    NOT NULL,
+	msg_types  TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS txs_time ON txs (time);
+`
+
+// Store is a Recorder backed by a local SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+var _ Recorder = (*Store)(nil)
+
+// OpenStore opens the SQLite database at path, creating it and its schema if necessary.
+func OpenStore(path string) (*Store, error) {
+	db, err := localdb.Open(path, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open accounting database %s: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record inserts tx into the store.
+func (s *Store) Record(ctx context.Context, tx Tx) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO txs (time, chain_id, path_name, tx_hash, height, code, gas_wanted, gas_used, fees, msg_types)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		tx.Time.UnixNano(),
+		tx.ChainID,
+		tx.PathName,
+		tx.TxHash,
+		tx.Height,
+		tx.Code,
+		tx.GasWanted,
+		tx.GasUsed,
+		tx.Fees.String(),
+		strings.Join(tx.MsgTypes, ","),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record tx %s on chain %s: %w", tx.TxHash, tx.ChainID, err)
+	}
+	return nil
+}
+
+// Txs returns all recorded transactions broadcast at or after since, ordered by time.
+func (s *Store) Txs(ctx context.Context, since time.Time) ([]Tx, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT time, chain_id, path_name, tx_hash, height, code, gas_wanted, gas_used, fees, msg_types
+		FROM txs WHERE time >= ? ORDER BY time`,
+		since.UnixNano(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recorded txs: %w", err)
+	}
+	defer rows.Close()
+
+	var txs []Tx
+	for rows.Next() {
+		var (
+			tx       Tx
+			ts       int64
+			fees     string
+			msgTypes string
+		)
+		if err := rows.Scan(
+			&ts,
+			&tx.ChainID,
+			&tx.PathName,
+			&tx.TxHash,
+			&tx.Height,
+			&tx.Code,
+			&tx.GasWanted,
+			&tx.GasUsed,
+			&fees,
+			&msgTypes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan recorded tx: %w", err)
+		}
+
+		tx.Time = time.Unix(0, ts)
+		if tx.Fees, err = sdk.ParseCoinsNormalized(fees); err != nil {
+			return nil, fmt.Errorf("failed to parse fees for recorded tx %s: %w", tx.TxHash, err)
+		}
+		if msgTypes != "" {
+			tx.MsgTypes = strings.Split(msgTypes, ",")
+		}
+
+		txs = append(txs, tx)
+	}
+
+	return txs, rows.Err()
+}
+
+// SpendReport summarizes the fee spend per path per chain of all transactions broadcast at or after since.
+func (s *Store) SpendReport(ctx context.Context, since time.Time) (SpendReport, error) {
+	until := time.Now()
+
+	txs, err := s.Txs(ctx, since)
+	if err != nil {
+		return SpendReport{}, err
+	}
+
+	return NewSpendReport(since, until, txs), nil
+}
+
+// RenameChain attributes the transactions recorded for the chain with oldChainID to newChainID,
+// e.g. after the chain relaunched with a new chain-id.
+func (s *Store) RenameChain(ctx context.Context, oldChainID, newChainID string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE txs SET chain_id = ? WHERE chain_id = ?`, newChainID, oldChainID); err != nil {
+		return fmt.Errorf("failed to rename recorded txs of chain %s to %s: %w", oldChainID, newChainID, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package accounting_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSpendReport(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := accounting.OpenStore(filepath.Join(t.TempDir(), accounting.DefaultDBFile))
+	require.NoError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	fee := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewCoin("uatom", sdkmath.NewInt(amount)))
+	}
+
+	txs := []accounting.Tx{
+		// outside of the report window
+		{Time: now.Add(-48 * time.Hour), ChainID: "cosmoshub-4", PathName: "hub-osmo", TxHash: "A", GasUsed: 100, Fees: fee(1000), MsgTypes: []string{"/ibc.core.client.v1.MsgUpdateClient"}},
+		{Time: now.Add(-2 * time.Hour), ChainID: "cosmoshub-4", PathName: "hub-osmo", TxHash: "B", GasUsed: 100, Fees: fee(10), MsgTypes: []string{"/ibc.core.client.v1.MsgUpdateClient", "/ibc.core.channel.v1.MsgRecvPacket"}},
+		{Time: now.Add(-time.Hour), ChainID: "cosmoshub-4", PathName: "hub-osmo", TxHash: "C", Code: 5, GasUsed: 50, Fees: fee(5), MsgTypes: []string{"/ibc.core.client.v1.MsgUpdateClient", "/ibc.core.channel.v1.MsgAcknowledgement"}},
+		{Time: now.Add(-time.Hour), ChainID: "osmosis-1", PathName: "hub-osmo", TxHash: "D", GasUsed: 70, Fees: sdk.NewCoins(sdk.NewCoin("uosmo", sdkmath.NewInt(7))), MsgTypes: []string{"/ibc.core.client.v1.MsgUpdateClient"}},
+		{Time: now.Add(-time.Hour), ChainID: "cosmoshub-4", PathName: "hub-juno", TxHash: "E", GasUsed: 20, Fees: fee(2)},
+	}
+	for _, tx := range txs {
+		require.NoError(t, s.Record(ctx, tx))
+	}
+
+	report, err := s.SpendReport(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+
+	require.Equal(t, []accounting.PathSpend{
+		{PathName: "hub-juno", ChainID: "cosmoshub-4", Txs: 1, Msgs: 0, GasUsed: 20, Fees: fee(2)},
+		{PathName: "hub-osmo", ChainID: "cosmoshub-4", Txs: 2, Failed: 1, Msgs: 4, GasUsed: 150, Fees: fee(15)},
+		{PathName: "hub-osmo", ChainID: "osmosis-1", Txs: 1, Msgs: 1, GasUsed: 70, Fees: sdk.NewCoins(sdk.NewCoin("uosmo", sdkmath.NewInt(7)))},
+	}, report.Paths)
+}
@@ -0,0 +1,229 @@
+// Package audit writes an append-only log of every transaction signed and broadcast by the relayer,
+// for operators who must be able to account for everything signed with their keys.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// EventBroadcast is logged when a signed transaction is broadcast, with the result of the broadcast.
+	EventBroadcast = "broadcast"
+
+	// EventResult is logged when the result of the execution of a broadcast transaction is known.
+	EventResult = "result"
+
+	// DefaultMaxSizeMB is the size at which the log is rotated, unless configured otherwise.
+	DefaultMaxSizeMB = 100
+
+	// rotatedTimeFormat is the suffix of rotated logs, which sorts in the order they were rotated.
+	rotatedTimeFormat = "20060102T150405.000000000Z"
+)
+
+// Config configures the audit log.
+type Config struct {
+	// File is the path of the log, relative to the relayer home unless absolute.
+	File string `yaml:"file" json:"file"`
+
+	// MaxSizeMB is the size in megabytes at which the log is rotated. DefaultMaxSizeMB is used if zero.
+	MaxSizeMB int `yaml:"max-size-mb,omitempty" json:"max-size-mb,omitempty"`
+
+	// MaxBackups is how many rotated logs are kept. All are kept if zero.
+	MaxBackups int `yaml:"max-backups,omitempty" json:"max-backups,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.File == "" {
+		return fmt.Errorf("audit log file is required")
+	}
+	if c.MaxSizeMB < 0 {
+		return fmt.Errorf("invalid audit log max-size-mb: %d", c.MaxSizeMB)
+	}
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("invalid audit log max-backups: %d", c.MaxBackups)
+	}
+	return nil
+}
+
+// Message is a message of a transaction.
+type Message struct {
+	TypeURL string `json:"type_url"`
+
+	// SHA256 is the hex encoded hash of the protobuf encoding of the message, as included in the signed transaction.
+	SHA256 string `json:"sha256"`
+}
+
+// Entry is a line of the audit log.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	ChainID    string    `json:"chain_id"`
+	Signer     string    `json:"signer"`
+	FeeGranter string    `json:"fee_granter,omitempty"`
+	Sequence   uint64    `json:"sequence"`
+	TxHash     string    `json:"tx_hash"`
+	Messages   []Message `json:"messages,omitempty"`
+	Memo       string    `json:"memo,omitempty"`
+	Fees       string    `json:"fees,omitempty"`
+	Height     int64     `json:"height,omitempty"`
+	Code       uint32    `json:"code"`
+	Codespace  string    `json:"codespace,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Log is an append-only JSONL audit log, rotated once it reaches its maximum size.
+// It is safe for concurrent use.
+type Log struct {
+	mu sync.Mutex
+
+	file       string
+	maxSize    int64
+	maxBackups int
+
+	f    *os.File
+	size int64
+}
+
+// Open opens the audit log configured by cfg, with its file relative to home unless absolute.
+func Open(home string, cfg Config) (*Log, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	file := cfg.File
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(home, file)
+	}
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB == 0 {
+		maxSizeMB = DefaultMaxSizeMB
+	}
+
+	l := &Log{
+		file:       file,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: cfg.MaxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return nil, err
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// File returns the path of the log.
+func (l *Log) File() string {
+	return l.file
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.f = f
+	l.size = info.Size()
+	return nil
+}
+
+// Record appends the entry to the log and syncs it to disk, rotating the log first if the entry
+// would exceed its maximum size. The time of the entry is set if it is zero.
+func (l *Log) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return fmt.Errorf("audit log %s is closed", l.file)
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.f.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return l.f.Sync()
+}
+
+// rotate renames the log with the time of the rotation as suffix, opens a new log,
+// and removes the oldest rotated logs beyond the maximum number of backups.
+func (l *Log) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+
+	rotated := l.file + "." + time.Now().UTC().Format(rotatedTimeFormat)
+	for i := 1; fileExists(rotated); i++ {
+		// never overwrite a rotated log, e.g. with a coarse clock.
+		rotated = fmt.Sprintf("%s.%s.%d", l.file, time.Now().UTC().Format(rotatedTimeFormat), i)
+	}
+	if err := os.Rename(l.file, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	if l.maxBackups == 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(l.file + ".*")
+	if err != nil {
+		return err
+	}
+	sort.Strings(backups)
+	for _, backup := range backups[:max(len(backups)-l.maxBackups, 0)] {
+		if err := os.Remove(backup); err != nil {
+			return fmt.Errorf("failed to remove rotated audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// Close closes the log.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
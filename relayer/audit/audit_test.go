@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readEntries(t *testing.T, file string) []Entry {
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestLog(t *testing.T) {
+	home := t.TempDir()
+
+	l, err := Open(home, Config{File: "audit/tx.jsonl", MaxBackups: 2})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, "audit", "tx.jsonl"), l.File())
+
+	entry := Entry{
+		Event:    EventBroadcast,
+		ChainID:  "chain-a",
+		Signer:   "cosmos1signer",
+		Sequence: 7,
+		TxHash:   "ABCD",
+		Messages: []Message{{TypeURL: "/ibc.core.channel.v1.MsgRecvPacket", SHA256: "00ff"}},
+	}
+	require.NoError(t, l.Record(entry))
+	require.NoError(t, l.Close())
+
+	// the log is appended to when it is opened again.
+	l, err = Open(home, Config{File: "audit/tx.jsonl", MaxBackups: 2})
+	require.NoError(t, err)
+	entry.Event = EventResult
+	require.NoError(t, l.Record(entry))
+
+	entries := readEntries(t, l.File())
+	require.Len(t, entries, 2)
+	require.Equal(t, EventBroadcast, entries[0].Event)
+	require.Equal(t, EventResult, entries[1].Event)
+	require.Equal(t, entry.Messages, entries[1].Messages)
+	require.False(t, entries[0].Time.IsZero())
+
+	// every entry exceeds the max size, so each is written to a new log and only 2 rotated logs are kept.
+	l.maxSize = 1
+	for i := 0; i < 4; i++ {
+		require.NoError(t, l.Record(entry))
+	}
+	require.Len(t, readEntries(t, l.File()), 1)
+	backups, err := filepath.Glob(l.File() + ".*")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	require.NoError(t, l.Close())
+	require.Error(t, l.Record(entry))
+
+	require.Error(t, (&Config{}).Validate())
+	require.Error(t, (&Config{File: "audit.jsonl", MaxSizeMB: -1}).Validate())
+	require.NoError(t, (*Config)(nil).Validate())
+}
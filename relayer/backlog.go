@@ -0,0 +1,118 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"golang.org/x/sync/errgroup"
+)
+
+// PacketBacklog is the backlog of the packets sent on a channel, detected by diffing the packet commitments on the
+// chain which sent them against the packet receipts and acknowledgements of the counterparty. Only the state of the
+// chains is queried, so the backlog is detected in full for packets of any age, even from nodes which have tx
+// indexing disabled.
+type PacketBacklog struct {
+	ChainID               string `json:"chain_id"`
+	ChannelID             string `json:"channel_id"`
+	PortID                string `json:"port_id"`
+	CounterpartyChainID   string `json:"counterparty_chain_id"`
+	CounterpartyChannelID string `json:"counterparty_channel_id"`
+	CounterpartyPortID    string `json:"counterparty_port_id"`
+
+	// UnrelayedPackets are the sequences of the packets which have not been received by the counterparty.
+	// Unlike UnrelayedSequences, all of them are included for ordered channels.
+	UnrelayedPackets []uint64 `json:"unrelayed_packets"`
+
+	// UnrelayedAcks are the sequences of the packets which have been received and acknowledged by the counterparty,
+	// but whose commitment has not been cleared by relaying their acknowledgement. Packets which have been received,
+	// but are acknowledged asynchronously and have no acknowledgement yet, are not included.
+	UnrelayedAcks []uint64 `json:"unrelayed_acks"`
+}
+
+// QueryPacketBacklog queries the backlog of the packets sent in both directions on the open channels of the
+// connection of the path end of src, which are allowed by filter.
+func QueryPacketBacklog(ctx context.Context, src, dst *Chain, filter ChannelFilter) ([]PacketBacklog, error) {
+	srch, err := src.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := src.ChainProvider.QueryConnectionChannels(ctx, srch, src.ConnectionID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels on chain{%s}@connection{%s}: %w", src.ChainID(), src.ConnectionID(), err)
+	}
+
+	var open []*chantypes.IdentifiedChannel
+	for _, channel := range channels {
+		if channel.State == chantypes.OPEN && filter.allows(channel.ChannelId) {
+			open = append(open, channel)
+		}
+	}
+
+	backlogs := make([]PacketBacklog, 2*len(open))
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(snapshotConcurrency)
+	for i, channel := range open {
+		i, channel := i, channel
+		eg.Go(func() error {
+			packets, err := unrelayedSequences(egCtx, src, dst, channel, false)
+			if err != nil {
+				return fmt.Errorf("failed to query unrelayed packets of channel{%s} port{%s} on chain{%s}: %w",
+					channel.ChannelId, channel.PortId, src.ChainID(), err)
+			}
+			acks, err := unrelayedAcknowledgements(egCtx, src, dst, channel)
+			if err != nil {
+				return fmt.Errorf("failed to query unrelayed acks of channel{%s} port{%s} on chain{%s}: %w",
+					channel.ChannelId, channel.PortId, src.ChainID(), err)
+			}
+
+			// the acks of the packets sent by src are written by dst, and vice versa.
+			backlogs[2*i] = PacketBacklog{
+				ChainID:               src.ChainID(),
+				ChannelID:             channel.ChannelId,
+				PortID:                channel.PortId,
+				CounterpartyChainID:   dst.ChainID(),
+				CounterpartyChannelID: channel.Counterparty.ChannelId,
+				CounterpartyPortID:    channel.Counterparty.PortId,
+				UnrelayedPackets:      sortedSequences(packets.Src),
+				UnrelayedAcks:         sortedSequences(acks.Dst),
+			}
+			backlogs[2*i+1] = PacketBacklog{
+				ChainID:               dst.ChainID(),
+				ChannelID:             channel.Counterparty.ChannelId,
+				PortID:                channel.Counterparty.PortId,
+				CounterpartyChainID:   src.ChainID(),
+				CounterpartyChannelID: channel.ChannelId,
+				CounterpartyPortID:    channel.PortId,
+				UnrelayedPackets:      sortedSequences(packets.Dst),
+				UnrelayedAcks:         sortedSequences(acks.Src),
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return backlogs, nil
+}
+
+// sortedSequences returns seqs in ascending order, and empty rather than nil.
+func sortedSequences(seqs []uint64) []uint64 {
+	sorted := append([]uint64{}, seqs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// allows returns whether the channel with channelID on the src chain of the path is relayed according to the filter.
+func (cf *ChannelFilter) allows(channelID string) bool {
+	switch cf.Rule {
+	case processor.RuleAllowList:
+		return cf.InChannelList(channelID)
+	case processor.RuleDenyList:
+		return !cf.InChannelList(channelID)
+	}
+	return true
+}
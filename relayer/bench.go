@@ -0,0 +1,421 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cometbft/cometbft/light"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/chains/memory"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// Defaults of BenchOptions.
+const (
+	DefaultBenchPackets     = 500
+	DefaultBenchBatchSize   = 10
+	DefaultBenchInterval    = 100 * time.Millisecond
+	DefaultBenchBlockTime   = 200 * time.Millisecond
+	DefaultBenchMaxDuration = 5 * time.Minute
+)
+
+const (
+	benchPathName = "bench"
+
+	// benchPollInterval is how often the packet commitments of the source chain are polled for relayed packets.
+	benchPollInterval = 10 * time.Millisecond
+)
+
+// BenchOptions configures Bench.
+type BenchOptions struct {
+	// Packets is how many ICS-20 transfers are sent from the source chain.
+	Packets int
+
+	// BatchSize is how many transfers are sent per tx, one tx every Interval.
+	BatchSize int
+	Interval  time.Duration
+
+	// BlockTime is the block time of the chains.
+	BlockTime time.Duration
+
+	// MaxDuration bounds how long the packets are relayed for, from the first transfer.
+	MaxDuration time.Duration
+
+	// MaxMsgLength is the max number of messages in a tx of the relayer.
+	MaxMsgLength uint64
+}
+
+func (o BenchOptions) validate() error {
+	switch {
+	case o.Packets <= 0:
+		return fmt.Errorf("invalid number of packets: %d", o.Packets)
+	case o.BatchSize <= 0:
+		return fmt.Errorf("invalid batch size: %d", o.BatchSize)
+	case o.Interval <= 0:
+		return fmt.Errorf("invalid interval: %s", o.Interval)
+	case o.BlockTime <= 0:
+		return fmt.Errorf("invalid block time: %s", o.BlockTime)
+	case o.MaxDuration <= 0:
+		return fmt.Errorf("invalid max duration: %s", o.MaxDuration)
+	}
+	return nil
+}
+
+// BenchResult is the machine-readable result of Bench.
+type BenchResult struct {
+	Packets int `json:"packets"`
+
+	// Relayed counts the packets whose acknowledgement was relayed back to the source chain within the max duration.
+	Relayed int `json:"relayed"`
+
+	// DurationSeconds is the time from the first transfer to the last relayed acknowledgement.
+	DurationSeconds  float64 `json:"duration_seconds"`
+	PacketsPerMinute float64 `json:"packets_per_minute"`
+
+	// AverageLatencySeconds and MaxLatencySeconds are the times from the transfer of the relayed packets
+	// to the relaying of their acknowledgements.
+	AverageLatencySeconds float64 `json:"average_latency_seconds"`
+	MaxLatencySeconds     float64 `json:"max_latency_seconds"`
+
+	// Queries counts the queries of the relayer to each chain while relaying, by chain ID,
+	// including the blocks queried by its chain processors.
+	Queries          map[string]uint64 `json:"queries"`
+	QueriesPerPacket float64           `json:"queries_per_packet"`
+}
+
+// benchChain is a chain of the benchmark, with the Chain of the relayer, whose queries are counted, and the provider
+// of a separate account of the chain, which sends the transfers and polls the packets without being counted.
+type benchChain struct {
+	*Chain
+	relayer *memory.Provider
+	user    *memory.Provider
+}
+
+// newBenchChain returns a new in-memory chain, which produces blocks every blockTime until ctx is done.
+func newBenchChain(ctx context.Context, log *zap.Logger, chainID string, blockTime time.Duration) (*benchChain, error) {
+	prov, err := memory.ProviderConfig{ChainID: chainID, Key: "relayer"}.NewProvider(log, "", false, chainID)
+	if err != nil {
+		return nil, err
+	}
+	relayerProv := prov.(*memory.Provider)
+
+	userProv, err := memory.ProviderConfig{ChainID: chainID, Key: "user", Chain: relayerProv.Chain()}.
+		NewProvider(log, "", false, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	go relayerProv.Chain().Run(ctx, blockTime)
+
+	c := NewChain(log, relayerProv, false)
+	c.Chainid = chainID
+	if err := c.SetPath(&PathEnd{ChainID: chainID}); err != nil {
+		return nil, err
+	}
+	return &benchChain{Chain: c, relayer: relayerProv, user: userProv.(*memory.Provider)}, nil
+}
+
+// Bench measures the relaying pipeline with a synthetic load of ICS-20 transfers between two in-memory chains,
+// linked by a new path. The transfers are sent from the source chain in batches while the relayer runs,
+// and each packet is relayed once its acknowledgement is relayed back to the source chain.
+func Bench(ctx context.Context, log *zap.Logger, opts BenchOptions) (*BenchResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	src, err := newBenchChain(ctx, log, "bench-a", opts.BlockTime)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := newBenchChain(ctx, log, "bench-b", opts.BlockTime)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := benchLink(ctx, src, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	queriesBefore := map[string]uint64{src.ChainID(): src.relayer.Queries(), dst.ChainID(): dst.relayer.Queries()}
+
+	relayCtx, relayCancel := context.WithCancel(ctx)
+	defer relayCancel()
+	control := NewControlAPI()
+	errCh := StartRelayer(
+		relayCtx, log,
+		map[string]*Chain{src.ChainID(): src.Chain, dst.ChainID(): dst.Chain},
+		[]NamedPath{{Name: benchPathName, Path: &Path{Src: src.PathEnd, Dst: dst.PathEnd}}},
+		opts.MaxMsgLength, 0, 0, "", 0, time.Hour, nil, ProcessorEvents, 20, nil, nil, StartOptions{Control: control},
+	)
+
+	if err := benchWaitInSync(ctx, control); err != nil {
+		relayCancel()
+		<-errCh
+		return nil, err
+	}
+
+	log.Info("Sending packets",
+		zap.Int("packets", opts.Packets),
+		zap.Int("batch_size", opts.BatchSize),
+		zap.Duration("interval", opts.Interval),
+	)
+
+	start := time.Now()
+	deadline, deadlineCancel := context.WithDeadline(ctx, start.Add(opts.MaxDuration))
+	defer deadlineCancel()
+
+	sender := newBenchSender(src.user, dst.user, channel)
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- sender.send(deadline, opts.Packets, opts.BatchSize, opts.Interval)
+	}()
+
+	latencies, last, err := sender.waitRelayed(deadline, opts.Packets, sendErr)
+
+	relayCancel()
+	if relayErr := <-errCh; relayErr != nil && !errors.Is(relayErr, context.Canceled) {
+		return nil, relayErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &BenchResult{
+		Packets: opts.Packets,
+		Relayed: len(latencies),
+		Queries: map[string]uint64{
+			src.ChainID(): src.relayer.Queries() - queriesBefore[src.ChainID()],
+			dst.ChainID(): dst.relayer.Queries() - queriesBefore[dst.ChainID()],
+		},
+	}
+	if res.Relayed == 0 {
+		return res, nil
+	}
+
+	duration := last.Sub(start)
+	res.DurationSeconds = duration.Seconds()
+	res.PacketsPerMinute = float64(res.Relayed) / duration.Minutes()
+
+	var total, maxLatency time.Duration
+	for _, l := range latencies {
+		total += l
+		maxLatency = max(maxLatency, l)
+	}
+	res.AverageLatencySeconds = (total / time.Duration(len(latencies))).Seconds()
+	res.MaxLatencySeconds = maxLatency.Seconds()
+
+	var queries uint64
+	for _, q := range res.Queries {
+		queries += q
+	}
+	res.QueriesPerPacket = float64(queries) / float64(res.Relayed)
+
+	return res, nil
+}
+
+// benchLink creates the clients, connection and transfer channel of the path between src and dst,
+// and returns the channel on src.
+func benchLink(ctx context.Context, src, dst *benchChain) (chantypes.IdentifiedChannel, error) {
+	if err := src.ChainProvider.WaitForNBlocks(ctx, 1); err != nil {
+		return chantypes.IdentifiedChannel{}, err
+	}
+	if err := dst.ChainProvider.WaitForNBlocks(ctx, 1); err != nil {
+		return chantypes.IdentifiedChannel{}, err
+	}
+
+	srcClientID, dstClientID, err := src.CreateClients(ctx, dst.Chain, true, true, false, 0, 0, 85, light.DefaultTrustLevel, "")
+	if err != nil {
+		return chantypes.IdentifiedChannel{}, fmt.Errorf("failed to create clients: %w", err)
+	}
+	src.PathEnd.ClientID, dst.PathEnd.ClientID = srcClientID, dstClientID
+
+	srcConnID, dstConnID, err := src.CreateOpenConnections(ctx, dst.Chain, nil, false, "", 0, benchPathName)
+	if err != nil {
+		return chantypes.IdentifiedChannel{}, fmt.Errorf("failed to open connection: %w", err)
+	}
+	src.PathEnd.ConnectionID, dst.PathEnd.ConnectionID = srcConnID, dstConnID
+
+	if err := src.CreateOpenChannels(
+		ctx, dst.Chain, nil, "transfer", "transfer", "unordered", "ics20-1", "", "", false, "", benchPathName,
+	); err != nil {
+		return chantypes.IdentifiedChannel{}, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	channels, err := src.user.QueryConnectionChannels(ctx, 0, srcConnID)
+	if err != nil {
+		return chantypes.IdentifiedChannel{}, err
+	}
+	if len(channels) != 1 {
+		return chantypes.IdentifiedChannel{}, fmt.Errorf("expected one channel on connection %s, found %d",
+			srcConnID, len(channels))
+	}
+	return *channels[0], nil
+}
+
+// benchWaitInSync waits until the relayer is in sync with both chains, so that the packets are relayed as they are
+// observed rather than by the flush on startup.
+func benchWaitInSync(ctx context.Context, control *ControlAPI) error {
+	ticker := time.NewTicker(benchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		pp, ok := control.pathProcessor(benchPathName)
+		if !ok {
+			continue
+		}
+		status, err := pp.Status(ctx)
+		if err != nil {
+			return err
+		}
+		inSync := len(status.Ends) == 2
+		for _, end := range status.Ends {
+			inSync = inSync && end.InSync
+		}
+		if inSync {
+			return nil
+		}
+	}
+}
+
+// benchSender sends the transfers of the benchmark and tracks when their acknowledgements are relayed.
+type benchSender struct {
+	src, dst *memory.Provider
+	channel  chantypes.IdentifiedChannel
+
+	mu     sync.Mutex
+	sentAt map[uint64]time.Time
+}
+
+func newBenchSender(src, dst *memory.Provider, channel chantypes.IdentifiedChannel) *benchSender {
+	return &benchSender{src: src, dst: dst, channel: channel, sentAt: make(map[uint64]time.Time)}
+}
+
+// send sends packets transfers, batchSize per tx, one tx every interval.
+func (s *benchSender) send(ctx context.Context, packets, batchSize int, interval time.Duration) error {
+	receiver, err := s.dst.Address()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sent := 0; sent < packets; {
+		n := min(batchSize, packets-sent)
+		msgs := make([]provider.RelayerMessage, n)
+		for i := range msgs {
+			if msgs[i], err = s.src.MsgTransfer(receiver, sdk.NewInt64Coin("stake", 1), "", provider.PacketInfo{
+				SourcePort:    s.channel.PortId,
+				SourceChannel: s.channel.ChannelId,
+				TimeoutHeight: clienttypes.NewHeight(0, 1_000_000_000),
+			}); err != nil {
+				return err
+			}
+		}
+
+		res, success, err := s.src.SendMessages(ctx, msgs, "")
+		if err != nil {
+			return fmt.Errorf("failed to send transfers: %w", err)
+		}
+		if !success {
+			return fmt.Errorf("transfers failed with code %d", res.Code)
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for _, event := range res.Events {
+			if event.EventType != chantypes.EventTypeSendPacket {
+				continue
+			}
+			seq, err := strconv.ParseUint(event.Attributes[chantypes.AttributeKeySequence], 10, 64)
+			if err != nil {
+				s.mu.Unlock()
+				return fmt.Errorf("invalid sequence of sent packet: %w", err)
+			}
+			s.sentAt[seq] = now
+		}
+		s.mu.Unlock()
+		sent += n
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// waitRelayed waits until the acknowledgements of packets transfers are relayed, or ctx is done, and returns the
+// latencies of the relayed packets along with when the last one was relayed.
+func (s *benchSender) waitRelayed(ctx context.Context, packets int, sendErr <-chan error) ([]time.Duration, time.Time, error) {
+	ticker := time.NewTicker(benchPollInterval)
+	defer ticker.Stop()
+
+	var (
+		latencies []time.Duration
+		last      time.Time
+		relayed   = make(map[uint64]bool)
+	)
+	for len(latencies) < packets {
+		select {
+		case <-ctx.Done():
+			return latencies, last, nil
+		case err := <-sendErr:
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+		case <-ticker.C:
+		}
+
+		// the sent packets are read before the commitments, so that packets sent in between are not mistaken
+		// for relayed ones.
+		s.mu.Lock()
+		sent := make(map[uint64]time.Time, len(s.sentAt))
+		for seq, at := range s.sentAt {
+			if !relayed[seq] {
+				sent[seq] = at
+			}
+		}
+		s.mu.Unlock()
+
+		res, err := s.src.QueryPacketCommitments(ctx, 0, s.channel.ChannelId, s.channel.PortId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return latencies, last, nil
+			}
+			return nil, time.Time{}, err
+		}
+		pending := make(map[uint64]bool, len(res.Commitments))
+		for _, c := range res.Commitments {
+			pending[c.Sequence] = true
+		}
+
+		now := time.Now()
+		for seq, at := range sent {
+			if pending[seq] {
+				continue
+			}
+			relayed[seq] = true
+			latencies = append(latencies, now.Sub(at))
+			last = now
+		}
+	}
+	return latencies, last, nil
+}
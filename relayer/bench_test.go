@@ -0,0 +1,40 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBench(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	res, err := Bench(ctx, zaptest.NewLogger(t), BenchOptions{
+		Packets:      12,
+		BatchSize:    5,
+		Interval:     50 * time.Millisecond,
+		BlockTime:    50 * time.Millisecond,
+		MaxDuration:  30 * time.Second,
+		MaxMsgLength: DefaultMaxMsgLength,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 12, res.Packets)
+	require.Equal(t, 12, res.Relayed)
+	require.Positive(t, res.DurationSeconds)
+	require.Positive(t, res.PacketsPerMinute)
+	require.Positive(t, res.AverageLatencySeconds)
+	require.GreaterOrEqual(t, res.MaxLatencySeconds, res.AverageLatencySeconds)
+	require.Len(t, res.Queries, 2)
+	for chainID, queries := range res.Queries {
+		require.Positive(t, queries, chainID)
+	}
+	require.Positive(t, res.QueriesPerPacket)
+
+	_, err = Bench(ctx, zaptest.NewLogger(t), BenchOptions{Packets: 1})
+	require.ErrorContains(t, err, "invalid batch size")
+}
@@ -36,6 +36,9 @@ type Chain struct {
 	PathEnd *PathEnd `yaml:"-" json:"-"`
 
 	debug bool
+
+	auditProofHeights bool
+	verifyProofs      bool
 }
 
 // Chains is a collection of Chain (mapped by chain_name)
@@ -78,6 +81,39 @@ func NewChain(log *zap.Logger, prov provider.ChainProvider, debug bool) *Chain {
 	}
 }
 
+// SetProofHeightAudit enables logging and validation of proof heights
+// for messages relayed to and from this chain.
+func (c *Chain) SetProofHeightAudit(enabled bool) {
+	c.auditProofHeights = enabled
+}
+
+// proofHeightAudit returns true if proof height auditing is enabled for either chain.
+func proofHeightAudit(chains ...*Chain) bool {
+	for _, c := range chains {
+		if c.auditProofHeights {
+			return true
+		}
+	}
+	return false
+}
+
+// SetProofVerification enables verifying the proofs queried from this chain against its headers
+// before the messages which carry them are sent to the counterparty.
+func (c *Chain) SetProofVerification(enabled bool) {
+	c.verifyProofs = enabled
+}
+
+// proofVerification returns true if proof verification is enabled for all chains, since the proofs of
+// a path can only be verified if those of both of its chains can.
+func proofVerification(chains ...*Chain) bool {
+	for _, c := range chains {
+		if c == nil || !c.verifyProofs {
+			return false
+		}
+	}
+	return len(chains) > 0
+}
+
 func (c *Chain) ChainID() string {
 	return c.ChainProvider.ChainId()
 }
@@ -0,0 +1,89 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// recordTxTimeout bounds writing a tx to the recorder. The write is detached from the context of the broadcast,
+// which may be done by the time the tx is included in a block.
+const recordTxTimeout = 10 * time.Second
+
+// SetTxRecorder sets the recorder to which every transaction broadcast by the provider is recorded
+// for fee accounting, attributed to the path of the context it is broadcast with, if any.
+func (cc *CosmosProvider) SetTxRecorder(r accounting.Recorder) {
+	cc.txRecorder = r
+}
+
+// newAccountingTx returns the accounting record of the signed tx, attributed to the path of ctx. The messages and
+// fees are read from the encoded tx itself, so that the txs broadcast by any command are described alike.
+func (cc *CosmosProvider) newAccountingTx(ctx context.Context, txBytes []byte) accounting.Tx {
+	tx := accounting.Tx{
+		ChainID:  cc.PCfg.ChainID,
+		PathName: accounting.PathName(ctx),
+		TxHash:   fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash()),
+		Fees:     sdk.NewCoins(),
+	}
+
+	var (
+		raw      txtypes.TxRaw
+		body     txtypes.TxBody
+		authInfo txtypes.AuthInfo
+	)
+	if err := raw.Unmarshal(txBytes); err != nil {
+		cc.log.Warn("Failed to decode tx for fee accounting", zap.Error(err))
+		return tx
+	}
+	if err := body.Unmarshal(raw.BodyBytes); err == nil {
+		for _, msg := range body.Messages {
+			tx.MsgTypes = append(tx.MsgTypes, msg.TypeUrl)
+		}
+	}
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err == nil && authInfo.Fee != nil {
+		tx.Fees = authInfo.Fee.Amount
+		tx.GasWanted = int64(authInfo.Fee.GasLimit)
+	}
+	return tx
+}
+
+// recordTx writes the tx to the recorder. The tx is already broadcast,
+// so a failure to record it is logged rather than returned.
+func (cc *CosmosProvider) recordTx(tx accounting.Tx) {
+	ctx, cancel := context.WithTimeout(context.Background(), recordTxTimeout)
+	defer cancel()
+
+	tx.Time = time.Now()
+	if err := cc.txRecorder.Record(ctx, tx); err != nil {
+		cc.log.Error("Failed to record tx for fee accounting",
+			zap.String("path_name", tx.PathName),
+			zap.String("chain_id", tx.ChainID),
+			zap.String("tx_hash", tx.TxHash),
+			zap.Error(err),
+		)
+	}
+}
+
+// recordTxCallback returns a callback which records the tx once its inclusion in a block is observed.
+func (cc *CosmosProvider) recordTxCallback(tx accounting.Tx) func(*provider.RelayerTxResponse, error) {
+	return func(res *provider.RelayerTxResponse, _ error) {
+		// the response is nil if inclusion of the tx could not be observed, so there is nothing to record.
+		if res == nil {
+			return
+		}
+		tx.Height = res.Height
+		tx.Code = res.Code
+		if res.GasWanted > 0 {
+			tx.GasWanted = res.GasWanted
+		}
+		tx.GasUsed = res.GasUsed
+		cc.recordTx(tx)
+	}
+}
@@ -0,0 +1,73 @@
+package cosmos
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type recordedTxs struct {
+	txs []accounting.Tx
+}
+
+func (r *recordedTxs) Record(ctx context.Context, tx accounting.Tx) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.txs = append(r.txs, tx)
+	return nil
+}
+
+func TestRecordTxCallback(t *testing.T) {
+	cc := &CosmosProvider{
+		log:  zaptest.NewLogger(t),
+		PCfg: CosmosProviderConfig{ChainID: "chain-a", AccountPrefix: "cosmos"},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+	recorded := &recordedTxs{}
+	cc.SetTxRecorder(recorded)
+
+	fees := sdk.NewCoins(sdk.NewInt64Coin("uatom", 2500))
+	txb := cc.Cdc.TxConfig.NewTxBuilder()
+	require.NoError(t, txb.SetMsgs(&banktypes.MsgSend{
+		FromAddress: "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+		ToAddress:   "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)),
+	}))
+	txb.SetGasLimit(100000)
+	txb.SetFeeAmount(fees)
+	txBytes, err := cc.Cdc.TxConfig.TxEncoder()(txb.GetTx())
+	require.NoError(t, err)
+
+	// the tx is recorded even though the context it was broadcast with is done by the time it is included.
+	ctx, cancel := context.WithCancel(accounting.WithPathName(context.Background(), "demo-path"))
+	callback := cc.recordTxCallback(cc.newAccountingTx(ctx, txBytes))
+	cancel()
+
+	// a tx whose inclusion was not observed is not recorded.
+	callback(nil, context.Canceled)
+	require.Empty(t, recorded.txs)
+
+	callback(&provider.RelayerTxResponse{Height: 120, Code: 5, GasUsed: 80000}, nil)
+	require.Len(t, recorded.txs, 1)
+	tx := recorded.txs[0]
+	require.Equal(t, "chain-a", tx.ChainID)
+	require.Equal(t, "demo-path", tx.PathName)
+	require.NotEmpty(t, tx.TxHash)
+	require.Equal(t, int64(120), tx.Height)
+	require.Equal(t, uint32(5), tx.Code)
+	require.Equal(t, int64(100000), tx.GasWanted)
+	require.Equal(t, int64(80000), tx.GasUsed)
+	require.Equal(t, fees, tx.Fees)
+	require.Equal(t, []string{"/cosmos.bank.v1beta1.MsgSend"}, tx.MsgTypes)
+	require.False(t, tx.Time.IsZero())
+
+	// txs broadcast outside of a path are not attributed to one.
+	require.Empty(t, cc.newAccountingTx(context.Background(), txBytes).PathName)
+}
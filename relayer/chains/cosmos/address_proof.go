@@ -0,0 +1,100 @@
+package cosmos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+)
+
+// AddressProof proves the ownership of the address of a relayer key by a signature over a challenge, e.g. one
+// issued by a counterparty team or an incentive program. The signature is an ADR-036 off-chain signature,
+// so it can also be verified by the wallets and libraries which support them, e.g. Keplr and CosmJS.
+type AddressProof struct {
+	Address   string          `json:"address"`
+	PubKey    json.RawMessage `json:"pub_key"`
+	Challenge string          `json:"challenge"`
+	Signature []byte          `json:"signature"`
+}
+
+// adr36SignBytes returns the bytes which are signed by an ADR-036 off-chain signature of data by signer,
+// the canonical amino JSON of a sign doc with a single MsgSignData and empty chain ID, fee and sequences.
+func adr36SignBytes(signer string, data []byte) ([]byte, error) {
+	// encoding/json sorts map keys, as required for the canonical amino JSON.
+	return json.Marshal(map[string]any{
+		"account_number": "0",
+		"chain_id":       "",
+		"fee": map[string]any{
+			"amount": []any{},
+			"gas":    "0",
+		},
+		"memo": "",
+		"msgs": []any{map[string]any{
+			"type": "sign/MsgSignData",
+			"value": map[string]any{
+				"data":   base64.StdEncoding.EncodeToString(data),
+				"signer": signer,
+			},
+		}},
+		"sequence": "0",
+	})
+}
+
+// ProveAddress signs the challenge with the key and returns the proof of the ownership of its address.
+func (cc *CosmosProvider) ProveAddress(keyName, challenge string) (*AddressProof, error) {
+	address, err := cc.ShowAddress(keyName)
+	if err != nil {
+		return nil, err
+	}
+
+	signBytes, err := adr36SignBytes(address, []byte(challenge))
+	if err != nil {
+		return nil, err
+	}
+
+	signature, pubKey, err := cc.Keybase.Sign(keyName, signBytes, signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign challenge with key %s: %w", keyName, err)
+	}
+
+	pubKeyJSON, err := cc.Cdc.Marshaler.MarshalInterfaceJSON(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddressProof{
+		Address:   address,
+		PubKey:    pubKeyJSON,
+		Challenge: challenge,
+		Signature: signature,
+	}, nil
+}
+
+// VerifyAddressProof returns an error unless the public key of the proof derives its address on this chain
+// and the signature of the proof is a valid signature of its challenge by the public key.
+func (cc *CosmosProvider) VerifyAddressProof(proof AddressProof) error {
+	var pubKey cryptotypes.PubKey
+	if err := cc.Cdc.Marshaler.UnmarshalInterfaceJSON(proof.PubKey, &pubKey); err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	address, err := cc.EncodeBech32AccAddr(sdk.AccAddress(pubKey.Address()))
+	if err != nil {
+		return err
+	}
+	if address != proof.Address {
+		return fmt.Errorf("public key is for address %s, not %s", address, proof.Address)
+	}
+
+	signBytes, err := adr36SignBytes(proof.Address, []byte(proof.Challenge))
+	if err != nil {
+		return err
+	}
+	if !pubKey.VerifySignature(signBytes, proof.Signature) {
+		return fmt.Errorf("invalid signature of challenge by %s", proof.Address)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cosmos_test
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressProof(t *testing.T) {
+	const (
+		keyName    = "test_key"
+		mnemonic   = "blind master acoustic speak victory lend kiss grab glad help demand hood roast zone lend sponsor level cheap truck kingdom apology token hover reunion"
+		challenge  = "relayer-registration-2f9c1a"
+		coinType   = uint32(118)
+		signingAlg = "secp256k1"
+	)
+
+	p := testProviderWithKeystore(t, "cosmos", nil).(*cosmos.CosmosProvider)
+
+	address, err := p.RestoreKey(keyName, mnemonic, coinType, signingAlg)
+	require.NoError(t, err)
+
+	proof, err := p.ProveAddress(keyName, challenge)
+	require.NoError(t, err)
+	require.Equal(t, address, proof.Address)
+	require.Equal(t, challenge, proof.Challenge)
+	require.NoError(t, p.VerifyAddressProof(*proof))
+
+	tampered := *proof
+	tampered.Challenge = "another-challenge"
+	require.Error(t, p.VerifyAddressProof(tampered))
+
+	tampered = *proof
+	tampered.Address = "cosmos1dea7vlekr9e34vugwkvesulglt8fx4e4s3dl7s"
+	require.Error(t, p.VerifyAddressProof(tampered))
+}
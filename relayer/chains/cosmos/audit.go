@@ -0,0 +1,94 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/relayer/v2/relayer/audit"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// SetAuditLog sets the log to which every transaction signed and broadcast by the provider is recorded.
+func (cc *CosmosProvider) SetAuditLog(l *audit.Log) {
+	cc.auditLog = l
+}
+
+// newAuditEntry returns the audit log entry of the signed tx, signed by the key. The messages, memo, fees and
+// sequence are read from the encoded tx itself, so that the entry describes exactly what was signed.
+func (cc *CosmosProvider) newAuditEntry(event string, txBytes []byte, signingKey string) audit.Entry {
+	entry := audit.Entry{
+		Event:   event,
+		ChainID: cc.PCfg.ChainID,
+		TxHash:  fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash()),
+	}
+
+	if addr, err := cc.GetKeyAddressForKey(signingKey); err == nil {
+		entry.Signer, _ = cc.EncodeBech32AccAddr(addr)
+	}
+
+	var (
+		raw      txtypes.TxRaw
+		body     txtypes.TxBody
+		authInfo txtypes.AuthInfo
+	)
+	if err := raw.Unmarshal(txBytes); err != nil {
+		cc.log.Warn("Failed to decode signed tx for the audit log", zap.Error(err))
+		return entry
+	}
+	if err := body.Unmarshal(raw.BodyBytes); err == nil {
+		for _, msg := range body.Messages {
+			hash := sha256.Sum256(msg.Value)
+			entry.Messages = append(entry.Messages, audit.Message{
+				TypeURL: msg.TypeUrl,
+				SHA256:  hex.EncodeToString(hash[:]),
+			})
+		}
+		entry.Memo = body.Memo
+	}
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err == nil {
+		if authInfo.Fee != nil {
+			entry.Fees = authInfo.Fee.Amount.String()
+			entry.FeeGranter = authInfo.Fee.Granter
+		}
+		if len(authInfo.SignerInfos) > 0 {
+			entry.Sequence = authInfo.SignerInfos[0].Sequence
+		}
+	}
+
+	return entry
+}
+
+// recordAudit appends the entry to the audit log, if one is set. The tx is already signed and broadcast,
+// so a failure to record it is logged rather than returned.
+func (cc *CosmosProvider) recordAudit(entry audit.Entry) {
+	if cc.auditLog == nil {
+		return
+	}
+	if err := cc.auditLog.Record(entry); err != nil {
+		cc.log.Error("Failed to record tx in the audit log",
+			zap.String("chain_id", entry.ChainID),
+			zap.String("tx_hash", entry.TxHash),
+			zap.Error(err),
+		)
+	}
+}
+
+// auditResultCallback returns a callback which records the result of the execution of the tx of the entry.
+func (cc *CosmosProvider) auditResultCallback(entry audit.Entry) func(*provider.RelayerTxResponse, error) {
+	return func(res *provider.RelayerTxResponse, err error) {
+		entry.Event = audit.EventResult
+		if res != nil {
+			entry.Height = res.Height
+			entry.Code = res.Code
+			entry.Codespace = res.Codespace
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		cc.recordAudit(entry)
+	}
+}
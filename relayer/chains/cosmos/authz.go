@@ -0,0 +1,134 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// AuthzRelayMsgTypeURLs are the types of the messages which relay packets,
+// which the authz-granter must grant to the key of the chain.
+// Only these messages are sent on behalf of the authz-granter, all others are signed by the key itself.
+var AuthzRelayMsgTypeURLs = []string{
+	sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}),
+	sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}),
+	sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}),
+	sdk.MsgTypeURL(&chantypes.MsgTimeout{}),
+	sdk.MsgTypeURL(&chantypes.MsgTimeoutOnClose{}),
+}
+
+// authzRelayMsg returns true if msg is one of AuthzRelayMsgTypeURLs.
+func authzRelayMsg(msg sdk.Msg) bool {
+	typeURL := sdk.MsgTypeURL(msg)
+	for _, relayTypeURL := range AuthzRelayMsgTypeURLs {
+		if typeURL == relayTypeURL {
+			return true
+		}
+	}
+	return false
+}
+
+// setAuthzSigner makes the granter the signer of those msgs which relay packets,
+// which the key then executes on its behalf with MsgExec.
+func (cc *CosmosProvider) setAuthzSigner(msgs []provider.RelayerMessage) {
+	for _, curr := range msgs {
+		if cMsg, ok := curr.(CosmosMessage); ok && cMsg.SetSigner != nil && authzRelayMsg(cMsg.Msg) {
+			cMsg.SetSigner(cc.PCfg.AuthzGranter)
+		}
+	}
+}
+
+// authzExec wraps those msgs which relay packets in a MsgExec, executed by the grantee key on behalf of the
+// authz-granter. Each run of consecutive wrapped msgs becomes one MsgExec, so that the order of msgs is kept.
+func (cc *CosmosProvider) authzExec(granteeKey string, msgs []sdk.Msg) ([]sdk.Msg, error) {
+	grantee, err := cc.GetKeyAddressForKey(granteeKey)
+	if err != nil {
+		return nil, err
+	}
+	granteeAddr, err := cc.EncodeBech32AccAddr(grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	return authzExecMsgs(granteeAddr, msgs)
+}
+
+// authzExecMsgs wraps each run of consecutive msgs which relay packets in a MsgExec of grantee.
+func authzExecMsgs(grantee string, msgs []sdk.Msg) ([]sdk.Msg, error) {
+	var (
+		out  []sdk.Msg
+		exec *authz.MsgExec
+	)
+	for _, msg := range msgs {
+		if !authzRelayMsg(msg) {
+			out = append(out, msg)
+			exec = nil
+			continue
+		}
+		if exec == nil {
+			exec = &authz.MsgExec{Grantee: grantee}
+			out = append(out, exec)
+		}
+		msgAny, err := types.NewAnyWithValue(msg)
+		if err != nil {
+			return nil, err
+		}
+		exec.Msgs = append(exec.Msgs, msgAny)
+	}
+	return out, nil
+}
+
+// ValidateAuthzGrants returns an error unless the configured authz-granter has granted all of msgTypeURLs
+// to the key of the chain, with authorizations which have not expired.
+func (cc *CosmosProvider) ValidateAuthzGrants(ctx context.Context, msgTypeURLs []string) error {
+	if cc.PCfg.AuthzGranter == "" {
+		return errors.New("no authz-granter configured for chainclient")
+	}
+
+	grantee, err := cc.Address()
+	if err != nil {
+		return err
+	}
+
+	res, err := authz.NewQueryClient(cc).Grants(ctx, &authz.QueryGrantsRequest{
+		Granter: cc.PCfg.AuthzGranter,
+		Grantee: grantee,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query authz grants of authz-granter %s: %w", cc.PCfg.AuthzGranter, err)
+	}
+
+	granted := make(map[string]bool)
+	now := time.Now()
+	for _, grant := range res.Grants {
+		if grant.Expiration != nil && now.After(*grant.Expiration) {
+			continue
+		}
+		var authorization authz.Authorization
+		if err := cc.Cdc.InterfaceRegistry.UnpackAny(grant.Authorization, &authorization); err != nil {
+			return err
+		}
+		granted[authorization.MsgTypeURL()] = true
+	}
+
+	var missing []string
+	for _, typeURL := range msgTypeURLs {
+		if !granted[typeURL] {
+			missing = append(missing, typeURL)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("authz-granter %s has not granted %s to %s on chain %s",
+			cc.PCfg.AuthzGranter, strings.Join(missing, ", "), grantee, cc.ChainId())
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cosmos
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthzGranter(t *testing.T) {
+	const granter = "cosmos15cw268ckjj2hgq8q3jf68slwjjcjlvxy57je2u"
+
+	cfg := CosmosProviderConfig{Timeout: "10s", AccountPrefix: "cosmos", AuthzGranter: granter}
+	require.NoError(t, cfg.Validate())
+
+	wrongPrefix := cfg
+	wrongPrefix.AccountPrefix = "osmo"
+	require.Error(t, wrongPrefix.Validate())
+
+	withFeeGrants := cfg
+	withFeeGrants.FeeGrants = &FeeGrantConfiguration{}
+	require.Error(t, withFeeGrants.Validate())
+
+	// messages are signed on behalf of the granter.
+	cc := &CosmosProvider{PCfg: cfg}
+	msg := &chantypes.MsgRecvPacket{Signer: "cosmos1grantee"}
+	cc.setAuthzSigner([]provider.RelayerMessage{NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	})})
+	require.Equal(t, granter, msg.Signer)
+
+	// messages which do not relay packets are signed by the key itself.
+	openInit := &chantypes.MsgChannelOpenInit{Signer: "cosmos1grantee"}
+	cc.setAuthzSigner([]provider.RelayerMessage{NewCosmosMessage(openInit, func(signer string) {
+		openInit.Signer = signer
+	})})
+	require.Equal(t, "cosmos1grantee", openInit.Signer)
+}
+
+func TestAuthzExecMsgs(t *testing.T) {
+	const grantee = "cosmos1grantee"
+
+	update := &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0"}
+	recv := &chantypes.MsgRecvPacket{}
+	ack := &chantypes.MsgAcknowledgement{}
+	openInit := &chantypes.MsgChannelOpenInit{PortId: "transfer"}
+	closeInit := &chantypes.MsgChannelCloseInit{PortId: "transfer"}
+	createClient := &clienttypes.MsgCreateClient{}
+
+	msgs, err := authzExecMsgs(grantee, []sdk.Msg{createClient, update, recv, openInit, ack, closeInit})
+	require.NoError(t, err)
+	require.Len(t, msgs, 5)
+
+	// messages which are not granted are signed by the key itself, in their original order.
+	require.Equal(t, createClient, msgs[0])
+	require.Equal(t, openInit, msgs[2])
+	require.Equal(t, closeInit, msgs[4])
+
+	exec, ok := msgs[1].(*authz.MsgExec)
+	require.True(t, ok)
+	require.Equal(t, grantee, exec.Grantee)
+	require.Len(t, exec.Msgs, 2)
+	require.Equal(t, sdk.MsgTypeURL(update), exec.Msgs[0].TypeUrl)
+	require.Equal(t, sdk.MsgTypeURL(recv), exec.Msgs[1].TypeUrl)
+
+	exec, ok = msgs[3].(*authz.MsgExec)
+	require.True(t, ok)
+	require.Len(t, exec.Msgs, 1)
+	require.Equal(t, sdk.MsgTypeURL(ack), exec.Msgs[0].TypeUrl)
+}
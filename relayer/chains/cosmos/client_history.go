@@ -0,0 +1,236 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+)
+
+// clientHistoryEventTypes are the types of the events of txs which created, updated, upgraded or froze a client.
+var clientHistoryEventTypes = []string{
+	clienttypes.EventTypeCreateClient,
+	clienttypes.EventTypeUpdateClient,
+	clienttypes.EventTypeUpgradeClient,
+	clienttypes.EventTypeSubmitMisbehaviour,
+}
+
+// clientHistoryTxsPerPage is the number of txs requested per page when searching the events of a client.
+const clientHistoryTxsPerPage = 100
+
+// ClientHistory is the history of a light client, assembled from its stored consensus states and the events of
+// the txs which created, updated, upgraded or froze it, for forensics after an incident.
+type ClientHistory struct {
+	ChainID      string `json:"chain_id"`
+	ClientID     string `json:"client_id"`
+	ClientType   string `json:"client_type"`
+	Status       string `json:"status"`
+	LatestHeight string `json:"latest_height"`
+
+	// FrozenHeight is set if the client was frozen for misbehaviour.
+	FrozenHeight string `json:"frozen_height,omitempty"`
+
+	// ConsensusStates are the consensus states stored by the client, which may have been pruned
+	// once they expired, by height.
+	ConsensusStates []ClientConsensusState `json:"consensus_states"`
+
+	// Events are the events of the client emitted by txs, in the order of the txs.
+	Events []ClientEvent `json:"events"`
+}
+
+// ClientConsensusState is a consensus state stored by a client.
+type ClientConsensusState struct {
+	Height    string    `json:"height"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Root and NextValidatorsHash are set for tendermint clients.
+	Root               string `json:"root,omitempty"`
+	NextValidatorsHash string `json:"next_validators_hash,omitempty"`
+}
+
+// ClientEvent is an event of a client emitted by a tx.
+type ClientEvent struct {
+	Type   string `json:"type"`
+	Height int64  `json:"height"`
+	TxHash string `json:"tx_hash"`
+
+	// ConsensusHeights are the heights of the consensus states added by the event, if any.
+	ConsensusHeights []string `json:"consensus_heights,omitempty"`
+
+	// Submitter is the sender of the message which emitted the event.
+	Submitter string `json:"submitter,omitempty"`
+}
+
+// QueryClientHistory returns the history of the client with clientID. The events are searched in the txs indexed
+// by the node, so events of txs which were pruned from the node, or emitted outside of txs, e.g. by governance
+// recovering the client, are missing.
+func (cc *CosmosProvider) QueryClientHistory(ctx context.Context, clientID string) (*ClientHistory, error) {
+	qc := clienttypes.NewQueryClient(cc)
+
+	clientRes, err := qc.ClientState(ctx, &clienttypes.QueryClientStateRequest{ClientId: clientID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client %s: %w", clientID, err)
+	}
+	clientState, err := clienttypes.UnpackClientState(clientRes.ClientState)
+	if err != nil {
+		return nil, err
+	}
+
+	statusRes, err := qc.ClientStatus(ctx, &clienttypes.QueryClientStatusRequest{ClientId: clientID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status of client %s: %w", clientID, err)
+	}
+
+	h := &ClientHistory{
+		ChainID:      cc.PCfg.ChainID,
+		ClientID:     clientID,
+		ClientType:   clientState.ClientType(),
+		Status:       statusRes.Status,
+		LatestHeight: clientState.GetLatestHeight().String(),
+	}
+	if cs, ok := clientState.(*tmclient.ClientState); ok && !cs.FrozenHeight.IsZero() {
+		h.FrozenHeight = cs.FrozenHeight.String()
+	}
+
+	if h.ConsensusStates, err = cc.queryClientConsensusStates(ctx, qc, clientID); err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range clientHistoryEventTypes {
+		query := fmt.Sprintf("%s.%s='%s'", eventType, clienttypes.AttributeKeyClientID, clientID)
+		for page := 1; ; page++ {
+			perPage := clientHistoryTxsPerPage
+			res, err := cc.node().TxSearch(ctx, query, false, &page, &perPage, "asc")
+			if err != nil {
+				return nil, fmt.Errorf("failed to search %s events of client %s: %w", eventType, clientID, err)
+			}
+			for _, tx := range res.Txs {
+				h.Events = append(h.Events, clientEvents(tx, eventType, clientID)...)
+			}
+			if len(res.Txs) < perPage || page*perPage >= res.TotalCount {
+				break
+			}
+			time.Sleep(PaginationDelay)
+		}
+	}
+	sort.SliceStable(h.Events, func(i, j int) bool {
+		return h.Events[i].Height < h.Events[j].Height
+	})
+
+	return h, nil
+}
+
+// queryClientConsensusStates returns the consensus states stored by the client with clientID, by height.
+func (cc *CosmosProvider) queryClientConsensusStates(
+	ctx context.Context,
+	qc clienttypes.QueryClient,
+	clientID string,
+) ([]ClientConsensusState, error) {
+	var (
+		states  []clienttypes.ConsensusStateWithHeight
+		pageReq = DefaultPageRequest()
+	)
+	for {
+		res, err := qc.ConsensusStates(ctx, &clienttypes.QueryConsensusStatesRequest{
+			ClientId:   clientID,
+			Pagination: pageReq,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query consensus states of client %s: %w", clientID, err)
+		}
+		states = append(states, res.ConsensusStates...)
+		next := res.GetPagination().GetNextKey()
+		if len(next) == 0 {
+			break
+		}
+		time.Sleep(PaginationDelay)
+		pageReq.Key = next
+	}
+
+	// the consensus states are stored by their height as a string, so they are not returned in the order of
+	// their heights.
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Height.LT(states[j].Height)
+	})
+
+	consensusStates := make([]ClientConsensusState, 0, len(states))
+	for _, s := range states {
+		consState, err := clienttypes.UnpackConsensusState(s.ConsensusState)
+		if err != nil {
+			return nil, err
+		}
+		c := ClientConsensusState{
+			Height:    s.Height.String(),
+			Timestamp: time.Unix(0, int64(consState.GetTimestamp())).UTC(),
+		}
+		if tmConsState, ok := consState.(*tmclient.ConsensusState); ok {
+			c.Root = fmt.Sprintf("%X", tmConsState.Root.GetHash())
+			c.NextValidatorsHash = tmConsState.NextValidatorsHash.String()
+		}
+		consensusStates = append(consensusStates, c)
+	}
+	return consensusStates, nil
+}
+
+// clientEvents returns the events of eventType of the client with clientID emitted by tx. The submitter of each
+// event is the sender of the message which emitted it, matched by the msg_index of the events.
+func clientEvents(tx *coretypes.ResultTx, eventType, clientID string) []ClientEvent {
+	senders := make(map[string]string)
+	var anySender string
+	for _, event := range tx.TxResult.Events {
+		if event.Type != "message" {
+			continue
+		}
+		sender := eventAttribute(event, "sender")
+		if sender == "" {
+			continue
+		}
+		if anySender == "" {
+			anySender = sender
+		}
+		if msgIndex := eventAttribute(event, "msg_index"); msgIndex != "" {
+			senders[msgIndex] = sender
+		}
+	}
+
+	var events []ClientEvent
+	for _, event := range tx.TxResult.Events {
+		if event.Type != eventType || eventAttribute(event, clienttypes.AttributeKeyClientID) != clientID {
+			continue
+		}
+		e := ClientEvent{
+			Type:   eventType,
+			Height: tx.Height,
+			TxHash: tx.Hash.String(),
+		}
+		heights := eventAttribute(event, clienttypes.AttributeKeyConsensusHeights)
+		if heights == "" {
+			heights = eventAttribute(event, clienttypes.AttributeKeyConsensusHeight)
+		}
+		if heights != "" {
+			e.ConsensusHeights = strings.Split(heights, ",")
+		}
+		var ok bool
+		if e.Submitter, ok = senders[eventAttribute(event, "msg_index")]; !ok {
+			e.Submitter = anySender
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// eventAttribute returns the value of the attribute of event with key, if any.
+func eventAttribute(event abci.Event, key string) string {
+	for _, attr := range event.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package cosmos
+
+import (
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientEvents(t *testing.T) {
+	event := func(typ string, attrs ...string) abci.Event {
+		e := abci.Event{Type: typ}
+		for i := 0; i < len(attrs); i += 2 {
+			e.Attributes = append(e.Attributes, abci.EventAttribute{Key: attrs[i], Value: attrs[i+1]})
+		}
+		return e
+	}
+
+	tx := &coretypes.ResultTx{
+		Hash:   []byte{0xab, 0xcd},
+		Height: 120,
+		TxResult: abci.ExecTxResult{Events: []abci.Event{
+			event("message", "action", "/ibc.core.client.v1.MsgUpdateClient", "sender", "cosmos1relayera", "msg_index", "0"),
+			event(clienttypes.EventTypeUpdateClient, "client_id", "07-tendermint-0", "consensus_heights", "1-100", "msg_index", "0"),
+			event("message", "action", "/ibc.core.client.v1.MsgUpdateClient", "sender", "cosmos1relayerb", "msg_index", "1"),
+			event(clienttypes.EventTypeUpdateClient, "client_id", "07-tendermint-1", "consensus_heights", "1-101", "msg_index", "1"),
+			event(clienttypes.EventTypeUpdateClient, "client_id", "07-tendermint-0", "consensus_height", "1-102", "msg_index", "1"),
+			event(clienttypes.EventTypeSubmitMisbehaviour, "client_id", "07-tendermint-0", "msg_index", "1"),
+		}},
+	}
+
+	events := clientEvents(tx, clienttypes.EventTypeUpdateClient, "07-tendermint-0")
+	require.Equal(t, []ClientEvent{
+		{
+			Type:             clienttypes.EventTypeUpdateClient,
+			Height:           120,
+			TxHash:           "ABCD",
+			ConsensusHeights: []string{"1-100"},
+			Submitter:        "cosmos1relayera",
+		},
+		{
+			Type:             clienttypes.EventTypeUpdateClient,
+			Height:           120,
+			TxHash:           "ABCD",
+			ConsensusHeights: []string{"1-102"},
+			Submitter:        "cosmos1relayerb",
+		},
+	}, events)
+
+	misbehaviour := clientEvents(tx, clienttypes.EventTypeSubmitMisbehaviour, "07-tendermint-0")
+	require.Len(t, misbehaviour, 1)
+	require.Empty(t, misbehaviour[0].ConsensusHeights)
+	require.Equal(t, "cosmos1relayerb", misbehaviour[0].Submitter)
+
+	// without msg_index, as emitted by older chains, the sender of the tx is the submitter.
+	tx.TxResult.Events = []abci.Event{
+		event("message", "action", "/ibc.core.client.v1.MsgCreateClient", "sender", "cosmos1relayera"),
+		event(clienttypes.EventTypeCreateClient, "client_id", "07-tendermint-0", "consensus_height", "1-10"),
+	}
+	created := clientEvents(tx, clienttypes.EventTypeCreateClient, "07-tendermint-0")
+	require.Len(t, created, 1)
+	require.Equal(t, "cosmos1relayera", created[0].Submitter)
+	require.Equal(t, []string{"1-10"}, created[0].ConsensusHeights)
+}
@@ -1,6 +1,8 @@
 package cosmos
 
 import (
+	"slices"
+
 	feegrant "cosmossdk.io/x/feegrant/module"
 	"cosmossdk.io/x/tx/signing"
 	"cosmossdk.io/x/upgrade"
@@ -10,8 +12,10 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/std"
 	"github.com/cosmos/cosmos-sdk/types/module"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/auth/tx"
+	txconfig "github.com/cosmos/cosmos-sdk/x/auth/tx/config"
 	authz "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/crisis"
@@ -33,6 +37,7 @@ import (
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos/stride"
 	ethermintcodecs "github.com/cosmos/relayer/v2/relayer/codecs/ethermint"
 	injectivecodecs "github.com/cosmos/relayer/v2/relayer/codecs/injective"
+	"google.golang.org/grpc"
 )
 
 var ModuleBasics = []module.AppModuleBasic{
@@ -115,3 +120,20 @@ func MakeCodecConfig(accBech32Prefix, valBech32Prefix string) Codec {
 		Amino:             codec.NewLegacyAmino(),
 	}
 }
+
+// newTextualTxConfig returns a TxConfig with SIGN_MODE_TEXTUAL enabled in addition to the default sign modes.
+// Textual sign bytes render coins with their denom metadata, which is queried from the chain through conn.
+func newTextualTxConfig(
+	marshaler codec.Codec,
+	accBech32Prefix, valBech32Prefix string,
+	conn grpc.ClientConnInterface,
+) (client.TxConfig, error) {
+	return tx.NewTxConfigWithOptions(marshaler, tx.ConfigOptions{
+		EnabledSignModes: append(slices.Clone(tx.DefaultSignModes), signingtypes.SignMode_SIGN_MODE_TEXTUAL),
+		SigningOptions: &signing.Options{
+			AddressCodec:          address.NewBech32Codec(accBech32Prefix),
+			ValidatorAddressCodec: address.NewBech32Codec(valBech32Prefix),
+		},
+		TextualCoinMetadataQueryFn: txconfig.NewGRPCCoinMetadataQueryFn(conn),
+	})
+}
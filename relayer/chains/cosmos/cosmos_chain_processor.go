@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
+	upgradetypes "cosmossdk.io/x/upgrade/types"
 	"github.com/avast/retry-go/v4"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
@@ -15,6 +18,7 @@ import (
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	"github.com/cosmos/relayer/v2/relayer/chains"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
@@ -49,6 +53,17 @@ type CosmosChainProcessor struct {
 	// map of channel ID to connection ID
 	channelConnections map[string]string
 
+	// if set, the height of the first block to query, rather than initialBlockHistory blocks before the latest height.
+	startHeight uint64
+
+	// the height of the block until which blocks are queried every fastQueryLoopDuration, and until when, see QueryUntil.
+	queryUntilMu       sync.Mutex
+	queryUntil         uint64
+	queryUntilDeadline time.Time
+
+	// wakes the query loop to query new blocks immediately.
+	wake chan struct{}
+
 	// metrics to monitor lifetime of processor
 	metrics *processor.PrometheusMetrics
 
@@ -70,6 +85,7 @@ func NewCosmosChainProcessor(
 		connectionClients:    make(map[string]string),
 		channelConnections:   make(map[string]string),
 		metrics:              metrics,
+		wake:                 make(chan struct{}, 1),
 	}
 }
 
@@ -81,9 +97,31 @@ const (
 	latestHeightQueryRetries    = 5
 
 	defaultMinQueryLoopDuration      = 1 * time.Second
+	fastQueryLoopDuration            = 100 * time.Millisecond
+	fastQueryTimeout                 = 30 * time.Second
+	maxAdaptiveQueryLoopDuration     = 5 * time.Second
+	adaptiveQueryLoopBlocks          = 0.25
 	defaultBalanceUpdateWaitDuration = 60 * time.Second
 	inSyncNumBlocksThreshold         = 2
 	blockMaxRetries                  = 5
+
+	// defaultChainHaltThreshold is how old the latest block of the chain must be for it to be considered halted,
+	// unless configured with halt-threshold.
+	defaultChainHaltThreshold = 2 * time.Minute
+
+	// defaultUpgradeQuiesceBlocks is how many blocks before the height of a scheduled upgrade relaying to the chain
+	// is paused, unless configured with upgrade-quiesce-blocks.
+	defaultUpgradeQuiesceBlocks = 10
+
+	// upgradePlanQueryInterval is how often the upgrade module is queried for a scheduled upgrade.
+	upgradePlanQueryInterval = 5 * time.Minute
+)
+
+const (
+	// feeGrantAlertWindow is how long before the fee grant allowance is estimated to be exhausted, or expires,
+	// that alerts to renew it are logged, at most every feeGrantAlertInterval.
+	feeGrantAlertWindow   = 24 * time.Hour
+	feeGrantAlertInterval = time.Hour
 )
 
 // latestClientState is a map of clientID to the latest clientInfo for that client.
@@ -92,12 +130,14 @@ type latestClientState map[string]provider.ClientState
 func (l latestClientState) update(ctx context.Context, clientInfo chains.ClientInfo, ccp *CosmosChainProcessor) {
 	existingClientInfo, ok := l[clientInfo.ClientID]
 	var trustingPeriod time.Duration
+	var trustLevel cmtmath.Fraction
 	if ok {
 		if clientInfo.ConsensusHeight.LT(existingClientInfo.ConsensusHeight) {
 			// height is less than latest, so no-op
 			return
 		}
 		trustingPeriod = existingClientInfo.TrustingPeriod
+		trustLevel = existingClientInfo.TrustLevel
 	}
 	if trustingPeriod == 0 {
 		cs, err := ccp.chainProvider.queryTMClientState(ctx, 0, clientInfo.ClientID)
@@ -110,8 +150,10 @@ func (l latestClientState) update(ctx context.Context, clientInfo chains.ClientI
 			return
 		}
 		trustingPeriod = cs.TrustingPeriod
+		trustLevel = cs.TrustLevel.ToTendermint()
 	}
 	clientState := clientInfo.ClientState(trustingPeriod)
+	clientState.TrustLevel = trustLevel
 
 	// update latest if no existing state or provided consensus height is newer
 	l[clientInfo.ClientID] = clientState
@@ -122,6 +164,52 @@ func (ccp *CosmosChainProcessor) Provider() provider.ChainProvider {
 	return ccp.chainProvider
 }
 
+// SetStartHeight sets the height of the first block to query when the ChainProcessor is run,
+// overriding the initial block history.
+func (ccp *CosmosChainProcessor) SetStartHeight(height uint64) {
+	ccp.startHeight = height
+}
+
+// QueryUntil makes the ChainProcessor query new blocks immediately, then every fastQueryLoopDuration rather than
+// every min-loop-duration until it has queried the block at height, for at most fastQueryTimeout.
+func (ccp *CosmosChainProcessor) QueryUntil(height uint64) {
+	ccp.queryUntilMu.Lock()
+	ccp.queryUntil = max(ccp.queryUntil, height)
+	ccp.queryUntilDeadline = time.Now().Add(fastQueryTimeout)
+	ccp.queryUntilMu.Unlock()
+
+	select {
+	case ccp.wake <- struct{}{}:
+	default:
+		// the query loop is already woken.
+	}
+}
+
+// queryLoopDuration returns how long the query loop waits between query cycles.
+func (ccp *CosmosChainProcessor) queryLoopDuration(persistence *queryCyclePersistence) time.Duration {
+	ccp.queryUntilMu.Lock()
+	defer ccp.queryUntilMu.Unlock()
+	if uint64(persistence.latestQueriedBlock) < ccp.queryUntil && time.Now().Before(ccp.queryUntilDeadline) {
+		return fastQueryLoopDuration
+	}
+	return pollDuration(persistence)
+}
+
+// pollDuration returns how long the query loop waits between query cycles while not querying blocks fast.
+// Unless min-loop-duration is configured, the chain is queried every quarter of its measured block interval,
+// so that slow chains are queried less often and new blocks of fast chains are queried without delay.
+func pollDuration(persistence *queryCyclePersistence) time.Duration {
+	if !persistence.adaptiveQueryLoop {
+		return persistence.minQueryLoopDuration
+	}
+	return persistence.blockInterval.Blocks(
+		adaptiveQueryLoopBlocks,
+		fastQueryLoopDuration,
+		maxAdaptiveQueryLoopDuration,
+		persistence.minQueryLoopDuration,
+	)
+}
+
 // Set the PathProcessors that this ChainProcessor should publish relevant IBC events to.
 // ChainProcessors need reference to their PathProcessors and vice-versa, handled by EventProcessorBuilder.Build().
 func (ccp *CosmosChainProcessor) SetPathProcessors(pathProcessors processor.PathProcessors) {
@@ -192,6 +280,7 @@ func (ccp *CosmosChainProcessor) clientState(ctx context.Context, clientID strin
 			ClientID:        clientID,
 			ConsensusHeight: cs.GetLatestHeight().(clienttypes.Height),
 			TrustingPeriod:  cs.TrustingPeriod,
+			TrustLevel:      cs.TrustLevel.ToTendermint(),
 		}
 	}
 
@@ -207,6 +296,26 @@ type queryCyclePersistence struct {
 	minQueryLoopDuration        time.Duration
 	lastBalanceUpdate           time.Time
 	balanceUpdateWaitDuration   time.Duration
+
+	// if true, the query loop duration is derived from blockInterval rather than minQueryLoopDuration.
+	adaptiveQueryLoop bool
+	blockInterval     processor.BlockInterval
+
+	// the chain is halted while its latest block is older than haltThreshold.
+	haltThreshold time.Duration
+	halted        bool
+
+	// upgradePlan is the upgrade scheduled on the chain, queried every upgradePlanQueryInterval. upgrade is the
+	// upgrade last published to the PathProcessors, quiesced upgradeQuiesceBlocks before the upgrade height.
+	upgradePlan          *upgradetypes.Plan
+	lastUpgradePlanQuery time.Time
+	upgrade              *processor.ChainUpgrade
+	upgradeQuiesceBlocks int64
+
+	// fees spent when the fee grant allowance was first checked, to estimate when the allowance runs out.
+	feeGrantFirstCheck time.Time
+	feeGrantFirstFees  sdk.Coins
+	lastFeeGrantAlert  time.Time
 }
 
 // Run starts the query loop for the chain which will gather applicable ibc messages and push events out to the relevant PathProcessors.
@@ -218,11 +327,24 @@ func (ccp *CosmosChainProcessor) Run(ctx context.Context, initialBlockHistory ui
 		minQueryLoopDuration = defaultMinQueryLoopDuration
 	}
 
+	haltThreshold := ccp.chainProvider.PCfg.HaltThreshold
+	if haltThreshold == 0 {
+		haltThreshold = defaultChainHaltThreshold
+	}
+
+	upgradeQuiesceBlocks := ccp.chainProvider.PCfg.UpgradeQuiesceBlocks
+	if upgradeQuiesceBlocks == 0 {
+		upgradeQuiesceBlocks = defaultUpgradeQuiesceBlocks
+	}
+
 	// this will be used for persistence across query cycle loop executions
 	persistence := queryCyclePersistence{
 		minQueryLoopDuration:      minQueryLoopDuration,
 		lastBalanceUpdate:         time.Unix(0, 0),
 		balanceUpdateWaitDuration: defaultBalanceUpdateWaitDuration,
+		adaptiveQueryLoop:         ccp.chainProvider.PCfg.MinLoopDuration == 0,
+		haltThreshold:             haltThreshold,
+		upgradeQuiesceBlocks:      upgradeQuiesceBlocks,
 	}
 
 	// Infinite retry to get initial latest height
@@ -250,6 +372,18 @@ func (ccp *CosmosChainProcessor) Run(ctx context.Context, initialBlockHistory ui
 		latestQueriedBlock = 0
 	}
 
+	if ccp.startHeight != 0 {
+		if int64(ccp.startHeight) > persistence.latestHeight {
+			ccp.log.Warn(
+				"Start height is above the latest height of the chain, waiting for the chain to reach it",
+				zap.Uint64("start_height", ccp.startHeight),
+				zap.Int64("latest_height", persistence.latestHeight),
+			)
+		}
+		ccp.log.Info("Replaying events from start height", zap.Uint64("start_height", ccp.startHeight))
+		latestQueriedBlock = int64(ccp.startHeight) - 1
+	}
+
 	if stuckPacket != nil && ccp.chainProvider.ChainId() == stuckPacket.ChainID {
 		latestQueriedBlock = int64(stuckPacket.StartHeight)
 	}
@@ -269,19 +403,23 @@ func (ccp *CosmosChainProcessor) Run(ctx context.Context, initialBlockHistory ui
 
 	ccp.log.Debug("Entering main query loop")
 
-	ticker := time.NewTicker(persistence.minQueryLoopDuration)
+	ticker := time.NewTicker(pollDuration(&persistence))
 	defer ticker.Stop()
 
 	for {
 		if err := ccp.queryCycle(ctx, &persistence, stuckPacket); err != nil {
 			return err
 		}
+		if d := ccp.queryLoopDuration(&persistence); d < pollDuration(&persistence) {
+			ticker.Reset(d)
+		}
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			ticker.Reset(persistence.minQueryLoopDuration)
+		case <-ccp.wake:
 		}
+		ticker.Reset(pollDuration(&persistence))
 	}
 }
 
@@ -352,13 +490,17 @@ func (ccp *CosmosChainProcessor) queryCycle(ctx context.Context, persistence *qu
 	}
 
 	persistence.latestHeight = status.SyncInfo.LatestBlockHeight
+	persistence.blockInterval.Observe(uint64(status.SyncInfo.LatestBlockHeight), status.SyncInfo.LatestBlockTime)
+
+	ccp.checkChainHalt(persistence, status.SyncInfo.LatestBlockTime, time.Now())
+	ccp.checkUpgradePlan(ctx, persistence, time.Now())
 
 	// This debug log is very noisy, but is helpful when debugging new chains.
 	// ccp.log.Debug("Queried latest height",
 	// 	zap.Int64("latest_height", persistence.latestHeight),
 	// )
 
-	if ccp.metrics != nil {
+	if ccp.metrics != nil || ccp.chainProvider.notifier != nil {
 		ccp.CollectMetrics(ctx, persistence)
 	}
 
@@ -403,7 +545,7 @@ func (ccp *CosmosChainProcessor) queryCycle(ctx context.Context, persistence *qu
 			queryCtx, cancelQueryCtx := context.WithTimeout(ctx, blockResultsQueryTimeout)
 			defer cancelQueryCtx()
 
-			blockRes, err = ccp.chainProvider.RPCClient.BlockResults(queryCtx, &sI)
+			blockRes, err = ccp.chainProvider.node().BlockResults(queryCtx, &sI)
 			if err != nil && ccp.metrics != nil {
 				ccp.metrics.IncBlockQueryFailure(chainID, "RPC Client")
 			}
@@ -468,6 +610,8 @@ func (ccp *CosmosChainProcessor) queryCycle(ctx context.Context, persistence *qu
 			ccp.handleMessage(ctx, m, ibcMessagesCache)
 		}
 
+		blockMessages := messages
+
 		for _, tx := range blockRes.TxsResults {
 			if tx.Code != 0 {
 				// tx was not successful
@@ -479,8 +623,12 @@ func (ccp *CosmosChainProcessor) queryCycle(ctx context.Context, persistence *qu
 			for _, m := range messages {
 				ccp.handleMessage(ctx, m, ibcMessagesCache)
 			}
+
+			blockMessages = append(blockMessages, messages...)
 		}
 
+		ccp.indexPackets(ctx, blockMessages)
+
 		newLatestQueriedBlock = i
 
 		if stuckPacket != nil &&
@@ -538,6 +686,115 @@ func (ccp *CosmosChainProcessor) queryCycle(ctx context.Context, persistence *qu
 	return nil
 }
 
+// checkChainHalt considers the chain halted once its latest block is older than the halt threshold, e.g. during
+// an upgrade, and pauses sending messages to it on all paths until it produces blocks again.
+func (ccp *CosmosChainProcessor) checkChainHalt(persistence *queryCyclePersistence, latestBlockTime, now time.Time) {
+	if latestBlockTime.IsZero() {
+		return
+	}
+	halted := now.Sub(latestBlockTime) > persistence.haltThreshold
+	if halted == persistence.halted {
+		return
+	}
+	persistence.halted = halted
+
+	chainID := ccp.chainProvider.ChainId()
+	if halted {
+		ccp.log.Warn("Chain halted, pausing relaying to it until it produces blocks again",
+			zap.Int64("latest_height", persistence.latestHeight),
+			zap.Time("latest_block_time", latestBlockTime),
+		)
+	} else {
+		ccp.log.Info("Chain is producing blocks again, resuming relaying to it",
+			zap.Int64("latest_height", persistence.latestHeight),
+		)
+	}
+
+	if ccp.metrics != nil {
+		ccp.metrics.SetChainHalted(chainID, halted)
+	}
+	for _, pp := range ccp.pathProcessors {
+		pp.SetChainHalted(chainID, halted)
+	}
+}
+
+// checkUpgradePlan queries the upgrade module for a scheduled upgrade of the chain when it is due,
+// and updates the upgrade published to the PathProcessors.
+func (ccp *CosmosChainProcessor) checkUpgradePlan(ctx context.Context, persistence *queryCyclePersistence, now time.Time) {
+	if now.Sub(persistence.lastUpgradePlanQuery) >= upgradePlanQueryInterval {
+		persistence.lastUpgradePlanQuery = now
+		plan, err := ccp.chainProvider.QueryUpgradePlan(ctx)
+		if err != nil {
+			// the halted node of a chain which reached the upgrade height may not serve queries,
+			// so the last queried plan is kept.
+			ccp.log.Debug("Failed to query upgrade plan", zap.Error(err))
+		} else {
+			persistence.upgradePlan = plan
+		}
+	}
+	ccp.updateChainUpgrade(persistence, now)
+}
+
+// updateChainUpgrade publishes the upgrade scheduled on the chain to the PathProcessors, which pause relaying to
+// the chain once it is quiesced, shortly before the upgrade height at which the chain halts. The upgrade is done
+// once the chain, restarted with the upgraded binary, commits the block at the upgrade height, and relaying
+// to it resumes.
+func (ccp *CosmosChainProcessor) updateChainUpgrade(persistence *queryCyclePersistence, now time.Time) {
+	plan := persistence.upgradePlan
+	if plan != nil && persistence.latestHeight >= plan.Height {
+		persistence.upgradePlan, plan = nil, nil
+	}
+
+	var upgrade *processor.ChainUpgrade
+	if plan != nil {
+		upgrade = &processor.ChainUpgrade{
+			Name:     plan.Name,
+			Height:   plan.Height,
+			Quiesced: persistence.latestHeight >= plan.Height-persistence.upgradeQuiesceBlocks,
+		}
+		if blockInterval := persistence.blockInterval.Duration(); blockInterval > 0 {
+			upgrade.Time = now.Add(time.Duration(plan.Height-persistence.latestHeight) * blockInterval)
+		}
+	}
+
+	prev := persistence.upgrade
+	persistence.upgrade = upgrade
+
+	switch {
+	case upgrade != nil && (prev == nil || prev.Name != upgrade.Name || prev.Height != upgrade.Height):
+		ccp.log.Info("Chain upgrade scheduled",
+			zap.String("name", upgrade.Name),
+			zap.Int64("upgrade_height", upgrade.Height),
+			zap.Int64("latest_height", persistence.latestHeight),
+		)
+	case upgrade == nil && prev != nil && persistence.latestHeight >= prev.Height:
+		ccp.log.Info("Chain upgraded and producing blocks, resuming relaying to it",
+			zap.String("name", prev.Name),
+			zap.Int64("latest_height", persistence.latestHeight),
+		)
+	case upgrade == nil && prev != nil:
+		ccp.log.Info("Chain upgrade cancelled",
+			zap.String("name", prev.Name),
+			zap.Int64("upgrade_height", prev.Height),
+		)
+	}
+	if upgrade != nil && upgrade.Quiesced && (prev == nil || !prev.Quiesced) {
+		ccp.log.Warn("Chain upgrade height approaching, pausing relaying to it until the upgraded chain produces blocks",
+			zap.String("name", upgrade.Name),
+			zap.Int64("upgrade_height", upgrade.Height),
+			zap.Int64("latest_height", persistence.latestHeight),
+		)
+	}
+
+	if upgrade == nil && prev == nil {
+		return
+	}
+	chainID := ccp.chainProvider.ChainId()
+	for _, pp := range ccp.pathProcessors {
+		pp.SetChainUpgrade(chainID, upgrade)
+	}
+}
+
 func (ccp *CosmosChainProcessor) CollectMetrics(ctx context.Context, persistence *queryCyclePersistence) {
 	ccp.CurrentBlockHeight(ctx, persistence)
 
@@ -549,15 +806,27 @@ func (ccp *CosmosChainProcessor) CollectMetrics(ctx context.Context, persistence
 				zap.Error(err))
 			return
 		}
+
+		if err := ccp.CurrentFeeGrantAllowance(ctx, persistence); err != nil {
+			ccp.log.Error("Failed to check fee grant allowance", zap.Error(err))
+		}
+
 		persistence.lastBalanceUpdate = time.Now()
 	}
 }
 
 func (ccp *CosmosChainProcessor) CurrentBlockHeight(ctx context.Context, persistence *queryCyclePersistence) {
-	ccp.metrics.SetLatestHeight(ccp.chainProvider.ChainId(), persistence.latestHeight)
+	if ccp.metrics != nil {
+		ccp.metrics.SetLatestHeight(ccp.chainProvider.ChainId(), persistence.latestHeight)
+	}
 }
 
 func (ccp *CosmosChainProcessor) CurrentRelayerBalance(ctx context.Context) error {
+	// there is no wallet to report on when monitoring without keys
+	if !ccp.chainProvider.KeyExists(ccp.chainProvider.Key()) {
+		return nil
+	}
+
 	// memoize the current gas prices to only show metrics for "interesting" denoms
 	gasPrice := ccp.chainProvider.PCfg.GasPrices
 
@@ -571,6 +840,14 @@ func (ccp *CosmosChainProcessor) CurrentRelayerBalance(ctx context.Context) erro
 		if err != nil {
 			return fmt.Errorf("failed to parse gas prices: %w", err)
 		}
+		// the balances of the fallback fee denoms are reported too, only their denoms are used.
+		for _, fallback := range ccp.chainProvider.PCfg.FallbackGasPrices {
+			fgp, err := sdk.ParseDecCoins(fallback)
+			if err != nil {
+				return fmt.Errorf("failed to parse fallback gas prices: %w", err)
+			}
+			gp = gp.Add(fgp...)
+		}
 		ccp.parsedGasPrices = &gp
 	}
 
@@ -590,7 +867,99 @@ func (ccp *CosmosChainProcessor) CurrentRelayerBalance(ctx context.Context) erro
 
 		// Convert to a big float to get a float64 for metrics
 		f, _ := big.NewFloat(0.0).SetInt(bal.BigInt()).Float64()
-		ccp.metrics.SetWalletBalance(ccp.chainProvider.ChainId(), gasPrice, ccp.chainProvider.Key(), address, gasDenom.Denom, f)
+		if ccp.metrics != nil {
+			ccp.metrics.SetWalletBalance(ccp.chainProvider.ChainId(), gasPrice, ccp.chainProvider.Key(), address, gasDenom.Denom, f)
+		}
+	}
+
+	ccp.chainProvider.notifier.WalletBalance(ccp.chainProvider.ChainId(), ccp.chainProvider.Key(), address, relayerWalletBalances)
+	return nil
+}
+
+// CurrentFeeGrantAllowance reports the remaining allowance of the fee-granter of the chain, if configured,
+// and alerts when the allowance is nearly exhausted or about to expire so that it can be renewed.
+func (ccp *CosmosChainProcessor) CurrentFeeGrantAllowance(ctx context.Context, persistence *queryCyclePersistence) error {
+	cc := ccp.chainProvider
+	if cc.PCfg.FeeGranter == "" || !cc.KeyExists(cc.Key()) {
+		return nil
+	}
+
+	spendLimit, expiration, err := cc.QueryFeeGranterAllowance(ctx)
+	if err != nil {
+		return err
+	}
+	address, err := cc.Address()
+	if err != nil {
+		return fmt.Errorf("failed to get relayer bech32 address: %w", err)
+	}
+
+	for _, coin := range spendLimit {
+		f, _ := big.NewFloat(0.0).SetInt(coin.Amount.BigInt()).Float64()
+		if ccp.metrics != nil {
+			ccp.metrics.SetFeeGrantAllowance(cc.ChainId(), cc.PCfg.FeeGranter, address, coin.Denom, f)
+		}
+	}
+
+	cc.totalFeesMu.Lock()
+	totalFees := cc.TotalFees
+	cc.totalFeesMu.Unlock()
+
+	now := time.Now()
+	if persistence.feeGrantFirstCheck.IsZero() {
+		persistence.feeGrantFirstCheck = now
+		persistence.feeGrantFirstFees = totalFees
+	}
+
+	if now.Sub(persistence.lastFeeGrantAlert) < feeGrantAlertInterval {
+		return nil
+	}
+
+	if expiration != nil && expiration.Sub(now) < feeGrantAlertWindow {
+		ccp.log.Warn("Fee grant allowance is about to expire, renew the grant",
+			zap.String("fee_granter", cc.PCfg.FeeGranter),
+			zap.Time("expiration", *expiration),
+		)
+		persistence.lastFeeGrantAlert = now
+		return nil
+	}
+
+	if spendLimit == nil {
+		return nil
+	}
+	spent, _ := totalFees.SafeSub(persistence.feeGrantFirstFees...)
+	runway, ok := feeGrantRunway(spendLimit, spent, now.Sub(persistence.feeGrantFirstCheck))
+	if ok && runway < feeGrantAlertWindow {
+		ccp.log.Warn("Fee grant allowance is nearly exhausted, renew the grant",
+			zap.String("fee_granter", cc.PCfg.FeeGranter),
+			zap.String("remaining", spendLimit.String()),
+			zap.Duration("estimated_time_remaining", runway),
+		)
+		persistence.lastFeeGrantAlert = now
 	}
 	return nil
 }
+
+// indexPackets stores the packet events of a block in the packet index of the chain, if set.
+func (ccp *CosmosChainProcessor) indexPackets(ctx context.Context, messages []chains.IbcMessage) {
+	index := ccp.chainProvider.packetIndex
+	if index == nil {
+		return
+	}
+
+	var events []indexer.Event
+	for _, m := range messages {
+		if pi, ok := m.Info.(*chains.PacketInfo); ok {
+			events = append(events, indexer.Event{
+				EventType: m.EventType,
+				Packet:    provider.PacketInfo(*pi),
+			})
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	if err := index.Index(ctx, ccp.chainProvider.ChainId(), events); err != nil {
+		ccp.log.Warn("Failed to index packet events", zap.Error(err))
+	}
+}
@@ -0,0 +1,71 @@
+package cosmos
+
+import (
+	"testing"
+	"time"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCheckChainHalt(t *testing.T) {
+	ccp := NewCosmosChainProcessor(zap.NewNop(), &CosmosProvider{PCfg: CosmosProviderConfig{ChainID: "chain-a"}}, nil)
+	persistence := &queryCyclePersistence{haltThreshold: time.Minute}
+
+	now := time.Now()
+	ccp.checkChainHalt(persistence, now.Add(-10*time.Second), now)
+	require.False(t, persistence.halted)
+
+	// no new block for longer than the threshold.
+	ccp.checkChainHalt(persistence, now.Add(-10*time.Second), now.Add(2*time.Minute))
+	require.True(t, persistence.halted)
+
+	// the chain produces blocks again.
+	ccp.checkChainHalt(persistence, now.Add(2*time.Minute), now.Add(2*time.Minute))
+	require.False(t, persistence.halted)
+
+	// nodes which do not report the time of the latest block are never considered halted.
+	ccp.checkChainHalt(persistence, time.Time{}, now)
+	require.False(t, persistence.halted)
+}
+
+func TestUpdateChainUpgrade(t *testing.T) {
+	ccp := NewCosmosChainProcessor(zap.NewNop(), &CosmosProvider{PCfg: CosmosProviderConfig{ChainID: "chain-a"}}, nil)
+	persistence := &queryCyclePersistence{
+		upgradePlan:          &upgradetypes.Plan{Name: "v2", Height: 100},
+		upgradeQuiesceBlocks: 10,
+	}
+
+	now := time.Now()
+	persistence.blockInterval.Observe(79, now.Add(-6*time.Second))
+	persistence.blockInterval.Observe(80, now)
+	persistence.latestHeight = 80
+	ccp.updateChainUpgrade(persistence, now)
+	require.NotNil(t, persistence.upgrade)
+	require.False(t, persistence.upgrade.Quiesced)
+	require.Equal(t, now.Add(20*6*time.Second), persistence.upgrade.Time)
+
+	// relaying is paused shortly before the chain halts at the upgrade height.
+	persistence.latestHeight = 90
+	ccp.updateChainUpgrade(persistence, now)
+	require.True(t, persistence.upgrade.Quiesced)
+
+	persistence.latestHeight = 99
+	ccp.updateChainUpgrade(persistence, now)
+	require.True(t, persistence.upgrade.Quiesced)
+
+	// the upgraded chain committed the block at the upgrade height.
+	persistence.latestHeight = 100
+	ccp.updateChainUpgrade(persistence, now)
+	require.Nil(t, persistence.upgrade)
+	require.Nil(t, persistence.upgradePlan)
+
+	// a cancelled upgrade resumes relaying.
+	persistence.upgradePlan = &upgradetypes.Plan{Name: "v3", Height: 105}
+	ccp.updateChainUpgrade(persistence, now)
+	require.True(t, persistence.upgrade.Quiesced)
+	persistence.upgradePlan = nil
+	ccp.updateChainUpgrade(persistence, now)
+	require.Nil(t, persistence.upgrade)
+}
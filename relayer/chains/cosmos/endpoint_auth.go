@@ -0,0 +1,151 @@
+package cosmos
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	libclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// EndpointAuth configures the TLS settings and auth headers of the connections to the RPC and gRPC endpoints of
+// a chain, as required by managed node providers which authenticate their clients. The RPC endpoint is only
+// queried over http, the relayer does not subscribe to events over its websocket.
+type EndpointAuth struct {
+	// CAFile is a PEM file of certificate authorities trusted for https endpoints, in addition to the system ones.
+	CAFile string `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+
+	// CertFile and KeyFile are the PEM files of the client certificate presented to https endpoints, for mutual TLS.
+	CertFile string `json:"cert-file,omitempty" yaml:"cert-file,omitempty"`
+	KeyFile  string `json:"key-file,omitempty" yaml:"key-file,omitempty"`
+
+	// ServerName, if set, is the name verified against the certificates of https endpoints rather than their host.
+	ServerName string `json:"server-name,omitempty" yaml:"server-name,omitempty"`
+
+	// Headers are set on every request to the endpoints, e.g. "Authorization: Bearer ${env:NODE_API_TOKEN}".
+	// They are sent as metadata with gRPC requests.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// Validate checks that the client certificate is configured with both its files, and that the TLS files can be loaded.
+func (a *EndpointAuth) Validate() error {
+	if (a.CertFile == "") != (a.KeyFile == "") {
+		return errors.New("cert-file and key-file must be set together")
+	}
+	for name := range a.Headers {
+		if name == "" {
+			return errors.New("header names must not be empty")
+		}
+	}
+	_, err := a.tlsConfig()
+	return err
+}
+
+// tlsConfig returns the TLS config of https endpoints, nil if a is nil or has no TLS settings.
+func (a *EndpointAuth) tlsConfig() (*tls.Config, error) {
+	if a == nil || (a.CAFile == "" && a.CertFile == "" && a.ServerName == "") {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: a.ServerName,
+	}
+	if a.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(a.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca-file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca-file %s", a.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if a.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(a.CertFile, a.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// httpClient returns the client of the http requests to the RPC endpoint at addr, with the TLS settings and headers.
+// A nil EndpointAuth returns the default client.
+func (a *EndpointAuth) httpClient(addr string, timeout time.Duration) (*http.Client, error) {
+	c, err := libclient.DefaultHTTPClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	c.Timeout = timeout
+
+	tlsConfig, err := a.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		c.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	}
+	if a != nil && len(a.Headers) > 0 {
+		c.Transport = headerTransport{base: c.Transport, headers: a.Headers}
+	}
+	return c, nil
+}
+
+// rpcClient returns the upstream CometBFT client of the RPC endpoint at addr, with the TLS settings and headers.
+func (a *EndpointAuth) rpcClient(addr string, timeout time.Duration) (*rpchttp.HTTP, error) {
+	httpClient, err := a.httpClient(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return rpchttp.NewWithClient(addr, "/websocket", httpClient)
+}
+
+// grpcDialOptions returns the dial options which send the headers as metadata with every gRPC request.
+func (a *EndpointAuth) grpcDialOptions() []grpc.DialOption {
+	if a == nil || len(a.Headers) == 0 {
+		return nil
+	}
+	kv := make([]string, 0, 2*len(a.Headers))
+	for name, value := range a.Headers {
+		kv = append(kv, name, value)
+	}
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(func(
+			ctx context.Context,
+			method string,
+			req, reply any,
+			cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker,
+			opts ...grpc.CallOption,
+		) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, kv...), method, req, reply, cc, opts...)
+		}),
+	}
+}
+
+// headerTransport sets headers on every request before sending it with base.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,136 @@
+package cosmos
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestEndpointAuthValidate(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	testCases := []struct {
+		name    string
+		auth    EndpointAuth
+		wantErr string
+	}{
+		{
+			name: "headers only",
+			auth: EndpointAuth{Headers: map[string]string{"Authorization": "Bearer token"}},
+		},
+		{
+			name:    "cert without key",
+			auth:    EndpointAuth{CertFile: "client.pem"},
+			wantErr: "must be set together",
+		},
+		{
+			name:    "missing ca file",
+			auth:    EndpointAuth{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: "failed to read ca-file",
+		},
+		{
+			name:    "ca file without certificates",
+			auth:    EndpointAuth{CAFile: caFile},
+			wantErr: "no certificates found",
+		},
+		{
+			name:    "empty header name",
+			auth:    EndpointAuth{Headers: map[string]string{"": "token"}},
+			wantErr: "header names must not be empty",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.auth.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEndpointAuthHTTPClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, ca, 0o600))
+
+	// the certificate of the server is not trusted without the ca-file.
+	c, err := (&EndpointAuth{}).httpClient(srv.URL, time.Second)
+	require.NoError(t, err)
+	_, err = c.Get(srv.URL)
+	require.ErrorContains(t, err, "certificate")
+
+	c, err = (&EndpointAuth{CAFile: caFile}).httpClient(srv.URL, time.Second)
+	require.NoError(t, err)
+	res, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	c, err = (&EndpointAuth{CAFile: caFile, Headers: map[string]string{"Authorization": "Bearer token"}}).httpClient(srv.URL, time.Second)
+	require.NoError(t, err)
+	res, err = c.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestEndpointAuthGRPCHeaders(t *testing.T) {
+	cc := &CosmosProvider{
+		PCfg: CosmosProviderConfig{AccountPrefix: "cosmos"},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+
+	var md metadata.MD
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.ForceServerCodec(codec.NewProtoCodec(cc.Cdc.InterfaceRegistry).GRPCCodec()),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ = metadata.FromIncomingContext(ctx)
+			return handler(ctx, req)
+		}),
+	)
+	banktypes.RegisterQueryServer(srv, &mockBankQueryServer{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	auth := &EndpointAuth{Headers: map[string]string{"X-Api-Key": "secret"}}
+	conn, err := newGRPCConn("passthrough:///bufnet", cc.Cdc.InterfaceRegistry, nil,
+		append(auth.grpcDialOptions(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}))...,
+	)
+	require.NoError(t, err)
+	cc.GRPCConn = conn
+	t.Cleanup(func() { _ = cc.Close() })
+
+	_, err = banktypes.NewQueryClient(cc).Balance(context.Background(), &banktypes.QueryBalanceRequest{
+		Address: "cosmos10d07y265gmmuvt4z0w9aw880jnsr700j6zn9kn",
+		Denom:   "uatom",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"secret"}, md.Get("x-api-key"))
+}
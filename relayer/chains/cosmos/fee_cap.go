@@ -0,0 +1,117 @@
+package cosmos
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// feeCapWindow is the window over which the fees of txs are limited by the per-hour fee cap.
+const feeCapWindow = time.Hour
+
+// FeeCap limits the fees paid for the txs which the relayer broadcasts to a chain, so that the wallet is not drained
+// during gas price spikes. Caps are coins, e.g. "50000uatom", and fees in denoms without a cap are not limited.
+type FeeCap struct {
+	// PerTx is the maximum fee of a single tx.
+	PerTx string `json:"per-tx,omitempty" yaml:"per-tx,omitempty"`
+
+	// PerHour is the maximum of the fees of the txs broadcast within the last hour.
+	PerHour string `json:"per-hour,omitempty" yaml:"per-hour,omitempty"`
+}
+
+// Validate checks that the caps are valid coins.
+func (c *FeeCap) Validate() error {
+	if _, err := sdk.ParseCoinsNormalized(c.PerTx); err != nil {
+		return fmt.Errorf("invalid per-tx: %w", err)
+	}
+	if _, err := sdk.ParseCoinsNormalized(c.PerHour); err != nil {
+		return fmt.Errorf("invalid per-hour: %w", err)
+	}
+	return nil
+}
+
+// feeSpend records the fees of the txs broadcast within the fee cap window.
+type feeSpend struct {
+	mu  sync.Mutex
+	txs []spentFee
+}
+
+type spentFee struct {
+	time time.Time
+	fees sdk.Coins
+}
+
+// add records the fees of a tx broadcast at now.
+func (s *feeSpend) add(now time.Time, fees sdk.Coins) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.txs = append(s.txs, spentFee{time: now, fees: fees})
+}
+
+// total returns the fees of the txs broadcast within the fee cap window before now.
+func (s *feeSpend) total(now time.Time) sdk.Coins {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := 0
+	for i < len(s.txs) && now.Sub(s.txs[i].time) >= feeCapWindow {
+		i++
+	}
+	s.txs = s.txs[i:]
+
+	total := sdk.NewCoins()
+	for _, tx := range s.txs {
+		total = total.Add(tx.fees...)
+	}
+	return total
+}
+
+// exceedsCap returns the denom in which fees are higher than limit, if any.
+func exceedsCap(fees, limit sdk.Coins) (string, bool) {
+	for _, c := range limit {
+		if fees.AmountOf(c.Denom).GT(c.Amount) {
+			return c.Denom, true
+		}
+	}
+	return "", false
+}
+
+// checkFeeCap returns an error wrapping provider.ErrFeeCapExceeded if the fees of a tx are higher than the per-tx
+// fee cap, or would raise the fees spent within the last hour above the per-hour fee cap.
+func (cc *CosmosProvider) checkFeeCap(fees sdk.Coins) error {
+	if cc.PCfg.FeeCap == nil {
+		return nil
+	}
+
+	perTx, _ := sdk.ParseCoinsNormalized(cc.PCfg.FeeCap.PerTx)
+	if denom, ok := exceedsCap(fees, perTx); ok {
+		cc.log.Warn("Skipping tx whose fee exceeds the per-tx fee cap",
+			zap.String("chain_id", cc.ChainId()),
+			zap.String("denom", denom),
+			zap.Stringer("fee", fees),
+			zap.Stringer("cap", perTx),
+		)
+		return fmt.Errorf("%w: fee %s is higher than per-tx cap %s", provider.ErrFeeCapExceeded, fees, perTx)
+	}
+
+	perHour, _ := sdk.ParseCoinsNormalized(cc.PCfg.FeeCap.PerHour)
+	if perHour.Empty() {
+		return nil
+	}
+	spent := cc.feeSpend.total(time.Now())
+	if denom, ok := exceedsCap(spent.Add(fees...), perHour); ok {
+		cc.log.Warn("Skipping tx whose fee exceeds the per-hour fee cap",
+			zap.String("chain_id", cc.ChainId()),
+			zap.String("denom", denom),
+			zap.Stringer("fee", fees),
+			zap.Stringer("spent", spent),
+			zap.Stringer("cap", perHour),
+		)
+		return fmt.Errorf("%w: fee %s on top of %s spent within the last hour is higher than per-hour cap %s",
+			provider.ErrFeeCapExceeded, fees, spent, perHour)
+	}
+	return nil
+}
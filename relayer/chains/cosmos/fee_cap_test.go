@@ -0,0 +1,57 @@
+package cosmos
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFeeSpendWindow(t *testing.T) {
+	var spend feeSpend
+	now := time.Now()
+
+	spend.add(now.Add(-90*time.Minute), sdk.NewCoins(sdk.NewInt64Coin("uatom", 500)))
+	spend.add(now.Add(-30*time.Minute), sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)))
+	spend.add(now, sdk.NewCoins(sdk.NewInt64Coin("uatom", 50), sdk.NewInt64Coin("uosmo", 10)))
+
+	require.Equal(t, "150uatom,10uosmo", spend.total(now).String())
+	require.Len(t, spend.txs, 2)
+	require.Equal(t, "50uatom,10uosmo", spend.total(now.Add(45*time.Minute)).String())
+}
+
+func TestCheckFeeCap(t *testing.T) {
+	cc := &CosmosProvider{
+		log: zap.NewNop(),
+		PCfg: CosmosProviderConfig{
+			ChainID: "chain-a",
+			FeeCap:  &FeeCap{PerTx: "200uatom", PerHour: "500uatom"},
+		},
+	}
+	require.NoError(t, cc.PCfg.FeeCap.Validate())
+
+	require.NoError(t, cc.checkFeeCap(sdk.NewCoins(sdk.NewInt64Coin("uatom", 200))))
+
+	// fees in denoms without a cap are not limited.
+	require.NoError(t, cc.checkFeeCap(sdk.NewCoins(sdk.NewInt64Coin("uosmo", 1000))))
+
+	err := cc.checkFeeCap(sdk.NewCoins(sdk.NewInt64Coin("uatom", 201)))
+	require.ErrorIs(t, err, provider.ErrFeeCapExceeded)
+	require.Equal(t, provider.TxFailureFeeCapExceeded, provider.ClassifyTxFailure(err))
+
+	cc.feeSpend.add(time.Now(), sdk.NewCoins(sdk.NewInt64Coin("uatom", 200)))
+	cc.feeSpend.add(time.Now(), sdk.NewCoins(sdk.NewInt64Coin("uatom", 200)))
+	require.NoError(t, cc.checkFeeCap(sdk.NewCoins(sdk.NewInt64Coin("uatom", 100))))
+	require.ErrorIs(t, cc.checkFeeCap(sdk.NewCoins(sdk.NewInt64Coin("uatom", 101))), provider.ErrFeeCapExceeded)
+
+	// the max-gas-amount is a cap on the gas of a tx.
+	cc.PCfg.MaxGasAmount = 300000
+	_, err = cc.adjustEstimatedGas(350000, 1.5)
+	require.ErrorIs(t, err, provider.ErrFeeCapExceeded)
+
+	require.Error(t, (&FeeCap{PerTx: "200"}).Validate())
+	require.NoError(t, (&FeeCap{}).Validate())
+}
@@ -0,0 +1,78 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// ClaimFees withdraws the ICS-29 fees paid to the fee-payee-key of the chain, which the key of the chain registered
+// as its payee with the fee middleware, to the key of the chain. The whole balance of the fee-payee-key is sent,
+// less the fee of the claim tx, which is paid by the fee-payee-key. The claimed coins are returned, which are empty
+// if the balance does not cover the fee of the claim tx.
+func (cc *CosmosProvider) ClaimFees(ctx context.Context, memo string) (sdk.Coins, error) {
+	payeeKey := cc.PCfg.FeePayeeKey
+	if payeeKey == "" {
+		return nil, fmt.Errorf("chain %s has no fee-payee-key, ICS-29 fees are paid to the key of the chain", cc.ChainId())
+	}
+	if payeeKey == cc.PCfg.Key {
+		return nil, fmt.Errorf("fee-payee-key of chain %s is the key of the chain", cc.ChainId())
+	}
+
+	payee, err := cc.ShowAddress(payeeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address of fee-payee-key %s: %w", payeeKey, err)
+	}
+	relayer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := cc.QueryBalanceWithAddress(ctx, payee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balance of fee payee %s: %w", payee, err)
+	}
+	if balance.IsZero() {
+		return sdk.NewCoins(), nil
+	}
+
+	// simulations are not charged a fee, so the claim of the whole balance is simulated to estimate the fee.
+	msg := &banktypes.MsgSend{FromAddress: payee, ToAddress: relayer, Amount: balance}
+	txf, err := cc.PrepareFactory(cc.TxFactory(cc.DynamicFee(ctx)), payeeKey)
+	if err != nil {
+		return nil, err
+	}
+	_, gas, err := cc.CalculateGas(ctx, txf, payeeKey, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas of fee claim: %w", err)
+	}
+	txb, err := txf.WithGas(gas).BuildUnsignedTx(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Amount = claimAmount(balance, txb.GetTx().GetFee())
+	if msg.Amount.IsZero() {
+		return msg.Amount, nil
+	}
+
+	res, err := cc.SubmitTxAwaitResponse(ctx, []sdk.Msg{msg}, memo, gas, payeeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send fee claim: %w", err)
+	}
+	if res.TxResponse.Code != 0 {
+		return nil, fmt.Errorf("fee claim tx %s failed with code %d: %s", res.TxResponse.TxHash, res.TxResponse.Code, res.TxResponse.RawLog)
+	}
+	return msg.Amount, nil
+}
+
+// claimAmount returns the coins of balance left after paying fee, or no coins if balance does not cover fee.
+func claimAmount(balance, fee sdk.Coins) sdk.Coins {
+	amount, negative := balance.SafeSub(fee...)
+	if negative {
+		return sdk.NewCoins()
+	}
+	return amount
+}
@@ -0,0 +1,30 @@
+package cosmos
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimAmount(t *testing.T) {
+	balance := sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000), sdk.NewInt64Coin("uosmo", 50))
+
+	require.Equal(t, "800uatom,50uosmo", claimAmount(balance, sdk.NewCoins(sdk.NewInt64Coin("uatom", 200))).String())
+	require.Equal(t, "50uosmo", claimAmount(balance, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))).String())
+
+	// balances which do not cover the fee of the claim are not claimed.
+	require.True(t, claimAmount(balance, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1001))).IsZero())
+	require.True(t, claimAmount(sdk.NewCoins(sdk.NewInt64Coin("uosmo", 50)), sdk.NewCoins(sdk.NewInt64Coin("uatom", 1))).IsZero())
+}
+
+func TestClaimFeesRequiresPayeeKey(t *testing.T) {
+	cc := &CosmosProvider{PCfg: CosmosProviderConfig{ChainID: "chain-a", Key: "default"}}
+	_, err := cc.ClaimFees(context.Background(), "")
+	require.ErrorContains(t, err, "no fee-payee-key")
+
+	cc.PCfg.FeePayeeKey = "default"
+	_, err = cc.ClaimFees(context.Background(), "")
+	require.ErrorContains(t, err, "is the key of the chain")
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"go.uber.org/zap"
 )
 
@@ -32,7 +34,7 @@ func (cc *CosmosProvider) DynamicFee(ctx context.Context) string {
 // QueryBaseFee attempts to make an ABCI query to retrieve the base fee on chains using the Osmosis EIP-1559 implementation.
 // This is currently hardcoded to only work on Osmosis.
 func (cc *CosmosProvider) QueryBaseFee(ctx context.Context) (string, error) {
-	resp, err := cc.RPCClient.ABCIQuery(ctx, queryPath, nil)
+	resp, err := cc.node().ABCIQuery(ctx, queryPath, nil)
 	if err != nil || resp.Response.Code != 0 {
 		return "", err
 	}
@@ -77,3 +79,53 @@ func parseTokenDenom(gasPrice string) (string, error) {
 
 	return matches[1], nil
 }
+
+// withFallbackGasPrices returns txf with the first of the FallbackGasPrices whose fee for gas the balance of
+// signingKey can pay, if it cannot pay the fee with the gas prices of txf. txf is returned as is if the chain has no
+// FallbackGasPrices or none of them can be paid either, in which case the tx fails for insufficient funds as usual.
+func (cc *CosmosProvider) withFallbackGasPrices(ctx context.Context, txf tx.Factory, signingKey string, gas uint64) tx.Factory {
+	if len(cc.PCfg.FallbackGasPrices) == 0 {
+		return txf
+	}
+
+	balance, err := cc.QueryBalance(ctx, signingKey)
+	if err != nil {
+		cc.log.Warn("Failed to query the balance of the signer for the fallback gas prices", zap.Error(err))
+		return txf
+	}
+
+	candidates := []sdk.DecCoins{txf.GasPrices()}
+	for _, gasPrice := range cc.PCfg.FallbackGasPrices {
+		// the fallback gas prices are checked by Validate.
+		gp, _ := sdk.ParseDecCoins(gasPrice)
+		candidates = append(candidates, gp)
+	}
+
+	i := selectGasPrices(candidates, gas, balance)
+	if i <= 0 {
+		return txf
+	}
+
+	cc.log.Debug(
+		"Paying fees with fallback gas prices",
+		zap.String("gas_prices", cc.PCfg.FallbackGasPrices[i-1]),
+		zap.String("signer", signingKey),
+	)
+	return txf.WithGasPrices(cc.PCfg.FallbackGasPrices[i-1])
+}
+
+// selectGasPrices returns the index of the first gas prices of candidates whose fees for gas balance can pay,
+// or -1 if it can pay none of them. The fees are computed as by the SDK, rounding up.
+func selectGasPrices(candidates []sdk.DecCoins, gas uint64, balance sdk.Coins) int {
+	gasLimit := sdkmath.LegacyNewDec(int64(gas))
+	for i, gasPrices := range candidates {
+		fees := sdk.NewCoins()
+		for _, gp := range gasPrices {
+			fees = fees.Add(sdk.NewCoin(gp.Denom, gp.Amount.Mul(gasLimit).Ceil().RoundInt()))
+		}
+		if balance.IsAllGTE(fees) {
+			return i
+		}
+	}
+	return -1
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +61,36 @@ func TestParseDenom(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "uosmo", denom)
 }
+
+func TestSelectGasPrices(t *testing.T) {
+	candidates := []sdk.DecCoins{
+		sdk.NewDecCoins(sdk.NewDecCoinFromDec("uosmo", sdkmath.LegacyMustNewDecFromStr("0.0025"))),
+		sdk.NewDecCoins(sdk.NewDecCoinFromDec("uusdc", sdkmath.LegacyMustNewDecFromStr("0.01"))),
+		sdk.NewDecCoins(sdk.NewDecCoinFromDec("uatom", sdkmath.LegacyMustNewDecFromStr("0.005"))),
+	}
+
+	testCases := []struct {
+		name    string
+		gas     uint64
+		balance sdk.Coins
+		want    int
+	}{
+		{"native balance pays", 100_000, sdk.NewCoins(sdk.NewInt64Coin("uosmo", 250)), 0},
+		{"fee is rounded up", 100_001, sdk.NewCoins(sdk.NewInt64Coin("uosmo", 250), sdk.NewInt64Coin("uusdc", 1001)), 1},
+		{"first fallback which pays", 100_000, sdk.NewCoins(sdk.NewInt64Coin("uusdc", 999), sdk.NewInt64Coin("uatom", 500)), 2},
+		{"nothing pays", 100_000, sdk.NewCoins(sdk.NewInt64Coin("uatom", 499)), -1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, selectGasPrices(candidates, tc.gas, tc.balance))
+		})
+	}
+}
+
+func TestFallbackGasPricesValidate(t *testing.T) {
+	cfg := CosmosProviderConfig{Timeout: "10s", FallbackGasPrices: []string{"0.01ibc/498A0751C798A0D9A389AA3691123DADA57DAA4FE165D5C75894505B876BA6E4"}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.FallbackGasPrices = []string{"0.01uusdc,0.005uatom"}
+	require.Error(t, cfg.Validate())
+}
@@ -525,3 +525,88 @@ func (cc *CosmosProvider) GrantBasicAllowanceWithExpiration(ctx context.Context,
 
 	return txResp, nil
 }
+
+// QueryFeeGranterAllowance queries the allowance which the configured fee-granter granted to the key of the provider.
+// It returns the remaining spend limit of the allowance, which is nil if the allowance is unlimited,
+// and its expiration, which is nil if the allowance does not expire.
+func (cc *CosmosProvider) QueryFeeGranterAllowance(ctx context.Context) (spendLimit sdk.Coins, expiration *time.Time, err error) {
+	if cc.PCfg.FeeGranter == "" {
+		return nil, nil, errors.New("no fee-granter configured for chainclient")
+	}
+
+	granter, err := cc.AccountFromKeyOrAddress(cc.PCfg.FeeGranter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid fee-granter %s: %w", cc.PCfg.FeeGranter, err)
+	}
+	grantee, err := cc.GetKeyAddress(cc.PCfg.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := feegrant.NewQueryClient(cc).Allowance(ctx, &feegrant.QueryAllowanceRequest{
+		Granter: cc.MustEncodeAccAddr(granter),
+		Grantee: cc.MustEncodeAccAddr(grantee),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query allowance of fee-granter %s: %w", cc.PCfg.FeeGranter, err)
+	}
+
+	var allowance feegrant.FeeAllowanceI
+	if err := cc.Cdc.InterfaceRegistry.UnpackAny(res.Allowance.Allowance, &allowance); err != nil {
+		return nil, nil, err
+	}
+
+	expiration, err = allowance.ExpiresAt()
+	if err != nil {
+		return nil, nil, err
+	}
+	spendLimit, err = allowanceSpendLimit(allowance)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spendLimit, expiration, nil
+}
+
+// allowanceSpendLimit returns the remaining spend limit of an allowance, which is nil if it is unlimited.
+func allowanceSpendLimit(allowance feegrant.FeeAllowanceI) (sdk.Coins, error) {
+	switch a := allowance.(type) {
+	case *feegrant.BasicAllowance:
+		return a.SpendLimit, nil
+	case *feegrant.PeriodicAllowance:
+		return a.Basic.SpendLimit, nil
+	case *feegrant.AllowedMsgAllowance:
+		inner, err := a.GetAllowance()
+		if err != nil {
+			return nil, err
+		}
+		return allowanceSpendLimit(inner)
+	default:
+		return nil, fmt.Errorf("unsupported allowance type %T", allowance)
+	}
+}
+
+// feeGrantRunway returns how long spendLimit lasts if fees keep being spent at the rate at which spent was spent
+// over elapsed. It returns false if no fees were spent in the denoms of spendLimit.
+func feeGrantRunway(spendLimit, spent sdk.Coins, elapsed time.Duration) (time.Duration, bool) {
+	var runway time.Duration
+	found := false
+	for _, fee := range spent {
+		if !fee.Amount.IsPositive() {
+			continue
+		}
+		ok, remaining := spendLimit.Find(fee.Denom)
+		if !ok {
+			continue
+		}
+
+		d := remaining.Amount.Mul(sdkmath.NewInt(int64(elapsed))).Quo(fee.Amount)
+		if !d.IsInt64() {
+			continue
+		}
+		if !found || time.Duration(d.Int64()) < runway {
+			runway = time.Duration(d.Int64())
+			found = true
+		}
+	}
+	return runway, found
+}
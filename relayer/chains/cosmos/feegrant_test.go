@@ -0,0 +1,39 @@
+package cosmos
+
+import (
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/x/feegrant"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeGrantRunway(t *testing.T) {
+	spendLimit := sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))
+
+	// 100uatom spent in an hour leaves 10 hours.
+	runway, ok := feeGrantRunway(spendLimit, sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), time.Hour)
+	require.True(t, ok)
+	require.Equal(t, 10*time.Hour, runway)
+
+	// fees in denoms which the grant does not pay are ignored.
+	_, ok = feeGrantRunway(spendLimit, sdk.NewCoins(sdk.NewInt64Coin("uosmo", 100)), time.Hour)
+	require.False(t, ok)
+
+	_, ok = feeGrantRunway(spendLimit, sdk.NewCoins(), time.Hour)
+	require.False(t, ok)
+}
+
+func TestAllowanceSpendLimit(t *testing.T) {
+	limit := sdk.NewCoins(sdk.NewCoin("uatom", sdkmath.NewInt(1000)))
+
+	spendLimit, err := allowanceSpendLimit(&feegrant.BasicAllowance{SpendLimit: limit})
+	require.NoError(t, err)
+	require.Equal(t, limit, spendLimit)
+
+	spendLimit, err = allowanceSpendLimit(&feegrant.PeriodicAllowance{Basic: feegrant.BasicAllowance{}})
+	require.NoError(t, err)
+	require.Nil(t, spendLimit)
+}
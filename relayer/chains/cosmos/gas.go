@@ -0,0 +1,131 @@
+package cosmos
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// wasmHookGasKeySuffix distinguishes the gas of packet messages which execute a CosmWasm contract through the
+// ibc-hooks middleware from the gas of plain packet messages of the same type, since the contract execution
+// can use many times more gas.
+const wasmHookGasKeySuffix = "+wasm-hook"
+
+// hasWasmHook returns true if the msg executes a CosmWasm contract through the ibc-hooks middleware,
+// i.e. it receives an ICS-20 packet with a wasm memo, or acknowledges or times out an ICS-20 packet
+// with an ibc_callback memo.
+func hasWasmHook(msg sdk.Msg) bool {
+	switch m := msg.(type) {
+	case *chantypes.MsgRecvPacket:
+		_, ok := provider.PacketWasmHook(m.Packet.Data)
+		return ok
+	case *chantypes.MsgAcknowledgement:
+		_, ok := provider.PacketWasmCallback(m.Packet.Data)
+		return ok
+	case *chantypes.MsgTimeout:
+		_, ok := provider.PacketWasmCallback(m.Packet.Data)
+		return ok
+	case *chantypes.MsgTimeoutOnClose:
+		_, ok := provider.PacketWasmCallback(m.Packet.Data)
+		return ok
+	}
+	return false
+}
+
+// gasKey returns the key of the msg in the gas table.
+func gasKey(msg sdk.Msg) string {
+	if hasWasmHook(msg) {
+		return sdk.MsgTypeURL(msg) + wasmHookGasKeySuffix
+	}
+	return sdk.MsgTypeURL(msg)
+}
+
+// gasTable learns the gas used by each type of message from the simulations of txs, keeping packet messages
+// which execute a contract through the ibc-hooks middleware apart, to estimate the gas of txs while simulation is unavailable.
+type gasTable struct {
+	mu  sync.Mutex
+	gas map[string]uint64
+}
+
+// record records the gas used by a simulation of msgs. The gas is attributed evenly to the messages,
+// and the most gas observed for each type of message is kept, so that estimates err on the side of too much gas.
+func (t *gasTable) record(msgs []sdk.Msg, gasUsed uint64) {
+	if len(msgs) == 0 || gasUsed == 0 {
+		return
+	}
+	perMsg := gasUsed / uint64(len(msgs))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.gas == nil {
+		t.gas = make(map[string]uint64)
+	}
+	for _, msg := range msgs {
+		key := gasKey(msg)
+		if perMsg > t.gas[key] {
+			t.gas[key] = perMsg
+		}
+	}
+}
+
+// estimate returns the gas used by msgs as learned from previous simulations,
+// or false if no tx with one of the types of msgs has been simulated yet.
+func (t *gasTable) estimate(msgs []sdk.Msg) (uint64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var gasUsed uint64
+	for _, msg := range msgs {
+		gas, ok := t.gas[gasKey(msg)]
+		if !ok {
+			return 0, false
+		}
+		gasUsed += gas
+	}
+	return gasUsed, len(msgs) > 0
+}
+
+// gasAdjustment returns the gas adjustment factor for a tx of msgs, the largest of the factors configured
+// in msg-gas-adjustments for their types, falling back to gas-adjustment. Messages which execute a contract
+// through the ibc-hooks middleware are adjusted by at least wasm-hook-gas-adjustment.
+func (cc *CosmosProvider) gasAdjustment(msgs []sdk.Msg) float64 {
+	var adjustment float64
+	for _, msg := range msgs {
+		msgAdjustment, ok := cc.PCfg.MsgGasAdjustments[sdk.MsgTypeURL(msg)]
+		if !ok {
+			msgAdjustment = cc.PCfg.GasAdjustment
+		}
+		if hasWasmHook(msg) {
+			msgAdjustment = math.Max(msgAdjustment, cc.PCfg.WasmHookGasAdjustment)
+		}
+		adjustment = math.Max(adjustment, msgAdjustment)
+	}
+	if adjustment == 0 {
+		return cc.PCfg.GasAdjustment
+	}
+	return adjustment
+}
+
+// adjustEstimatedGas multiplies the estimated gas usage by the gas adjustment factor, raising it to min-gas-amount
+// if it is lower. It returns an error if the estimated gas is higher than max-gas-amount.
+func (cc *CosmosProvider) adjustEstimatedGas(gasUsed uint64, adjustment float64) (uint64, error) {
+	if gasUsed == 0 {
+		return gasUsed, nil
+	}
+	if cc.PCfg.MaxGasAmount > 0 && gasUsed > cc.PCfg.MaxGasAmount {
+		return 0, fmt.Errorf("%w: estimated gas %d is higher than max gas %d", provider.ErrFeeCapExceeded, gasUsed, cc.PCfg.MaxGasAmount)
+	}
+	gas := adjustment * float64(gasUsed)
+	if math.IsInf(gas, 1) {
+		return 0, fmt.Errorf("infinite gas used")
+	}
+	if uint64(gas) < cc.PCfg.MinGasAmount {
+		return cc.PCfg.MinGasAmount, nil
+	}
+	return uint64(gas), nil
+}
@@ -0,0 +1,71 @@
+package cosmos
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasTable(t *testing.T) {
+	var table gasTable
+	updateClient, recvPacket := &clienttypes.MsgUpdateClient{}, &chantypes.MsgRecvPacket{}
+
+	_, ok := table.estimate([]sdk.Msg{updateClient})
+	require.False(t, ok)
+
+	table.record([]sdk.Msg{updateClient, recvPacket, recvPacket}, 300000)
+	table.record([]sdk.Msg{recvPacket}, 80000)
+
+	gas, ok := table.estimate([]sdk.Msg{updateClient, recvPacket})
+	require.True(t, ok)
+	require.Equal(t, uint64(200000), gas)
+
+	_, ok = table.estimate([]sdk.Msg{updateClient, &chantypes.MsgAcknowledgement{}})
+	require.False(t, ok)
+
+	// packets which execute a contract through ibc-hooks are estimated apart from plain packets.
+	hookRecvPacket := &chantypes.MsgRecvPacket{Packet: chantypes.Packet{Data: wasmHookPacketData(`{"wasm":{"contract":"osmo1contract","msg":{}}}`)}}
+	_, ok = table.estimate([]sdk.Msg{hookRecvPacket})
+	require.False(t, ok)
+
+	table.record([]sdk.Msg{hookRecvPacket}, 500000)
+	gas, ok = table.estimate([]sdk.Msg{recvPacket, hookRecvPacket})
+	require.True(t, ok)
+	require.Equal(t, uint64(600000), gas)
+}
+
+func wasmHookPacketData(memo string) []byte {
+	return transfertypes.NewFungibleTokenPacketData("uosmo", "100", "cosmos1sender", "osmo1contract", memo).GetBytes()
+}
+
+func TestGasAdjustment(t *testing.T) {
+	cc := &CosmosProvider{PCfg: CosmosProviderConfig{
+		GasAdjustment: 1.3,
+		MinGasAmount:  100000,
+		MsgGasAdjustments: map[string]float64{
+			sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}): 2,
+		},
+	}}
+
+	require.Equal(t, 1.3, cc.gasAdjustment([]sdk.Msg{&chantypes.MsgRecvPacket{}}))
+	require.Equal(t, 2.0, cc.gasAdjustment([]sdk.Msg{&clienttypes.MsgUpdateClient{}, &chantypes.MsgRecvPacket{}}))
+
+	// packets which execute a contract through ibc-hooks are adjusted by at least the wasm-hook-gas-adjustment.
+	cc.PCfg.WasmHookGasAdjustment = 3
+	hookAck := &chantypes.MsgAcknowledgement{Packet: chantypes.Packet{Data: wasmHookPacketData(`{"ibc_callback":"cosmos1contract"}`)}}
+	require.Equal(t, 1.3, cc.gasAdjustment([]sdk.Msg{&chantypes.MsgRecvPacket{}}))
+	require.Equal(t, 3.0, cc.gasAdjustment([]sdk.Msg{&clienttypes.MsgUpdateClient{}, hookAck}))
+
+	gas, err := cc.adjustEstimatedGas(200000, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(400000), gas)
+
+	// estimates are raised to the min-gas-amount.
+	gas, err = cc.adjustEstimatedGas(50000, 1.3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100000), gas)
+}
@@ -0,0 +1,110 @@
+package cosmos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// GovProposal is a governance proposal in the format of the proposal file of `tx gov submit-proposal`.
+type GovProposal struct {
+	// Messages are the messages executed by the proposal, proto-JSON-encoded as Anys.
+	Messages  []json.RawMessage `json:"messages"`
+	Metadata  string            `json:"metadata"`
+	Deposit   string            `json:"deposit"`
+	Title     string            `json:"title"`
+	Summary   string            `json:"summary"`
+	Expedited bool              `json:"expedited"`
+}
+
+// GovAuthority returns the address of the governance module, which is the authority of the chain
+// that signs the messages of passed proposals.
+func (cc *CosmosProvider) GovAuthority() (string, error) {
+	return sdk.Bech32ifyAddressBytes(cc.PCfg.AccountPrefix, authtypes.NewModuleAddress(govtypes.ModuleName))
+}
+
+// MsgChannelOpenInitByAuthority returns a MsgChannelOpenInit signed by the governance authority,
+// for chains on which only governance may open channels.
+func (cc *CosmosProvider) MsgChannelOpenInitByAuthority(info provider.ChannelInfo) (*chantypes.MsgChannelOpenInit, error) {
+	authority, err := cc.GovAuthority()
+	if err != nil {
+		return nil, err
+	}
+	return &chantypes.MsgChannelOpenInit{
+		PortId: info.PortID,
+		Channel: chantypes.Channel{
+			State:    chantypes.INIT,
+			Ordering: info.Order,
+			Counterparty: chantypes.Counterparty{
+				PortId:    info.CounterpartyPortID,
+				ChannelId: "",
+			},
+			ConnectionHops: info.Hops(),
+			Version:        info.Version,
+		},
+		Signer: authority,
+	}, nil
+}
+
+// NewGovProposal returns the proposal executing msgs, which can be submitted with `tx gov submit-proposal`.
+func (cc *CosmosProvider) NewGovProposal(msgs []sdk.Msg, deposit sdk.Coins, title, summary string) (GovProposal, error) {
+	p := GovProposal{
+		Messages: make([]json.RawMessage, len(msgs)),
+		Deposit:  deposit.String(),
+		Title:    title,
+		Summary:  summary,
+	}
+	for i, msg := range msgs {
+		bz, err := cc.Cdc.Marshaler.MarshalInterfaceJSON(msg)
+		if err != nil {
+			return GovProposal{}, fmt.Errorf("failed to encode proposal message %s: %w", sdk.MsgTypeURL(msg), err)
+		}
+		p.Messages[i] = bz
+	}
+	return p, nil
+}
+
+// SubmitGovProposal submits a proposal executing msgs with the key of the provider, returning the proposal ID.
+func (cc *CosmosProvider) SubmitGovProposal(
+	ctx context.Context,
+	msgs []sdk.Msg,
+	deposit sdk.Coins,
+	title, summary, memo string,
+) (uint64, error) {
+	proposer, err := cc.Address()
+	if err != nil {
+		return 0, err
+	}
+	msg, err := govv1.NewMsgSubmitProposal(msgs, deposit, proposer, "", title, summary, false)
+	if err != nil {
+		return 0, err
+	}
+
+	res, _, err := cc.SendMessage(ctx, NewCosmosMessage(msg, func(signer string) {
+		msg.Proposer = signer
+	}), memo)
+	if err != nil {
+		if res != nil {
+			return 0, fmt.Errorf("proposal tx {%s} failed: %w", res.TxHash, err)
+		}
+		return 0, err
+	}
+
+	for _, event := range res.Events {
+		if event.EventType != govtypes.EventTypeSubmitProposal {
+			continue
+		}
+		if id, ok := event.Attributes[govtypes.AttributeKeyProposalID]; ok {
+			return strconv.ParseUint(id, 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("proposal ID not found in the events of tx {%s}", res.TxHash)
+}
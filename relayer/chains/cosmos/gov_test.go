@@ -0,0 +1,50 @@
+package cosmos
+
+import (
+	"encoding/json"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGovChannelOpenProposal(t *testing.T) {
+	cc := &CosmosProvider{
+		PCfg: CosmosProviderConfig{AccountPrefix: "cosmos"},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+
+	authority, err := cc.GovAuthority()
+	require.NoError(t, err)
+	require.Equal(t, "cosmos10d07y265gmmuvt4z0w9aw880jnsr700j6zn9kn", authority)
+
+	msg, err := cc.MsgChannelOpenInitByAuthority(provider.ChannelInfo{
+		PortID:             "icahost",
+		CounterpartyPortID: "icacontroller-owner",
+		ConnID:             "connection-0",
+		Version:            "ics27-1",
+		Order:              chantypes.ORDERED,
+	})
+	require.NoError(t, err)
+	require.Equal(t, authority, msg.Signer)
+	require.Equal(t, []string{"connection-0"}, msg.Channel.ConnectionHops)
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("uatom", 10000000))
+	p, err := cc.NewGovProposal([]sdk.Msg{msg}, deposit, "Open channel", "Open an ICA host channel.")
+	require.NoError(t, err)
+	require.Equal(t, "10000000uatom", p.Deposit)
+	require.Len(t, p.Messages, 1)
+
+	// the messages are encoded as Anys, as expected by `tx gov submit-proposal`.
+	var encoded struct {
+		Type   string `json:"@type"`
+		PortID string `json:"port_id"`
+		Signer string `json:"signer"`
+	}
+	require.NoError(t, json.Unmarshal(p.Messages[0], &encoded))
+	require.Equal(t, "/ibc.core.channel.v1.MsgChannelOpenInit", encoded.Type)
+	require.Equal(t, "icahost", encoded.PortID)
+	require.Equal(t, authority, encoded.Signer)
+}
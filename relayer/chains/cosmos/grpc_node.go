@@ -0,0 +1,484 @@
+package cosmos
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	provtypes "github.com/cometbft/cometbft/light/provider"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// nodeClient is the subset of the CometBFT RPC client used by the CosmosProvider to read blocks and txs of the
+// chain and to broadcast txs. It is implemented by the RPC client, and by grpcNode for chains with grpc-only set.
+type nodeClient interface {
+	Status(ctx context.Context) (*coretypes.ResultStatus, error)
+	Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error)
+	BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error)
+	Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error)
+	Validators(ctx context.Context, height *int64, page, perPage *int) (*coretypes.ResultValidators, error)
+	ABCIQuery(ctx context.Context, path string, data cmtbytes.HexBytes) (*coretypes.ResultABCIQuery, error)
+	ABCIQueryWithOptions(ctx context.Context, path string, data cmtbytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error)
+	BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error)
+	BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error)
+	Tx(ctx context.Context, hash []byte, prove bool) (*coretypes.ResultTx, error)
+	TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error)
+	BlockSearch(ctx context.Context, query string, page, perPage *int, orderBy string) (*coretypes.ResultBlockSearch, error)
+}
+
+// node returns the client of the node of the chain, which is the gRPC server if grpc-only is set, or else the RPC.
+func (cc *CosmosProvider) node() nodeClient {
+	if cc.PCfg.GRPCOnly {
+		return grpcNode{cc: cc}
+	}
+	return cc.RPCClient
+}
+
+var (
+	_ nodeClient         = grpcNode{}
+	_ provtypes.Provider = grpcNode{}
+)
+
+// errGRPCOnly is returned for requests which require the CometBFT RPC of a chain with grpc-only set.
+var errGRPCOnly = errors.New("not supported over gRPC, requires the rpc-addr of the chain without grpc-only")
+
+// txsEventPageLimit is the number of txs requested per page from the GetTxsEvent endpoint.
+const txsEventPageLimit = 100
+
+// validatorSetPageLimit is the number of validators requested per page from the GetValidatorSetByHeight endpoint.
+// The endpoint serves page offset/limit+1 and CometBFT caps a page at 100 validators, so the offset advances by
+// this limit rather than by the validators received.
+const validatorSetPageLimit = 100
+
+// grpcNode serves the blocks and txs of the chain from the CometBFT and tx services of the gRPC server of the node,
+// for nodes which do not expose the CometBFT RPC, e.g. those of managed node services.
+//
+// The commit of a block is only available from the next block, so the latest block served by grpcNode is the one
+// before the latest block of the node. The events of a block are the events of its txs, which are queried from the
+// tx index of the node: events emitted outside of txs, e.g. at the beginning or the end of a block, are not
+// available, and are only picked up by flushes.
+type grpcNode struct {
+	cc *CosmosProvider
+}
+
+func (n grpcNode) service() cmtservice.ServiceClient {
+	// queries through the provider are sent to the gRPC server, as grpc-only requires grpc-addr.
+	return cmtservice.NewServiceClient(n.cc)
+}
+
+// ChainID implements provtypes.Provider.
+func (n grpcNode) ChainID() string {
+	return n.cc.PCfg.ChainID
+}
+
+// LightBlock implements provtypes.Provider. It returns the signed header and the validator set of the block at
+// height, or of the latest block with a commit if height is 0.
+func (n grpcNode) LightBlock(ctx context.Context, height int64) (*tmtypes.LightBlock, error) {
+	commit, err := n.Commit(ctx, &height)
+	if err != nil {
+		return nil, err
+	}
+	height = commit.Header.Height
+
+	vals, err := n.validatorSet(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	if _, proposer := vals.GetByAddress(commit.Header.ProposerAddress); proposer != nil {
+		vals.Proposer = proposer
+	}
+	if !bytes.Equal(vals.Hash(), commit.Header.ValidatorsHash) {
+		return nil, fmt.Errorf("validator set of block %d does not match its header", height)
+	}
+
+	lb := &tmtypes.LightBlock{SignedHeader: &commit.SignedHeader, ValidatorSet: vals}
+	if err := lb.ValidateBasic(n.ChainID()); err != nil {
+		return nil, fmt.Errorf("invalid light block %d: %w", height, err)
+	}
+	return lb, nil
+}
+
+// ReportEvidence implements provtypes.Provider.
+func (n grpcNode) ReportEvidence(context.Context, tmtypes.Evidence) error {
+	return fmt.Errorf("report evidence: %w", errGRPCOnly)
+}
+
+// latestHeight returns the height of the latest block with a commit, which is the one before the latest block.
+func (n grpcNode) latestHeight(ctx context.Context) (int64, error) {
+	res, err := n.service().GetLatestBlock(ctx, &cmtservice.GetLatestBlockRequest{})
+	if err != nil {
+		return 0, err
+	}
+	if res.Block == nil {
+		return 0, errors.New("no latest block")
+	}
+	return res.Block.Header.Height - 1, nil
+}
+
+// height returns *height, or the height of the latest block with a commit if height is nil or 0.
+func (n grpcNode) height(ctx context.Context, height *int64) (int64, error) {
+	if height != nil && *height > 0 {
+		return *height, nil
+	}
+	return n.latestHeight(ctx)
+}
+
+func (n grpcNode) block(ctx context.Context, height int64) (*tmtypes.Block, *tmtypes.BlockID, error) {
+	res, err := n.service().GetBlockByHeight(ctx, &cmtservice.GetBlockByHeightRequest{Height: height})
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.Block == nil || res.BlockId == nil {
+		return nil, nil, fmt.Errorf("no block %d", height)
+	}
+	block, err := tmtypes.BlockFromProto(res.Block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid block %d: %w", height, err)
+	}
+	blockID, err := tmtypes.BlockIDFromProto(res.BlockId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid block id of block %d: %w", height, err)
+	}
+	return block, blockID, nil
+}
+
+func (n grpcNode) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	height, err := n.latestHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	block, blockID, err := n.block(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	syncing, err := n.service().GetSyncing(ctx, &cmtservice.GetSyncingRequest{})
+	if err != nil {
+		return nil, err
+	}
+	nodeInfo, err := n.service().GetNodeInfo(ctx, &cmtservice.GetNodeInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &coretypes.ResultStatus{
+		SyncInfo: coretypes.SyncInfo{
+			LatestBlockHash:   blockID.Hash,
+			LatestAppHash:     block.AppHash,
+			LatestBlockHeight: height,
+			LatestBlockTime:   block.Time,
+			CatchingUp:        syncing.Syncing,
+		},
+	}
+	if ni := nodeInfo.DefaultNodeInfo; ni != nil {
+		status.NodeInfo = p2p.DefaultNodeInfo{
+			DefaultNodeID: p2p.ID(ni.DefaultNodeID),
+			ListenAddr:    ni.ListenAddr,
+			Network:       ni.Network,
+			Version:       ni.Version,
+			Moniker:       ni.Moniker,
+		}
+	}
+	return status, nil
+}
+
+func (n grpcNode) Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error) {
+	h, err := n.height(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	block, blockID, err := n.block(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultBlock{BlockID: *blockID, Block: block}, nil
+}
+
+// BlockResults returns the results of the txs of the block at height, from the tx index of the node.
+// The FinalizeBlockEvents of the block are not available over gRPC, so they are left empty.
+func (n grpcNode) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	h, err := n.height(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := n.txsEvent(ctx, fmt.Sprintf("tx.height=%d", h), 0, 0, tx.OrderBy_ORDER_BY_ASC)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*abci.ExecTxResult, len(txs))
+	for i, t := range txs {
+		results[i] = &t.TxResult
+	}
+	return &coretypes.ResultBlockResults{Height: h, TxsResults: results}, nil
+}
+
+// Commit returns the signed header of the block at height, whose commit is the last commit of the next block.
+func (n grpcNode) Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error) {
+	h, err := n.height(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	block, _, err := n.block(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	next, _, err := n.block(ctx, h+1)
+	if err != nil {
+		return nil, fmt.Errorf("commit of block %d not available yet: %w", h, err)
+	}
+	return &coretypes.ResultCommit{
+		SignedHeader:    tmtypes.SignedHeader{Header: &block.Header, Commit: next.LastCommit},
+		CanonicalCommit: true,
+	}, nil
+}
+
+// Validators returns the whole validator set at height, regardless of page and perPage.
+func (n grpcNode) Validators(ctx context.Context, height *int64, _, _ *int) (*coretypes.ResultValidators, error) {
+	h, err := n.height(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := n.validatorSet(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultValidators{
+		BlockHeight: h,
+		Validators:  vals.Validators,
+		Count:       len(vals.Validators),
+		Total:       len(vals.Validators),
+	}, nil
+}
+
+// validatorSet returns the validator set at height, in the order of the set and with the priorities of the
+// validators, so that its hash matches the validators hash of the header at height.
+func (n grpcNode) validatorSet(ctx context.Context, height int64) (*tmtypes.ValidatorSet, error) {
+	var vals []*tmtypes.Validator
+	for offset := uint64(0); ; offset += validatorSetPageLimit {
+		res, err := n.service().GetValidatorSetByHeight(ctx, &cmtservice.GetValidatorSetByHeightRequest{
+			Height:     height,
+			Pagination: &query.PageRequest{Offset: offset, Limit: validatorSetPageLimit},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range res.Validators {
+			var pk cryptotypes.PubKey
+			if err := n.cc.Cdc.InterfaceRegistry.UnpackAny(v.PubKey, &pk); err != nil {
+				return nil, fmt.Errorf("invalid pubkey of validator %s: %w", v.Address, err)
+			}
+			tmPk, err := cryptocodec.ToCmtPubKeyInterface(pk)
+			if err != nil {
+				return nil, err
+			}
+			val := tmtypes.NewValidator(tmPk, v.VotingPower)
+			val.ProposerPriority = v.ProposerPriority
+			vals = append(vals, val)
+		}
+		if len(res.Validators) < validatorSetPageLimit || res.Pagination == nil || uint64(len(vals)) >= res.Pagination.Total {
+			break
+		}
+	}
+	if len(vals) == 0 {
+		return nil, fmt.Errorf("no validators at height %d", height)
+	}
+	return &tmtypes.ValidatorSet{Validators: vals}, nil
+}
+
+func (n grpcNode) ABCIQuery(ctx context.Context, path string, data cmtbytes.HexBytes) (*coretypes.ResultABCIQuery, error) {
+	return n.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
+}
+
+// ABCIQueryWithOptions queries the application of the node directly, with proofs if requested.
+func (n grpcNode) ABCIQueryWithOptions(
+	ctx context.Context,
+	path string,
+	data cmtbytes.HexBytes,
+	opts rpcclient.ABCIQueryOptions,
+) (*coretypes.ResultABCIQuery, error) {
+	res, err := n.service().ABCIQuery(ctx, &cmtservice.ABCIQueryRequest{
+		Data:   data,
+		Path:   path,
+		Height: opts.Height,
+		Prove:  opts.Prove,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := abci.ResponseQuery{
+		Code:      res.Code,
+		Log:       res.Log,
+		Info:      res.Info,
+		Index:     res.Index,
+		Key:       res.Key,
+		Value:     res.Value,
+		Height:    res.Height,
+		Codespace: res.Codespace,
+	}
+	if res.ProofOps != nil {
+		ops := make([]crypto.ProofOp, len(res.ProofOps.Ops))
+		for i, op := range res.ProofOps.Ops {
+			ops[i] = crypto.ProofOp{Type: op.Type, Key: op.Key, Data: op.Data}
+		}
+		resp.ProofOps = &crypto.ProofOps{Ops: ops}
+	}
+	return &coretypes.ResultABCIQuery{Response: resp}, nil
+}
+
+func (n grpcNode) BroadcastTxAsync(ctx context.Context, t tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	return n.broadcastTx(ctx, t, tx.BroadcastMode_BROADCAST_MODE_ASYNC)
+}
+
+func (n grpcNode) BroadcastTxSync(ctx context.Context, t tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	return n.broadcastTx(ctx, t, tx.BroadcastMode_BROADCAST_MODE_SYNC)
+}
+
+func (n grpcNode) broadcastTx(ctx context.Context, t tmtypes.Tx, mode tx.BroadcastMode) (*coretypes.ResultBroadcastTx, error) {
+	// the provider sends broadcasts to the RPC, so they are sent to the gRPC server directly.
+	res, err := tx.NewServiceClient(n.cc.GRPCConn).BroadcastTx(ctx, &tx.BroadcastTxRequest{TxBytes: t, Mode: mode})
+	if err != nil {
+		return nil, err
+	}
+	if res.TxResponse == nil {
+		return nil, errors.New("empty broadcast response")
+	}
+	data, err := hex.DecodeString(res.TxResponse.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data of broadcast response: %w", err)
+	}
+	return &coretypes.ResultBroadcastTx{
+		Code:      res.TxResponse.Code,
+		Data:      data,
+		Log:       res.TxResponse.RawLog,
+		Codespace: res.TxResponse.Codespace,
+		Hash:      t.Hash(),
+	}, nil
+}
+
+func (n grpcNode) Tx(ctx context.Context, hash []byte, _ bool) (*coretypes.ResultTx, error) {
+	res, err := tx.NewServiceClient(n.cc).GetTx(ctx, &tx.GetTxRequest{Hash: hex.EncodeToString(hash)})
+	if err != nil {
+		return nil, err
+	}
+	return resultTx(res.Tx, res.TxResponse)
+}
+
+// TxSearch searches the tx index of the node with GetTxsEvent. Proofs of the txs are not available.
+func (n grpcNode) TxSearch(
+	ctx context.Context,
+	query string,
+	_ bool,
+	page, perPage *int,
+	orderBy string,
+) (*coretypes.ResultTxSearch, error) {
+	order := tx.OrderBy_ORDER_BY_ASC
+	if orderBy == "desc" {
+		order = tx.OrderBy_ORDER_BY_DESC
+	}
+	var p, limit uint64
+	if page != nil && perPage != nil {
+		p, limit = uint64(*page), uint64(*perPage)
+	}
+	txs, err := n.txsEvent(ctx, query, p, limit, order)
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultTxSearch{Txs: txs, TotalCount: len(txs)}, nil
+}
+
+// BlockSearch is not supported over gRPC, since blocks are not indexed by their events.
+func (n grpcNode) BlockSearch(context.Context, string, *int, *int, string) (*coretypes.ResultBlockSearch, error) {
+	return nil, fmt.Errorf("block search: %w", errGRPCOnly)
+}
+
+// txsEvent returns the txs matching query, from page of limit txs, or all of them if limit is 0.
+func (n grpcNode) txsEvent(ctx context.Context, query string, page, limit uint64, order tx.OrderBy) ([]*coretypes.ResultTx, error) {
+	all := limit == 0
+	if all {
+		page, limit = 1, txsEventPageLimit
+	}
+
+	var txs []*coretypes.ResultTx
+	for {
+		res, err := tx.NewServiceClient(n.cc).GetTxsEvent(ctx, &tx.GetTxsEventRequest{
+			Query:   query,
+			OrderBy: order,
+			Page:    page,
+			Limit:   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Txs) != len(res.TxResponses) {
+			return nil, fmt.Errorf("got %d txs for %d tx responses", len(res.Txs), len(res.TxResponses))
+		}
+		for i, t := range res.Txs {
+			resTx, err := resultTx(t, res.TxResponses[i])
+			if err != nil {
+				return nil, err
+			}
+			txs = append(txs, resTx)
+		}
+		if !all || len(res.Txs) == 0 || uint64(len(txs)) >= res.Total {
+			return txs, nil
+		}
+		page++
+	}
+}
+
+// resultTx converts the tx t and its response from the tx service to the result of the tx from the RPC.
+func resultTx(t *tx.Tx, res *sdk.TxResponse) (*coretypes.ResultTx, error) {
+	if t == nil || res == nil {
+		return nil, errors.New("empty tx response")
+	}
+	hash, err := hex.DecodeString(res.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx hash %s: %w", res.TxHash, err)
+	}
+	data, err := hex.DecodeString(res.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data of tx %s: %w", res.TxHash, err)
+	}
+	bodyBytes, err := proto.Marshal(t.Body)
+	if err != nil {
+		return nil, err
+	}
+	authInfoBytes, err := proto.Marshal(t.AuthInfo)
+	if err != nil {
+		return nil, err
+	}
+	txBytes, err := proto.Marshal(&tx.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes, Signatures: t.Signatures})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultTx{
+		Hash:   hash,
+		Height: res.Height,
+		TxResult: abci.ExecTxResult{
+			Code:      res.Code,
+			Data:      data,
+			Log:       res.RawLog,
+			Info:      res.Info,
+			GasWanted: res.GasWanted,
+			GasUsed:   res.GasUsed,
+			Events:    res.Events,
+			Codespace: res.Codespace,
+		},
+		Tx: txBytes,
+	}, nil
+}
@@ -0,0 +1,260 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const grpcNodeTestChainID = "grpc-node-1"
+
+// mockCmtService serves a validator set and the blocks of a chain, the latest of which commits the one before.
+type mockCmtService struct {
+	cmtservice.UnimplementedServiceServer
+
+	vals   *tmtypes.ValidatorSet
+	blocks map[int64]*tmtypes.Block
+	latest int64
+
+	// valsPages counts the pages of validators served.
+	valsPages int
+}
+
+func (s *mockCmtService) blockResponse(height int64) (*cmtservice.GetBlockByHeightResponse, error) {
+	block, ok := s.blocks[height]
+	if !ok {
+		return nil, fmt.Errorf("no block %d", height)
+	}
+	ps, err := block.MakePartSet(tmtypes.BlockPartSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	blockID := tmtypes.BlockID{Hash: block.Hash(), PartSetHeader: ps.Header()}
+	pbID := blockID.ToProto()
+	pb, err := block.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	return &cmtservice.GetBlockByHeightResponse{BlockId: &pbID, Block: pb}, nil
+}
+
+func (s *mockCmtService) GetLatestBlock(context.Context, *cmtservice.GetLatestBlockRequest) (*cmtservice.GetLatestBlockResponse, error) {
+	res, err := s.blockResponse(s.latest)
+	if err != nil {
+		return nil, err
+	}
+	return &cmtservice.GetLatestBlockResponse{BlockId: res.BlockId, Block: res.Block}, nil
+}
+
+func (s *mockCmtService) GetBlockByHeight(_ context.Context, req *cmtservice.GetBlockByHeightRequest) (*cmtservice.GetBlockByHeightResponse, error) {
+	return s.blockResponse(req.Height)
+}
+
+func (s *mockCmtService) GetSyncing(context.Context, *cmtservice.GetSyncingRequest) (*cmtservice.GetSyncingResponse, error) {
+	return &cmtservice.GetSyncingResponse{}, nil
+}
+
+func (s *mockCmtService) GetNodeInfo(context.Context, *cmtservice.GetNodeInfoRequest) (*cmtservice.GetNodeInfoResponse, error) {
+	return &cmtservice.GetNodeInfoResponse{}, nil
+}
+
+func (s *mockCmtService) GetValidatorSetByHeight(_ context.Context, req *cmtservice.GetValidatorSetByHeightRequest) (*cmtservice.GetValidatorSetByHeightResponse, error) {
+	// serve pages like the SDK does: it requests page offset/limit+1 from CometBFT, which caps a page at 100
+	// validators.
+	limit := req.Pagination.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	page := req.Pagination.Offset/limit + 1
+	perPage := min(limit, 100)
+	start := int((page - 1) * perPage)
+	end := start + int(perPage)
+	if end > len(s.vals.Validators) {
+		end = len(s.vals.Validators)
+	}
+	s.valsPages++
+
+	res := &cmtservice.GetValidatorSetByHeightResponse{
+		BlockHeight: req.Height,
+		Pagination:  &query.PageResponse{Total: uint64(len(s.vals.Validators))},
+	}
+	for i := start; i < end; i++ {
+		v := s.vals.Validators[i]
+		pk, err := cryptocodec.FromCmtPubKeyInterface(v.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		anyPk, err := codectypes.NewAnyWithValue(pk)
+		if err != nil {
+			return nil, err
+		}
+		res.Validators = append(res.Validators, &cmtservice.Validator{
+			Address:          sdk.ConsAddress(v.Address).String(),
+			PubKey:           anyPk,
+			VotingPower:      v.VotingPower,
+			ProposerPriority: v.ProposerPriority,
+		})
+	}
+	return res, nil
+}
+
+// mockTxService serves numTxs txs at every height, with the tx index as the sequence of a send_packet event.
+type mockTxService struct {
+	tx.UnimplementedServiceServer
+
+	numTxs int
+}
+
+func (s *mockTxService) GetTxsEvent(_ context.Context, req *tx.GetTxsEventRequest) (*tx.GetTxsEventResponse, error) {
+	var height int64
+	if _, err := fmt.Sscanf(req.Query, "tx.height=%d", &height); err != nil {
+		return nil, err
+	}
+	res := &tx.GetTxsEventResponse{Total: uint64(s.numTxs)}
+	for i := int((req.Page - 1) * req.Limit); i < s.numTxs && len(res.Txs) < int(req.Limit); i++ {
+		res.Txs = append(res.Txs, &tx.Tx{Body: &tx.TxBody{Memo: fmt.Sprint(i)}, AuthInfo: &tx.AuthInfo{}})
+		res.TxResponses = append(res.TxResponses, &sdk.TxResponse{
+			Height: height,
+			TxHash: strings.Repeat("AB", 32),
+			Events: []abci.Event{{
+				Type:       "send_packet",
+				Attributes: []abci.EventAttribute{{Key: "packet_sequence", Value: fmt.Sprint(i)}},
+			}},
+		})
+	}
+	return res, nil
+}
+
+func newGRPCNodeTestProvider(t *testing.T, numVals int) (*CosmosProvider, *mockCmtService) {
+	vals, privVals := tmtypes.RandValidatorSet(numVals, 10)
+	now := time.Now().UTC()
+
+	block := tmtypes.MakeBlock(5, nil, &tmtypes.Commit{}, nil)
+	block.ChainID = grpcNodeTestChainID
+	block.Time = now
+	block.ValidatorsHash = vals.Hash()
+	block.NextValidatorsHash = vals.Hash()
+	block.ProposerAddress = vals.GetProposer().Address
+	ps, err := block.MakePartSet(tmtypes.BlockPartSizeBytes)
+	require.NoError(t, err)
+	blockID := tmtypes.BlockID{Hash: block.Hash(), PartSetHeader: ps.Header()}
+
+	voteSet := tmtypes.NewVoteSet(grpcNodeTestChainID, 5, 0, cmtproto.PrecommitType, vals)
+	extCommit, err := tmtypes.MakeExtCommit(blockID, 5, 0, voteSet, privVals, now, false)
+	require.NoError(t, err)
+
+	next := tmtypes.MakeBlock(6, nil, extCommit.ToCommit(), nil)
+	next.ChainID = grpcNodeTestChainID
+	next.Time = now.Add(time.Second)
+	next.ProposerAddress = vals.GetProposer().Address
+
+	cmtSrv := &mockCmtService{vals: vals, blocks: map[int64]*tmtypes.Block{5: block, 6: next}, latest: 6}
+
+	cc := &CosmosProvider{
+		PCfg: CosmosProviderConfig{ChainID: grpcNodeTestChainID, AccountPrefix: "cosmos", GRPCOnly: true},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(grpc.ForceServerCodec(codec.NewProtoCodec(cc.Cdc.InterfaceRegistry).GRPCCodec()))
+	cmtservice.RegisterServiceServer(srv, cmtSrv)
+	tx.RegisterServiceServer(srv, &mockTxService{numTxs: txsEventPageLimit + 1})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := newGRPCConn("passthrough:///bufnet", cc.Cdc.InterfaceRegistry, nil,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	require.NoError(t, err)
+	cc.GRPCConn = conn
+	t.Cleanup(func() { _ = cc.Close() })
+
+	return cc, cmtSrv
+}
+
+func TestGRPCNodeLightBlock(t *testing.T) {
+	cc, cmtSrv := newGRPCNodeTestProvider(t, 3)
+	ctx := context.Background()
+
+	// the latest block of the node has no commit yet, so the latest height is the one before.
+	latest, err := cc.QueryLatestHeight(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), latest)
+
+	lb, err := grpcNode{cc: cc}.LightBlock(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), lb.Height)
+	require.Equal(t, cmtSrv.vals.Hash(), lb.ValidatorSet.Hash())
+	require.NoError(t, cmtSrv.vals.VerifyCommitLight(grpcNodeTestChainID, lb.Commit.BlockID, 5, lb.Commit))
+
+	// the commit of the latest block of the node is not available.
+	_, err = grpcNode{cc: cc}.LightBlock(ctx, 6)
+	require.Error(t, err)
+
+	// a validator set which does not match the header is rejected.
+	cmtSrv.vals, _ = tmtypes.RandValidatorSet(3, 10)
+	_, err = grpcNode{cc: cc}.LightBlock(ctx, 5)
+	require.ErrorContains(t, err, "does not match")
+}
+
+func TestGRPCNodeValidatorSetPages(t *testing.T) {
+	// more validators than fit on a page, so that the set spans two pages.
+	cc, cmtSrv := newGRPCNodeTestProvider(t, validatorSetPageLimit+50)
+
+	lb, err := grpcNode{cc: cc}.LightBlock(context.Background(), 5)
+	require.NoError(t, err)
+	require.Len(t, lb.ValidatorSet.Validators, validatorSetPageLimit+50)
+	require.Equal(t, cmtSrv.vals.Hash(), lb.ValidatorSet.Hash())
+	require.Equal(t, 2, cmtSrv.valsPages)
+}
+
+func TestGRPCNodeBlockResults(t *testing.T) {
+	cc, _ := newGRPCNodeTestProvider(t, 3)
+
+	height := int64(5)
+	res, err := cc.node().BlockResults(context.Background(), &height)
+	require.NoError(t, err)
+	require.Equal(t, height, res.Height)
+	require.Empty(t, res.FinalizeBlockEvents)
+
+	// the txs of the block span two pages.
+	require.Len(t, res.TxsResults, txsEventPageLimit+1)
+	for i, txRes := range res.TxsResults {
+		require.Equal(t, "send_packet", txRes.Events[0].Type)
+		require.Equal(t, fmt.Sprint(i), txRes.Events[0].Attributes[0].Value)
+	}
+
+	// the tx of a result is decoded by the tx decoder of the provider.
+	txs, err := cc.node().TxSearch(context.Background(), "tx.height=5", false, nil, nil, "")
+	require.NoError(t, err)
+	decoded, err := cc.Cdc.TxConfig.TxDecoder()(txs.Txs[1].Tx)
+	require.NoError(t, err)
+	require.Equal(t, "1", decoded.(sdk.TxWithMemo).GetMemo())
+}
+
+func TestGRPCOnlyRequiresGRPCAddr(t *testing.T) {
+	pc := CosmosProviderConfig{Timeout: "10s", GRPCOnly: true}
+	require.ErrorContains(t, pc.Validate(), "grpc-only requires grpc-addr")
+
+	pc.GRPCAddr = "127.0.0.1:9090"
+	require.NoError(t, pc.Validate())
+}
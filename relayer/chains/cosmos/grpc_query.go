@@ -2,14 +2,16 @@ package cosmos
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
-	"time"
 
 	sdkerrors "cosmossdk.io/errors"
 	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
@@ -19,6 +21,8 @@ import (
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/encoding/proto"
 	"google.golang.org/grpc/metadata"
@@ -59,6 +63,19 @@ func (cc *CosmosProvider) Invoke(ctx context.Context, method string, req, reply
 	}
 
 	// Case 2. Querying state.
+	if cc.GRPCConn != nil {
+		// query the chain's gRPC server directly rather than through abci_query.
+		if err := cc.GRPCConn.Invoke(ctx, method, req, reply, opts...); err != nil {
+			return err
+		}
+
+		if cc.Cdc.InterfaceRegistry != nil {
+			return types.UnpackInterfaces(reply, cc.Cdc.Marshaler)
+		}
+
+		return nil
+	}
+
 	inMd, _ := metadata.FromOutgoingContext(ctx)
 	abciRes, outMd, err := cc.RunGRPCQuery(ctx, method, req, inMd)
 	if err != nil {
@@ -85,6 +102,42 @@ func (cc *CosmosProvider) Invoke(ctx context.Context, method string, req, reply
 	return nil
 }
 
+// newGRPCConn dials the gRPC server at addr, using TLS if addr has an https:// scheme, with tlsConfig if not nil.
+func newGRPCConn(addr string, interfaceRegistry types.InterfaceRegistry, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if strings.HasPrefix(addr, "https://") {
+		addr = strings.TrimPrefix(addr, "https://")
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	} else {
+		addr = strings.TrimPrefix(addr, "http://")
+	}
+
+	conn, err := grpc.Dial(
+		addr,
+		append([]grpc.DialOption{
+			grpc.WithTransportCredentials(creds),
+			grpc.WithDefaultCallOptions(grpc.ForceCodec(codec.NewProtoCodec(interfaceRegistry).GRPCCodec())),
+		}, opts...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial grpc address %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// Close closes the connection to the gRPC server of the chain, if any.
+func (cc *CosmosProvider) Close() error {
+	if cc.GRPCConn == nil {
+		return nil
+	}
+	err := cc.GRPCConn.Close()
+	cc.GRPCConn = nil
+	return err
+}
+
 // NewStream implements the grpc ClientConn.NewStream method
 func (cc *CosmosProvider) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
 	return nil, fmt.Errorf("streaming rpc not supported")
@@ -154,22 +207,13 @@ func (cc *CosmosProvider) TxServiceBroadcast(ctx context.Context, req *tx.Broadc
 	}
 
 	var (
-		blockTimeout = defaultBroadcastWaitTimeout
+		blockTimeout = cc.PCfg.TxInclusionTimeout()
 		err          error
 		rlyResp      *provider.RelayerTxResponse
 		callbackErr  error
 		wg           sync.WaitGroup
 	)
 
-	if cc.PCfg.BlockTimeout != "" {
-		blockTimeout, err = time.ParseDuration(cc.PCfg.BlockTimeout)
-		if err != nil {
-			// Did you call Validate() method on CosmosProviderConfig struct
-			// before coming here?
-			return nil, err
-		}
-	}
-
 	callback := func(rtr *provider.RelayerTxResponse, err error) {
 		rlyResp = rtr
 		callbackErr = err
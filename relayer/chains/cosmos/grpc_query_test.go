@@ -0,0 +1,56 @@
+package cosmos
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockBankQueryServer struct {
+	banktypes.UnimplementedQueryServer
+}
+
+func (*mockBankQueryServer) Balance(_ context.Context, req *banktypes.QueryBalanceRequest) (*banktypes.QueryBalanceResponse, error) {
+	coin := sdk.NewInt64Coin(req.Denom, 42)
+	return &banktypes.QueryBalanceResponse{Balance: &coin}, nil
+}
+
+func TestGRPCQuery(t *testing.T) {
+	cc := &CosmosProvider{
+		PCfg: CosmosProviderConfig{AccountPrefix: "cosmos"},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(grpc.ForceServerCodec(codec.NewProtoCodec(cc.Cdc.InterfaceRegistry).GRPCCodec()))
+	banktypes.RegisterQueryServer(srv, &mockBankQueryServer{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := newGRPCConn("passthrough:///bufnet", cc.Cdc.InterfaceRegistry, nil,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	require.NoError(t, err)
+	cc.GRPCConn = conn
+
+	// state queries are sent to the gRPC server rather than through abci_query.
+	res, err := banktypes.NewQueryClient(cc).Balance(context.Background(), &banktypes.QueryBalanceRequest{
+		Address: "cosmos10d07y265gmmuvt4z0w9aw880jnsr700j6zn9kn",
+		Denom:   "uatom",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "42uatom", res.Balance.String())
+
+	require.NoError(t, cc.Close())
+	require.Nil(t, cc.GRPCConn)
+	require.NoError(t, cc.Close())
+}
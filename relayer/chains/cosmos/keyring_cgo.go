@@ -0,0 +1,6 @@
+//go:build cgo
+
+package cosmos
+
+// cgoEnabled is whether the binary is built with cgo, which the keychain of macOS requires.
+const cgoEnabled = true
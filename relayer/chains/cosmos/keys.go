@@ -2,11 +2,13 @@ package cosmos
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"runtime"
+	"slices"
 
 	"github.com/cosmos/relayer/v2/relayer/provider"
 
-	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -40,8 +42,21 @@ func KeyringAlgoOptions() keyring.Option {
 	}
 }
 
+// checkKeyringBackend returns an error if the keyring backend is not available in this binary.
+// The os backend of macOS is the keychain, which is not available without cgo, e.g. in static builds.
+func checkKeyringBackend(backend string) error {
+	if backend == keyring.BackendOS && runtime.GOOS == "darwin" && !cgoEnabled {
+		return fmt.Errorf("keyring-backend %s is not available in binaries built without cgo on macOS, use %s instead",
+			backend, keyring.BackendFile)
+	}
+	return nil
+}
+
 // CreateKeystore initializes a new instance of a keyring at the specified path in the local filesystem.
 func (cc *CosmosProvider) CreateKeystore(path string) error {
+	if err := checkKeyringBackend(cc.PCfg.KeyringBackend); err != nil {
+		return err
+	}
 	keybase, err := keyring.New(cc.PCfg.ChainID, cc.PCfg.KeyringBackend, cc.PCfg.KeyDirectory, cc.Input, cc.Cdc.Marshaler, KeyringAlgoOptions())
 	if err != nil {
 		return err
@@ -93,12 +108,9 @@ func (cc *CosmosProvider) KeyAddOrRestore(keyName string, coinType uint32, signi
 	var mnemonicStr string
 	var err error
 
-	var algo keyring.SignatureAlgo
-	switch signingAlgorithm {
-	case string(hd.Sr25519Type):
-		algo = sr25519.Sr25519
-	default:
-		algo = hd.Secp256k1
+	algo, err := cc.signatureAlgo(signingAlgorithm, coinType)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(mnemonic) > 0 {
@@ -110,15 +122,6 @@ func (cc *CosmosProvider) KeyAddOrRestore(keyName string, coinType uint32, signi
 		}
 	}
 
-	if coinType == ethereumCoinType {
-		algo = keyring.SignatureAlgo(ethermint.EthSecp256k1)
-		for _, codec := range cc.PCfg.ExtraCodecs {
-			if codec == "injective" {
-				algo = keyring.SignatureAlgo(injective.EthSecp256k1)
-			}
-		}
-	}
-
 	done := SetSDKConfigContext(cc.PCfg.AccountPrefix)
 
 	info, err := cc.Keybase.NewAccount(keyName, mnemonicStr, "", hd.CreateHDPath(coinType, 0, 0).String(), algo)
@@ -140,6 +143,47 @@ func (cc *CosmosProvider) KeyAddOrRestore(keyName string, coinType uint32, signi
 	return &provider.KeyOutput{Mnemonic: mnemonicStr, Address: out}, nil
 }
 
+// signatureAlgo returns the algorithm of keys created with signingAlgorithm, or the signing-algorithm of the chain
+// if empty. Only if neither is set is the algorithm derived from the coin type: keys of the ethereum coin type
+// are eth_secp256k1 keys, and keys of any other coin type are secp256k1 keys.
+func (cc *CosmosProvider) signatureAlgo(signingAlgorithm string, coinType uint32) (keyring.SignatureAlgo, error) {
+	if signingAlgorithm == "" {
+		signingAlgorithm = cc.PCfg.SigningAlgorithm
+	}
+	if signingAlgorithm == "" && coinType == ethereumCoinType {
+		signingAlgorithm = string(ethermint.EthSecp256k1Type)
+	}
+	switch signingAlgorithm {
+	case "", string(hd.Secp256k1Type):
+		return hd.Secp256k1, nil
+	case string(hd.Sr25519Type):
+		return sr25519.Sr25519, nil
+	case string(ethermint.EthSecp256k1Type):
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", signingAlgorithm)
+	}
+
+	// the address of an eth_secp256k1 key is derived from the keccak hash of its public key,
+	// with the key type of the injective codec on injective chains.
+	if slices.Contains(cc.PCfg.ExtraCodecs, "injective") {
+		return injective.EthSecp256k1, nil
+	}
+	return ethermint.EthSecp256k1, nil
+}
+
+// DefaultCoinType returns the coin type of the HD path of keys created for the chain, configured with coin-type,
+// or the ethereum coin type for eth_secp256k1 keys, as used by Ethermint based chains.
+func (pc CosmosProviderConfig) DefaultCoinType() uint32 {
+	switch {
+	case pc.Slip44 != nil:
+		return uint32(*pc.Slip44)
+	case pc.SigningAlgorithm == string(ethermint.EthSecp256k1Type):
+		return ethereumCoinType
+	default:
+		return sdk.CoinType
+	}
+}
+
 // ShowAddress retrieves a key by name from the keystore and returns the bech32 encoded string representation of that key.
 func (cc *CosmosProvider) ShowAddress(name string) (address string, err error) {
 	info, err := cc.Keybase.Key(name)
@@ -194,10 +238,20 @@ func (cc *CosmosProvider) KeyExists(name string) bool {
 
 }
 
-// ExportPrivKeyArmor returns a private key in ASCII armored format.
-// It returns an error if the key does not exist or a wrong encryption passphrase is supplied.
-func (cc *CosmosProvider) ExportPrivKeyArmor(keyName string) (armor string, err error) {
-	return cc.Keybase.ExportPrivKeyArmor(keyName, ckeys.DefaultKeyPass)
+// ExportPrivKeyArmor returns a private key in ASCII armored format, encrypted with the passphrase.
+// It returns an error if the key does not exist.
+func (cc *CosmosProvider) ExportPrivKeyArmor(keyName, passphrase string) (armor string, err error) {
+	return cc.Keybase.ExportPrivKeyArmor(keyName, passphrase)
+}
+
+// ImportPrivKeyArmor imports a private key in ASCII armored format, encrypted with the passphrase, e.g. exported
+// from another keyring, into the keystore under the specified name and returns its address.
+// It returns an error if a key with the name already exists or a wrong passphrase is supplied.
+func (cc *CosmosProvider) ImportPrivKeyArmor(name, armor, passphrase string) (address string, err error) {
+	if err := cc.Keybase.ImportPrivKey(name, armor, passphrase); err != nil {
+		return "", err
+	}
+	return cc.ShowAddress(name)
 }
 
 // GetKeyAddress returns the account address representation for the currently configured key.
@@ -49,11 +49,11 @@ func TestKeyRestore(t *testing.T) {
 	require.Equal(t, expectedAddress, address)
 }
 
-// TestKeyRestoreEth restores a test mnemonic
+// TestKeyRestoreEth restores a test mnemonic, as an eth_secp256k1 key of the ethereum coin type
 func TestKeyRestoreEth(t *testing.T) {
 	const (
 		keyName            = "test_key"
-		signatureAlgorithm = "secp256k1"
+		signatureAlgorithm = ""
 		mnemonic           = "three elevator silk family street child flip also leaf inmate call frame shock little legal october vivid enable fetch siege sell burger dolphin green"
 		accountPrefix      = "evmos"
 		expectedAddress    = "evmos1dea7vlekr9e34vugwkvesulglt8fx4e457vk9z"
@@ -67,11 +67,11 @@ func TestKeyRestoreEth(t *testing.T) {
 	require.Equal(t, expectedAddress, address)
 }
 
-// TestKeyRestoreInj restores a test mnemonic
+// TestKeyRestoreInj restores a test mnemonic, as an eth_secp256k1 key of the ethereum coin type
 func TestKeyRestoreInj(t *testing.T) {
 	const (
 		keyName            = "inj_key"
-		signatureAlgorithm = "secp256k1"
+		signatureAlgorithm = ""
 		mnemonic           = "three elevator silk family street child flip also leaf inmate call frame shock little legal october vivid enable fetch siege sell burger dolphin green"
 		accountPrefix      = "inj"
 		expectedAddress    = "inj1dea7vlekr9e34vugwkvesulglt8fx4e4uk2udj"
@@ -102,3 +102,88 @@ func TestKeyRestoreSr25519(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedAddress, address)
 }
+
+// TestKeyRestoreEthSigningAlgorithm restores a test mnemonic on a chain configured with the eth_secp256k1
+// signing algorithm, without extra codecs.
+func TestKeyRestoreEthSigningAlgorithm(t *testing.T) {
+	const (
+		keyName         = "evmos_key"
+		mnemonic        = "three elevator silk family street child flip also leaf inmate call frame shock little legal october vivid enable fetch siege sell burger dolphin green"
+		expectedAddress = "evmos1dea7vlekr9e34vugwkvesulglt8fx4e457vk9z"
+	)
+
+	homePath := t.TempDir()
+	cfg := cosmos.CosmosProviderConfig{
+		ChainID:          "test",
+		KeyDirectory:     filepath.Join(homePath, "keys"),
+		KeyringBackend:   "test",
+		Timeout:          "10s",
+		AccountPrefix:    "evmos",
+		SigningAlgorithm: "eth_secp256k1",
+	}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, uint32(60), cfg.DefaultCoinType())
+
+	p, err := cfg.NewProvider(zap.NewNop(), homePath, true, "test_chain")
+	require.NoError(t, err)
+	require.NoError(t, p.CreateKeystore(homePath))
+
+	address, err := p.RestoreKey(keyName, mnemonic, cfg.DefaultCoinType(), "")
+	require.NoError(t, err)
+	require.Equal(t, expectedAddress, address)
+
+	// the key is decoded from the keystore with the ethermint codec.
+	address, err = p.ShowAddress(keyName)
+	require.NoError(t, err)
+	require.Equal(t, expectedAddress, address)
+
+	_, err = p.RestoreKey("other_key", mnemonic, cfg.DefaultCoinType(), "ed25519")
+	require.EqualError(t, err, `unsupported signing algorithm "ed25519"`)
+
+	cfg.SigningAlgorithm = "ed25519"
+	require.ErrorContains(t, cfg.Validate(), "invalid signing-algorithm: ed25519")
+}
+
+// TestKeyRestoreExplicitAlgorithm restores a test mnemonic of the ethereum coin type with an explicit signing
+// algorithm, which takes precedence over the coin type.
+func TestKeyRestoreExplicitAlgorithm(t *testing.T) {
+	const (
+		mnemonic = "three elevator silk family street child flip also leaf inmate call frame shock little legal october vivid enable fetch siege sell burger dolphin green"
+		coinType = uint32(60)
+	)
+
+	tests := []struct {
+		name               string
+		signatureAlgorithm string
+		chainAlgorithm     string
+		expectedAddress    string
+	}{
+		{"secp256k1", "secp256k1", "", "evmos1j57yt284w9zqwz6tkrvlrc3p76s8vzv96wgsrk"},
+		{"sr25519", "sr25519", "", "evmos1926xj2zryj3nkvk275vvrwakhmu53ltflez270"},
+		{"eth_secp256k1", "eth_secp256k1", "", "evmos1dea7vlekr9e34vugwkvesulglt8fx4e457vk9z"},
+		{"chain secp256k1", "", "secp256k1", "evmos1j57yt284w9zqwz6tkrvlrc3p76s8vzv96wgsrk"},
+		{"override chain eth_secp256k1", "secp256k1", "eth_secp256k1", "evmos1j57yt284w9zqwz6tkrvlrc3p76s8vzv96wgsrk"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			homePath := t.TempDir()
+			cfg := cosmos.CosmosProviderConfig{
+				ChainID:          "test",
+				KeyDirectory:     filepath.Join(homePath, "keys"),
+				KeyringBackend:   "test",
+				Timeout:          "10s",
+				AccountPrefix:    "evmos",
+				SigningAlgorithm: tt.chainAlgorithm,
+			}
+			p, err := cfg.NewProvider(zap.NewNop(), homePath, true, "test_chain")
+			require.NoError(t, err)
+			require.NoError(t, p.CreateKeystore(homePath))
+
+			address, err := p.RestoreKey("test_key", mnemonic, coinType, tt.signatureAlgorithm)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedAddress, address)
+		})
+	}
+}
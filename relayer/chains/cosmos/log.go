@@ -62,6 +62,8 @@ func (cc *CosmosProvider) LogFailedTx(res *provider.RelayerTxResponse, err error
 			return
 		}
 
+		cc.logTxPayload(fields, msgs)
+
 		// Make a copy since we may continue to the warning
 		errorFields := append(fields, zap.Error(err))
 		cc.log.Error(
@@ -75,6 +77,9 @@ func (cc *CosmosProvider) LogFailedTx(res *provider.RelayerTxResponse, err error
 	}
 
 	if res.Code != 0 {
+		if err == nil {
+			cc.logTxPayload(fields, msgs)
+		}
 		if sdkErr := cc.sdkError(res.Codespace, res.Code); err != nil {
 			fields = append(fields, zap.NamedError("sdk_error", sdkErr))
 		}
@@ -132,6 +137,26 @@ func (cc *CosmosProvider) LogSuccessTx(res *sdk.TxResponse, msgs []provider.Rela
 		"Successful transaction",
 		fields...,
 	)
+	cc.logTxPayload(fields, msgs)
+}
+
+// logTxPayload logs the messages of a transaction at debug level, which may be enabled at runtime for the chain.
+// The messages are only encoded if the log is written.
+func (cc *CosmosProvider) logTxPayload(fields []zapcore.Field, msgs []provider.RelayerMessage) {
+	payload := zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, m := range msgs {
+			if cm, ok := m.(CosmosMessage); ok {
+				if err := enc.AppendObject(cm); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	cc.log.Debug(
+		"Transaction payload",
+		append(fields[:len(fields):len(fields)], zap.Array("msgs", payload))...,
+	)
 }
 
 func msgTypesField(msgs []provider.RelayerMessage) zap.Field {
@@ -0,0 +1,172 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+const (
+	// mempoolWaitExtensions is how many times the wait for block inclusion of a tx is extended while the tx
+	// is still in the mempool of the node, before the tx is presumed lost and its messages are resent.
+	mempoolWaitExtensions = 3
+
+	// unconfirmedTxsLimit is the number of txs of the mempool of the node checked for a tx, which is the
+	// maximum returned by the unconfirmed_txs RPC endpoint.
+	unconfirmedTxsLimit = 100
+
+	// recentBlocksScanned is the maximum number of blocks scanned for a tx which is not in the tx index of the node.
+	recentBlocksScanned = 50
+)
+
+// recheckTx is called once the wait for block inclusion of the tx times out, before the tx is presumed lost.
+// It returns the result of the tx if it was included in a block since fromHeight, even if the tx index of the
+// node lags behind, or else whether the tx is still in the mempool of the node, in which case it is merely slow.
+func (cc *CosmosProvider) recheckTx(ctx context.Context, txHash []byte, fromHeight int64) (*coretypes.ResultTx, bool) {
+	if res, err := cc.node().Tx(ctx, txHash, false); err == nil {
+		return res, false
+	}
+
+	res, err := cc.findTxInBlocks(ctx, txHash, fromHeight)
+	if err != nil {
+		cc.log.Debug("Failed to scan recent blocks for tx", zap.String("tx_hash", fmt.Sprintf("%X", txHash)), zap.Error(err))
+	} else if res != nil {
+		return res, false
+	}
+
+	inMempool, err := cc.txInMempool(ctx, txHash)
+	if err != nil {
+		cc.log.Debug("Failed to check mempool for tx", zap.String("tx_hash", fmt.Sprintf("%X", txHash)), zap.Error(err))
+	}
+	return nil, inMempool
+}
+
+// txInMempool returns true if the tx is among the first unconfirmedTxsLimit txs of the mempool of the node.
+func (cc *CosmosProvider) txInMempool(ctx context.Context, txHash []byte) (bool, error) {
+	if cc.PCfg.GRPCOnly {
+		return false, fmt.Errorf("mempool query: %w", errGRPCOnly)
+	}
+	timeout, _ := time.ParseDuration(cc.PCfg.Timeout) // Timeout is validated in the config so no error check
+	client, err := cc.PCfg.EndpointAuth.rpcClient(cc.RPCAddr(), timeout)
+	if err != nil {
+		return false, err
+	}
+	limit := unconfirmedTxsLimit
+	res, err := client.UnconfirmedTxs(ctx, &limit)
+	if err != nil {
+		return false, err
+	}
+	return tmtypes.Txs(res.Txs).IndexByHash(txHash) >= 0, nil
+}
+
+// findTxInBlocks scans the blocks from fromHeight up to the latest block, at most recentBlocksScanned of them,
+// for the tx, and returns its result if found, or nil.
+func (cc *CosmosProvider) findTxInBlocks(ctx context.Context, txHash []byte, fromHeight int64) (*coretypes.ResultTx, error) {
+	latest, err := cc.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for h := latest; h >= max(fromHeight, latest-recentBlocksScanned+1, 1); h-- {
+		block, err := cc.node().Block(ctx, &h)
+		if err != nil {
+			return nil, err
+		}
+		i := block.Block.Txs.IndexByHash(txHash)
+		if i < 0 {
+			continue
+		}
+		results, err := cc.node().BlockResults(ctx, &h)
+		if err != nil {
+			return nil, err
+		}
+		if i >= len(results.TxsResults) {
+			return nil, fmt.Errorf("no result for tx %d of block %d", i, h)
+		}
+		return &coretypes.ResultTx{
+			Hash:     txHash,
+			Height:   h,
+			Index:    uint32(i),
+			TxResult: *results.TxsResults[i],
+			Tx:       block.Block.Txs[i],
+		}, nil
+	}
+	return nil, nil
+}
+
+// packetsRelayed returns true if the msgs include packet messages and all of them were already relayed
+// on this chain, e.g. by another tx of another relayer, so that resending them would only waste fees.
+func (cc *CosmosProvider) packetsRelayed(ctx context.Context, msgs []provider.RelayerMessage) bool {
+	var packetMsgs int
+	for _, msg := range CosmosMsgs(msgs...) {
+		if _, ok := msg.(*clienttypes.MsgUpdateClient); ok {
+			continue
+		}
+		relayed, err := cc.packetRelayed(ctx, msg)
+		if err != nil {
+			cc.log.Debug("Failed to check whether packet was relayed", zap.String("msg_type", sdk.MsgTypeURL(msg)), zap.Error(err))
+			return false
+		}
+		if !relayed {
+			return false
+		}
+		packetMsgs++
+	}
+	return packetMsgs > 0
+}
+
+// packetRelayed returns true if the packet of the message was already received, or acknowledged or timed out,
+// on this chain. Messages which are not packet messages are never relayed.
+func (cc *CosmosProvider) packetRelayed(ctx context.Context, msg sdk.Msg) (bool, error) {
+	packet, recv := relayedPacket(msg)
+	if packet == nil {
+		return false, nil
+	}
+
+	if recv {
+		receipt, err := cc.QueryPacketReceipt(ctx, 0, packet.DestinationChannel, packet.DestinationPort, packet.Sequence)
+		if err != nil {
+			return false, err
+		}
+		if receipt.Received {
+			return true, nil
+		}
+		// packets of ordered channels are received in order, without receipts.
+		nextSeqRecv, err := cc.QueryNextSeqRecv(ctx, 0, packet.DestinationChannel, packet.DestinationPort)
+		if err != nil {
+			return false, err
+		}
+		return nextSeqRecv.NextSequenceReceive > packet.Sequence, nil
+	}
+
+	// the commitment of a packet is deleted once it is acknowledged or timed out.
+	_, err := cc.QueryPacketCommitment(ctx, 0, packet.SourceChannel, packet.SourcePort, packet.Sequence)
+	if errors.Is(err, chantypes.ErrPacketCommitmentNotFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+// relayedPacket returns the packet of a packet message, and whether the message receives the packet
+// rather than acknowledges or times it out, or nil if msg is not a packet message.
+func relayedPacket(msg sdk.Msg) (*chantypes.Packet, bool) {
+	switch m := msg.(type) {
+	case *chantypes.MsgRecvPacket:
+		return &m.Packet, true
+	case *chantypes.MsgAcknowledgement:
+		return &m.Packet, false
+	case *chantypes.MsgTimeout:
+		return &m.Packet, false
+	case *chantypes.MsgTimeoutOnClose:
+		return &m.Packet, false
+	}
+	return nil, false
+}
@@ -0,0 +1,28 @@
+package cosmos
+
+import (
+	"testing"
+
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayedPacket(t *testing.T) {
+	packet := chantypes.Packet{Sequence: 7, SourcePort: "transfer", SourceChannel: "channel-0"}
+
+	got, recv := relayedPacket(&chantypes.MsgRecvPacket{Packet: packet})
+	require.Equal(t, packet, *got)
+	require.True(t, recv)
+
+	got, recv = relayedPacket(&chantypes.MsgAcknowledgement{Packet: packet})
+	require.Equal(t, packet, *got)
+	require.False(t, recv)
+
+	got, recv = relayedPacket(&chantypes.MsgTimeout{Packet: packet})
+	require.Equal(t, packet, *got)
+	require.False(t, recv)
+
+	got, _ = relayedPacket(&bankTypes.MsgSend{})
+	require.Nil(t, got)
+}
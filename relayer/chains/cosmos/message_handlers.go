@@ -18,7 +18,7 @@ func (ccp *CosmosChainProcessor) handleMessage(ctx context.Context, m chains.Ibc
 	case *chains.PacketInfo:
 		ccp.handlePacketMessage(m.EventType, provider.PacketInfo(*t), c)
 	case *chains.ChannelInfo:
-		ccp.handleChannelMessage(m.EventType, provider.ChannelInfo(*t), c)
+		ccp.handleChannelMessage(ctx, m.EventType, provider.ChannelInfo(*t), c)
 	case *chains.ConnectionInfo:
 		ccp.handleConnectionMessage(m.EventType, provider.ConnectionInfo(*t), c)
 	case *chains.ClientInfo:
@@ -63,8 +63,10 @@ func (ccp *CosmosChainProcessor) handlePacketMessage(eventType string, pi provid
 	ccp.logPacketMessage(eventType, pi)
 }
 
-func (ccp *CosmosChainProcessor) handleChannelMessage(eventType string, ci provider.ChannelInfo, ibcMessagesCache processor.IBCMessagesCache) {
-	ccp.channelConnections[ci.ChannelID] = ci.ConnID
+func (ccp *CosmosChainProcessor) handleChannelMessage(ctx context.Context, eventType string, ci provider.ChannelInfo, ibcMessagesCache processor.IBCMessagesCache) {
+	if ci.ConnID != "" {
+		ccp.channelConnections[ci.ChannelID] = ci.ConnID
+	}
 	channelKey := processor.ChannelInfoChannelKey(ci)
 
 	if eventType == chantypes.EventTypeChannelOpenInit {
@@ -95,6 +97,11 @@ func (ccp *CosmosChainProcessor) handleChannelMessage(eventType string, ci provi
 					break
 				}
 			}
+		case chantypes.EventTypeChannelUpgradeTry, chantypes.EventTypeChannelUpgradeAck:
+			ci = ccp.withChannelUpgradeState(ctx, eventType, ci)
+		case chantypes.EventTypeChannelUpgradeOpen:
+			// the channel ordering may have changed with the upgrade.
+			ccp.channelStateCache.SetOpen(channelKey, true, ci.Order)
 		}
 		// Clear out MsgInitKeys once we have the counterparty channel ID
 		delete(ccp.channelStateCache, channelKey.MsgInitKey())
@@ -105,6 +112,37 @@ func (ccp *CosmosChainProcessor) handleChannelMessage(eventType string, ci provi
 	ccp.logChannelMessage(eventType, ci)
 }
 
+// withChannelUpgradeState populates the channel state and upgrade timeout for the channel upgrade events
+// which move the channel into FLUSHING, since they are not included in the event attributes.
+func (ccp *CosmosChainProcessor) withChannelUpgradeState(ctx context.Context, eventType string, ci provider.ChannelInfo) provider.ChannelInfo {
+	channelRes, err := ccp.chainProvider.QueryChannel(ctx, int64(ci.Height), ci.ChannelID, ci.PortID)
+	if err != nil {
+		ccp.log.Error("Error querying channel state for channel upgrade",
+			zap.String("event_type", eventType),
+			zap.String("channel_id", ci.ChannelID),
+			zap.String("port_id", ci.PortID),
+			zap.Error(err),
+		)
+		return ci
+	}
+	ci.State = channelRes.Channel.State
+
+	upgradeRes, err := ccp.chainProvider.QueryChannelUpgrade(ctx, int64(ci.Height), ci.ChannelID, ci.PortID)
+	if err != nil {
+		ccp.log.Error("Error querying channel upgrade",
+			zap.String("event_type", eventType),
+			zap.String("channel_id", ci.ChannelID),
+			zap.String("port_id", ci.PortID),
+			zap.Error(err),
+		)
+		return ci
+	}
+	ci.UpgradeTimeoutHeight = upgradeRes.Upgrade.Timeout.Height
+	ci.UpgradeTimeoutTimestamp = upgradeRes.Upgrade.Timeout.Timestamp
+
+	return ci
+}
+
 func (ccp *CosmosChainProcessor) handleConnectionMessage(eventType string, ci provider.ConnectionInfo, ibcMessagesCache processor.IBCMessagesCache) {
 	ccp.connectionClients[ci.ConnID] = ci.ClientID
 	connectionKey := processor.ConnectionInfoConnectionKey(ci)
@@ -1,6 +1,7 @@
 package cosmos
 
 import (
+	"context"
 	"testing"
 
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
@@ -122,7 +123,7 @@ func TestChannelStateCache(t *testing.T) {
 		c := processor.NewIBCMessagesCache()
 
 		// Observe MsgChannelOpenInit, which does not have counterparty channel ID.
-		ccp.handleChannelMessage(chantypes.EventTypeChannelOpenInit, msgOpenInit, c)
+		ccp.handleChannelMessage(context.Background(), chantypes.EventTypeChannelOpenInit, msgOpenInit, c)
 
 		require.Len(t, ccp.channelStateCache, 1)
 
@@ -131,7 +132,7 @@ func TestChannelStateCache(t *testing.T) {
 		require.False(t, ccp.channelStateCache[k.MsgInitKey()].Open)
 
 		// Observe MsgChannelOpenAck, which does have counterparty channel ID.
-		ccp.handleChannelMessage(chantypes.EventTypeChannelOpenAck, msgOpenAck, c)
+		ccp.handleChannelMessage(context.Background(), chantypes.EventTypeChannelOpenAck, msgOpenAck, c)
 
 		// The key with the empty counterparty channel ID should have been removed.
 		// The key with the counterparty channel ID should have been added.
@@ -159,7 +160,7 @@ func TestChannelStateCache(t *testing.T) {
 		ccp.channelStateCache.SetOpen(k, true, chantypes.NONE)
 
 		// Observe MsgChannelOpenInit, which does not have counterparty channel ID.
-		ccp.handleChannelMessage(chantypes.EventTypeChannelOpenInit, msgOpenInit, c)
+		ccp.handleChannelMessage(context.Background(), chantypes.EventTypeChannelOpenInit, msgOpenInit, c)
 
 		// The key with the empty counterparty channel ID should not have been added.
 		require.Len(t, ccp.channelStateCache, 1)
@@ -169,7 +170,7 @@ func TestChannelStateCache(t *testing.T) {
 		require.True(t, ccp.channelStateCache[k].Open)
 
 		// Observe MsgChannelOpenAck, which does have counterparty channel ID.
-		ccp.handleChannelMessage(chantypes.EventTypeChannelOpenAck, msgOpenAck, c)
+		ccp.handleChannelMessage(context.Background(), chantypes.EventTypeChannelOpenAck, msgOpenAck, c)
 
 		// Number of keys should still be 1.
 		require.Len(t, ccp.channelStateCache, 1)
@@ -0,0 +1,194 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/mempool"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"go.uber.org/zap"
+)
+
+// MultiBroadcast broadcasts each signed tx to other RPC endpoints along with the node of the chain, so that a tx
+// dropped from the mempool of a flaky public node still makes it into a block through another one.
+type MultiBroadcast struct {
+	// Endpoints are the RPC endpoints which txs are broadcast to besides the node of the chain.
+	Endpoints []BroadcastEndpoint `json:"endpoints" yaml:"endpoints"`
+
+	// Fanout is the number of Endpoints each tx is broadcast to, picked at random in proportion to their weights.
+	// Zero broadcasts each tx to all of them.
+	Fanout int `json:"fanout,omitempty" yaml:"fanout,omitempty"`
+}
+
+// BroadcastEndpoint is an RPC endpoint which txs are broadcast to.
+type BroadcastEndpoint struct {
+	Address string `json:"address" yaml:"address"`
+
+	// Weight is the relative chance of the endpoint being picked for a tx, when the Fanout is less than the number
+	// of endpoints. Defaults to 1.
+	Weight int `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// Validate checks that the endpoints have addresses and that the weights and fanout are not negative.
+func (m *MultiBroadcast) Validate() error {
+	if len(m.Endpoints) == 0 {
+		return fmt.Errorf("no endpoints")
+	}
+	for i, e := range m.Endpoints {
+		if e.Address == "" {
+			return fmt.Errorf("endpoint %d has no address", i)
+		}
+		if e.Weight < 0 {
+			return fmt.Errorf("invalid weight of endpoint %s: %d", e.Address, e.Weight)
+		}
+	}
+	if m.Fanout < 0 {
+		return fmt.Errorf("invalid fanout: %d", m.Fanout)
+	}
+	return nil
+}
+
+// txBroadcaster broadcasts txs to the mempool of a node.
+type txBroadcaster interface {
+	BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error)
+}
+
+// broadcastNode is a client of a multi-broadcast endpoint.
+type broadcastNode struct {
+	address string
+	weight  int
+	client  txBroadcaster
+}
+
+// newBroadcastNodes returns the clients of the multi-broadcast endpoints, if any.
+func (cc *CosmosProvider) newBroadcastNodes(timeout time.Duration) ([]broadcastNode, error) {
+	if cc.PCfg.MultiBroadcast == nil {
+		return nil, nil
+	}
+	nodes := make([]broadcastNode, 0, len(cc.PCfg.MultiBroadcast.Endpoints))
+	for _, e := range cc.PCfg.MultiBroadcast.Endpoints {
+		c, _, err := cc.newNodeClient(e.Address, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client of broadcast endpoint %s: %w", e.Address, err)
+		}
+		weight := e.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		nodes = append(nodes, broadcastNode{address: e.Address, weight: weight, client: c})
+	}
+	return nodes, nil
+}
+
+// broadcastTxSync broadcasts a tx to the node of the chain and, with multi-broadcast, to the broadcast endpoints
+// picked for it. The response of the first node which accepts the tx into its mempool is returned, or else the
+// response of the node of the chain, so that the tx is waited for once by its hash however many nodes accept it.
+func (cc *CosmosProvider) broadcastTxSync(ctx context.Context, tx []byte) (*coretypes.ResultBroadcastTx, error) {
+	var nodes []broadcastNode
+	if cc.PCfg.MultiBroadcast != nil {
+		nodes = pickBroadcastNodes(cc.broadcastNodes, cc.PCfg.MultiBroadcast.Fanout)
+	}
+	return multiBroadcastTx(ctx, cc.log, cc.node(), nodes, tx)
+}
+
+// multiBroadcastTx broadcasts tx to the primary node and to the nodes concurrently. It returns once a node accepts
+// the tx, leaving the broadcasts in flight to the other nodes to complete in the background.
+func multiBroadcastTx(
+	ctx context.Context,
+	log *zap.Logger,
+	primary txBroadcaster,
+	nodes []broadcastNode,
+	tx []byte,
+) (*coretypes.ResultBroadcastTx, error) {
+	if len(nodes) == 0 {
+		return primary.BroadcastTxSync(ctx, tx)
+	}
+
+	type result struct {
+		address string
+		res     *coretypes.ResultBroadcastTx
+		err     error
+	}
+	results := make(chan result, len(nodes)+1)
+	go func() {
+		res, err := primary.BroadcastTxSync(ctx, tx)
+		results <- result{res: res, err: err}
+	}()
+	// the broadcasts to the other nodes must not be canceled once the tx is accepted by one of them.
+	bgCtx := context.WithoutCancel(ctx)
+	for _, n := range nodes {
+		go func(n broadcastNode) {
+			res, err := n.client.BroadcastTxSync(bgCtx, tx)
+			results <- result{address: n.address, res: res, err: err}
+		}(n)
+	}
+
+	var primaryResult result
+	inCache := false
+	for i := 0; i < len(nodes)+1; i++ {
+		r := <-results
+		if r.err == nil && r.res != nil && r.res.Code == 0 {
+			return r.res, nil
+		}
+		if r.err != nil && strings.Contains(r.err.Error(), mempool.ErrTxInCache.Error()) {
+			inCache = true
+		}
+		if r.address == "" {
+			primaryResult = r
+			continue
+		}
+		log.Debug(
+			"Failed to broadcast tx to broadcast endpoint",
+			zap.String("address", r.address),
+			zap.Error(broadcastError(r.res, r.err)),
+		)
+	}
+
+	// a tx which is already in the mempool of a node was accepted by an earlier broadcast of it.
+	if inCache {
+		return &coretypes.ResultBroadcastTx{Hash: tmtypes.Tx(tx).Hash()}, nil
+	}
+	return primaryResult.res, primaryResult.err
+}
+
+// broadcastError returns the error of a broadcast which was not accepted, for logging.
+func broadcastError(res *coretypes.ResultBroadcastTx, err error) error {
+	if err != nil || res == nil {
+		return err
+	}
+	return fmt.Errorf("codespace: %s, code: %d, log: %s", res.Codespace, res.Code, res.Log)
+}
+
+// pickBroadcastNodes picks fanout of the nodes at random, without replacement, in proportion to their weights.
+// All the nodes are picked if fanout is zero or not less than the number of nodes.
+func pickBroadcastNodes(nodes []broadcastNode, fanout int) []broadcastNode {
+	if fanout == 0 || fanout >= len(nodes) {
+		return nodes
+	}
+	remaining := make([]broadcastNode, len(nodes))
+	copy(remaining, nodes)
+	picked := make([]broadcastNode, 0, fanout)
+	for len(picked) < fanout {
+		total := 0
+		for _, n := range remaining {
+			total += n.weight
+		}
+		if total == 0 {
+			break
+		}
+		r := rand.Intn(total)
+		for i, n := range remaining {
+			if r < n.weight {
+				picked = append(picked, n)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+			r -= n.weight
+		}
+	}
+	return picked
+}
@@ -0,0 +1,133 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/mempool"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockBroadcaster struct {
+	mu    sync.Mutex
+	calls int
+	res   *coretypes.ResultBroadcastTx
+	err   error
+}
+
+func (b *mockBroadcaster) BroadcastTxSync(_ context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+	if b.err != nil {
+		return nil, b.err
+	}
+	res := *b.res
+	res.Hash = tx.Hash()
+	return &res, nil
+}
+
+func (b *mockBroadcaster) numCalls() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+func TestMultiBroadcastTx(t *testing.T) {
+	tx := []byte("tx")
+	accepted := func() *mockBroadcaster { return &mockBroadcaster{res: &coretypes.ResultBroadcastTx{}} }
+	rejected := func() *mockBroadcaster {
+		return &mockBroadcaster{res: &coretypes.ResultBroadcastTx{Codespace: "sdk", Code: 32, Log: "account sequence mismatch"}}
+	}
+	unreachable := func() *mockBroadcaster { return &mockBroadcaster{err: errors.New("connection refused")} }
+	inCache := func() *mockBroadcaster { return &mockBroadcaster{err: mempool.ErrTxInCache} }
+
+	tests := []struct {
+		name     string
+		primary  *mockBroadcaster
+		nodes    []*mockBroadcaster
+		wantCode uint32
+		wantErr  bool
+	}{
+		{"primary only", accepted(), nil, 0, false},
+		{"primary unreachable", unreachable(), []*mockBroadcaster{unreachable(), accepted()}, 0, false},
+		{"all rejected", rejected(), []*mockBroadcaster{unreachable(), rejected()}, 32, false},
+		{"all unreachable", unreachable(), []*mockBroadcaster{unreachable()}, 0, true},
+		{"already in cache", unreachable(), []*mockBroadcaster{inCache()}, 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var nodes []broadcastNode
+			for i, n := range tc.nodes {
+				nodes = append(nodes, broadcastNode{address: string(rune('a' + i)), weight: 1, client: n})
+			}
+			res, err := multiBroadcastTx(context.Background(), zap.NewNop(), tc.primary, nodes, tx)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantCode, res.Code)
+			require.Equal(t, tmtypes.Tx(tx).Hash(), []byte(res.Hash))
+		})
+	}
+}
+
+func TestMultiBroadcastTxBroadcastsToAllNodes(t *testing.T) {
+	primary := &mockBroadcaster{res: &coretypes.ResultBroadcastTx{}}
+	nodes := []*mockBroadcaster{
+		{res: &coretypes.ResultBroadcastTx{}},
+		{err: errors.New("connection refused")},
+	}
+	_, err := multiBroadcastTx(context.Background(), zap.NewNop(), primary, []broadcastNode{
+		{address: "a", weight: 1, client: nodes[0]},
+		{address: "b", weight: 1, client: nodes[1]},
+	}, []byte("tx"))
+	require.NoError(t, err)
+
+	// the broadcasts in flight complete after the first node accepts the tx.
+	require.Eventually(t, func() bool {
+		return primary.numCalls() == 1 && nodes[0].numCalls() == 1 && nodes[1].numCalls() == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPickBroadcastNodes(t *testing.T) {
+	nodes := []broadcastNode{
+		{address: "a", weight: 1},
+		{address: "b", weight: 1},
+		{address: "c", weight: 98},
+	}
+	require.Len(t, pickBroadcastNodes(nodes, 0), 3)
+	require.Len(t, pickBroadcastNodes(nodes, 5), 3)
+
+	picks := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		picked := pickBroadcastNodes(nodes, 2)
+		require.Len(t, picked, 2)
+		require.NotEqual(t, picked[0].address, picked[1].address)
+		for _, n := range picked {
+			picks[n.address]++
+		}
+	}
+	// the heavy node is picked nearly every time, and the others share the second pick.
+	require.Greater(t, picks["c"], 950)
+	require.Equal(t, 2000, picks["a"]+picks["b"]+picks["c"])
+
+	// the nodes to pick from are left untouched.
+	require.Equal(t, "a", nodes[0].address)
+	require.Equal(t, "c", nodes[2].address)
+}
+
+func TestMultiBroadcastValidate(t *testing.T) {
+	require.NoError(t, (&MultiBroadcast{Endpoints: []BroadcastEndpoint{{Address: "http://a:26657"}}}).Validate())
+	require.Error(t, (&MultiBroadcast{}).Validate())
+	require.Error(t, (&MultiBroadcast{Endpoints: []BroadcastEndpoint{{}}}).Validate())
+	require.Error(t, (&MultiBroadcast{Endpoints: []BroadcastEndpoint{{Address: "http://a:26657", Weight: -1}}}).Validate())
+	require.Error(t, (&MultiBroadcast{Endpoints: []BroadcastEndpoint{{Address: "http://a:26657"}}, Fanout: -1}).Validate())
+}
@@ -0,0 +1,165 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/cosmos/relayer/v2/relayer/audit"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// ErrTxGenerated is returned in place of the result of a tx by a provider which generates txs only,
+// once the tx has been written out unsigned, since the tx is not broadcast.
+var ErrTxGenerated = errors.New("unsigned tx generated, not broadcast")
+
+// generateOnlyMu serializes the txs written out by providers generating txs only, which may share a writer.
+var generateOnlyMu sync.Mutex
+
+// SetGenerateOnly makes the provider write each tx it would sign and broadcast to w instead, unsigned, as a line
+// of JSON. The tx is then signed offline and broadcast with BroadcastSignedTx.
+func (cc *CosmosProvider) SetGenerateOnly(w io.Writer) {
+	cc.generateOnly = w
+}
+
+// generateUnsignedTx writes the tx of msgs, with its gas and fees set, unsigned to the generate-only writer,
+// and returns ErrTxGenerated.
+func (cc *CosmosProvider) generateUnsignedTx(
+	ctx context.Context,
+	msgs []provider.RelayerMessage,
+	memo string,
+	gas uint64,
+	txSignerKey string,
+	feegranterKeyOrAddr string,
+) error {
+	sequenceGuard := ensureSequenceGuard(cc, txSignerKey)
+	sequenceGuard.Mu.Lock()
+	defer sequenceGuard.Mu.Unlock()
+
+	dynamicFee := cc.DynamicFee(ctx)
+
+	var (
+		txf tx.Factory
+		bz  []byte
+	)
+	if err := func() error {
+		done := cc.SetSDKContext()
+		defer done()
+
+		var (
+			txb client.TxBuilder
+			err error
+		)
+		txf, txb, err = cc.buildUnsignedTx(ctx, msgs, memo, gas, txSignerKey, feegranterKeyOrAddr, sequenceGuard, dynamicFee)
+		if err != nil {
+			return err
+		}
+		bz, err = cc.Cdc.TxConfig.TxJSONEncoder()(txb.GetTx())
+		return err
+	}(); err != nil {
+		return err
+	}
+
+	generateOnlyMu.Lock()
+	_, err := fmt.Fprintf(cc.generateOnly, "%s\n", bz)
+	generateOnlyMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to write unsigned tx: %w", err)
+	}
+
+	// the account number and sequence are needed to sign the tx offline.
+	cc.log.Info(
+		"Generated unsigned tx",
+		zap.String("chain_id", cc.PCfg.ChainID),
+		zap.String("signer_key", txSignerKey),
+		zap.Uint64("account_number", txf.AccountNumber()),
+		zap.Uint64("sequence", txf.Sequence()),
+	)
+
+	return ErrTxGenerated
+}
+
+// BroadcastSignedTx broadcasts a tx which was signed offline, given as JSON, and waits for it to be included
+// in a block.
+func (cc *CosmosProvider) BroadcastSignedTx(ctx context.Context, txJSON []byte) (*provider.RelayerTxResponse, error) {
+	sdkTx, err := cc.Cdc.TxConfig.TxJSONDecoder()(txJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signed tx: %w", err)
+	}
+
+	sigTx, ok := sdkTx.(authsigning.Tx)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tx type %T", sdkTx)
+	}
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signatures of tx: %w", err)
+	}
+	if len(sigs) == 0 {
+		return nil, errors.New("tx is not signed")
+	}
+
+	txBytes, err := cc.Cdc.TxConfig.TxEncoder()(sdkTx)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := relayerMessages(sigTx.GetMsgs())
+
+	var (
+		rlyResp     *provider.RelayerTxResponse
+		callbackErr error
+		wg          sync.WaitGroup
+	)
+	wg.Add(1)
+	callbacks := []func(*provider.RelayerTxResponse, error){func(rtr *provider.RelayerTxResponse, err error) {
+		rlyResp = rtr
+		callbackErr = err
+		wg.Done()
+	}}
+
+	var auditEntry audit.Entry
+	if cc.auditLog != nil {
+		auditEntry = cc.newAuditEntry(audit.EventBroadcast, txBytes, "")
+		callbacks = append(callbacks, cc.auditResultCallback(auditEntry))
+	}
+
+	err = cc.broadcastTx(ctx, txBytes, msgs, sigTx.GetFee(), ctx, cc.PCfg.TxInclusionTimeout(), callbacks, "")
+
+	if cc.auditLog != nil {
+		if err != nil {
+			auditEntry.Error = err.Error()
+		}
+		cc.recordAudit(auditEntry)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	wg.Wait()
+
+	if callbackErr != nil {
+		return rlyResp, callbackErr
+	}
+	if rlyResp.Code != 0 {
+		return rlyResp, fmt.Errorf("transaction failed with code: %d", rlyResp.Code)
+	}
+	return rlyResp, nil
+}
+
+// relayerMessages wraps msgs as the messages of the provider.
+func relayerMessages(msgs []sdk.Msg) []provider.RelayerMessage {
+	rms := make([]provider.RelayerMessage, len(msgs))
+	for i, msg := range msgs {
+		rms[i] = NewCosmosMessage(msg, nil)
+	}
+	return rms
+}
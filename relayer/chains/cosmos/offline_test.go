@@ -0,0 +1,34 @@
+package cosmos
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastSignedTxRequiresSignedTx(t *testing.T) {
+	cc := &CosmosProvider{
+		PCfg: CosmosProviderConfig{AccountPrefix: "cosmos"},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+
+	_, err := cc.BroadcastSignedTx(context.Background(), []byte("not a tx"))
+	require.ErrorContains(t, err, "failed to decode signed tx")
+
+	txb := cc.Cdc.TxConfig.NewTxBuilder()
+	require.NoError(t, txb.SetMsgs(&banktypes.MsgSend{
+		FromAddress: "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+		ToAddress:   "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)),
+	}))
+	txb.SetGasLimit(100000)
+	unsigned, err := cc.Cdc.TxConfig.TxJSONEncoder()(txb.GetTx())
+	require.NoError(t, err)
+
+	// an unsigned tx, e.g. the output of --generate-only, is rejected before it is broadcast.
+	_, err = cc.BroadcastSignedTx(context.Background(), unsigned)
+	require.EqualError(t, err, "tx is not signed")
+}
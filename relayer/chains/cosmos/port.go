@@ -0,0 +1,64 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	capabilitytypes "github.com/cosmos/ibc-go/modules/capability/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"google.golang.org/grpc/status"
+)
+
+// QueryPortBound returns whether the port is bound by a module of the chain, so that channels can be opened on it.
+// IBC has no query of ports, so the MsgChannelOpenInit of a channel on the connection is simulated instead: the port
+// is bound unless the simulation fails to look up the capability of the port. The port is presumed bound if the
+// simulation fails otherwise, e.g. if the handshake is rejected by the module of the port.
+func (cc *CosmosProvider) QueryPortBound(ctx context.Context, portID, connectionID, counterpartyPortID string) (bool, error) {
+	if !cc.KeyExists(cc.PCfg.Key) {
+		return false, fmt.Errorf("key %s not found on chain %s", cc.PCfg.Key, cc.PCfg.ChainID)
+	}
+	keyInfo, err := cc.Keybase.Key(cc.PCfg.Key)
+	if err != nil {
+		return false, err
+	}
+	signer, err := cc.Address()
+	if err != nil {
+		return false, err
+	}
+	msg := chantypes.NewMsgChannelOpenInit(portID, "", chantypes.UNORDERED, []string{connectionID}, counterpartyPortID, signer)
+
+	done := cc.SetSDKContext()
+	defer done()
+
+	txf, err := cc.PrepareFactory(cc.TxFactory(""), cc.PCfg.Key)
+	if err != nil {
+		return false, err
+	}
+	txBytes, err := BuildSimTx(keyInfo, txf, msg)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = cc.QueryABCI(ctx, abci.RequestQuery{
+		Path: "/cosmos.tx.v1beta1.Service/Simulate",
+		Data: txBytes,
+	})
+	return portBoundFromSimulation(err)
+}
+
+// portBoundFromSimulation returns whether the port is bound from the error of the simulation of MsgChannelOpenInit on
+// the port. The port is not bound if the capability of the port is not found, and is presumed bound if the
+// simulation fails on chain otherwise. The error is returned if the simulation could not be run on chain.
+func portBoundFromSimulation(err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	if _, ok := status.FromError(err); !ok {
+		return false, err
+	}
+	notBound := strings.Contains(err.Error(), capabilitytypes.ErrCapabilityNotFound.Error()) ||
+		strings.Contains(err.Error(), capabilitytypes.ErrCapabilityOwnersNotFound.Error())
+	return !notBound, nil
+}
@@ -0,0 +1,64 @@
+package cosmos
+
+import (
+	"errors"
+	"testing"
+
+	errorsmod "cosmossdk.io/errors"
+	abci "github.com/cometbft/cometbft/abci/types"
+	capabilitytypes "github.com/cosmos/ibc-go/modules/capability/types"
+	porttypes "github.com/cosmos/ibc-go/v8/modules/core/05-port/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortBoundFromSimulation(t *testing.T) {
+	// simulationErr returns the error of a simulation which failed on chain with err, as returned by QueryABCI.
+	simulationErr := func(err error) error {
+		_, code, _ := errorsmod.ABCIInfo(err, false)
+		return sdkErrorToGRPCError(abci.ResponseQuery{Code: code, Log: "failed to execute message; message index: 0: " + err.Error()})
+	}
+
+	for _, tc := range []struct {
+		name          string
+		err           error
+		expectedBound bool
+		expectedErr   bool
+	}{
+		{
+			name:          "simulation succeeds",
+			expectedBound: true,
+		},
+		{
+			name: "capability of the port not found",
+			err: simulationErr(errorsmod.Wrap(
+				capabilitytypes.ErrCapabilityNotFound, "could not retrieve module from port-id: ports/icahost",
+			)),
+		},
+		{
+			name: "owners of the capability of the port not found",
+			err: simulationErr(errorsmod.Wrap(
+				capabilitytypes.ErrCapabilityOwnersNotFound, "could not retrieve module from port-id: ports/wasm.contract",
+			)),
+		},
+		{
+			name:          "handshake rejected by the module of the port",
+			err:           simulationErr(errorsmod.Wrap(porttypes.ErrInvalidPort, "invalid port: transfer, expected icacontroller")),
+			expectedBound: true,
+		},
+		{
+			name:        "simulation could not be run",
+			err:         errors.New("post failed: dial tcp 127.0.0.1:26657: connect: connection refused"),
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bound, err := portBoundFromSimulation(tc.err)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedBound, bound)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"slices"
 	"sync"
 	"time"
 
@@ -13,23 +14,38 @@ import (
 	prov "github.com/cometbft/cometbft/light/provider/http"
 	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	libclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/gogoproto/proto"
 	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
 	cwrapper "github.com/cosmos/relayer/v2/client"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/audit"
 	"github.com/cosmos/relayer/v2/relayer/codecs/ethermint"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"github.com/strangelove-ventures/cometbft-client/client"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 var (
-	_ provider.ChainProvider  = &CosmosProvider{}
-	_ provider.KeyProvider    = &CosmosProvider{}
-	_ provider.ProviderConfig = &CosmosProviderConfig{}
+	_ provider.ChainProvider       = &CosmosProvider{}
+	_ provider.KeyProvider         = &CosmosProvider{}
+	_ provider.RPCEndpointSwitcher = &CosmosProvider{}
+	_ provider.ProviderConfig      = &CosmosProviderConfig{}
+)
+
+// Sign modes of the sign-mode config. Some chains reject SIGN_MODE_DIRECT for certain messages,
+// e.g. those signed with ledger support in mind, and require amino-json instead.
+const (
+	SignModeDirect    = "direct"
+	SignModeAminoJSON = "amino-json"
+	SignModeTextual   = "textual"
 )
 
 type CosmosProviderConfig struct {
@@ -38,6 +54,8 @@ type CosmosProviderConfig struct {
 	ChainName        string                     `json:"-" yaml:"-"`
 	ChainID          string                     `json:"chain-id" yaml:"chain-id"`
 	RPCAddr          string                     `json:"rpc-addr" yaml:"rpc-addr"`
+	GRPCAddr         string                     `json:"grpc-addr,omitempty" yaml:"grpc-addr,omitempty"`
+	GRPCOnly         bool                       `json:"grpc-only,omitempty" yaml:"grpc-only,omitempty"`
 	AccountPrefix    string                     `json:"account-prefix" yaml:"account-prefix"`
 	KeyringBackend   string                     `json:"keyring-backend" yaml:"keyring-backend"`
 	DynamicGasPrice  bool                       `json:"dynamic-gas-price" yaml:"dynamic-gas-price"`
@@ -55,11 +73,61 @@ type CosmosProviderConfig struct {
 	Slip44           *int                       `json:"coin-type" yaml:"coin-type"`
 	SigningAlgorithm string                     `json:"signing-algorithm" yaml:"signing-algorithm"`
 	Broadcast        provider.BroadcastMode     `json:"broadcast-mode" yaml:"broadcast-mode"`
+	ClientUpdate     provider.ClientUpdateMode  `json:"client-update-mode" yaml:"client-update-mode"`
+	MaxBatch         uint64                     `json:"max-batch-msgs" yaml:"max-batch-msgs"`
 	MinLoopDuration  time.Duration              `json:"min-loop-duration" yaml:"min-loop-duration"`
+	HaltThreshold    time.Duration              `json:"halt-threshold,omitempty" yaml:"halt-threshold,omitempty"`
 	ExtensionOptions []provider.ExtensionOption `json:"extension-options" yaml:"extension-options"`
 
+	// FallbackGasPrices are gas prices in other fee denoms accepted by the chain, e.g. through fee abstraction,
+	// tried in order when the balance of the signer is too low to pay the fee of a tx with the GasPrices.
+	FallbackGasPrices []string `json:"fallback-gas-prices,omitempty" yaml:"fallback-gas-prices,omitempty"`
+
+	// FeePayeeKey is the name of the key registered as the ICS-29 payee of Key with the fee middleware,
+	// whose fees are claimed to Key by ClaimFees.
+	FeePayeeKey string `json:"fee-payee-key,omitempty" yaml:"fee-payee-key,omitempty"`
+
+	// FeeCap, if set, limits the fees paid for the txs broadcast to the chain, per tx and per hour.
+	FeeCap *FeeCap `json:"fee-cap,omitempty" yaml:"fee-cap,omitempty"`
+
+	// EndpointAuth, if set, configures TLS and auth headers for the connections to the rpc-addr and grpc-addr.
+	EndpointAuth *EndpointAuth `json:"endpoint-auth,omitempty" yaml:"endpoint-auth,omitempty"`
+
+	// MultiBroadcast, if set, broadcasts each tx to other RPC endpoints along with the node of the chain.
+	MultiBroadcast *MultiBroadcast `json:"multi-broadcast,omitempty" yaml:"multi-broadcast,omitempty"`
+
 	// If FeeGrantConfiguration is set, TXs submitted by the ChainClient will be signed by the FeeGrantees in a round-robin fashion by default.
 	FeeGrants *FeeGrantConfiguration `json:"feegrants" yaml:"feegrants"`
+
+	// If FeeGranter is set, TXs signed by the ChainClient key are paid for by the allowance which the FeeGranter,
+	// a bech32 address or key name of a sponsor account, granted to the key via the feegrant module.
+	FeeGranter string `json:"fee-granter,omitempty" yaml:"fee-granter,omitempty"`
+
+	// If AuthzGranter is set, messages are sent on behalf of the AuthzGranter, the bech32 address of a cold account
+	// which granted them to the ChainClient key via the authz module, and executed by the key with MsgExec.
+	AuthzGranter string `json:"authz-granter,omitempty" yaml:"authz-granter,omitempty"`
+
+	// MsgGasAdjustments overrides the GasAdjustment of txs with the given types of messages, by type URL,
+	// e.g. for messages whose gas usage varies between simulation and execution.
+	MsgGasAdjustments map[string]float64 `json:"msg-gas-adjustments,omitempty" yaml:"msg-gas-adjustments,omitempty"`
+
+	// WasmHookGasAdjustment is the minimum gas adjustment of txs with packet messages which execute a CosmWasm
+	// contract through the ibc-hooks middleware, whose gas usage depends on the state of the contract.
+	WasmHookGasAdjustment float64 `json:"wasm-hook-gas-adjustment,omitempty" yaml:"wasm-hook-gas-adjustment,omitempty"`
+
+	// UpgradeQuiesceBlocks is how many blocks before the height of an upgrade scheduled by the upgrade module
+	// relaying to the chain is paused, until the upgraded chain produces blocks. Defaults to 10.
+	UpgradeQuiesceBlocks int64 `json:"upgrade-quiesce-blocks,omitempty" yaml:"upgrade-quiesce-blocks,omitempty"`
+}
+
+// codecs returns the extra-codecs of the chain, along with the ethermint codec for eth_secp256k1 keys if
+// neither the ethermint nor the injective codec is configured, so that the keys can be decoded.
+func (pc CosmosProviderConfig) codecs() []string {
+	if pc.SigningAlgorithm != string(ethermint.EthSecp256k1Type) ||
+		slices.Contains(pc.ExtraCodecs, "ethermint") || slices.Contains(pc.ExtraCodecs, "injective") {
+		return pc.ExtraCodecs
+	}
+	return append(slices.Clip(pc.ExtraCodecs), "ethermint")
 }
 
 // By default, TXs will be signed by the feegrantees 'ManagedGrantees' keys in a round robin fashion.
@@ -82,6 +150,75 @@ func (pc CosmosProviderConfig) Validate() error {
 	if _, err := time.ParseDuration(pc.Timeout); err != nil {
 		return fmt.Errorf("invalid Timeout: %w", err)
 	}
+	if _, err := provider.ParseBlockTimeout(pc.BlockTimeout); err != nil {
+		return fmt.Errorf("invalid block-timeout: %w", err)
+	}
+	switch pc.ClientUpdate {
+	case "", provider.ClientUpdateModeBundled, provider.ClientUpdateModeSeparate:
+	default:
+		return fmt.Errorf("invalid client-update-mode: %s, supports one of: [%s, %s]",
+			pc.ClientUpdate, provider.ClientUpdateModeBundled, provider.ClientUpdateModeSeparate)
+	}
+	if pc.GasAdjustment < 0 {
+		return fmt.Errorf("invalid gas-adjustment: %v", pc.GasAdjustment)
+	}
+	for typeURL, adjustment := range pc.MsgGasAdjustments {
+		if adjustment <= 0 {
+			return fmt.Errorf("invalid msg-gas-adjustments for %s: %v", typeURL, adjustment)
+		}
+	}
+	if pc.WasmHookGasAdjustment < 0 {
+		return fmt.Errorf("invalid wasm-hook-gas-adjustment: %v", pc.WasmHookGasAdjustment)
+	}
+	if pc.UpgradeQuiesceBlocks < 0 {
+		return fmt.Errorf("invalid upgrade-quiesce-blocks: %d", pc.UpgradeQuiesceBlocks)
+	}
+	for _, gasPrice := range pc.FallbackGasPrices {
+		if _, err := sdk.ParseDecCoin(gasPrice); err != nil {
+			return fmt.Errorf("invalid fallback-gas-prices %q: %w", gasPrice, err)
+		}
+	}
+	if pc.FeeCap != nil {
+		if err := pc.FeeCap.Validate(); err != nil {
+			return fmt.Errorf("invalid fee-cap: %w", err)
+		}
+	}
+	if pc.GRPCOnly && pc.GRPCAddr == "" {
+		return fmt.Errorf("grpc-only requires grpc-addr")
+	}
+	if pc.EndpointAuth != nil {
+		if err := pc.EndpointAuth.Validate(); err != nil {
+			return fmt.Errorf("invalid endpoint-auth: %w", err)
+		}
+	}
+	if pc.MultiBroadcast != nil {
+		if err := pc.MultiBroadcast.Validate(); err != nil {
+			return fmt.Errorf("invalid multi-broadcast: %w", err)
+		}
+	}
+	if pc.FeeGranter != "" && pc.FeeGrants != nil {
+		return fmt.Errorf("fee-granter cannot be used along with feegrants")
+	}
+	switch pc.SignModeStr {
+	case "", SignModeDirect, SignModeAminoJSON, SignModeTextual:
+	default:
+		return fmt.Errorf("invalid sign-mode: %s, supports one of: [%s, %s, %s]",
+			pc.SignModeStr, SignModeDirect, SignModeAminoJSON, SignModeTextual)
+	}
+	switch pc.SigningAlgorithm {
+	case "", string(hd.Secp256k1Type), string(hd.Sr25519Type), string(ethermint.EthSecp256k1Type):
+	default:
+		return fmt.Errorf("invalid signing-algorithm: %s, supports one of: [%s, %s, %s]",
+			pc.SigningAlgorithm, hd.Secp256k1Type, hd.Sr25519Type, ethermint.EthSecp256k1Type)
+	}
+	if pc.AuthzGranter != "" {
+		if pc.FeeGrants != nil {
+			return fmt.Errorf("authz-granter cannot be used along with feegrants")
+		}
+		if _, err := sdk.GetFromBech32(pc.AuthzGranter, pc.AccountPrefix); err != nil {
+			return fmt.Errorf("invalid authz-granter: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -89,6 +226,20 @@ func (pc CosmosProviderConfig) BroadcastMode() provider.BroadcastMode {
 	return pc.Broadcast
 }
 
+func (pc CosmosProviderConfig) ClientUpdateMode() provider.ClientUpdateMode {
+	return pc.ClientUpdate
+}
+
+func (pc CosmosProviderConfig) MaxBatchMsgs() uint64 {
+	return pc.MaxBatch
+}
+
+// TxInclusionTimeout returns the block-timeout, which is checked by Validate.
+func (pc CosmosProviderConfig) TxInclusionTimeout() provider.BlockTimeout {
+	timeout, _ := provider.ParseBlockTimeout(pc.BlockTimeout)
+	return timeout
+}
+
 // NewProvider validates the CosmosProviderConfig, instantiates a ChainClient and then instantiates a CosmosProvider
 func (pc CosmosProviderConfig) NewProvider(log *zap.Logger, homepath string, debug bool, chainName string) (provider.ChainProvider, error) {
 	if err := pc.Validate(); err != nil {
@@ -104,6 +255,10 @@ func (pc CosmosProviderConfig) NewProvider(log *zap.Logger, homepath string, deb
 		pc.Broadcast = provider.BroadcastModeBatch
 	}
 
+	if pc.ClientUpdate == "" {
+		pc.ClientUpdate = provider.ClientUpdateModeBundled
+	}
+
 	cp := &CosmosProvider{
 		log:            log,
 		PCfg:           pc,
@@ -113,7 +268,16 @@ func (pc CosmosProviderConfig) NewProvider(log *zap.Logger, homepath string, deb
 		walletStateMap: map[string]*WalletState{},
 
 		// TODO: this is a bit of a hack, we should probably have a better way to inject modules
-		Cdc: MakeCodec(pc.Modules, pc.ExtraCodecs, pc.AccountPrefix, pc.AccountPrefix+"valoper"),
+		Cdc: MakeCodec(pc.Modules, pc.codecs(), pc.AccountPrefix, pc.AccountPrefix+"valoper"),
+	}
+
+	if pc.SignModeStr == SignModeTextual {
+		// the coin metadata for textual sign bytes is queried from the chain through the provider.
+		txConfig, err := newTextualTxConfig(cp.Cdc.Marshaler, pc.AccountPrefix, pc.AccountPrefix+"valoper", cp)
+		if err != nil {
+			return nil, err
+		}
+		cp.Cdc.TxConfig = txConfig
 	}
 
 	return cp, nil
@@ -130,7 +294,17 @@ type CosmosProvider struct {
 	Input          io.Reader
 	Output         io.Writer
 	Cdc            Codec
-	// TODO: GRPC Client type?
+
+	// GRPCConn is used for state queries which do not require proofs, if a gRPC address is configured.
+	// Otherwise, state queries are made through abci_query on the RPC client.
+	GRPCConn *grpc.ClientConn
+
+	// endpointMu guards the RPC endpoint in use and the LightProvider, which are replaced by SwitchRPCAddr.
+	endpointMu sync.RWMutex
+	rpcAddr    string
+
+	// broadcastNodes are the clients of the multi-broadcast endpoints, which txs are broadcast to as well.
+	broadcastNodes []broadcastNode
 
 	//nextAccountSeq uint64
 	feegrantMu sync.Mutex
@@ -146,6 +320,31 @@ type CosmosProvider struct {
 
 	metrics *processor.PrometheusMetrics
 
+	// feeSpend records the fees of the txs broadcast within the last hour, for the per-hour fee cap.
+	feeSpend feeSpend
+
+	// gasTable learns the gas used by each type of message, to estimate gas when txs cannot be simulated.
+	gasTable gasTable
+
+	// packetIndex, if set, stores the packet events observed by the chain processor
+	// and serves packet queries before falling back to tx_search.
+	packetIndex *indexer.Store
+
+	// auditLog, if set, records every transaction signed and broadcast.
+	auditLog *audit.Log
+
+	// txRecorder, if set, records every transaction broadcast for fee accounting.
+	txRecorder accounting.Recorder
+
+	// notifier, if set, is notified of the balance of the wallet by the chain processor.
+	notifier *notify.Notifier
+
+	// generateOnly, if set, receives the txs of the provider unsigned in place of them being signed and broadcast.
+	generateOnly io.Writer
+
+	// queryCache, if set, caches the results of client, connection and channel queries.
+	queryCache *provider.QueryCache
+
 	// for comet < v0.37, decode tm events as base64
 	cometLegacyEncoding bool
 
@@ -273,10 +472,20 @@ func (cc *CosmosProvider) SetRpcAddr(rpcAddr string) error {
 	return nil
 }
 
-// Init initializes the keystore, RPC client, amd light client provider.
+// SetChainId sets the chain-id of the chain.
+func (cc *CosmosProvider) SetChainId(chainID string) error {
+	cc.PCfg.ChainID = chainID
+	return nil
+}
+
+// Init initializes the keystore, RPC client, amd light client provider, which are served by the gRPC server
+// instead if grpc-only is set.
 // Once initialization is complete an attempt to query the underlying node's tendermint version is performed.
 // NOTE: Init must be called after creating a new instance of CosmosProvider.
 func (cc *CosmosProvider) Init(ctx context.Context) error {
+	if err := checkKeyringBackend(cc.PCfg.KeyringBackend); err != nil {
+		return err
+	}
 	keybase, err := keyring.New(
 		cc.PCfg.ChainID,
 		cc.PCfg.KeyringBackend,
@@ -295,29 +504,129 @@ func (cc *CosmosProvider) Init(ctx context.Context) error {
 		return err
 	}
 
-	c, err := client.NewClient(cc.PCfg.RPCAddr, timeout)
+	// with grpc-only, blocks and txs are read from the gRPC server, and the rpc-addr is not used.
+	var (
+		rpcClient     cwrapper.RPCClient
+		lightprovider provtypes.Provider = grpcNode{cc: cc}
+	)
+	if !cc.PCfg.GRPCOnly {
+		rpcClient, lightprovider, err = cc.newNodeClient(cc.PCfg.RPCAddr, timeout)
+		if err != nil {
+			return err
+		}
+	}
+	broadcastNodes, err := cc.newBroadcastNodes(timeout)
 	if err != nil {
 		return err
 	}
 
-	lightprovider, err := prov.New(cc.PCfg.ChainID, cc.PCfg.RPCAddr)
-	if err != nil {
-		return err
+	// a provider which is initialized again, e.g. to fail over to another endpoint, must not leak its connection.
+	if err := cc.Close(); err != nil {
+		return fmt.Errorf("failed to close grpc connection: %w", err)
 	}
 
-	rpcClient := cwrapper.NewRPCClient(c)
+	if cc.PCfg.GRPCAddr != "" {
+		tlsConfig, err := cc.PCfg.EndpointAuth.tlsConfig()
+		if err != nil {
+			return err
+		}
+		grpcConn, err := newGRPCConn(cc.PCfg.GRPCAddr, cc.Cdc.InterfaceRegistry, tlsConfig, cc.PCfg.EndpointAuth.grpcDialOptions()...)
+		if err != nil {
+			return err
+		}
+		cc.GRPCConn = grpcConn
+	}
 
 	cc.RPCClient = rpcClient
 	cc.LightProvider = lightprovider
+	cc.rpcAddr = cc.PCfg.RPCAddr
+	cc.broadcastNodes = broadcastNodes
 	cc.Keybase = keybase
 
 	return nil
 }
 
+// RPCAddr returns the RPC endpoint in use, which is the configured rpc-addr unless switched by SwitchRPCAddr.
+func (cc *CosmosProvider) RPCAddr() string {
+	cc.endpointMu.RLock()
+	defer cc.endpointMu.RUnlock()
+	if cc.rpcAddr == "" {
+		return cc.PCfg.RPCAddr
+	}
+	return cc.rpcAddr
+}
+
+// SwitchRPCAddr switches the provider to another RPC endpoint at runtime, once the endpoint is verified to serve
+// the chain and to be caught up. The configured rpc-addr is left unchanged. Requests in flight complete on the
+// previous endpoint.
+func (cc *CosmosProvider) SwitchRPCAddr(ctx context.Context, rpcAddr string) error {
+	if cc.PCfg.GRPCOnly {
+		return fmt.Errorf("switch rpc-addr: %w", errGRPCOnly)
+	}
+
+	timeout, err := time.ParseDuration(cc.PCfg.Timeout)
+	if err != nil {
+		return err
+	}
+
+	rpcClient, lightprovider, err := cc.newNodeClient(rpcAddr, timeout)
+	if err != nil {
+		return err
+	}
+
+	stat, err := rpcClient.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query status of %s: %w", rpcAddr, err)
+	}
+	if stat.NodeInfo.Network != cc.PCfg.ChainID {
+		return fmt.Errorf("node at %s is running chain %s, expected %s", rpcAddr, stat.NodeInfo.Network, cc.PCfg.ChainID)
+	}
+	if stat.SyncInfo.CatchingUp {
+		return fmt.Errorf("node at %s running chain %s not caught up", rpcAddr, cc.PCfg.ChainID)
+	}
+
+	cc.endpointMu.Lock()
+	defer cc.endpointMu.Unlock()
+	cc.RPCClient.SetClientOf(rpcClient)
+	cc.LightProvider = lightprovider
+	cc.rpcAddr = rpcAddr
+
+	return nil
+}
+
+// newNodeClient returns the RPC client of the node at rpcAddr, and the light client provider served by it.
+// The upstream CometBFT client is used if endpoint-auth is configured, since the slimmed down client used
+// otherwise does not support TLS settings or auth headers.
+func (cc *CosmosProvider) newNodeClient(rpcAddr string, timeout time.Duration) (cwrapper.RPCClient, provtypes.Provider, error) {
+	if cc.PCfg.EndpointAuth != nil {
+		c, err := cc.PCfg.EndpointAuth.rpcClient(rpcAddr, timeout)
+		if err != nil {
+			return cwrapper.RPCClient{}, nil, err
+		}
+		return cwrapper.NewCometRPCClient(c), prov.NewWithClient(cc.PCfg.ChainID, c), nil
+	}
+
+	c, err := client.NewClient(rpcAddr, timeout)
+	if err != nil {
+		return cwrapper.RPCClient{}, nil, err
+	}
+	lightprovider, err := prov.New(cc.PCfg.ChainID, rpcAddr)
+	if err != nil {
+		return cwrapper.RPCClient{}, nil, err
+	}
+	return cwrapper.NewRPCClient(c), lightprovider, nil
+}
+
+func (cc *CosmosProvider) lightProvider() provtypes.Provider {
+	cc.endpointMu.RLock()
+	defer cc.endpointMu.RUnlock()
+	return cc.LightProvider
+}
+
 // WaitForNBlocks blocks until the next block on a given chain
 func (cc *CosmosProvider) WaitForNBlocks(ctx context.Context, n int64) error {
 	var initial int64
-	h, err := cc.RPCClient.Status(ctx)
+	h, err := cc.node().Status(ctx)
 	if err != nil {
 		return err
 	}
@@ -326,7 +635,7 @@ func (cc *CosmosProvider) WaitForNBlocks(ctx context.Context, n int64) error {
 	}
 	initial = h.SyncInfo.LatestBlockHeight
 	for {
-		h, err = cc.RPCClient.Status(ctx)
+		h, err = cc.node().Status(ctx)
 		if err != nil {
 			return err
 		}
@@ -343,7 +652,7 @@ func (cc *CosmosProvider) WaitForNBlocks(ctx context.Context, n int64) error {
 }
 
 func (cc *CosmosProvider) BlockTime(ctx context.Context, height int64) (time.Time, error) {
-	resultBlock, err := cc.RPCClient.Block(ctx, &height)
+	resultBlock, err := cc.node().Block(ctx, &height)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -354,6 +663,21 @@ func (cc *CosmosProvider) SetMetrics(m *processor.PrometheusMetrics) {
 	cc.metrics = m
 }
 
+// SetPacketIndex sets the index which stores the packet events of the chain and serves packet queries.
+func (cc *CosmosProvider) SetPacketIndex(index *indexer.Store) {
+	cc.packetIndex = index
+}
+
+// SetNotifier sets the notifier of the wallet of the chain running low on funds.
+func (cc *CosmosProvider) SetNotifier(notifier *notify.Notifier) {
+	cc.notifier = notifier
+}
+
+// SetQueryCache sets the cache of the results of client, connection and channel queries.
+func (cc *CosmosProvider) SetQueryCache(cache *provider.QueryCache) {
+	cc.queryCache = cache
+}
+
 func (cc *CosmosProvider) updateNextAccountSequence(sequenceGuard *WalletState, seq uint64) {
 	if seq > sequenceGuard.NextAccountSequence {
 		sequenceGuard.NextAccountSequence = seq
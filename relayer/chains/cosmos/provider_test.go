@@ -0,0 +1,48 @@
+package cosmos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockTimeout(t *testing.T) {
+	cfg := CosmosProviderConfig{Timeout: "10s"}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, provider.BlockTimeout{}, cfg.TxInclusionTimeout())
+
+	cfg.BlockTimeout = "90s"
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, provider.BlockTimeout{Duration: 90 * time.Second}, cfg.TxInclusionTimeout())
+
+	cfg.BlockTimeout = "20"
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, provider.BlockTimeout{Blocks: 20}, cfg.TxInclusionTimeout())
+
+	for _, invalid := range []string{"0", "-5s", "twenty"} {
+		cfg.BlockTimeout = invalid
+		require.Error(t, cfg.Validate(), invalid)
+	}
+}
+
+func TestSignMode(t *testing.T) {
+	cfg := CosmosProviderConfig{Timeout: "10s"}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, signing.SignMode_SIGN_MODE_UNSPECIFIED, cfg.SignMode())
+
+	for signModeStr, signMode := range map[string]signing.SignMode{
+		SignModeDirect:    signing.SignMode_SIGN_MODE_DIRECT,
+		SignModeAminoJSON: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		SignModeTextual:   signing.SignMode_SIGN_MODE_TEXTUAL,
+	} {
+		cfg.SignModeStr = signModeStr
+		require.NoError(t, cfg.Validate())
+		require.Equal(t, signMode, cfg.SignMode())
+	}
+
+	cfg.SignModeStr = "amino"
+	require.Error(t, cfg.Validate())
+}
@@ -67,7 +67,11 @@ func (cc *CosmosProvider) queryIBCMessages(ctx context.Context, log *zap.Logger,
 	)
 
 	eg.Go(func() error {
-		res, err := cc.RPCClient.BlockSearch(ctx, query, &page, &limit, "")
+		if cc.PCfg.GRPCOnly {
+			// blocks cannot be searched over gRPC, only the messages of txs are found.
+			return nil
+		}
+		res, err := cc.node().BlockSearch(ctx, query, &page, &limit, "")
 		if err != nil {
 			return err
 		}
@@ -77,7 +81,7 @@ func (cc *CosmosProvider) queryIBCMessages(ctx context.Context, log *zap.Logger,
 		for _, b := range res.Blocks {
 			b := b
 			nestedEg.Go(func() error {
-				block, err := cc.RPCClient.BlockResults(ctx, &b.Block.Height)
+				block, err := cc.node().BlockResults(ctx, &b.Block.Height)
 				if err != nil {
 					return err
 				}
@@ -93,7 +97,7 @@ func (cc *CosmosProvider) queryIBCMessages(ctx context.Context, log *zap.Logger,
 	})
 
 	eg.Go(func() error {
-		res, err := cc.RPCClient.TxSearch(ctx, query, true, &page, &limit, "")
+		res, err := cc.node().TxSearch(ctx, query, true, &page, &limit, "")
 		if err != nil {
 			return err
 		}
@@ -121,7 +125,7 @@ func (cc *CosmosProvider) QueryTx(ctx context.Context, hashHex string) (*provide
 		return nil, err
 	}
 
-	resp, err := cc.RPCClient.Tx(ctx, hash, true)
+	resp, err := cc.node().Tx(ctx, hash, true)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +155,7 @@ func (cc *CosmosProvider) QueryTxs(ctx context.Context, page, limit int, events
 		return nil, errors.New("limit must greater than 0")
 	}
 
-	res, err := cc.RPCClient.TxSearch(ctx, strings.Join(events, " AND "), true, &page, &limit, "")
+	res, err := cc.node().TxSearch(ctx, strings.Join(events, " AND "), true, &page, &limit, "")
 	if err != nil {
 		return nil, err
 	}
@@ -316,6 +320,20 @@ func (cc *CosmosProvider) QueryBalanceWithAddress(ctx context.Context, address s
 	return coins, nil
 }
 
+// QuerySupplyOf returns the total supply of denom.
+func (cc *CosmosProvider) QuerySupplyOf(ctx context.Context, denom string) (sdk.Coin, error) {
+	res, err := bankTypes.NewQueryClient(cc).SupplyOf(ctx, &bankTypes.QuerySupplyOfRequest{Denom: denom})
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+	return res.Amount, nil
+}
+
+// EscrowAddress returns the address of the account escrowing the tokens sent through the ICS-20 channel.
+func (cc *CosmosProvider) EscrowAddress(portID, channelID string) (string, error) {
+	return sdk.Bech32ifyAddressBytes(cc.PCfg.AccountPrefix, transfertypes.GetEscrowAddress(portID, channelID))
+}
+
 func (cc *CosmosProvider) queryParamsSubspaceTime(
 	ctx context.Context,
 	subspace string,
@@ -389,6 +407,12 @@ func (cc *CosmosProvider) QueryUnbondingPeriod(ctx context.Context) (time.Durati
 // at the latest state available.
 // Issue: https://github.com/cosmos/cosmos-sdk/issues/6567
 func (cc *CosmosProvider) QueryTendermintProof(ctx context.Context, height int64, key []byte) ([]byte, []byte, clienttypes.Height, error) {
+	return cc.QueryStoreProof(ctx, height, ibcexported.StoreKey, key)
+}
+
+// QueryStoreProof performs an ABCI query with the given key in the given store, e.g. "ibc" or "bank",
+// and returns the value and proof of the query as QueryTendermintProof does.
+func (cc *CosmosProvider) QueryStoreProof(ctx context.Context, height int64, storeKey string, key []byte) ([]byte, []byte, clienttypes.Height, error) {
 	// ABCI queries at heights 1, 2 or less than or equal to 0 are not supported.
 	// Base app does not support queries for height less than or equal to 1.
 	// Therefore, a query at height 2 would be equivalent to a query at height 3.
@@ -404,7 +428,7 @@ func (cc *CosmosProvider) QueryTendermintProof(ctx context.Context, height int64
 	}
 
 	req := abci.RequestQuery{
-		Path:   fmt.Sprintf("store/%s/key", ibcexported.StoreKey),
+		Path:   fmt.Sprintf("store/%s/key", storeKey),
 		Height: height,
 		Data:   key,
 		Prove:  true,
@@ -433,6 +457,14 @@ func (cc *CosmosProvider) QueryTendermintProof(ctx context.Context, height int64
 
 // QueryClientStateResponse retrieves the latest consensus state for a client in state at a given height
 func (cc *CosmosProvider) QueryClientStateResponse(ctx context.Context, height int64, srcClientId string) (*clienttypes.QueryClientStateResponse, error) {
+	return provider.CachedQuery(ctx, cc.queryCache, provider.QueryTypeClientState, height, srcClientId,
+		func(ctx context.Context) (*clienttypes.QueryClientStateResponse, error) {
+			return cc.queryClientStateResponse(ctx, height, srcClientId)
+		},
+	)
+}
+
+func (cc *CosmosProvider) queryClientStateResponse(ctx context.Context, height int64, srcClientId string) (*clienttypes.QueryClientStateResponse, error) {
 	key := host.FullClientStateKey(srcClientId)
 
 	value, proofBz, proofHeight, err := cc.QueryTendermintProof(ctx, height, key)
@@ -482,6 +514,15 @@ func (cc *CosmosProvider) QueryClientState(ctx context.Context, height int64, cl
 
 // QueryClientConsensusState retrieves the latest consensus state for a client in state at a given height
 func (cc *CosmosProvider) QueryClientConsensusState(ctx context.Context, chainHeight int64, clientid string, clientHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	return provider.CachedQuery(ctx, cc.queryCache, provider.QueryTypeConsensusState,
+		chainHeight, fmt.Sprintf("%s/%s", clientid, clientHeight),
+		func(ctx context.Context) (*clienttypes.QueryConsensusStateResponse, error) {
+			return cc.queryClientConsensusState(ctx, chainHeight, clientid, clientHeight)
+		},
+	)
+}
+
+func (cc *CosmosProvider) queryClientConsensusState(ctx context.Context, chainHeight int64, clientid string, clientHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
 	key := host.FullConsensusStateKey(clientid, clientHeight)
 
 	value, proofBz, proofHeight, err := cc.QueryTendermintProof(ctx, chainHeight, key)
@@ -513,6 +554,16 @@ func (cc *CosmosProvider) QueryClientConsensusState(ctx context.Context, chainHe
 	}, nil
 }
 
+// QueryUpgradePlan returns the upgrade currently scheduled by the upgrade module of the chain,
+// or nil if there is none.
+func (cc *CosmosProvider) QueryUpgradePlan(ctx context.Context) (*upgradetypes.Plan, error) {
+	res, err := upgradetypes.NewQueryClient(cc).CurrentPlan(ctx, &upgradetypes.QueryCurrentPlanRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query current upgrade plan: %w", err)
+	}
+	return res.Plan, nil
+}
+
 // QueryUpgradeProof performs an abci query with the given key and returns the proto encoded merkle proof
 // for the query and the height at which the proof will succeed on a tendermint verifier.
 func (cc *CosmosProvider) QueryUpgradeProof(ctx context.Context, key []byte, height uint64) ([]byte, clienttypes.Height, error) {
@@ -604,7 +655,7 @@ func (cc *CosmosProvider) QueryUpgradedConsState(ctx context.Context, height int
 // QueryConsensusState returns a consensus state for a given chain to be used as a
 // client in another chain, fetches latest height when passed 0 as arg
 func (cc *CosmosProvider) QueryConsensusState(ctx context.Context, height int64) (ibcexported.ConsensusState, int64, error) {
-	commit, err := cc.RPCClient.Commit(ctx, &height)
+	commit, err := cc.node().Commit(ctx, &height)
 	if err != nil {
 		return &tmclient.ConsensusState{}, 0, err
 	}
@@ -613,7 +664,7 @@ func (cc *CosmosProvider) QueryConsensusState(ctx context.Context, height int64)
 	count := 10_000
 
 	nextHeight := height + 1
-	nextVals, err := cc.RPCClient.Validators(ctx, &nextHeight, &page, &count)
+	nextVals, err := cc.node().Validators(ctx, &nextHeight, &page, &count)
 	if err != nil {
 		return &tmclient.ConsensusState{}, 0, err
 	}
@@ -679,6 +730,14 @@ func (cc *CosmosProvider) QueryConnection(ctx context.Context, height int64, con
 }
 
 func (cc *CosmosProvider) queryConnectionABCI(ctx context.Context, height int64, connectionID string) (*conntypes.QueryConnectionResponse, error) {
+	return provider.CachedQuery(ctx, cc.queryCache, provider.QueryTypeConnection, height, connectionID,
+		func(ctx context.Context) (*conntypes.QueryConnectionResponse, error) {
+			return cc.queryConnectionProof(ctx, height, connectionID)
+		},
+	)
+}
+
+func (cc *CosmosProvider) queryConnectionProof(ctx context.Context, height int64, connectionID string) (*conntypes.QueryConnectionResponse, error) {
 	key := host.ConnectionKey(connectionID)
 
 	value, proofBz, proofHeight, err := cc.QueryTendermintProof(ctx, height, key)
@@ -829,6 +888,14 @@ func (cc *CosmosProvider) QueryChannel(ctx context.Context, height int64, channe
 }
 
 func (cc *CosmosProvider) queryChannelABCI(ctx context.Context, height int64, portID, channelID string) (*chantypes.QueryChannelResponse, error) {
+	return provider.CachedQuery(ctx, cc.queryCache, provider.QueryTypeChannel, height, portID+"/"+channelID,
+		func(ctx context.Context) (*chantypes.QueryChannelResponse, error) {
+			return cc.queryChannelProof(ctx, height, portID, channelID)
+		},
+	)
+}
+
+func (cc *CosmosProvider) queryChannelProof(ctx context.Context, height int64, portID, channelID string) (*chantypes.QueryChannelResponse, error) {
 	key := host.ChannelKey(portID, channelID)
 
 	value, proofBz, proofHeight, err := cc.QueryTendermintProof(ctx, height, key)
@@ -855,6 +922,54 @@ func (cc *CosmosProvider) queryChannelABCI(ctx context.Context, height int64, po
 	}, nil
 }
 
+// QueryChannelUpgrade returns the in progress upgrade of a channel along with a proof of it.
+func (cc *CosmosProvider) QueryChannelUpgrade(ctx context.Context, height int64, channelID, portID string) (*chantypes.QueryUpgradeResponse, error) {
+	key := host.ChannelUpgradeKey(portID, channelID)
+
+	value, proofBz, proofHeight, err := cc.QueryTendermintProof(ctx, height, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if upgrade exists
+	if len(value) == 0 {
+		return nil, sdkerrors.Wrapf(chantypes.ErrUpgradeNotFound, "portID (%s), channelID (%s)", portID, channelID)
+	}
+
+	cdc := codec.NewProtoCodec(cc.Cdc.InterfaceRegistry)
+
+	var upgrade chantypes.Upgrade
+	if err := cdc.Unmarshal(value, &upgrade); err != nil {
+		return nil, err
+	}
+
+	return chantypes.NewQueryUpgradeResponse(upgrade, proofBz, proofHeight), nil
+}
+
+// QueryChannelUpgradeError returns the latest upgrade error receipt of a channel along with a proof of it.
+func (cc *CosmosProvider) QueryChannelUpgradeError(ctx context.Context, height int64, channelID, portID string) (*chantypes.QueryUpgradeErrorResponse, error) {
+	key := host.ChannelUpgradeErrorKey(portID, channelID)
+
+	value, proofBz, proofHeight, err := cc.QueryTendermintProof(ctx, height, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if error receipt exists
+	if len(value) == 0 {
+		return nil, sdkerrors.Wrapf(chantypes.ErrUpgradeErrorNotFound, "portID (%s), channelID (%s)", portID, channelID)
+	}
+
+	cdc := codec.NewProtoCodec(cc.Cdc.InterfaceRegistry)
+
+	var errorReceipt chantypes.ErrorReceipt
+	if err := cdc.Unmarshal(value, &errorReceipt); err != nil {
+		return nil, err
+	}
+
+	return chantypes.NewQueryUpgradeErrorResponse(errorReceipt, proofBz, proofHeight), nil
+}
+
 // QueryChannelClient returns the client state of the client supporting a given channel
 func (cc *CosmosProvider) QueryChannelClient(ctx context.Context, height int64, channelid, portid string) (*clienttypes.IdentifiedClientState, error) {
 	qc := chantypes.NewQueryClient(cc)
@@ -1033,6 +1148,10 @@ func (cc *CosmosProvider) QuerySendPacket(
 	srcPortID string,
 	sequence uint64,
 ) (provider.PacketInfo, error) {
+	if pi, ok := cc.indexedPacket(ctx, chantypes.EventTypeSendPacket, srcChanID, srcPortID, sequence); ok {
+		return pi, nil
+	}
+
 	q := sendPacketQuery(srcChanID, srcPortID, sequence)
 
 	ibcMsgs, err := cc.queryIBCMessages(ctx, cc.log, 1, 1000, q)
@@ -1061,6 +1180,10 @@ func (cc *CosmosProvider) QueryRecvPacket(
 	dstPortID string,
 	sequence uint64,
 ) (provider.PacketInfo, error) {
+	if pi, ok := cc.indexedPacket(ctx, chantypes.EventTypeWriteAck, dstChanID, dstPortID, sequence); ok {
+		return pi, nil
+	}
+
 	q := writeAcknowledgementQuery(dstChanID, dstPortID, sequence)
 
 	ibcMsgs, err := cc.queryIBCMessages(ctx, cc.log, 1, 1000, q)
@@ -1083,6 +1206,24 @@ func (cc *CosmosProvider) QueryRecvPacket(
 	return provider.PacketInfo{}, fmt.Errorf("no ibc messages found for write_acknowledgement query: %s", q)
 }
 
+// indexedPacket returns the packet of an event on the channel of the chain from the packet index, if set,
+// and false if the event has not been indexed.
+func (cc *CosmosProvider) indexedPacket(
+	ctx context.Context,
+	eventType, channelID, portID string,
+	sequence uint64,
+) (provider.PacketInfo, bool) {
+	if cc.packetIndex == nil {
+		return provider.PacketInfo{}, false
+	}
+	pi, ok, err := cc.packetIndex.Packet(ctx, cc.ChainId(), eventType, channelID, portID, sequence)
+	if err != nil {
+		cc.log.Warn("Failed to query packet index, falling back to tx_search", zap.Error(err))
+		return provider.PacketInfo{}, false
+	}
+	return pi, ok
+}
+
 // QueryUnreceivedAcknowledgements returns a list of unrelayed packet acks
 func (cc *CosmosProvider) QueryUnreceivedAcknowledgements(ctx context.Context, height uint64, channelid, portid string, seqs []uint64) ([]uint64, error) {
 	qc := chantypes.NewQueryClient(cc)
@@ -1201,18 +1342,18 @@ func (cc *CosmosProvider) QueryPacketReceipt(ctx context.Context, height int64,
 }
 
 func (cc *CosmosProvider) QueryLatestHeight(ctx context.Context) (int64, error) {
-	stat, err := cc.RPCClient.Status(ctx)
+	stat, err := cc.node().Status(ctx)
 	if err != nil {
 		return -1, err
 	} else if stat.SyncInfo.CatchingUp {
-		return -1, fmt.Errorf("node at %s running chain %s not caught up", cc.PCfg.RPCAddr, cc.PCfg.ChainID)
+		return -1, fmt.Errorf("node at %s running chain %s not caught up", cc.RPCAddr(), cc.PCfg.ChainID)
 	}
 	return stat.SyncInfo.LatestBlockHeight, nil
 }
 
 // Query current node status
 func (cc *CosmosProvider) QueryStatus(ctx context.Context) (*coretypes.ResultStatus, error) {
-	status, err := cc.RPCClient.Status(ctx)
+	status, err := cc.node().Status(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query node status: %w", err)
 	}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
 	"math/rand"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -43,6 +43,7 @@ import (
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
 	localhost "github.com/cosmos/ibc-go/v8/modules/light-clients/09-localhost"
+	"github.com/cosmos/relayer/v2/relayer/audit"
 	strideicqtypes "github.com/cosmos/relayer/v2/relayer/chains/cosmos/stride"
 	"github.com/cosmos/relayer/v2/relayer/ethermint"
 	"github.com/cosmos/relayer/v2/relayer/provider"
@@ -59,6 +60,7 @@ var (
 	rtyErr                      = retry.LastErrorOnly(true)
 	accountSeqRegex             = regexp.MustCompile("account sequence mismatch, expected ([0-9]+), got ([0-9]+)")
 	defaultBroadcastWaitTimeout = 10 * time.Minute
+	blockTimeoutHeightInterval  = time.Second
 	errUnknown                  = "unknown"
 )
 
@@ -110,6 +112,14 @@ func ensureSequenceGuard(cc *CosmosProvider, key string) *WalletState {
 // of that transaction will be logged. A boolean indicating if a transaction was successfully
 // sent and executed successfully is returned.
 func (cc *CosmosProvider) SendMessages(ctx context.Context, msgs []provider.RelayerMessage, memo string) (*provider.RelayerTxResponse, bool, error) {
+	if cc.generateOnly != nil {
+		txSignerKey, feegranterKeyOrAddr, err := cc.buildSignerConfig(msgs)
+		if err != nil {
+			return nil, false, err
+		}
+		return nil, false, cc.generateUnsignedTx(ctx, msgs, memo, 0, txSignerKey, feegranterKeyOrAddr)
+	}
+
 	var (
 		rlyResp     *provider.RelayerTxResponse
 		callbackErr error
@@ -168,6 +178,10 @@ func (cc *CosmosProvider) SendMessagesToMempool(
 		return err
 	}
 
+	if cc.generateOnly != nil {
+		return cc.generateUnsignedTx(ctx, msgs, memo, 0, txSignerKey, feegranterKeyOrAddr)
+	}
+
 	sequenceGuard := ensureSequenceGuard(cc, txSignerKey)
 	sequenceGuard.Mu.Lock()
 	defer sequenceGuard.Mu.Unlock()
@@ -194,17 +208,30 @@ func (cc *CosmosProvider) SendMessagesToMempool(
 		return err
 	}
 
+	var auditEntry audit.Entry
+	if cc.auditLog != nil {
+		auditEntry = cc.newAuditEntry(audit.EventBroadcast, txBytes, txSignerKey)
+		asyncCallbacks = append(slices.Clone(asyncCallbacks), cc.auditResultCallback(auditEntry))
+	}
+
 	err = cc.broadcastTx(
 		ctx,
 		txBytes,
 		msgs,
 		fees,
 		asyncCtx,
-		defaultBroadcastWaitTimeout,
+		cc.PCfg.TxInclusionTimeout(),
 		asyncCallbacks,
 		dynamicFee,
 	)
 
+	if cc.auditLog != nil {
+		if err != nil {
+			auditEntry.Error = err.Error()
+		}
+		cc.recordAudit(auditEntry)
+	}
+
 	if err != nil {
 		if strings.Contains(err.Error(), legacyerrors.ErrWrongSequence.Error()) {
 			cc.handleAccountSequenceMismatchError(sequenceGuard, err)
@@ -266,6 +293,10 @@ func (cc *CosmosProvider) AwaitTx(txHash bytes.HexBytes, timeout time.Duration)
 //
 // feegranterKey - key name of the address set as the feegranter, empty string will not feegrant
 func (cc *CosmosProvider) SendMsgsWith(ctx context.Context, msgs []sdk.Msg, memo string, gas uint64, signingKey string, feegranterKey string) (*coretypes.ResultBroadcastTx, error) {
+	if cc.generateOnly != nil {
+		return nil, cc.generateUnsignedTx(ctx, relayerMessages(msgs), memo, gas, signingKey, feegranterKey)
+	}
+
 	sdkConfigMutex.Lock()
 	sdkConf := sdk.GetConfig()
 	sdkConf.SetBech32PrefixForAccount(cc.PCfg.AccountPrefix, cc.PCfg.AccountPrefix+"pub")
@@ -309,11 +340,20 @@ func (cc *CosmosProvider) SendMsgsWith(ctx context.Context, msgs []sdk.Msg, memo
 	// Set the gas amount on the transaction factory
 	txf = txf.WithGas(adjusted)
 
+	// The signer pays the fees unless they are granted
+	if signingKey == feegranterKey || feegranterKey == "" {
+		txf = cc.withFallbackGasPrices(ctx, txf, signingKey, adjusted)
+	}
+
 	// Build the transaction builder
 	txb, err := txf.BuildUnsignedTx(msgs...)
 	if err != nil {
 		return nil, err
 	}
+	fees := txb.GetTx().GetFee()
+	if err := cc.checkFeeCap(fees); err != nil {
+		return nil, err
+	}
 
 	// Attach the signature to the transaction
 	// c.LogFailedTx(nil, err, msgs)
@@ -343,10 +383,28 @@ func (cc *CosmosProvider) SendMsgsWith(ctx context.Context, msgs []sdk.Msg, memo
 		return nil, err
 	}
 
-	res, err := cc.RPCClient.BroadcastTxAsync(ctx, txBytes)
+	res, err := cc.node().BroadcastTxAsync(ctx, txBytes)
 	if res != nil {
 		fmt.Printf("TX hash: %s\n", res.Hash)
 	}
+	if err == nil && res.Code == 0 {
+		cc.feeSpend.add(time.Now(), fees)
+		// the tx is not waited on to be included in a block, so it is recorded without its height and gas used.
+		if cc.txRecorder != nil {
+			cc.recordTx(cc.newAccountingTx(ctx, txBytes))
+		}
+	}
+	if cc.auditLog != nil {
+		auditEntry := cc.newAuditEntry(audit.EventBroadcast, txBytes, signingKey)
+		if res != nil {
+			auditEntry.Code = res.Code
+			auditEntry.Codespace = res.Codespace
+		}
+		if err != nil {
+			auditEntry.Error = err.Error()
+		}
+		cc.recordAudit(auditEntry)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -382,11 +440,11 @@ func (cc *CosmosProvider) broadcastTx(
 	fees sdk.Coins, // used for metrics
 
 	asyncCtx context.Context, // context for async wait for block inclusion after successful tx broadcast
-	asyncTimeout time.Duration, // timeout for waiting for block inclusion
+	asyncTimeout provider.BlockTimeout, // timeout for waiting for block inclusion
 	asyncCallbacks []func(*provider.RelayerTxResponse, error), // callback for success/fail of the wait for block inclusion
 	dynamicFee string,
 ) error {
-	res, err := cc.RPCClient.BroadcastTxSync(ctx, tx)
+	res, err := cc.broadcastTxSync(ctx, tx)
 	isErr := err != nil
 	isFailed := res != nil && res.Code != 0
 	if isErr || isFailed {
@@ -400,6 +458,7 @@ func (cc *CosmosProvider) broadcastTx(
 			Codespace: res.Codespace,
 			Code:      res.Code,
 			Data:      res.Data.String(),
+			Log:       res.Log,
 		}
 		if isFailed {
 			err = cc.sdkError(res.Codespace, res.Code)
@@ -416,11 +475,16 @@ func (cc *CosmosProvider) broadcastTx(
 
 	}
 	cc.UpdateFeesSpent(cc.ChainId(), cc.Key(), address, fees, dynamicFee)
+	cc.feeSpend.add(time.Now(), fees)
+
+	if cc.txRecorder != nil {
+		asyncCallbacks = append(slices.Clone(asyncCallbacks), cc.recordTxCallback(cc.newAccountingTx(ctx, tx)))
+	}
 
 	// TODO: maybe we need to check if the node has tx indexing enabled?
 	// if not, we need to find a new way to block until inclusion in a block
 
-	go cc.waitForTx(asyncCtx, res.Hash, msgs, asyncTimeout, asyncCallbacks)
+	go cc.waitForTx(asyncCtx, res.Hash, msgs, fees, asyncTimeout, asyncCallbacks)
 
 	return nil
 }
@@ -431,11 +495,16 @@ func (cc *CosmosProvider) waitForTx(
 	ctx context.Context,
 	txHash []byte,
 	msgs []provider.RelayerMessage, // used for logging only
-	waitTimeout time.Duration,
+	fees sdk.Coins, // used for accounting only
+	waitTimeout provider.BlockTimeout,
 	callbacks []func(*provider.RelayerTxResponse, error),
 ) {
 	res, err := cc.waitForBlockInclusion(ctx, txHash, waitTimeout)
 	if err != nil {
+		if errors.Is(err, ErrTimeoutAfterWaitingForTxBroadcast) && cc.packetsRelayed(ctx, msgs) {
+			// the tx is lost, but its packets were relayed by another tx, so they must not be resent.
+			err = sdkerrors.Wrapf(chantypes.ErrRedundantTx, "packet messages of tx %X were already relayed: %v", txHash, err)
+		}
 		cc.log.Error("Failed to wait for block inclusion", zap.Error(err))
 		if len(callbacks) > 0 {
 			for _, cb := range callbacks {
@@ -452,7 +521,11 @@ func (cc *CosmosProvider) waitForTx(
 		Codespace: res.Codespace,
 		Code:      res.Code,
 		Data:      res.Data,
+		Log:       res.RawLog,
 		Events:    parseEventsFromTxResponse(res),
+		GasWanted: res.GasWanted,
+		GasUsed:   res.GasUsed,
+		Fees:      fees,
 	}
 
 	// transaction was executed, log the success or failure using the tx response code
@@ -467,8 +540,9 @@ func (cc *CosmosProvider) waitForTx(
 		}
 		if len(callbacks) > 0 {
 			for _, cb := range callbacks {
-				//Call each callback in order since waitForTx is already invoked asynchronously
-				cb(nil, err)
+				//Call each callback in order since waitForTx is already invoked asynchronously.
+				// The response is included since fees are still paid for transactions which failed to execute.
+				cb(rlyResp, err)
 			}
 		}
 		cc.LogFailedTx(rlyResp, nil, msgs)
@@ -484,20 +558,85 @@ func (cc *CosmosProvider) waitForTx(
 	cc.LogSuccessTx(res, msgs)
 }
 
-// waitForBlockInclusion will wait for a transaction to be included in a block, up to waitTimeout or context cancellation.
+// waitForBlockInclusion will wait for a transaction to be included in a block, up to the timeout or context cancellation.
+// A timeout in blocks runs out once that many blocks have been committed on the chain since the wait started.
+// Once the timeout runs out, the recent blocks and the mempool of the node are checked for the transaction,
+// and the wait is extended up to mempoolWaitExtensions times while the transaction is merely slow.
 func (cc *CosmosProvider) waitForBlockInclusion(
 	ctx context.Context,
 	txHash []byte,
-	waitTimeout time.Duration,
+	timeout provider.BlockTimeout,
 ) (*sdk.TxResponse, error) {
-	exitAfter := time.After(waitTimeout)
+	var (
+		exitAfter   <-chan time.Time
+		heightCheck <-chan time.Time
+		waitFrom    int64
+		extensions  int
+	)
+	waitTimeout := timeout.Duration
+	if waitTimeout == 0 {
+		waitTimeout = defaultBroadcastWaitTimeout
+	}
+	startHeight, err := cc.QueryLatestHeight(ctx)
+	if timeout.Blocks > 0 {
+		if err == nil {
+			waitFrom = startHeight
+			ticker := time.NewTicker(blockTimeoutHeightInterval)
+			defer ticker.Stop()
+			heightCheck = ticker.C
+		} else {
+			cc.log.Debug("Failed to query latest height, waiting for block inclusion with the default timeout", zap.Error(err))
+		}
+	}
+	if heightCheck == nil {
+		exitAfter = time.After(waitTimeout)
+	}
+
+	// timedOut returns the result of the tx if it was included in a block after all, or else whether to keep waiting.
+	timedOut := func() (*coretypes.ResultTx, bool) {
+		res, inMempool := cc.recheckTx(ctx, txHash, startHeight)
+		if res != nil || !inMempool || extensions >= mempoolWaitExtensions {
+			return res, false
+		}
+		extensions++
+		cc.log.Info("Tx still in mempool after timeout, waiting longer for block inclusion",
+			zap.String("chain_id", cc.PCfg.ChainID),
+			zap.String("tx_hash", fmt.Sprintf("%X", txHash)),
+			zap.Int("extension", extensions),
+		)
+		return nil, true
+	}
+
 	for {
 		select {
 		case <-exitAfter:
+			res, wait := timedOut()
+			if res != nil {
+				return cc.mkTxResult(res)
+			}
+			if wait {
+				exitAfter = time.After(waitTimeout)
+				continue
+			}
 			return nil, fmt.Errorf("timed out after: %d; %w", waitTimeout, ErrTimeoutAfterWaitingForTxBroadcast)
+		case <-heightCheck:
+			h, err := cc.QueryLatestHeight(ctx)
+			if err != nil || h-waitFrom < int64(timeout.Blocks) {
+				continue
+			}
+			// the tx may have been included in the latest block since it was last polled.
+			res, wait := timedOut()
+			if res != nil {
+				return cc.mkTxResult(res)
+			}
+			if wait {
+				waitFrom = h
+				continue
+			}
+			return nil, fmt.Errorf("timed out after: %d blocks; %w", timeout.Blocks, ErrTimeoutAfterWaitingForTxBroadcast)
 		// This fixed poll is fine because it's only for logging and updating prometheus metrics currently.
 		case <-time.After(time.Millisecond * 100):
-			res, err := cc.RPCClient.Tx(ctx, txHash, false)
+			res, err := cc.node().Tx(ctx, txHash, false)
 			if err == nil {
 				return cc.mkTxResult(res)
 			}
@@ -574,19 +713,16 @@ func (cc *CosmosProvider) buildSignerConfig(msgs []provider.RelayerMessage) (
 	defer cc.feegrantMu.Unlock()
 
 	// Some messages have feegranting disabled. If any message in the TX disables feegrants, then the TX will not be feegranted.
-	isFeegrantEligible := cc.PCfg.FeeGrants != nil
-
-	for _, curr := range msgs {
-		if cMsg, ok := curr.(CosmosMessage); ok {
-			if cMsg.FeegrantDisabled {
-				isFeegrantEligible = false
-			}
-		}
-	}
+	isFeegrantEligible := cc.PCfg.FeeGrants != nil && !feegrantDisabled(msgs)
 
 	// By default, we should sign TXs with the provider's default key
 	txSignerKey = cc.PCfg.Key
 
+	// The fees of TXs signed by the default key are paid by the sponsor account, if configured.
+	if cc.PCfg.FeeGranter != "" && cc.PCfg.FeeGrants == nil && !feegrantDisabled(msgs) {
+		feegranterKeyOrAddr = cc.PCfg.FeeGranter
+	}
+
 	if isFeegrantEligible {
 		txSignerKey, feegranterKeyOrAddr = cc.GetTxFeeGrant()
 		signerAcc, addrErr := cc.GetKeyAddressForKey(txSignerKey)
@@ -611,9 +747,23 @@ func (cc *CosmosProvider) buildSignerConfig(msgs []provider.RelayerMessage) (
 		}
 	}
 
+	if cc.PCfg.AuthzGranter != "" {
+		cc.setAuthzSigner(msgs)
+	}
+
 	return
 }
 
+// feegrantDisabled returns true if feegranting is disabled for any of msgs.
+func feegrantDisabled(msgs []provider.RelayerMessage) bool {
+	for _, curr := range msgs {
+		if cMsg, ok := curr.(CosmosMessage); ok && cMsg.FeegrantDisabled {
+			return true
+		}
+	}
+	return false
+}
+
 func (cc *CosmosProvider) buildMessages(
 	ctx context.Context,
 	msgs []provider.RelayerMessage,
@@ -632,18 +782,60 @@ func (cc *CosmosProvider) buildMessages(
 	done := cc.SetSDKContext()
 	defer done()
 
+	txf, txb, err := cc.buildUnsignedTx(ctx, msgs, memo, gas, txSignerKey, feegranterKeyOrAddr, sequenceGuard, dynamicFee)
+	if err != nil {
+		return nil, 0, sdk.Coins{}, err
+	}
+
+	if err = tx.Sign(ctx, txf, txSignerKey, txb, false); err != nil {
+		return nil, 0, sdk.Coins{}, err
+	}
+
+	tx := txb.GetTx()
+	fees = tx.GetFee()
+
+	// Generate the transaction bytes
+	txBytes, err = cc.Cdc.TxConfig.TxEncoder()(tx)
+	if err != nil {
+		return nil, 0, sdk.Coins{}, err
+	}
+
+	return txBytes, txf.Sequence(), fees, nil
+}
+
+// buildUnsignedTx builds the tx of msgs with its gas and fees set, ready to be signed with the returned factory.
+// The caller must hold the SDK context of the provider.
+func (cc *CosmosProvider) buildUnsignedTx(
+	ctx context.Context,
+	msgs []provider.RelayerMessage,
+	memo string,
+	gas uint64,
+	txSignerKey string,
+	feegranterKeyOrAddr string,
+	sequenceGuard *WalletState,
+	dynamicFee string,
+) (tx.Factory, client.TxBuilder, error) {
 	cMsgs := CosmosMsgs(msgs...)
 
+	// The messages which relay packets are signed on behalf of the authz-granter, so the key executes them with MsgExec.
+	if cc.PCfg.AuthzGranter != "" {
+		var err error
+		cMsgs, err = cc.authzExec(txSignerKey, cMsgs)
+		if err != nil {
+			return tx.Factory{}, nil, err
+		}
+	}
+
 	txf, err := cc.PrepareFactory(cc.TxFactory(dynamicFee), txSignerKey)
 	if err != nil {
-		return nil, 0, sdk.Coins{}, err
+		return tx.Factory{}, nil, err
 	}
 
 	if memo != "" {
 		txf = txf.WithMemo(memo)
 	}
 
-	sequence = txf.Sequence()
+	sequence := txf.Sequence()
 	cc.updateNextAccountSequence(sequenceGuard, sequence)
 	if sequence < sequenceGuard.NextAccountSequence {
 		sequence = sequenceGuard.NextAccountSequence
@@ -653,15 +845,20 @@ func (cc *CosmosProvider) buildMessages(
 	// Cannot feegrant your own TX
 	if txSignerKey != feegranterKeyOrAddr && feegranterKeyOrAddr != "" {
 		var granterAddr sdk.AccAddress
-		if cc.PCfg.FeeGrants != nil && cc.PCfg.FeeGrants.IsExternalGranter {
+		if feegranterKeyOrAddr == cc.PCfg.FeeGranter {
+			granterAddr, err = cc.AccountFromKeyOrAddress(feegranterKeyOrAddr)
+			if err != nil {
+				return tx.Factory{}, nil, fmt.Errorf("invalid fee-granter %s: %w", feegranterKeyOrAddr, err)
+			}
+		} else if cc.PCfg.FeeGrants != nil && cc.PCfg.FeeGrants.IsExternalGranter {
 			granterAddr, err = cc.DecodeBech32AccAddr(feegranterKeyOrAddr)
 			if err != nil {
-				return nil, 0, sdk.Coins{}, err
+				return tx.Factory{}, nil, err
 			}
 		} else {
 			granterAddr, err = cc.GetKeyAddressForKey(feegranterKeyOrAddr)
 			if err != nil {
-				return nil, 0, sdk.Coins{}, err
+				return tx.Factory{}, nil, err
 			}
 		}
 
@@ -674,33 +871,28 @@ func (cc *CosmosProvider) buildMessages(
 		_, adjusted, err = cc.CalculateGas(ctx, txf, txSignerKey, cMsgs...)
 
 		if err != nil {
-			return nil, 0, sdk.Coins{}, err
+			return tx.Factory{}, nil, err
 		}
 	}
 
 	// Set the gas amount on the transaction factory
 	txf = txf.WithGas(adjusted)
 
+	// The signer pays the fees unless they are granted
+	if txSignerKey == feegranterKeyOrAddr || feegranterKeyOrAddr == "" {
+		txf = cc.withFallbackGasPrices(ctx, txf, txSignerKey, adjusted)
+	}
+
 	// Build the transaction builder
 	txb, err := txf.BuildUnsignedTx(cMsgs...)
 	if err != nil {
-		return nil, 0, sdk.Coins{}, err
+		return tx.Factory{}, nil, err
 	}
-
-	if err = tx.Sign(ctx, txf, txSignerKey, txb, false); err != nil {
-		return nil, 0, sdk.Coins{}, err
+	if err := cc.checkFeeCap(txb.GetTx().GetFee()); err != nil {
+		return tx.Factory{}, nil, err
 	}
 
-	tx := txb.GetTx()
-	fees = tx.GetFee()
-
-	// Generate the transaction bytes
-	txBytes, err = cc.Cdc.TxConfig.TxEncoder()(tx)
-	if err != nil {
-		return nil, 0, sdk.Coins{}, err
-	}
-
-	return txBytes, txf.Sequence(), fees, nil
+	return txf, txb, nil
 }
 
 // handleAccountSequenceMismatchError will parse the error string, e.g.:
@@ -795,6 +987,7 @@ func (cc *CosmosProvider) MsgUpgradeClient(srcClientId string, consRes *clientty
 func (cc *CosmosProvider) MsgTransfer(
 	dstAddr string,
 	amount sdk.Coin,
+	memo string,
 	info provider.PacketInfo,
 ) (provider.RelayerMessage, error) {
 	acc, err := cc.Address()
@@ -808,6 +1001,7 @@ func (cc *CosmosProvider) MsgTransfer(
 		Sender:           acc,
 		Receiver:         dstAddr,
 		TimeoutTimestamp: info.TimeoutTimestamp,
+		Memo:             memo,
 	}
 
 	// If the timeoutHeight is 0 then we don't need to explicitly set it on the MsgTransfer
@@ -820,6 +1014,20 @@ func (cc *CosmosProvider) MsgTransfer(
 	return msgTransfer, nil
 }
 
+// MsgFromJSON decodes a JSON-encoded sdk.Msg, identified by its "@type" field, into a RelayerMessage.
+// The message type must be registered with the chain's codec, e.g. through the configured modules.
+// This allows broadcasting messages from custom IBC modules that send packets on arbitrary ports.
+func (cc *CosmosProvider) MsgFromJSON(bz []byte) (provider.RelayerMessage, error) {
+	var msg sdk.Msg
+	if err := cc.Cdc.Marshaler.UnmarshalInterfaceJSON(bz, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message json: %w", err)
+	}
+
+	cosmosMsg := NewCosmosMessage(msg, nil).(CosmosMessage)
+	cosmosMsg.FeegrantDisabled = true
+	return cosmosMsg, nil
+}
+
 func (cc *CosmosProvider) ValidatePacket(msgTransfer provider.PacketInfo, latest provider.LatestBlock) error {
 	if msgTransfer.Sequence == 0 {
 		return errors.New("refusing to relay packet with sequence: 0")
@@ -834,17 +1042,7 @@ func (cc *CosmosProvider) ValidatePacket(msgTransfer provider.PacketInfo, latest
 		return errors.New("refusing to relay packet without a timeout (height or timestamp must be set)")
 	}
 
-	revision := clienttypes.ParseChainID(cc.PCfg.ChainID)
-	latestClientTypesHeight := clienttypes.NewHeight(revision, latest.Height)
-	if !msgTransfer.TimeoutHeight.IsZero() && latestClientTypesHeight.GTE(msgTransfer.TimeoutHeight) {
-		return provider.NewTimeoutHeightError(latest.Height, msgTransfer.TimeoutHeight.RevisionHeight)
-	}
-	latestTimestamp := uint64(latest.Time.UnixNano())
-	if msgTransfer.TimeoutTimestamp > 0 && latestTimestamp > msgTransfer.TimeoutTimestamp {
-		return provider.NewTimeoutTimestampError(latestTimestamp, msgTransfer.TimeoutTimestamp)
-	}
-
-	return nil
+	return msgTransfer.TimeoutElapsed(clienttypes.ParseChainID(cc.PCfg.ChainID), latest)
 }
 
 func (cc *CosmosProvider) PacketCommitment(
@@ -1166,7 +1364,7 @@ func (cc *CosmosProvider) MsgChannelOpenInit(info provider.ChannelInfo, proof pr
 				PortId:    info.CounterpartyPortID,
 				ChannelId: "",
 			},
-			ConnectionHops: []string{info.ConnID},
+			ConnectionHops: info.Hops(),
 			Version:        info.Version,
 		},
 		Signer: signer,
@@ -1209,7 +1407,7 @@ func (cc *CosmosProvider) MsgChannelOpenTry(msgOpenInit provider.ChannelInfo, pr
 				PortId:    msgOpenInit.PortID,
 				ChannelId: msgOpenInit.ChannelID,
 			},
-			ConnectionHops: []string{msgOpenInit.CounterpartyConnID},
+			ConnectionHops: msgOpenInit.CounterpartyHops(),
 			// In the future, may need to separate this from the CounterpartyVersion.
 			// https://github.com/cosmos/ibc/tree/master/spec/core/ics-004-channel-and-packet-semantics#definitions
 			// Using same version as counterparty for now.
@@ -1298,6 +1496,176 @@ func (cc *CosmosProvider) MsgChannelCloseConfirm(msgCloseInit provider.ChannelIn
 	}), nil
 }
 
+func (cc *CosmosProvider) ChannelUpgradeProof(
+	ctx context.Context,
+	msg provider.ChannelInfo,
+	height uint64,
+) (provider.ChannelUpgradeProof, error) {
+	channelRes, err := cc.QueryChannel(ctx, int64(height), msg.ChannelID, msg.PortID)
+	if err != nil {
+		return provider.ChannelUpgradeProof{}, err
+	}
+	proof := provider.ChannelUpgradeProof{
+		Channel:      *channelRes.Channel,
+		ChannelProof: channelRes.Proof,
+		ProofHeight:  channelRes.ProofHeight,
+	}
+
+	upgradeRes, err := cc.QueryChannelUpgrade(ctx, int64(height), msg.ChannelID, msg.PortID)
+	switch {
+	case errors.Is(err, chantypes.ErrUpgradeNotFound):
+		// the upgrade is removed once the channel is open again,
+		// in which case only the channel state is needed.
+	case err != nil:
+		return provider.ChannelUpgradeProof{}, err
+	default:
+		proof.Upgrade = upgradeRes.Upgrade
+		proof.UpgradeProof = upgradeRes.Proof
+	}
+
+	return proof, nil
+}
+
+func (cc *CosmosProvider) ChannelUpgradeErrorProof(
+	ctx context.Context,
+	msgUpgradeError provider.ChannelInfo,
+	height uint64,
+) (provider.ChannelUpgradeProof, error) {
+	errorRes, err := cc.QueryChannelUpgradeError(ctx, int64(height), msgUpgradeError.ChannelID, msgUpgradeError.PortID)
+	if err != nil {
+		return provider.ChannelUpgradeProof{}, err
+	}
+	return provider.ChannelUpgradeProof{
+		ErrorReceipt:      errorRes.ErrorReceipt,
+		ErrorReceiptProof: errorRes.Proof,
+		ProofHeight:       errorRes.ProofHeight,
+	}, nil
+}
+
+func (cc *CosmosProvider) MsgChannelUpgradeTry(msgUpgradeInit provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	if msgUpgradeInit.CounterpartyConnID == "" {
+		return nil, fmt.Errorf("counterparty connection of proposed connection hop %s is unknown", msgUpgradeInit.ConnID)
+	}
+	msg := chantypes.NewMsgChannelUpgradeTry(
+		msgUpgradeInit.CounterpartyPortID,
+		msgUpgradeInit.CounterpartyChannelID,
+		[]string{msgUpgradeInit.CounterpartyConnID},
+		proof.Upgrade.Fields,
+		proof.Channel.UpgradeSequence,
+		proof.ChannelProof,
+		proof.UpgradeProof,
+		proof.ProofHeight,
+		signer,
+	)
+
+	return NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (cc *CosmosProvider) MsgChannelUpgradeAck(msgUpgradeTry provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := chantypes.NewMsgChannelUpgradeAck(
+		msgUpgradeTry.CounterpartyPortID,
+		msgUpgradeTry.CounterpartyChannelID,
+		proof.Upgrade,
+		proof.ChannelProof,
+		proof.UpgradeProof,
+		proof.ProofHeight,
+		signer,
+	)
+
+	return NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (cc *CosmosProvider) MsgChannelUpgradeConfirm(msgUpgradeAck provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := chantypes.NewMsgChannelUpgradeConfirm(
+		msgUpgradeAck.CounterpartyPortID,
+		msgUpgradeAck.CounterpartyChannelID,
+		proof.Channel.State,
+		proof.Upgrade,
+		proof.ChannelProof,
+		proof.UpgradeProof,
+		proof.ProofHeight,
+		signer,
+	)
+
+	return NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (cc *CosmosProvider) MsgChannelUpgradeOpen(info provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := chantypes.NewMsgChannelUpgradeOpen(
+		info.CounterpartyPortID,
+		info.CounterpartyChannelID,
+		proof.Channel.State,
+		proof.Channel.UpgradeSequence,
+		proof.ChannelProof,
+		proof.ProofHeight,
+		signer,
+	)
+
+	return NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (cc *CosmosProvider) MsgChannelUpgradeTimeout(info provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := chantypes.NewMsgChannelUpgradeTimeout(
+		info.CounterpartyPortID,
+		info.CounterpartyChannelID,
+		proof.Channel,
+		proof.ChannelProof,
+		proof.ProofHeight,
+		signer,
+	)
+
+	return NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (cc *CosmosProvider) MsgChannelUpgradeCancel(msgUpgradeError provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	signer, err := cc.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := chantypes.NewMsgChannelUpgradeCancel(
+		msgUpgradeError.CounterpartyPortID,
+		msgUpgradeError.CounterpartyChannelID,
+		proof.ErrorReceipt,
+		proof.ErrorReceiptProof,
+		proof.ProofHeight,
+		signer,
+	)
+
+	return NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
 func (cc *CosmosProvider) MsgUpdateClientHeader(latestHeader provider.IBCHeader, trustedHeight clienttypes.Height, trustedHeader provider.IBCHeader) (ibcexported.ClientMessage, error) {
 	trustedCosmosHeader, ok := trustedHeader.(provider.TendermintIBCHeader)
 	if !ok {
@@ -1477,7 +1845,7 @@ func (cc *CosmosProvider) QueryIBCHeader(ctx context.Context, h int64) (provider
 		return nil, fmt.Errorf("height cannot be 0")
 	}
 
-	lightBlock, err := cc.LightProvider.LightBlock(ctx, h)
+	lightBlock, err := cc.lightProvider().LightBlock(ctx, h)
 	if err != nil {
 		return nil, err
 	}
@@ -1724,17 +2092,7 @@ func (cc *CosmosProvider) PrepareFactory(txf tx.Factory, signingKey string) (tx.
 // and return estimated gas is higher than max gas error. If the gas usage is zero, the adjusted gas
 // is also zero.
 func (cc *CosmosProvider) AdjustEstimatedGas(gasUsed uint64) (uint64, error) {
-	if gasUsed == 0 {
-		return gasUsed, nil
-	}
-	if cc.PCfg.MaxGasAmount > 0 && gasUsed > cc.PCfg.MaxGasAmount {
-		return 0, fmt.Errorf("estimated gas %d is higher than max gas %d", gasUsed, cc.PCfg.MaxGasAmount)
-	}
-	gas := cc.PCfg.GasAdjustment * float64(gasUsed)
-	if math.IsInf(gas, 1) {
-		return 0, fmt.Errorf("infinite gas used")
-	}
-	return uint64(gas), nil
+	return cc.adjustEstimatedGas(gasUsed, cc.PCfg.GasAdjustment)
 }
 
 // SetWithExtensionOptions sets the dynamic fee extension options on the given
@@ -1799,7 +2157,22 @@ func (cc *CosmosProvider) CalculateGas(ctx context.Context, txf tx.Factory, sign
 		}
 		return nil
 	}, retry.Context(ctx), rtyAtt, rtyDel, rtyErr); err != nil {
-		return txtypes.SimulateResponse{}, 0, err
+		// Errors of the simulation itself are returned by the node as a status, e.g. when the tx would fail.
+		// Otherwise the simulation endpoint is unavailable, and the gas may be estimated from previous simulations.
+		if _, ok := status.FromError(err); ok || ctx.Err() != nil {
+			return txtypes.SimulateResponse{}, 0, err
+		}
+		gasUsed, ok := cc.gasTable.estimate(msgs)
+		if !ok {
+			return txtypes.SimulateResponse{}, 0, err
+		}
+		cc.log.Warn(
+			"Failed to simulate tx, estimating gas from previous simulations",
+			zap.Uint64("gas_used", gasUsed),
+			zap.Error(err),
+		)
+		gas, err := cc.adjustEstimatedGas(gasUsed, cc.gasAdjustment(msgs))
+		return txtypes.SimulateResponse{}, gas, err
 	}
 
 	var simRes txtypes.SimulateResponse
@@ -1807,7 +2180,9 @@ func (cc *CosmosProvider) CalculateGas(ctx context.Context, txf tx.Factory, sign
 		return txtypes.SimulateResponse{}, 0, err
 	}
 
-	gas, err := cc.AdjustEstimatedGas(simRes.GasInfo.GasUsed)
+	cc.gasTable.record(msgs, simRes.GasInfo.GasUsed)
+
+	gas, err := cc.adjustEstimatedGas(simRes.GasInfo.GasUsed, cc.gasAdjustment(msgs))
 	return simRes, gas, err
 }
 
@@ -1833,10 +2208,12 @@ func (cc *CosmosProvider) TxFactory(dynamicFee string) tx.Factory {
 func (pc *CosmosProviderConfig) SignMode() signing.SignMode {
 	signMode := signing.SignMode_SIGN_MODE_UNSPECIFIED
 	switch pc.SignModeStr {
-	case "direct":
+	case SignModeDirect:
 		signMode = signing.SignMode_SIGN_MODE_DIRECT
-	case "amino-json":
+	case SignModeAminoJSON:
 		signMode = signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+	case SignModeTextual:
+		signMode = signing.SignMode_SIGN_MODE_TEXTUAL
 	}
 	return signMode
 }
@@ -1848,7 +2225,7 @@ func (cc *CosmosProvider) QueryABCI(ctx context.Context, req abci.RequestQuery)
 		Prove:  req.Prove,
 	}
 
-	result, err := cc.RPCClient.ABCIQueryWithOptions(ctx, req.Path, req.Data, opts)
+	result, err := cc.node().ABCIQueryWithOptions(ctx, req.Path, req.Data, opts)
 	if err != nil {
 		return abci.ResponseQuery{}, err
 	}
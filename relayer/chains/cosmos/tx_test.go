@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	sdkmath "cosmossdk.io/math"
 	"github.com/cosmos/cosmos-sdk/client"
@@ -12,6 +13,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec/testutil"
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	authTx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	"github.com/cosmos/relayer/v2/relayer/ethermint"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"github.com/stretchr/testify/require"
@@ -121,6 +124,68 @@ func TestSetWithExtensionOptions(t *testing.T) {
 	}
 }
 
+func TestCosmosProvider_ValidatePacketTimeout(t *testing.T) {
+	cc := &CosmosProvider{PCfg: CosmosProviderConfig{ChainID: "chain-1"}}
+	blockTime := time.Unix(1700000000, 0)
+	latest := provider.LatestBlock{Height: 100, Time: blockTime}
+
+	packet := provider.PacketInfo{
+		Sequence: 1,
+		Data:     []byte("data"),
+	}
+
+	testCases := []struct {
+		name             string
+		timeoutHeight    uint64
+		timeoutTimestamp uint64
+		expectedErr      error
+	}{
+		{
+			name:          "height not elapsed",
+			timeoutHeight: 101,
+		},
+		{
+			name:          "height elapsed",
+			timeoutHeight: 100,
+			expectedErr:   provider.NewTimeoutHeightError(100, 100),
+		},
+		{
+			name:             "timestamp not elapsed",
+			timeoutTimestamp: uint64(blockTime.UnixNano()) + 1,
+		},
+		{
+			name:             "timestamp elapsed at block time",
+			timeoutTimestamp: uint64(blockTime.UnixNano()),
+			expectedErr:      provider.NewTimeoutTimestampError(uint64(blockTime.UnixNano()), uint64(blockTime.UnixNano())),
+		},
+		{
+			name:             "timestamp elapsed before height",
+			timeoutHeight:    200,
+			timeoutTimestamp: uint64(blockTime.Add(-time.Minute).UnixNano()),
+			expectedErr: provider.NewTimeoutTimestampError(
+				uint64(blockTime.UnixNano()), uint64(blockTime.Add(-time.Minute).UnixNano()),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := packet
+			if tc.timeoutHeight > 0 {
+				p.TimeoutHeight = clienttypes.NewHeight(1, tc.timeoutHeight)
+			}
+			p.TimeoutTimestamp = tc.timeoutTimestamp
+
+			err := cc.ValidatePacket(p, latest)
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tc.expectedErr, err)
+			}
+		})
+	}
+}
+
 type mockTxConfig struct {
 	client.TxConfig
 	txBuilder *mockTxBuilder
@@ -155,3 +220,66 @@ func makeMockTxConfig() mockTxConfig {
 		TxConfig: makeTxConfig(),
 	}
 }
+
+func TestCosmosProvider_MsgFromJSON(t *testing.T) {
+	cc := &CosmosProvider{
+		PCfg: CosmosProviderConfig{AccountPrefix: "cosmos"},
+		Cdc:  MakeCodec(ModuleBasics, nil, "cosmos", "cosmosvaloper"),
+	}
+
+	tests := []struct {
+		name    string
+		msg     string
+		wantErr string
+	}{
+		{
+			name: "registered message",
+			msg: `{
+				"@type": "/ibc.applications.transfer.v1.MsgTransfer",
+				"source_port": "wasm.cosmos14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s4hmalr",
+				"source_channel": "channel-3",
+				"token": {"denom": "stake", "amount": "1"},
+				"sender": "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+				"receiver": "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk",
+				"timeout_height": {"revision_number": "1", "revision_height": "500"},
+				"timeout_timestamp": "0"
+			}`,
+		},
+		{
+			name:    "unknown type url",
+			msg:     `{"@type": "/mymodule.v1.MsgSendPacket", "sender": "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk"}`,
+			wantErr: "failed to decode message json",
+		},
+		{
+			name:    "missing type url",
+			msg:     `{"sender": "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk"}`,
+			wantErr: "failed to decode message json",
+		},
+		{
+			name:    "malformed json",
+			msg:     `{"@type": "/ibc.applications.transfer.v1.MsgTransfer", "source_port": `,
+			wantErr: "failed to decode message json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := cc.MsgFromJSON([]byte(tt.msg))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			cosmosMsg, ok := msg.(CosmosMessage)
+			require.True(t, ok)
+			require.True(t, cosmosMsg.FeegrantDisabled)
+
+			transfer, ok := cosmosMsg.Msg.(*transfertypes.MsgTransfer)
+			require.True(t, ok)
+			require.Equal(t, "channel-3", transfer.SourceChannel)
+			require.Equal(t, clienttypes.NewHeight(1, 500), transfer.TimeoutHeight)
+			require.Equal(t, "/ibc.applications.transfer.v1.MsgTransfer", msg.Type())
+		})
+	}
+}
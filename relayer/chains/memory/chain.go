@@ -0,0 +1,323 @@
+// Package memory implements a ChainProvider and ChainProcessor for in-memory chains, which simulate the IBC
+// handshakes and packet flows of IBC-connected chains without networks, so that the relaying logic can be unit
+// tested.
+//
+// A Chain keeps the IBC state of a chain and executes the IBC messages sent to it, one tx per block, emitting the
+// same events as ibc-go. Clients track their counterparty through real 07-tendermint client and consensus states,
+// but there are no merkle proofs: a proof is the value stored on the chain under the proven key, which the
+// counterparty checks against the message, along with the consensus state of its client at the proof height.
+// Queries are answered from the latest state of the chain, whatever the height they are made at.
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// DefaultUnbondingPeriod is the unbonding period of a Chain.
+const DefaultUnbondingPeriod = 21 * 24 * time.Hour
+
+// nextValidatorsHash is the hash of the validator set of every Chain, which never changes.
+var nextValidatorsHash = func() []byte {
+	h := sha256.Sum256([]byte("memory"))
+	return h[:]
+}()
+
+// Chain is an in-memory chain. It is safe for concurrent use.
+type Chain struct {
+	chainID  string
+	revision uint64
+
+	mu     sync.Mutex
+	blocks []block
+	txs    map[string]*provider.RelayerTxResponse
+	state  *state
+}
+
+// block is a block of a Chain, with the events of its successful txs.
+type block struct {
+	header Header
+	events []abci.Event
+}
+
+// NewChain returns a Chain with chainID, at height 1.
+func NewChain(chainID string) *Chain {
+	c := &Chain{
+		chainID:  chainID,
+		revision: clienttypes.ParseChainID(chainID),
+		txs:      make(map[string]*provider.RelayerTxResponse),
+		state:    newState(),
+	}
+	c.commit(c.nextHeader(), nil)
+	return c
+}
+
+// ChainID returns the chain ID of the chain.
+func (c *Chain) ChainID() string {
+	return c.chainID
+}
+
+// LatestHeight returns the height of the latest block of the chain.
+func (c *Chain) LatestHeight() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(len(c.blocks))
+}
+
+// Commit produces an empty block.
+func (c *Chain) Commit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commit(c.nextHeader(), nil)
+}
+
+// Run produces an empty block every blockTime until ctx is done, so that the clients of the chain can be updated
+// while no txs are sent to it. The block time should be longer than the poll interval of the ChainProcessors of
+// the chain, for them to be in sync with it.
+func (c *Chain) Run(ctx context.Context, blockTime time.Duration) {
+	ticker := time.NewTicker(blockTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Commit()
+		}
+	}
+}
+
+// Header returns the header of the block at height.
+func (c *Chain) Header(height int64) (Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, err := c.block(height)
+	if err != nil {
+		return Header{}, err
+	}
+	return b.header, nil
+}
+
+// Events returns the events of the successful txs of the block at height.
+func (c *Chain) Events(height int64) ([]abci.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, err := c.block(height)
+	if err != nil {
+		return nil, err
+	}
+	return b.events, nil
+}
+
+// DeliverTx executes msgs as a tx in a new block. The msgs are executed atomically: if any of them fails, the
+// tx is included in the block with a non-zero code and none of the msgs is applied, and the error is returned
+// along with the response.
+func (c *Chain) DeliverTx(msgs []sdk.Msg) (*provider.RelayerTxResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	height := int64(len(c.blocks)) + 1
+	txHash := c.txHash(height, msgs)
+	res := &provider.RelayerTxResponse{Height: height, TxHash: txHash}
+	c.txs[txHash] = res
+
+	x := &execution{
+		chain:  c,
+		state:  c.state.clone(),
+		header: c.nextHeader(),
+	}
+	for i, msg := range msgs {
+		if err := x.execute(msg); err != nil {
+			err = fmt.Errorf("failed to execute message %d (%s): %w", i, sdk.MsgTypeURL(msg), err)
+			res.Code = 1
+			res.Log = err.Error()
+			c.commit(x.header, nil)
+			return res, err
+		}
+	}
+
+	c.state = x.state
+	for _, event := range x.events {
+		res.Events = append(res.Events, relayerEvent(event))
+	}
+	c.commit(x.header, x.events)
+	return res, nil
+}
+
+// tx returns the response of the tx with hash.
+func (c *Chain) tx(hash string) (*provider.RelayerTxResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res, ok := c.txs[hash]
+	if !ok {
+		return nil, fmt.Errorf("tx %s not found on chain %s", hash, c.chainID)
+	}
+	return res, nil
+}
+
+// searchTxs returns the responses of the txs for which match returns true, ordered by height.
+func (c *Chain) searchTxs(match func(*provider.RelayerTxResponse) bool) []*provider.RelayerTxResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []*provider.RelayerTxResponse
+	for _, res := range c.txs {
+		if match(res) {
+			out = append(out, res)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Height < out[j].Height })
+	return out
+}
+
+// query calls f with the latest state of the chain and the latest height.
+func (c *Chain) query(f func(st *state, height int64) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return f(c.state, int64(len(c.blocks)))
+}
+
+func (c *Chain) block(height int64) (block, error) {
+	if height < 1 || height > int64(len(c.blocks)) {
+		return block{}, fmt.Errorf("no block at height %d on chain %s, latest height is %d", height, c.chainID, len(c.blocks))
+	}
+	return c.blocks[height-1], nil
+}
+
+// nextHeader returns the header of the next block. Block times are strictly increasing.
+func (c *Chain) nextHeader() Header {
+	height := uint64(len(c.blocks)) + 1
+	t := time.Now().UTC()
+	if n := len(c.blocks); n > 0 && !t.After(c.blocks[n-1].header.Time) {
+		t = c.blocks[n-1].header.Time.Add(time.Nanosecond)
+	}
+	appHash := sha256.Sum256(binary.BigEndian.AppendUint64([]byte(c.chainID), height))
+	return Header{
+		ChainID: c.chainID,
+		height:  height,
+		Time:    t,
+		AppHash: appHash[:],
+	}
+}
+
+func (c *Chain) commit(header Header, events []abci.Event) {
+	c.blocks = append(c.blocks, block{header: header, events: events})
+}
+
+func (c *Chain) txHash(height int64, msgs []sdk.Msg) string {
+	h := sha256.New()
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(height)))
+	for _, msg := range msgs {
+		h.Write([]byte(sdk.MsgTypeURL(msg)))
+		h.Write([]byte(msg.String()))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// height returns the height of the chain at revision height h.
+func (c *Chain) height(h uint64) clienttypes.Height {
+	return clienttypes.NewHeight(c.revision, h)
+}
+
+// relayerEvent converts an abci event to a RelayerEvent.
+func relayerEvent(event abci.Event) provider.RelayerEvent {
+	attributes := make(map[string]string, len(event.Attributes))
+	for _, attr := range event.Attributes {
+		attributes[attr.Key] = attr.Value
+	}
+	return provider.RelayerEvent{EventType: event.Type, Attributes: attributes}
+}
+
+// client is a 07-tendermint client on a Chain.
+type client struct {
+	clientState     tmclient.ClientState
+	consensusStates map[clienttypes.Height]tmclient.ConsensusState
+}
+
+// state is the IBC state of a Chain.
+type state struct {
+	nextClientSeq     uint64
+	nextConnectionSeq uint64
+	nextChannelSeq    uint64
+
+	clients     map[string]client
+	connections map[string]conntypes.ConnectionEnd
+	channels    map[portChannel]chantypes.Channel
+
+	nextSeqSend map[portChannel]uint64
+	nextSeqRecv map[portChannel]uint64
+	nextSeqAck  map[portChannel]uint64
+
+	commitments map[packetKey][]byte
+	receipts    map[packetKey]bool
+	acks        map[packetKey][]byte // acknowledgement commitments
+
+	// the packets sent and received, with the heights they were sent and received at.
+	sent     map[packetKey]provider.PacketInfo
+	received map[packetKey]provider.PacketInfo
+}
+
+type portChannel struct {
+	portID    string
+	channelID string
+}
+
+type packetKey struct {
+	portChannel
+	sequence uint64
+}
+
+func newState() *state {
+	return &state{
+		clients:     make(map[string]client),
+		connections: make(map[string]conntypes.ConnectionEnd),
+		channels:    make(map[portChannel]chantypes.Channel),
+		nextSeqSend: make(map[portChannel]uint64),
+		nextSeqRecv: make(map[portChannel]uint64),
+		nextSeqAck:  make(map[portChannel]uint64),
+		commitments: make(map[packetKey][]byte),
+		receipts:    make(map[packetKey]bool),
+		acks:        make(map[packetKey][]byte),
+		sent:        make(map[packetKey]provider.PacketInfo),
+		received:    make(map[packetKey]provider.PacketInfo),
+	}
+}
+
+// clone returns a copy of st which can be modified without modifying st.
+func (st *state) clone() *state {
+	clients := make(map[string]client, len(st.clients))
+	for id, cl := range st.clients {
+		cl.consensusStates = maps.Clone(cl.consensusStates)
+		clients[id] = cl
+	}
+	return &state{
+		nextClientSeq:     st.nextClientSeq,
+		nextConnectionSeq: st.nextConnectionSeq,
+		nextChannelSeq:    st.nextChannelSeq,
+		clients:           clients,
+		connections:       maps.Clone(st.connections),
+		channels:          maps.Clone(st.channels),
+		nextSeqSend:       maps.Clone(st.nextSeqSend),
+		nextSeqRecv:       maps.Clone(st.nextSeqRecv),
+		nextSeqAck:        maps.Clone(st.nextSeqAck),
+		commitments:       maps.Clone(st.commitments),
+		receipts:          maps.Clone(st.receipts),
+		acks:              maps.Clone(st.acks),
+		sent:              maps.Clone(st.sent),
+		received:          maps.Clone(st.received),
+	}
+}
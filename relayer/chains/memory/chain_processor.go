@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/chains"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+var _ processor.ChainProcessor = &ChainProcessor{}
+
+const (
+	// defaultPollInterval is how often the ChainProcessor queries new blocks unless configured with PollInterval.
+	// The chain is only in sync if it is polled more often than it produces blocks, see Chain.Run.
+	defaultPollInterval = 10 * time.Millisecond
+
+	inSyncNumBlocksThreshold = 2
+)
+
+// ChainProcessor is the processor.ChainProcessor of an in-memory chain. It reads the IBC messages of the new blocks
+// of the chain as the cosmos chain processor reads them from the block results of an RPC node.
+type ChainProcessor struct {
+	log *zap.Logger
+
+	chainProvider *Provider
+
+	pathProcessors processor.PathProcessors
+
+	// indicates whether queries are in sync with latest height of the chain
+	inSync bool
+
+	// highest block
+	latestBlock provider.LatestBlock
+
+	// holds highest consensus height and header for all clients
+	latestClientState map[string]provider.ClientState
+
+	// holds open state for known connections
+	connectionStateCache processor.ConnectionStateCache
+
+	// holds open state for known channels
+	channelStateCache processor.ChannelStateCache
+
+	// map of connection ID to client ID
+	connectionClients map[string]string
+
+	// map of channel ID to connection ID
+	channelConnections map[string]string
+}
+
+// NewChainProcessor returns the ChainProcessor of the chain of p.
+func NewChainProcessor(log *zap.Logger, p *Provider) *ChainProcessor {
+	return &ChainProcessor{
+		log:                  log.With(zap.String("chain_name", p.ChainName()), zap.String("chain_id", p.ChainId())),
+		chainProvider:        p,
+		latestClientState:    make(map[string]provider.ClientState),
+		connectionStateCache: make(processor.ConnectionStateCache),
+		channelStateCache:    make(processor.ChannelStateCache),
+		connectionClients:    make(map[string]string),
+		channelConnections:   make(map[string]string),
+	}
+}
+
+// Provider returns the ChainProvider, which provides the methods for querying, assembling IBC messages, and sending transactions.
+func (mcp *ChainProcessor) Provider() provider.ChainProvider {
+	return mcp.chainProvider
+}
+
+// Set the PathProcessors that this ChainProcessor should publish relevant IBC events to.
+// ChainProcessors need reference to their PathProcessors and vice-versa, handled by EventProcessorBuilder.Build().
+func (mcp *ChainProcessor) SetPathProcessors(pathProcessors processor.PathProcessors) {
+	mcp.pathProcessors = pathProcessors
+}
+
+// Run queries the new blocks of the chain every poll interval and publishes their IBC messages to the PathProcessors,
+// starting initialBlockHistory blocks before the latest height. Stuck packets are not supported, all blocks are kept.
+func (mcp *ChainProcessor) Run(ctx context.Context, initialBlockHistory uint64, _ *processor.StuckPacket) error {
+	pollInterval := mcp.chainProvider.PCfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	latestQueriedBlock := mcp.chainProvider.chain.LatestHeight() - int64(initialBlockHistory)
+	if latestQueriedBlock < 0 {
+		latestQueriedBlock = 0
+	}
+
+	if err := mcp.initializeConnectionState(ctx); err != nil {
+		return err
+	}
+	if err := mcp.initializeChannelState(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		latestQueriedBlock = mcp.queryCycle(ctx, latestQueriedBlock)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// initializeConnectionState will bootstrap the connectionStateCache with the open connection state.
+func (mcp *ChainProcessor) initializeConnectionState(ctx context.Context) error {
+	connections, err := mcp.chainProvider.QueryConnections(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying connections: %w", err)
+	}
+	for _, c := range connections {
+		mcp.connectionClients[c.Id] = c.ClientId
+		mcp.connectionStateCache[processor.ConnectionKey{
+			ConnectionID:         c.Id,
+			ClientID:             c.ClientId,
+			CounterpartyConnID:   c.Counterparty.ConnectionId,
+			CounterpartyClientID: c.Counterparty.ClientId,
+		}] = c.State == conntypes.OPEN
+	}
+	return nil
+}
+
+// initializeChannelState will bootstrap the channelStateCache with the open channel state.
+func (mcp *ChainProcessor) initializeChannelState(ctx context.Context) error {
+	channels, err := mcp.chainProvider.QueryChannels(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying channels: %w", err)
+	}
+	for _, ch := range channels {
+		// in-memory chains only open channels with a single connection hop.
+		mcp.channelConnections[ch.ChannelId] = ch.ConnectionHops[0]
+		k := processor.ChannelKey{
+			ChannelID:             ch.ChannelId,
+			PortID:                ch.PortId,
+			CounterpartyChannelID: ch.Counterparty.ChannelId,
+			CounterpartyPortID:    ch.Counterparty.PortId,
+		}
+		mcp.channelStateCache.SetOpen(k, ch.State == chantypes.OPEN, ch.Ordering)
+	}
+	return nil
+}
+
+// queryCycle handles the blocks after latestQueriedBlock up to the latest height and returns the new latest
+// queried block.
+func (mcp *ChainProcessor) queryCycle(ctx context.Context, latestQueriedBlock int64) int64 {
+	chain := mcp.chainProvider.chain
+	latestHeight := chain.LatestHeight()
+	mcp.chainProvider.queried(1)
+
+	// used at the end of the cycle to send signal to path processors to start processing if both chains are in sync and no new messages came in this cycle
+	firstTimeInSync := false
+
+	if !mcp.inSync && latestHeight-latestQueriedBlock < inSyncNumBlocksThreshold {
+		mcp.inSync = true
+		firstTimeInSync = true
+		mcp.log.Info("Chain is in sync")
+	}
+
+	ibcMessagesCache := processor.NewIBCMessagesCache()
+	ibcHeaderCache := make(processor.IBCHeaderCache)
+
+	var latestHeader Header
+
+	chainID := mcp.chainProvider.ChainId()
+
+	for i := latestQueriedBlock + 1; i <= latestHeight; i++ {
+		header, err := chain.Header(i)
+		if err != nil {
+			mcp.log.Error("Error querying block header", zap.Int64("height", i), zap.Error(err))
+			break
+		}
+		events, err := chain.Events(i)
+		if err != nil {
+			mcp.log.Error("Error querying block events", zap.Int64("height", i), zap.Error(err))
+			break
+		}
+
+		// the header and the events of a block are queried separately from a node, as the block and its results.
+		mcp.chainProvider.queried(2)
+
+		latestHeader = header
+		heightUint64 := uint64(i)
+
+		mcp.latestBlock = provider.LatestBlock{
+			Height: heightUint64,
+			Time:   header.Time,
+		}
+
+		ibcHeaderCache[heightUint64] = header
+
+		for _, m := range chains.IbcMessagesFromEvents(mcp.log, events, chainID, heightUint64) {
+			mcp.handleMessage(ctx, m, ibcMessagesCache)
+		}
+
+		latestQueriedBlock = i
+	}
+
+	if len(ibcHeaderCache) == 0 {
+		if firstTimeInSync {
+			for _, pp := range mcp.pathProcessors {
+				pp.ProcessBacklogIfReady()
+			}
+		}
+		return latestQueriedBlock
+	}
+
+	for _, pp := range mcp.pathProcessors {
+		clientID := pp.RelevantClientID(chainID)
+		clientState, err := mcp.clientState(ctx, clientID)
+		if err != nil {
+			mcp.log.Error("Error fetching client state",
+				zap.String("client_id", clientID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		pp.HandleNewData(chainID, processor.ChainProcessorCacheData{
+			LatestBlock:          mcp.latestBlock,
+			LatestHeader:         latestHeader,
+			IBCMessagesCache:     ibcMessagesCache.Clone(),
+			InSync:               mcp.inSync,
+			ClientState:          clientState,
+			ConnectionStateCache: mcp.connectionStateCache.FilterForClient(clientID),
+			ChannelStateCache:    mcp.channelStateCache.FilterForClient(clientID, mcp.channelConnections, mcp.connectionClients),
+			IBCHeaderCache:       ibcHeaderCache.Clone(),
+		})
+	}
+
+	return latestQueriedBlock
+}
+
+// clientState will return the most recent client state if client messages
+// have already been observed for the clientID, otherwise it will query for it.
+func (mcp *ChainProcessor) clientState(ctx context.Context, clientID string) (provider.ClientState, error) {
+	if state, ok := mcp.latestClientState[clientID]; ok && state.TrustingPeriod > 0 {
+		return state, nil
+	}
+	cs, err := mcp.tmClientState(ctx, clientID)
+	if err != nil {
+		return provider.ClientState{}, err
+	}
+	clientState := provider.ClientState{
+		ClientID:        clientID,
+		ConsensusHeight: cs.GetLatestHeight().(clienttypes.Height),
+		TrustingPeriod:  cs.TrustingPeriod,
+		TrustLevel:      cs.TrustLevel.ToTendermint(),
+	}
+	mcp.latestClientState[clientID] = clientState
+	return clientState, nil
+}
+
+// tmClientState queries the latest state of the 07-tendermint client with clientID.
+func (mcp *ChainProcessor) tmClientState(ctx context.Context, clientID string) (*tmclient.ClientState, error) {
+	cs, err := mcp.chainProvider.QueryClientState(ctx, 0, clientID)
+	if err != nil {
+		return nil, err
+	}
+	tmcs, ok := cs.(*tmclient.ClientState)
+	if !ok {
+		return nil, fmt.Errorf("unexpected client state type %T for client %s", cs, clientID)
+	}
+	return tmcs, nil
+}
+
+// updateClientState records the consensus height of the client of clientInfo unless it is older than the latest.
+func (mcp *ChainProcessor) updateClientState(ctx context.Context, clientInfo chains.ClientInfo) {
+	existing, ok := mcp.latestClientState[clientInfo.ClientID]
+	if ok && clientInfo.ConsensusHeight.LT(existing.ConsensusHeight) {
+		// height is less than latest, so no-op
+		return
+	}
+	if !ok || existing.TrustingPeriod == 0 {
+		cs, err := mcp.tmClientState(ctx, clientInfo.ClientID)
+		if err != nil {
+			mcp.log.Error(
+				"Failed to query client state to get trusting period",
+				zap.String("client_id", clientInfo.ClientID),
+				zap.Error(err),
+			)
+			return
+		}
+		existing.TrustingPeriod = cs.TrustingPeriod
+		existing.TrustLevel = cs.TrustLevel.ToTendermint()
+	}
+	clientState := clientInfo.ClientState(existing.TrustingPeriod)
+	clientState.TrustLevel = existing.TrustLevel
+	mcp.latestClientState[clientInfo.ClientID] = clientState
+}
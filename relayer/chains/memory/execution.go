@@ -0,0 +1,724 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// ErrUnsupported is returned for the messages and queries which in-memory chains do not support.
+var ErrUnsupported = errors.New("not supported by in-memory chains")
+
+// proofPrefix prefixes the value stored under a key to prove it.
+const proofPrefix = "memory-proof:"
+
+// newProof returns the proof of value, which is empty if nothing is stored under the key.
+func newProof(value []byte) []byte {
+	return append([]byte(proofPrefix), value...)
+}
+
+// execution executes the messages of a tx on a copy of the state of a Chain, in the block with header.
+type execution struct {
+	chain  *Chain
+	state  *state
+	header Header
+	events []abci.Event
+}
+
+func (x *execution) execute(msg sdk.Msg) error {
+	switch m := msg.(type) {
+	case *clienttypes.MsgCreateClient:
+		return x.createClient(m)
+	case *clienttypes.MsgUpdateClient:
+		return x.updateClient(m)
+	case *conntypes.MsgConnectionOpenInit:
+		return x.connectionOpenInit(m)
+	case *conntypes.MsgConnectionOpenTry:
+		return x.connectionOpenTry(m)
+	case *conntypes.MsgConnectionOpenAck:
+		return x.connectionOpenAck(m)
+	case *conntypes.MsgConnectionOpenConfirm:
+		return x.connectionOpenConfirm(m)
+	case *chantypes.MsgChannelOpenInit:
+		return x.channelOpenInit(m)
+	case *chantypes.MsgChannelOpenTry:
+		return x.channelOpenTry(m)
+	case *chantypes.MsgChannelOpenAck:
+		return x.channelOpenAck(m)
+	case *chantypes.MsgChannelOpenConfirm:
+		return x.channelOpenConfirm(m)
+	case *chantypes.MsgChannelCloseInit:
+		return x.channelCloseInit(m)
+	case *chantypes.MsgChannelCloseConfirm:
+		return x.channelCloseConfirm(m)
+	case *transfertypes.MsgTransfer:
+		return x.transfer(m)
+	case *chantypes.MsgRecvPacket:
+		return x.recvPacket(m)
+	case *chantypes.MsgAcknowledgement:
+		return x.acknowledgePacket(m)
+	case *chantypes.MsgTimeout:
+		return x.timeoutPacket(m)
+	default:
+		return fmt.Errorf("%w: message %s", ErrUnsupported, sdk.MsgTypeURL(msg))
+	}
+}
+
+// emit emits an event with the attributes given as key-value pairs.
+func (x *execution) emit(eventType string, kv ...string) {
+	event := abci.Event{Type: eventType}
+	for i := 0; i+1 < len(kv); i += 2 {
+		event.Attributes = append(event.Attributes, abci.EventAttribute{Key: kv[i], Value: kv[i+1]})
+	}
+	x.events = append(x.events, event)
+}
+
+func (x *execution) height() clienttypes.Height {
+	return x.chain.height(x.header.height)
+}
+
+// cachedValue returns the value packed in a, which must have been packed in process.
+func cachedValue[T any](a *codectypes.Any) (T, error) {
+	var v T
+	if a == nil {
+		return v, fmt.Errorf("missing %T", v)
+	}
+	v, ok := a.GetCachedValue().(T)
+	if !ok {
+		return v, fmt.Errorf("unexpected %s, expected %T", a.TypeUrl, v)
+	}
+	return v, nil
+}
+
+func (x *execution) createClient(m *clienttypes.MsgCreateClient) error {
+	cs, err := cachedValue[*tmclient.ClientState](m.ClientState)
+	if err != nil {
+		return err
+	}
+	consState, err := cachedValue[*tmclient.ConsensusState](m.ConsensusState)
+	if err != nil {
+		return err
+	}
+
+	clientID := clienttypes.FormatClientIdentifier(ibcexported.Tendermint, x.state.nextClientSeq)
+	x.state.nextClientSeq++
+	x.state.clients[clientID] = client{
+		clientState:     *cs,
+		consensusStates: map[clienttypes.Height]tmclient.ConsensusState{cs.LatestHeight: *consState},
+	}
+
+	x.emit(clienttypes.EventTypeCreateClient,
+		clienttypes.AttributeKeyClientID, clientID,
+		clienttypes.AttributeKeyClientType, ibcexported.Tendermint,
+		clienttypes.AttributeKeyConsensusHeight, cs.LatestHeight.String(),
+	)
+	return nil
+}
+
+func (x *execution) updateClient(m *clienttypes.MsgUpdateClient) error {
+	cl, ok := x.state.clients[m.ClientId]
+	if !ok {
+		return fmt.Errorf("client %s not found", m.ClientId)
+	}
+	header, err := cachedValue[*tmclient.Header](m.ClientMessage)
+	if err != nil {
+		return err
+	}
+	if header.SignedHeader == nil || header.Header == nil {
+		return fmt.Errorf("header of client update of client %s is empty", m.ClientId)
+	}
+	if header.Header.ChainID != cl.clientState.ChainId {
+		return fmt.Errorf("header of chain %s can not update client %s of chain %s",
+			header.Header.ChainID, m.ClientId, cl.clientState.ChainId)
+	}
+	trusted, ok := cl.consensusStates[header.TrustedHeight]
+	if !ok {
+		return fmt.Errorf("client %s has no consensus state at trusted height %s", m.ClientId, header.TrustedHeight)
+	}
+	if cl.clientState.IsExpired(trusted.Timestamp, x.header.Time) {
+		return fmt.Errorf("client %s is expired", m.ClientId)
+	}
+
+	height := header.GetHeight().(clienttypes.Height)
+	if _, ok := cl.consensusStates[height]; !ok {
+		cl.consensusStates[height] = *header.ConsensusState()
+	}
+	if height.GT(cl.clientState.LatestHeight) {
+		cl.clientState.LatestHeight = height
+	}
+	x.state.clients[m.ClientId] = cl
+
+	x.emit(clienttypes.EventTypeUpdateClient,
+		clienttypes.AttributeKeyClientID, m.ClientId,
+		clienttypes.AttributeKeyClientType, ibcexported.Tendermint,
+		clienttypes.AttributeKeyConsensusHeight, height.String(),
+		clienttypes.AttributeKeyConsensusHeights, height.String(),
+	)
+	return nil
+}
+
+// consensusState returns the consensus state of the client at height.
+func (x *execution) consensusState(clientID string, height clienttypes.Height) (tmclient.ConsensusState, error) {
+	cl, ok := x.state.clients[clientID]
+	if !ok {
+		return tmclient.ConsensusState{}, fmt.Errorf("client %s not found", clientID)
+	}
+	consState, ok := cl.consensusStates[height]
+	if !ok {
+		return tmclient.ConsensusState{}, fmt.Errorf("client %s has no consensus state at proof height %s", clientID, height)
+	}
+	return consState, nil
+}
+
+// verify returns the value proven by proof on the counterparty chain of the client at proofHeight.
+func (x *execution) verify(clientID string, proofHeight clienttypes.Height, proof []byte) ([]byte, error) {
+	if _, err := x.consensusState(clientID, proofHeight); err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(proof, []byte(proofPrefix)) {
+		return nil, fmt.Errorf("invalid proof")
+	}
+	return proof[len(proofPrefix):], nil
+}
+
+// verifyMessage decodes the message proven by proof on the counterparty chain of the client at proofHeight into m.
+func (x *execution) verifyMessage(clientID string, proofHeight clienttypes.Height, proof []byte, m proto.Message) error {
+	value, err := x.verify(clientID, proofHeight, proof)
+	if err != nil {
+		return err
+	}
+	if len(value) == 0 {
+		return fmt.Errorf("proof of %T is empty", m)
+	}
+	return proto.Unmarshal(value, m)
+}
+
+func (x *execution) emitConnection(eventType, connectionID string, end conntypes.ConnectionEnd) {
+	x.emit(eventType,
+		conntypes.AttributeKeyConnectionID, connectionID,
+		conntypes.AttributeKeyClientID, end.ClientId,
+		conntypes.AttributeKeyCounterpartyClientID, end.Counterparty.ClientId,
+		conntypes.AttributeKeyCounterpartyConnectionID, end.Counterparty.ConnectionId,
+	)
+}
+
+func (x *execution) connection(connectionID string, state conntypes.State) (conntypes.ConnectionEnd, error) {
+	end, ok := x.state.connections[connectionID]
+	if !ok {
+		return end, fmt.Errorf("connection %s not found", connectionID)
+	}
+	if end.State != state {
+		return end, fmt.Errorf("connection %s is %s, expected %s", connectionID, end.State, state)
+	}
+	return end, nil
+}
+
+func (x *execution) newConnectionID() string {
+	connectionID := conntypes.FormatConnectionIdentifier(x.state.nextConnectionSeq)
+	x.state.nextConnectionSeq++
+	return connectionID
+}
+
+func (x *execution) connectionOpenInit(m *conntypes.MsgConnectionOpenInit) error {
+	if _, ok := x.state.clients[m.ClientId]; !ok {
+		return fmt.Errorf("client %s not found", m.ClientId)
+	}
+	versions := conntypes.GetCompatibleVersions()
+	if m.Version != nil {
+		versions = []*conntypes.Version{m.Version}
+	}
+
+	connectionID := x.newConnectionID()
+	end := conntypes.ConnectionEnd{
+		ClientId:     m.ClientId,
+		Versions:     versions,
+		State:        conntypes.INIT,
+		Counterparty: m.Counterparty,
+		DelayPeriod:  m.DelayPeriod,
+	}
+	x.state.connections[connectionID] = end
+	x.emitConnection(conntypes.EventTypeConnectionOpenInit, connectionID, end)
+	return nil
+}
+
+func (x *execution) connectionOpenTry(m *conntypes.MsgConnectionOpenTry) error {
+	cs, err := cachedValue[*tmclient.ClientState](m.ClientState)
+	if err != nil {
+		return err
+	}
+	if cs.ChainId != x.chain.chainID {
+		return fmt.Errorf("counterparty client tracks chain %s, expected %s", cs.ChainId, x.chain.chainID)
+	}
+
+	var counterparty conntypes.ConnectionEnd
+	if err := x.verifyMessage(m.ClientId, m.ProofHeight, m.ProofInit, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != conntypes.INIT ||
+		counterparty.ClientId != m.Counterparty.ClientId || counterparty.Counterparty.ClientId != m.ClientId {
+		return fmt.Errorf("counterparty connection %s does not match the connection", m.Counterparty.ConnectionId)
+	}
+
+	connectionID := x.newConnectionID()
+	end := conntypes.ConnectionEnd{
+		ClientId:     m.ClientId,
+		Versions:     conntypes.GetCompatibleVersions(),
+		State:        conntypes.TRYOPEN,
+		Counterparty: m.Counterparty,
+		DelayPeriod:  m.DelayPeriod,
+	}
+	x.state.connections[connectionID] = end
+	x.emitConnection(conntypes.EventTypeConnectionOpenTry, connectionID, end)
+	return nil
+}
+
+func (x *execution) connectionOpenAck(m *conntypes.MsgConnectionOpenAck) error {
+	end, err := x.connection(m.ConnectionId, conntypes.INIT)
+	if err != nil {
+		return err
+	}
+
+	var counterparty conntypes.ConnectionEnd
+	if err := x.verifyMessage(end.ClientId, m.ProofHeight, m.ProofTry, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != conntypes.TRYOPEN || counterparty.Counterparty.ConnectionId != m.ConnectionId {
+		return fmt.Errorf("counterparty connection %s does not match the connection", m.CounterpartyConnectionId)
+	}
+
+	end.State = conntypes.OPEN
+	end.Counterparty.ConnectionId = m.CounterpartyConnectionId
+	if m.Version != nil {
+		end.Versions = []*conntypes.Version{m.Version}
+	}
+	x.state.connections[m.ConnectionId] = end
+	x.emitConnection(conntypes.EventTypeConnectionOpenAck, m.ConnectionId, end)
+	return nil
+}
+
+func (x *execution) connectionOpenConfirm(m *conntypes.MsgConnectionOpenConfirm) error {
+	end, err := x.connection(m.ConnectionId, conntypes.TRYOPEN)
+	if err != nil {
+		return err
+	}
+
+	var counterparty conntypes.ConnectionEnd
+	if err := x.verifyMessage(end.ClientId, m.ProofHeight, m.ProofAck, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != conntypes.OPEN || counterparty.Counterparty.ConnectionId != m.ConnectionId {
+		return fmt.Errorf("counterparty connection %s does not match the connection", end.Counterparty.ConnectionId)
+	}
+
+	end.State = conntypes.OPEN
+	x.state.connections[m.ConnectionId] = end
+	x.emitConnection(conntypes.EventTypeConnectionOpenConfirm, m.ConnectionId, end)
+	return nil
+}
+
+func (x *execution) emitChannel(eventType string, k portChannel, ch chantypes.Channel) {
+	x.emit(eventType,
+		chantypes.AttributeKeyPortID, k.portID,
+		chantypes.AttributeKeyChannelID, k.channelID,
+		chantypes.AttributeCounterpartyPortID, ch.Counterparty.PortId,
+		chantypes.AttributeCounterpartyChannelID, ch.Counterparty.ChannelId,
+		chantypes.AttributeKeyConnectionID, ch.ConnectionHops[0],
+		chantypes.AttributeVersion, ch.Version,
+	)
+}
+
+func (x *execution) channel(k portChannel) (chantypes.Channel, error) {
+	ch, ok := x.state.channels[k]
+	if !ok {
+		return ch, fmt.Errorf("channel %s on port %s not found", k.channelID, k.portID)
+	}
+	return ch, nil
+}
+
+func (x *execution) channelInState(k portChannel, state chantypes.State) (chantypes.Channel, error) {
+	ch, err := x.channel(k)
+	if err != nil {
+		return ch, err
+	}
+	if ch.State != state {
+		return ch, fmt.Errorf("channel %s on port %s is %s, expected %s", k.channelID, k.portID, ch.State, state)
+	}
+	return ch, nil
+}
+
+// channelClientID returns the client of the connection of the channel, which must be open.
+func (x *execution) channelClientID(ch chantypes.Channel) (string, error) {
+	if len(ch.ConnectionHops) != 1 {
+		return "", fmt.Errorf("channels must have a single connection hop, got %d", len(ch.ConnectionHops))
+	}
+	end, err := x.connection(ch.ConnectionHops[0], conntypes.OPEN)
+	if err != nil {
+		return "", err
+	}
+	return end.ClientId, nil
+}
+
+func (x *execution) newChannel(portID string, ch chantypes.Channel) portChannel {
+	k := portChannel{portID: portID, channelID: chantypes.FormatChannelIdentifier(x.state.nextChannelSeq)}
+	x.state.nextChannelSeq++
+	x.state.channels[k] = ch
+	x.state.nextSeqSend[k] = 1
+	x.state.nextSeqRecv[k] = 1
+	x.state.nextSeqAck[k] = 1
+	return k
+}
+
+func (x *execution) channelOpenInit(m *chantypes.MsgChannelOpenInit) error {
+	ch := m.Channel
+	if len(ch.ConnectionHops) != 1 {
+		return fmt.Errorf("channels must have a single connection hop, got %d", len(ch.ConnectionHops))
+	}
+	if _, ok := x.state.connections[ch.ConnectionHops[0]]; !ok {
+		return fmt.Errorf("connection %s not found", ch.ConnectionHops[0])
+	}
+
+	ch.State = chantypes.INIT
+	ch.Counterparty.ChannelId = ""
+	k := x.newChannel(m.PortId, ch)
+	x.emitChannel(chantypes.EventTypeChannelOpenInit, k, ch)
+	return nil
+}
+
+func (x *execution) channelOpenTry(m *chantypes.MsgChannelOpenTry) error {
+	ch := m.Channel
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+
+	var counterparty chantypes.Channel
+	if err := x.verifyMessage(clientID, m.ProofHeight, m.ProofInit, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != chantypes.INIT || counterparty.Counterparty.PortId != m.PortId ||
+		counterparty.Ordering != ch.Ordering {
+		return fmt.Errorf("counterparty channel %s does not match the channel", ch.Counterparty.ChannelId)
+	}
+
+	ch.State = chantypes.TRYOPEN
+	k := x.newChannel(m.PortId, ch)
+	x.emitChannel(chantypes.EventTypeChannelOpenTry, k, ch)
+	return nil
+}
+
+func (x *execution) channelOpenAck(m *chantypes.MsgChannelOpenAck) error {
+	k := portChannel{portID: m.PortId, channelID: m.ChannelId}
+	ch, err := x.channelInState(k, chantypes.INIT)
+	if err != nil {
+		return err
+	}
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+
+	var counterparty chantypes.Channel
+	if err := x.verifyMessage(clientID, m.ProofHeight, m.ProofTry, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != chantypes.TRYOPEN || counterparty.Counterparty.ChannelId != m.ChannelId {
+		return fmt.Errorf("counterparty channel %s does not match the channel", m.CounterpartyChannelId)
+	}
+
+	ch.State = chantypes.OPEN
+	ch.Counterparty.ChannelId = m.CounterpartyChannelId
+	ch.Version = m.CounterpartyVersion
+	x.state.channels[k] = ch
+	x.emitChannel(chantypes.EventTypeChannelOpenAck, k, ch)
+	return nil
+}
+
+func (x *execution) channelOpenConfirm(m *chantypes.MsgChannelOpenConfirm) error {
+	k := portChannel{portID: m.PortId, channelID: m.ChannelId}
+	ch, err := x.channelInState(k, chantypes.TRYOPEN)
+	if err != nil {
+		return err
+	}
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+
+	var counterparty chantypes.Channel
+	if err := x.verifyMessage(clientID, m.ProofHeight, m.ProofAck, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != chantypes.OPEN || counterparty.Counterparty.ChannelId != m.ChannelId {
+		return fmt.Errorf("counterparty channel %s does not match the channel", ch.Counterparty.ChannelId)
+	}
+
+	ch.State = chantypes.OPEN
+	x.state.channels[k] = ch
+	x.emitChannel(chantypes.EventTypeChannelOpenConfirm, k, ch)
+	return nil
+}
+
+func (x *execution) channelCloseInit(m *chantypes.MsgChannelCloseInit) error {
+	k := portChannel{portID: m.PortId, channelID: m.ChannelId}
+	ch, err := x.channelInState(k, chantypes.OPEN)
+	if err != nil {
+		return err
+	}
+
+	ch.State = chantypes.CLOSED
+	x.state.channels[k] = ch
+	x.emitChannel(chantypes.EventTypeChannelCloseInit, k, ch)
+	return nil
+}
+
+func (x *execution) channelCloseConfirm(m *chantypes.MsgChannelCloseConfirm) error {
+	k := portChannel{portID: m.PortId, channelID: m.ChannelId}
+	ch, err := x.channelInState(k, chantypes.OPEN)
+	if err != nil {
+		return err
+	}
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+
+	var counterparty chantypes.Channel
+	if err := x.verifyMessage(clientID, m.ProofHeight, m.ProofInit, &counterparty); err != nil {
+		return err
+	}
+	if counterparty.State != chantypes.CLOSED || counterparty.Counterparty.ChannelId != m.ChannelId {
+		return fmt.Errorf("counterparty channel %s is not closed", ch.Counterparty.ChannelId)
+	}
+
+	ch.State = chantypes.CLOSED
+	x.state.channels[k] = ch
+	x.emitChannel(chantypes.EventTypeChannelCloseConfirm, k, ch)
+	return nil
+}
+
+// packetInfo returns the PacketInfo of packet on a channel with order, observed at height.
+func packetInfo(packet chantypes.Packet, order chantypes.Order, height uint64) provider.PacketInfo {
+	return provider.PacketInfo{
+		Height:           height,
+		Sequence:         packet.Sequence,
+		SourcePort:       packet.SourcePort,
+		SourceChannel:    packet.SourceChannel,
+		DestPort:         packet.DestinationPort,
+		DestChannel:      packet.DestinationChannel,
+		ChannelOrder:     order.String(),
+		Data:             packet.Data,
+		TimeoutHeight:    packet.TimeoutHeight,
+		TimeoutTimestamp: packet.TimeoutTimestamp,
+	}
+}
+
+func (x *execution) emitPacket(eventType string, packet chantypes.Packet, ch chantypes.Channel, ack []byte) {
+	kv := []string{
+		chantypes.AttributeKeyDataHex, hex.EncodeToString(packet.Data),
+		chantypes.AttributeKeyTimeoutHeight, packet.TimeoutHeight.String(),
+		chantypes.AttributeKeyTimeoutTimestamp, strconv.FormatUint(packet.TimeoutTimestamp, 10),
+		chantypes.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10),
+		chantypes.AttributeKeySrcPort, packet.SourcePort,
+		chantypes.AttributeKeySrcChannel, packet.SourceChannel,
+		chantypes.AttributeKeyDstPort, packet.DestinationPort,
+		chantypes.AttributeKeyDstChannel, packet.DestinationChannel,
+		chantypes.AttributeKeyChannelOrdering, ch.Ordering.String(),
+		chantypes.AttributeKeyConnection, ch.ConnectionHops[0],
+	}
+	if ack != nil {
+		kv = append(kv, chantypes.AttributeKeyAckHex, hex.EncodeToString(ack))
+	}
+	x.emit(eventType, kv...)
+}
+
+func (x *execution) transfer(m *transfertypes.MsgTransfer) error {
+	k := portChannel{portID: m.SourcePort, channelID: m.SourceChannel}
+	ch, err := x.channelInState(k, chantypes.OPEN)
+	if err != nil {
+		return err
+	}
+	if m.TimeoutHeight.IsZero() && m.TimeoutTimestamp == 0 {
+		return fmt.Errorf("packet must have a timeout height or timestamp")
+	}
+
+	data := transfertypes.NewFungibleTokenPacketData(
+		m.Token.Denom, m.Token.Amount.String(), m.Sender, m.Receiver, m.Memo,
+	).GetBytes()
+
+	seq := x.state.nextSeqSend[k]
+	x.state.nextSeqSend[k] = seq + 1
+	packet := chantypes.NewPacket(data, seq, k.portID, k.channelID,
+		ch.Counterparty.PortId, ch.Counterparty.ChannelId, m.TimeoutHeight, m.TimeoutTimestamp)
+
+	pk := packetKey{portChannel: k, sequence: seq}
+	x.state.commitments[pk] = chantypes.CommitPacket(nil, &packet)
+	x.state.sent[pk] = packetInfo(packet, ch.Ordering, x.header.height)
+	x.emitPacket(chantypes.EventTypeSendPacket, packet, ch, nil)
+	return nil
+}
+
+func (x *execution) recvPacket(m *chantypes.MsgRecvPacket) error {
+	packet := m.Packet
+	k := portChannel{portID: packet.DestinationPort, channelID: packet.DestinationChannel}
+	ch, err := x.channelInState(k, chantypes.OPEN)
+	if err != nil {
+		return err
+	}
+	if ch.Counterparty.PortId != packet.SourcePort || ch.Counterparty.ChannelId != packet.SourceChannel {
+		return fmt.Errorf("packet source does not match the counterparty of channel %s", k.channelID)
+	}
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+	commitment, err := x.verify(clientID, m.ProofHeight, m.ProofCommitment)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(commitment, chantypes.CommitPacket(nil, &packet)) {
+		return fmt.Errorf("packet %d does not match its commitment", packet.Sequence)
+	}
+	if err := packetInfo(packet, ch.Ordering, x.header.height).TimeoutElapsed(
+		x.chain.revision, provider.LatestBlock{Height: x.header.height, Time: x.header.Time},
+	); err != nil {
+		return fmt.Errorf("packet %d timed out: %w", packet.Sequence, err)
+	}
+
+	pk := packetKey{portChannel: k, sequence: packet.Sequence}
+	switch ch.Ordering {
+	case chantypes.ORDERED:
+		next := x.state.nextSeqRecv[k]
+		if packet.Sequence < next {
+			// already received, a no-op as with ibc-go.
+			return nil
+		}
+		if packet.Sequence > next {
+			return fmt.Errorf("packet %d received out of order, expected %d", packet.Sequence, next)
+		}
+		x.state.nextSeqRecv[k] = next + 1
+	default:
+		if x.state.receipts[pk] {
+			return nil
+		}
+		x.state.receipts[pk] = true
+	}
+
+	ack := chantypes.NewResultAcknowledgement([]byte{byte(1)}).Acknowledgement()
+	x.state.acks[pk] = chantypes.CommitAcknowledgement(ack)
+	info := packetInfo(packet, ch.Ordering, x.header.height)
+	info.Ack = ack
+	x.state.received[pk] = info
+
+	x.emitPacket(chantypes.EventTypeRecvPacket, packet, ch, nil)
+	x.emitPacket(chantypes.EventTypeWriteAck, packet, ch, ack)
+	return nil
+}
+
+// sentPacket returns the channel of the packet sent from this chain, and whether its commitment still exists.
+func (x *execution) sentPacket(packet chantypes.Packet) (portChannel, chantypes.Channel, bool, error) {
+	k := portChannel{portID: packet.SourcePort, channelID: packet.SourceChannel}
+	ch, err := x.channel(k)
+	if err != nil {
+		return k, ch, false, err
+	}
+	commitment, ok := x.state.commitments[packetKey{portChannel: k, sequence: packet.Sequence}]
+	if !ok {
+		return k, ch, false, nil
+	}
+	if !bytes.Equal(commitment, chantypes.CommitPacket(nil, &packet)) {
+		return k, ch, false, fmt.Errorf("packet %d does not match its commitment", packet.Sequence)
+	}
+	return k, ch, true, nil
+}
+
+func (x *execution) acknowledgePacket(m *chantypes.MsgAcknowledgement) error {
+	packet := m.Packet
+	k, ch, ok, err := x.sentPacket(packet)
+	if err != nil || !ok {
+		// already acknowledged or timed out, a no-op as with ibc-go.
+		return err
+	}
+	if ch.State != chantypes.OPEN {
+		return fmt.Errorf("channel %s on port %s is %s", k.channelID, k.portID, ch.State)
+	}
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+	ackCommitment, err := x.verify(clientID, m.ProofHeight, m.ProofAcked)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(ackCommitment, chantypes.CommitAcknowledgement(m.Acknowledgement)) {
+		return fmt.Errorf("acknowledgement of packet %d does not match its commitment", packet.Sequence)
+	}
+	if ch.Ordering == chantypes.ORDERED {
+		if next := x.state.nextSeqAck[k]; packet.Sequence != next {
+			return fmt.Errorf("packet %d acknowledged out of order, expected %d", packet.Sequence, next)
+		}
+		x.state.nextSeqAck[k]++
+	}
+
+	delete(x.state.commitments, packetKey{portChannel: k, sequence: packet.Sequence})
+	x.emitPacket(chantypes.EventTypeAcknowledgePacket, packet, ch, nil)
+	return nil
+}
+
+func (x *execution) timeoutPacket(m *chantypes.MsgTimeout) error {
+	packet := m.Packet
+	k, ch, ok, err := x.sentPacket(packet)
+	if err != nil || !ok {
+		return err
+	}
+	clientID, err := x.channelClientID(ch)
+	if err != nil {
+		return err
+	}
+	consState, err := x.consensusState(clientID, m.ProofHeight)
+	if err != nil {
+		return err
+	}
+	if err := packetInfo(packet, ch.Ordering, 0).TimeoutElapsed(
+		m.ProofHeight.RevisionNumber, provider.LatestBlock{Height: m.ProofHeight.RevisionHeight, Time: consState.Timestamp},
+	); err == nil {
+		return fmt.Errorf("packet %d has not timed out at proof height %s", packet.Sequence, m.ProofHeight)
+	}
+	unreceived, err := x.verify(clientID, m.ProofHeight, m.ProofUnreceived)
+	if err != nil {
+		return err
+	}
+	switch ch.Ordering {
+	case chantypes.ORDERED:
+		if len(unreceived) != 8 || binary.BigEndian.Uint64(unreceived) > packet.Sequence {
+			return fmt.Errorf("packet %d was received", packet.Sequence)
+		}
+	default:
+		if len(unreceived) != 0 {
+			return fmt.Errorf("packet %d was received", packet.Sequence)
+		}
+	}
+
+	delete(x.state.commitments, packetKey{portChannel: k, sequence: packet.Sequence})
+	x.emitPacket(chantypes.EventTypeTimeoutPacket, packet, ch, nil)
+	if ch.Ordering == chantypes.ORDERED {
+		ch.State = chantypes.CLOSED
+		x.state.channels[k] = ch
+		x.emitChannel(chantypes.EventTypeChannelClosed, k, ch)
+	}
+	return nil
+}
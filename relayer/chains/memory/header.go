@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"time"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+var _ provider.IBCHeader = Header{}
+
+// Header is the header of a block of a Chain.
+type Header struct {
+	ChainID string
+	Time    time.Time
+	AppHash []byte
+
+	height uint64
+}
+
+// Height implements provider.IBCHeader.
+func (h Header) Height() uint64 {
+	return h.height
+}
+
+// ConsensusState implements provider.IBCHeader, the consensus state of a 07-tendermint client at the header.
+func (h Header) ConsensusState() ibcexported.ConsensusState {
+	return &tmclient.ConsensusState{
+		Timestamp:          h.Time,
+		Root:               commitmenttypes.NewMerkleRoot(h.AppHash),
+		NextValidatorsHash: nextValidatorsHash,
+	}
+}
+
+// NextValidatorsHash implements provider.IBCHeader.
+func (h Header) NextValidatorsHash() []byte {
+	return nextValidatorsHash
+}
+
+// TMHeader returns the header as the header of a 07-tendermint client update from trustedHeight.
+// It is not signed, the validator sets are empty.
+func (h Header) TMHeader(trustedHeight clienttypes.Height) *tmclient.Header {
+	return &tmclient.Header{
+		SignedHeader: &cmtproto.SignedHeader{
+			Header: &cmtproto.Header{
+				ChainID:            h.ChainID,
+				Height:             int64(h.height),
+				Time:               h.Time,
+				AppHash:            h.AppHash,
+				NextValidatorsHash: nextValidatorsHash,
+			},
+			Commit: &cmtproto.Commit{Height: int64(h.height)},
+		},
+		TrustedHeight: trustedHeight,
+	}
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/chains"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+func (mcp *ChainProcessor) handleMessage(ctx context.Context, m chains.IbcMessage, c processor.IBCMessagesCache) {
+	switch t := m.Info.(type) {
+	case *chains.PacketInfo:
+		mcp.handlePacketMessage(m.EventType, provider.PacketInfo(*t), c)
+	case *chains.ChannelInfo:
+		mcp.handleChannelMessage(m.EventType, provider.ChannelInfo(*t), c)
+	case *chains.ConnectionInfo:
+		mcp.handleConnectionMessage(m.EventType, provider.ConnectionInfo(*t), c)
+	case *chains.ClientInfo:
+		mcp.handleClientMessage(ctx, m.EventType, *t)
+	}
+}
+
+func (mcp *ChainProcessor) handlePacketMessage(eventType string, pi provider.PacketInfo, c processor.IBCMessagesCache) {
+	k, err := processor.PacketInfoChannelKey(eventType, pi)
+	if err != nil {
+		mcp.log.Error("Unexpected error handling packet message",
+			zap.String("event_type", eventType),
+			zap.Uint64("sequence", pi.Sequence),
+			zap.Inline(k),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if eventType == chantypes.EventTypeTimeoutPacket && pi.ChannelOrder == chantypes.ORDERED.String() {
+		mcp.channelStateCache.SetOpen(k, false, chantypes.ORDERED)
+	}
+
+	if !c.PacketFlow.ShouldRetainSequence(mcp.pathProcessors, k, mcp.chainProvider.ChainId(), eventType, pi.Sequence) {
+		return
+	}
+
+	c.PacketFlow.Retain(k, eventType, pi)
+	mcp.logObservedIBCMessage(eventType, zap.Uint64("sequence", pi.Sequence), zap.Inline(k))
+}
+
+func (mcp *ChainProcessor) handleChannelMessage(eventType string, ci provider.ChannelInfo, ibcMessagesCache processor.IBCMessagesCache) {
+	if ci.ConnID != "" {
+		mcp.channelConnections[ci.ChannelID] = ci.ConnID
+	}
+	channelKey := processor.ChannelInfoChannelKey(ci)
+
+	if eventType == chantypes.EventTypeChannelOpenInit {
+		found := false
+		for k := range mcp.channelStateCache {
+			// Don't add a channelKey to the channelStateCache without counterparty channel ID
+			// since we already have the channelKey in the channelStateCache which includes the
+			// counterparty channel ID.
+			if k.MsgInitKey() == channelKey {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mcp.channelStateCache.SetOpen(channelKey, false, ci.Order)
+		}
+	} else {
+		switch eventType {
+		case chantypes.EventTypeChannelOpenTry:
+			mcp.channelStateCache.SetOpen(channelKey, false, ci.Order)
+		case chantypes.EventTypeChannelOpenAck, chantypes.EventTypeChannelOpenConfirm:
+			mcp.channelStateCache.SetOpen(channelKey, true, ci.Order)
+		case chantypes.EventTypeChannelClosed, chantypes.EventTypeChannelCloseConfirm:
+			for k := range mcp.channelStateCache {
+				if k.PortID == ci.PortID && k.ChannelID == ci.ChannelID {
+					mcp.channelStateCache.SetOpen(channelKey, false, ci.Order)
+					break
+				}
+			}
+		}
+		// Clear out MsgInitKeys once we have the counterparty channel ID
+		delete(mcp.channelStateCache, channelKey.MsgInitKey())
+	}
+
+	ibcMessagesCache.ChannelHandshake.Retain(channelKey, eventType, ci)
+
+	mcp.logObservedIBCMessage(eventType,
+		zap.String("channel_id", ci.ChannelID),
+		zap.String("port_id", ci.PortID),
+		zap.String("counterparty_channel_id", ci.CounterpartyChannelID),
+		zap.String("counterparty_port_id", ci.CounterpartyPortID),
+		zap.String("connection_id", ci.ConnID),
+	)
+}
+
+func (mcp *ChainProcessor) handleConnectionMessage(eventType string, ci provider.ConnectionInfo, ibcMessagesCache processor.IBCMessagesCache) {
+	mcp.connectionClients[ci.ConnID] = ci.ClientID
+	connectionKey := processor.ConnectionInfoConnectionKey(ci)
+	if eventType == conntypes.EventTypeConnectionOpenInit {
+		found := false
+		for k := range mcp.connectionStateCache {
+			// Don't add a connectionKey to the connectionStateCache without counterparty connection ID
+			// since we already have the connectionKey in the connectionStateCache which includes the
+			// counterparty connection ID.
+			if k.MsgInitKey() == connectionKey {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mcp.connectionStateCache[connectionKey] = false
+		}
+	} else {
+		// Clear out MsgInitKeys once we have the counterparty connection ID
+		delete(mcp.connectionStateCache, connectionKey.MsgInitKey())
+		open := (eventType == conntypes.EventTypeConnectionOpenAck || eventType == conntypes.EventTypeConnectionOpenConfirm)
+		mcp.connectionStateCache[connectionKey] = open
+	}
+	ibcMessagesCache.ConnectionHandshake.Retain(connectionKey, eventType, ci)
+
+	mcp.logObservedIBCMessage(eventType,
+		zap.String("client_id", ci.ClientID),
+		zap.String("connection_id", ci.ConnID),
+		zap.String("counterparty_client_id", ci.CounterpartyClientID),
+		zap.String("counterparty_connection_id", ci.CounterpartyConnID),
+	)
+}
+
+func (mcp *ChainProcessor) handleClientMessage(ctx context.Context, eventType string, ci chains.ClientInfo) {
+	mcp.updateClientState(ctx, ci)
+	mcp.logObservedIBCMessage(eventType, zap.String("client_id", ci.ClientID))
+}
+
+func (mcp *ChainProcessor) logObservedIBCMessage(m string, fields ...zap.Field) {
+	mcp.log.With(zap.String("event_type", m)).Debug("Observed IBC message", fields...)
+}
@@ -0,0 +1,334 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+var (
+	_ provider.ChainProvider  = &Provider{}
+	_ provider.KeyProvider    = &Provider{}
+	_ provider.ProviderConfig = &ProviderConfig{}
+)
+
+// accountPrefix is the bech32 prefix of the addresses of the keys of in-memory chains.
+const accountPrefix = "memory"
+
+// ProviderConfig configures a Provider of an in-memory chain.
+type ProviderConfig struct {
+	ChainName    string                    `json:"-" yaml:"-"`
+	ChainID      string                    `json:"chain-id" yaml:"chain-id"`
+	Key          string                    `json:"key" yaml:"key"`
+	Timeout      string                    `json:"timeout" yaml:"timeout"`
+	Broadcast    provider.BroadcastMode    `json:"broadcast-mode" yaml:"broadcast-mode"`
+	ClientUpdate provider.ClientUpdateMode `json:"client-update-mode" yaml:"client-update-mode"`
+	MaxBatch     uint64                    `json:"max-batch-msgs" yaml:"max-batch-msgs"`
+
+	// PollInterval is how often the ChainProcessor queries new blocks, defaultPollInterval if zero.
+	PollInterval time.Duration `json:"poll-interval" yaml:"poll-interval"`
+
+	// Chain is the chain to provide, a new Chain with ChainID if nil.
+	// Providers of the same Chain share its state, as the providers of the nodes of a real chain.
+	Chain *Chain `json:"-" yaml:"-"`
+}
+
+func (pc ProviderConfig) Validate() error {
+	if pc.ChainID == "" {
+		return fmt.Errorf("chain-id is required")
+	}
+	if pc.Chain != nil && pc.Chain.ChainID() != pc.ChainID {
+		return fmt.Errorf("chain-id %s does not match the chain %s", pc.ChainID, pc.Chain.ChainID())
+	}
+	if pc.Timeout != "" {
+		if _, err := time.ParseDuration(pc.Timeout); err != nil {
+			return fmt.Errorf("invalid Timeout: %w", err)
+		}
+	}
+	switch pc.ClientUpdate {
+	case "", provider.ClientUpdateModeBundled, provider.ClientUpdateModeSeparate:
+	default:
+		return fmt.Errorf("invalid client-update-mode: %s, supports one of: [%s, %s]",
+			pc.ClientUpdate, provider.ClientUpdateModeBundled, provider.ClientUpdateModeSeparate)
+	}
+	return nil
+}
+
+func (pc ProviderConfig) BroadcastMode() provider.BroadcastMode {
+	return pc.Broadcast
+}
+
+func (pc ProviderConfig) ClientUpdateMode() provider.ClientUpdateMode {
+	return pc.ClientUpdate
+}
+
+func (pc ProviderConfig) MaxBatchMsgs() uint64 {
+	return pc.MaxBatch
+}
+
+// TxInclusionTimeout returns no timeout, txs are included in a block as soon as they are sent.
+func (pc ProviderConfig) TxInclusionTimeout() provider.BlockTimeout {
+	return provider.BlockTimeout{}
+}
+
+// NewProvider validates the ProviderConfig and instantiates a Provider, with the configured key if any.
+func (pc ProviderConfig) NewProvider(log *zap.Logger, homepath string, debug bool, chainName string) (provider.ChainProvider, error) {
+	if err := pc.Validate(); err != nil {
+		return nil, err
+	}
+
+	pc.ChainName = chainName
+	if pc.Chain == nil {
+		pc.Chain = NewChain(pc.ChainID)
+	}
+	if pc.Timeout == "" {
+		pc.Timeout = "10s"
+	}
+	if pc.Broadcast == "" {
+		pc.Broadcast = provider.BroadcastModeBatch
+	}
+	if pc.ClientUpdate == "" {
+		pc.ClientUpdate = provider.ClientUpdateModeBundled
+	}
+
+	p := &Provider{
+		log:   log,
+		PCfg:  pc,
+		chain: pc.Chain,
+		cdc:   cosmos.MakeCodec(cosmos.ModuleBasics, nil, accountPrefix, accountPrefix+"valoper"),
+		keys:  make(map[string]string),
+	}
+	if pc.Key != "" {
+		if _, err := p.AddKey(pc.Key, 0, ""); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Provider is the ChainProvider of an in-memory Chain.
+type Provider struct {
+	log *zap.Logger
+
+	PCfg ProviderConfig
+
+	chain *Chain
+	cdc   cosmos.Codec
+
+	// keys maps the names of the keys to their addresses.
+	keysMu sync.Mutex
+	keys   map[string]string
+
+	// queries counts the queries served by the provider, which stand in for the RPC queries of a real chain.
+	queries atomic.Uint64
+}
+
+// Queries returns how many queries the provider served, including the blocks queried by its ChainProcessor.
+func (p *Provider) Queries() uint64 {
+	return p.queries.Load()
+}
+
+// queried counts n queries served by the provider.
+func (p *Provider) queried(n uint64) {
+	p.queries.Add(n)
+}
+
+// Chain returns the provided chain.
+func (p *Provider) Chain() *Chain {
+	return p.chain
+}
+
+func (p *Provider) ProviderConfig() provider.ProviderConfig {
+	return p.PCfg
+}
+
+func (p *Provider) ChainId() string {
+	return p.PCfg.ChainID
+}
+
+func (p *Provider) ChainName() string {
+	return p.PCfg.ChainName
+}
+
+func (p *Provider) Type() string {
+	return "memory"
+}
+
+func (p *Provider) Key() string {
+	return p.PCfg.Key
+}
+
+func (p *Provider) Timeout() string {
+	return p.PCfg.Timeout
+}
+
+func (p *Provider) CommitmentPrefix() commitmenttypes.MerklePrefix {
+	return commitmenttypes.NewMerklePrefix([]byte("ibc"))
+}
+
+func (p *Provider) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *Provider) SetRpcAddr(rpcAddr string) error {
+	return nil
+}
+
+// SetChainId fails unless chainID is the chain ID of the provided chain, which can not change.
+func (p *Provider) SetChainId(chainID string) error {
+	if chainID != p.chain.ChainID() {
+		return fmt.Errorf("%w: changing the chain-id of chain %s", ErrUnsupported, p.chain.ChainID())
+	}
+	return nil
+}
+
+// TrustingPeriod returns percentage of the unbonding period of the chain, in whole hours if longer than an hour.
+func (p *Provider) TrustingPeriod(ctx context.Context, overrideUnbondingPeriod time.Duration, percentage int64) (time.Duration, error) {
+	unbondingTime := overrideUnbondingPeriod
+	if unbondingTime == 0 {
+		unbondingTime = DefaultUnbondingPeriod
+	}
+	tp := time.Duration(int64(unbondingTime) / 100 * percentage)
+	if tp > time.Hour {
+		tp = tp.Truncate(time.Hour)
+	}
+	return tp, nil
+}
+
+// WaitForNBlocks produces n empty blocks.
+func (p *Provider) WaitForNBlocks(ctx context.Context, n int64) error {
+	for i := int64(0); i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.chain.Commit()
+	}
+	return nil
+}
+
+func (p *Provider) Sprint(toPrint proto.Message) (string, error) {
+	out, err := p.cdc.Marshaler.MarshalJSON(toPrint)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// keyAddress returns the address of the key with name, which is derived from the name.
+func keyAddress(name string) (string, error) {
+	h := sha256.Sum256([]byte(name))
+	return sdk.Bech32ifyAddressBytes(accountPrefix, h[:20])
+}
+
+// CreateKeystore is a no-op, keys are kept in memory.
+func (p *Provider) CreateKeystore(path string) error {
+	return nil
+}
+
+func (p *Provider) KeystoreCreated(path string) bool {
+	return true
+}
+
+// AddKey adds a key with name, whose address is derived from the name. There are no mnemonics.
+func (p *Provider) AddKey(name string, coinType uint32, signingAlgorithm string) (*provider.KeyOutput, error) {
+	address, err := p.RestoreKey(name, "", coinType, signingAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.KeyOutput{Address: address}, nil
+}
+
+func (p *Provider) UseKey(key string) error {
+	if !p.KeyExists(key) {
+		return fmt.Errorf("key %s does not exist for chain %s", key, p.ChainId())
+	}
+	p.PCfg.Key = key
+	return nil
+}
+
+// RestoreKey adds a key with name as AddKey does, the mnemonic is ignored.
+func (p *Provider) RestoreKey(name, mnemonic string, coinType uint32, signingAlgorithm string) (string, error) {
+	address, err := keyAddress(name)
+	if err != nil {
+		return "", err
+	}
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	if _, ok := p.keys[name]; ok {
+		return "", fmt.Errorf("key %s already exists for chain %s", name, p.ChainId())
+	}
+	p.keys[name] = address
+	return address, nil
+}
+
+func (p *Provider) ShowAddress(name string) (string, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	address, ok := p.keys[name]
+	if !ok {
+		return "", fmt.Errorf("key %s does not exist for chain %s", name, p.ChainId())
+	}
+	return address, nil
+}
+
+func (p *Provider) ListAddresses() (map[string]string, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	out := make(map[string]string, len(p.keys))
+	for name, address := range p.keys {
+		out[name] = address
+	}
+	return out, nil
+}
+
+func (p *Provider) DeleteKey(name string) error {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	if _, ok := p.keys[name]; !ok {
+		return fmt.Errorf("key %s does not exist for chain %s", name, p.ChainId())
+	}
+	delete(p.keys, name)
+	return nil
+}
+
+func (p *Provider) KeyExists(name string) bool {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	_, ok := p.keys[name]
+	return ok
+}
+
+func (p *Provider) ExportPrivKeyArmor(keyName, passphrase string) (string, error) {
+	return "", fmt.Errorf("%w: exporting keys", ErrUnsupported)
+}
+
+func (p *Provider) ImportPrivKeyArmor(name, armor, passphrase string) (string, error) {
+	return "", fmt.Errorf("%w: importing keys", ErrUnsupported)
+}
+
+// Address returns the address of the configured key.
+func (p *Provider) Address() (string, error) {
+	return p.ShowAddress(p.PCfg.Key)
+}
+
+// sortIdentifiers sorts identifiers with the same prefix and a sequence suffix, e.g. connection-2 before
+// connection-10, by sequence.
+func sortIdentifiers(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+}
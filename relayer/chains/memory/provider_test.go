@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestProvider(t *testing.T, chainID string) *Provider {
+	p, err := ProviderConfig{ChainID: chainID, Key: "relayer"}.NewProvider(zap.NewNop(), "", false, chainID)
+	require.NoError(t, err)
+	return p.(*Provider)
+}
+
+// createClient creates a client of the chain of counterparty on the chain of p.
+func createClient(ctx context.Context, t *testing.T, p, counterparty *Provider) string {
+	counterparty.chain.Commit()
+	header, err := counterparty.QueryIBCHeader(ctx, counterparty.chain.LatestHeight())
+	require.NoError(t, err)
+	cs, err := p.NewClientState(counterparty.ChainId(), header, time.Hour, DefaultUnbondingPeriod, time.Minute, false, false)
+	require.NoError(t, err)
+	msg, err := p.MsgCreateClient(cs, header.ConsensusState())
+	require.NoError(t, err)
+	res, success, err := p.SendMessage(ctx, msg, "")
+	require.NoError(t, err)
+	require.True(t, success)
+	for _, event := range res.Events {
+		if event.EventType == clienttypes.EventTypeCreateClient {
+			return event.Attributes[clienttypes.AttributeKeyClientID]
+		}
+	}
+	require.FailNow(t, "no create_client event")
+	return ""
+}
+
+func TestDeliverTxIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	a, b := newTestProvider(t, "chain-a"), newTestProvider(t, "chain-b")
+	clientID := createClient(ctx, t, a, b)
+
+	open, err := a.MsgConnectionOpenInit(provider.ConnectionInfo{
+		ClientID:                     clientID,
+		CounterpartyClientID:         "07-tendermint-0",
+		CounterpartyCommitmentPrefix: b.CommitmentPrefix(),
+	}, provider.ConnectionProof{})
+	require.NoError(t, err)
+	invalid, err := a.MsgConnectionOpenInit(provider.ConnectionInfo{
+		ClientID:                     "07-tendermint-9",
+		CounterpartyClientID:         "07-tendermint-0",
+		CounterpartyCommitmentPrefix: b.CommitmentPrefix(),
+	}, provider.ConnectionProof{})
+	require.NoError(t, err)
+
+	heightBefore := a.chain.LatestHeight()
+	res, success, err := a.SendMessages(ctx, []provider.RelayerMessage{open, invalid}, "")
+	require.Error(t, err)
+	require.False(t, success)
+	require.NotZero(t, res.Code)
+
+	// the failed tx is included in a block, but the valid message is not applied.
+	require.Equal(t, heightBefore+1, a.chain.LatestHeight())
+	conns, err := a.QueryConnections(ctx)
+	require.NoError(t, err)
+	require.Empty(t, conns)
+
+	_, success, err = a.SendMessages(ctx, []provider.RelayerMessage{open}, "")
+	require.NoError(t, err)
+	require.True(t, success)
+	conns, err = a.QueryConnections(ctx)
+	require.NoError(t, err)
+	require.Len(t, conns, 1)
+	require.Equal(t, "connection-0", conns[0].Id)
+	require.Equal(t, conntypes.INIT, conns[0].State)
+}
+
+func TestConnectionOpenTryVerifiesProof(t *testing.T) {
+	ctx := context.Background()
+	a, b := newTestProvider(t, "chain-a"), newTestProvider(t, "chain-b")
+	clientA := createClient(ctx, t, a, b)
+	clientB := createClient(ctx, t, b, a)
+
+	info := provider.ConnectionInfo{
+		ClientID:                     clientA,
+		CounterpartyClientID:         clientB,
+		CounterpartyCommitmentPrefix: b.CommitmentPrefix(),
+	}
+	init, err := a.MsgConnectionOpenInit(info, provider.ConnectionProof{})
+	require.NoError(t, err)
+	_, _, err = a.SendMessage(ctx, init, "")
+	require.NoError(t, err)
+	info.ConnID = "connection-0"
+
+	// the client on b has not been updated to a height at which the connection exists on a.
+	proof, err := a.ConnectionHandshakeProof(ctx, info, uint64(a.chain.LatestHeight()))
+	require.NoError(t, err)
+	try, err := b.MsgConnectionOpenTry(info, proof)
+	require.NoError(t, err)
+	_, success, err := b.SendMessage(ctx, try, "")
+	require.Error(t, err)
+	require.False(t, success)
+
+	// updating the client to the proof height first makes the proof valid.
+	header, err := a.QueryIBCHeader(ctx, int64(proof.ProofHeight.RevisionHeight))
+	require.NoError(t, err)
+	clientState, err := b.QueryClientState(ctx, 0, clientB)
+	require.NoError(t, err)
+	tmHeader, err := b.MsgUpdateClientHeader(header, clientState.GetLatestHeight().(clienttypes.Height), nil)
+	require.NoError(t, err)
+	update, err := b.MsgUpdateClient(clientB, tmHeader)
+	require.NoError(t, err)
+	_, success, err = b.SendMessages(ctx, []provider.RelayerMessage{update, try}, "")
+	require.NoError(t, err)
+	require.True(t, success)
+
+	res, err := b.QueryConnection(ctx, 0, "connection-0")
+	require.NoError(t, err)
+	require.Equal(t, conntypes.TRYOPEN, res.Connection.State)
+	require.Equal(t, "connection-0", res.Connection.Counterparty.ConnectionId)
+}
+
+func TestPacketProofsOfMissingPackets(t *testing.T) {
+	ctx := context.Background()
+	p := newTestProvider(t, "chain-a")
+	p.chain.Commit()
+
+	packet := provider.PacketInfo{
+		Sequence:      1,
+		SourcePort:    "transfer",
+		SourceChannel: "channel-0",
+		DestPort:      "transfer",
+		DestChannel:   "channel-0",
+	}
+	_, err := p.PacketCommitment(ctx, packet, 1)
+	require.ErrorIs(t, err, chantypes.ErrPacketCommitmentNotFound)
+	_, err = p.PacketAcknowledgement(ctx, packet, 1)
+	require.ErrorIs(t, err, chantypes.ErrInvalidAcknowledgement)
+}
+
+func TestQueryTxs(t *testing.T) {
+	ctx := context.Background()
+	a, b := newTestProvider(t, "chain-a"), newTestProvider(t, "chain-b")
+	clientID := createClient(ctx, t, a, b)
+	createClient(ctx, t, a, b)
+
+	txs, err := a.QueryTxs(ctx, 1, 10, []string{"create_client.client_id='" + clientID + "'"})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	txs, err = a.QueryTxs(ctx, 1, 10, []string{"create_client.client_type='07-tendermint'"})
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Less(t, txs[0].Height, txs[1].Height)
+
+	txs, err = a.QueryTxs(ctx, 2, 1, []string{"create_client.client_type='07-tendermint'"})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	_, err = a.QueryTxs(ctx, 1, 10, []string{"create_client"})
+	require.Error(t, err)
+}
+
+func TestKeys(t *testing.T) {
+	p := newTestProvider(t, "chain-a")
+	address, err := p.Address()
+	require.NoError(t, err)
+	_, err = sdk.GetFromBech32(address, accountPrefix)
+	require.NoError(t, err)
+
+	_, err = p.AddKey("relayer", 118, "")
+	require.Error(t, err)
+	other, err := p.AddKey("other", 118, "")
+	require.NoError(t, err)
+	require.NotEqual(t, address, other.Address)
+	require.NoError(t, p.UseKey("other"))
+	address, err = p.Address()
+	require.NoError(t, err)
+	require.Equal(t, other.Address, address)
+}
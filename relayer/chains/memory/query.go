@@ -0,0 +1,600 @@
+package memory
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// proofHeight returns the height of proofs queried at height, the latest height if 0.
+func (p *Provider) proofHeight(height, latest int64) clienttypes.Height {
+	if height <= 0 {
+		height = latest
+	}
+	return p.chain.height(uint64(height))
+}
+
+// marshalProof returns the proof of the marshaled m.
+func marshalProof(m proto.Message) ([]byte, error) {
+	bz, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return newProof(bz), nil
+}
+
+func (p *Provider) BlockTime(ctx context.Context, height int64) (time.Time, error) {
+	p.queried(1)
+	header, err := p.chain.Header(height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return header.Time, nil
+}
+
+func (p *Provider) QueryTx(ctx context.Context, hashHex string) (*provider.RelayerTxResponse, error) {
+	p.queried(1)
+	return p.chain.tx(hashHex)
+}
+
+// QueryTxs returns the txs with events matching all the events, of the form "{eventType}.{attributeKey}='{value}'".
+func (p *Provider) QueryTxs(ctx context.Context, page, limit int, events []string) ([]*provider.RelayerTxResponse, error) {
+	p.queried(1)
+	if len(events) == 0 {
+		return nil, fmt.Errorf("must declare at least one event to search")
+	}
+	if page <= 0 {
+		return nil, fmt.Errorf("page must greater than 0")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must greater than 0")
+	}
+
+	type condition struct{ eventType, key, value string }
+	var conditions []condition
+	for _, e := range events {
+		for _, q := range strings.Split(e, " AND ") {
+			k, v, ok := strings.Cut(q, "=")
+			eventType, key, ok2 := strings.Cut(strings.TrimSpace(k), ".")
+			if !ok || !ok2 {
+				return nil, fmt.Errorf("invalid event query %q", q)
+			}
+			conditions = append(conditions, condition{eventType, key, strings.Trim(strings.TrimSpace(v), "'")})
+		}
+	}
+
+	txs := p.chain.searchTxs(func(res *provider.RelayerTxResponse) bool {
+		if res.Code != 0 {
+			return false
+		}
+		for _, c := range conditions {
+			found := false
+			for _, event := range res.Events {
+				if event.EventType == c.eventType && event.Attributes[c.key] == c.value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	})
+
+	start := (page - 1) * limit
+	if start >= len(txs) {
+		return nil, nil
+	}
+	return txs[start:min(start+limit, len(txs))], nil
+}
+
+func (p *Provider) QueryLatestHeight(ctx context.Context) (int64, error) {
+	p.queried(1)
+	return p.chain.LatestHeight(), nil
+}
+
+func (p *Provider) QueryIBCHeader(ctx context.Context, h int64) (provider.IBCHeader, error) {
+	p.queried(1)
+	if h == 0 {
+		return nil, fmt.Errorf("height cannot be 0")
+	}
+	return p.chain.Header(h)
+}
+
+func (p *Provider) QuerySendPacket(ctx context.Context, srcChanID, srcPortID string, sequence uint64) (provider.PacketInfo, error) {
+	p.queried(1)
+	var pi provider.PacketInfo
+	err := p.chain.query(func(st *state, _ int64) error {
+		var ok bool
+		pi, ok = st.sent[packetKey{portChannel{srcPortID, srcChanID}, sequence}]
+		if !ok {
+			return fmt.Errorf("no send_packet found for sequence %d on channel %s port %s", sequence, srcChanID, srcPortID)
+		}
+		return nil
+	})
+	return pi, err
+}
+
+func (p *Provider) QueryRecvPacket(ctx context.Context, dstChanID, dstPortID string, sequence uint64) (provider.PacketInfo, error) {
+	p.queried(1)
+	var pi provider.PacketInfo
+	err := p.chain.query(func(st *state, _ int64) error {
+		var ok bool
+		pi, ok = st.received[packetKey{portChannel{dstPortID, dstChanID}, sequence}]
+		if !ok {
+			return fmt.Errorf("no write_acknowledgement found for sequence %d on channel %s port %s", sequence, dstChanID, dstPortID)
+		}
+		return nil
+	})
+	return pi, err
+}
+
+// QueryBalance returns no coins, in-memory chains have no bank module.
+func (p *Provider) QueryBalance(ctx context.Context, keyName string) (sdk.Coins, error) {
+	p.queried(1)
+	return sdk.NewCoins(), nil
+}
+
+// QueryBalanceWithAddress returns no coins, in-memory chains have no bank module.
+func (p *Provider) QueryBalanceWithAddress(ctx context.Context, addr string) (sdk.Coins, error) {
+	p.queried(1)
+	return sdk.NewCoins(), nil
+}
+
+func (p *Provider) QueryUnbondingPeriod(context.Context) (time.Duration, error) {
+	p.queried(1)
+	return DefaultUnbondingPeriod, nil
+}
+
+func (p *Provider) QueryClientState(ctx context.Context, height int64, clientid string) (ibcexported.ClientState, error) {
+	res, err := p.QueryClientStateResponse(ctx, height, clientid)
+	if err != nil {
+		return nil, err
+	}
+	return clienttypes.UnpackClientState(res.ClientState)
+}
+
+func (p *Provider) QueryClientStateResponse(ctx context.Context, height int64, srcClientId string) (*clienttypes.QueryClientStateResponse, error) {
+	p.queried(1)
+	var res *clienttypes.QueryClientStateResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		cl, ok := st.clients[srcClientId]
+		if !ok {
+			return errorsmod.Wrap(clienttypes.ErrClientNotFound, srcClientId)
+		}
+		anyClientState, err := clienttypes.PackClientState(&cl.clientState)
+		if err != nil {
+			return err
+		}
+		proof, err := marshalProof(&cl.clientState)
+		if err != nil {
+			return err
+		}
+		res = clienttypes.NewQueryClientStateResponse(anyClientState, proof, p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryClientConsensusState(ctx context.Context, chainHeight int64, clientid string, clientHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	p.queried(1)
+	var res *clienttypes.QueryConsensusStateResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		cl, ok := st.clients[clientid]
+		if !ok {
+			return errorsmod.Wrap(clienttypes.ErrClientNotFound, clientid)
+		}
+		consState, ok := cl.consensusStates[clienttypes.NewHeight(clientHeight.GetRevisionNumber(), clientHeight.GetRevisionHeight())]
+		if !ok {
+			return errorsmod.Wrapf(clienttypes.ErrConsensusStateNotFound, "client %s at height %s", clientid, clientHeight)
+		}
+		anyConsState, err := clienttypes.PackConsensusState(&consState)
+		if err != nil {
+			return err
+		}
+		proof, err := marshalProof(&consState)
+		if err != nil {
+			return err
+		}
+		res = clienttypes.NewQueryConsensusStateResponse(anyConsState, proof, p.proofHeight(chainHeight, latest))
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryUpgradedClient(ctx context.Context, height int64) (*clienttypes.QueryClientStateResponse, error) {
+	p.queried(1)
+	return nil, fmt.Errorf("%w: upgrades", ErrUnsupported)
+}
+
+func (p *Provider) QueryUpgradedConsState(ctx context.Context, height int64) (*clienttypes.QueryConsensusStateResponse, error) {
+	p.queried(1)
+	return nil, fmt.Errorf("%w: upgrades", ErrUnsupported)
+}
+
+// QueryConsensusState returns the consensus state of a client of the chain at height.
+func (p *Provider) QueryConsensusState(ctx context.Context, height int64) (ibcexported.ConsensusState, int64, error) {
+	p.queried(1)
+	header, err := p.chain.Header(height)
+	if err != nil {
+		return nil, 0, err
+	}
+	return header.ConsensusState(), height, nil
+}
+
+func (p *Provider) QueryClients(ctx context.Context) (clienttypes.IdentifiedClientStates, error) {
+	p.queried(1)
+	var clients clienttypes.IdentifiedClientStates
+	err := p.chain.query(func(st *state, _ int64) error {
+		ids := make([]string, 0, len(st.clients))
+		for id := range st.clients {
+			ids = append(ids, id)
+		}
+		sortIdentifiers(ids)
+		for _, id := range ids {
+			cs := st.clients[id].clientState
+			clients = append(clients, clienttypes.NewIdentifiedClientState(id, &cs))
+		}
+		return nil
+	})
+	return clients, err
+}
+
+func (p *Provider) QueryConnection(ctx context.Context, height int64, connectionid string) (*conntypes.QueryConnectionResponse, error) {
+	p.queried(1)
+	var res *conntypes.QueryConnectionResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		end, ok := st.connections[connectionid]
+		if !ok {
+			return errorsmod.Wrap(conntypes.ErrConnectionNotFound, connectionid)
+		}
+		proof, err := marshalProof(&end)
+		if err != nil {
+			return err
+		}
+		res = conntypes.NewQueryConnectionResponse(end, proof, p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+// identifiedConnections returns the connections for which match returns true.
+func identifiedConnections(st *state, match func(conntypes.ConnectionEnd) bool) []*conntypes.IdentifiedConnection {
+	ids := make([]string, 0, len(st.connections))
+	for id, end := range st.connections {
+		if match(end) {
+			ids = append(ids, id)
+		}
+	}
+	sortIdentifiers(ids)
+	conns := make([]*conntypes.IdentifiedConnection, 0, len(ids))
+	for _, id := range ids {
+		conn := conntypes.NewIdentifiedConnection(id, st.connections[id])
+		conns = append(conns, &conn)
+	}
+	return conns
+}
+
+func (p *Provider) QueryConnections(ctx context.Context) ([]*conntypes.IdentifiedConnection, error) {
+	p.queried(1)
+	var conns []*conntypes.IdentifiedConnection
+	err := p.chain.query(func(st *state, _ int64) error {
+		conns = identifiedConnections(st, func(conntypes.ConnectionEnd) bool { return true })
+		return nil
+	})
+	return conns, err
+}
+
+func (p *Provider) QueryConnectionsUsingClient(ctx context.Context, height int64, clientid string) (*conntypes.QueryConnectionsResponse, error) {
+	p.queried(1)
+	res := &conntypes.QueryConnectionsResponse{}
+	err := p.chain.query(func(st *state, latest int64) error {
+		res.Connections = identifiedConnections(st, func(end conntypes.ConnectionEnd) bool {
+			return end.ClientId == clientid
+		})
+		res.Height = p.proofHeight(height, latest)
+		return nil
+	})
+	return res, err
+}
+
+// GenerateConnHandshakeProof returns the client state of the connection with the proofs of the client state,
+// of its consensus state at its latest height and of the connection.
+func (p *Provider) GenerateConnHandshakeProof(ctx context.Context, height int64, clientId, connId string) (
+	clientState ibcexported.ClientState, clientStateProof []byte, consensusProof []byte, connectionProof []byte,
+	connectionProofHeight ibcexported.Height, err error,
+) {
+	p.queried(1)
+	err = p.chain.query(func(st *state, latest int64) error {
+		cl, ok := st.clients[clientId]
+		if !ok {
+			return errorsmod.Wrap(clienttypes.ErrClientNotFound, clientId)
+		}
+		end, ok := st.connections[connId]
+		if !ok {
+			return errorsmod.Wrap(conntypes.ErrConnectionNotFound, connId)
+		}
+		cs := cl.clientState
+		consState := cl.consensusStates[cs.LatestHeight]
+
+		var err error
+		if clientStateProof, err = marshalProof(&cs); err != nil {
+			return err
+		}
+		if consensusProof, err = marshalProof(&consState); err != nil {
+			return err
+		}
+		if connectionProof, err = marshalProof(&end); err != nil {
+			return err
+		}
+		clientState = &cs
+		connectionProofHeight = p.proofHeight(height, latest)
+		return nil
+	})
+	return clientState, clientStateProof, consensusProof, connectionProof, connectionProofHeight, err
+}
+
+func (p *Provider) QueryChannel(ctx context.Context, height int64, channelid, portid string) (*chantypes.QueryChannelResponse, error) {
+	p.queried(1)
+	var res *chantypes.QueryChannelResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		ch, ok := st.channels[portChannel{portid, channelid}]
+		if !ok {
+			return errorsmod.Wrapf(chantypes.ErrChannelNotFound, "port-id: %s, channel-id: %s", portid, channelid)
+		}
+		proof, err := marshalProof(&ch)
+		if err != nil {
+			return err
+		}
+		res = chantypes.NewQueryChannelResponse(ch, proof, p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryChannelClient(ctx context.Context, height int64, channelid, portid string) (*clienttypes.IdentifiedClientState, error) {
+	p.queried(1)
+	var res *clienttypes.IdentifiedClientState
+	err := p.chain.query(func(st *state, _ int64) error {
+		ch, ok := st.channels[portChannel{portid, channelid}]
+		if !ok {
+			return errorsmod.Wrapf(chantypes.ErrChannelNotFound, "port-id: %s, channel-id: %s", portid, channelid)
+		}
+		end, ok := st.connections[ch.ConnectionHops[0]]
+		if !ok {
+			return errorsmod.Wrap(conntypes.ErrConnectionNotFound, ch.ConnectionHops[0])
+		}
+		cs := st.clients[end.ClientId].clientState
+		identified := clienttypes.NewIdentifiedClientState(end.ClientId, &cs)
+		res = &identified
+		return nil
+	})
+	return res, err
+}
+
+// identifiedChannels returns the channels for which match returns true.
+func identifiedChannels(st *state, match func(chantypes.Channel) bool) []*chantypes.IdentifiedChannel {
+	var keys []portChannel
+	for k, ch := range st.channels {
+		if match(ch) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if len(a.channelID) != len(b.channelID) {
+			return len(a.channelID) < len(b.channelID)
+		}
+		if a.channelID != b.channelID {
+			return a.channelID < b.channelID
+		}
+		return a.portID < b.portID
+	})
+	channels := make([]*chantypes.IdentifiedChannel, 0, len(keys))
+	for _, k := range keys {
+		ch := chantypes.NewIdentifiedChannel(k.portID, k.channelID, st.channels[k])
+		channels = append(channels, &ch)
+	}
+	return channels
+}
+
+func (p *Provider) QueryConnectionChannels(ctx context.Context, height int64, connectionid string) ([]*chantypes.IdentifiedChannel, error) {
+	p.queried(1)
+	var channels []*chantypes.IdentifiedChannel
+	err := p.chain.query(func(st *state, _ int64) error {
+		channels = identifiedChannels(st, func(ch chantypes.Channel) bool {
+			return len(ch.ConnectionHops) > 0 && ch.ConnectionHops[0] == connectionid
+		})
+		return nil
+	})
+	return channels, err
+}
+
+func (p *Provider) QueryChannels(ctx context.Context) ([]*chantypes.IdentifiedChannel, error) {
+	p.queried(1)
+	var channels []*chantypes.IdentifiedChannel
+	err := p.chain.query(func(st *state, _ int64) error {
+		channels = identifiedChannels(st, func(chantypes.Channel) bool { return true })
+		return nil
+	})
+	return channels, err
+}
+
+// packetStates returns the values stored for the packets of a channel, by sequence.
+func packetStates(values map[packetKey][]byte, k portChannel) []*chantypes.PacketState {
+	states := []*chantypes.PacketState{}
+	for pk, v := range values {
+		if pk.portChannel == k {
+			state := chantypes.NewPacketState(k.portID, k.channelID, pk.sequence, v)
+			states = append(states, &state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Sequence < states[j].Sequence })
+	return states
+}
+
+func (p *Provider) QueryPacketCommitments(ctx context.Context, height uint64, channelid, portid string) (*chantypes.QueryPacketCommitmentsResponse, error) {
+	p.queried(1)
+	res := &chantypes.QueryPacketCommitmentsResponse{}
+	err := p.chain.query(func(st *state, latest int64) error {
+		res.Commitments = packetStates(st.commitments, portChannel{portid, channelid})
+		res.Height = p.proofHeight(int64(height), latest)
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryPacketAcknowledgements(ctx context.Context, height uint64, channelid, portid string) ([]*chantypes.PacketState, error) {
+	p.queried(1)
+	var acks []*chantypes.PacketState
+	err := p.chain.query(func(st *state, _ int64) error {
+		acks = packetStates(st.acks, portChannel{portid, channelid})
+		return nil
+	})
+	return acks, err
+}
+
+// QueryUnreceivedPackets returns the sequences of the packets sent to the channel which it did not receive.
+func (p *Provider) QueryUnreceivedPackets(ctx context.Context, height uint64, channelid, portid string, seqs []uint64) ([]uint64, error) {
+	p.queried(1)
+	var unreceived []uint64
+	err := p.chain.query(func(st *state, _ int64) error {
+		k := portChannel{portid, channelid}
+		ch, ok := st.channels[k]
+		if !ok {
+			return errorsmod.Wrapf(chantypes.ErrChannelNotFound, "port-id: %s, channel-id: %s", portid, channelid)
+		}
+		for _, seq := range seqs {
+			received := st.receipts[packetKey{k, seq}]
+			if ch.Ordering == chantypes.ORDERED {
+				received = seq < st.nextSeqRecv[k]
+			}
+			if !received {
+				unreceived = append(unreceived, seq)
+			}
+		}
+		return nil
+	})
+	return unreceived, err
+}
+
+// QueryUnreceivedAcknowledgements returns the sequences of the packets sent from the channel whose
+// acknowledgement it did not receive, i.e. whose commitments still exist.
+func (p *Provider) QueryUnreceivedAcknowledgements(ctx context.Context, height uint64, channelid, portid string, seqs []uint64) ([]uint64, error) {
+	p.queried(1)
+	var unreceived []uint64
+	err := p.chain.query(func(st *state, _ int64) error {
+		k := portChannel{portid, channelid}
+		for _, seq := range seqs {
+			if _, ok := st.commitments[packetKey{k, seq}]; ok {
+				unreceived = append(unreceived, seq)
+			}
+		}
+		return nil
+	})
+	return unreceived, err
+}
+
+// nextSequence returns the next sequence of the channel in seqs with its proof.
+func (p *Provider) nextSequence(
+	height int64, channelid, portid string, seqs func(st *state) map[portChannel]uint64,
+) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	var res *chantypes.QueryNextSequenceReceiveResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		seq, ok := seqs(st)[portChannel{portid, channelid}]
+		if !ok {
+			return errorsmod.Wrapf(chantypes.ErrChannelNotFound, "port-id: %s, channel-id: %s", portid, channelid)
+		}
+		proof := newProof(binary.BigEndian.AppendUint64(nil, seq))
+		res = chantypes.NewQueryNextSequenceReceiveResponse(seq, proof, p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryNextSeqRecv(ctx context.Context, height int64, channelid, portid string) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	p.queried(1)
+	return p.nextSequence(height, channelid, portid, func(st *state) map[portChannel]uint64 { return st.nextSeqRecv })
+}
+
+func (p *Provider) QueryNextSeqAck(ctx context.Context, height int64, channelid, portid string) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	p.queried(1)
+	return p.nextSequence(height, channelid, portid, func(st *state) map[portChannel]uint64 { return st.nextSeqAck })
+}
+
+func (p *Provider) QueryPacketCommitment(ctx context.Context, height int64, channelid, portid string, seq uint64) (*chantypes.QueryPacketCommitmentResponse, error) {
+	p.queried(1)
+	var res *chantypes.QueryPacketCommitmentResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		commitment, ok := st.commitments[packetKey{portChannel{portid, channelid}, seq}]
+		if !ok {
+			return errorsmod.Wrapf(chantypes.ErrPacketCommitmentNotFound, "port-id: %s, channel-id: %s, sequence: %d", portid, channelid, seq)
+		}
+		res = chantypes.NewQueryPacketCommitmentResponse(commitment, newProof(commitment), p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryPacketAcknowledgement(ctx context.Context, height int64, channelid, portid string, seq uint64) (*chantypes.QueryPacketAcknowledgementResponse, error) {
+	p.queried(1)
+	var res *chantypes.QueryPacketAcknowledgementResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		ack, ok := st.acks[packetKey{portChannel{portid, channelid}, seq}]
+		if !ok {
+			return errorsmod.Wrapf(chantypes.ErrInvalidAcknowledgement, "acknowledgement not found, port-id: %s, channel-id: %s, sequence: %d", portid, channelid, seq)
+		}
+		res = chantypes.NewQueryPacketAcknowledgementResponse(ack, newProof(ack), p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+func (p *Provider) QueryPacketReceipt(ctx context.Context, height int64, channelid, portid string, seq uint64) (*chantypes.QueryPacketReceiptResponse, error) {
+	p.queried(1)
+	var res *chantypes.QueryPacketReceiptResponse
+	err := p.chain.query(func(st *state, latest int64) error {
+		received := st.receipts[packetKey{portChannel{portid, channelid}, seq}]
+		res = chantypes.NewQueryPacketReceiptResponse(received, receiptProof(received), p.proofHeight(height, latest))
+		return nil
+	})
+	return res, err
+}
+
+// receiptProof returns the proof of a packet receipt, or of its absence.
+func receiptProof(received bool) []byte {
+	if !received {
+		return newProof(nil)
+	}
+	return newProof([]byte{byte(1)})
+}
+
+func (p *Provider) QueryDenomTrace(ctx context.Context, denom string) (*transfertypes.DenomTrace, error) {
+	p.queried(1)
+	return nil, fmt.Errorf("%w: denom traces", ErrUnsupported)
+}
+
+func (p *Provider) QueryDenomTraces(ctx context.Context, offset, limit uint64, height int64) ([]transfertypes.DenomTrace, error) {
+	p.queried(1)
+	return nil, fmt.Errorf("%w: denom traces", ErrUnsupported)
+}
+
+// QueryDenomHash returns the hash of the denom trace, which in-memory chains do not store.
+func (p *Provider) QueryDenomHash(ctx context.Context, trace string) (string, error) {
+	p.queried(1)
+	return transfertypes.ParseDenomTrace(trace).Hash().String(), nil
+}
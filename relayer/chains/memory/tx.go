@@ -0,0 +1,622 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cometbft/cometbft/light"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// defaultDelayPeriod is the delay period of the connections, as for cosmos chains.
+const defaultDelayPeriod = uint64(0)
+
+// SendMessage sends msg in a tx, see SendMessages.
+func (p *Provider) SendMessage(ctx context.Context, msg provider.RelayerMessage, memo string) (*provider.RelayerTxResponse, bool, error) {
+	return p.SendMessages(ctx, []provider.RelayerMessage{msg}, memo)
+}
+
+// SendMessages delivers msgs to the chain in a tx, which is included in a new block before it returns.
+func (p *Provider) SendMessages(ctx context.Context, msgs []provider.RelayerMessage, memo string) (*provider.RelayerTxResponse, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	sdkMsgs, err := sdkMsgs(msgs)
+	if err != nil {
+		return nil, false, err
+	}
+	res, err := p.chain.DeliverTx(sdkMsgs)
+	if err != nil {
+		p.log.Debug("Tx failed", zap.String("chain_id", p.ChainId()), zap.Error(err))
+		return res, false, err
+	}
+	return res, true, nil
+}
+
+// SendMessagesToMempool delivers msgs to the chain as SendMessages does, then calls the callbacks asynchronously
+// with the result, as if the tx were included in a block later.
+func (p *Provider) SendMessagesToMempool(
+	ctx context.Context,
+	msgs []provider.RelayerMessage,
+	memo string,
+
+	asyncCtx context.Context,
+	asyncCallbacks []func(*provider.RelayerTxResponse, error),
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	sdkMsgs, err := sdkMsgs(msgs)
+	if err != nil {
+		return err
+	}
+	res, err := p.chain.DeliverTx(sdkMsgs)
+	go func() {
+		for _, cb := range asyncCallbacks {
+			cb(res, err)
+		}
+	}()
+	return nil
+}
+
+// sdkMsgs returns the sdk.Msgs of msgs, which must be cosmos.CosmosMessages.
+func sdkMsgs(msgs []provider.RelayerMessage) ([]sdk.Msg, error) {
+	out := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		cosmosMsg, ok := msg.(cosmos.CosmosMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected message type %T, expected %T", msg, cosmos.CosmosMessage{})
+		}
+		out = append(out, cosmosMsg.Msg)
+	}
+	return out, nil
+}
+
+// NewClientState returns the state of a 07-tendermint client of the chain with dstChainID, as for cosmos chains.
+func (p *Provider) NewClientState(
+	dstChainID string,
+	dstUpdateHeader provider.IBCHeader,
+	dstTrustingPeriod,
+	dstUbdPeriod,
+	maxClockDrift time.Duration,
+	allowUpdateAfterExpiry,
+	allowUpdateAfterMisbehaviour bool,
+) (ibcexported.ClientState, error) {
+	return &tmclient.ClientState{
+		ChainId:         dstChainID,
+		TrustLevel:      tmclient.NewFractionFromTm(light.DefaultTrustLevel),
+		TrustingPeriod:  dstTrustingPeriod,
+		UnbondingPeriod: dstUbdPeriod,
+		MaxClockDrift:   maxClockDrift,
+		FrozenHeight:    clienttypes.ZeroHeight(),
+		LatestHeight: clienttypes.Height{
+			RevisionNumber: clienttypes.ParseChainID(dstChainID),
+			RevisionHeight: dstUpdateHeader.Height(),
+		},
+		ProofSpecs:                   commitmenttypes.GetSDKSpecs(),
+		AllowUpdateAfterExpiry:       allowUpdateAfterExpiry,
+		AllowUpdateAfterMisbehaviour: allowUpdateAfterMisbehaviour,
+	}, nil
+}
+
+func (p *Provider) MsgCreateClient(clientState ibcexported.ClientState, consensusState ibcexported.ConsensusState) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	anyClientState, err := clienttypes.PackClientState(clientState)
+	if err != nil {
+		return nil, err
+	}
+	anyConsensusState, err := clienttypes.PackConsensusState(consensusState)
+	if err != nil {
+		return nil, err
+	}
+	msg := &clienttypes.MsgCreateClient{
+		ClientState:    anyClientState,
+		ConsensusState: anyConsensusState,
+		Signer:         signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgUpgradeClient(srcClientId string, consRes *clienttypes.QueryConsensusStateResponse, clientRes *clienttypes.QueryClientStateResponse) (provider.RelayerMessage, error) {
+	return nil, fmt.Errorf("%w: upgrades", ErrUnsupported)
+}
+
+func (p *Provider) MsgSubmitMisbehaviour(clientID string, misbehaviour ibcexported.ClientMessage) (provider.RelayerMessage, error) {
+	return nil, fmt.Errorf("%w: misbehaviour", ErrUnsupported)
+}
+
+// MsgUpdateClientHeader returns latestHeader as a 07-tendermint header, which must be a Header of an in-memory chain.
+func (p *Provider) MsgUpdateClientHeader(latestHeader provider.IBCHeader, trustedHeight clienttypes.Height, trustedHeader provider.IBCHeader) (ibcexported.ClientMessage, error) {
+	header, ok := latestHeader.(Header)
+	if !ok {
+		return nil, fmt.Errorf("unsupported IBC header type, expected: %T, actual: %T", Header{}, latestHeader)
+	}
+	return header.TMHeader(trustedHeight), nil
+}
+
+func (p *Provider) MsgUpdateClient(clientID string, counterpartyHeader ibcexported.ClientMessage) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	clientMsg, err := clienttypes.PackClientMessage(counterpartyHeader)
+	if err != nil {
+		return nil, err
+	}
+	msg := &clienttypes.MsgUpdateClient{
+		ClientId:      clientID,
+		ClientMessage: clientMsg,
+		Signer:        signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgTransfer(dstAddr string, amount sdk.Coin, memo string, info provider.PacketInfo) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &transfertypes.MsgTransfer{
+		SourcePort:       info.SourcePort,
+		SourceChannel:    info.SourceChannel,
+		Token:            amount,
+		Sender:           signer,
+		Receiver:         dstAddr,
+		TimeoutHeight:    info.TimeoutHeight,
+		TimeoutTimestamp: info.TimeoutTimestamp,
+		Memo:             memo,
+	}
+	return cosmos.NewCosmosMessage(msg, nil), nil
+}
+
+func (p *Provider) ValidatePacket(msgTransfer provider.PacketInfo, latest provider.LatestBlock) error {
+	if msgTransfer.Sequence == 0 {
+		return errors.New("refusing to relay packet with sequence: 0")
+	}
+	if len(msgTransfer.Data) == 0 {
+		return errors.New("refusing to relay packet with empty data")
+	}
+	if msgTransfer.TimeoutHeight.IsZero() && msgTransfer.TimeoutTimestamp == 0 {
+		return errors.New("refusing to relay packet without a timeout (height or timestamp must be set)")
+	}
+	return msgTransfer.TimeoutElapsed(p.chain.revision, latest)
+}
+
+func (p *Provider) PacketCommitment(ctx context.Context, msgTransfer provider.PacketInfo, height uint64) (provider.PacketProof, error) {
+	res, err := p.QueryPacketCommitment(ctx, int64(height), msgTransfer.SourceChannel, msgTransfer.SourcePort, msgTransfer.Sequence)
+	if err != nil {
+		if errors.Is(err, chantypes.ErrPacketCommitmentNotFound) {
+			return provider.PacketProof{}, chantypes.ErrPacketCommitmentNotFound
+		}
+		return provider.PacketProof{}, err
+	}
+	return provider.PacketProof{Proof: res.Proof, ProofHeight: res.ProofHeight}, nil
+}
+
+func (p *Provider) MsgRecvPacket(msgTransfer provider.PacketInfo, proof provider.PacketProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgRecvPacket{
+		Packet:          msgTransfer.Packet(),
+		ProofCommitment: proof.Proof,
+		ProofHeight:     proof.ProofHeight,
+		Signer:          signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) PacketAcknowledgement(ctx context.Context, msgRecvPacket provider.PacketInfo, height uint64) (provider.PacketProof, error) {
+	res, err := p.QueryPacketAcknowledgement(ctx, int64(height), msgRecvPacket.DestChannel, msgRecvPacket.DestPort, msgRecvPacket.Sequence)
+	if err != nil {
+		if errors.Is(err, chantypes.ErrInvalidAcknowledgement) {
+			return provider.PacketProof{}, chantypes.ErrInvalidAcknowledgement
+		}
+		return provider.PacketProof{}, err
+	}
+	return provider.PacketProof{Proof: res.Proof, ProofHeight: res.ProofHeight}, nil
+}
+
+func (p *Provider) MsgAcknowledgement(msgRecvPacket provider.PacketInfo, proof provider.PacketProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgAcknowledgement{
+		Packet:          msgRecvPacket.Packet(),
+		Acknowledgement: msgRecvPacket.Ack,
+		ProofAcked:      proof.Proof,
+		ProofHeight:     proof.ProofHeight,
+		Signer:          signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) PacketReceipt(ctx context.Context, msgTransfer provider.PacketInfo, height uint64) (provider.PacketProof, error) {
+	res, err := p.QueryPacketReceipt(ctx, int64(height), msgTransfer.DestChannel, msgTransfer.DestPort, msgTransfer.Sequence)
+	if err != nil {
+		return provider.PacketProof{}, err
+	}
+	return provider.PacketProof{Proof: res.Proof, ProofHeight: res.ProofHeight}, nil
+}
+
+func (p *Provider) NextSeqRecv(ctx context.Context, msgTransfer provider.PacketInfo, height uint64) (provider.PacketProof, error) {
+	res, err := p.QueryNextSeqRecv(ctx, int64(height), msgTransfer.DestChannel, msgTransfer.DestPort)
+	if err != nil {
+		return provider.PacketProof{}, err
+	}
+	return provider.PacketProof{Proof: res.Proof, ProofHeight: res.ProofHeight}, nil
+}
+
+func (p *Provider) MsgTimeout(msgTransfer provider.PacketInfo, proof provider.PacketProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgTimeout{
+		Packet:           msgTransfer.Packet(),
+		ProofUnreceived:  proof.Proof,
+		ProofHeight:      proof.ProofHeight,
+		NextSequenceRecv: msgTransfer.Sequence,
+		Signer:           signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgTimeoutOnClose(msgTransfer provider.PacketInfo, proof provider.PacketProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgTimeoutOnClose{
+		Packet:           msgTransfer.Packet(),
+		ProofUnreceived:  proof.Proof,
+		ProofHeight:      proof.ProofHeight,
+		NextSequenceRecv: msgTransfer.Sequence,
+		Signer:           signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgConnectionOpenInit(info provider.ConnectionInfo, proof provider.ConnectionProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &conntypes.MsgConnectionOpenInit{
+		ClientId: info.ClientID,
+		Counterparty: conntypes.Counterparty{
+			ClientId:     info.CounterpartyClientID,
+			ConnectionId: "",
+			Prefix:       info.CounterpartyCommitmentPrefix,
+		},
+		Version:     nil,
+		DelayPeriod: defaultDelayPeriod,
+		Signer:      signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) ConnectionHandshakeProof(ctx context.Context, msgOpenInit provider.ConnectionInfo, height uint64) (provider.ConnectionProof, error) {
+	clientState, clientStateProof, consensusStateProof, connStateProof, proofHeight, err := p.GenerateConnHandshakeProof(ctx, int64(height), msgOpenInit.ClientID, msgOpenInit.ConnID)
+	if err != nil {
+		return provider.ConnectionProof{}, err
+	}
+	return provider.ConnectionProof{
+		ClientState:          clientState,
+		ClientStateProof:     clientStateProof,
+		ConsensusStateProof:  consensusStateProof,
+		ConnectionStateProof: connStateProof,
+		ProofHeight:          proofHeight.(clienttypes.Height),
+	}, nil
+}
+
+func (p *Provider) MsgConnectionOpenTry(msgOpenInit provider.ConnectionInfo, proof provider.ConnectionProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	csAny, err := clienttypes.PackClientState(proof.ClientState)
+	if err != nil {
+		return nil, err
+	}
+	msg := &conntypes.MsgConnectionOpenTry{
+		ClientId:             msgOpenInit.CounterpartyClientID,
+		PreviousConnectionId: msgOpenInit.CounterpartyConnID,
+		ClientState:          csAny,
+		Counterparty: conntypes.Counterparty{
+			ClientId:     msgOpenInit.ClientID,
+			ConnectionId: msgOpenInit.ConnID,
+			Prefix:       msgOpenInit.CounterpartyCommitmentPrefix,
+		},
+		DelayPeriod:          defaultDelayPeriod,
+		CounterpartyVersions: conntypes.GetCompatibleVersions(),
+		ProofHeight:          proof.ProofHeight,
+		ProofInit:            proof.ConnectionStateProof,
+		ProofClient:          proof.ClientStateProof,
+		ProofConsensus:       proof.ConsensusStateProof,
+		ConsensusHeight:      proof.ClientState.GetLatestHeight().(clienttypes.Height),
+		Signer:               signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgConnectionOpenAck(msgOpenTry provider.ConnectionInfo, proof provider.ConnectionProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	csAny, err := clienttypes.PackClientState(proof.ClientState)
+	if err != nil {
+		return nil, err
+	}
+	msg := &conntypes.MsgConnectionOpenAck{
+		ConnectionId:             msgOpenTry.CounterpartyConnID,
+		CounterpartyConnectionId: msgOpenTry.ConnID,
+		Version:                  conntypes.DefaultIBCVersion,
+		ClientState:              csAny,
+		ProofHeight:              proof.ProofHeight,
+		ProofTry:                 proof.ConnectionStateProof,
+		ProofClient:              proof.ClientStateProof,
+		ProofConsensus:           proof.ConsensusStateProof,
+		ConsensusHeight:          proof.ClientState.GetLatestHeight().(clienttypes.Height),
+		Signer:                   signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) ConnectionProof(ctx context.Context, msgOpenAck provider.ConnectionInfo, height uint64) (provider.ConnectionProof, error) {
+	res, err := p.QueryConnection(ctx, int64(height), msgOpenAck.ConnID)
+	if err != nil {
+		return provider.ConnectionProof{}, err
+	}
+	return provider.ConnectionProof{
+		ConnectionStateProof: res.Proof,
+		ProofHeight:          res.ProofHeight,
+	}, nil
+}
+
+func (p *Provider) MsgConnectionOpenConfirm(msgOpenAck provider.ConnectionInfo, proof provider.ConnectionProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &conntypes.MsgConnectionOpenConfirm{
+		ConnectionId: msgOpenAck.CounterpartyConnID,
+		ProofAck:     proof.ConnectionStateProof,
+		ProofHeight:  proof.ProofHeight,
+		Signer:       signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) ChannelProof(ctx context.Context, msg provider.ChannelInfo, height uint64) (provider.ChannelProof, error) {
+	res, err := p.QueryChannel(ctx, int64(height), msg.ChannelID, msg.PortID)
+	if err != nil {
+		return provider.ChannelProof{}, err
+	}
+	return provider.ChannelProof{
+		Proof:       res.Proof,
+		ProofHeight: res.ProofHeight,
+		Version:     res.Channel.Version,
+		Ordering:    res.Channel.Ordering,
+	}, nil
+}
+
+func (p *Provider) MsgChannelOpenInit(info provider.ChannelInfo, proof provider.ChannelProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgChannelOpenInit{
+		PortId: info.PortID,
+		Channel: chantypes.Channel{
+			State:    chantypes.INIT,
+			Ordering: info.Order,
+			Counterparty: chantypes.Counterparty{
+				PortId:    info.CounterpartyPortID,
+				ChannelId: "",
+			},
+			ConnectionHops: info.Hops(),
+			Version:        info.Version,
+		},
+		Signer: signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgChannelOpenTry(msgOpenInit provider.ChannelInfo, proof provider.ChannelProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgChannelOpenTry{
+		PortId:            msgOpenInit.CounterpartyPortID,
+		PreviousChannelId: msgOpenInit.CounterpartyChannelID,
+		Channel: chantypes.Channel{
+			State:    chantypes.TRYOPEN,
+			Ordering: proof.Ordering,
+			Counterparty: chantypes.Counterparty{
+				PortId:    msgOpenInit.PortID,
+				ChannelId: msgOpenInit.ChannelID,
+			},
+			ConnectionHops: msgOpenInit.CounterpartyHops(),
+			Version:        proof.Version,
+		},
+		CounterpartyVersion: proof.Version,
+		ProofInit:           proof.Proof,
+		ProofHeight:         proof.ProofHeight,
+		Signer:              signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgChannelOpenAck(msgOpenTry provider.ChannelInfo, proof provider.ChannelProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgChannelOpenAck{
+		PortId:                msgOpenTry.CounterpartyPortID,
+		ChannelId:             msgOpenTry.CounterpartyChannelID,
+		CounterpartyChannelId: msgOpenTry.ChannelID,
+		CounterpartyVersion:   proof.Version,
+		ProofTry:              proof.Proof,
+		ProofHeight:           proof.ProofHeight,
+		Signer:                signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgChannelOpenConfirm(msgOpenAck provider.ChannelInfo, proof provider.ChannelProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgChannelOpenConfirm{
+		PortId:      msgOpenAck.CounterpartyPortID,
+		ChannelId:   msgOpenAck.CounterpartyChannelID,
+		ProofAck:    proof.Proof,
+		ProofHeight: proof.ProofHeight,
+		Signer:      signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgChannelCloseInit(info provider.ChannelInfo, proof provider.ChannelProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgChannelCloseInit{
+		PortId:    info.PortID,
+		ChannelId: info.ChannelID,
+		Signer:    signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+func (p *Provider) MsgChannelCloseConfirm(msgCloseInit provider.ChannelInfo, proof provider.ChannelProof) (provider.RelayerMessage, error) {
+	signer, err := p.Address()
+	if err != nil {
+		return nil, err
+	}
+	msg := &chantypes.MsgChannelCloseConfirm{
+		PortId:      msgCloseInit.CounterpartyPortID,
+		ChannelId:   msgCloseInit.CounterpartyChannelID,
+		ProofInit:   proof.Proof,
+		ProofHeight: proof.ProofHeight,
+		Signer:      signer,
+	}
+	return cosmos.NewCosmosMessage(msg, func(signer string) {
+		msg.Signer = signer
+	}), nil
+}
+
+// errChannelUpgradeNotSupported is returned by the channel upgrade handshake methods.
+var errChannelUpgradeNotSupported = fmt.Errorf("%w: channel upgrades", ErrUnsupported)
+
+func (p *Provider) ChannelUpgradeProof(ctx context.Context, msg provider.ChannelInfo, height uint64) (provider.ChannelUpgradeProof, error) {
+	return provider.ChannelUpgradeProof{}, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) ChannelUpgradeErrorProof(ctx context.Context, msgUpgradeError provider.ChannelInfo, height uint64) (provider.ChannelUpgradeProof, error) {
+	return provider.ChannelUpgradeProof{}, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) MsgChannelUpgradeTry(msgUpgradeInit provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) MsgChannelUpgradeAck(msgUpgradeTry provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) MsgChannelUpgradeConfirm(msgUpgradeAck provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) MsgChannelUpgradeOpen(info provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) MsgChannelUpgradeTimeout(info provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) MsgChannelUpgradeCancel(msgUpgradeError provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (p *Provider) QueryICQWithProof(ctx context.Context, msgType string, request []byte, height uint64) (provider.ICQProof, error) {
+	p.queried(1)
+	return provider.ICQProof{}, fmt.Errorf("%w: interchain queries", ErrUnsupported)
+}
+
+func (p *Provider) MsgSubmitQueryResponse(chainID string, queryID provider.ClientICQQueryID, proof provider.ICQProof) (provider.RelayerMessage, error) {
+	return nil, fmt.Errorf("%w: interchain queries", ErrUnsupported)
+}
+
+func (p *Provider) RelayPacketFromSequence(
+	ctx context.Context,
+	src provider.ChainProvider,
+	srch, dsth, seq uint64,
+	srcChanID, srcPortID string,
+	order chantypes.Order,
+) (provider.RelayerMessage, provider.RelayerMessage, error) {
+	return nil, nil, fmt.Errorf("%w: relaying packets from sequences, use the event processor", ErrUnsupported)
+}
+
+func (p *Provider) AcknowledgementFromSequence(ctx context.Context, dst provider.ChainProvider, dsth, seq uint64, dstChanId, dstPortId, srcChanId, srcPortId string) (provider.RelayerMessage, error) {
+	return nil, fmt.Errorf("%w: relaying acknowledgements from sequences, use the event processor", ErrUnsupported)
+}
+
+func (p *Provider) MsgRegisterCounterpartyPayee(portID, channelID, relayerAddr, counterpartyPayee string) (provider.RelayerMessage, error) {
+	return nil, fmt.Errorf("%w: fee middleware", ErrUnsupported)
+}
@@ -9,6 +9,7 @@ import (
 
 	abci "github.com/cometbft/cometbft/abci/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
@@ -36,8 +37,15 @@ func IbcMessagesFromEvents(
 	chainID string,
 	height uint64,
 ) (messages []IbcMessage) {
+	// packets for which fees were escrowed with the ICS-29 fee middleware.
+	incentivized := make(map[string]bool)
+
 	for _, event := range events {
 		evt := sdk.StringifyEvent(event)
+		if evt.Type == feetypes.EventTypeIncentivizedPacket {
+			incentivized[incentivizedPacketKey(evt.Attributes)] = true
+			continue
+		}
 		m := ParseIBCMessageFromEvent(log, evt, chainID, height)
 		if m == nil || m.Info == nil {
 			// Not an IBC message, don't need to log here
@@ -45,9 +53,37 @@ func IbcMessagesFromEvents(
 		}
 		messages = append(messages, *m)
 	}
+
+	if len(incentivized) > 0 {
+		for _, m := range messages {
+			if m.EventType != chantypes.EventTypeSendPacket {
+				continue
+			}
+			pi := m.Info.(*PacketInfo)
+			key := pi.SourcePort + "/" + pi.SourceChannel + "/" + strconv.FormatUint(pi.Sequence, 10)
+			pi.Incentivized = incentivized[key]
+		}
+	}
+
 	return messages
 }
 
+// incentivizedPacketKey returns the port, channel and sequence of the packet of an incentivized_ibc_packet event.
+func incentivizedPacketKey(attrs []sdk.Attribute) string {
+	var port, channel, sequence string
+	for _, attr := range attrs {
+		switch attr.Key {
+		case chantypes.AttributeKeyPortID:
+			port = attr.Value
+		case chantypes.AttributeKeyChannelID:
+			channel = attr.Value
+		case chantypes.AttributeKeySequence:
+			sequence = attr.Value
+		}
+	}
+	return port + "/" + channel + "/" + sequence
+}
+
 type messageInfo interface {
 	ibcMessageInfo
 	ParseAttrs(log *zap.Logger, attrs []sdk.Attribute)
@@ -66,7 +102,12 @@ func ParseIBCMessageFromEvent(
 		msgInfo = &PacketInfo{Height: height}
 	case chantypes.EventTypeChannelOpenInit, chantypes.EventTypeChannelOpenTry,
 		chantypes.EventTypeChannelOpenAck, chantypes.EventTypeChannelOpenConfirm,
-		chantypes.EventTypeChannelCloseInit, chantypes.EventTypeChannelClosed, chantypes.EventTypeChannelCloseConfirm:
+		chantypes.EventTypeChannelCloseInit, chantypes.EventTypeChannelClosed, chantypes.EventTypeChannelCloseConfirm,
+		chantypes.EventTypeChannelUpgradeInit, chantypes.EventTypeChannelUpgradeTry,
+		chantypes.EventTypeChannelUpgradeAck, chantypes.EventTypeChannelUpgradeConfirm,
+		chantypes.EventTypeChannelUpgradeOpen, chantypes.EventTypeChannelUpgradeTimeout,
+		chantypes.EventTypeChannelUpgradeCancel, chantypes.EventTypeChannelUpgradeError,
+		chantypes.EventTypeChannelFlushComplete:
 		msgInfo = &ChannelInfo{Height: height}
 	case conntypes.EventTypeConnectionOpenInit, conntypes.EventTypeConnectionOpenTry,
 		conntypes.EventTypeConnectionOpenAck, conntypes.EventTypeConnectionOpenConfirm:
@@ -319,7 +360,7 @@ func (res *ChannelInfo) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 
 func (res *ChannelInfo) ParseAttrs(log *zap.Logger, attrs []sdk.Attribute) {
 	for _, attr := range attrs {
-		res.parseChannelAttribute(attr)
+		res.parseChannelAttribute(log, attr)
 	}
 }
 
@@ -327,7 +368,7 @@ func (res *ChannelInfo) ParseAttrs(log *zap.Logger, attrs []sdk.Attribute) {
 // If the attribute has already been parsed into the channelInfo,
 // it will not overwrite, and return true to inform the caller that
 // the attribute already exists.
-func (res *ChannelInfo) parseChannelAttribute(attr sdk.Attribute) {
+func (res *ChannelInfo) parseChannelAttribute(log *zap.Logger, attr sdk.Attribute) {
 	switch attr.Key {
 	case chantypes.AttributeKeyPortID:
 		res.PortID = attr.Value
@@ -341,6 +382,23 @@ func (res *ChannelInfo) parseChannelAttribute(attr sdk.Attribute) {
 		res.ConnID = attr.Value
 	case chantypes.AttributeVersion:
 		res.Version = attr.Value
+	case chantypes.AttributeKeyConnectionHops:
+		// only emitted by channel upgrade events, which do not include connection_id.
+		res.ConnID = attr.Value
+	case chantypes.AttributeKeyOrdering:
+		res.Order = chantypes.Order(chantypes.Order_value[attr.Value])
+	case chantypes.AttributeKeyChannelState:
+		res.State = chantypes.State(chantypes.State_value[attr.Value])
+	case chantypes.AttributeKeyUpgradeSequence:
+		var err error
+		res.UpgradeSequence, err = strconv.ParseUint(attr.Value, 10, 64)
+		if err != nil {
+			log.Error("Error parsing channel upgrade sequence",
+				zap.String("value", attr.Value),
+				zap.Error(err),
+			)
+			return
+		}
 	}
 }
 
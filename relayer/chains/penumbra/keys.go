@@ -4,7 +4,6 @@ import (
 	"errors"
 	"os"
 
-	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -182,10 +181,20 @@ func (cc *PenumbraProvider) KeyExists(name string) bool {
 
 }
 
-// ExportPrivKeyArmor returns a private key in ASCII armored format.
-// It returns an error if the key does not exist or a wrong encryption passphrase is supplied.
-func (cc *PenumbraProvider) ExportPrivKeyArmor(keyName string) (armor string, err error) {
-	return cc.Keybase.ExportPrivKeyArmor(keyName, ckeys.DefaultKeyPass)
+// ExportPrivKeyArmor returns a private key in ASCII armored format, encrypted with the passphrase.
+// It returns an error if the key does not exist.
+func (cc *PenumbraProvider) ExportPrivKeyArmor(keyName, passphrase string) (armor string, err error) {
+	return cc.Keybase.ExportPrivKeyArmor(keyName, passphrase)
+}
+
+// ImportPrivKeyArmor imports a private key in ASCII armored format, encrypted with the passphrase, e.g. exported
+// from another keyring, into the keystore under the specified name and returns its address.
+// It returns an error if a key with the name already exists or a wrong passphrase is supplied.
+func (cc *PenumbraProvider) ImportPrivKeyArmor(name, armor, passphrase string) (address string, err error) {
+	if err := cc.Keybase.ImportPrivKey(name, armor, passphrase); err != nil {
+		return "", err
+	}
+	return cc.ShowAddress(name)
 }
 
 // GetKeyAddress returns the account address representation for the currently configured key.
@@ -48,6 +48,9 @@ type PenumbraChainProcessor struct {
 
 	// map of channel ID to connection ID
 	channelConnections map[string]string
+
+	// if set, the height of the first block to query, rather than initialBlockHistory blocks before the latest height.
+	startHeight uint64
 }
 
 func NewPenumbraChainProcessor(log *zap.Logger, provider *PenumbraProvider) *PenumbraChainProcessor {
@@ -100,6 +103,12 @@ func (pcp *PenumbraChainProcessor) Provider() provider.ChainProvider {
 	return pcp.chainProvider
 }
 
+// SetStartHeight sets the height of the first block to query when the ChainProcessor is run,
+// overriding the initial block history.
+func (pcp *PenumbraChainProcessor) SetStartHeight(height uint64) {
+	pcp.startHeight = height
+}
+
 // Set the PathProcessors that this ChainProcessor should publish relevant IBC events to.
 // ChainProcessors need reference to their PathProcessors and vice-versa, handled by EventProcessorBuilder.Build().
 func (pcp *PenumbraChainProcessor) SetPathProcessors(pathProcessors processor.PathProcessors) {
@@ -189,6 +198,18 @@ func (pcp *PenumbraChainProcessor) Run(ctx context.Context, initialBlockHistory
 		latestQueriedBlock = 0
 	}
 
+	if pcp.startHeight != 0 {
+		if int64(pcp.startHeight) > persistence.latestHeight {
+			pcp.log.Warn(
+				"Start height is above the latest height of the chain, waiting for the chain to reach it",
+				zap.Uint64("start_height", pcp.startHeight),
+				zap.Int64("latest_height", persistence.latestHeight),
+			)
+		}
+		pcp.log.Info("Replaying events from start height", zap.Uint64("start_height", pcp.startHeight))
+		latestQueriedBlock = int64(pcp.startHeight) - 1
+	}
+
 	persistence.latestQueriedBlock = latestQueriedBlock
 
 	var eg errgroup.Group
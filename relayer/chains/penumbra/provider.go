@@ -60,6 +60,8 @@ type PenumbraProviderConfig struct {
 	Modules          []module.AppModuleBasic    `json:"-" yaml:"-"`
 	Slip44           int                        `json:"coin-type" yaml:"coin-type"`
 	Broadcast        provider.BroadcastMode     `json:"broadcast-mode" yaml:"broadcast-mode"`
+	ClientUpdate     provider.ClientUpdateMode  `json:"client-update-mode" yaml:"client-update-mode"`
+	MaxBatch         uint64                     `json:"max-batch-msgs" yaml:"max-batch-msgs"`
 	MinLoopDuration  time.Duration              `json:"min-loop-duration" yaml:"min-loop-duration"`
 	ExtensionOptions []provider.ExtensionOption `json:"extension-options" yaml:"extension-options"`
 }
@@ -68,6 +70,12 @@ func (pc PenumbraProviderConfig) Validate() error {
 	if _, err := time.ParseDuration(pc.Timeout); err != nil {
 		return fmt.Errorf("invalid Timeout: %w", err)
 	}
+	switch pc.ClientUpdate {
+	case "", provider.ClientUpdateModeBundled, provider.ClientUpdateModeSeparate:
+	default:
+		return fmt.Errorf("invalid client-update-mode: %s, supports one of: [%s, %s]",
+			pc.ClientUpdate, provider.ClientUpdateModeBundled, provider.ClientUpdateModeSeparate)
+	}
 	return nil
 }
 
@@ -75,6 +83,23 @@ func (pc PenumbraProviderConfig) BroadcastMode() provider.BroadcastMode {
 	return pc.Broadcast
 }
 
+func (pc PenumbraProviderConfig) ClientUpdateMode() provider.ClientUpdateMode {
+	return pc.ClientUpdate
+}
+
+func (pc PenumbraProviderConfig) MaxBatchMsgs() uint64 {
+	return pc.MaxBatch
+}
+
+// TxInclusionTimeout returns the block-timeout, which is only supported as a duration on penumbra.
+func (pc PenumbraProviderConfig) TxInclusionTimeout() provider.BlockTimeout {
+	d, err := time.ParseDuration(pc.BlockTimeout)
+	if err != nil {
+		return provider.BlockTimeout{}
+	}
+	return provider.BlockTimeout{Duration: d}
+}
+
 // NewProvider validates the PenumbraProviderConfig, instantiates a ChainClient and then instantiates a CosmosProvider
 func (pc PenumbraProviderConfig) NewProvider(log *zap.Logger, homepath string, debug bool, chainName string) (provider.ChainProvider, error) {
 	if err := pc.Validate(); err != nil {
@@ -90,6 +115,10 @@ func (pc PenumbraProviderConfig) NewProvider(log *zap.Logger, homepath string, d
 		pc.Broadcast = provider.BroadcastModeBatch
 	}
 
+	if pc.ClientUpdate == "" {
+		pc.ClientUpdate = provider.ClientUpdateModeBundled
+	}
+
 	httpClient, err := jsonrpcclient.DefaultHTTPClient(pc.RPCAddr)
 	if err != nil {
 		return nil, err
@@ -234,6 +263,12 @@ func (cc *PenumbraProvider) SetRpcAddr(rpcAddr string) error {
 	return nil
 }
 
+// SetChainId sets the chain-id of the chain.
+func (cc *PenumbraProvider) SetChainId(chainID string) error {
+	cc.PCfg.ChainID = chainID
+	return nil
+}
+
 // Init initializes the keystore, RPC client, amd light client provider.
 // Once initialization is complete an attempt to query the underlying node's tendermint version is performed.
 // NOTE: Init must be called after creating a new instance of CosmosProvider.
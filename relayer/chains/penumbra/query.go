@@ -855,7 +855,14 @@ func (cc *PenumbraProvider) QueryDenomTraces(ctx context.Context, offset, limit
 }
 
 func (cc *PenumbraProvider) QueryDenomHash(ctx context.Context, trace string) (string, error) {
-	panic("not implemented")
+	resp, err := transfertypes.NewQueryClient(cc).DenomHash(ctx,
+		&transfertypes.QueryDenomHashRequest{
+			Trace: trace,
+		})
+	if err != nil {
+		return "", err
+	}
+	return resp.Hash, nil
 }
 
 func (cc *PenumbraProvider) QueryStakingParams(ctx context.Context) (*stakingtypes.Params, error) {
@@ -1012,6 +1019,5 @@ func (cc *PenumbraProvider) QueryStatus(ctx context.Context) (*coretypes.ResultS
 }
 
 func (cc *PenumbraProvider) QueryICQWithProof(ctx context.Context, msgType string, request []byte, height uint64) (provider.ICQProof, error) {
-	//TODO implement me
-	panic("implement me")
+	return provider.ICQProof{}, fmt.Errorf("interchain queries are not supported on penumbra")
 }
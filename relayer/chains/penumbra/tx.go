@@ -302,6 +302,16 @@ func parseEventsFromABCIResponse(resp abci.ExecTxResult) []provider.RelayerEvent
 }
 
 func (cc *PenumbraProvider) sendMessagesInner(ctx context.Context, msgs []provider.RelayerMessage, _memo string) (*coretypes.ResultBroadcastTx, error) {
+	txBytes, err := cc.buildTx(ctx, msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.RPCClient.BroadcastTxSync(ctx, txBytes)
+}
+
+// buildTx encodes a penumbra transaction with an action for each of msgs.
+func (cc *PenumbraProvider) buildTx(ctx context.Context, msgs []provider.RelayerMessage) ([]byte, error) {
 
 	// TODO: fee estimation, fee payments
 	// NOTE: we do not actually need to sign this tx currently, since there
@@ -340,12 +350,7 @@ func (cc *PenumbraProvider) sendMessagesInner(ctx context.Context, msgs []provid
 	}
 
 	cc.log.Debug("Broadcasting penumbra tx")
-	txBytes, err := cosmosproto.Marshal(tx)
-	if err != nil {
-		return nil, err
-	}
-
-	return cc.RPCClient.BroadcastTxSync(ctx, txBytes)
+	return cosmosproto.Marshal(tx)
 }
 
 // SendMessages attempts to sign, encode, & send a slice of RelayerMessages
@@ -998,6 +1003,7 @@ func (cc *PenumbraProvider) MsgRelayAcknowledgement(ctx context.Context, dst pro
 func (cc *PenumbraProvider) MsgTransfer(
 	dstAddr string,
 	amount sdk.Coin,
+	memo string,
 	info provider.PacketInfo,
 ) (provider.RelayerMessage, error) {
 	acc, err := cc.Address()
@@ -1011,6 +1017,7 @@ func (cc *PenumbraProvider) MsgTransfer(
 		Sender:           acc,
 		Receiver:         dstAddr,
 		TimeoutTimestamp: info.TimeoutTimestamp,
+		Memo:             memo,
 	}
 
 	// If the timeoutHeight is 0 then we don't need to explicitly set it on the MsgTransfer
@@ -1201,17 +1208,7 @@ func (cc *PenumbraProvider) ValidatePacket(msgTransfer provider.PacketInfo, late
 		return errors.New("refusing to relay packet without a timeout (height or timestamp must be set)")
 	}
 
-	revision := clienttypes.ParseChainID(cc.PCfg.ChainID)
-	latestClientTypesHeight := clienttypes.NewHeight(revision, latest.Height)
-	if !msgTransfer.TimeoutHeight.IsZero() && latestClientTypesHeight.GTE(msgTransfer.TimeoutHeight) {
-		return provider.NewTimeoutHeightError(latest.Height, msgTransfer.TimeoutHeight.RevisionHeight)
-	}
-	latestTimestamp := uint64(latest.Time.UnixNano())
-	if msgTransfer.TimeoutTimestamp > 0 && latestTimestamp > msgTransfer.TimeoutTimestamp {
-		return provider.NewTimeoutTimestampError(latestTimestamp, msgTransfer.TimeoutTimestamp)
-	}
-
-	return nil
+	return msgTransfer.TimeoutElapsed(clienttypes.ParseChainID(cc.PCfg.ChainID), latest)
 }
 
 func (cc *PenumbraProvider) PacketCommitment(ctx context.Context, msgTransfer provider.PacketInfo, height uint64) (provider.PacketProof, error) {
@@ -1499,7 +1496,7 @@ func (cc *PenumbraProvider) MsgChannelOpenInit(info provider.ChannelInfo, proof
 				PortId:    info.CounterpartyPortID,
 				ChannelId: "",
 			},
-			ConnectionHops: []string{info.ConnID},
+			ConnectionHops: info.Hops(),
 			Version:        info.Version,
 		},
 		Signer: signer,
@@ -1538,7 +1535,7 @@ func (cc *PenumbraProvider) MsgChannelOpenTry(msgOpenInit provider.ChannelInfo,
 				PortId:    msgOpenInit.PortID,
 				ChannelId: msgOpenInit.ChannelID,
 			},
-			ConnectionHops: []string{msgOpenInit.CounterpartyConnID},
+			ConnectionHops: msgOpenInit.CounterpartyHops(),
 			// In the future, may need to separate this from the CounterpartyVersion.
 			// https://github.com/cosmos/ibc/tree/master/spec/core/ics-004-channel-and-packet-semantics#definitions
 			// Using same version as counterparty for now.
@@ -1627,6 +1624,42 @@ func (cc *PenumbraProvider) MsgChannelCloseConfirm(msgCloseInit provider.Channel
 	}), nil
 }
 
+// errChannelUpgradeNotSupported is returned by the channel upgrade handshake methods,
+// since penumbra does not support ICS-004 channel upgrades.
+var errChannelUpgradeNotSupported = errors.New("channel upgrades are not supported by penumbra")
+
+func (cc *PenumbraProvider) ChannelUpgradeProof(ctx context.Context, msg provider.ChannelInfo, height uint64) (provider.ChannelUpgradeProof, error) {
+	return provider.ChannelUpgradeProof{}, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) ChannelUpgradeErrorProof(ctx context.Context, msgUpgradeError provider.ChannelInfo, height uint64) (provider.ChannelUpgradeProof, error) {
+	return provider.ChannelUpgradeProof{}, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) MsgChannelUpgradeTry(msgUpgradeInit provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) MsgChannelUpgradeAck(msgUpgradeTry provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) MsgChannelUpgradeConfirm(msgUpgradeAck provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) MsgChannelUpgradeOpen(info provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) MsgChannelUpgradeTimeout(info provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
+func (cc *PenumbraProvider) MsgChannelUpgradeCancel(msgUpgradeError provider.ChannelInfo, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	return nil, errChannelUpgradeNotSupported
+}
+
 func (cc *PenumbraProvider) MsgUpdateClientHeader(
 	latestHeader provider.IBCHeader,
 	trustedHeight clienttypes.Height,
@@ -2280,18 +2313,30 @@ func (cc *PenumbraProvider) mkTxResult(resTx *coretypes.ResultTx) (*sdk.TxRespon
 }
 
 func (cc *PenumbraProvider) MsgSubmitQueryResponse(chainID string, queryID provider.ClientICQQueryID, proof provider.ICQProof) (provider.RelayerMessage, error) {
-	//TODO implement me
-	panic("implement me")
+	return nil, fmt.Errorf("interchain query responses are not supported on penumbra")
 }
 
-func (cc *PenumbraProvider) SendMessagesToMempool(ctx context.Context, msgs []provider.RelayerMessage, memo string, asyncCtx context.Context, asyncCallback []func(*provider.RelayerTxResponse, error)) error {
-	sendRsp, err := cc.sendMessagesInner(ctx, msgs, memo)
-	cc.log.Debug("Received response from sending messages", zap.Any("response", sendRsp), zap.Error(err))
-	return err
+// SendMessagesToMempool broadcasts msgs in a transaction and, in an async goroutine, waits for it to be included
+// in a block before invoking asyncCallbacks with its result.
+func (cc *PenumbraProvider) SendMessagesToMempool(ctx context.Context, msgs []provider.RelayerMessage, memo string, asyncCtx context.Context, asyncCallbacks []func(*provider.RelayerTxResponse, error)) error {
+	txBytes, err := cc.buildTx(ctx, msgs)
+	if err != nil {
+		return err
+	}
+
+	waitTimeout := defaultBroadcastWaitTimeout
+	if d := cc.PCfg.TxInclusionTimeout().Duration; d > 0 {
+		waitTimeout = d
+	}
+
+	return cc.broadcastTx(ctx, txBytes, msgs, nil, asyncCtx, waitTimeout, func(rtr *provider.RelayerTxResponse, err error) {
+		for _, cb := range asyncCallbacks {
+			cb(rtr, err)
+		}
+	})
 }
 
 // MsgRegisterCounterpartyPayee creates an sdk.Msg to broadcast the counterparty address
 func (cc *PenumbraProvider) MsgRegisterCounterpartyPayee(portID, channelID, relayerAddr, counterpartyPayee string) (provider.RelayerMessage, error) {
-	//TODO implement me
-	panic("implement me")
+	return nil, fmt.Errorf("registering counterparty payees is not supported on penumbra")
 }
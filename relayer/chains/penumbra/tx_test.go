@@ -0,0 +1,185 @@
+package penumbra
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cwrapper "github.com/cosmos/relayer/v2/client"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// mockCometClient is a CometBFT RPC client of a penumbra node, which includes the txs broadcast to it
+// in a block with deliverCode, unless they fail CheckTx with checkCode or are never included.
+type mockCometClient struct {
+	rpcclient.Client
+
+	checkCode     uint32
+	deliverCode   uint32
+	neverIncluded bool
+
+	// tx is an SDK tx, which the results of txs are decoded as.
+	tx []byte
+
+	mu        sync.Mutex
+	broadcast [][]byte
+}
+
+func (c *mockCometClient) Status(context.Context) (*coretypes.ResultStatus, error) {
+	return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{LatestBlockHeight: 10}}, nil
+}
+
+func (c *mockCometClient) ABCIQueryWithOptions(
+	context.Context, string, cmtbytes.HexBytes, rpcclient.ABCIQueryOptions,
+) (*coretypes.ResultABCIQuery, error) {
+	return &coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: make([]byte, 34)}}, nil
+}
+
+func (c *mockCometClient) BroadcastTxSync(_ context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	c.mu.Lock()
+	c.broadcast = append(c.broadcast, tx)
+	c.mu.Unlock()
+	return &coretypes.ResultBroadcastTx{Code: c.checkCode, Hash: tx.Hash()}, nil
+}
+
+func (c *mockCometClient) Tx(_ context.Context, hash []byte, _ bool) (*coretypes.ResultTx, error) {
+	if c.neverIncluded {
+		return nil, errors.New("tx not found")
+	}
+	return &coretypes.ResultTx{
+		Hash:     hash,
+		Height:   11,
+		TxResult: abci.ExecTxResult{Code: c.deliverCode},
+		Tx:       c.tx,
+	}, nil
+}
+
+func testProvider(t *testing.T, rpc *mockCometClient) *PenumbraProvider {
+	pcfg := PenumbraProviderConfig{
+		ChainID:      "penumbra-testnet",
+		RPCAddr:      "http://localhost:26657",
+		Timeout:      "10s",
+		BlockTimeout: "500ms",
+	}
+	p, err := pcfg.NewProvider(zaptest.NewLogger(t), t.TempDir(), false, "penumbra")
+	require.NoError(t, err)
+	cc := p.(*PenumbraProvider)
+
+	txb := cc.Codec.TxConfig.NewTxBuilder()
+	txb.SetFeePayer(sdk.AccAddress(make([]byte, 20)))
+	tx, err := cc.Codec.TxConfig.TxEncoder()(txb.GetTx())
+	require.NoError(t, err)
+	rpc.tx = tx
+	cc.RPCClient = cwrapper.NewCometRPCClient(rpc)
+	return cc
+}
+
+func TestSendMessagesToMempool(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		rpc           *mockCometClient
+		expectedErr   error
+		callbackErr   error
+		expectResults bool
+	}{
+		{
+			name:          "included",
+			rpc:           &mockCometClient{},
+			expectResults: true,
+		},
+		{
+			name:          "failed to execute",
+			rpc:           &mockCometClient{deliverCode: 1},
+			expectResults: true,
+			callbackErr:   errors.New("transaction failed to execute"),
+		},
+		{
+			name:          "never included",
+			rpc:           &mockCometClient{neverIncluded: true},
+			expectResults: true,
+			callbackErr:   ErrTimeoutAfterWaitingForTxBroadcast,
+		},
+		{
+			name:        "failed check tx",
+			rpc:         &mockCometClient{checkCode: 1},
+			expectedErr: errors.New("transaction failed to execute"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cc := testProvider(t, tc.rpc)
+
+			// every callback of the messages sent in the tx is invoked with its result.
+			type result struct {
+				rtr *provider.RelayerTxResponse
+				err error
+			}
+			results := make(chan result, 2)
+			callback := func(rtr *provider.RelayerTxResponse, err error) {
+				results <- result{rtr, err}
+			}
+
+			err := cc.SendMessagesToMempool(
+				context.Background(), nil, "", context.Background(),
+				[]func(*provider.RelayerTxResponse, error){callback, callback},
+			)
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, tc.rpc.broadcast, 1)
+
+			if !tc.expectResults {
+				select {
+				case r := <-results:
+					t.Fatalf("callback invoked for a tx which was not broadcast: %v", r)
+				case <-time.After(200 * time.Millisecond):
+				}
+				return
+			}
+
+			for i := 0; i < 2; i++ {
+				select {
+				case r := <-results:
+					switch {
+					case errors.Is(tc.callbackErr, ErrTimeoutAfterWaitingForTxBroadcast):
+						require.ErrorIs(t, r.err, ErrTimeoutAfterWaitingForTxBroadcast)
+						require.Nil(t, r.rtr)
+					case tc.callbackErr != nil:
+						require.EqualError(t, r.err, tc.callbackErr.Error())
+						require.Nil(t, r.rtr)
+					default:
+						require.NoError(t, r.err)
+						require.Equal(t, int64(11), r.rtr.Height)
+						require.Equal(t, uint32(0), r.rtr.Code)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("callback not invoked with the result of the tx")
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedFeaturesReturnErrors(t *testing.T) {
+	cc := testProvider(t, &mockCometClient{})
+
+	_, err := cc.QueryICQWithProof(context.Background(), "", nil, 0)
+	require.EqualError(t, err, "interchain queries are not supported on penumbra")
+
+	_, err = cc.MsgSubmitQueryResponse("penumbra-testnet", "", provider.ICQProof{})
+	require.EqualError(t, err, "interchain query responses are not supported on penumbra")
+
+	_, err = cc.MsgRegisterCounterpartyPayee("transfer", "channel-0", "relayer", "payee")
+	require.EqualError(t, err, "registering counterparty payees is not supported on penumbra")
+}
@@ -3,22 +3,26 @@ package relayer
 import (
 	"context"
 	"fmt"
-	"time"
 
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 )
 
 // CreateOpenChannels runs the channel creation messages on timeout until they pass.
+// Unless override is set, the interrupted handshake of the channel ends srcChannelID and dstChannelID between the
+// ports, if either is set, is resumed, since incomplete handshakes between the same ports may belong to another
+// path or relayer. Otherwise a new handshake is started, unless a channel between the ports is already open.
+// If the handshake fails, the error names the channel ends created so far, so that it can be resumed.
 func (c *Chain) CreateOpenChannels(
 	ctx context.Context,
 	dst *Chain,
-	maxRetries uint64,
-	timeout time.Duration,
+	retryPolicy *RetryPolicy,
 	srcPortID, dstPortID, order, version string,
+	srcChannelID, dstChannelID string,
 	override bool,
 	memo string,
 	pathName string,
@@ -33,36 +37,150 @@ func (c *Chain) CreateOpenChannels(
 		return err
 	}
 
+	initial := &processor.ChannelMessage{
+		ChainID:   c.PathEnd.ChainID,
+		EventType: chantypes.EventTypeChannelOpenInit,
+		Info: provider.ChannelInfo{
+			PortID:             srcPortID,
+			CounterpartyPortID: dstPortID,
+			ConnID:             c.PathEnd.ConnectionID,
+			Version:            version,
+			Order:              OrderFromString(order),
+		},
+	}
+	// the chain on which the handshake completes with MsgChannelOpenConfirm.
+	confirm := dst
+	confirmPortID, confirmCounterpartyPortID := dstPortID, srcPortID
+
+	// the channel ends of a resumed handshake are reserved from the start.
+	reserved := newHandshakeReservation()
+
 	if !override {
-		channel, err := QueryPortChannel(ctx, c, srcPortID)
-		if err == nil && channel != nil {
-			return fmt.Errorf("channel {%s} with port {%s} already exists on chain {%s}", channel.ChannelId, channel.PortId, c.ChainID())
+		srcChannels, err := queryHandshakeChannels(ctx, c, srcPortID, dstPortID)
+		if err != nil {
+			return err
+		}
+		dstChannels, err := queryHandshakeChannels(ctx, dst, dstPortID, srcPortID)
+		if err != nil {
+			return err
 		}
 
-		channel, err = QueryPortChannel(ctx, dst, dstPortID)
-		if err == nil && channel != nil {
-			return fmt.Errorf("channel {%s} with port {%s} already exists on chain {%s}", channel.ChannelId, channel.PortId, dst.ChainID())
+		step, end, onSrc := pathHandshakeStep(
+			channelHandshakeEnds(srcChannels),
+			channelHandshakeEnds(dstChannels),
+			srcChannelID,
+			dstChannelID,
+		)
+
+		switch step {
+		case handshakeStepOpen:
+			return fmt.Errorf("channel {%s} with port {%s} already exists on chain {%s}", end.ID, srcPortID, c.ChainID())
+		case handshakeStepInit:
+			channel, err := QueryPortChannel(ctx, c, srcPortID)
+			if err == nil && channel != nil {
+				return fmt.Errorf("channel {%s} with port {%s} already exists on chain {%s}", channel.ChannelId, channel.PortId, c.ChainID())
+			}
+
+			channel, err = QueryPortChannel(ctx, dst, dstPortID)
+			if err == nil && channel != nil {
+				return fmt.Errorf("channel {%s} with port {%s} already exists on chain {%s}", channel.ChannelId, channel.PortId, dst.ChainID())
+			}
+		default:
+			// the chain of the end which the next step follows from, and its counterparty.
+			from, to := c, dst
+			fromPortID, toPortID := srcPortID, dstPortID
+			fromChannels := srcChannels
+			if !onSrc {
+				from, to = dst, c
+				fromPortID, toPortID = dstPortID, srcPortID
+				fromChannels = dstChannels
+			}
+
+			var channel *chantypes.IdentifiedChannel
+			for _, ch := range fromChannels {
+				if ch.ChannelId == end.ID {
+					channel = ch
+					break
+				}
+			}
+
+			reserved.reserve(from.PathEnd.ChainID, end.ID)
+			if end.CounterpartyID != "" {
+				reserved.reserve(to.PathEnd.ChainID, end.CounterpartyID)
+			}
+
+			c.log.Info("Resuming channel handshake",
+				zap.String("step", step.String()),
+				zap.String("chain_id", from.PathEnd.ChainID),
+				zap.String("channel_id", end.ID),
+				zap.String("port_id", fromPortID),
+				zap.String("counterparty_chain_id", to.PathEnd.ChainID),
+				zap.String("counterparty_channel_id", end.CounterpartyID),
+				zap.String("counterparty_port_id", toPortID),
+			)
+
+			// the next step is relayed as if the event for the previous step had just been observed for the end.
+			initial = &processor.ChannelMessage{
+				ChainID: from.PathEnd.ChainID,
+				Info: provider.ChannelInfo{
+					PortID:                fromPortID,
+					ChannelID:             end.ID,
+					CounterpartyPortID:    toPortID,
+					CounterpartyChannelID: end.CounterpartyID,
+					ConnID:                from.PathEnd.ConnectionID,
+					CounterpartyConnID:    to.PathEnd.ConnectionID,
+					Version:               channel.Version,
+					Order:                 channel.Ordering,
+				},
+			}
+
+			switch step {
+			case handshakeStepTry:
+				initial.EventType = chantypes.EventTypeChannelOpenTry
+				confirm = to
+				confirmPortID, confirmCounterpartyPortID = toPortID, fromPortID
+			case handshakeStepAck:
+				initial.EventType = chantypes.EventTypeChannelOpenAck
+				confirm = from
+				confirmPortID, confirmCounterpartyPortID = fromPortID, toPortID
+			case handshakeStepConfirm:
+				initial.EventType = chantypes.EventTypeChannelOpenConfirm
+				confirm = to
+				confirmPortID, confirmCounterpartyPortID = toPortID, fromPortID
+			}
 		}
 	}
 
-	// Timeout is per message. Four channel handshake messages, allowing maxRetries for each.
-	processorTimeout := timeout * 4 * time.Duration(maxRetries)
+	if initial.EventType == chantypes.EventTypeChannelOpenInit {
+		if err := checkPortsBound(ctx, c, dst, srcPortID, dstPortID); err != nil {
+			return err
+		}
+	}
+
+	// Timeout is per message. Four channel handshake messages, allowing the handshake retries for each.
+	processorTimeout := retryPolicy.handshakeTimeout(4)
 
 	ctx, cancel := context.WithTimeout(ctx, processorTimeout)
 	defer cancel()
 
-	pp := processor.NewPathProcessor(
-		c.log,
-		processor.NewPathEnd(pathName, c.PathEnd.ChainID, c.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-		processor.NewPathEnd(pathName, dst.PathEnd.ChainID, dst.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-		nil,
-		memo,
-		DefaultClientUpdateThreshold,
-		DefaultFlushInterval,
-		DefaultMaxMsgLength,
-		0,
-		0,
-	)
+	pp := c.handshakePathProcessor(dst, pathName, memo, retryPolicy)
+
+	lifecycle := &processor.ChannelMessageLifecycle{
+		Initial: initial,
+		Termination: &processor.ChannelMessage{
+			ChainID:   confirm.PathEnd.ChainID,
+			EventType: chantypes.EventTypeChannelOpenConfirm,
+			Info: provider.ChannelInfo{
+				PortID:             confirmPortID,
+				CounterpartyPortID: confirmCounterpartyPortID,
+			},
+		},
+	}
+
+	// the ends of a channel from a chain to itself are not told apart by chain, so they are not reserved.
+	if c.PathEnd.ChainID != dst.PathEnd.ChainID {
+		lifecycle.TerminationFilter = c.reserveChannelEnds(ctx, pp, dst, srcPortID, dstPortID, confirm, reserved)
+	}
 
 	c.log.Info("Starting event processor for channel handshake",
 		zap.String("src_chain_id", c.PathEnd.ChainID),
@@ -71,51 +189,148 @@ func (c *Chain) CreateOpenChannels(
 		zap.String("dst_port_id", dstPortID),
 	)
 
-	return processor.NewEventProcessor().
+	err := processor.NewEventProcessor().
 		WithChainProcessors(
 			c.chainProcessor(c.log, nil),
 			dst.chainProcessor(c.log, nil),
 		).
 		WithPathProcessors(pp).
+		WithProofHeightAudit(proofHeightAudit(c, dst)).
 		WithInitialBlockHistory(0).
-		WithMessageLifecycle(&processor.ChannelMessageLifecycle{
-			Initial: &processor.ChannelMessage{
-				ChainID:   c.PathEnd.ChainID,
-				EventType: chantypes.EventTypeChannelOpenInit,
-				Info: provider.ChannelInfo{
-					PortID:             srcPortID,
-					CounterpartyPortID: dstPortID,
-					ConnID:             c.PathEnd.ConnectionID,
-					Version:            version,
-					Order:              OrderFromString(order),
-				},
-			},
-			Termination: &processor.ChannelMessage{
-				ChainID:   dst.PathEnd.ChainID,
-				EventType: chantypes.EventTypeChannelOpenConfirm,
-				Info: provider.ChannelInfo{
-					PortID:             dstPortID,
-					CounterpartyPortID: srcPortID,
-				},
-			},
-		}).
+		WithMessageLifecycle(lifecycle).
 		Build().
 		Run(ctx)
+	if err != nil {
+		if srcID, dstID := reserved.id(c.PathEnd.ChainID), reserved.id(dst.PathEnd.ChainID); srcID != "" || dstID != "" {
+			return fmt.Errorf("%w; resume the handshake of channel{%s} on chain{%s} and channel{%s} on chain{%s} "+
+				"with --src-channel and --dst-channel", err, srcID, c.ChainID(), dstID, dst.ChainID())
+		}
+		return err
+	}
+	return nil
+}
+
+// reserveChannelEnds reserves the channel ends of the handshake between c and dst relayed by pp as they are
+// created, and returns a termination filter which accepts only the channel of the handshake once it is open on
+// confirm. The counterparty of a new channel end is verified by query before it is reserved, since other relayers
+// may open channels between the same ports at the same time. The queries run until ctx is done.
+func (c *Chain) reserveChannelEnds(
+	ctx context.Context,
+	pp *processor.PathProcessor,
+	dst *Chain,
+	srcPortID, dstPortID string,
+	confirm *Chain,
+	reserved *handshakeReservation,
+) func(provider.ChannelInfo) bool {
+	verifier := newHandshakeVerifier(ctx)
+	for _, ends := range []struct {
+		chain, counterparty        *Chain
+		portID, counterpartyPortID string
+	}{
+		{chain: c, counterparty: dst, portID: srcPortID, counterpartyPortID: dstPortID},
+		{chain: dst, counterparty: c, portID: dstPortID, counterpartyPortID: srcPortID},
+	} {
+		ends := ends
+		for _, eventType := range []string{chantypes.EventTypeChannelOpenInit, chantypes.EventTypeChannelOpenTry} {
+			eventType := eventType
+			pp.OnChannelMessage(ends.chain.PathEnd.ChainID, eventType, func(ci provider.ChannelInfo) {
+				if ci.PortID != ends.portID || ci.CounterpartyPortID != ends.counterpartyPortID {
+					return
+				}
+				verifier.enqueue(func(ctx context.Context) {
+					var counterpartyChannelID string
+					if eventType == chantypes.EventTypeChannelOpenTry {
+						counterpartyChannelID = reserved.id(ends.counterparty.PathEnd.ChainID)
+						if counterpartyChannelID == "" || ci.CounterpartyChannelID != counterpartyChannelID {
+							c.log.Info("Ignoring channel of another handshake",
+								zap.String("chain_id", ends.chain.PathEnd.ChainID),
+								zap.String("channel_id", ci.ChannelID),
+								zap.String("counterparty_channel_id", ci.CounterpartyChannelID),
+							)
+							return
+						}
+					}
+					err := verifyChannelEnd(ctx, ends.chain, ci.PortID, ci.ChannelID, ends.counterpartyPortID, counterpartyChannelID)
+					if err != nil {
+						c.log.Warn("Ignoring unverified channel", zap.Error(err))
+						return
+					}
+					if !reserved.reserve(ends.chain.PathEnd.ChainID, ci.ChannelID) {
+						c.log.Info("Ignoring channel of another handshake",
+							zap.String("chain_id", ends.chain.PathEnd.ChainID),
+							zap.String("channel_id", ci.ChannelID),
+							zap.String("reserved_channel_id", reserved.id(ends.chain.PathEnd.ChainID)),
+						)
+					}
+				})
+			})
+		}
+	}
+
+	// the handshake terminates once the reserved channel ends are open, not on a channel which another handshake
+	// opens between the same ports.
+	confirmCounterparty := c
+	if confirm == c {
+		confirmCounterparty = dst
+	}
+	return func(ci provider.ChannelInfo) bool {
+		if ci.ChannelID != reserved.id(confirm.PathEnd.ChainID) ||
+			ci.CounterpartyChannelID != reserved.id(confirmCounterparty.PathEnd.ChainID) {
+			c.log.Info("Ignoring channel opened by another handshake",
+				zap.String("chain_id", confirm.PathEnd.ChainID),
+				zap.String("channel_id", ci.ChannelID),
+				zap.String("reserved_channel_id", reserved.id(confirm.PathEnd.ChainID)),
+			)
+			return false
+		}
+		return true
+	}
+}
+
+// checkPortsBound returns an error if the port of either end of a new channel is not bound on its chain, so that
+// the handshake fails before MsgChannelOpenInit rather than at MsgChannelOpenTry on the counterparty.
+// Ports are only checked on chains which support it, and are presumed bound if the check fails.
+func checkPortsBound(ctx context.Context, src, dst *Chain, srcPortID, dstPortID string) error {
+	for _, end := range []struct {
+		chain                      *Chain
+		portID, counterpartyPortID string
+	}{
+		{chain: src, portID: srcPortID, counterpartyPortID: dstPortID},
+		{chain: dst, portID: dstPortID, counterpartyPortID: srcPortID},
+	} {
+		cc, ok := end.chain.ChainProvider.(*cosmos.CosmosProvider)
+		if !ok {
+			continue
+		}
+		bound, err := cc.QueryPortBound(ctx, end.portID, end.chain.ConnectionID(), end.counterpartyPortID)
+		if err != nil {
+			end.chain.log.Warn("Failed to check whether port is bound, presuming it is",
+				zap.String("chain_id", end.chain.ChainID()),
+				zap.String("port_id", end.portID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !bound {
+			return fmt.Errorf("port {%s} not bound on chain {%s}, the chain may not run the application of the port",
+				end.portID, end.chain.ChainID())
+		}
+	}
+	return nil
 }
 
 // CloseChannel runs the channel closing messages on timeout until they pass.
 func (c *Chain) CloseChannel(
 	ctx context.Context,
 	dst *Chain,
-	maxRetries uint64,
-	timeout time.Duration,
+	retryPolicy *RetryPolicy,
 	srcChanID,
 	srcPortID string,
 	memo string,
 	pathName string,
 ) error {
-	// Timeout is per message. Two close channel handshake messages, allowing maxRetries for each.
-	processorTimeout := timeout * 2 * time.Duration(maxRetries)
+	// Timeout is per message. Two close channel handshake messages, allowing the handshake retries for each.
+	processorTimeout := retryPolicy.handshakeTimeout(2)
 
 	// Perform a flush first so that any timeouts are cleared.
 	flushCtx, flushCancel := context.WithTimeout(ctx, processorTimeout)
@@ -126,18 +341,8 @@ func (c *Chain) CloseChannel(
 			c.chainProcessor(c.log, nil),
 			dst.chainProcessor(c.log, nil),
 		).
-		WithPathProcessors(processor.NewPathProcessor(
-			c.log,
-			processor.NewPathEnd(pathName, c.PathEnd.ChainID, c.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-			processor.NewPathEnd(pathName, dst.PathEnd.ChainID, dst.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-			nil,
-			memo,
-			DefaultClientUpdateThreshold,
-			DefaultFlushInterval,
-			DefaultMaxMsgLength,
-			0,
-			0,
-		)).
+		WithPathProcessors(c.handshakePathProcessor(dst, pathName, memo, retryPolicy)).
+		WithProofHeightAudit(proofHeightAudit(c, dst)).
 		WithInitialBlockHistory(0).
 		WithMessageLifecycle(&processor.FlushLifecycle{}).
 		Build()
@@ -166,18 +371,8 @@ func (c *Chain) CloseChannel(
 			c.chainProcessor(c.log, nil),
 			dst.chainProcessor(c.log, nil),
 		).
-		WithPathProcessors(processor.NewPathProcessor(
-			c.log,
-			processor.NewPathEnd(pathName, c.PathEnd.ChainID, c.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-			processor.NewPathEnd(pathName, dst.PathEnd.ChainID, dst.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-			nil,
-			memo,
-			DefaultClientUpdateThreshold,
-			DefaultFlushInterval,
-			DefaultMaxMsgLength,
-			0,
-			0,
-		)).
+		WithPathProcessors(c.handshakePathProcessor(dst, pathName, memo, retryPolicy)).
+		WithProofHeightAudit(proofHeightAudit(c, dst)).
 		WithInitialBlockHistory(0).
 		WithMessageLifecycle(&processor.ChannelCloseLifecycle{
 			SrcChainID:   c.PathEnd.ChainID,
@@ -190,6 +385,92 @@ func (c *Chain) CloseChannel(
 		Run(ctx)
 }
 
+// RelayChannelUpgrade relays the channel upgrade handshake for a channel upgrade which has been
+// initialized on c, e.g. through governance, until the upgraded channel is open on c.
+func (c *Chain) RelayChannelUpgrade(
+	ctx context.Context,
+	dst *Chain,
+	retryPolicy *RetryPolicy,
+	srcChanID,
+	srcPortID string,
+	memo string,
+	pathName string,
+) error {
+	srch, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return err
+	}
+
+	upgrade, err := c.ChainProvider.ChannelUpgradeProof(ctx, provider.ChannelInfo{
+		PortID:    srcPortID,
+		ChannelID: srcChanID,
+	}, uint64(srch))
+	if err != nil {
+		return fmt.Errorf("failed to query upgrade of channel{%s} on port{%s}: %w", srcChanID, srcPortID, err)
+	}
+	if len(upgrade.Upgrade.Fields.ConnectionHops) == 0 {
+		return fmt.Errorf("no upgrade has been initialized for channel{%s} on port{%s} on chain{%s}", srcChanID, srcPortID, c.ChainID())
+	}
+
+	// the upgrade try on dst proposes the counterparty of the connection proposed by c.
+	connectionID := upgrade.Upgrade.Fields.ConnectionHops[0]
+	connection, err := c.ChainProvider.QueryConnection(ctx, srch, connectionID)
+	if err != nil {
+		return fmt.Errorf("failed to query proposed connection{%s} on chain{%s}: %w", connectionID, c.ChainID(), err)
+	}
+
+	// Timeout is per message. Four channel upgrade handshake messages, allowing the handshake retries for each.
+	processorTimeout := retryPolicy.handshakeTimeout(4)
+
+	ctx, cancel := context.WithTimeout(ctx, processorTimeout)
+	defer cancel()
+
+	c.log.Info("Starting event processor for channel upgrade",
+		zap.String("src_chain_id", c.PathEnd.ChainID),
+		zap.String("src_channel_id", srcChanID),
+		zap.String("src_port_id", srcPortID),
+		zap.String("dst_chain_id", dst.PathEnd.ChainID),
+		zap.Uint64("upgrade_sequence", upgrade.Channel.UpgradeSequence),
+	)
+
+	return processor.NewEventProcessor().
+		WithChainProcessors(
+			c.chainProcessor(c.log, nil),
+			dst.chainProcessor(c.log, nil),
+		).
+		WithPathProcessors(c.handshakePathProcessor(dst, pathName, memo, retryPolicy)).
+		WithProofHeightAudit(proofHeightAudit(c, dst)).
+		WithInitialBlockHistory(0).
+		WithMessageLifecycle(&processor.ChannelMessageLifecycle{
+			Initial: &processor.ChannelMessage{
+				ChainID:   c.PathEnd.ChainID,
+				EventType: chantypes.EventTypeChannelUpgradeTry,
+				Info: provider.ChannelInfo{
+					Height:                uint64(srch),
+					PortID:                srcPortID,
+					ChannelID:             srcChanID,
+					CounterpartyPortID:    upgrade.Channel.Counterparty.PortId,
+					CounterpartyChannelID: upgrade.Channel.Counterparty.ChannelId,
+					ConnID:                connectionID,
+					CounterpartyConnID:    connection.Connection.Counterparty.ConnectionId,
+					Order:                 upgrade.Upgrade.Fields.Ordering,
+					Version:               upgrade.Upgrade.Fields.Version,
+					UpgradeSequence:       upgrade.Channel.UpgradeSequence,
+				},
+			},
+			Termination: &processor.ChannelMessage{
+				ChainID:   c.PathEnd.ChainID,
+				EventType: chantypes.EventTypeChannelUpgradeOpen,
+				Info: provider.ChannelInfo{
+					PortID:    srcPortID,
+					ChannelID: srcChanID,
+				},
+			},
+		}).
+		Build().
+		Run(ctx)
+}
+
 // ValidateChannelParams validates a set of port-ids as well as the order.
 func ValidateChannelParams(srcPortID, dstPortID, order string) error {
 	if err := host.PortIdentifierValidator(srcPortID); err != nil {
@@ -0,0 +1,198 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// govChannelPollInterval is how often a chain is queried for the channel opened by a governance proposal.
+const govChannelPollInterval = 30 * time.Second
+
+// GovChannelOpenOptions configure the governance proposal opening a channel on a chain on which only
+// governance may open channels.
+type GovChannelOpenOptions struct {
+	// ProposalFile is the file the proposal is written to. It is written to Out if empty.
+	ProposalFile string
+	Out          io.Writer
+
+	// Deposit is the initial deposit of the proposal. If set, the proposal is submitted with the key of the chain,
+	// otherwise it is left to the operator to submit it.
+	Deposit sdk.Coins
+
+	Title   string
+	Summary string
+	Memo    string
+}
+
+// OpenChannelByGovernance starts the handshake of a channel on c, on which only governance may open channels,
+// by generating a proposal executing MsgChannelOpenInit, and optionally submitting it. It then waits until the
+// channel exists on c and returns its identifier, so that the handshake of that channel can be continued on dst
+// with CreateOpenChannels. It returns an empty identifier if a channel between the ports is already open.
+func (c *Chain) OpenChannelByGovernance(
+	ctx context.Context,
+	dst *Chain,
+	srcPortID, dstPortID, order, version string,
+	opts GovChannelOpenOptions,
+) (string, error) {
+	if err := ValidateConnectionPaths(c, dst); err != nil {
+		return "", err
+	}
+	if err := ValidateChannelParams(srcPortID, dstPortID, order); err != nil {
+		return "", err
+	}
+
+	cc, ok := c.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return "", fmt.Errorf("opening channels by governance is not supported on chain {%s} of type %s", c.ChainID(), c.ChainProvider.Type())
+	}
+
+	srcChannels, err := queryHandshakeChannels(ctx, c, srcPortID, dstPortID)
+	if err != nil {
+		return "", err
+	}
+	dstChannels, err := queryHandshakeChannels(ctx, dst, dstPortID, srcPortID)
+	if err != nil {
+		return "", err
+	}
+	if step, _, _ := nextHandshakeStep(channelHandshakeEnds(srcChannels), channelHandshakeEnds(dstChannels)); step == handshakeStepOpen {
+		c.log.Info(
+			"Channel already open, skipping governance proposal",
+			zap.String("chain_id", c.ChainID()),
+			zap.String("port_id", srcPortID),
+		)
+		return "", nil
+	}
+
+	// channels between the ports which exist before the proposal belong to other handshakes.
+	existing := make(map[string]bool, len(srcChannels))
+	for _, channel := range srcChannels {
+		existing[channel.ChannelId] = true
+	}
+
+	msg, err := cc.MsgChannelOpenInitByAuthority(provider.ChannelInfo{
+		PortID:             srcPortID,
+		CounterpartyPortID: dstPortID,
+		ConnID:             c.PathEnd.ConnectionID,
+		Version:            version,
+		Order:              OrderFromString(order),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	title, summary := opts.Title, opts.Summary
+	if title == "" {
+		title = fmt.Sprintf("Open IBC channel %s to %s", srcPortID, dst.ChainID())
+	}
+	if summary == "" {
+		summary = fmt.Sprintf(
+			"Open an IBC channel on port %s and connection %s to port %s on chain %s.",
+			srcPortID, c.ConnectionID(), dstPortID, dst.ChainID(),
+		)
+	}
+
+	proposal, err := cc.NewGovProposal([]sdk.Msg{msg}, opts.Deposit, title, summary)
+	if err != nil {
+		return "", err
+	}
+	bz, err := json.MarshalIndent(proposal, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	bz = append(bz, '\n')
+	if opts.ProposalFile != "" {
+		if err := os.WriteFile(opts.ProposalFile, bz, 0o644); err != nil {
+			return "", fmt.Errorf("failed to write proposal file: %w", err)
+		}
+	} else if opts.Out != nil {
+		if _, err := opts.Out.Write(bz); err != nil {
+			return "", err
+		}
+	}
+
+	if opts.Deposit.Empty() {
+		c.log.Info(
+			"Generated channel open proposal, waiting for it to be submitted and passed",
+			zap.String("chain_id", c.ChainID()),
+			zap.String("proposal_file", opts.ProposalFile),
+		)
+	} else {
+		id, err := cc.SubmitGovProposal(ctx, []sdk.Msg{msg}, opts.Deposit, title, summary, opts.Memo)
+		if err != nil {
+			return "", fmt.Errorf("failed to submit channel open proposal on chain {%s}: %w", c.ChainID(), err)
+		}
+		c.log.Info(
+			"Submitted channel open proposal, waiting for it to pass",
+			zap.String("chain_id", c.ChainID()),
+			zap.Uint64("proposal_id", id),
+		)
+	}
+
+	return waitForGovChannel(ctx, c, srcPortID, dstPortID, existing, govChannelPollInterval)
+}
+
+// waitForGovChannel polls c until a channel between the ports of c and dst, other than the existing ones, is in
+// INIT, i.e. the governance proposal opening it has been executed, and returns its identifier.
+func waitForGovChannel(
+	ctx context.Context,
+	c *Chain,
+	srcPortID, dstPortID string,
+	existing map[string]bool,
+	interval time.Duration,
+) (string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+
+		channels, err := queryHandshakeChannels(ctx, c, srcPortID, dstPortID)
+		if err != nil {
+			c.log.Info(
+				"Failed to query channels opened by governance",
+				zap.String("chain_id", c.ChainID()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if channelID := govOpenedChannel(channels, existing); channelID != "" {
+			c.log.Info(
+				"Channel opened by governance",
+				zap.String("chain_id", c.ChainID()),
+				zap.String("port_id", srcPortID),
+				zap.String("channel_id", channelID),
+			)
+			return channelID, nil
+		}
+		c.log.Debug(
+			"Channel not opened by governance yet",
+			zap.String("chain_id", c.ChainID()),
+			zap.String("port_id", srcPortID),
+		)
+	}
+}
+
+// govOpenedChannel returns the identifier of the channel in INIT without counterparty channel, other than the
+// existing ones, i.e. the channel opened by the governance proposal, if any.
+func govOpenedChannel(channels []*chantypes.IdentifiedChannel, existing map[string]bool) string {
+	for _, channel := range channels {
+		if channel.State == chantypes.INIT && channel.Counterparty.ChannelId == "" && !existing[channel.ChannelId] {
+			return channel.ChannelId
+		}
+	}
+	return ""
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
@@ -24,6 +25,7 @@ func (c *Chain) CreateClients(ctx context.Context,
 	customClientTrustingPeriod,
 	maxClockDrift time.Duration,
 	customClientTrustingPeriodPercentage int64,
+	trustLevel cmtmath.Fraction,
 	memo string) (string, string, error) {
 	// Query the latest heights on src and dst and retry if the query fails
 	var srch, dsth int64
@@ -76,7 +78,7 @@ func (c *Chain) CreateClients(ctx context.Context,
 			allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour,
 			override, customClientTrustingPeriod,
 			overrideUnbondingPeriod, maxClockDrift,
-			customClientTrustingPeriodPercentage, memo)
+			customClientTrustingPeriodPercentage, trustLevel, memo)
 		if err != nil {
 			return fmt.Errorf("failed to create client on src chain{%s}: %w", c.ChainID(), err)
 		}
@@ -91,7 +93,7 @@ func (c *Chain) CreateClients(ctx context.Context,
 			allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour,
 			override, customClientTrustingPeriod,
 			overrideUnbondingPeriod, maxClockDrift,
-			customClientTrustingPeriodPercentage, memo)
+			customClientTrustingPeriodPercentage, trustLevel, memo)
 		if err != nil {
 			return fmt.Errorf("failed to create client on dst chain{%s}: %w", dst.ChainID(), err)
 		}
@@ -126,20 +128,29 @@ func CreateClient(
 	overrideUnbondingPeriod,
 	maxClockDrift time.Duration,
 	customClientTrustingPeriodPercentage int64,
+	trustLevel cmtmath.Fraction,
 	memo string) (string, error) {
 	// If a client ID was specified in the path and override is not set, ensure the client exists.
 	if !override && src.PathEnd.ClientID != "" {
-		// TODO: check client is not expired
-		_, err := src.ChainProvider.QueryClientStateResponse(ctx, int64(srcUpdateHeader.Height()), src.ClientID())
-		if err != nil {
-			return "", fmt.Errorf("please ensure provided on-chain client (%s) exists on the chain (%s): %w",
-				src.PathEnd.ClientID, src.ChainID(), err)
+		if err := VerifyClient(ctx, src, dst, src.PathEnd.ClientID); err != nil {
+			return "", fmt.Errorf("please ensure provided on-chain client (%s) exists on the chain (%s) and tracks the chain (%s): %w",
+				src.PathEnd.ClientID, src.ChainID(), dst.ChainID(), err)
 		}
 
 		return "", nil
 	}
 
+	// The localhost client of a chain exists from genesis and is never created.
+	if src.PathEnd.clientType() == ClientTypeLocalhost {
+		src.PathEnd.ClientID = ibcexported.LocalhostClientID
+		return src.PathEnd.ClientID, nil
+	}
+
 	// Otherwise, create client for the destination chain on the source chain.
+	lc, err := lightClient(src.PathEnd.clientType())
+	if err != nil {
+		return "", err
+	}
 
 	// Query the trusting period for dst and retry if the query fails
 	tp := customClientTrustingPeriod
@@ -184,7 +195,15 @@ func CreateClient(
 
 	// We want to create a light client on the src chain which tracks the state of the dst chain.
 	// So we build a new client state from dst and attempt to use this for creating the light client on src.
-	clientState, err := dst.ChainProvider.NewClientState(dst.ChainID(), dstUpdateHeader, tp, ubdPeriod, maxClockDrift, allowUpdateAfterExpiry, allowUpdateAfterMisbehaviour)
+	clientState, consensusState, err := lc.NewClientState(dst.ChainProvider, dstUpdateHeader, ClientStateParams{
+		ChainID:                      dst.ChainID(),
+		TrustingPeriod:               tp,
+		UnbondingPeriod:              ubdPeriod,
+		MaxClockDrift:                maxClockDrift,
+		AllowUpdateAfterExpiry:       allowUpdateAfterExpiry,
+		AllowUpdateAfterMisbehaviour: allowUpdateAfterMisbehaviour,
+		TrustLevel:                   trustLevel,
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to create new client state for chain{%s}: %w", dst.ChainID(), err)
 	}
@@ -222,7 +241,7 @@ func CreateClient(
 	// the dst chains implementation of CreateClient, to ensure the proper client/header
 	// logic is executed, but the message gets submitted on the src chain which means
 	// we need to sign with the address from src.
-	createMsg, err := src.ChainProvider.MsgCreateClient(clientState, dstUpdateHeader.ConsensusState())
+	createMsg, err := src.ChainProvider.MsgCreateClient(clientState, consensusState)
 	if err != nil {
 		return "", fmt.Errorf("failed to compose CreateClient msg for chain{%s} tracking the state of chain{%s}: %w",
 			src.ChainID(), dst.ChainID(), err)
@@ -248,16 +267,24 @@ func CreateClient(
 		}
 
 		return nil
-	}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+	}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr, retry.RetryIf(func(err error) bool {
+		// failures which sending the message again cannot recover from are returned right away.
+		return provider.ClassifyTxFailure(err).Retryable()
+	})); err != nil {
 		return "", err
 	}
 
 	// update the client identifier
-	// use index 0, the transaction only has one message
-	if clientID, err = parseClientIDFromEvents(res.Events); err != nil {
+	if clientID, err = ParseClientIDFromEvents(res.Events); err != nil {
 		return "", err
 	}
 
+	// the client parsed from the events is verified by query to track dst, since other relayers may create
+	// clients on src in the same block.
+	if err := VerifyClient(ctx, src, dst, clientID); err != nil {
+		return "", fmt.Errorf("created client{%s} on chain{%s} does not track chain{%s}: %w", clientID, src.ChainID(), dst.ChainID(), err)
+	}
+
 	src.PathEnd.ClientID = clientID
 
 	src.log.Info(
@@ -273,11 +300,35 @@ func CreateClient(
 // MsgUpdateClient queries for the current client state on dst,
 // then queries for the latest and trusted headers on src
 // in order to build a MsgUpdateClient message for dst.
+// It fails if the client can only be updated through intermediate heights, see MsgsUpdateClient.
 func MsgUpdateClient(
 	ctx context.Context,
 	src, dst *Chain,
 	srch, dsth int64,
 ) (provider.RelayerMessage, error) {
+	msgs, err := MsgsUpdateClient(ctx, src, dst, srch, dsth, false)
+	if err != nil {
+		return nil, err
+	}
+	return msgs[0], nil
+}
+
+// MsgsUpdateClient builds the messages to update the client on dst to the header of src at srch. The header must
+// satisfy the trust level of the client. If it does not, e.g. because the client fell so far behind that the
+// validator set of src rotated too much since its trusted height, and forceBisection is set, the client is caught
+// up through intermediate headers found by bisection, each of which satisfies the trust level, with a message for
+// each of them in order.
+func MsgsUpdateClient(
+	ctx context.Context,
+	src, dst *Chain,
+	srch, dsth int64,
+	forceBisection bool,
+) ([]provider.RelayerMessage, error) {
+	lc, err := lightClient(dst.PathEnd.clientType())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update client %s on chain %s: %w", dst.ClientID(), dst.ChainID(), err)
+	}
+
 	var dstClientState ibcexported.ClientState
 	if err := retry.Do(func() error {
 		var err error
@@ -350,31 +401,78 @@ func MsgUpdateClient(
 		return nil, err
 	}
 
-	var updateHeader ibcexported.ClientMessage
+	trustedHeight := dstClientState.GetLatestHeight().(clienttypes.Height)
+	steps := []provider.ClientUpdateStep{{TrustedHeight: trustedHeight, TrustedHeader: dstTrustedHeader, Header: srcHeader}}
+
+	if tmClientState, ok := dstClientState.(*tmclient.ClientState); ok {
+		trustLevel := tmClientState.TrustLevel.ToTendermint()
+		var err error
+		steps, err = clientUpdateSteps(src.ChainID(), trustLevel, steps[0], forceBisection, func(height uint64) (provider.IBCHeader, error) {
+			return queryIBCHeader(ctx, src, int64(height))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot update client %s on chain %s to height %d: %w", dst.ClientID(), dst.ChainID(), srch, err)
+		}
+		if len(steps) > 1 {
+			dst.log.Info(
+				"Updating client through intermediate heights",
+				zap.String("chain_id", dst.ChainID()),
+				zap.String("client_id", dst.ClientID()),
+				zap.Int("updates", len(steps)),
+			)
+		}
+	}
+
+	msgs := make([]provider.RelayerMessage, 0, len(steps))
+	for _, step := range steps {
+		var updateHeader ibcexported.ClientMessage
+		if err := retry.Do(func() error {
+			var err error
+			updateHeader, err = lc.ClientMessage(src.ChainProvider, step.Header, step.TrustedHeight, step.TrustedHeader)
+			return err
+		}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
+			src.log.Info(
+				"Failed to build update client header",
+				zap.String("client_id", dst.ClientID()),
+				zap.Uint("attempt", n+1),
+				zap.Uint("max_attempts", RtyAttNum),
+				zap.Error(err),
+			)
+		})); err != nil {
+			return nil, err
+		}
+
+		// updates off-chain light client
+		msg, err := dst.ChainProvider.MsgUpdateClient(dst.ClientID(), updateHeader)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// queryIBCHeader queries the IBC header of chain at height, retrying if the query fails.
+func queryIBCHeader(ctx context.Context, chain *Chain, height int64) (provider.IBCHeader, error) {
+	var header provider.IBCHeader
 	if err := retry.Do(func() error {
 		var err error
-		updateHeader, err = src.ChainProvider.MsgUpdateClientHeader(srcHeader, dstClientState.GetLatestHeight().(clienttypes.Height), dstTrustedHeader)
+		header, err = chain.ChainProvider.QueryIBCHeader(ctx, height)
 		return err
-	}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
-		src.log.Info(
-			"Failed to build update client header",
-			zap.String("client_id", dst.ClientID()),
-			zap.Uint("attempt", n+1),
-			zap.Uint("max_attempts", RtyAttNum),
-			zap.Error(err),
-		)
-	})); err != nil {
-		return nil, err
+	}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+		return nil, fmt.Errorf("failed to query IBC header of chain %s at height %d: %w", chain.ChainID(), height, err)
 	}
-
-	// updates off-chain light client
-	return dst.ChainProvider.MsgUpdateClient(dst.ClientID(), updateHeader)
+	return header, nil
 }
 
 // UpdateClients updates clients for src on dst and dst on src given the configured paths.
+// If forceBisection is set, a client which cannot be updated to the latest height in one update, because the
+// validator set rotated too much, is caught up through intermediate heights, so that the clients of dormant paths
+// can be revived.
 func UpdateClients(
 	ctx context.Context,
 	src, dst *Chain,
+	forceBisection bool,
 	memo string,
 ) error {
 	srch, dsth, err := QueryLatestHeights(ctx, src, dst)
@@ -382,16 +480,16 @@ func UpdateClients(
 		return err
 	}
 
-	var srcMsgUpdateClient, dstMsgUpdateClient provider.RelayerMessage
+	var srcMsgsUpdateClient, dstMsgsUpdateClient []provider.RelayerMessage
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(func() error {
 		var err error
-		srcMsgUpdateClient, err = MsgUpdateClient(egCtx, dst, src, dsth, srch)
+		srcMsgsUpdateClient, err = MsgsUpdateClient(egCtx, dst, src, dsth, srch, forceBisection)
 		return err
 	})
 	eg.Go(func() error {
 		var err error
-		dstMsgUpdateClient, err = MsgUpdateClient(egCtx, src, dst, srch, dsth)
+		dstMsgsUpdateClient, err = MsgsUpdateClient(egCtx, src, dst, srch, dsth, forceBisection)
 		return err
 	})
 
@@ -400,8 +498,8 @@ func UpdateClients(
 	}
 
 	clients := &RelayMsgs{
-		Src: []provider.RelayerMessage{srcMsgUpdateClient},
-		Dst: []provider.RelayerMessage{dstMsgUpdateClient},
+		Src: srcMsgsUpdateClient,
+		Dst: dstMsgsUpdateClient,
 	}
 
 	// Send msgs to both chains
@@ -551,22 +649,6 @@ func findMatchingClient(ctx context.Context, src, dst *Chain, newClientState ibc
 	return "", nil
 }
 
-// parseClientIDFromEvents parses events emitted from a MsgCreateClient and returns the
-// client identifier.
-func parseClientIDFromEvents(events []provider.RelayerEvent) (string, error) {
-	for _, event := range events {
-		if event.EventType == clienttypes.EventTypeCreateClient {
-			for attributeKey, attributeValue := range event.Attributes {
-				if attributeKey == clienttypes.AttributeKeyClientID {
-					return attributeValue, nil
-				}
-			}
-		}
-	}
-
-	return "", fmt.Errorf("client identifier event attribute not found")
-}
-
 type ClientStateInfo struct {
 	ChainID        string
 	TrustingPeriod time.Duration
@@ -0,0 +1,178 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"go.uber.org/zap"
+)
+
+// ErrUnverifiableClient is returned by MatchClient for clients of another type than 07-tendermint,
+// which can not be verified to track the counterparty chain.
+var ErrUnverifiableClient = errors.New("client can not be verified")
+
+// ClientMatch is the result of checking that an existing client tracks a counterparty chain.
+type ClientMatch struct {
+	ChainID             string `json:"chain_id"`
+	ClientID            string `json:"client_id"`
+	CounterpartyChainID string `json:"counterparty_chain_id"`
+
+	TrackedChainID  string `json:"tracked_chain_id"`
+	LatestHeight    string `json:"latest_height"`
+	TrustingPeriod  string `json:"trusting_period"`
+	UnbondingPeriod string `json:"unbonding_period"`
+
+	// Problems are the reasons why the client must not be used for the counterparty chain.
+	Problems []string `json:"problems,omitempty"`
+
+	// Warnings are checks which could not be made, e.g. because the counterparty node is pruned.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Err returns an error listing the problems of the client, if any.
+func (m ClientMatch) Err() error {
+	if len(m.Problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("client %s on chain %s does not track chain %s: %s",
+		m.ClientID, m.ChainID, m.CounterpartyChainID, strings.Join(m.Problems, "; "))
+}
+
+// MatchClient checks that the client with clientID on src tracks dst: that it is a client of the chain ID of dst,
+// neither frozen nor expired, whose latest height is not ahead of dst, whose trusting period is shorter than the
+// unbonding period of dst, and whose latest consensus state matches the header of dst at that height.
+// The returned error is only about the queries, the problems of the client are reported by the ClientMatch.
+func MatchClient(ctx context.Context, src, dst *Chain, clientID string) (ClientMatch, error) {
+	m := ClientMatch{ChainID: src.ChainID(), ClientID: clientID, CounterpartyChainID: dst.ChainID()}
+
+	srch, err := src.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return m, fmt.Errorf("failed to query latest height of chain %s: %w", src.ChainID(), err)
+	}
+	clientState, err := src.ChainProvider.QueryClientState(ctx, srch, clientID)
+	if err != nil {
+		return m, fmt.Errorf("failed to query client %s on chain %s: %w", clientID, src.ChainID(), err)
+	}
+	cs, ok := clientState.(*tmclient.ClientState)
+	if !ok {
+		return m, fmt.Errorf("%w: client %s is of type %s", ErrUnverifiableClient, clientID, clientState.ClientType())
+	}
+
+	consRes, err := src.ChainProvider.QueryClientConsensusState(ctx, srch, clientID, cs.LatestHeight)
+	if err != nil {
+		return m, fmt.Errorf("failed to query consensus state of client %s on chain %s: %w", clientID, src.ChainID(), err)
+	}
+	exportedConsState, err := clienttypes.UnpackConsensusState(consRes.ConsensusState)
+	if err != nil {
+		return m, err
+	}
+	consState, ok := exportedConsState.(*tmclient.ConsensusState)
+	if !ok {
+		return m, fmt.Errorf("got type(%T) expected type(*tmclient.ConsensusState)", exportedConsState)
+	}
+
+	dsth, err := dst.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return m, fmt.Errorf("failed to query latest height of chain %s: %w", dst.ChainID(), err)
+	}
+	unbondingPeriod, err := dst.ChainProvider.QueryUnbondingPeriod(ctx)
+	if err != nil {
+		return m, fmt.Errorf("failed to query unbonding period of chain %s: %w", dst.ChainID(), err)
+	}
+
+	// the header may be pruned from the counterparty node, in which case the consensus state is not compared.
+	var counterpartyConsState *tmclient.ConsensusState
+	if cs.ChainId == dst.ChainID() && cs.LatestHeight.GetRevisionHeight() <= uint64(dsth) {
+		header, err := dst.ChainProvider.QueryIBCHeader(ctx, int64(cs.LatestHeight.GetRevisionHeight()))
+		if err != nil {
+			m.Warnings = append(m.Warnings, fmt.Sprintf(
+				"failed to query header of chain %s at height %d to compare the consensus state: %v",
+				dst.ChainID(), cs.LatestHeight.GetRevisionHeight(), err))
+		} else if counterpartyConsState, ok = header.ConsensusState().(*tmclient.ConsensusState); !ok {
+			return m, fmt.Errorf("got type(%T) expected type(*tmclient.ConsensusState)", header.ConsensusState())
+		}
+	}
+
+	m.check(cs, consState, dsth, unbondingPeriod, counterpartyConsState, time.Now())
+	return m, nil
+}
+
+// check records the problems of the client state cs with the consensus state consState at its latest height,
+// given the latest height and unbonding period of the counterparty chain, and the consensus state of the
+// counterparty chain at the latest height of the client if known.
+func (m *ClientMatch) check(
+	cs *tmclient.ClientState,
+	consState *tmclient.ConsensusState,
+	counterpartyHeight int64,
+	counterpartyUnbondingPeriod time.Duration,
+	counterpartyConsState *tmclient.ConsensusState,
+	now time.Time,
+) {
+	m.TrackedChainID = cs.ChainId
+	m.LatestHeight = cs.LatestHeight.String()
+	m.TrustingPeriod = cs.TrustingPeriod.String()
+	m.UnbondingPeriod = cs.UnbondingPeriod.String()
+
+	if cs.ChainId != m.CounterpartyChainID {
+		m.Problems = append(m.Problems, fmt.Sprintf("client tracks chain %s", cs.ChainId))
+		// the other checks are meaningless for another chain.
+		return
+	}
+	if !cs.FrozenHeight.IsZero() {
+		m.Problems = append(m.Problems, fmt.Sprintf("client is frozen at height %s", cs.FrozenHeight))
+	}
+	if revision := clienttypes.ParseChainID(m.CounterpartyChainID); cs.LatestHeight.GetRevisionNumber() != revision {
+		m.Problems = append(m.Problems, fmt.Sprintf("client latest height %s is not of the revision %d of the chain",
+			cs.LatestHeight, revision))
+	}
+	if cs.LatestHeight.GetRevisionHeight() > uint64(counterpartyHeight) {
+		m.Problems = append(m.Problems, fmt.Sprintf("client latest height %s is ahead of the chain height %d",
+			cs.LatestHeight, counterpartyHeight))
+	}
+	if cs.TrustingPeriod >= counterpartyUnbondingPeriod {
+		m.Problems = append(m.Problems, fmt.Sprintf("client trusting period %s is not shorter than the unbonding period %s of the chain",
+			cs.TrustingPeriod, counterpartyUnbondingPeriod))
+	}
+	if cs.IsExpired(consState.Timestamp, now) {
+		m.Problems = append(m.Problems, fmt.Sprintf("client expired at %s",
+			consState.Timestamp.Add(cs.TrustingPeriod).Format(time.RFC3339)))
+	}
+	if counterpartyConsState != nil && !consensusStatesMatch(consState, counterpartyConsState) {
+		m.Problems = append(m.Problems, fmt.Sprintf("client consensus state at height %s does not match the header of the chain",
+			cs.LatestHeight))
+	}
+}
+
+// consensusStatesMatch returns true if the consensus states commit to the same app hash, time and next validators.
+func consensusStatesMatch(a, b *tmclient.ConsensusState) bool {
+	return a.Timestamp.Equal(b.Timestamp) &&
+		bytes.Equal(a.Root.GetHash(), b.Root.GetHash()) &&
+		bytes.Equal(a.NextValidatorsHash, b.NextValidatorsHash)
+}
+
+// VerifyClient returns an error if the existing client with clientID on src does not track dst, see MatchClient.
+// Clients which can not be verified are accepted.
+func VerifyClient(ctx context.Context, src, dst *Chain, clientID string) error {
+	m, err := MatchClient(ctx, src, dst, clientID)
+	if errors.Is(err, ErrUnverifiableClient) {
+		src.log.Info("Using existing client which can not be verified", zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, warning := range m.Warnings {
+		src.log.Warn("Could not verify existing client",
+			zap.String("chain_id", m.ChainID),
+			zap.String("client_id", clientID),
+			zap.String("warning", warning),
+		)
+	}
+	return m.Err()
+}
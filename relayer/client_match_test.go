@@ -0,0 +1,95 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientMatch(t *testing.T) {
+	now := time.Now()
+	consState := &tmclient.ConsensusState{
+		Timestamp:          now.Add(-time.Hour),
+		Root:               commitmenttypes.NewMerkleRoot([]byte("apphash")),
+		NextValidatorsHash: []byte("nextvals"),
+	}
+	clientState := func() *tmclient.ClientState {
+		return &tmclient.ClientState{
+			ChainId:         "osmosis-1",
+			TrustingPeriod:  10 * 24 * time.Hour,
+			UnbondingPeriod: 14 * 24 * time.Hour,
+			LatestHeight:    clienttypes.NewHeight(1, 100),
+		}
+	}
+	unbonding := 14 * 24 * time.Hour
+
+	for _, tc := range []struct {
+		name         string
+		clientState  func(*tmclient.ClientState)
+		counterparty *tmclient.ConsensusState
+		problems     []string
+	}{
+		{
+			name:         "matches",
+			counterparty: consState,
+		},
+		{
+			name:        "other chain",
+			clientState: func(cs *tmclient.ClientState) { cs.ChainId = "juno-1" },
+			problems:    []string{"client tracks chain juno-1"},
+		},
+		{
+			name:        "frozen",
+			clientState: func(cs *tmclient.ClientState) { cs.FrozenHeight = clienttypes.NewHeight(1, 50) },
+			problems:    []string{"client is frozen at height 1-50"},
+		},
+		{
+			name:        "other revision",
+			clientState: func(cs *tmclient.ClientState) { cs.LatestHeight = clienttypes.NewHeight(2, 100) },
+			problems:    []string{"client latest height 2-100 is not of the revision 1 of the chain"},
+		},
+		{
+			name:        "ahead of chain",
+			clientState: func(cs *tmclient.ClientState) { cs.LatestHeight = clienttypes.NewHeight(1, 1000) },
+			problems:    []string{"client latest height 1-1000 is ahead of the chain height 500"},
+		},
+		{
+			name:        "trusting period too long",
+			clientState: func(cs *tmclient.ClientState) { cs.TrustingPeriod = unbonding },
+			problems:    []string{"client trusting period 336h0m0s is not shorter than the unbonding period 336h0m0s of the chain"},
+		},
+		{
+			name:        "expired",
+			clientState: func(cs *tmclient.ClientState) { cs.TrustingPeriod = time.Minute },
+			problems:    []string{"client expired at " + now.Add(-59*time.Minute).Format(time.RFC3339)},
+		},
+		{
+			name: "other consensus state",
+			counterparty: &tmclient.ConsensusState{
+				Timestamp:          consState.Timestamp,
+				Root:               commitmenttypes.NewMerkleRoot([]byte("otherhash")),
+				NextValidatorsHash: consState.NextValidatorsHash,
+			},
+			problems: []string{"client consensus state at height 1-100 does not match the header of the chain"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := clientState()
+			if tc.clientState != nil {
+				tc.clientState(cs)
+			}
+			m := ClientMatch{ChainID: "cosmoshub-4", ClientID: "07-tendermint-0", CounterpartyChainID: "osmosis-1"}
+			m.check(cs, consState, 500, unbonding, tc.counterparty, now)
+			require.Equal(t, tc.problems, m.Problems)
+			if tc.problems == nil {
+				require.NoError(t, m.Err())
+			} else {
+				require.ErrorContains(t, m.Err(), "client 07-tendermint-0 on chain cosmoshub-4 does not track chain osmosis-1")
+			}
+		})
+	}
+}
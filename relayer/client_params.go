@@ -0,0 +1,177 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"go.uber.org/zap"
+)
+
+// DefaultClientParamsCheckInterval is how often WatchClientParams checks the parameters of the clients of the paths.
+const DefaultClientParamsCheckInterval = time.Hour
+
+// ClientParamsDrift is a parameter of a client which no longer matches the chain it tracks or the config of its path.
+type ClientParamsDrift struct {
+	// Param is the name of the client parameter, e.g. trusting-period.
+	Param string
+
+	// Unsafe is true if the client can be made to trust headers which are not secured by the stake of the validators
+	// of the chain, rather than only differing from the config of the path.
+	Unsafe bool
+
+	Reason string
+}
+
+// CheckClientParams compares the trust level, trusting period and unbonding period of the tendermint client state
+// against the unbonding period of the chain it tracks, as queried from the staking params of the chain, and against
+// the client trust options of its path, which may be nil.
+// Governance proposals which reduce the unbonding period of a chain make the clients of the chain unsafe,
+// since validators may unbond and forge headers which the clients still trust.
+func CheckClientParams(cs *tmclient.ClientState, unbondingPeriod time.Duration, trust *ClientTrustOptions) []ClientParamsDrift {
+	var drifts []ClientParamsDrift
+
+	if cs.TrustingPeriod >= unbondingPeriod {
+		drifts = append(drifts, ClientParamsDrift{
+			Param:  "trusting-period",
+			Unsafe: true,
+			Reason: fmt.Sprintf("trusting period %s of the client is not shorter than the unbonding period %s of the chain",
+				cs.TrustingPeriod, unbondingPeriod),
+		})
+	}
+	if cs.UnbondingPeriod != unbondingPeriod {
+		drifts = append(drifts, ClientParamsDrift{
+			Param: "unbonding-period",
+			Reason: fmt.Sprintf("unbonding period %s of the client differs from the unbonding period %s of the chain",
+				cs.UnbondingPeriod, unbondingPeriod),
+		})
+	}
+
+	if trust == nil {
+		return drifts
+	}
+
+	trustingPeriod, _, err := trust.Durations()
+	switch {
+	case err != nil:
+		// invalid options are reported by config validation.
+	case trustingPeriod != 0 && cs.TrustingPeriod != trustingPeriod:
+		drifts = append(drifts, ClientParamsDrift{
+			Param: "trusting-period",
+			Reason: fmt.Sprintf("trusting period %s of the client differs from the trusting-period %s of the path",
+				cs.TrustingPeriod, trustingPeriod),
+		})
+	case trustingPeriod == 0 && trust.TrustingPeriodPercentage > 0 &&
+		cs.TrustingPeriod > time.Duration(int64(unbondingPeriod)/100*trust.TrustingPeriodPercentage):
+		drifts = append(drifts, ClientParamsDrift{
+			Param: "trusting-period",
+			Reason: fmt.Sprintf("trusting period %s of the client exceeds the trusting-period-percentage %d%% of the unbonding period %s of the chain",
+				cs.TrustingPeriod, trust.TrustingPeriodPercentage, unbondingPeriod),
+		})
+	}
+
+	if trust.TrustLevel != "" {
+		trustLevel, err := ParseTrustLevel(trust.TrustLevel)
+		if err == nil && cs.TrustLevel != tmclient.NewFractionFromTm(trustLevel) {
+			drifts = append(drifts, ClientParamsDrift{
+				Param: "trust-level",
+				Reason: fmt.Sprintf("trust level %d/%d of the client differs from the trust-level %s of the path",
+					cs.TrustLevel.Numerator, cs.TrustLevel.Denominator, trust.TrustLevel),
+			})
+		}
+	}
+
+	return drifts
+}
+
+// WatchClientParams checks the parameters of the clients of the paths every interval until ctx is done,
+// and logs a warning for each client parameter which drifted from the chain tracked by the client or the config
+// of its path. If metrics is not nil, clients with unsafe parameters are reported by the client params unsafe gauge.
+func WatchClientParams(
+	ctx context.Context,
+	log *zap.Logger,
+	chains map[string]*Chain,
+	paths []NamedPath,
+	interval time.Duration,
+	metrics *processor.PrometheusMetrics,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var wg sync.WaitGroup
+		for _, np := range paths {
+			for _, pe := range []struct{ end, counterparty *PathEnd }{{np.Path.Src, np.Path.Dst}, {np.Path.Dst, np.Path.Src}} {
+				c, ok := chains[pe.end.ChainID]
+				cp, cpOk := chains[pe.counterparty.ChainID]
+				if !ok || !cpOk || pe.end.ClientID == "" {
+					continue
+				}
+
+				np, pe := np, pe
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					log := log.With(
+						zap.String("path_name", np.Name),
+						zap.String("chain_id", pe.end.ChainID),
+						zap.String("client_id", pe.end.ClientID),
+						zap.String("counterparty_chain_id", pe.counterparty.ChainID),
+					)
+					drifts, err := checkClientParams(ctx, c, cp, pe.end.ClientID, np.Path.ClientTrust)
+					if err != nil {
+						log.Debug("Failed to check client parameters", zap.Error(err))
+						return
+					}
+
+					unsafe := false
+					for _, d := range drifts {
+						unsafe = unsafe || d.Unsafe
+						log.Warn(
+							"Client parameter drifted",
+							zap.String("param", d.Param),
+							zap.Bool("unsafe", d.Unsafe),
+							zap.String("reason", d.Reason),
+						)
+					}
+					if metrics != nil {
+						metrics.SetClientParamsUnsafe(np.Name, pe.end.ChainID, pe.end.ClientID, unsafe)
+					}
+				}()
+			}
+		}
+		wg.Wait()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkClientParams queries the client with clientID on chain c, which tracks the counterparty chain cp,
+// and the unbonding period of cp, and checks the parameters of the client against them.
+// Clients other than tendermint clients are not checked.
+func checkClientParams(ctx context.Context, c, cp *Chain, clientID string, trust *ClientTrustOptions) ([]ClientParamsDrift, error) {
+	height, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientState, err := c.ChainProvider.QueryClientState(ctx, height, clientID)
+	if err != nil {
+		return nil, err
+	}
+	cs, ok := clientState.(*tmclient.ClientState)
+	if !ok {
+		return nil, nil
+	}
+	unbondingPeriod, err := cp.ChainProvider.QueryUnbondingPeriod(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unbonding period of chain %s: %w", cp.ChainID(), err)
+	}
+	return CheckClientParams(cs, unbondingPeriod, trust), nil
+}
@@ -0,0 +1,47 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckClientParams(t *testing.T) {
+	const day = 24 * time.Hour
+	cs := &tmclient.ClientState{
+		TrustLevel:      tmclient.DefaultTrustLevel,
+		TrustingPeriod:  14 * day,
+		UnbondingPeriod: 21 * day,
+	}
+
+	require.Empty(t, CheckClientParams(cs, 21*day, nil))
+
+	// governance reduced the unbonding period below the trusting period of the client
+	drifts := CheckClientParams(cs, 14*day, nil)
+	require.Len(t, drifts, 2)
+	require.Equal(t, "trusting-period", drifts[0].Param)
+	require.True(t, drifts[0].Unsafe)
+	require.Equal(t, "unbonding-period", drifts[1].Param)
+	require.False(t, drifts[1].Unsafe)
+
+	// the unbonding period was reduced, but the client remains safe
+	drifts = CheckClientParams(cs, 20*day, nil)
+	require.Len(t, drifts, 1)
+	require.False(t, drifts[0].Unsafe)
+
+	require.Empty(t, CheckClientParams(cs, 21*day, &ClientTrustOptions{TrustingPeriod: "336h", TrustLevel: "1/3"}))
+
+	drifts = CheckClientParams(cs, 21*day, &ClientTrustOptions{TrustingPeriod: "240h", TrustLevel: "2/3"})
+	require.Len(t, drifts, 2)
+	require.Equal(t, "trusting-period", drifts[0].Param)
+	require.Equal(t, "trust-level", drifts[1].Param)
+
+	// the trusting period is 2/3 of the unbonding period
+	require.Empty(t, CheckClientParams(cs, 21*day, &ClientTrustOptions{TrustingPeriodPercentage: 70}))
+	drifts = CheckClientParams(cs, 21*day, &ClientTrustOptions{TrustingPeriodPercentage: 60})
+	require.Len(t, drifts, 1)
+	require.Equal(t, "trusting-period", drifts[0].Param)
+	require.False(t, drifts[0].Unsafe)
+}
@@ -0,0 +1,109 @@
+package relayer
+
+import (
+	"fmt"
+	"time"
+
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	"github.com/cometbft/cometbft/light"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// ClientTrustOptions configures the trust parameters of the clients created for a path.
+// Fields which are not set fall back to the flags of the client creation commands and their defaults.
+type ClientTrustOptions struct {
+	// TrustingPeriod is the trusting period of the clients, e.g. 336h. If not set, it is derived from the
+	// unbonding period of the counterparty chain, which is queried from its staking params.
+	TrustingPeriod string `yaml:"trusting-period,omitempty" json:"trusting-period,omitempty"`
+
+	// TrustingPeriodPercentage is the percentage of the unbonding period of the counterparty chain
+	// which is used as the trusting period when TrustingPeriod is not set.
+	TrustingPeriodPercentage int64 `yaml:"trusting-period-percentage,omitempty" json:"trusting-period-percentage,omitempty"`
+
+	// MaxClockDrift is the max clock drift of the clients, e.g. 10m.
+	MaxClockDrift string `yaml:"max-clock-drift,omitempty" json:"max-clock-drift,omitempty"`
+
+	// TrustLevel is the fraction of the voting power of the trusted validators which must have signed a header
+	// to update the clients, e.g. 1/3 or 2/3.
+	TrustLevel string `yaml:"trust-level,omitempty" json:"trust-level,omitempty"`
+}
+
+// Validate checks that the durations and the percentage of the ClientTrustOptions are valid.
+func (o *ClientTrustOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if _, _, err := o.Durations(); err != nil {
+		return err
+	}
+	if o.TrustingPeriodPercentage < 0 || o.TrustingPeriodPercentage > 100 {
+		return fmt.Errorf("trusting-period-percentage must be between 1 and 100, got %d", o.TrustingPeriodPercentage)
+	}
+	if o.TrustLevel != "" {
+		if _, err := ParseTrustLevel(o.TrustLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Durations returns the trusting period and max clock drift of the ClientTrustOptions, which are zero if not set.
+func (o *ClientTrustOptions) Durations() (trustingPeriod, maxClockDrift time.Duration, err error) {
+	if o == nil {
+		return 0, 0, nil
+	}
+	if o.TrustingPeriod != "" {
+		if trustingPeriod, err = time.ParseDuration(o.TrustingPeriod); err != nil {
+			return 0, 0, fmt.Errorf("invalid trusting-period %s: %w", o.TrustingPeriod, err)
+		}
+		if trustingPeriod <= 0 {
+			return 0, 0, fmt.Errorf("trusting-period must be positive, got %s", o.TrustingPeriod)
+		}
+	}
+	if o.MaxClockDrift != "" {
+		if maxClockDrift, err = time.ParseDuration(o.MaxClockDrift); err != nil {
+			return 0, 0, fmt.Errorf("invalid max-clock-drift %s: %w", o.MaxClockDrift, err)
+		}
+		if maxClockDrift <= 0 {
+			return 0, 0, fmt.Errorf("max-clock-drift must be positive, got %s", o.MaxClockDrift)
+		}
+	}
+	return trustingPeriod, maxClockDrift, nil
+}
+
+// ParseTrustLevel parses a trust level given as a fraction, e.g. 1/3 or 2/3, which must be within [1/3, 1].
+func ParseTrustLevel(s string) (cmtmath.Fraction, error) {
+	trustLevel, err := cmtmath.ParseFraction(s)
+	if err != nil {
+		return cmtmath.Fraction{}, fmt.Errorf("invalid trust-level %s: %w", s, err)
+	}
+	if err := light.ValidateTrustLevel(trustLevel); err != nil {
+		return cmtmath.Fraction{}, fmt.Errorf("invalid trust-level %s: %w", s, err)
+	}
+	return trustLevel, nil
+}
+
+// clientUpdateSteps returns the steps to perform the update of a client. The header of the update must satisfy
+// the trust level of the client. If it does not, e.g. because the validator set rotated too much since the trusted
+// height of the client, and forceBisection is set, the client is updated through intermediate headers, each of
+// which satisfies the trust level, with a step for each of them. The intermediate headers are queried with header.
+func clientUpdateSteps(
+	chainID string,
+	trustLevel cmtmath.Fraction,
+	update provider.ClientUpdateStep,
+	forceBisection bool,
+	header func(height uint64) (provider.IBCHeader, error),
+) ([]provider.ClientUpdateStep, error) {
+	err := provider.VerifyTrustLevel(chainID, update.TrustedHeader, update.Header, trustLevel)
+	if err == nil {
+		return []provider.ClientUpdateStep{update}, nil
+	}
+	if !forceBisection {
+		return nil, fmt.Errorf("%w; update it through intermediate heights with rly tx update-clients --force-bisection", err)
+	}
+	steps, err := provider.BisectClientUpdate(chainID, trustLevel, update, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bisect the update: %w", err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,116 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientTrustOptions(t *testing.T) {
+	var unset *ClientTrustOptions
+	require.NoError(t, unset.Validate())
+	tp, drift, err := unset.Durations()
+	require.NoError(t, err)
+	require.Zero(t, tp)
+	require.Zero(t, drift)
+
+	require.Error(t, (&ClientTrustOptions{TrustingPeriod: "24"}).Validate())
+	require.Error(t, (&ClientTrustOptions{TrustingPeriod: "-24h"}).Validate())
+	require.Error(t, (&ClientTrustOptions{MaxClockDrift: "soon"}).Validate())
+	require.Error(t, (&ClientTrustOptions{TrustingPeriodPercentage: 101}).Validate())
+	require.Error(t, (&ClientTrustOptions{TrustingPeriodPercentage: -1}).Validate())
+	require.Error(t, (&ClientTrustOptions{TrustLevel: "1/4"}).Validate())
+
+	o := &ClientTrustOptions{
+		TrustingPeriod:           "336h",
+		TrustingPeriodPercentage: 66,
+		MaxClockDrift:            "20s",
+		TrustLevel:               "2/3",
+	}
+	require.NoError(t, o.Validate())
+	tp, drift, err = o.Durations()
+	require.NoError(t, err)
+	require.Equal(t, 336*time.Hour, tp)
+	require.Equal(t, 20*time.Second, drift)
+}
+
+func TestParseTrustLevel(t *testing.T) {
+	trustLevel, err := ParseTrustLevel("2/3")
+	require.NoError(t, err)
+	require.Equal(t, cmtmath.Fraction{Numerator: 2, Denominator: 3}, trustLevel)
+
+	for _, invalid := range []string{"", "1/4", "4/3", "0/0", "half"} {
+		_, err := ParseTrustLevel(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestClientUpdateSteps(t *testing.T) {
+	const chainID = "chain-a"
+	oneThird := cmtmath.Fraction{Numerator: 1, Denominator: 3}
+
+	// the validator set is entirely replaced at height 56.
+	vals, privVals := tmtypes.RandValidatorSet(4, 10)
+	rotatedVals, rotatedPrivVals := tmtypes.RandValidatorSet(4, 10)
+	header := func(height uint64) (provider.IBCHeader, error) {
+		if height < 56 {
+			return signedIBCHeader(t, chainID, int64(height), vals, privVals), nil
+		}
+		return signedIBCHeader(t, chainID, int64(height), rotatedVals, rotatedPrivVals), nil
+	}
+	update := func(height uint64) provider.ClientUpdateStep {
+		trustedHeader, _ := header(11)
+		h, _ := header(height)
+		return provider.ClientUpdateStep{TrustedHeight: clienttypes.NewHeight(1, 10), TrustedHeader: trustedHeader, Header: h}
+	}
+
+	// an update satisfying the trust level is performed in one step, with or without bisection.
+	for _, forceBisection := range []bool{false, true} {
+		steps, err := clientUpdateSteps(chainID, oneThird, update(50), forceBisection, header)
+		require.NoError(t, err)
+		require.Len(t, steps, 1)
+	}
+
+	// an update past the rotation of the validator set fails unless bisection is forced.
+	_, err := clientUpdateSteps(chainID, oneThird, update(100), false, header)
+	require.ErrorContains(t, err, "rotated too much between heights 10 and 100")
+	require.ErrorContains(t, err, "--force-bisection")
+
+	steps, err := clientUpdateSteps(chainID, oneThird, update(100), true, header)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	require.Equal(t, uint64(55), steps[0].Header.Height())
+	require.Equal(t, uint64(100), steps[1].Header.Height())
+}
+
+// signedIBCHeader returns a header at height committed by all of the validators.
+func signedIBCHeader(
+	t *testing.T,
+	chainID string,
+	height int64,
+	vals *tmtypes.ValidatorSet,
+	privVals []tmtypes.PrivValidator,
+) provider.TendermintIBCHeader {
+	blockID := tmtypes.BlockID{
+		Hash:          tmhash.Sum([]byte("block")),
+		PartSetHeader: tmtypes.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+	}
+	voteSet := tmtypes.NewVoteSet(chainID, height, 0, cmtproto.PrecommitType, vals)
+	extCommit, err := tmtypes.MakeExtCommit(blockID, height, 0, voteSet, privVals, time.Now(), false)
+	require.NoError(t, err)
+
+	return provider.TendermintIBCHeader{
+		SignedHeader: &tmtypes.SignedHeader{
+			Header: &tmtypes.Header{ChainID: chainID, Height: height},
+			Commit: extCommit.ToCommit(),
+		},
+		ValidatorSet: vals,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/relayer/v2/relayer/codecs/ethsig"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -124,7 +125,7 @@ func (privKey PrivKey) Sign(digestBz []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return crypto.Sign(digestBz, key)
+	return ethsig.Sign(digestBz, key)
 }
 
 // ToECDSA returns the ECDSA private key as a reference to ecdsa.PrivateKey type.
@@ -231,5 +232,5 @@ func (pubKey PubKey) verifySignatureECDSA(msg, sig []byte) bool {
 	}
 
 	// the signature needs to be in [R || S] format when provided to VerifySignature
-	return crypto.VerifySignature(pubKey.Key, crypto.Keccak256Hash(msg).Bytes(), sig)
+	return ethsig.Verify(pubKey.Key, crypto.Keccak256Hash(msg).Bytes(), sig)
 }
@@ -0,0 +1,31 @@
+//go:build cgo && !purego
+
+package ethsig
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+)
+
+// Sign returns the [R || S || V] signature of the digest by key.
+func Sign(digest []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	if len(digest) != DigestLength {
+		return nil, errInvalidDigest
+	}
+	seckey := key.D.FillBytes(make([]byte, DigestLength))
+	defer zero(seckey)
+	return secp256k1.Sign(digest, seckey)
+}
+
+// Verify returns true if sig, in [R || S] format, is a signature of the digest by the compressed or uncompressed
+// public key. Malleable signatures, whose S is in the upper half of the curve order, are rejected.
+func Verify(pubKey, digest, sig []byte) bool {
+	return secp256k1.VerifySignature(pubKey, digest, sig)
+}
+
+func zero(bz []byte) {
+	for i := range bz {
+		bz[i] = 0
+	}
+}
@@ -0,0 +1,24 @@
+// Package ethsig signs and verifies the recoverable secp256k1 signatures of the eth_secp256k1 keys of
+// ethermint and injective chains.
+//
+// By default they are computed with libsecp256k1 through cgo, like go-ethereum does.
+// Binaries built without cgo, or with the purego build tag, compute them in pure Go instead,
+// so that static binaries can be cross-compiled, e.g. for arm64 or alpine:
+//
+//	CGO_ENABLED=0 go build -tags purego
+package ethsig
+
+import "errors"
+
+const (
+	// DigestLength is the length of the digests which are signed.
+	DigestLength = 32
+
+	// SignatureLength is the length of the [R || S || V] signatures, where V is the recovery ID.
+	SignatureLength = 65
+)
+
+var (
+	errInvalidDigest     = errors.New("digest must be exactly 32 bytes")
+	errInvalidPrivateKey = errors.New("invalid secp256k1 private key")
+)
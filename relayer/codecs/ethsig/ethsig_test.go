@@ -0,0 +1,40 @@
+package ethsig_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/codecs/ethsig"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	pubKey := ethcrypto.CompressPubkey(&key.PublicKey)
+	digest := ethcrypto.Keccak256([]byte("relayer"))
+
+	_, err = ethsig.Sign(digest[:31], key)
+	require.Error(t, err)
+
+	sig, err := ethsig.Sign(digest, key)
+	require.NoError(t, err)
+	require.Len(t, sig, ethsig.SignatureLength)
+
+	// the recovery ID recovers the public key, as go-ethereum does.
+	recovered, err := ethcrypto.SigToPub(digest, sig)
+	require.NoError(t, err)
+	require.Equal(t, key.PublicKey, *recovered)
+
+	require.True(t, ethsig.Verify(pubKey, digest, sig[:64]))
+	require.True(t, ethsig.Verify(ethcrypto.FromECDSAPub(&key.PublicKey), digest, sig[:64]))
+	require.False(t, ethsig.Verify(pubKey, digest, sig))
+	require.False(t, ethsig.Verify(pubKey, ethcrypto.Keccak256([]byte("other")), sig[:64]))
+
+	// the malleable signature with S negated is rejected.
+	n := ethcrypto.S256().Params().N
+	s := new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64]))
+	malleable := append(append([]byte{}, sig[:32]...), s.FillBytes(make([]byte, 32))...)
+	require.False(t, ethsig.Verify(pubKey, digest, malleable))
+}
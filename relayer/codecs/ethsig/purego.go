@@ -0,0 +1,51 @@
+//go:build !cgo || purego
+
+package ethsig
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	decredecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// Sign returns the [R || S || V] signature of the digest by key.
+func Sign(digest []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	if len(digest) != DigestLength {
+		return nil, errInvalidDigest
+	}
+
+	var priv secp256k1.PrivateKey
+	if overflow := priv.Key.SetByteSlice(key.D.Bytes()); overflow || priv.Key.IsZero() {
+		return nil, errInvalidPrivateKey
+	}
+	defer priv.Zero()
+
+	// the compact signature is [V || R || S], with V offset by 27.
+	sig := decredecdsa.SignCompact(&priv, digest, false)
+	v := sig[0] - 27
+	copy(sig, sig[1:])
+	sig[SignatureLength-1] = v
+	return sig, nil
+}
+
+// Verify returns true if sig, in [R || S] format, is a signature of the digest by the compressed or uncompressed
+// public key. Malleable signatures, whose S is in the upper half of the curve order, are rejected.
+func Verify(pubKey, digest, sig []byte) bool {
+	if len(sig) != SignatureLength-1 {
+		return false
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) {
+		return false
+	}
+	// libsecp256k1 rejects malleable signatures, decred does not.
+	if s.IsOverHalfOrder() {
+		return false
+	}
+	key, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil {
+		return false
+	}
+	return decredecdsa.NewSignature(&r, &s).Verify(digest, key)
+}
@@ -11,8 +11,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/relayer/v2/relayer/codecs/ethsig"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 )
 
 const (
@@ -108,7 +108,7 @@ func (privKey *PrivKey) UnmarshalAminoJSON(bz []byte) error {
 // Keccak256 hash of the provided message. The produced signature is 65 bytes
 // where the last byte contains the recovery ID.
 func (privKey PrivKey) Sign(msg []byte) ([]byte, error) {
-	return ethcrypto.Sign(ethcrypto.Keccak256Hash(msg).Bytes(), privKey.ToECDSA())
+	return ethsig.Sign(ethcrypto.Keccak256Hash(msg).Bytes(), privKey.ToECDSA())
 }
 
 // ToECDSA returns the ECDSA private key as a reference to ecdsa.PrivateKey type.
@@ -197,5 +197,5 @@ func (pubKey PubKey) VerifySignature(msg []byte, sig []byte) bool {
 	}
 
 	// the signature needs to be in [R || S] format when provided to VerifySignature
-	return secp256k1.VerifySignature(pubKey.Key, ethcrypto.Keccak256Hash(msg).Bytes(), sig)
+	return ethsig.Verify(pubKey.Key, ethcrypto.Keccak256Hash(msg).Bytes(), sig)
 }
@@ -0,0 +1,58 @@
+package relayer
+
+import (
+	"fmt"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// Concurrency tunes how much work is done at once on a path, independently of other paths, since the best
+// values differ between chains with fast and slow blocks. Fields which are not set do not limit the path.
+type Concurrency struct {
+	// MaxInFlightTxs is how many transactions broadcast to each chain of the path may await inclusion at once.
+	MaxInFlightTxs int `yaml:"max-in-flight-txs,omitempty" json:"max-in-flight-txs,omitempty"`
+
+	// WorkersPerPath is how many messages of the path are assembled at once, each of which queries proofs.
+	WorkersPerPath int `yaml:"workers-per-path,omitempty" json:"workers-per-path,omitempty"`
+
+	// QueryConcurrency is how many channels are flushed at once, and how many packets are queried at once
+	// when flushing a channel.
+	QueryConcurrency int `yaml:"query-concurrency,omitempty" json:"query-concurrency,omitempty"`
+
+	// Weight is the share of the path in the transactions broadcast per block to a chain shared with other paths,
+	// relative to the weights of the other paths, when the relayer is started with a tx budget per block.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+// Validate checks that the limits of the Concurrency are not negative.
+func (c *Concurrency) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxInFlightTxs < 0 {
+		return fmt.Errorf("max-in-flight-txs must not be negative, got %d", c.MaxInFlightTxs)
+	}
+	if c.WorkersPerPath < 0 {
+		return fmt.Errorf("workers-per-path must not be negative, got %d", c.WorkersPerPath)
+	}
+	if c.QueryConcurrency < 0 {
+		return fmt.Errorf("query-concurrency must not be negative, got %d", c.QueryConcurrency)
+	}
+	if c.Weight < 0 {
+		return fmt.Errorf("weight must not be negative, got %d", c.Weight)
+	}
+	return nil
+}
+
+// ProcessorConcurrency returns the processor.Concurrency with the limits of the Concurrency.
+func (c *Concurrency) ProcessorConcurrency() processor.Concurrency {
+	if c == nil {
+		return processor.Concurrency{}
+	}
+	return processor.Concurrency{
+		MaxInFlightTxs:   c.MaxInFlightTxs,
+		Workers:          c.WorkersPerPath,
+		QueryConcurrency: c.QueryConcurrency,
+		Weight:           c.Weight,
+	}
+}
@@ -0,0 +1,33 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrency(t *testing.T) {
+	var unset *Concurrency
+	require.NoError(t, unset.Validate())
+	require.Equal(t, processor.Concurrency{}, unset.ProcessorConcurrency())
+
+	require.Error(t, (&Concurrency{MaxInFlightTxs: -1}).Validate())
+	require.Error(t, (&Concurrency{WorkersPerPath: -1}).Validate())
+	require.Error(t, (&Concurrency{QueryConcurrency: -1}).Validate())
+	require.Error(t, (&Concurrency{Weight: -1}).Validate())
+
+	c := &Concurrency{
+		MaxInFlightTxs:   4,
+		WorkersPerPath:   8,
+		QueryConcurrency: 16,
+		Weight:           3,
+	}
+	require.NoError(t, c.Validate())
+	require.Equal(t, processor.Concurrency{
+		MaxInFlightTxs:   4,
+		Workers:          8,
+		QueryConcurrency: 16,
+		Weight:           3,
+	}, c.ProcessorConcurrency())
+}
@@ -2,7 +2,6 @@ package relayer
 
 import (
 	"context"
-	"time"
 
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	"github.com/cosmos/relayer/v2/relayer/processor"
@@ -11,12 +10,15 @@ import (
 )
 
 // CreateOpenConnections runs the connection creation messages on timeout until they pass.
-// The returned boolean indicates that the path end has been modified.
+// Unless override is set, the handshake of the connection recorded on the path ends is resumed, or otherwise
+// an open connection between the clients of the path is adopted, rather than opening a duplicate connection.
+// The identifiers of the connection ends are set on the path ends once they are verified, also when the
+// handshake is interrupted, so that it can be resumed once they are persisted.
 func (c *Chain) CreateOpenConnections(
 	ctx context.Context,
 	dst *Chain,
-	maxRetries uint64,
-	timeout time.Duration,
+	retryPolicy *RetryPolicy,
+	override bool,
 	memo string,
 	initialBlockHistory uint64,
 	pathName string,
@@ -26,33 +28,153 @@ func (c *Chain) CreateOpenConnections(
 		return "", "", err
 	}
 
-	// Timeout is per message. Four connection handshake messages, allowing maxRetries for each.
-	processorTimeout := timeout * 4 * time.Duration(maxRetries)
+	var srcConns, dstConns []*conntypes.IdentifiedConnection
+	if !override {
+		var err error
+		if srcConns, err = queryHandshakeConnections(ctx, c, dst); err != nil {
+			return "", "", err
+		}
+		if dstConns, err = queryHandshakeConnections(ctx, dst, c); err != nil {
+			return "", "", err
+		}
+	}
+
+	step, end, onSrc := connectionHandshakeStep(srcConns, dstConns, c.PathEnd.ConnectionID, dst.PathEnd.ConnectionID, override)
+
+	if step == handshakeStepOpen {
+		c.log.Info("Connection already open",
+			zap.String("src_chain_id", c.PathEnd.ChainID),
+			zap.String("src_connection_id", end.ID),
+			zap.String("dst_chain_id", dst.PathEnd.ChainID),
+			zap.String("dst_connection_id", end.CounterpartyID),
+		)
+		c.PathEnd.ConnectionID = end.ID
+		dst.PathEnd.ConnectionID = end.CounterpartyID
+		return end.ID, end.CounterpartyID, nil
+	}
+
+	// the chain of the end which the next step follows from, and its counterparty.
+	from, to := c, dst
+	if !onSrc {
+		from, to = dst, c
+	}
+
+	initial := &processor.ConnectionMessage{
+		ChainID:   c.PathEnd.ChainID,
+		EventType: conntypes.EventTypeConnectionOpenInit,
+		Info: provider.ConnectionInfo{
+			ClientID:                     c.PathEnd.ClientID,
+			CounterpartyClientID:         dst.PathEnd.ClientID,
+			CounterpartyCommitmentPrefix: dst.ChainProvider.CommitmentPrefix(),
+		},
+	}
+	// the chain on which the handshake completes with MsgConnectionOpenConfirm.
+	confirm, confirmCounterparty := dst, c
+
+	if step != handshakeStepInit {
+		c.log.Info("Resuming connection handshake",
+			zap.String("step", step.String()),
+			zap.String("chain_id", from.PathEnd.ChainID),
+			zap.String("connection_id", end.ID),
+			zap.String("counterparty_chain_id", to.PathEnd.ChainID),
+			zap.String("counterparty_connection_id", end.CounterpartyID),
+		)
+
+		// the next step is relayed as if the event for the previous step had just been observed for the end.
+		initial = &processor.ConnectionMessage{
+			ChainID: from.PathEnd.ChainID,
+			Info: provider.ConnectionInfo{
+				ConnID:               end.ID,
+				ClientID:             from.PathEnd.ClientID,
+				CounterpartyClientID: to.PathEnd.ClientID,
+				CounterpartyConnID:   end.CounterpartyID,
+			},
+		}
+
+		switch step {
+		case handshakeStepTry:
+			initial.EventType = conntypes.EventTypeConnectionOpenTry
+			confirm, confirmCounterparty = to, from
+		case handshakeStepAck:
+			initial.EventType = conntypes.EventTypeConnectionOpenAck
+			confirm, confirmCounterparty = from, to
+		case handshakeStepConfirm:
+			initial.EventType = conntypes.EventTypeConnectionOpenConfirm
+			confirm, confirmCounterparty = to, from
+		}
+	}
+
+	// Timeout is per message. Four connection handshake messages, allowing the handshake retries for each.
+	processorTimeout := retryPolicy.handshakeTimeout(4)
 
 	ctx, cancel := context.WithTimeout(ctx, processorTimeout)
 	defer cancel()
 
-	pp := processor.NewPathProcessor(
-		c.log,
-		processor.NewPathEnd(pathName, c.PathEnd.ChainID, c.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-		processor.NewPathEnd(pathName, dst.PathEnd.ChainID, dst.PathEnd.ClientID, "", []processor.ChainChannelKey{}),
-		nil,
-		memo,
-		DefaultClientUpdateThreshold,
-		DefaultFlushInterval,
-		DefaultMaxMsgLength,
-		0,
-		0,
-	)
+	pp := c.handshakePathProcessor(dst, pathName, memo, retryPolicy)
 
-	var connectionSrc, connectionDst string
+	// the connection ends of a resumed handshake are reserved from the start.
+	reserved := newHandshakeReservation()
+	if step != handshakeStepInit {
+		reserved.reserve(from.PathEnd.ChainID, end.ID)
+		if end.CounterpartyID != "" {
+			reserved.reserve(to.PathEnd.ChainID, end.CounterpartyID)
+		}
+	}
 
-	pp.OnConnectionMessage(dst.PathEnd.ChainID, conntypes.EventTypeConnectionOpenConfirm, func(ci provider.ConnectionInfo) {
-		dst.PathEnd.ConnectionID = ci.ConnID
-		c.PathEnd.ConnectionID = ci.CounterpartyConnID
-		connectionSrc = ci.CounterpartyConnID
-		connectionDst = ci.ConnID
-	})
+	// reserve the connection ends of the handshake as they are created. The counterparty of a new end is verified
+	// by query, since other relayers may create connections between the same clients at the same time.
+	verifier := newHandshakeVerifier(ctx)
+	defer verifier.stop()
+	for _, ends := range [][2]*Chain{{c, dst}, {dst, c}} {
+		chain, counterparty := ends[0], ends[1]
+		for _, eventType := range []string{conntypes.EventTypeConnectionOpenInit, conntypes.EventTypeConnectionOpenTry} {
+			eventType := eventType
+			pp.OnConnectionMessage(chain.PathEnd.ChainID, eventType, func(ci provider.ConnectionInfo) {
+				verifier.enqueue(func(ctx context.Context) {
+					var counterpartyConnID string
+					if eventType == conntypes.EventTypeConnectionOpenTry {
+						counterpartyConnID = reserved.id(counterparty.PathEnd.ChainID)
+						if counterpartyConnID == "" || ci.CounterpartyConnID != counterpartyConnID {
+							c.log.Info("Ignoring connection of another handshake",
+								zap.String("chain_id", chain.PathEnd.ChainID),
+								zap.String("connection_id", ci.ConnID),
+								zap.String("counterparty_connection_id", ci.CounterpartyConnID),
+							)
+							return
+						}
+					}
+					if err := verifyConnectionEnd(ctx, chain, counterparty, ci.ConnID, counterpartyConnID); err != nil {
+						c.log.Warn("Ignoring unverified connection", zap.Error(err))
+						return
+					}
+					if !reserved.reserve(chain.PathEnd.ChainID, ci.ConnID) {
+						c.log.Info("Ignoring connection of another handshake",
+							zap.String("chain_id", chain.PathEnd.ChainID),
+							zap.String("connection_id", ci.ConnID),
+							zap.String("reserved_connection_id", reserved.id(chain.PathEnd.ChainID)),
+						)
+					}
+				})
+			})
+		}
+	}
+
+	// the handshake terminates once the reserved connection ends are open, not on a connection which another
+	// handshake opens between the same clients.
+	var opened bool
+	terminationFilter := func(ci provider.ConnectionInfo) bool {
+		if ci.ConnID != reserved.id(confirm.PathEnd.ChainID) ||
+			ci.CounterpartyConnID != reserved.id(confirmCounterparty.PathEnd.ChainID) {
+			c.log.Info("Ignoring connection opened by another handshake",
+				zap.String("chain_id", confirm.PathEnd.ChainID),
+				zap.String("connection_id", ci.ConnID),
+				zap.String("reserved_connection_id", reserved.id(confirm.PathEnd.ChainID)),
+			)
+			return false
+		}
+		opened = true
+		return true
+	}
 
 	c.log.Info("Starting event processor for connection handshake",
 		zap.String("src_chain_id", c.PathEnd.ChainID),
@@ -61,33 +183,39 @@ func (c *Chain) CreateOpenConnections(
 		zap.String("dst_client_id", dst.PathEnd.ClientID),
 	)
 
-	return connectionSrc, connectionDst, processor.NewEventProcessor().
+	err := processor.NewEventProcessor().
 		WithChainProcessors(
 			c.chainProcessor(c.log, nil),
 			dst.chainProcessor(c.log, nil),
 		).
 		WithPathProcessors(pp).
+		WithProofHeightAudit(proofHeightAudit(c, dst)).
 		WithInitialBlockHistory(initialBlockHistory).
 		WithMessageLifecycle(&processor.ConnectionMessageLifecycle{
-			Initial: &processor.ConnectionMessage{
-				ChainID:   c.PathEnd.ChainID,
-				EventType: conntypes.EventTypeConnectionOpenInit,
-				Info: provider.ConnectionInfo{
-					ClientID:                     c.PathEnd.ClientID,
-					CounterpartyClientID:         dst.PathEnd.ClientID,
-					CounterpartyCommitmentPrefix: dst.ChainProvider.CommitmentPrefix(),
-				},
-			},
+			Initial: initial,
 			Termination: &processor.ConnectionMessage{
-				ChainID:   dst.PathEnd.ChainID,
+				ChainID:   confirm.PathEnd.ChainID,
 				EventType: conntypes.EventTypeConnectionOpenConfirm,
 				Info: provider.ConnectionInfo{
-					ClientID:                     dst.PathEnd.ClientID,
-					CounterpartyClientID:         c.PathEnd.ClientID,
-					CounterpartyCommitmentPrefix: c.ChainProvider.CommitmentPrefix(),
+					ClientID:                     confirm.PathEnd.ClientID,
+					CounterpartyClientID:         confirmCounterparty.PathEnd.ClientID,
+					CounterpartyCommitmentPrefix: confirmCounterparty.ChainProvider.CommitmentPrefix(),
 				},
 			},
+			TerminationFilter: terminationFilter,
 		}).
 		Build().
 		Run(ctx)
+
+	// record the reserved connection ends on the path ends, also those of an interrupted handshake.
+	verifier.stop()
+	for _, chain := range []*Chain{c, dst} {
+		if id := reserved.id(chain.PathEnd.ChainID); id != "" {
+			chain.PathEnd.ConnectionID = id
+		}
+	}
+	if !opened {
+		return "", "", err
+	}
+	return c.PathEnd.ConnectionID, dst.PathEnd.ConnectionID, err
 }
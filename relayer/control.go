@@ -0,0 +1,373 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// ControlAPIPrefix is the URL path prefix under which the ControlAPI is served.
+const ControlAPIPrefix = "/relayer/control/"
+
+// defaultControlTxResults is how many tx results are returned by the control API unless a limit is given.
+const defaultControlTxResults = 10
+
+// ControlAPI is an HTTP API to inspect and intervene in the relaying of the paths of a running relayer,
+// used by rly debug shell. Its routes, relative to ControlAPIPrefix, are:
+//
+//	GET  paths                         names of the paths
+//	GET  paths/{path}/packets          packets yet to be relayed
+//	POST paths/{path}/update-clients   force an update of the clients of the path
+//	POST paths/{path}/retry            relay a packet again, given chain_id, channel_id, port_id and sequence
+//	GET  paths/{path}/quarantined      packets quarantined after failing on their own in bisected batches
+//	GET  paths/{path}/txs?limit=N      results of the most recent transactions
+//	GET  paths/{path}/stats            rolling SLA statistics of the path
+//	GET  paths/{path}/status           summary of the state of the path, used by rly tui
+//	POST packets/relay                 relay a packet as soon as possible on whichever path relays its channel,
+//	                                   given chain_id, channel_id, port_id (transfer by default) and sequence
+//	GET  events?path=NAME              stream of relay events as newline delimited JSON, of all paths by default
+//	GET  debug                         chains and paths whose debug logs are enabled
+//	POST paths/{path}/debug?enabled=B  enable or disable the debug logs of the path
+//	POST chains/{chain}/debug?enabled=B
+//	                                   enable or disable the debug logs of the chain, including tx payloads
+type ControlAPI struct {
+	mu           sync.RWMutex
+	paths        map[string]*processor.PathProcessor
+	chains       map[string]*Chain
+	relayEvents  *eventstream.Stream
+	debugLogging *DebugLogging
+}
+
+// NewControlAPI returns a ControlAPI to which the PathProcessors of the relayer are added once it is started.
+func NewControlAPI() *ControlAPI {
+	return &ControlAPI{
+		paths:  make(map[string]*processor.PathProcessor),
+		chains: make(map[string]*Chain),
+	}
+}
+
+func (c *ControlAPI) addPathProcessor(pp *processor.PathProcessor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths[pp.PathName()] = pp
+}
+
+func (c *ControlAPI) addChains(chains map[string]*Chain) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for chainID, chain := range chains {
+		c.chains[chainID] = chain
+	}
+}
+
+func (c *ControlAPI) setRelayEvents(relayEvents *eventstream.Stream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.relayEvents = relayEvents
+}
+
+// SetDebugLogging sets the DebugLogging of the root logger, through which the debug logs of chains and paths are
+// enabled at runtime.
+func (c *ControlAPI) SetDebugLogging(debugLogging *DebugLogging) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.debugLogging = debugLogging
+}
+
+func (c *ControlAPI) pathProcessor(name string) (*processor.PathProcessor, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	pp, ok := c.paths[name]
+	return pp, ok
+}
+
+// ServeHTTP implements http.Handler.
+func (c *ControlAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, ControlAPIPrefix), "/"), "/")
+	if len(parts) == 2 && parts[0] == "packets" && parts[1] == "relay" {
+		c.relayPacket(w, r)
+		return
+	}
+	if len(parts) == 1 && parts[0] == "events" {
+		c.streamRelayEvents(w, r)
+		return
+	}
+	if len(parts) == 1 && parts[0] == "debug" {
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		if debugLogging, ok := c.getDebugLogging(w); ok {
+			writeJSON(w, debugLogging.Targets())
+		}
+		return
+	}
+	if len(parts) == 3 && parts[0] == "chains" && parts[2] == "debug" {
+		c.mu.RLock()
+		_, ok := c.chains[parts[1]]
+		c.mu.RUnlock()
+		if !ok {
+			http.Error(w, fmt.Sprintf("chain %s is not being relayed", parts[1]), http.StatusNotFound)
+			return
+		}
+		c.setDebug(w, r, func(d *DebugLogging, enabled bool) { d.SetChain(parts[1], enabled) })
+		return
+	}
+	if parts[0] != "paths" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 1 {
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		c.mu.RLock()
+		names := make([]string, 0, len(c.paths))
+		for name := range c.paths {
+			names = append(names, name)
+		}
+		c.mu.RUnlock()
+		sort.Strings(names)
+		writeJSON(w, names)
+		return
+	}
+
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	pp, ok := c.pathProcessor(parts[1])
+	if !ok {
+		http.Error(w, fmt.Sprintf("path %s is not being relayed", parts[1]), http.StatusNotFound)
+		return
+	}
+
+	switch parts[2] {
+	case "packets":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		packets, err := pp.QueuedPackets(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, packets)
+	case "quarantined":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		writeJSON(w, pp.QuarantinedPackets())
+	case "update-clients":
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		if err := pp.ForceClientUpdates(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "retry":
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		q := r.URL.Query()
+		sequence, err := strconv.ParseUint(q.Get("sequence"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid sequence: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := pp.RetryPacket(r.Context(), q.Get("chain_id"), q.Get("channel_id"), q.Get("port_id"), sequence); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "txs":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		limit := defaultControlTxResults
+		if l := r.URL.Query().Get("limit"); l != "" {
+			var err error
+			if limit, err = strconv.Atoi(l); err != nil {
+				http.Error(w, fmt.Sprintf("invalid limit: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		writeJSON(w, pp.TxResults(limit))
+	case "stats":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		writeJSON(w, pp.SLAStats())
+	case "status":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		status, err := pp.Status(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		for i, end := range status.Ends {
+			status.Ends[i].Balance = c.balance(r.Context(), end.ChainID)
+		}
+		writeJSON(w, status)
+	case "debug":
+		c.setDebug(w, r, func(d *DebugLogging, enabled bool) { d.SetPath(parts[1], enabled) })
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// relayPacket makes the PathProcessor which relays the channel of the packet relay it as soon as possible,
+// so that external services, e.g. dApp backends, can trigger urgent relays through a shared relayer.
+func (c *ControlAPI) relayPacket(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	q := r.URL.Query()
+	chainID, channelID, portID := q.Get("chain_id"), q.Get("channel_id"), q.Get("port_id")
+	if portID == "" {
+		portID = "transfer"
+	}
+	sequence, err := strconv.ParseUint(q.Get("sequence"), 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid sequence: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.RLock()
+	names := make([]string, 0, len(c.paths))
+	for name := range c.paths {
+		names = append(names, name)
+	}
+	c.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		pp, ok := c.pathProcessor(name)
+		if !ok {
+			continue
+		}
+		relays, err := pp.RelaysChannel(r.Context(), chainID, channelID, portID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if !relays {
+			continue
+		}
+		if err := pp.RetryPacket(r.Context(), chainID, channelID, portID, sequence); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, map[string]string{"path_name": name})
+		return
+	}
+	http.Error(w, fmt.Sprintf("channel %s/%s on chain %s is not being relayed", portID, channelID, chainID), http.StatusNotFound)
+}
+
+// streamRelayEvents streams the relay events, optionally of a single path, until the client disconnects.
+func (c *ControlAPI) streamRelayEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	c.mu.RLock()
+	relayEvents := c.relayEvents
+	c.mu.RUnlock()
+	if relayEvents == nil {
+		http.Error(w, "relay events are not streamed", http.StatusServiceUnavailable)
+		return
+	}
+
+	var filter func(eventstream.Event) bool
+	if pathName := r.URL.Query().Get("path"); pathName != "" {
+		filter = func(e eventstream.Event) bool { return e.PathName == pathName }
+	}
+	flush := func() {}
+	if f, ok := w.(http.Flusher); ok {
+		flush = f.Flush
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flush()
+	_ = relayEvents.WriteTo(r.Context(), w, filter, flush)
+}
+
+// setDebug enables or disables debug logs with set, as given by the enabled query parameter.
+func (c *ControlAPI) setDebug(w http.ResponseWriter, r *http.Request, set func(d *DebugLogging, enabled bool)) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid enabled: %v", err), http.StatusBadRequest)
+		return
+	}
+	debugLogging, ok := c.getDebugLogging(w)
+	if !ok {
+		return
+	}
+	set(debugLogging, enabled)
+	writeJSON(w, debugLogging.Targets())
+}
+
+// getDebugLogging returns the DebugLogging of the root logger, or writes an error if debug logs cannot be enabled
+// at runtime.
+func (c *ControlAPI) getDebugLogging(w http.ResponseWriter) (*DebugLogging, bool) {
+	c.mu.RLock()
+	debugLogging := c.debugLogging
+	c.mu.RUnlock()
+	if debugLogging == nil {
+		http.Error(w, "debug logs cannot be enabled at runtime", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return debugLogging, true
+}
+
+// balance returns the balance of the relayer wallet on the chain, or an empty string if it is not known,
+// e.g. when relaying without keys.
+func (c *ControlAPI) balance(ctx context.Context, chainID string) string {
+	c.mu.RLock()
+	chain, ok := c.chains[chainID]
+	c.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	cp := chain.ChainProvider
+	if !cp.KeyExists(cp.Key()) {
+		return ""
+	}
+	coins, err := cp.QueryBalance(ctx, cp.Key())
+	if err != nil {
+		return ""
+	}
+	return coins.String()
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
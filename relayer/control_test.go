@@ -0,0 +1,65 @@
+package relayer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlAPIRoutes(t *testing.T) {
+	control := NewControlAPI()
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, ControlAPIPrefix + "paths", http.StatusOK},
+		{http.MethodPost, ControlAPIPrefix + "paths", http.StatusMethodNotAllowed},
+		{http.MethodGet, ControlAPIPrefix + "paths/demo/packets", http.StatusNotFound},
+		{http.MethodGet, ControlAPIPrefix + "unknown", http.StatusNotFound},
+		{http.MethodGet, ControlAPIPrefix + "packets/relay", http.StatusMethodNotAllowed},
+		{http.MethodPost, ControlAPIPrefix + "packets/relay?chain_id=ibc-0&channel_id=channel-0", http.StatusBadRequest},
+		{http.MethodPost, ControlAPIPrefix + "packets/relay?chain_id=ibc-0&channel_id=channel-0&sequence=1", http.StatusNotFound},
+		{http.MethodGet, ControlAPIPrefix + "events", http.StatusServiceUnavailable},
+		{http.MethodPost, ControlAPIPrefix + "events", http.StatusMethodNotAllowed},
+		{http.MethodGet, ControlAPIPrefix + "debug", http.StatusServiceUnavailable},
+		{http.MethodPost, ControlAPIPrefix + "chains/ibc-0/debug?enabled=true", http.StatusNotFound},
+		{http.MethodPost, ControlAPIPrefix + "paths/demo/debug?enabled=true", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		control.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		require.Equal(t, tc.status, rec.Code, "%s %s", tc.method, tc.path)
+	}
+
+	rec := httptest.NewRecorder()
+	control.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ControlAPIPrefix+"paths", nil))
+	require.JSONEq(t, `[]`, rec.Body.String())
+}
+
+func TestControlAPIDebug(t *testing.T) {
+	control := NewControlAPI()
+	control.addChains(map[string]*Chain{"ibc-0": {}})
+	control.SetDebugLogging(NewDebugLogging())
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodPost, ControlAPIPrefix + "chains/ibc-0/debug?enabled=true", http.StatusOK, `{"chains":["ibc-0"],"paths":[]}`},
+		{http.MethodGet, ControlAPIPrefix + "chains/ibc-0/debug?enabled=true", http.StatusMethodNotAllowed, ""},
+		{http.MethodPost, ControlAPIPrefix + "chains/ibc-0/debug?enabled=yes", http.StatusBadRequest, ""},
+		{http.MethodPost, ControlAPIPrefix + "chains/ibc-1/debug?enabled=true", http.StatusNotFound, ""},
+		{http.MethodGet, ControlAPIPrefix + "debug", http.StatusOK, `{"chains":["ibc-0"],"paths":[]}`},
+		{http.MethodPost, ControlAPIPrefix + "chains/ibc-0/debug?enabled=false", http.StatusOK, `{"chains":[],"paths":[]}`},
+	} {
+		rec := httptest.NewRecorder()
+		control.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		require.Equal(t, tc.status, rec.Code, "%s %s", tc.method, tc.path)
+		if tc.body != "" {
+			require.JSONEq(t, tc.body, rec.Body.String(), "%s %s", tc.method, tc.path)
+		}
+	}
+}
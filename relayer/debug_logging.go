@@ -0,0 +1,137 @@
+package relayer
+
+import (
+	"sort"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugLogging enables debug logs of chains and paths at runtime, e.g. through the ControlAPI, so that issues can be
+// diagnosed without restarting the relayer and losing its state. Debug logs are attributed to a chain or path by their
+// chain_id and path_name fields, which include the payloads of the txs sent to the chain and the state of handshakes.
+type DebugLogging struct {
+	mu     sync.RWMutex
+	chains map[string]bool
+	paths  map[string]bool
+}
+
+// DebugTargets are the chains and paths whose debug logs are enabled.
+type DebugTargets struct {
+	Chains []string `json:"chains"`
+	Paths  []string `json:"paths"`
+}
+
+// NewDebugLogging returns a DebugLogging without debug logs enabled.
+func NewDebugLogging() *DebugLogging {
+	return &DebugLogging{chains: make(map[string]bool), paths: make(map[string]bool)}
+}
+
+// SetChain enables or disables the debug logs of the chain with chainID.
+func (d *DebugLogging) SetChain(chainID string, enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	setDebugTarget(d.chains, chainID, enabled)
+}
+
+// SetPath enables or disables the debug logs of the path with pathName.
+func (d *DebugLogging) SetPath(pathName string, enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	setDebugTarget(d.paths, pathName, enabled)
+}
+
+func setDebugTarget(targets map[string]bool, name string, enabled bool) {
+	if enabled {
+		targets[name] = true
+	} else {
+		delete(targets, name)
+	}
+}
+
+// Targets returns the chains and paths whose debug logs are enabled.
+func (d *DebugLogging) Targets() DebugTargets {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	targets := DebugTargets{Chains: make([]string, 0, len(d.chains)), Paths: make([]string, 0, len(d.paths))}
+	for chainID := range d.chains {
+		targets.Chains = append(targets.Chains, chainID)
+	}
+	for pathName := range d.paths {
+		targets.Paths = append(targets.Paths, pathName)
+	}
+	sort.Strings(targets.Chains)
+	sort.Strings(targets.Paths)
+	return targets
+}
+
+func (d *DebugLogging) any() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.chains) > 0 || len(d.paths) > 0
+}
+
+func (d *DebugLogging) enabled(chainID, pathName string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return (chainID != "" && d.chains[chainID]) || (pathName != "" && d.paths[pathName])
+}
+
+// Core wraps core, which must be enabled for debug logs, so that it only writes the logs enabled by level,
+// and the debug logs of the chains and paths whose debug logs are enabled.
+func (d *DebugLogging) Core(core zapcore.Core, level zapcore.LevelEnabler) zapcore.Core {
+	return &debugCore{Core: core, level: level, debug: d}
+}
+
+// debugCore is a zapcore.Core which writes the entries enabled by level, and the debug entries of the chains and
+// paths whose debug logs are enabled, which it attributes by the chain_id and path_name fields of the logger and
+// of the entries.
+type debugCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+	debug *DebugLogging
+
+	chainID, pathName string
+}
+
+func (c *debugCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) || c.debug.any()
+}
+
+func (c *debugCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	clone.chainID, clone.pathName = debugFields(fields, c.chainID, c.pathName)
+	return &clone
+}
+
+func (c *debugCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.level.Enabled(ent.Level) || c.debug.any() {
+		// debug entries of chains and paths which are only attributed by their own fields are filtered on write.
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *debugCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.level.Enabled(ent.Level) && !c.debug.enabled(debugFields(fields, c.chainID, c.pathName)) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// debugFields returns the chain_id and path_name of fields, or else chainID and pathName.
+func debugFields(fields []zapcore.Field, chainID, pathName string) (string, string) {
+	for _, f := range fields {
+		if f.Type != zapcore.StringType {
+			continue
+		}
+		switch f.Key {
+		case "chain_id":
+			chainID = f.String
+		case "path_name":
+			pathName = f.String
+		}
+	}
+	return chainID, pathName
+}
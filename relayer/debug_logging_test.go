@@ -0,0 +1,53 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDebugLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	debugLogging := NewDebugLogging()
+	log := zap.New(debugLogging.Core(core, zapcore.InfoLevel))
+
+	pathLog := log.With(zap.String("path_name", "demo-path"), zap.String("chain_id", "ibc-0"))
+	debug := func() {
+		log.Debug("chain debug", zap.String("chain_id", "ibc-1"))
+		pathLog.Debug("path debug")
+		log.Debug("other debug")
+		log.Info("info")
+	}
+	messages := func() []string {
+		var messages []string
+		for _, e := range logs.TakeAll() {
+			messages = append(messages, e.Message)
+		}
+		return messages
+	}
+
+	debug()
+	require.Equal(t, []string{"info"}, messages())
+
+	debugLogging.SetChain("ibc-1", true)
+	debug()
+	require.Equal(t, []string{"chain debug", "info"}, messages())
+
+	debugLogging.SetPath("demo-path", true)
+	debug()
+	require.Equal(t, []string{"chain debug", "path debug", "info"}, messages())
+	require.Equal(t, DebugTargets{Chains: []string{"ibc-1"}, Paths: []string{"demo-path"}}, debugLogging.Targets())
+
+	debugLogging.SetChain("ibc-1", false)
+	debugLogging.SetPath("demo-path", false)
+	debug()
+	require.Equal(t, []string{"info"}, messages())
+
+	// the chain_id of the logger attributes its debug logs to the chain.
+	debugLogging.SetChain("ibc-0", true)
+	debug()
+	require.Equal(t, []string{"path debug", "info"}, messages())
+}
@@ -0,0 +1,131 @@
+// Package dedup stores the packet messages relayed by the relayer in a local database, so that they are not
+// relayed again after a restart, while the events which complete them have not been observed yet.
+package dedup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/cosmos/relayer/v2/relayer/localdb"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// DefaultDBFile is the name of the database of relayed packet messages within the relayer home directory.
+const DefaultDBFile = "dedup.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS processed_packets (
+	path_name               TEXT    NOT NULL,
+	chain_id                TEXT    NOT NULL,
+	event_type              TEXT    NOT NULL,
+	channel_id              TEXT    NOT NULL,
+	port_id                 TEXT    NOT NULL,
+	counterparty_channel_id TEXT    NOT NULL,
+	counterparty_port_id    TEXT    NOT NULL,
+	sequence                INTEGER NOT NULL,
+	height                  INTEGER NOT NULL,
+	PRIMARY KEY (path_name, chain_id, event_type, channel_id, port_id, counterparty_channel_id, counterparty_port_id, sequence)
+);
+CREATE INDEX IF NOT EXISTS processed_packets_height ON processed_packets (path_name, chain_id, height);
+`
+
+// Store is a processor.ProcessedEventStore backed by a local SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+var _ processor.ProcessedEventStore = (*Store)(nil)
+
+// OpenStore opens the SQLite database at path, creating it and its schema if necessary.
+func OpenStore(path string) (*Store, error) {
+	db, err := localdb.Open(path, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dedup database %s: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// MarkProcessed stores packets relayed to the chain on behalf of the path, replacing the heights of packets
+// which were already stored.
+func (s *Store) MarkProcessed(ctx context.Context, pathName, chainID string, packets []processor.ProcessedPacket) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to store processed packets of path %s on chain %s: %w", pathName, chainID, err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range packets {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO processed_packets (path_name, chain_id, event_type, channel_id, port_id,
+			counterparty_channel_id, counterparty_port_id, sequence, height) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			pathName,
+			chainID,
+			p.EventType,
+			p.Channel.ChannelID,
+			p.Channel.PortID,
+			p.Channel.CounterpartyChannelID,
+			p.Channel.CounterpartyPortID,
+			p.Sequence,
+			p.Height,
+		); err != nil {
+			return fmt.Errorf("failed to store processed %s packet %d of path %s on chain %s: %w",
+				p.EventType, p.Sequence, pathName, chainID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store processed packets of path %s on chain %s: %w", pathName, chainID, err)
+	}
+	return nil
+}
+
+// ProcessedPackets returns the packets relayed to the chain on behalf of the path which have not been pruned.
+func (s *Store) ProcessedPackets(ctx context.Context, pathName, chainID string) ([]processor.ProcessedPacket, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT event_type, channel_id, port_id, counterparty_channel_id, counterparty_port_id, sequence, height
+		FROM processed_packets WHERE path_name = ? AND chain_id = ?`,
+		pathName, chainID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processed packets of path %s on chain %s: %w", pathName, chainID, err)
+	}
+	defer rows.Close()
+
+	var packets []processor.ProcessedPacket
+	for rows.Next() {
+		var p processor.ProcessedPacket
+		if err := rows.Scan(
+			&p.EventType,
+			&p.Channel.ChannelID,
+			&p.Channel.PortID,
+			&p.Channel.CounterpartyChannelID,
+			&p.Channel.CounterpartyPortID,
+			&p.Sequence,
+			&p.Height,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan processed packet of path %s on chain %s: %w", pathName, chainID, err)
+		}
+		packets = append(packets, p)
+	}
+
+	return packets, rows.Err()
+}
+
+// Prune deletes the packets relayed to the chain on behalf of the path below height.
+func (s *Store) Prune(ctx context.Context, pathName, chainID string, height uint64) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM processed_packets WHERE path_name = ? AND chain_id = ? AND height < ?`,
+		pathName, chainID, height,
+	); err != nil {
+		return fmt.Errorf("failed to prune processed packets of path %s on chain %s below height %d: %w",
+			pathName, chainID, height, err)
+	}
+	return nil
+}
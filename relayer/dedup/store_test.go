@@ -0,0 +1,63 @@
+package dedup_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/dedup"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), dedup.DefaultDBFile)
+
+	s, err := dedup.OpenStore(dbPath)
+	require.NoError(t, err)
+
+	channel := processor.ChannelKey{
+		ChannelID:             "channel-5",
+		PortID:                "transfer",
+		CounterpartyChannelID: "channel-0",
+		CounterpartyPortID:    "transfer",
+	}
+	packet := func(seq, height uint64) processor.ProcessedPacket {
+		return processor.ProcessedPacket{
+			EventType: chantypes.EventTypeRecvPacket,
+			Channel:   channel,
+			Sequence:  seq,
+			Height:    height,
+		}
+	}
+
+	require.NoError(t, s.MarkProcessed(ctx, "demo-path", "chain-b", []processor.ProcessedPacket{packet(1, 100), packet(2, 150)}))
+	require.NoError(t, s.MarkProcessed(ctx, "other-path", "chain-b", []processor.ProcessedPacket{packet(1, 100)}))
+	require.NoError(t, s.Close())
+
+	// processed packets are kept across restarts.
+	s, err = dedup.OpenStore(dbPath)
+	require.NoError(t, err)
+	defer s.Close()
+
+	packets, err := s.ProcessedPackets(ctx, "demo-path", "chain-b")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []processor.ProcessedPacket{packet(1, 100), packet(2, 150)}, packets)
+
+	packets, err = s.ProcessedPackets(ctx, "demo-path", "chain-a")
+	require.NoError(t, err)
+	require.Empty(t, packets)
+
+	// pruning only deletes the packets of the path and chain below the height.
+	require.NoError(t, s.Prune(ctx, "demo-path", "chain-b", 150))
+
+	packets, err = s.ProcessedPackets(ctx, "demo-path", "chain-b")
+	require.NoError(t, err)
+	require.Equal(t, []processor.ProcessedPacket{packet(2, 150)}, packets)
+
+	packets, err = s.ProcessedPackets(ctx, "other-path", "chain-b")
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+}
@@ -0,0 +1,68 @@
+package relayer
+
+import (
+	"fmt"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// DenomPolicy restricts the ICS-20 transfers relayed on a path by the denom and amount of their tokens,
+// e.g. to refuse to relay transfers of a known exploited token. Denoms are either the denom of the packet data,
+// e.g. transfer/channel-0/uatom, its base denom, e.g. uatom, or its ibc/ hash as held on the sending chain.
+type DenomPolicy struct {
+	// Rule is "allowlist" to only relay transfers of Denoms, "denylist" to relay transfers of all denoms but Denoms,
+	// or empty to relay transfers of all denoms.
+	Rule   string   `yaml:"rule,omitempty" json:"rule,omitempty"`
+	Denoms []string `yaml:"denoms,omitempty" json:"denoms,omitempty"`
+
+	// MaxAmounts limits the amount of tokens of a single transfer by denom.
+	MaxAmounts map[string]string `yaml:"max-amounts,omitempty" json:"max-amounts,omitempty"`
+}
+
+// Validate checks that the rule and amounts of the DenomPolicy are valid.
+func (dp *DenomPolicy) Validate() error {
+	if dp == nil {
+		return nil
+	}
+	if dp.Rule != processor.RuleAllowList && dp.Rule != processor.RuleDenyList && dp.Rule != "" {
+		return fmt.Errorf(`denom-policy rule must be "%s", "%s" or empty, got %s`,
+			processor.RuleAllowList, processor.RuleDenyList, dp.Rule)
+	}
+	if dp.Rule == "" && len(dp.Denoms) > 0 {
+		return fmt.Errorf("denom-policy denoms require a rule")
+	}
+	if _, err := dp.maxAmounts(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dp *DenomPolicy) maxAmounts() (map[string]sdkmath.Int, error) {
+	if len(dp.MaxAmounts) == 0 {
+		return nil, nil
+	}
+	maxAmounts := make(map[string]sdkmath.Int, len(dp.MaxAmounts))
+	for denom, amount := range dp.MaxAmounts {
+		maxAmount, ok := sdkmath.NewIntFromString(amount)
+		if !ok || maxAmount.IsNegative() {
+			return nil, fmt.Errorf("invalid max amount %s of denom %s", amount, denom)
+		}
+		maxAmounts[denom] = maxAmount
+	}
+	return maxAmounts, nil
+}
+
+// ProcessorDenomPolicy returns the processor.DenomPolicy with the rule and limits of the DenomPolicy.
+// The DenomPolicy must be valid.
+func (dp *DenomPolicy) ProcessorDenomPolicy() processor.DenomPolicy {
+	if dp == nil {
+		return processor.DenomPolicy{}
+	}
+	maxAmounts, _ := dp.maxAmounts()
+	return processor.DenomPolicy{
+		Rule:       dp.Rule,
+		Denoms:     dp.Denoms,
+		MaxAmounts: maxAmounts,
+	}
+}
@@ -0,0 +1,32 @@
+package relayer
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenomPolicy(t *testing.T) {
+	var unset *DenomPolicy
+	require.NoError(t, unset.Validate())
+	require.Equal(t, processor.DenomPolicy{}, unset.ProcessorDenomPolicy())
+
+	require.Error(t, (&DenomPolicy{Rule: "blocklist", Denoms: []string{"uatom"}}).Validate())
+	require.Error(t, (&DenomPolicy{Denoms: []string{"uatom"}}).Validate())
+	require.Error(t, (&DenomPolicy{MaxAmounts: map[string]string{"uatom": "1.5"}}).Validate())
+	require.Error(t, (&DenomPolicy{MaxAmounts: map[string]string{"uatom": "-1"}}).Validate())
+
+	dp := &DenomPolicy{
+		Rule:       processor.RuleDenyList,
+		Denoms:     []string{"uexploit"},
+		MaxAmounts: map[string]string{"uatom": "1000000000"},
+	}
+	require.NoError(t, dp.Validate())
+	require.Equal(t, processor.DenomPolicy{
+		Rule:       processor.RuleDenyList,
+		Denoms:     []string{"uexploit"},
+		MaxAmounts: map[string]sdkmath.Int{"uatom": sdkmath.NewInt(1000000000)},
+	}, dp.ProcessorDenomPolicy())
+}
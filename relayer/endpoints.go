@@ -0,0 +1,127 @@
+package relayer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultEndpointRefreshInterval is how often WatchEndpoints checks the RPC endpoints of the chains.
+	DefaultEndpointRefreshInterval = 5 * time.Minute
+
+	// endpointHealthTimeout is how long the RPC endpoint in use is given to report the latest height.
+	endpointHealthTimeout = 10 * time.Second
+)
+
+// EndpointResolver resolves the RPC endpoints of a chain at runtime,
+// e.g. from a service discovery system or the chain registry.
+type EndpointResolver interface {
+	ResolveRPCEndpoints(ctx context.Context, chainName, chainID string) ([]string, error)
+}
+
+// WatchEndpoints checks the RPC endpoint in use by each of the chains every interval until ctx is done.
+// When the endpoint of a chain is unhealthy, the chain is switched to the first endpoint resolved by resolver
+// which serves it, so that long-running relayers survive endpoint churn without config edits.
+// Chains whose provider cannot switch endpoints are left as is.
+func WatchEndpoints(ctx context.Context, log *zap.Logger, chains map[string]*Chain, resolver EndpointResolver, interval time.Duration) {
+	var wg sync.WaitGroup
+	for _, c := range chains {
+		switcher, ok := c.ChainProvider.(provider.RPCEndpointSwitcher)
+		if !ok {
+			log.Debug(
+				"Chain provider does not support switching RPC endpoints",
+				zap.String("chain_id", c.ChainID()),
+			)
+			continue
+		}
+
+		c := c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					refreshEndpoint(
+						ctx,
+						log.With(zap.String("chain_name", c.ChainProvider.ChainName()), zap.String("chain_id", c.ChainID())),
+						c.ChainProvider.ChainName(), c.ChainID(),
+						switcher,
+						func(ctx context.Context) error {
+							_, err := c.ChainProvider.QueryLatestHeight(ctx)
+							return err
+						},
+						resolver,
+					)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// refreshEndpoint switches to a resolved endpoint if the endpoint in use fails the health check.
+func refreshEndpoint(
+	ctx context.Context,
+	log *zap.Logger,
+	chainName, chainID string,
+	switcher provider.RPCEndpointSwitcher,
+	healthCheck func(ctx context.Context) error,
+	resolver EndpointResolver,
+) {
+	current := switcher.RPCAddr()
+
+	healthCtx, cancel := context.WithTimeout(ctx, endpointHealthTimeout)
+	err := healthCheck(healthCtx)
+	cancel()
+	if err == nil {
+		return
+	}
+
+	log.Warn(
+		"RPC endpoint is unhealthy, resolving endpoints",
+		zap.String("rpc_addr", current),
+		zap.Error(err),
+	)
+
+	endpoints, err := resolver.ResolveRPCEndpoints(ctx, chainName, chainID)
+	if err != nil {
+		log.Warn("Failed to resolve RPC endpoints", zap.Error(err))
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint == current {
+			continue
+		}
+		if err := switcher.SwitchRPCAddr(ctx, endpoint); err != nil {
+			log.Debug(
+				"Skipping RPC endpoint",
+				zap.String("rpc_addr", endpoint),
+				zap.Error(err),
+			)
+			continue
+		}
+		log.Info(
+			"Switched RPC endpoint",
+			zap.String("previous_rpc_addr", current),
+			zap.String("rpc_addr", endpoint),
+		)
+		return
+	}
+
+	log.Warn(
+		"No resolved RPC endpoint serves the chain, keeping the endpoint in use",
+		zap.String("rpc_addr", current),
+		zap.Int("resolved", len(endpoints)),
+	)
+}
@@ -0,0 +1,55 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockEndpointSwitcher struct {
+	rpcAddr string
+	serving map[string]bool
+}
+
+func (s *mockEndpointSwitcher) RPCAddr() string {
+	return s.rpcAddr
+}
+
+func (s *mockEndpointSwitcher) SwitchRPCAddr(_ context.Context, rpcAddr string) error {
+	if !s.serving[rpcAddr] {
+		return errors.New("not serving")
+	}
+	s.rpcAddr = rpcAddr
+	return nil
+}
+
+type mockEndpointResolver []string
+
+func (r mockEndpointResolver) ResolveRPCEndpoints(_ context.Context, _, _ string) ([]string, error) {
+	return r, nil
+}
+
+func TestRefreshEndpoint(t *testing.T) {
+	ctx := context.Background()
+	log := zap.NewNop()
+	healthy := func(context.Context) error { return nil }
+	unhealthy := func(context.Context) error { return errors.New("unreachable") }
+	resolver := mockEndpointResolver{"http://a:26657", "http://b:26657", "http://c:26657"}
+
+	// a healthy endpoint is kept, even if it is not resolved.
+	s := &mockEndpointSwitcher{rpcAddr: "http://private:26657", serving: map[string]bool{"http://b:26657": true}}
+	refreshEndpoint(ctx, log, "chain", "chain-1", s, healthy, resolver)
+	require.Equal(t, "http://private:26657", s.rpcAddr)
+
+	// an unhealthy endpoint is replaced by the first resolved endpoint which serves the chain.
+	refreshEndpoint(ctx, log, "chain", "chain-1", s, unhealthy, resolver)
+	require.Equal(t, "http://b:26657", s.rpcAddr)
+
+	// the endpoint in use is kept if no resolved endpoint serves the chain.
+	s.serving = nil
+	refreshEndpoint(ctx, log, "chain", "chain-1", s, unhealthy, resolver)
+	require.Equal(t, "http://b:26657", s.rpcAddr)
+}
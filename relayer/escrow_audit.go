@@ -0,0 +1,208 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	sdkmath "cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+)
+
+// EscrowAuditStatus is the outcome of comparing the tokens escrowed for a channel with the supply of their vouchers.
+type EscrowAuditStatus string
+
+const (
+	// EscrowAuditMatch means the supply of vouchers equals the escrowed amount.
+	EscrowAuditMatch EscrowAuditStatus = "match"
+
+	// EscrowAuditExcessEscrow means more is escrowed than the supply of vouchers, which is expected while transfers
+	// are in flight, but otherwise means tokens are locked in escrow, e.g. by a bug in a refund.
+	EscrowAuditExcessEscrow EscrowAuditStatus = "excess-escrow"
+
+	// EscrowAuditUnbackedSupply means the supply of vouchers exceeds the escrowed amount, i.e. vouchers were minted
+	// without tokens escrowed for them, which should never happen and indicates a bug or an exploit.
+	EscrowAuditUnbackedSupply EscrowAuditStatus = "unbacked-supply"
+)
+
+// EscrowAudit compares the amount of a denom escrowed for an ICS-20 channel with the total supply of its vouchers
+// on the counterparty chain.
+type EscrowAudit struct {
+	ChainID       string `json:"chain_id"`
+	PortID        string `json:"port_id"`
+	ChannelID     string `json:"channel_id"`
+	EscrowAddress string `json:"escrow_address"`
+
+	// Denom is the escrowed denom, and Trace its full denom trace.
+	Denom    string      `json:"denom"`
+	Trace    string      `json:"trace"`
+	Escrowed sdkmath.Int `json:"escrowed"`
+
+	CounterpartyChainID string      `json:"counterparty_chain_id"`
+	Voucher             string      `json:"voucher"`
+	VoucherSupply       sdkmath.Int `json:"voucher_supply"`
+
+	Status EscrowAuditStatus `json:"status"`
+}
+
+// AuditEscrows audits the escrows of the open ICS-20 channels on the connection of src, or only of the channel
+// channelID of src if set, in both directions: the tokens escrowed on src against the vouchers on dst, and the
+// tokens escrowed on dst against the vouchers on src.
+func AuditEscrows(ctx context.Context, src, dst *Chain, channelID string) ([]EscrowAudit, error) {
+	var channels []*chantypes.IdentifiedChannel
+	if channelID != "" {
+		channel, err := QueryChannel(ctx, src, channelID)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	} else {
+		all, err := queryChannelsOnConnection(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		for _, channel := range all {
+			if channel.State == chantypes.OPEN &&
+				channel.PortId == transfertypes.PortID && channel.Counterparty.PortId == transfertypes.PortID {
+				channels = append(channels, channel)
+			}
+		}
+	}
+
+	var audits []EscrowAudit
+	for _, channel := range channels {
+		srcAudits, err := AuditEscrow(ctx, src, dst, channel.PortId, channel.ChannelId,
+			channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+		if err != nil {
+			return nil, err
+		}
+		dstAudits, err := AuditEscrow(ctx, dst, src, channel.Counterparty.PortId, channel.Counterparty.ChannelId,
+			channel.PortId, channel.ChannelId)
+		if err != nil {
+			return nil, err
+		}
+		audits = append(audits, srcAudits...)
+		audits = append(audits, dstAudits...)
+	}
+	return audits, nil
+}
+
+// AuditEscrow compares, for each denom escrowed for the ICS-20 channel of src, and each denom of src whose vouchers
+// exist on dst, the balance of the escrow account of the channel with the total supply of the vouchers on dst.
+func AuditEscrow(
+	ctx context.Context,
+	src, dst *Chain,
+	srcPortID, srcChannelID, dstPortID, dstChannelID string,
+) ([]EscrowAudit, error) {
+	srcProvider, ok := src.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, fmt.Errorf("escrow audit is not supported on chain {%s} of type %s", src.ChainID(), src.ChainProvider.Type())
+	}
+	dstProvider, ok := dst.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, fmt.Errorf("escrow audit is not supported on chain {%s} of type %s", dst.ChainID(), dst.ChainProvider.Type())
+	}
+
+	escrowAddress, err := srcProvider.EscrowAddress(srcPortID, srcChannelID)
+	if err != nil {
+		return nil, err
+	}
+	escrowed, err := srcProvider.QueryBalanceWithAddress(ctx, escrowAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query escrow balance on chain {%s}: %w", src.ChainID(), err)
+	}
+
+	// the denom traces on src of the denoms escrowed or with vouchers on dst, by denom.
+	traces := make(map[string]transfertypes.DenomTrace)
+	amounts := make(map[string]sdkmath.Int)
+	for _, coin := range escrowed {
+		trace := transfertypes.DenomTrace{BaseDenom: coin.Denom}
+		if strings.HasPrefix(coin.Denom, transfertypes.DenomPrefix+"/") {
+			t, err := srcProvider.QueryDenomTrace(ctx, coin.Denom)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query denom trace of %s on chain {%s}: %w", coin.Denom, src.ChainID(), err)
+			}
+			trace = *t
+		}
+		traces[coin.Denom] = trace
+		amounts[coin.Denom] = coin.Amount
+	}
+
+	// vouchers of denoms which are not escrowed at all are found from the denom traces of dst.
+	dstTraces, err := dstProvider.QueryDenomTraces(ctx, 0, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query denom traces on chain {%s}: %w", dst.ChainID(), err)
+	}
+	for _, voucher := range dstTraces {
+		trace, ok := escrowedDenomTrace(dstPortID, dstChannelID, voucher)
+		if !ok {
+			continue
+		}
+		denom := trace.IBCDenom()
+		if _, ok := traces[denom]; !ok {
+			traces[denom] = trace
+			amounts[denom] = sdkmath.ZeroInt()
+		}
+	}
+
+	audits := make([]EscrowAudit, 0, len(traces))
+	for denom, trace := range traces {
+		voucher := voucherDenomTrace(dstPortID, dstChannelID, trace).IBCDenom()
+		supply, err := dstProvider.QuerySupplyOf(ctx, voucher)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query supply of %s on chain {%s}: %w", voucher, dst.ChainID(), err)
+		}
+		audits = append(audits, EscrowAudit{
+			ChainID:             src.ChainID(),
+			PortID:              srcPortID,
+			ChannelID:           srcChannelID,
+			EscrowAddress:       escrowAddress,
+			Denom:               denom,
+			Trace:               trace.GetFullDenomPath(),
+			Escrowed:            amounts[denom],
+			CounterpartyChainID: dst.ChainID(),
+			Voucher:             voucher,
+			VoucherSupply:       supply.Amount,
+			Status:              escrowAuditStatus(amounts[denom], supply.Amount),
+		})
+	}
+
+	sort.Slice(audits, func(i, j int) bool { return audits[i].Denom < audits[j].Denom })
+	return audits, nil
+}
+
+// voucherDenomTrace returns the denom trace of the vouchers, received through the channel of port and channel,
+// of the tokens with denom trace trace on the counterparty chain.
+func voucherDenomTrace(portID, channelID string, trace transfertypes.DenomTrace) transfertypes.DenomTrace {
+	path := portID + "/" + channelID
+	if trace.Path != "" {
+		path += "/" + trace.Path
+	}
+	return transfertypes.DenomTrace{Path: path, BaseDenom: trace.BaseDenom}
+}
+
+// escrowedDenomTrace returns the denom trace, on the counterparty chain, of the tokens whose vouchers have denom
+// trace voucher, if they were received through the channel of port and channel.
+func escrowedDenomTrace(portID, channelID string, voucher transfertypes.DenomTrace) (transfertypes.DenomTrace, bool) {
+	prefix := portID + "/" + channelID
+	switch {
+	case voucher.Path == prefix:
+		return transfertypes.DenomTrace{BaseDenom: voucher.BaseDenom}, true
+	case strings.HasPrefix(voucher.Path, prefix+"/"):
+		return transfertypes.DenomTrace{Path: strings.TrimPrefix(voucher.Path, prefix+"/"), BaseDenom: voucher.BaseDenom}, true
+	}
+	return transfertypes.DenomTrace{}, false
+}
+
+func escrowAuditStatus(escrowed, supply sdkmath.Int) EscrowAuditStatus {
+	switch {
+	case supply.GT(escrowed):
+		return EscrowAuditUnbackedSupply
+	case escrowed.GT(supply):
+		return EscrowAuditExcessEscrow
+	}
+	return EscrowAuditMatch
+}
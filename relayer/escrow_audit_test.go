@@ -0,0 +1,40 @@
+package relayer
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscrowAuditDenomTraces(t *testing.T) {
+	native := transfertypes.DenomTrace{BaseDenom: "uatom"}
+	forwarded := transfertypes.DenomTrace{Path: "transfer/channel-141", BaseDenom: "uosmo"}
+
+	// tokens escrowed on one chain are received as vouchers through the counterparty channel.
+	voucher := voucherDenomTrace("transfer", "channel-0", native)
+	require.Equal(t, "transfer/channel-0/uatom", voucher.GetFullDenomPath())
+	trace, ok := escrowedDenomTrace("transfer", "channel-0", voucher)
+	require.True(t, ok)
+	require.Equal(t, native, trace)
+
+	voucher = voucherDenomTrace("transfer", "channel-0", forwarded)
+	require.Equal(t, "transfer/channel-0/transfer/channel-141/uosmo", voucher.GetFullDenomPath())
+	trace, ok = escrowedDenomTrace("transfer", "channel-0", voucher)
+	require.True(t, ok)
+	require.Equal(t, forwarded, trace)
+	require.Equal(t, forwarded.IBCDenom(), trace.IBCDenom())
+
+	// vouchers received through other channels are not backed by the escrow of the channel.
+	_, ok = escrowedDenomTrace("transfer", "channel-1", voucher)
+	require.False(t, ok)
+	_, ok = escrowedDenomTrace("transfer", "channel-0", transfertypes.DenomTrace{Path: "transfer/channel-01", BaseDenom: "uatom"})
+	require.False(t, ok)
+}
+
+func TestEscrowAuditStatus(t *testing.T) {
+	require.Equal(t, EscrowAuditMatch, escrowAuditStatus(sdkmath.NewInt(100), sdkmath.NewInt(100)))
+	require.Equal(t, EscrowAuditExcessEscrow, escrowAuditStatus(sdkmath.NewInt(100), sdkmath.NewInt(90)))
+	require.Equal(t, EscrowAuditUnbackedSupply, escrowAuditStatus(sdkmath.ZeroInt(), sdkmath.NewInt(1)))
+}
@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"fmt"
+	"slices"
 
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
@@ -12,44 +13,49 @@ import (
 // ParseClientIDFromEvents parses events emitted from a MsgCreateClient and returns the
 // client identifier.
 func ParseClientIDFromEvents(events []provider.RelayerEvent) (string, error) {
-	for _, event := range events {
-		if event.EventType == clienttypes.EventTypeCreateClient {
-			for attributeKey, attributeValue := range event.Attributes {
-				if attributeKey == clienttypes.AttributeKeyClientID {
-					return attributeValue, nil
-				}
-			}
-		}
-	}
-	return "", fmt.Errorf("client identifier event attribute not found")
+	return parseIdentifierFromEvents(events, "client", clienttypes.AttributeKeyClientID,
+		clienttypes.EventTypeCreateClient)
 }
 
 // ParseConnectionIDFromEvents parses events emitted from a MsgConnectionOpenInit or
 // MsgConnectionOpenTry and returns the connection identifier.
 func ParseConnectionIDFromEvents(events []provider.RelayerEvent) (string, error) {
-	for _, event := range events {
-		if event.EventType == connectiontypes.EventTypeConnectionOpenInit || event.EventType == connectiontypes.EventTypeConnectionOpenTry {
-			for attributeKey, attributeValue := range event.Attributes {
-				if attributeKey == connectiontypes.AttributeKeyConnectionID {
-					return attributeValue, nil
-				}
-			}
-		}
-	}
-	return "", fmt.Errorf("connection identifier event attribute not found")
+	return parseIdentifierFromEvents(events, "connection", connectiontypes.AttributeKeyConnectionID,
+		connectiontypes.EventTypeConnectionOpenInit, connectiontypes.EventTypeConnectionOpenTry)
 }
 
 // ParseChannelIDFromEvents parses events emitted from a MsgChannelOpenInit or
 // MsgChannelOpenTry and returns the channel identifier.
 func ParseChannelIDFromEvents(events []provider.RelayerEvent) (string, error) {
+	return parseIdentifierFromEvents(events, "channel", channeltypes.AttributeKeyChannelID,
+		channeltypes.EventTypeChannelOpenInit, channeltypes.EventTypeChannelOpenTry)
+}
+
+// parseIdentifierFromEvents returns the identifier in the attributeKey attribute of the events of eventTypes.
+// The events are those of a whole tx, whose messages may come in any order, e.g. with or without a MsgUpdateClient
+// ahead of the message creating the identifier, so all events are scanned rather than those of the message at
+// a given index. It fails rather than picking one if the events hold different identifiers.
+func parseIdentifierFromEvents(
+	events []provider.RelayerEvent,
+	kind, attributeKey string,
+	eventTypes ...string,
+) (string, error) {
+	var id string
 	for _, event := range events {
-		if event.EventType == channeltypes.EventTypeChannelOpenInit || event.EventType == channeltypes.EventTypeChannelOpenTry {
-			for attributeKey, attributeValue := range event.Attributes {
-				if attributeKey == channeltypes.AttributeKeyChannelID {
-					return attributeValue, nil
-				}
-			}
+		if !slices.Contains(eventTypes, event.EventType) {
+			continue
+		}
+		v, ok := event.Attributes[attributeKey]
+		if !ok || v == "" {
+			continue
 		}
+		if id != "" && id != v {
+			return "", fmt.Errorf("%s identifier event attribute is ambiguous, found %s and %s", kind, id, v)
+		}
+		id = v
+	}
+	if id == "" {
+		return "", fmt.Errorf("%s identifier event attribute not found", kind)
 	}
-	return "", fmt.Errorf("channel identifier event attribute not found")
+	return id, nil
 }
@@ -0,0 +1,50 @@
+package relayer
+
+import (
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConnectionIDFromEvents(t *testing.T) {
+	updateClient := provider.RelayerEvent{
+		EventType:  clienttypes.EventTypeUpdateClient,
+		Attributes: map[string]string{clienttypes.AttributeKeyClientID: "07-tendermint-0"},
+	}
+	openTry := provider.RelayerEvent{
+		EventType: connectiontypes.EventTypeConnectionOpenTry,
+		Attributes: map[string]string{
+			connectiontypes.AttributeKeyConnectionID: "connection-3",
+			connectiontypes.AttributeKeyClientID:     "07-tendermint-0",
+		},
+	}
+
+	// the handshake message may come with or without a client update, in any order.
+	for _, events := range [][]provider.RelayerEvent{
+		{updateClient, openTry},
+		{openTry, updateClient},
+		{openTry},
+		{openTry, openTry},
+	} {
+		connectionID, err := ParseConnectionIDFromEvents(events)
+		require.NoError(t, err)
+		require.Equal(t, "connection-3", connectionID)
+	}
+
+	_, err := ParseConnectionIDFromEvents([]provider.RelayerEvent{updateClient})
+	require.EqualError(t, err, "connection identifier event attribute not found")
+
+	other := provider.RelayerEvent{
+		EventType:  connectiontypes.EventTypeConnectionOpenInit,
+		Attributes: map[string]string{connectiontypes.AttributeKeyConnectionID: "connection-4"},
+	}
+	_, err = ParseConnectionIDFromEvents([]provider.RelayerEvent{openTry, other})
+	require.EqualError(t, err, "connection identifier event attribute is ambiguous, found connection-3 and connection-4")
+
+	// the client identifier of the update is not mistaken for the one of a created client.
+	_, err = ParseClientIDFromEvents([]provider.RelayerEvent{updateClient, openTry})
+	require.EqualError(t, err, "client identifier event attribute not found")
+}
@@ -0,0 +1,206 @@
+// Package eventstream streams the lifecycle of the messages relayed by the relayer, from the observation of a packet
+// to the confirmation or failure of the transaction relaying it, as newline delimited JSON, so that external indexers
+// and incentive programs can consume the activity of the relayer directly.
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Type is a lifecycle transition of a relayed message.
+type Type string
+
+const (
+	// TypePacketObserved is a packet event observed in a block of the chain.
+	TypePacketObserved Type = "packet_observed"
+
+	// TypeMsgBuilt is a message assembled, with its proofs, for the chain.
+	TypeMsgBuilt Type = "msg_built"
+
+	// TypeBroadcast is a message broadcast to the mempool of the chain.
+	TypeBroadcast Type = "broadcast"
+
+	// TypeConfirmed is a message included in a block of the chain by a successful transaction.
+	TypeConfirmed Type = "confirmed"
+
+	// TypeFailed is a message whose broadcast or transaction failed.
+	TypeFailed Type = "failed"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before it is dropped.
+const subscriberBuffer = 1024
+
+// ErrSlowSubscriber is returned to a subscriber which lagged too far behind the published events,
+// which is dropped rather than silently missing events.
+var ErrSlowSubscriber = errors.New("subscriber dropped for lagging behind the relay events")
+
+// Event is a lifecycle transition of a message relayed on a path.
+// Height is the height of the chain at which the transition happened: the height of the block of an observed packet
+// or of a confirmed or failed transaction, otherwise the latest height of the chain.
+type Event struct {
+	Type     Type      `json:"type"`
+	Time     time.Time `json:"time"`
+	PathName string    `json:"path"`
+	ChainID  string    `json:"chain_id"`
+	Height   uint64    `json:"height"`
+
+	// MsgType is the type URL of the message, and EventType the IBC event type of the packet or handshake.
+	MsgType   string `json:"msg_type,omitempty"`
+	EventType string `json:"event_type,omitempty"`
+
+	Sequence   uint64 `json:"sequence,omitempty"`
+	SrcChannel string `json:"src_channel,omitempty"`
+	SrcPort    string `json:"src_port,omitempty"`
+	DstChannel string `json:"dst_channel,omitempty"`
+	DstPort    string `json:"dst_port,omitempty"`
+
+	ConnectionID             string `json:"connection_id,omitempty"`
+	CounterpartyConnectionID string `json:"counterparty_connection_id,omitempty"`
+
+	TxHash  string `json:"tx_hash,omitempty"`
+	Failure string `json:"failure,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type subscriber struct {
+	events chan Event
+	err    error
+}
+
+// Stream publishes the relay events to its subscribers.
+type Stream struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// New returns a Stream without subscribers.
+func New() *Stream {
+	return &Stream{subscribers: make(map[*subscriber]struct{})}
+}
+
+// Publish sends the event to the subscribers, stamping its time if unset. It is a no-op on a nil Stream.
+func (s *Stream) Publish(e Event) {
+	if s == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- e:
+		default:
+			sub.err = ErrSlowSubscriber
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (s *Stream) subscribe() *subscriber {
+	sub := &subscriber{events: make(chan Event, subscriberBuffer)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (s *Stream) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Subscribe returns a channel of the events published from now on, and a function which unsubscribes from them.
+// The channel is closed once unsubscribed, or once the subscriber lags too far behind the events.
+func (s *Stream) Subscribe() (<-chan Event, func()) {
+	sub := s.subscribe()
+	return sub.events, func() { s.unsubscribe(sub) }
+}
+
+// WriteTo writes the events published from now on which match filter, or all events if filter is nil, to w as
+// newline delimited JSON, calling flush, if not nil, after each event. It returns once ctx is done, writing to w
+// fails, or the writer lags too far behind the events.
+func (s *Stream) WriteTo(ctx context.Context, w io.Writer, filter func(Event) bool, flush func()) error {
+	sub := s.subscribe()
+	defer s.unsubscribe(sub)
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-sub.events:
+			if !ok {
+				s.mu.Lock()
+				err := sub.err
+				s.mu.Unlock()
+				return err
+			}
+			if filter != nil && !filter(e) {
+				continue
+			}
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+			if flush != nil {
+				flush()
+			}
+		}
+	}
+}
+
+// ListenUnix listens on a unix socket at socketPath, replacing a stale socket left by a previous run.
+func ListenUnix(socketPath string) (net.Listener, error) {
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Serve streams the events to every connection accepted by ln until ctx is done.
+func (s *Stream) Serve(ctx context.Context, log *zap.Logger, ln net.Listener) {
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("Failed to accept relay events connection", zap.Error(err))
+			}
+			return
+		}
+		go func() {
+			defer conn.Close()
+			if err := s.WriteTo(ctx, conn, nil, nil); err != nil && ctx.Err() == nil {
+				log.Debug("Relay events connection closed", zap.Error(err))
+			}
+		}()
+	}
+}
@@ -0,0 +1,114 @@
+package eventstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func (s *Stream) subscriberCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+func TestStreamServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a nil stream drops events.
+	var disabled *Stream
+	disabled.Publish(Event{Type: TypePacketObserved})
+
+	s := New()
+	socketPath := filepath.Join(t.TempDir(), "relay-events.sock")
+	ln, err := ListenUnix(socketPath)
+	require.NoError(t, err)
+	go s.Serve(ctx, zap.NewNop(), ln)
+
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return s.subscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	s.Publish(Event{
+		Type:       TypePacketObserved,
+		PathName:   "demo-path",
+		ChainID:    "chain-a",
+		Height:     100,
+		EventType:  "send_packet",
+		Sequence:   1,
+		SrcChannel: "channel-0",
+		SrcPort:    "transfer",
+	})
+	s.Publish(Event{
+		Type:     TypeConfirmed,
+		PathName: "demo-path",
+		ChainID:  "chain-b",
+		Height:   205,
+		MsgType:  "/ibc.core.channel.v1.MsgRecvPacket",
+		Sequence: 1,
+		TxHash:   "ABCD",
+	})
+
+	scanner := bufio.NewScanner(conn)
+	var events []Event
+	for len(events) < 2 && scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	require.Equal(t, TypePacketObserved, events[0].Type)
+	require.Equal(t, uint64(100), events[0].Height)
+	require.False(t, events[0].Time.IsZero())
+	require.Equal(t, TypeConfirmed, events[1].Type)
+	require.Equal(t, "ABCD", events[1].TxHash)
+
+	// the connection is closed, and the subscriber removed, once the stream stops.
+	cancel()
+	require.Eventually(t, func() bool { return s.subscriberCount() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamSlowSubscriber(t *testing.T) {
+	s := New()
+	sub := s.subscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		s.Publish(Event{Type: TypeBroadcast, Sequence: uint64(i)})
+	}
+	require.Equal(t, 0, s.subscriberCount())
+	require.ErrorIs(t, sub.err, ErrSlowSubscriber)
+
+	// the events published before it lagged behind are delivered.
+	n := 0
+	for range sub.events {
+		n++
+	}
+	require.Equal(t, subscriberBuffer, n)
+}
+
+func TestStreamSubscribe(t *testing.T) {
+	s := New()
+	events, unsubscribe := s.Subscribe()
+
+	s.Publish(Event{Type: TypeConfirmed, PathName: "demo-path", TxHash: "ABCD"})
+	e := <-events
+	require.Equal(t, TypeConfirmed, e.Type)
+	require.Equal(t, "ABCD", e.TxHash)
+
+	unsubscribe()
+	_, ok := <-events
+	require.False(t, ok)
+	require.Zero(t, s.subscriberCount())
+
+	// unsubscribing again is a no-op.
+	unsubscribe()
+}
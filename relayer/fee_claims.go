@@ -0,0 +1,59 @@
+package relayer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"go.uber.org/zap"
+)
+
+// DefaultFeeClaimInterval is how often ScheduleFeeClaims claims the ICS-29 fees paid to the fee payees of the chains.
+const DefaultFeeClaimInterval = 24 * time.Hour
+
+// ScheduleFeeClaims claims the ICS-29 fees paid to the fee-payee-key of each chain which has one to the key of
+// the chain every interval until ctx is done. Failed claims are logged and retried on the next interval.
+func ScheduleFeeClaims(ctx context.Context, log *zap.Logger, chains map[string]*Chain, interval time.Duration, memo string) {
+	var claimers []*cosmos.CosmosProvider
+	for _, c := range chains {
+		if cc, ok := c.ChainProvider.(*cosmos.CosmosProvider); ok && cc.PCfg.FeePayeeKey != "" {
+			claimers = append(claimers, cc)
+		}
+	}
+	if len(claimers) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var wg sync.WaitGroup
+		for _, cc := range claimers {
+			cc := cc
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				log := log.With(zap.String("chain_id", cc.ChainId()), zap.String("fee_payee_key", cc.PCfg.FeePayeeKey))
+				claimed, err := cc.ClaimFees(ctx, memo)
+				if err != nil {
+					log.Warn("Failed to claim ICS-29 fees", zap.Error(err))
+					return
+				}
+				if claimed.IsZero() {
+					log.Debug("No ICS-29 fees to claim")
+					return
+				}
+				log.Info("Claimed ICS-29 fees", zap.Stringer("fees", claimed))
+			}()
+		}
+		wg.Wait()
+	}
+}
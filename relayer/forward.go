@@ -0,0 +1,62 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// pfmIntermediateReceiver is the receiver on intermediate chains of a forwarded transfer.
+// Packet forward middleware derives the intermediate receiver itself, so any non-empty value is accepted.
+const pfmIntermediateReceiver = "pfm"
+
+// TransferHop is a single transfer of a transfer over multiple hops, identified by the channel it is sent through.
+type TransferHop struct {
+	PortID    string
+	ChannelID string
+}
+
+type pfmForward struct {
+	Receiver string       `json:"receiver"`
+	Port     string       `json:"port"`
+	Channel  string       `json:"channel"`
+	Next     *pfmMetadata `json:"next,omitempty"`
+}
+
+type pfmMetadata struct {
+	Forward pfmForward `json:"forward"`
+}
+
+// ForwardTransfer returns the receiver and packet forward middleware memo for the first transfer of a transfer
+// over multiple hops, e.g. an unwind or a route, such that the token is forwarded over the remaining hops and
+// delivered to receiver on the last chain.
+func ForwardTransfer(hops []TransferHop, receiver string) (string, string, error) {
+	if len(hops) == 0 {
+		return "", "", fmt.Errorf("no hops to transfer over")
+	}
+	if len(hops) == 1 {
+		return receiver, "", nil
+	}
+
+	// build the forwards from the origin chain backwards, nesting each in the previous hop.
+	var next *pfmMetadata
+	for i := len(hops) - 1; i > 0; i-- {
+		forwardReceiver := pfmIntermediateReceiver
+		if i == len(hops)-1 {
+			forwardReceiver = receiver
+		}
+		next = &pfmMetadata{
+			Forward: pfmForward{
+				Receiver: forwardReceiver,
+				Port:     hops[i].PortID,
+				Channel:  hops[i].ChannelID,
+				Next:     next,
+			},
+		}
+	}
+
+	memo, err := json.Marshal(next)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal packet forward memo: %w", err)
+	}
+	return pfmIntermediateReceiver, string(memo), nil
+}
@@ -0,0 +1,44 @@
+package relayer_test
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardTransfer(t *testing.T) {
+	const receiver = "cosmos1skjwj5whet0lpe65qaq4rpq03hjxlwd9nf39lk"
+
+	_, _, err := relayer.ForwardTransfer(nil, receiver)
+	require.Error(t, err)
+
+	dstAddr, memo, err := relayer.ForwardTransfer([]relayer.TransferHop{
+		{PortID: "transfer", ChannelID: "channel-1"},
+	}, receiver)
+	require.NoError(t, err)
+	require.Equal(t, receiver, dstAddr)
+	require.Empty(t, memo)
+
+	dstAddr, memo, err = relayer.ForwardTransfer([]relayer.TransferHop{
+		{PortID: "transfer", ChannelID: "channel-2"},
+		{PortID: "transfer", ChannelID: "channel-1"},
+		{PortID: "transfer", ChannelID: "channel-0"},
+	}, receiver)
+	require.NoError(t, err)
+	require.Equal(t, "pfm", dstAddr)
+	require.JSONEq(t, `{
+		"forward": {
+			"receiver": "pfm",
+			"port": "transfer",
+			"channel": "channel-1",
+			"next": {
+				"forward": {
+					"receiver": "`+receiver+`",
+					"port": "transfer",
+					"channel": "channel-0"
+				}
+			}
+		}
+	}`, memo)
+}
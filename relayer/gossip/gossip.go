@@ -0,0 +1,607 @@
+// Package gossip exchanges the packet messages which cooperating relayers intend to relay, so that relayers which
+// relay the same channels do not all broadcast txs for the same packets.
+//
+// Every relayer announces the packets it is about to relay to the relayer set as claims, and leaves the packets
+// claimed by other relayers to them until their claims expire. When two relayers claim the same packet, the claim of
+// the relayer with the lowest ID takes precedence.
+//
+// Relayers form a peer-to-peer network over HTTP. A relayer is identified by its ed25519 public key, and is
+// configured with the IDs of the relayers it trusts, which make up its relayer set. It discovers the gossip servers
+// of the trusted relayers from the bootstrap relayers it is configured with, and then from any relayer it knows, by
+// exchanging records of the gossip servers signed by the relayers they belong to. Claims are published in messages
+// signed by the relayer which claims the packets, which are sent to a few random peers, each of which relays messages
+// it has not seen before to a few random peers of its own, until the message has reached the whole relayer set. A
+// relayer only accepts the claims of the relayer which signed the message, so that relayers cannot claim packets on
+// behalf of each other. The claims, messages and records of relayers which are not trusted are ignored, so that
+// relayers outside of the relayer set can neither keep packets from being relayed nor take the place of its
+// relayers among the peers of a relayer. Trusted relayers are still trusted to relay the packets they claim.
+package gossip
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"go.uber.org/zap"
+)
+
+// ClaimsPath is the URL path on which messages with claims are received from peers.
+const ClaimsPath = "/relayer/gossip/claims"
+
+// DefaultKeyFile is the name of the file in the home directory holding the gossip key of the relayer by default.
+const DefaultKeyFile = "gossip.key"
+
+// DefaultClaimTTL is how long a claim is valid for by default. A packet claimed by a relayer which fails to relay it
+// is relayed by the other relayers once the claim expires.
+const DefaultClaimTTL = time.Minute
+
+const (
+	// outboxSize is how many claims are queued to be published, and how many messages of other relayers are queued
+	// to be relayed, further claims and messages are dropped.
+	outboxSize = 1000
+
+	// maxBatchClaims is the maximum number of claims published in a single message.
+	maxBatchClaims = 100
+
+	// maxRequestBytes is the maximum size of a request from a peer.
+	maxRequestBytes = 1 << 20
+
+	// publishTimeout is how long sending a request to a peer may take.
+	publishTimeout = 5 * time.Second
+
+	// fanout is how many random peers a message is sent to, and peers are exchanged with, at once.
+	fanout = 6
+
+	// messageDomain separates the signatures of messages from the signatures of anything else.
+	messageDomain = "relayer-gossip-message"
+)
+
+var _ processor.Coordinator = (*Gossip)(nil)
+
+// claimKey identifies a packet message claimed by a relayer.
+type claimKey struct {
+	chainID   string
+	eventType string
+	channel   processor.ChannelKey
+	sequence  uint64
+}
+
+// claim is the claim of a packet message by a relayer.
+type claim struct {
+	relayerID string
+	expires   time.Time
+}
+
+// message is published by the relayer From, with the claims encoded in Data. Messages are identified by From and
+// Seqno, which increases with every message of a relayer.
+type message struct {
+	From      string `json:"from"`
+	Seqno     uint64 `json:"seqno"`
+	Data      []byte `json:"data"`
+	Signature []byte `json:"signature"`
+}
+
+func (m message) id() string {
+	return fmt.Sprintf("%s/%d", m.From, m.Seqno)
+}
+
+func (m message) signBytes() []byte {
+	var b bytes.Buffer
+	b.WriteString(messageDomain)
+	b.WriteString(m.From)
+	_ = binary.Write(&b, binary.BigEndian, m.Seqno)
+	b.Write(m.Data)
+	return b.Bytes()
+}
+
+// Gossip is a processor.Coordinator which exchanges claims with the relayer set over HTTP. It serves the claims and
+// the peers it knows as an http.Handler on ClaimsPath and PeersPath.
+type Gossip struct {
+	log       *zap.Logger
+	id        string
+	key       ed25519.PrivateKey
+	url       string
+	bootstrap []string
+	trusted   map[string]bool
+	ttl       time.Duration
+	client    *http.Client
+
+	// local is true if the relayer set runs on the loopback interface of this host, as gossip with loopback
+	// addresses is only allowed then.
+	local bool
+
+	// discoveryInterval is how often peers are exchanged, see runDiscovery.
+	discoveryInterval time.Duration
+
+	seqno atomic.Uint64
+
+	mu     sync.Mutex
+	own    map[claimKey]time.Time
+	claims map[claimKey]claim
+	seen   map[string]time.Time
+	peers  map[string]peerRecord
+
+	outbox chan processor.PacketClaim
+	relays chan message
+}
+
+// New returns a Gossip for the relayer with key, which serves the gossip server advertised to its peers at url,
+// e.g. http://relayer-2:5184, and discovers the relayer set from the gossip servers at the bootstrap URLs. If url is
+// empty, the relayer is not advertised, and only publishes its claims. The relayer set is made up of the relayers
+// with the IDs in trusted, which must not be empty, as only their claims and gossip servers are accepted. Claims are
+// valid for ttl.
+func New(
+	log *zap.Logger,
+	key ed25519.PrivateKey,
+	url string,
+	bootstrap []string,
+	trusted []string,
+	ttl time.Duration,
+) (*Gossip, error) {
+	if len(trusted) == 0 {
+		return nil, errors.New("no trusted relayers, gossip requires the IDs of the relayers of the relayer set")
+	}
+	if ttl <= 0 {
+		ttl = DefaultClaimTTL
+	}
+	g := &Gossip{
+		log:               log,
+		id:                PeerID(key.Public().(ed25519.PublicKey)),
+		key:               key,
+		url:               strings.TrimRight(url, "/"),
+		bootstrap:         bootstrap,
+		trusted:           make(map[string]bool, len(trusted)),
+		ttl:               ttl,
+		discoveryInterval: defaultDiscoveryInterval,
+		own:               make(map[claimKey]time.Time),
+		claims:            make(map[claimKey]claim),
+		seen:              make(map[string]time.Time),
+		peers:             make(map[string]peerRecord),
+		outbox:            make(chan processor.PacketClaim, outboxSize),
+		relays:            make(chan message, outboxSize),
+	}
+	for _, id := range trusted {
+		if _, err := ParsePeerID(id); err != nil {
+			return nil, err
+		}
+		g.trusted[id] = true
+	}
+	for _, u := range append([]string{url}, bootstrap...) {
+		if isLoopbackURL(u) {
+			g.local = true
+		}
+	}
+	g.client = &http.Client{
+		Timeout: publishTimeout,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: publishTimeout, Control: g.checkDial}).DialContext,
+		},
+		// a peer must not send this relayer to URLs it would not gossip with.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	// messages of a restarted relayer must not be taken for messages its peers have already seen.
+	g.seqno.Store(uint64(time.Now().UnixNano()))
+	return g, nil
+}
+
+// ID returns the ID of the relayer among the relayer set.
+func (g *Gossip) ID() string {
+	return g.id
+}
+
+// Claim implements processor.Coordinator. The claim is published to the relayer set asynchronously by Run.
+func (g *Gossip) Claim(c processor.PacketClaim) {
+	c.RelayerID = g.id
+	c.Expires = time.Now().Add(g.ttl)
+
+	g.mu.Lock()
+	for _, seq := range c.Sequences {
+		g.own[claimKey{c.ChainID, c.EventType, c.Channel, seq}] = c.Expires
+	}
+	g.mu.Unlock()
+
+	select {
+	case g.outbox <- c:
+	default:
+		g.log.Warn("Dropping packet claim, too many claims queued to be published",
+			zap.String("chain_id", c.ChainID),
+			zap.String("event_type", c.EventType),
+			zap.Int("sequences", len(c.Sequences)),
+		)
+	}
+}
+
+// ClaimedByPeer implements processor.Coordinator. A claim of a peer takes precedence over the claim of this relayer
+// if this relayer has not claimed the packet message, or if the ID of the peer is lower.
+func (g *Gossip) ClaimedByPeer(chainID, eventType string, k processor.ChannelKey, sequence uint64) (string, bool) {
+	key := claimKey{chainID, eventType, k, sequence}
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.claims[key]
+	if !ok || !now.Before(c.expires) {
+		return "", false
+	}
+	if expires, ok := g.own[key]; ok && now.Before(expires) && g.id < c.relayerID {
+		return "", false
+	}
+	return c.relayerID, true
+}
+
+// trusts returns true if the relayer id is in the relayer set, so that its claims and gossip server are accepted.
+func (g *Gossip) trusts(id string) bool {
+	return g.trusted[id]
+}
+
+// checkAddr returns an error unless this relayer may gossip with the address addr. Unspecified, link-local and
+// multicast addresses are never gossiped with, and loopback addresses only if the relayer set is local.
+func (g *Gossip) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	switch {
+	case addr.IsUnspecified(), addr.IsLinkLocalUnicast(), addr.IsMulticast():
+		return fmt.Errorf("gossip with address %s is not allowed", addr)
+	case addr.IsLoopback() && !g.local:
+		return fmt.Errorf("gossip with loopback address %s is only allowed if the relayer set is local", addr)
+	}
+	return nil
+}
+
+// checkDial is the net.Dialer Control of the client of the relayer, which checks the addresses the host names of
+// the URLs of peers resolve to.
+func (g *Gossip) checkDial(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	return g.checkAddr(addrPort.Addr())
+}
+
+// checkURL returns an error unless this relayer may gossip with the server at the URL of a peer.
+func (g *Gossip) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid url %q", rawURL)
+	}
+	if isLoopbackURL(rawURL) && !g.local {
+		return fmt.Errorf("gossip with %q is only allowed if the relayer set is local", rawURL)
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		return g.checkAddr(addr)
+	}
+	return nil
+}
+
+// isLoopbackURL returns true if the host of rawURL is localhost or a loopback address.
+func isLoopbackURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(u.Hostname())
+	return err == nil && addr.Unmap().IsLoopback()
+}
+
+// markSeen returns true if the message with id had not been seen before, and remembers that it has been seen.
+func (g *Gossip) markSeen(id string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.seen[id]; ok {
+		return false
+	}
+	g.seen[id] = now
+	return true
+}
+
+// receive records the claims of the relayer relayerID, which signed the message with the claims. The claims are
+// rejected if any of them is not of relayerID, and ignored unless relayerID is trusted. Expired claims are ignored,
+// and the expiry of claims is limited to the claim ttl of this relayer.
+func (g *Gossip) receive(relayerID string, claims []processor.PacketClaim) error {
+	for _, c := range claims {
+		if c.RelayerID != relayerID {
+			return fmt.Errorf("claim of relayer %q published by relayer %q", c.RelayerID, relayerID)
+		}
+	}
+	if !g.trusts(relayerID) {
+		return nil
+	}
+
+	now := time.Now()
+	maxExpires := now.Add(g.ttl)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, c := range claims {
+		if !now.Before(c.Expires) {
+			continue
+		}
+		expires := c.Expires
+		if expires.After(maxExpires) {
+			expires = maxExpires
+		}
+		for _, seq := range c.Sequences {
+			key := claimKey{c.ChainID, c.EventType, c.Channel, seq}
+			// an unexpired claim of another trusted relayer with a lower ID takes precedence.
+			if existing, ok := g.claims[key]; ok && now.Before(existing.expires) && existing.relayerID < c.RelayerID {
+				continue
+			}
+			g.claims[key] = claim{relayerID: c.RelayerID, expires: expires}
+		}
+	}
+	return nil
+}
+
+// prune forgets the expired claims, the messages seen long enough ago that their claims have expired, and the peers
+// which have not refreshed their records.
+func (g *Gossip) prune(now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, expires := range g.own {
+		if !now.Before(expires) {
+			delete(g.own, key)
+		}
+	}
+	for key, c := range g.claims {
+		if !now.Before(c.expires) {
+			delete(g.claims, key)
+		}
+	}
+	for id, seen := range g.seen {
+		if now.Sub(seen) > 2*g.ttl {
+			delete(g.seen, id)
+		}
+	}
+	for id, r := range g.peers {
+		if now.Sub(r.Timestamp) > peerTTL {
+			delete(g.peers, id)
+		}
+	}
+}
+
+// Run publishes the claims of this relayer, relays the messages of other relayers, discovers the relayer set,
+// and prunes expired claims, until ctx is done.
+func (g *Gossip) Run(ctx context.Context) {
+	go g.runDiscovery(ctx)
+
+	ticker := time.NewTicker(g.ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			g.prune(now)
+		case m := <-g.relays:
+			g.send(ctx, m, m.From)
+		case c := <-g.outbox:
+			batch := []processor.PacketClaim{c}
+		Drain:
+			for len(batch) < maxBatchClaims {
+				select {
+				case c := <-g.outbox:
+					batch = append(batch, c)
+				default:
+					break Drain
+				}
+			}
+			g.publish(ctx, batch)
+		}
+	}
+}
+
+// publish publishes the claims of this relayer in a signed message.
+func (g *Gossip) publish(ctx context.Context, claims []processor.PacketClaim) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		g.log.Error("Failed to encode packet claims", zap.Error(err))
+		return
+	}
+	m := message{From: g.id, Seqno: g.seqno.Add(1), Data: data}
+	m.Signature = ed25519.Sign(g.key, m.signBytes())
+	g.markSeen(m.id(), time.Now())
+	g.send(ctx, m, "")
+}
+
+// send sends the message concurrently to fanout random peers other than the relayer exclude. Peers which cannot be
+// reached are forgotten until they are discovered again.
+func (g *Gossip) send(ctx context.Context, m message, exclude string) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		g.log.Error("Failed to encode gossip message", zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range g.randomPeers(fanout, exclude) {
+		wg.Add(1)
+		go func(p peerRecord) {
+			defer wg.Done()
+			if err := g.post(ctx, p.URL+ClaimsPath, body); err != nil {
+				g.log.Debug("Failed to send packet claims to peer",
+					zap.String("peer_id", p.ID),
+					zap.String("peer_url", p.URL),
+					zap.Error(err),
+				)
+				g.dropPeer(p)
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (g *Gossip) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler, receiving the messages of peers on ClaimsPath, and exchanging peers on
+// PeersPath.
+func (g *Gossip) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case ClaimsPath:
+		g.serveClaims(w, r)
+	case PeersPath:
+		g.servePeers(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveClaims receives a message with claims, and relays it to the relayer set if it has not been seen before.
+// Messages of relayers which are not trusted are ignored, and not relayed either.
+func (g *Gossip) serveClaims(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read message: %v", err), http.StatusBadRequest)
+		return
+	}
+	var m message
+	if err := json.Unmarshal(body, &m); err != nil {
+		http.Error(w, fmt.Sprintf("invalid message: %v", err), http.StatusBadRequest)
+		return
+	}
+	if m.From == g.id || !g.trusts(m.From) {
+		// a message of this relayer relayed back by a peer, or of a relayer outside of the relayer set, which
+		// peers with other relayer sets may relay.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := verifySignature(m.From, m.signBytes(), m.Signature); err != nil {
+		g.log.Debug("Rejecting unauthenticated gossip message", zap.String("relayer_id", m.From), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var claims []processor.PacketClaim
+	if err := json.Unmarshal(m.Data, &claims); err != nil {
+		http.Error(w, fmt.Sprintf("invalid claims: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !g.markSeen(m.id(), time.Now()) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := g.receive(m.From, claims); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	select {
+	case g.relays <- m:
+	default:
+		g.log.Debug("Dropping gossip message, too many messages queued to be relayed", zap.String("relayer_id", m.From))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verifySignature returns an error unless signature is the signature of msg by the relayer id.
+func verifySignature(id string, msg, signature []byte) error {
+	pub, err := ParsePeerID(id)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, signature) {
+		return fmt.Errorf("invalid signature of relayer %q", id)
+	}
+	return nil
+}
+
+// PeerID returns the ID of the relayer with the gossip public key pub, which is the hex encoded key.
+func PeerID(pub ed25519.PublicKey) string {
+	return hex.EncodeToString(pub)
+}
+
+// ParsePeerID parses the ID of a relayer, returning its gossip public key.
+func ParsePeerID(id string) (ed25519.PublicKey, error) {
+	bz, err := hex.DecodeString(id)
+	if err != nil || len(bz) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid relayer id %q, expected a hex encoded %d byte ed25519 public key", id, ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(bz), nil
+}
+
+// LoadOrCreateKey returns the gossip key of the relayer stored in file, as a hex encoded ed25519 seed.
+// If the file does not exist, a new key is generated and stored in it.
+func LoadOrCreateKey(file string) (ed25519.PrivateKey, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate gossip key: %w", err)
+		}
+		if err := os.WriteFile(file, []byte(hex.EncodeToString(key.Seed())+"\n"), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write gossip key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gossip key: %w", err)
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(bz)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid gossip key in %s, expected a hex encoded %d byte seed", file, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// Serve serves the gossip server of g on ln until ctx is done.
+func Serve(ctx context.Context, log *zap.Logger, ln net.Listener, g *Gossip) {
+	srv := &http.Server{
+		Handler:  g,
+		ErrorLog: zap.NewStdLog(log),
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	go srv.Serve(ln)
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+}
@@ -0,0 +1,275 @@
+package gossip
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PeersPath is the URL path on which the records of the relayers known by a relayer are exchanged with peers.
+const PeersPath = "/relayer/gossip/peers"
+
+const (
+	// defaultDiscoveryInterval is how often a relayer exchanges peers with the relayer set.
+	defaultDiscoveryInterval = 30 * time.Second
+
+	// peerTTL is how long the record of a relayer is valid for. Relayers refresh their records every discovery
+	// interval, so the records of relayers which left the relayer set expire.
+	peerTTL = 10 * time.Minute
+
+	// maxClockDrift is how far in the future the records of peers may be.
+	maxClockDrift = time.Minute
+
+	// peerRecordDomain separates the signatures of peer records from the signatures of anything else.
+	peerRecordDomain = "relayer-gossip-peer"
+)
+
+// peerRecord announces the gossip server of the relayer ID at URL, and is signed by the relayer.
+type peerRecord struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Timestamp time.Time `json:"timestamp"`
+	Signature []byte    `json:"signature"`
+}
+
+func (r peerRecord) signBytes() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%d", peerRecordDomain, r.ID, r.URL, r.Timestamp.UnixNano()))
+}
+
+// verify returns an error unless the record is signed by its relayer, and was refreshed within the peer ttl.
+func (r peerRecord) verify(now time.Time) error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q of relayer %q", r.URL, r.ID)
+	}
+	if now.Sub(r.Timestamp) > peerTTL {
+		return fmt.Errorf("expired record of relayer %q", r.ID)
+	}
+	if r.Timestamp.After(now.Add(maxClockDrift)) {
+		return fmt.Errorf("record of relayer %q from the future", r.ID)
+	}
+	return verifySignature(r.ID, r.signBytes(), r.Signature)
+}
+
+// ownRecord returns the record of this relayer, signed at now.
+func (g *Gossip) ownRecord(now time.Time) peerRecord {
+	r := peerRecord{ID: g.id, URL: g.url, Timestamp: now}
+	r.Signature = ed25519.Sign(g.key, r.signBytes())
+	return r
+}
+
+// addPeer records the peer r, unless a more recent record of the peer is known. Records of relayers which are not
+// trusted are ignored, so that only the trusted relayers are ever peers of this relayer, each with the server it
+// signed the record of, however many records a peer serves.
+func (g *Gossip) addPeer(r peerRecord, now time.Time) error {
+	if r.ID == g.id || !g.trusts(r.ID) {
+		return nil
+	}
+	if err := r.verify(now); err != nil {
+		return err
+	}
+	if err := g.checkURL(r.URL); err != nil {
+		return fmt.Errorf("record of relayer %q: %w", r.ID, err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	existing, ok := g.peers[r.ID]
+	if ok && !r.Timestamp.After(existing.Timestamp) {
+		return nil
+	}
+	g.peers[r.ID] = r
+	return nil
+}
+
+// dropPeer forgets the peer r, unless it has been refreshed since.
+func (g *Gossip) dropPeer(r peerRecord) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.peers[r.ID]; ok && existing.Timestamp.Equal(r.Timestamp) {
+		delete(g.peers, r.ID)
+	}
+}
+
+// Peers returns the IDs of the relayers known by this relayer.
+func (g *Gossip) Peers() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ids := make([]string, 0, len(g.peers))
+	for id := range g.peers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// randomPeers returns up to n random peers other than the relayer exclude.
+func (g *Gossip) randomPeers(n int, exclude string) []peerRecord {
+	g.mu.Lock()
+	peers := make([]peerRecord, 0, len(g.peers))
+	for id, r := range g.peers {
+		if id != exclude {
+			peers = append(peers, r)
+		}
+	}
+	g.mu.Unlock()
+
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	if len(peers) > n {
+		peers = peers[:n]
+	}
+	return peers
+}
+
+// runDiscovery exchanges peers with the relayer set every discovery interval until ctx is done.
+func (g *Gossip) runDiscovery(ctx context.Context) {
+	ticker := time.NewTicker(g.discoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		g.discover(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discover learns the peers known by the bootstrap relayers and fanout random peers, then announces the record of
+// this relayer to the bootstrap relayers and fanout random peers, which include the peers just learned.
+func (g *Gossip) discover(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, u := range g.discoveryURLs() {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			if err := g.fetchPeers(ctx, u); err != nil {
+				g.log.Debug("Failed to fetch peers", zap.String("peer_url", u), zap.Error(err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	if g.url == "" {
+		return
+	}
+	body, err := json.Marshal(g.ownRecord(time.Now()))
+	if err != nil {
+		g.log.Error("Failed to encode peer record", zap.Error(err))
+		return
+	}
+	for _, u := range g.discoveryURLs() {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			if err := g.post(ctx, u+PeersPath, body); err != nil {
+				g.log.Debug("Failed to announce to peer", zap.String("peer_url", u), zap.Error(err))
+			}
+		}(u)
+	}
+	wg.Wait()
+}
+
+// discoveryURLs returns the URLs of the bootstrap relayers and fanout random peers.
+func (g *Gossip) discoveryURLs() []string {
+	urls := slices.Clone(g.bootstrap)
+	for _, r := range g.randomPeers(fanout, "") {
+		urls = append(urls, r.URL)
+	}
+	for i, u := range urls {
+		urls[i] = strings.TrimRight(u, "/")
+	}
+	slices.Sort(urls)
+	return slices.Compact(urls)
+}
+
+// fetchPeers records the trusted peers known by the relayer at url.
+func (g *Gossip) fetchPeers(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+PeersPath, nil)
+	if err != nil {
+		return err
+	}
+	res, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	var records []peerRecord
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxRequestBytes)).Decode(&records); err != nil {
+		return fmt.Errorf("invalid peers: %w", err)
+	}
+	// a peer serves no more records than there are trusted relayers, further records are ignored.
+	now := time.Now()
+	var errs []error
+	n := 0
+	for _, r := range records {
+		if r.ID == g.id || !g.trusts(r.ID) {
+			continue
+		}
+		if n++; n > len(g.trusted) {
+			break
+		}
+		errs = append(errs, g.addPeer(r, now))
+	}
+	return errors.Join(errs...)
+}
+
+// servePeers returns the records of the relayers known by this relayer, including its own, on GET, and records
+// the peer announced on POST.
+func (g *Gossip) servePeers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		now := time.Now()
+		g.mu.Lock()
+		records := make([]peerRecord, 0, len(g.peers)+1)
+		for _, p := range g.peers {
+			records = append(records, p)
+		}
+		g.mu.Unlock()
+		if g.url != "" {
+			records = append(records, g.ownRecord(now))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(records); err != nil {
+			g.log.Debug("Failed to write peers", zap.Error(err))
+		}
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read peer record: %v", err), http.StatusBadRequest)
+			return
+		}
+		var record peerRecord
+		if err := json.Unmarshal(body, &record); err != nil {
+			http.Error(w, fmt.Sprintf("invalid peer record: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := g.addPeer(record, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
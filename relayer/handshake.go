@@ -0,0 +1,431 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// handshakeStep is the next step of a connection or channel handshake between two chains.
+type handshakeStep int
+
+const (
+	// handshakeStepInit means no handshake is in progress, so a new one is started.
+	handshakeStepInit handshakeStep = iota
+	// handshakeStepTry means an end is in INIT without a counterparty end.
+	handshakeStepTry
+	// handshakeStepAck means an end is in TRYOPEN and its counterparty end is still in INIT.
+	handshakeStepAck
+	// handshakeStepConfirm means an end is OPEN and its counterparty end is still in TRYOPEN.
+	handshakeStepConfirm
+	// handshakeStepOpen means both ends are already OPEN.
+	handshakeStepOpen
+)
+
+func (s handshakeStep) String() string {
+	switch s {
+	case handshakeStepInit:
+		return "init"
+	case handshakeStepTry:
+		return "try"
+	case handshakeStepAck:
+		return "ack"
+	case handshakeStepConfirm:
+		return "confirm"
+	case handshakeStepOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+type handshakeState int
+
+const (
+	handshakeStateOther handshakeState = iota
+	handshakeStateInit
+	handshakeStateTry
+	handshakeStateOpen
+)
+
+// handshakeEnd is a connection or channel end on one chain of a handshake.
+type handshakeEnd struct {
+	ID             string
+	CounterpartyID string
+	State          handshakeState
+}
+
+// nextHandshakeStep determines where to pick up a handshake between the ends on chains a and b, which may have been
+// interrupted at any step by an earlier attempt, e.g. after a tx was included but its result was lost.
+// It returns the step and the end which the step follows from, along with whether that end is on a.
+// The most recent end is resumed if several handshakes were left incomplete.
+func nextHandshakeStep(a, b []handshakeEnd) (handshakeStep, handshakeEnd, bool) {
+	a, b = latestHandshakeEndsFirst(a), latestHandshakeEndsFirst(b)
+
+	find := func(ends []handshakeEnd, id string) (handshakeEnd, bool) {
+		for _, end := range ends {
+			if end.ID == id {
+				return end, true
+			}
+		}
+		return handshakeEnd{}, false
+	}
+
+	for _, end := range a {
+		if end.State != handshakeStateOpen {
+			continue
+		}
+		if counterparty, ok := find(b, end.CounterpartyID); ok &&
+			counterparty.State == handshakeStateOpen && counterparty.CounterpartyID == end.ID {
+			return handshakeStepOpen, end, true
+		}
+	}
+
+	for _, side := range []struct {
+		try, init []handshakeEnd
+		onA       bool
+	}{{a, b, true}, {b, a, false}} {
+		for _, end := range side.try {
+			if end.State != handshakeStateTry {
+				continue
+			}
+			counterparty, ok := find(side.init, end.CounterpartyID)
+			if !ok {
+				continue
+			}
+			switch {
+			case counterparty.State == handshakeStateInit:
+				return handshakeStepAck, end, side.onA
+			case counterparty.State == handshakeStateOpen && counterparty.CounterpartyID == end.ID:
+				return handshakeStepConfirm, counterparty, !side.onA
+			}
+		}
+	}
+
+	for _, side := range []struct {
+		ends []handshakeEnd
+		onA  bool
+	}{{a, true}, {b, false}} {
+		for _, end := range side.ends {
+			if end.State == handshakeStateInit {
+				return handshakeStepTry, end, side.onA
+			}
+		}
+	}
+
+	return handshakeStepInit, handshakeEnd{}, true
+}
+
+// pathHandshakeStep determines where to pick up the handshake of a path from the ends on its src and dst chains.
+// The handshake recorded on the path ends by srcID and dstID, if any, is resumed from any step. Otherwise only
+// ends which are already open are adopted, since an incomplete handshake between the same clients may have been
+// started by another path or relayer, and a new handshake is started if there are none.
+func pathHandshakeStep(src, dst []handshakeEnd, srcID, dstID string) (handshakeStep, handshakeEnd, bool) {
+	if srcID != "" || dstID != "" {
+		recordedSrc, recordedDst := recordedHandshakeEnds(src, dst, srcID, dstID)
+		if step, end, onSrc := nextHandshakeStep(recordedSrc, recordedDst); step != handshakeStepInit {
+			return step, end, onSrc
+		}
+	}
+
+	if step, end, onSrc := nextHandshakeStep(src, dst); step == handshakeStepOpen {
+		return step, end, onSrc
+	}
+
+	return handshakeStepInit, handshakeEnd{}, true
+}
+
+// recordedHandshakeEnds returns the ends of a and b with the recorded identifiers aID and bID,
+// along with the counterparty ends which the recorded ends refer to.
+func recordedHandshakeEnds(a, b []handshakeEnd, aID, bID string) (recordedA, recordedB []handshakeEnd) {
+	for _, end := range a {
+		if aID != "" && end.ID == aID {
+			recordedA = append(recordedA, end)
+			if bID == "" {
+				bID = end.CounterpartyID
+			}
+		}
+	}
+	for _, end := range b {
+		if bID != "" && end.ID == bID {
+			recordedB = append(recordedB, end)
+			if aID == "" && end.CounterpartyID != "" {
+				aID = end.CounterpartyID
+				for _, end := range a {
+					if end.ID == aID {
+						recordedA = append(recordedA, end)
+					}
+				}
+			}
+		}
+	}
+	return recordedA, recordedB
+}
+
+// latestHandshakeEndsFirst returns the ends sorted by descending identifier sequence, e.g. connection-2 before connection-1.
+func latestHandshakeEndsFirst(ends []handshakeEnd) []handshakeEnd {
+	sorted := make([]handshakeEnd, len(ends))
+	copy(sorted, ends)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return handshakeIDSequence(sorted[i].ID) > handshakeIDSequence(sorted[j].ID)
+	})
+	return sorted
+}
+
+func handshakeIDSequence(id string) uint64 {
+	seq, err := strconv.ParseUint(id[strings.LastIndex(id, "-")+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return seq
+}
+
+// queryHandshakeConnections returns the connections on src, in any state, between the clients of src and dst
+// and with the commitment prefix of dst, i.e. those which a connection for the path may be adopted from.
+func queryHandshakeConnections(ctx context.Context, src, dst *Chain) ([]*conntypes.IdentifiedConnection, error) {
+	srch, err := src.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest height on chain{%s}: %w", src.ChainID(), err)
+	}
+
+	res, err := src.ChainProvider.QueryConnectionsUsingClient(ctx, srch, src.ClientID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connections using client{%s} on chain{%s}: %w", src.ClientID(), src.ChainID(), err)
+	}
+
+	return matchingConnections(res.Connections, dst.ClientID(), dst.ChainProvider.CommitmentPrefix()), nil
+}
+
+// matchingConnections returns the connections whose counterparty is the client with counterpartyClientID
+// and the commitment prefix of its chain.
+func matchingConnections(
+	conns []*conntypes.IdentifiedConnection,
+	counterpartyClientID string,
+	prefix commitmenttypes.MerklePrefix,
+) []*conntypes.IdentifiedConnection {
+	var matching []*conntypes.IdentifiedConnection
+	for _, conn := range conns {
+		if conn.Counterparty.ClientId == counterpartyClientID && bytes.Equal(conn.Counterparty.Prefix.Bytes(), prefix.Bytes()) {
+			matching = append(matching, conn)
+		}
+	}
+	return matching
+}
+
+// connectionHandshakeStep determines where to pick up the connection handshake of the path between the src and
+// dst path ends from the connections between their clients, see pathHandshakeStep. With override, a new handshake
+// is always started.
+func connectionHandshakeStep(
+	srcConns, dstConns []*conntypes.IdentifiedConnection,
+	srcConnID, dstConnID string,
+	override bool,
+) (handshakeStep, handshakeEnd, bool) {
+	if override {
+		return handshakeStepInit, handshakeEnd{}, true
+	}
+	return pathHandshakeStep(connectionHandshakeEnds(srcConns), connectionHandshakeEnds(dstConns), srcConnID, dstConnID)
+}
+
+func connectionHandshakeEnds(conns []*conntypes.IdentifiedConnection) []handshakeEnd {
+	ends := make([]handshakeEnd, len(conns))
+	for i, conn := range conns {
+		ends[i] = handshakeEnd{ID: conn.Id, CounterpartyID: conn.Counterparty.ConnectionId}
+		switch conn.State {
+		case conntypes.INIT:
+			ends[i].State = handshakeStateInit
+		case conntypes.TRYOPEN:
+			ends[i].State = handshakeStateTry
+		case conntypes.OPEN:
+			ends[i].State = handshakeStateOpen
+		}
+	}
+	return ends
+}
+
+// queryHandshakeChannels returns the channels on the connection of src, in any state,
+// between srcPortID on src and dstPortID on its counterparty.
+func queryHandshakeChannels(ctx context.Context, src *Chain, srcPortID, dstPortID string) ([]*chantypes.IdentifiedChannel, error) {
+	channels, err := queryChannelsOnConnection(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels on chain{%s}@connection{%s}: %w", src.ChainID(), src.ConnectionID(), err)
+	}
+
+	var portChannels []*chantypes.IdentifiedChannel
+	for _, channel := range channels {
+		if channel.PortId == srcPortID && channel.Counterparty.PortId == dstPortID {
+			portChannels = append(portChannels, channel)
+		}
+	}
+	return portChannels, nil
+}
+
+func channelHandshakeEnds(channels []*chantypes.IdentifiedChannel) []handshakeEnd {
+	ends := make([]handshakeEnd, len(channels))
+	for i, channel := range channels {
+		ends[i] = handshakeEnd{ID: channel.ChannelId, CounterpartyID: channel.Counterparty.ChannelId}
+		switch channel.State {
+		case chantypes.INIT:
+			ends[i].State = handshakeStateInit
+		case chantypes.TRYOPEN:
+			ends[i].State = handshakeStateTry
+		case chantypes.OPEN, chantypes.FLUSHING, chantypes.FLUSHCOMPLETE:
+			// a channel which is being upgraded has completed its opening handshake.
+			ends[i].State = handshakeStateOpen
+		}
+	}
+	return ends
+}
+
+// handshakeReservation holds the identifiers of the ends of a handshake by chain ID, reserved as they are created.
+// An end is reserved once it is verified by query to refer to the expected counterparty, so that ends which other
+// relayers create between the same clients or ports, possibly in the same block, are not mistaken for those of
+// the handshake. It is safe for concurrent use, since ends are verified off the goroutine of the path processor
+// which terminates the handshake on the reserved ends.
+type handshakeReservation struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+func newHandshakeReservation() *handshakeReservation {
+	return &handshakeReservation{ids: make(map[string]string)}
+}
+
+// reserve records id as the end on chainID unless another end is reserved there already,
+// and returns whether id is the end reserved on chainID.
+func (r *handshakeReservation) reserve(chainID, id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reserved, ok := r.ids[chainID]; ok {
+		return reserved == id
+	}
+	r.ids[chainID] = id
+	return true
+}
+
+// id returns the end reserved on chainID, or an empty string if none is.
+func (r *handshakeReservation) id(chainID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ids[chainID]
+}
+
+// handshakeVerifier runs the verification of the handshake ends observed by the callbacks of a path processor,
+// in the order they are observed, on its own goroutine so that the queries do not hold up the path processor.
+type handshakeVerifier struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan func(context.Context)
+	done   chan struct{}
+}
+
+func newHandshakeVerifier(ctx context.Context) *handshakeVerifier {
+	ctx, cancel := context.WithCancel(ctx)
+	v := &handshakeVerifier{ctx: ctx, cancel: cancel, queue: make(chan func(context.Context), 16), done: make(chan struct{})}
+	go func() {
+		defer close(v.done)
+		for {
+			select {
+			case verify := <-v.queue:
+				verify(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return v
+}
+
+// enqueue schedules verify to run after the verifications enqueued before it, unless the verifier is stopped.
+func (v *handshakeVerifier) enqueue(verify func(context.Context)) {
+	select {
+	case v.queue <- verify:
+	case <-v.ctx.Done():
+	}
+}
+
+// stop stops the verifier and waits for a running verification to return.
+func (v *handshakeVerifier) stop() {
+	v.cancel()
+	<-v.done
+}
+
+// verifyConnectionEnd queries the connection connID on chain and returns an error unless it is between the clients
+// of chain and counterparty and, if counterpartyConnID is set, its counterparty is the connection counterpartyConnID.
+func verifyConnectionEnd(ctx context.Context, chain, counterparty *Chain, connID, counterpartyConnID string) error {
+	res, err := chain.ChainProvider.QueryConnection(ctx, 0, connID)
+	if err != nil {
+		return fmt.Errorf("failed to query connection{%s} on chain{%s}: %w", connID, chain.ChainID(), err)
+	}
+	conn := res.Connection
+	switch {
+	case conn == nil:
+		return fmt.Errorf("connection{%s} not found on chain{%s}", connID, chain.ChainID())
+	case conn.ClientId != chain.ClientID():
+		return fmt.Errorf("connection{%s} on chain{%s} uses client{%s}, expected client{%s}",
+			connID, chain.ChainID(), conn.ClientId, chain.ClientID())
+	case conn.Counterparty.ClientId != counterparty.ClientID():
+		return fmt.Errorf("connection{%s} on chain{%s} has counterparty client{%s}, expected client{%s}",
+			connID, chain.ChainID(), conn.Counterparty.ClientId, counterparty.ClientID())
+	case counterpartyConnID != "" && conn.Counterparty.ConnectionId != counterpartyConnID:
+		return fmt.Errorf("connection{%s} on chain{%s} has counterparty connection{%s}, expected connection{%s}",
+			connID, chain.ChainID(), conn.Counterparty.ConnectionId, counterpartyConnID)
+	}
+	return nil
+}
+
+// verifyChannelEnd queries the channel channelID on portID of chain and returns an error unless it is on the
+// connection of chain with counterpartyPortID and, if counterpartyChannelID is set, its counterparty is the channel
+// counterpartyChannelID.
+func verifyChannelEnd(ctx context.Context, chain *Chain, portID, channelID, counterpartyPortID, counterpartyChannelID string) error {
+	res, err := chain.ChainProvider.QueryChannel(ctx, 0, channelID, portID)
+	if err != nil {
+		return fmt.Errorf("failed to query channel{%s} with port{%s} on chain{%s}: %w", channelID, portID, chain.ChainID(), err)
+	}
+	channel := res.Channel
+	switch {
+	case channel == nil:
+		return fmt.Errorf("channel{%s} with port{%s} not found on chain{%s}", channelID, portID, chain.ChainID())
+	case len(channel.ConnectionHops) == 0 || channel.ConnectionHops[0] != chain.ConnectionID():
+		return fmt.Errorf("channel{%s} on chain{%s} uses connections %v, expected connection{%s}",
+			channelID, chain.ChainID(), channel.ConnectionHops, chain.ConnectionID())
+	case channel.Counterparty.PortId != counterpartyPortID:
+		return fmt.Errorf("channel{%s} on chain{%s} has counterparty port{%s}, expected port{%s}",
+			channelID, chain.ChainID(), channel.Counterparty.PortId, counterpartyPortID)
+	case counterpartyChannelID != "" && channel.Counterparty.ChannelId != counterpartyChannelID:
+		return fmt.Errorf("channel{%s} on chain{%s} has counterparty channel{%s}, expected channel{%s}",
+			channelID, chain.ChainID(), channel.Counterparty.ChannelId, counterpartyChannelID)
+	}
+	return nil
+}
+
+// handshakePathProcessor returns a PathProcessor for relaying a handshake between c and dst,
+// which retries messages according to the retry policy.
+func (c *Chain) handshakePathProcessor(dst *Chain, pathName, memo string, retryPolicy *RetryPolicy) *processor.PathProcessor {
+	pp := processor.NewPathProcessor(
+		c.log,
+		processor.NewPathEnd(pathName, c.PathEnd.ChainID, c.PathEnd.ClientID, "", []processor.ChainChannelKey{}).
+			WithConnectionHops(c.PathEnd.ConnectionHops).
+			WithClientMessages(c.PathEnd.processorClientMessages()),
+		processor.NewPathEnd(pathName, dst.PathEnd.ChainID, dst.PathEnd.ClientID, "", []processor.ChainChannelKey{}).
+			WithConnectionHops(dst.PathEnd.ConnectionHops).
+			WithClientMessages(dst.PathEnd.processorClientMessages()),
+		nil,
+		memo,
+		DefaultClientUpdateThreshold,
+		DefaultFlushInterval,
+		DefaultMaxMsgLength,
+		0,
+		0,
+	)
+	pp.SetMsgRetryPolicy(retryPolicy.ProcessorRetryPolicy())
+	pp.SetProofVerification(proofVerification(c, dst))
+	return pp
+}
@@ -0,0 +1,297 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/light"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextHandshakeStep(t *testing.T) {
+	testCases := []struct {
+		name         string
+		a, b         []handshakeEnd
+		expectedStep handshakeStep
+		expectedEnd  handshakeEnd
+		expectedOnA  bool
+	}{
+		{
+			name:         "no handshake",
+			expectedStep: handshakeStepInit,
+			expectedOnA:  true,
+		},
+		{
+			name:         "init without try",
+			a:            []handshakeEnd{{ID: "connection-0", State: handshakeStateInit}},
+			expectedStep: handshakeStepTry,
+			expectedEnd:  handshakeEnd{ID: "connection-0", State: handshakeStateInit},
+			expectedOnA:  true,
+		},
+		{
+			name: "latest init is resumed",
+			b: []handshakeEnd{
+				{ID: "connection-1", State: handshakeStateInit},
+				{ID: "connection-10", State: handshakeStateInit},
+				{ID: "connection-2", State: handshakeStateInit},
+			},
+			expectedStep: handshakeStepTry,
+			expectedEnd:  handshakeEnd{ID: "connection-10", State: handshakeStateInit},
+			expectedOnA:  false,
+		},
+		{
+			name:         "try after lost result needs ack",
+			a:            []handshakeEnd{{ID: "connection-0", State: handshakeStateInit}},
+			b:            []handshakeEnd{{ID: "connection-3", CounterpartyID: "connection-0", State: handshakeStateTry}},
+			expectedStep: handshakeStepAck,
+			expectedEnd:  handshakeEnd{ID: "connection-3", CounterpartyID: "connection-0", State: handshakeStateTry},
+			expectedOnA:  false,
+		},
+		{
+			name:         "ack needs confirm",
+			a:            []handshakeEnd{{ID: "connection-0", CounterpartyID: "connection-3", State: handshakeStateOpen}},
+			b:            []handshakeEnd{{ID: "connection-3", CounterpartyID: "connection-0", State: handshakeStateTry}},
+			expectedStep: handshakeStepConfirm,
+			expectedEnd:  handshakeEnd{ID: "connection-0", CounterpartyID: "connection-3", State: handshakeStateOpen},
+			expectedOnA:  true,
+		},
+		{
+			name:         "reversed handshake needs confirm",
+			a:            []handshakeEnd{{ID: "channel-5", CounterpartyID: "channel-1", State: handshakeStateTry}},
+			b:            []handshakeEnd{{ID: "channel-1", CounterpartyID: "channel-5", State: handshakeStateOpen}},
+			expectedStep: handshakeStepConfirm,
+			expectedEnd:  handshakeEnd{ID: "channel-1", CounterpartyID: "channel-5", State: handshakeStateOpen},
+			expectedOnA:  false,
+		},
+		{
+			name: "open on both ends",
+			a: []handshakeEnd{
+				{ID: "connection-0", State: handshakeStateInit},
+				{ID: "connection-1", CounterpartyID: "connection-3", State: handshakeStateOpen},
+			},
+			b:            []handshakeEnd{{ID: "connection-3", CounterpartyID: "connection-1", State: handshakeStateOpen}},
+			expectedStep: handshakeStepOpen,
+			expectedEnd:  handshakeEnd{ID: "connection-1", CounterpartyID: "connection-3", State: handshakeStateOpen},
+			expectedOnA:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step, end, onA := nextHandshakeStep(tc.a, tc.b)
+			require.Equal(t, tc.expectedStep, step)
+			require.Equal(t, tc.expectedEnd, end)
+			require.Equal(t, tc.expectedOnA, onA)
+		})
+	}
+}
+
+func TestPathHandshakeStep(t *testing.T) {
+	testCases := []struct {
+		name         string
+		src, dst     []handshakeEnd
+		srcID, dstID string
+		expectedStep handshakeStep
+		expectedEnd  handshakeEnd
+		expectedOnA  bool
+	}{
+		{
+			name:         "recorded init is resumed",
+			src:          []handshakeEnd{{ID: "connection-4", State: handshakeStateInit}},
+			srcID:        "connection-4",
+			expectedStep: handshakeStepTry,
+			expectedEnd:  handshakeEnd{ID: "connection-4", State: handshakeStateInit},
+			expectedOnA:  true,
+		},
+		{
+			name:         "recorded try is resumed from its counterparty",
+			src:          []handshakeEnd{{ID: "connection-4", State: handshakeStateInit}},
+			dst:          []handshakeEnd{{ID: "connection-7", CounterpartyID: "connection-4", State: handshakeStateTry}},
+			dstID:        "connection-7",
+			expectedStep: handshakeStepAck,
+			expectedEnd:  handshakeEnd{ID: "connection-7", CounterpartyID: "connection-4", State: handshakeStateTry},
+			expectedOnA:  false,
+		},
+		{
+			name: "only the recorded handshake is resumed",
+			src: []handshakeEnd{
+				{ID: "connection-4", State: handshakeStateInit},
+				{ID: "connection-9", State: handshakeStateInit},
+			},
+			srcID:        "connection-4",
+			expectedStep: handshakeStepTry,
+			expectedEnd:  handshakeEnd{ID: "connection-4", State: handshakeStateInit},
+			expectedOnA:  true,
+		},
+		{
+			name:         "unrecorded init is not resumed",
+			src:          []handshakeEnd{{ID: "connection-9", State: handshakeStateInit}},
+			expectedStep: handshakeStepInit,
+			expectedOnA:  true,
+		},
+		{
+			name:         "unrecorded open connection is adopted",
+			src:          []handshakeEnd{{ID: "connection-1", CounterpartyID: "connection-3", State: handshakeStateOpen}},
+			dst:          []handshakeEnd{{ID: "connection-3", CounterpartyID: "connection-1", State: handshakeStateOpen}},
+			expectedStep: handshakeStepOpen,
+			expectedEnd:  handshakeEnd{ID: "connection-1", CounterpartyID: "connection-3", State: handshakeStateOpen},
+			expectedOnA:  true,
+		},
+		{
+			name:         "recorded end which no longer exists starts a new handshake",
+			srcID:        "connection-4",
+			expectedStep: handshakeStepInit,
+			expectedOnA:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step, end, onA := pathHandshakeStep(tc.src, tc.dst, tc.srcID, tc.dstID)
+			require.Equal(t, tc.expectedStep, step)
+			require.Equal(t, tc.expectedEnd, end)
+			require.Equal(t, tc.expectedOnA, onA)
+		})
+	}
+}
+
+func TestConnectionHandshakeStep(t *testing.T) {
+	ibcPrefix := commitmenttypes.NewMerklePrefix([]byte("ibc"))
+	otherPrefix := commitmenttypes.NewMerklePrefix([]byte("other"))
+
+	connection := func(id, clientID, counterpartyClientID, counterpartyID string, prefix commitmenttypes.MerklePrefix, state conntypes.State) *conntypes.IdentifiedConnection {
+		return &conntypes.IdentifiedConnection{
+			Id:       id,
+			ClientId: clientID,
+			State:    state,
+			Counterparty: conntypes.Counterparty{
+				ClientId:     counterpartyClientID,
+				ConnectionId: counterpartyID,
+				Prefix:       prefix,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		srcConns, dstConns []*conntypes.IdentifiedConnection
+		override           bool
+		expectedStep       handshakeStep
+		expectedID         string
+	}{
+		{
+			name: "matching open connection is reused",
+			srcConns: []*conntypes.IdentifiedConnection{
+				connection("connection-1", "07-tendermint-0", "07-tendermint-5", "connection-3", ibcPrefix, conntypes.OPEN),
+			},
+			dstConns: []*conntypes.IdentifiedConnection{
+				connection("connection-3", "07-tendermint-5", "07-tendermint-0", "connection-1", ibcPrefix, conntypes.OPEN),
+			},
+			expectedStep: handshakeStepOpen,
+			expectedID:   "connection-1",
+		},
+		{
+			name: "connection with another commitment prefix is skipped",
+			srcConns: []*conntypes.IdentifiedConnection{
+				connection("connection-1", "07-tendermint-0", "07-tendermint-5", "connection-3", otherPrefix, conntypes.OPEN),
+			},
+			dstConns: []*conntypes.IdentifiedConnection{
+				connection("connection-3", "07-tendermint-5", "07-tendermint-0", "connection-1", ibcPrefix, conntypes.OPEN),
+			},
+			expectedStep: handshakeStepInit,
+		},
+		{
+			name: "connection to another client is skipped",
+			srcConns: []*conntypes.IdentifiedConnection{
+				connection("connection-1", "07-tendermint-0", "07-tendermint-6", "connection-3", ibcPrefix, conntypes.OPEN),
+			},
+			dstConns: []*conntypes.IdentifiedConnection{
+				connection("connection-3", "07-tendermint-5", "07-tendermint-0", "connection-1", ibcPrefix, conntypes.OPEN),
+			},
+			expectedStep: handshakeStepInit,
+		},
+		{
+			name: "override forces a new handshake",
+			srcConns: []*conntypes.IdentifiedConnection{
+				connection("connection-1", "07-tendermint-0", "07-tendermint-5", "connection-3", ibcPrefix, conntypes.OPEN),
+			},
+			dstConns: []*conntypes.IdentifiedConnection{
+				connection("connection-3", "07-tendermint-5", "07-tendermint-0", "connection-1", ibcPrefix, conntypes.OPEN),
+			},
+			override:     true,
+			expectedStep: handshakeStepInit,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srcConns := matchingConnections(tc.srcConns, "07-tendermint-5", ibcPrefix)
+			dstConns := matchingConnections(tc.dstConns, "07-tendermint-0", ibcPrefix)
+
+			step, end, _ := connectionHandshakeStep(srcConns, dstConns, "", "", tc.override)
+			require.Equal(t, tc.expectedStep, step)
+			require.Equal(t, tc.expectedID, end.ID)
+		})
+	}
+}
+
+func TestHandshakeReservation(t *testing.T) {
+	reserved := newHandshakeReservation()
+	require.True(t, reserved.reserve("chain-a", "connection-0"))
+	require.True(t, reserved.reserve("chain-a", "connection-0"))
+	require.False(t, reserved.reserve("chain-a", "connection-1"))
+	require.True(t, reserved.reserve("chain-b", "connection-1"))
+	require.Equal(t, "connection-0", reserved.id("chain-a"))
+	require.Empty(t, reserved.id("chain-c"))
+}
+
+func TestVerifyConnectionEnd(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	src := newMemoryChain(ctx, t, "chain-a")
+	dst := newMemoryChain(ctx, t, "chain-b")
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(ctx, 1))
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(ctx, 1))
+
+	srcClientID, dstClientID, err := src.CreateClients(ctx, dst, true, true, false, 0, 0, 85, light.DefaultTrustLevel, "")
+	require.NoError(t, err)
+	src.PathEnd.ClientID, dst.PathEnd.ClientID = srcClientID, dstClientID
+
+	init, err := src.ChainProvider.MsgConnectionOpenInit(provider.ConnectionInfo{
+		ClientID:                     srcClientID,
+		CounterpartyClientID:         dstClientID,
+		CounterpartyCommitmentPrefix: dst.ChainProvider.CommitmentPrefix(),
+	}, provider.ConnectionProof{})
+	require.NoError(t, err)
+	_, success, err := src.ChainProvider.SendMessage(ctx, init, "")
+	require.NoError(t, err)
+	require.True(t, success)
+
+	require.NoError(t, verifyConnectionEnd(ctx, src, dst, "connection-0", ""))
+	require.ErrorContains(t, verifyConnectionEnd(ctx, src, dst, "connection-0", "connection-3"), "counterparty connection")
+	require.ErrorContains(t, verifyConnectionEnd(ctx, src, dst, "connection-1", ""), "failed to query")
+
+	// a connection of the same client to another counterparty client is not the one of the handshake.
+	dst.PathEnd.ClientID = "07-tendermint-7"
+	require.ErrorContains(t, verifyConnectionEnd(ctx, src, dst, "connection-0", ""), "counterparty client")
+}
+
+func TestGovOpenedChannel(t *testing.T) {
+	channels := []*chantypes.IdentifiedChannel{
+		{ChannelId: "channel-1", State: chantypes.INIT},
+		{ChannelId: "channel-2", State: chantypes.INIT, Counterparty: chantypes.Counterparty{ChannelId: "channel-5"}},
+		{ChannelId: "channel-3", State: chantypes.OPEN, Counterparty: chantypes.Counterparty{ChannelId: "channel-6"}},
+	}
+
+	require.Equal(t, "channel-1", govOpenedChannel(channels, map[string]bool{}))
+	require.Empty(t, govOpenedChannel(channels, map[string]bool{"channel-1": true}))
+
+	channels = append(channels, &chantypes.IdentifiedChannel{ChannelId: "channel-4", State: chantypes.INIT})
+	require.Equal(t, "channel-4", govOpenedChannel(channels, map[string]bool{"channel-1": true}))
+}
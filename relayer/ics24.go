@@ -2,8 +2,10 @@ package relayer
 
 import (
 	"fmt"
+	"slices"
 
 	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 )
 
 // Vclient validates the client identifier in the path
@@ -16,6 +18,35 @@ func (pe *PathEnd) Vconn() error {
 	return host.ConnectionIdentifierValidator(pe.ConnectionID)
 }
 
+// Vhops validates the connection hops in the path
+func (pe *PathEnd) Vhops() error {
+	for _, hop := range pe.ConnectionHops {
+		if err := host.ConnectionIdentifierValidator(hop); err != nil {
+			return fmt.Errorf("invalid connection hop %s: %w", hop, err)
+		}
+	}
+	if len(pe.ConnectionHops) > 0 && pe.ConnectionID != "" && pe.ConnectionHops[0] != pe.ConnectionID {
+		return fmt.Errorf("first connection hop %s must be the connection %s", pe.ConnectionHops[0], pe.ConnectionID)
+	}
+	return nil
+}
+
+// VclientType validates the client type in the path
+func (pe *PathEnd) VclientType() error {
+	if pe.ClientType == "" {
+		return nil
+	}
+	known := knownClientTypes()
+	if !slices.Contains(known, pe.ClientType) {
+		return fmt.Errorf("invalid client-type %s, supports one of: %v", pe.ClientType, known)
+	}
+	if pe.ClientType == ClientTypeLocalhost && pe.ClientID != "" && pe.ClientID != ibcexported.LocalhostClientID {
+		return fmt.Errorf("client-type %s requires the %s client, got %s",
+			ClientTypeLocalhost, ibcexported.LocalhostClientID, pe.ClientID)
+	}
+	return nil
+}
+
 func (pe PathEnd) String() string {
 	return fmt.Sprintf("%s:cl(%s):co(%s)", pe.ChainID, pe.ClientID, pe.ConnectionID)
 }
@@ -55,7 +86,12 @@ func (pe *PathEnd) ValidateFull() error {
 			return err
 		}
 	}
-	return nil
+
+	if err := pe.VclientType(); err != nil {
+		return err
+	}
+
+	return pe.Vhops()
 }
 
 // ErrPathNotSet returns information what identifiers are needed to relay
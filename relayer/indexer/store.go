@@ -0,0 +1,343 @@
+// Package indexer stores the IBC packet events observed on chains in a local database, so that lookups of
+// packets and acknowledgements, e.g. during flushes, can be served without repeated tx_search queries
+// against the RPC nodes of the chains.
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/localdb"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// DefaultDBFile is the name of the index database within the relayer home directory.
+const DefaultDBFile = "index.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS packet_events (
+	chain_id                TEXT    NOT NULL,
+	event_type              TEXT    NOT NULL,
+	channel_id              TEXT    NOT NULL,
+	port_id                 TEXT    NOT NULL,
+	sequence                INTEGER NOT NULL,
+	height                  INTEGER NOT NULL,
+	src_port                TEXT    NOT NULL,
+	src_channel             TEXT    NOT NULL,
+	dst_port                TEXT    NOT NULL,
+	dst_channel             TEXT    NOT NULL,
+	channel_order           TEXT    NOT NULL,
+	data                    BLOB,
+	timeout_revision_number INTEGER NOT NULL,
+	timeout_revision_height INTEGER NOT NULL,
+	timeout_timestamp       INTEGER NOT NULL,
+	ack                     BLOB,
+	incentivized            INTEGER NOT NULL,
+	PRIMARY KEY (chain_id, event_type, channel_id, port_id, sequence)
+);
+CREATE INDEX IF NOT EXISTS packet_events_height ON packet_events (chain_id, event_type, height);
+CREATE INDEX IF NOT EXISTS packet_events_chain_height ON packet_events (chain_id, height);
+`
+
+// pruneInterval is how many blocks of a chain are indexed between prunings of its events outside the retention.
+const pruneInterval = 100
+
+const packetColumns = `height, sequence, src_port, src_channel, dst_port, dst_channel, channel_order, data,
+	timeout_revision_number, timeout_revision_height, timeout_timestamp, ack, incentivized`
+
+// Event is a packet event, e.g. send_packet or write_acknowledgement, observed on a chain.
+type Event struct {
+	EventType string
+	Packet    provider.PacketInfo
+}
+
+// Channel is a channel and port on a chain.
+type Channel struct {
+	ChannelID string
+	PortID    string
+}
+
+// Store is an index of packet events backed by a local SQLite database.
+type Store struct {
+	db *sql.DB
+
+	// retention is how many blocks of events of each chain are kept, all of them if zero.
+	retention uint64
+
+	mu         sync.Mutex
+	lastPruned map[string]uint64
+}
+
+// OpenStore opens the SQLite database at path, creating it and its schema if necessary.
+func OpenStore(path string) (*Store, error) {
+	db, err := localdb.Open(path, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index database %s: %w", path, err)
+	}
+
+	return &Store{db: db, lastPruned: make(map[string]uint64)}, nil
+}
+
+// SetRetention sets how many of the most recent blocks of events of each chain are kept as events are indexed.
+// Older events are pruned every pruneInterval blocks. All events are kept if blocks is zero.
+func (s *Store) SetRetention(blocks uint64) {
+	s.retention = blocks
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// chainEnd returns the channel and port on the chain which emits events of eventType for packet p.
+func chainEnd(eventType string, p provider.PacketInfo) (channelID, portID string) {
+	switch eventType {
+	case chantypes.EventTypeRecvPacket, chantypes.EventTypeWriteAck:
+		return p.DestChannel, p.DestPort
+	default:
+		return p.SourceChannel, p.SourcePort
+	}
+}
+
+// Index stores the packet events observed on the chain, replacing previously stored events for the same packets.
+func (s *Store) Index(ctx context.Context, chainID string, events []Event) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to index packet events on chain %s: %w", chainID, err)
+	}
+	defer tx.Rollback()
+
+	for _, e := range events {
+		p := e.Packet
+		channelID, portID := chainEnd(e.EventType, p)
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO packet_events (chain_id, event_type, channel_id, port_id, `+packetColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			chainID,
+			e.EventType,
+			channelID,
+			portID,
+			p.Height,
+			p.Sequence,
+			p.SourcePort,
+			p.SourceChannel,
+			p.DestPort,
+			p.DestChannel,
+			p.ChannelOrder,
+			p.Data,
+			p.TimeoutHeight.RevisionNumber,
+			p.TimeoutHeight.RevisionHeight,
+			p.TimeoutTimestamp,
+			p.Ack,
+			p.Incentivized,
+		); err != nil {
+			return fmt.Errorf("failed to index %s event for packet %d on chain %s: %w", e.EventType, p.Sequence, chainID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to index packet events on chain %s: %w", chainID, err)
+	}
+
+	if s.retention == 0 {
+		return nil
+	}
+	var height uint64
+	for _, e := range events {
+		height = max(height, e.Packet.Height)
+	}
+	s.mu.Lock()
+	due := height >= s.lastPruned[chainID]+pruneInterval
+	if due {
+		s.lastPruned[chainID] = height
+	}
+	s.mu.Unlock()
+	if !due || height <= s.retention {
+		return nil
+	}
+	return s.Prune(ctx, chainID, height-s.retention)
+}
+
+// Prune deletes the events indexed for the chain below the given height.
+func (s *Store) Prune(ctx context.Context, chainID string, belowHeight uint64) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM packet_events WHERE chain_id = ? AND height < ?`, chainID, belowHeight,
+	); err != nil {
+		return fmt.Errorf("failed to prune indexed events of chain %s below height %d: %w", chainID, belowHeight, err)
+	}
+	return nil
+}
+
+// Packet returns the packet of the event of eventType for the packet with the given sequence on the channel and port
+// of the chain, and false if no such event has been indexed.
+func (s *Store) Packet(
+	ctx context.Context,
+	chainID, eventType, channelID, portID string,
+	sequence uint64,
+) (provider.PacketInfo, bool, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+packetColumns+` FROM packet_events
+		WHERE chain_id = ? AND event_type = ? AND channel_id = ? AND port_id = ? AND sequence = ?`,
+		chainID, eventType, channelID, portID, sequence,
+	)
+	p, err := scanPacket(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return provider.PacketInfo{}, false, nil
+	}
+	if err != nil {
+		return provider.PacketInfo{}, false, fmt.Errorf("failed to query indexed %s event for packet %d on chain %s: %w", eventType, sequence, chainID, err)
+	}
+	return p, true, nil
+}
+
+// Packets returns the given page of the packets of the indexed events of eventType on the channels of the chain,
+// most recent first. The events are those of all channels if channels is nil.
+func (s *Store) Packets(
+	ctx context.Context,
+	chainID, eventType string,
+	channels []Channel,
+	page, limit int,
+) ([]provider.PacketInfo, error) {
+	if page < 1 {
+		page = 1
+	}
+	if channels != nil && len(channels) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + packetColumns + ` FROM packet_events WHERE chain_id = ? AND event_type = ?`
+	args := []any{chainID, eventType}
+	if channels != nil {
+		// the channels are filtered before paginating, so that every page is full.
+		match := make([]string, len(channels))
+		for i, ch := range channels {
+			match[i] = `(channel_id = ? AND port_id = ?)`
+			args = append(args, ch.ChannelID, ch.PortID)
+		}
+		query += ` AND (` + strings.Join(match, " OR ") + `)`
+	}
+	query += ` ORDER BY height DESC, sequence DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexed %s events on chain %s: %w", eventType, chainID, err)
+	}
+	defer rows.Close()
+
+	var packets []provider.PacketInfo
+	for rows.Next() {
+		p, err := scanPacket(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan indexed %s event on chain %s: %w", eventType, chainID, err)
+		}
+		packets = append(packets, p)
+	}
+
+	return packets, rows.Err()
+}
+
+// UnrelayedPackets returns the sequences of the packets indexed as sent on the channel of the chain, which are not
+// indexed as received on the counterparty chain, nor as acknowledged or timed out on the chain, in order.
+// The result is only as complete as the events indexed for both chains.
+func (s *Store) UnrelayedPackets(ctx context.Context, chainID, counterpartyChainID string, ch Channel) ([]uint64, error) {
+	return s.sequences(ctx,
+		`SELECT s.sequence FROM packet_events s
+		WHERE s.chain_id = ? AND s.event_type = ? AND s.channel_id = ? AND s.port_id = ?
+		AND NOT EXISTS (SELECT 1 FROM packet_events r
+			WHERE r.chain_id = ? AND r.event_type = ?
+			AND r.channel_id = s.dst_channel AND r.port_id = s.dst_port AND r.sequence = s.sequence)
+		AND NOT EXISTS (SELECT 1 FROM packet_events d
+			WHERE d.chain_id = s.chain_id AND d.event_type IN (?, ?)
+			AND d.channel_id = s.channel_id AND d.port_id = s.port_id AND d.sequence = s.sequence)
+		ORDER BY s.sequence`,
+		chainID, chantypes.EventTypeSendPacket, ch.ChannelID, ch.PortID,
+		counterpartyChainID, chantypes.EventTypeRecvPacket,
+		chantypes.EventTypeAcknowledgePacket, chantypes.EventTypeTimeoutPacket,
+	)
+}
+
+// UnrelayedAcks returns the sequences of the packets received on the channel of the chain whose acknowledgement is
+// indexed as written on the chain, but not as acknowledged on the counterparty chain which sent them, in order.
+// The result is only as complete as the events indexed for both chains.
+func (s *Store) UnrelayedAcks(ctx context.Context, chainID, counterpartyChainID string, ch Channel) ([]uint64, error) {
+	return s.sequences(ctx,
+		`SELECT w.sequence FROM packet_events w
+		WHERE w.chain_id = ? AND w.event_type = ? AND w.channel_id = ? AND w.port_id = ?
+		AND NOT EXISTS (SELECT 1 FROM packet_events a
+			WHERE a.chain_id = ? AND a.event_type = ?
+			AND a.channel_id = w.src_channel AND a.port_id = w.src_port AND a.sequence = w.sequence)
+		ORDER BY w.sequence`,
+		chainID, chantypes.EventTypeWriteAck, ch.ChannelID, ch.PortID,
+		counterpartyChainID, chantypes.EventTypeAcknowledgePacket,
+	)
+}
+
+// sequences returns the sequences selected by query.
+func (s *Store) sequences(ctx context.Context, query string, args ...any) ([]uint64, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexed sequences: %w", err)
+	}
+	defer rows.Close()
+
+	seqs := []uint64{}
+	for rows.Next() {
+		var seq uint64
+		if err := rows.Scan(&seq); err != nil {
+			return nil, fmt.Errorf("failed to scan indexed sequence: %w", err)
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, rows.Err()
+}
+
+func scanPacket(row interface{ Scan(dest ...any) error }) (provider.PacketInfo, error) {
+	var p provider.PacketInfo
+	err := row.Scan(
+		&p.Height,
+		&p.Sequence,
+		&p.SourcePort,
+		&p.SourceChannel,
+		&p.DestPort,
+		&p.DestChannel,
+		&p.ChannelOrder,
+		&p.Data,
+		&p.TimeoutHeight.RevisionNumber,
+		&p.TimeoutHeight.RevisionHeight,
+		&p.TimeoutTimestamp,
+		&p.Ack,
+		&p.Incentivized,
+	)
+	return p, err
+}
+
+// RenameChain moves the events indexed for the chain with oldChainID to newChainID, e.g. after the chain relaunched
+// with a new chain-id. Events already indexed for newChainID are kept over those of oldChainID for the same packets.
+func (s *Store) RenameChain(ctx context.Context, oldChainID, newChainID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to rename indexed events of chain %s to %s: %w", oldChainID, newChainID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE OR IGNORE packet_events SET chain_id = ? WHERE chain_id = ?`, newChainID, oldChainID,
+	); err != nil {
+		return fmt.Errorf("failed to rename indexed events of chain %s to %s: %w", oldChainID, newChainID, err)
+	}
+	// the remaining events were ignored in favor of those already indexed for newChainID.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM packet_events WHERE chain_id = ?`, oldChainID); err != nil {
+		return fmt.Errorf("failed to rename indexed events of chain %s to %s: %w", oldChainID, newChainID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to rename indexed events of chain %s to %s: %w", oldChainID, newChainID, err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package indexer_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorePackets(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := indexer.OpenStore(filepath.Join(t.TempDir(), indexer.DefaultDBFile))
+	require.NoError(t, err)
+	defer s.Close()
+
+	packet := func(height, seq uint64) provider.PacketInfo {
+		return provider.PacketInfo{
+			Height:           height,
+			Sequence:         seq,
+			SourcePort:       "transfer",
+			SourceChannel:    "channel-0",
+			DestPort:         "transfer",
+			DestChannel:      "channel-5",
+			ChannelOrder:     chantypes.UNORDERED.String(),
+			Data:             []byte(`{"amount":"1"}`),
+			TimeoutHeight:    clienttypes.NewHeight(1, 500),
+			TimeoutTimestamp: 1700000000000000000,
+		}
+	}
+
+	sent := packet(10, 1)
+	sent.Incentivized = true
+	acked := packet(12, 1)
+	acked.Ack = []byte(`{"result":"AQ=="}`)
+
+	require.NoError(t, s.Index(ctx, "chain-a", []indexer.Event{
+		{EventType: chantypes.EventTypeSendPacket, Packet: sent},
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(11, 2)},
+	}))
+	require.NoError(t, s.Index(ctx, "chain-b", []indexer.Event{
+		{EventType: chantypes.EventTypeWriteAck, Packet: acked},
+	}))
+
+	// send_packet events are looked up by the source channel, write_acknowledgement events by the destination channel.
+	pi, ok, err := s.Packet(ctx, "chain-a", chantypes.EventTypeSendPacket, "channel-0", "transfer", 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, sent, pi)
+
+	pi, ok, err = s.Packet(ctx, "chain-b", chantypes.EventTypeWriteAck, "channel-5", "transfer", 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, acked, pi)
+
+	_, ok, err = s.Packet(ctx, "chain-b", chantypes.EventTypeSendPacket, "channel-0", "transfer", 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	packets, err := s.Packets(ctx, "chain-a", chantypes.EventTypeSendPacket, nil, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	require.Equal(t, uint64(2), packets[0].Sequence)
+
+	packets, err = s.Packets(ctx, "chain-a", chantypes.EventTypeSendPacket, nil, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	require.Equal(t, uint64(1), packets[0].Sequence)
+
+	// the packets of other channels do not take up the page.
+	require.NoError(t, s.Index(ctx, "chain-a", []indexer.Event{
+		{EventType: chantypes.EventTypeSendPacket, Packet: provider.PacketInfo{
+			Height: 13, Sequence: 1, SourcePort: "transfer", SourceChannel: "channel-1",
+		}},
+	}))
+	packets, err = s.Packets(ctx, "chain-a", chantypes.EventTypeSendPacket,
+		[]indexer.Channel{{ChannelID: "channel-0", PortID: "transfer"}}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	require.Equal(t, "channel-0", packets[0].SourceChannel)
+	require.Equal(t, uint64(2), packets[0].Sequence)
+
+	packets, err = s.Packets(ctx, "chain-a", chantypes.EventTypeSendPacket, []indexer.Channel{}, 1, 10)
+	require.NoError(t, err)
+	require.Empty(t, packets)
+}
+
+func TestStoreUnrelayed(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := indexer.OpenStore(filepath.Join(t.TempDir(), indexer.DefaultDBFile))
+	require.NoError(t, err)
+	defer s.Close()
+
+	packet := func(height, seq uint64) provider.PacketInfo {
+		return provider.PacketInfo{
+			Height:        height,
+			Sequence:      seq,
+			SourcePort:    "transfer",
+			SourceChannel: "channel-0",
+			DestPort:      "transfer",
+			DestChannel:   "channel-5",
+		}
+	}
+
+	// 1 is received and acknowledged, 2 is received, 3 timed out and 4 is not relayed.
+	require.NoError(t, s.Index(ctx, "chain-a", []indexer.Event{
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(10, 1)},
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(10, 2)},
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(10, 3)},
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(10, 4)},
+		{EventType: chantypes.EventTypeAcknowledgePacket, Packet: packet(12, 1)},
+		{EventType: chantypes.EventTypeTimeoutPacket, Packet: packet(12, 3)},
+	}))
+	require.NoError(t, s.Index(ctx, "chain-b", []indexer.Event{
+		{EventType: chantypes.EventTypeRecvPacket, Packet: packet(20, 1)},
+		{EventType: chantypes.EventTypeRecvPacket, Packet: packet(20, 2)},
+		{EventType: chantypes.EventTypeWriteAck, Packet: packet(20, 1)},
+		{EventType: chantypes.EventTypeWriteAck, Packet: packet(20, 2)},
+	}))
+
+	seqs, err := s.UnrelayedPackets(ctx, "chain-a", "chain-b", indexer.Channel{ChannelID: "channel-0", PortID: "transfer"})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4}, seqs)
+
+	seqs, err = s.UnrelayedAcks(ctx, "chain-b", "chain-a", indexer.Channel{ChannelID: "channel-5", PortID: "transfer"})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, seqs)
+}
+
+func TestStoreRetention(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := indexer.OpenStore(filepath.Join(t.TempDir(), indexer.DefaultDBFile))
+	require.NoError(t, err)
+	defer s.Close()
+	s.SetRetention(50)
+
+	index := func(chainID string, height uint64) {
+		require.NoError(t, s.Index(ctx, chainID, []indexer.Event{
+			{EventType: chantypes.EventTypeSendPacket, Packet: provider.PacketInfo{
+				Height: height, Sequence: height, SourcePort: "transfer", SourceChannel: "channel-0",
+			}},
+		}))
+	}
+
+	index("chain-a", 10)
+	index("chain-b", 10)
+	index("chain-a", 60)
+	index("chain-a", 120)
+
+	// only the events of chain-a older than the retention are pruned.
+	packets, err := s.Packets(ctx, "chain-a", chantypes.EventTypeSendPacket, nil, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	require.Equal(t, uint64(120), packets[0].Height)
+
+	packets, err = s.Packets(ctx, "chain-b", chantypes.EventTypeSendPacket, nil, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+}
+
+func TestStoreRenameChain(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := indexer.OpenStore(filepath.Join(t.TempDir(), indexer.DefaultDBFile))
+	require.NoError(t, err)
+	defer s.Close()
+
+	packet := func(height, seq uint64) provider.PacketInfo {
+		return provider.PacketInfo{
+			Height:        height,
+			Sequence:      seq,
+			SourcePort:    "transfer",
+			SourceChannel: "channel-0",
+			DestPort:      "transfer",
+			DestChannel:   "channel-5",
+		}
+	}
+
+	require.NoError(t, s.Index(ctx, "chain-a", []indexer.Event{
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(10, 1)},
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(11, 2)},
+	}))
+	require.NoError(t, s.Index(ctx, "chain-a-2", []indexer.Event{
+		{EventType: chantypes.EventTypeSendPacket, Packet: packet(20, 2)},
+	}))
+
+	require.NoError(t, s.RenameChain(ctx, "chain-a", "chain-a-2"))
+
+	packets, err := s.Packets(ctx, "chain-a", chantypes.EventTypeSendPacket, nil, 1, 10)
+	require.NoError(t, err)
+	require.Empty(t, packets)
+
+	// the event already indexed for the new chain-id is kept.
+	packets, err = s.Packets(ctx, "chain-a-2", chantypes.EventTypeSendPacket, nil, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, packets, 2)
+	require.Equal(t, uint64(20), packets[0].Height)
+	require.Equal(t, uint64(10), packets[1].Height)
+}
@@ -0,0 +1,157 @@
+package relayer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// Client types of path ends, set with client-type.
+const (
+	ClientTypeTendermint  = "tendermint"
+	ClientTypeWasm        = "wasm"
+	ClientTypeSolomachine = "solomachine"
+	ClientTypeLocalhost   = "localhost"
+)
+
+// LightClient builds the states and messages of a type of light client on a chain which tracks a counterparty chain.
+// Implementations are registered by client type with RegisterLightClient.
+type LightClient interface {
+	// NewClientState returns the client state and the consensus state of a new client tracking the counterparty
+	// chain as of its header.
+	NewClientState(
+		counterparty provider.ChainProvider,
+		header provider.IBCHeader,
+		params ClientStateParams,
+	) (ibcexported.ClientState, ibcexported.ConsensusState, error)
+
+	// ClientMessage returns the message updating a client tracking the counterparty chain from its trusted header,
+	// at trustedHeight, to its latest header. The path processor updates clients with it as well.
+	processor.ClientMessageBuilder
+}
+
+// ClientStateParams are the parameters of a new client.
+type ClientStateParams struct {
+	// ChainID is the chain ID of the counterparty chain tracked by the client.
+	ChainID string
+
+	TrustingPeriod  time.Duration
+	UnbondingPeriod time.Duration
+	MaxClockDrift   time.Duration
+
+	AllowUpdateAfterExpiry       bool
+	AllowUpdateAfterMisbehaviour bool
+
+	// TrustLevel overrides the default trust level of the client if its denominator is not zero.
+	TrustLevel cmtmath.Fraction
+}
+
+var (
+	lightClientsMu sync.RWMutex
+	lightClients   = map[string]LightClient{
+		ClientTypeTendermint: tendermintLightClient{},
+	}
+)
+
+// RegisterLightClient registers the implementation of the light clients of clientType, replacing any
+// registered before, e.g. for wasm clients, whose client states depend on the contract they are backed by.
+func RegisterLightClient(clientType string, lc LightClient) {
+	lightClientsMu.Lock()
+	defer lightClientsMu.Unlock()
+	lightClients[clientType] = lc
+}
+
+// lightClient returns the implementation registered for clientType, tendermint if empty.
+func lightClient(clientType string) (LightClient, error) {
+	if clientType == "" {
+		clientType = ClientTypeTendermint
+	}
+
+	lightClientsMu.RLock()
+	defer lightClientsMu.RUnlock()
+	if lc, ok := lightClients[clientType]; ok {
+		return lc, nil
+	}
+
+	switch clientType {
+	case ClientTypeLocalhost:
+		return nil, fmt.Errorf("%s clients track the chain they are on and are neither created nor updated",
+			ibcexported.LocalhostClientID)
+	case ClientTypeSolomachine:
+		return nil, fmt.Errorf("solomachine clients are created and updated with the signatures of their signer, " +
+			"set the client-id of the path end to an existing client")
+	}
+	return nil, fmt.Errorf("no light client registered for client-type %s", clientType)
+}
+
+// knownClientTypes returns the client types which may be set on path ends, sorted. These are the client types with a
+// registered light client, and the solomachine and localhost clients, which are used as they exist on chain.
+// Wasm clients may only be set once a light client is registered for them, see RegisterLightClient.
+func knownClientTypes() []string {
+	types := map[string]struct{}{
+		ClientTypeSolomachine: {},
+		ClientTypeLocalhost:   {},
+	}
+	lightClientsMu.RLock()
+	for clientType := range lightClients {
+		types[clientType] = struct{}{}
+	}
+	lightClientsMu.RUnlock()
+
+	known := make([]string, 0, len(types))
+	for clientType := range types {
+		known = append(known, clientType)
+	}
+	sort.Strings(known)
+	return known
+}
+
+// processorClientMessages returns the light client which builds the client messages of the path processor updating
+// the client of the path end, or nil for client types which are not updated with client messages built by
+// the relayer, e.g. localhost clients.
+func (pe *PathEnd) processorClientMessages() processor.ClientMessageBuilder {
+	lc, err := lightClient(pe.clientType())
+	if err != nil {
+		return nil
+	}
+	return lc
+}
+
+// tendermintLightClient builds 07-tendermint clients through the chain providers of the tracked chains.
+type tendermintLightClient struct{}
+
+func (tendermintLightClient) NewClientState(
+	counterparty provider.ChainProvider,
+	header provider.IBCHeader,
+	params ClientStateParams,
+) (ibcexported.ClientState, ibcexported.ConsensusState, error) {
+	clientState, err := counterparty.NewClientState(
+		params.ChainID, header,
+		params.TrustingPeriod, params.UnbondingPeriod, params.MaxClockDrift,
+		params.AllowUpdateAfterExpiry, params.AllowUpdateAfterMisbehaviour,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tmClientState, ok := clientState.(*tmclient.ClientState); ok && params.TrustLevel.Denominator != 0 {
+		tmClientState.TrustLevel = tmclient.NewFractionFromTm(params.TrustLevel)
+	}
+	return clientState, header.ConsensusState(), nil
+}
+
+func (tendermintLightClient) ClientMessage(
+	counterparty provider.ChainProvider,
+	latestHeader provider.IBCHeader,
+	trustedHeight clienttypes.Height,
+	trustedHeader provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	return counterparty.MsgUpdateClientHeader(latestHeader, trustedHeight, trustedHeader)
+}
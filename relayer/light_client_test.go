@@ -0,0 +1,61 @@
+package relayer
+
+import (
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+type testLightClient struct{}
+
+func (testLightClient) NewClientState(
+	provider.ChainProvider,
+	provider.IBCHeader,
+	ClientStateParams,
+) (ibcexported.ClientState, ibcexported.ConsensusState, error) {
+	return nil, nil, nil
+}
+
+func (testLightClient) ClientMessage(
+	provider.ChainProvider,
+	provider.IBCHeader,
+	clienttypes.Height,
+	provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	return nil, nil
+}
+
+func TestLightClientRegistry(t *testing.T) {
+	lc, err := lightClient("")
+	require.NoError(t, err)
+	require.IsType(t, tendermintLightClient{}, lc)
+
+	_, err = lightClient(ClientTypeLocalhost)
+	require.ErrorContains(t, err, "neither created nor updated")
+	_, err = lightClient(ClientTypeSolomachine)
+	require.ErrorContains(t, err, "set the client-id of the path end")
+	_, err = lightClient(ClientTypeWasm)
+	require.EqualError(t, err, "no light client registered for client-type wasm")
+
+	// client types without a registered light client are rejected, except those used as they exist on chain.
+	require.ErrorContains(t, (&PathEnd{ClientType: ClientTypeWasm}).VclientType(), "invalid client-type wasm")
+	require.NoError(t, (&PathEnd{ClientType: ClientTypeSolomachine}).VclientType())
+	require.Nil(t, (&PathEnd{ClientType: ClientTypeSolomachine}).processorClientMessages())
+	require.IsType(t, tendermintLightClient{}, (&PathEnd{}).processorClientMessages())
+
+	RegisterLightClient("test", testLightClient{})
+	t.Cleanup(func() {
+		lightClientsMu.Lock()
+		delete(lightClients, "test")
+		lightClientsMu.Unlock()
+	})
+
+	lc, err = lightClient("test")
+	require.NoError(t, err)
+	require.IsType(t, testLightClient{}, lc)
+	require.NoError(t, (&PathEnd{ClientType: "test"}).VclientType())
+	require.IsType(t, testLightClient{}, (&PathEnd{ClientType: "test"}).processorClientMessages())
+}
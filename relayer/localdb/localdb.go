@@ -0,0 +1,32 @@
+// Package localdb opens the SQLite databases which the relayer keeps in its home directory,
+// e.g. the accounting, dedup and index databases.
+package localdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	// registers the pure go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// A database may be written by several processors of a running relayer while it is read by rly query commands,
+// wait for locks held by other connections or processes rather than failing immediately.
+const dsnParams = "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+// Open opens the SQLite database at path, creating it and the tables and indexes of schema if necessary.
+func Open(path, schema string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path+dsnParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite only supports a single writer, serialize access from concurrent processors.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,72 @@
+package localdb_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/localdb"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+CREATE TABLE IF NOT EXISTS kv (
+	k TEXT PRIMARY KEY,
+	v TEXT NOT NULL
+);
+`
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := localdb.Open(path, testSchema)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO kv (k, v) VALUES ('a', 'b')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// the schema is only created if necessary, rows are kept.
+	db, err = localdb.Open(path, testSchema)
+	require.NoError(t, err)
+	defer db.Close()
+	var v string
+	require.NoError(t, db.QueryRow(`SELECT v FROM kv WHERE k = 'a'`).Scan(&v))
+	require.Equal(t, "b", v)
+
+	var journalMode string
+	require.NoError(t, db.QueryRow(`PRAGMA journal_mode`).Scan(&journalMode))
+	require.Equal(t, "wal", journalMode)
+
+	_, err = localdb.Open(filepath.Join(t.TempDir(), "invalid.db"), "CREATE TABLE (")
+	require.ErrorContains(t, err, "failed to initialize schema")
+}
+
+func TestOpenInMemorySpendReport(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := accounting.OpenStore(":memory:")
+	require.NoError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	uatom := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewCoin("uatom", sdkmath.NewInt(amount)))
+	}
+	for _, tx := range []accounting.Tx{
+		{Time: now.Add(-3 * time.Hour), ChainID: "cosmoshub-4", PathName: "hub-osmo", TxHash: "A", GasUsed: 100, Fees: uatom(10), MsgTypes: []string{"/ibc.core.client.v1.MsgUpdateClient", "/ibc.core.channel.v1.MsgRecvPacket"}},
+		{Time: now.Add(-2 * time.Hour), ChainID: "cosmoshub-4", PathName: "hub-osmo", TxHash: "B", GasUsed: 80, Fees: uatom(8), MsgTypes: []string{"/ibc.core.channel.v1.MsgAcknowledgement"}},
+		{Time: now.Add(-time.Hour), ChainID: "cosmoshub-4", PathName: "hub-osmo", TxHash: "C", Code: 11, GasUsed: 40, Fees: uatom(4), MsgTypes: []string{"/ibc.core.channel.v1.MsgTimeout"}},
+	} {
+		require.NoError(t, s.Record(ctx, tx))
+	}
+
+	report, err := s.SpendReport(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []accounting.PathSpend{
+		{PathName: "hub-osmo", ChainID: "cosmoshub-4", Txs: 3, Failed: 1, Msgs: 4, GasUsed: 220, Fees: uatom(22)},
+	}, report.Paths)
+}
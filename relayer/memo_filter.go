@@ -0,0 +1,43 @@
+package relayer
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// MemoFilter refuses to relay the ICS-20 transfers on a path whose memo matches any of its patterns,
+// e.g. to avoid paying the fees of waves of inscription spam. The size of memos is limited by the ics20-memo-limit.
+type MemoFilter struct {
+	// DenyPatterns are regular expressions, in the syntax of the Go regexp package, matched against the memo.
+	DenyPatterns []string `yaml:"deny-patterns,omitempty" json:"deny-patterns,omitempty"`
+}
+
+// Validate checks that the patterns of the MemoFilter are valid regular expressions.
+func (mf *MemoFilter) Validate() error {
+	_, err := mf.denyPatterns()
+	return err
+}
+
+func (mf *MemoFilter) denyPatterns() ([]*regexp.Regexp, error) {
+	if mf == nil || len(mf.DenyPatterns) == 0 {
+		return nil, nil
+	}
+	patterns := make([]*regexp.Regexp, len(mf.DenyPatterns))
+	for i, pattern := range mf.DenyPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memo-filter deny pattern %s: %w", pattern, err)
+		}
+		patterns[i] = re
+	}
+	return patterns, nil
+}
+
+// ProcessorMemoFilter returns the processor.MemoFilter with the compiled patterns of the MemoFilter.
+// The MemoFilter must be valid.
+func (mf *MemoFilter) ProcessorMemoFilter() processor.MemoFilter {
+	patterns, _ := mf.denyPatterns()
+	return processor.MemoFilter{DenyPatterns: patterns}
+}
@@ -0,0 +1,22 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoFilter(t *testing.T) {
+	var unset *MemoFilter
+	require.NoError(t, unset.Validate())
+	require.Equal(t, processor.MemoFilter{}, unset.ProcessorMemoFilter())
+
+	require.Error(t, (&MemoFilter{DenyPatterns: []string{`"p":"ibc-20`, `(`}}).Validate())
+
+	mf := &MemoFilter{DenyPatterns: []string{`"p"\s*:\s*"ibc-20"`}}
+	require.NoError(t, mf.Validate())
+	patterns := mf.ProcessorMemoFilter().DenyPatterns
+	require.Len(t, patterns, 1)
+	require.True(t, patterns[0].MatchString(`{"p": "ibc-20","op":"mint"}`))
+}
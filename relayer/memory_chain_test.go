@@ -0,0 +1,354 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/light"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/chains/memory"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// newMemoryChain returns a Chain of a new in-memory chain, which produces blocks until ctx is done.
+func newMemoryChain(ctx context.Context, t *testing.T, chainID string) *Chain {
+	return newMemoryChainWithConfig(ctx, t, memory.ProviderConfig{ChainID: chainID, Key: "relayer"}, 50*time.Millisecond)
+}
+
+// newMemoryChainWithConfig returns a Chain of a new in-memory chain configured by pc,
+// which produces a block every blockInterval until ctx is done.
+func newMemoryChainWithConfig(ctx context.Context, t *testing.T, pc memory.ProviderConfig, blockInterval time.Duration) *Chain {
+	log := zaptest.NewLogger(t)
+	prov, err := pc.NewProvider(log, t.TempDir(), false, pc.ChainID)
+	require.NoError(t, err)
+	go prov.(*memory.Provider).Chain().Run(ctx, blockInterval)
+
+	c := NewChain(log, prov, false)
+	c.Chainid = pc.ChainID
+	require.NoError(t, c.SetPath(&PathEnd{ChainID: pc.ChainID}))
+	return c
+}
+
+// linkMemoryChains creates the clients, connection and an ICS-20 channel between the in-memory chains,
+// and returns the channel end on src.
+func linkMemoryChains(ctx context.Context, t *testing.T, src, dst *Chain) *chantypes.IdentifiedChannel {
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(ctx, 1))
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(ctx, 1))
+
+	srcClientID, dstClientID, err := src.CreateClients(ctx, dst, true, true, false, 0, 0, 85, light.DefaultTrustLevel, "")
+	require.NoError(t, err)
+	src.PathEnd.ClientID, dst.PathEnd.ClientID = srcClientID, dstClientID
+
+	srcConnID, dstConnID, err := src.CreateOpenConnections(ctx, dst, nil, false, "", 0, "memory")
+	require.NoError(t, err)
+	src.PathEnd.ConnectionID, dst.PathEnd.ConnectionID = srcConnID, dstConnID
+
+	require.NoError(t, src.CreateOpenChannels(ctx, dst, nil, "transfer", "transfer", "unordered", "ics20-1", "", "", false, "", "memory"))
+
+	channels, err := src.ChainProvider.QueryConnectionChannels(ctx, 0, srcConnID)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	require.Equal(t, chantypes.OPEN, channels[0].State)
+	return channels[0]
+}
+
+// sendMemoryTransfer sends a transfer of 100stake on the channel of src to the relayer key on dst.
+func sendMemoryTransfer(ctx context.Context, t *testing.T, src, dst *Chain, channel *chantypes.IdentifiedChannel) {
+	receiver, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	transfer, err := src.ChainProvider.MsgTransfer(receiver, sdk.NewInt64Coin("stake", 100), "", provider.PacketInfo{
+		SourcePort:    channel.PortId,
+		SourceChannel: channel.ChannelId,
+		TimeoutHeight: clienttypes.NewHeight(0, 1_000_000),
+	})
+	require.NoError(t, err)
+	_, success, err := src.ChainProvider.SendMessage(ctx, transfer, "")
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+func TestMemoryChainsRelayTransfer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	src := newMemoryChain(ctx, t, "chain-a")
+	dst := newMemoryChain(ctx, t, "chain-b")
+	channel := linkMemoryChains(ctx, t, src, dst)
+
+	sendMemoryTransfer(ctx, t, src, dst, channel)
+
+	// the backlog of the packet is detected from the state of both chains before it is relayed.
+	backlogs, err := QueryPacketBacklog(ctx, src, dst, ChannelFilter{})
+	require.NoError(t, err)
+	require.Len(t, backlogs, 2)
+	require.Equal(t, []uint64{1}, backlogs[0].UnrelayedPackets)
+	require.Empty(t, backlogs[0].UnrelayedAcks)
+	require.Empty(t, backlogs[1].UnrelayedPackets)
+	backlogs, err = QueryPacketBacklog(ctx, src, dst, ChannelFilter{Rule: processor.RuleDenyList, ChannelList: []string{channel.ChannelId}})
+	require.NoError(t, err)
+	require.Empty(t, backlogs)
+
+	path := &Path{
+		Src: src.PathEnd,
+		Dst: dst.PathEnd,
+	}
+	relayCtx, relayCancel := context.WithCancel(ctx)
+	defer relayCancel()
+	errCh := StartRelayer(
+		relayCtx, zaptest.NewLogger(t),
+		map[string]*Chain{src.ChainID(): src, dst.ChainID(): dst},
+		[]NamedPath{{Name: "memory", Path: path}},
+		5, 0, 0, "", 0, time.Hour, nil, ProcessorEvents, 20, nil, nil, StartOptions{},
+	)
+
+	// the packet is received on dst, then acknowledged on src, which deletes its commitment.
+	require.Eventually(t, func() bool {
+		res, err := src.ChainProvider.QueryPacketCommitments(ctx, 0, channel.ChannelId, channel.PortId)
+		return err == nil && len(res.Commitments) == 0
+	}, 30*time.Second, 50*time.Millisecond)
+
+	unreceived, err := dst.ChainProvider.QueryUnreceivedPackets(ctx, 0, channel.Counterparty.ChannelId, channel.Counterparty.PortId, []uint64{1})
+	require.NoError(t, err)
+	require.Empty(t, unreceived)
+
+	relayCancel()
+	require.NoError(t, <-errCh)
+}
+
+func TestMemoryChainsSeparateClientUpdatesWithinTxBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// the client updates are sent in their own txs, which take up the budget of a tx per block. Blocks of dst are
+	// slower, so that new packets are sent on src while the budget of the latest block of dst is spent.
+	newChain := func(chainID string, blockInterval time.Duration) *Chain {
+		return newMemoryChainWithConfig(ctx, t, memory.ProviderConfig{
+			ChainID:      chainID,
+			Key:          "relayer",
+			ClientUpdate: provider.ClientUpdateModeSeparate,
+		}, blockInterval)
+	}
+	src, dst := newChain("chain-a", 50*time.Millisecond), newChain("chain-b", 300*time.Millisecond)
+	channel := linkMemoryChains(ctx, t, src, dst)
+
+	relayCtx, relayCancel := context.WithCancel(ctx)
+	defer relayCancel()
+	errCh := StartRelayer(
+		relayCtx, zaptest.NewLogger(t),
+		map[string]*Chain{src.ChainID(): src, dst.ChainID(): dst},
+		[]NamedPath{{Name: "memory", Path: &Path{Src: src.PathEnd, Dst: dst.PathEnd}}},
+		5, 0, 0, "", 0, time.Hour, nil, ProcessorEvents, 20, nil, nil, StartOptions{TxsPerBlock: 1},
+	)
+
+	relayed := func(sequences ...uint64) func() bool {
+		return func() bool {
+			res, err := src.ChainProvider.QueryPacketCommitments(ctx, 0, channel.ChannelId, channel.PortId)
+			if err != nil || len(res.Commitments) != 0 {
+				return false
+			}
+			unreceived, err := dst.ChainProvider.QueryUnreceivedPackets(
+				ctx, 0, channel.Counterparty.ChannelId, channel.Counterparty.PortId, sequences,
+			)
+			return err == nil && len(unreceived) == 0
+		}
+	}
+
+	sendMemoryTransfer(ctx, t, src, dst, channel)
+	require.Eventually(t, relayed(1), 30*time.Second, 50*time.Millisecond)
+
+	// once the relayer is running, packets are sent in consecutive blocks of src, so that the client update
+	// of the messages of a block is sent while the budget of dst is spent by the messages of an earlier block.
+	sequences := []uint64{1}
+	for seq := uint64(2); seq <= 10; seq++ {
+		sendMemoryTransfer(ctx, t, src, dst, channel)
+		sequences = append(sequences, seq)
+	}
+	require.Eventually(t, relayed(sequences...), 30*time.Second, 50*time.Millisecond)
+
+	relayCancel()
+	require.NoError(t, <-errCh)
+}
+
+// sendMemoryTransferWithTimeout sends amount with memo over the channel of the in-memory chain src to dst,
+// which times out once dst has produced timeoutBlocks more blocks.
+func sendMemoryTransferWithTimeout(
+	ctx context.Context,
+	t *testing.T,
+	src, dst *Chain,
+	channel *chantypes.IdentifiedChannel,
+	amount sdk.Coin,
+	memo string,
+	timeoutBlocks uint64,
+) {
+	receiver, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	latest, err := dst.ChainProvider.QueryLatestHeight(ctx)
+	require.NoError(t, err)
+	transfer, err := src.ChainProvider.MsgTransfer(receiver, amount, memo, provider.PacketInfo{
+		SourcePort:    channel.PortId,
+		SourceChannel: channel.ChannelId,
+		TimeoutHeight: clienttypes.NewHeight(0, uint64(latest)+timeoutBlocks),
+	})
+	require.NoError(t, err)
+	_, success, err := src.ChainProvider.SendMessage(ctx, transfer, "")
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+func TestMemoryChainsTimeoutRefusedPackets(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		path   func(src, dst *Chain) *Path
+		amount sdk.Coin
+		memo   string
+	}{
+		{
+			name: "denom refused by the denom policy",
+			path: func(src, dst *Chain) *Path {
+				return &Path{
+					Src:         src.PathEnd,
+					Dst:         dst.PathEnd,
+					DenomPolicy: &DenomPolicy{Rule: processor.RuleDenyList, Denoms: []string{"uexploit"}},
+				}
+			},
+			amount: sdk.NewInt64Coin("uexploit", 100),
+		},
+		{
+			name: "memo refused by the memo filter",
+			path: func(src, dst *Chain) *Path {
+				return &Path{
+					Src:        src.PathEnd,
+					Dst:        dst.PathEnd,
+					MemoFilter: &MemoFilter{DenyPatterns: []string{`"p"\s*:\s*"ibc-20"`}},
+				}
+			},
+			amount: sdk.NewInt64Coin("stake", 100),
+			memo:   `{"p":"ibc-20","op":"mint"}`,
+		},
+		{
+			name: "memo over the memo limit of the path",
+			path: func(src, dst *Chain) *Path {
+				memoLimit := 8
+				return &Path{Src: src.PathEnd, Dst: dst.PathEnd, ICS20MemoLimit: &memoLimit}
+			},
+			amount: sdk.NewInt64Coin("stake", 100),
+			memo:   "a memo over the limit",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			src := newMemoryChain(ctx, t, "chain-a")
+			dst := newMemoryChain(ctx, t, "chain-b")
+			channel := linkMemoryChains(ctx, t, src, dst)
+
+			relayCtx, relayCancel := context.WithCancel(ctx)
+			defer relayCancel()
+			errCh := StartRelayer(
+				relayCtx, zaptest.NewLogger(t),
+				map[string]*Chain{src.ChainID(): src, dst.ChainID(): dst},
+				[]NamedPath{{Name: "memory", Path: tc.path(src, dst)}},
+				5, 0, 0, "", 0, time.Hour, nil, ProcessorEvents, 20, nil, nil, StartOptions{},
+			)
+
+			sendMemoryTransferWithTimeout(ctx, t, src, dst, channel, tc.amount, tc.memo, 20)
+
+			// the refused packet is never received on dst, but timed out on src, which deletes its commitment.
+			require.Eventually(t, func() bool {
+				res, err := src.ChainProvider.QueryPacketCommitments(ctx, 0, channel.ChannelId, channel.PortId)
+				return err == nil && len(res.Commitments) == 0
+			}, 30*time.Second, 50*time.Millisecond)
+
+			unreceived, err := dst.ChainProvider.QueryUnreceivedPackets(
+				ctx, 0, channel.Counterparty.ChannelId, channel.Counterparty.PortId, []uint64{1},
+			)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{1}, unreceived)
+
+			relayCancel()
+			require.NoError(t, <-errCh)
+		})
+	}
+}
+
+func TestMemoryChainsIBCSnapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	src := newMemoryChain(ctx, t, "chain-a")
+	dst := newMemoryChain(ctx, t, "chain-b")
+	channel := linkMemoryChains(ctx, t, src, dst)
+
+	// the first packet is relayed and acknowledged, the second one is left pending.
+	relayCtx, relayCancel := context.WithCancel(ctx)
+	defer relayCancel()
+	errCh := StartRelayer(
+		relayCtx, zaptest.NewLogger(t),
+		map[string]*Chain{src.ChainID(): src, dst.ChainID(): dst},
+		[]NamedPath{{Name: "memory", Path: &Path{Src: src.PathEnd, Dst: dst.PathEnd}}},
+		5, 0, 0, "", 0, time.Hour, nil, ProcessorEvents, 20, nil, nil, StartOptions{},
+	)
+	sendMemoryTransfer(ctx, t, src, dst, channel)
+	require.Eventually(t, func() bool {
+		res, err := src.ChainProvider.QueryPacketCommitments(ctx, 0, channel.ChannelId, channel.PortId)
+		return err == nil && len(res.Commitments) == 0
+	}, 30*time.Second, 50*time.Millisecond)
+	relayCancel()
+	require.NoError(t, <-errCh)
+
+	sendMemoryTransfer(ctx, t, src, dst, channel)
+
+	// decode unmarshals the JSON of the snapshot, as exported by q ibc-snapshot.
+	decode := func(snapshot *IBCSnapshot) map[string]any {
+		out, err := json.Marshal(snapshot)
+		require.NoError(t, err)
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(out, &decoded))
+		return decoded
+	}
+
+	snapshot, err := QueryIBCSnapshot(ctx, src)
+	require.NoError(t, err)
+	require.Equal(t, "chain-a", snapshot.ChainID)
+	require.Positive(t, snapshot.Height)
+
+	exported := decode(snapshot)
+	require.Equal(t, "chain-a", exported["chain_id"])
+	clients := exported["clients"].([]any)
+	require.Len(t, clients, 1)
+	require.Equal(t, src.PathEnd.ClientID, clients[0].(map[string]any)["client_id"])
+	connections := exported["connections"].([]any)
+	require.Len(t, connections, 1)
+	require.Equal(t, src.PathEnd.ConnectionID, connections[0].(map[string]any)["id"])
+	require.Equal(t, "STATE_OPEN", connections[0].(map[string]any)["state"])
+
+	require.Len(t, snapshot.Channels, 1)
+	channels := exported["channels"].([]any)
+	require.Equal(t, channel.ChannelId, channels[0].(map[string]any)["channel"].(map[string]any)["channel_id"])
+	require.Equal(t, "STATE_OPEN", channels[0].(map[string]any)["channel"].(map[string]any)["state"])
+	require.Len(t, snapshot.Channels[0].PendingCommitments, 1)
+	require.Equal(t, uint64(2), snapshot.Channels[0].PendingCommitments[0].Sequence)
+	require.Equal(t, channel.ChannelId, snapshot.Channels[0].PendingCommitments[0].ChannelId)
+	require.NotEmpty(t, snapshot.Channels[0].PendingCommitments[0].Data)
+	require.Empty(t, snapshot.Channels[0].Acknowledgements)
+
+	// the acknowledgement of the first packet is written on dst, which has no pending commitments.
+	snapshot, err = QueryIBCSnapshot(ctx, dst)
+	require.NoError(t, err)
+	require.Equal(t, "chain-b", snapshot.ChainID)
+	require.Len(t, snapshot.Clients, 1)
+	require.Len(t, snapshot.Connections, 1)
+	require.Len(t, snapshot.Channels, 1)
+	require.Empty(t, snapshot.Channels[0].PendingCommitments)
+	require.Len(t, snapshot.Channels[0].Acknowledgements, 1)
+	require.Equal(t, uint64(1), snapshot.Channels[0].Acknowledgements[0].Sequence)
+	require.Equal(t, channel.Counterparty.ChannelId, snapshot.Channels[0].Acknowledgements[0].ChannelId)
+}
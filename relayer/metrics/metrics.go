@@ -0,0 +1,157 @@
+// Package metrics exports the relayer metrics, which are recorded in the Prometheus registry of
+// processor.PrometheusMetrics, to the monitoring backend selected in the config. Prometheus scrapes the registry from
+// the debug server, while the metrics are pushed to StatsD servers, e.g. the DataDog agent, and to OpenTelemetry
+// collectors over OTLP.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// Backends of the metrics.
+const (
+	BackendPrometheus = "prometheus"
+	BackendStatsd     = "statsd"
+	BackendOTLP       = "otlp"
+)
+
+const (
+	// DefaultInterval is how often the metrics are pushed to the backend, unless configured otherwise.
+	DefaultInterval = 15 * time.Second
+
+	// DefaultStatsdAddress is the address of the StatsD server, unless configured otherwise.
+	DefaultStatsdAddress = "localhost:8125"
+
+	// DefaultOTLPAddress is the URL of the OTLP HTTP endpoint, unless configured otherwise.
+	DefaultOTLPAddress = "http://localhost:4318"
+)
+
+// Config configures the backend to which the relayer metrics are exported.
+type Config struct {
+	// Backend is prometheus, statsd or otlp. prometheus if empty.
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// Address is the host:port of the StatsD server, e.g. localhost:8125, or the URL of the OTLP HTTP endpoint of
+	// an OpenTelemetry collector, e.g. http://localhost:4318. The default of the backend if empty.
+	Address string `yaml:"address,omitempty" json:"address,omitempty"`
+
+	// Interval is how often the metrics are pushed to the backend, e.g. 30s. DefaultInterval if empty.
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	// Prefix is prepended to the names of the metrics sent to StatsD, e.g. "rly.".
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// Tags are added to every metric, as tags of StatsD metrics or resource attributes of OTLP metrics.
+	Tags map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// Headers are added to the OTLP requests, e.g. for authorization.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	switch c.Backend {
+	case "", BackendPrometheus, BackendStatsd, BackendOTLP:
+	default:
+		return fmt.Errorf("invalid metrics backend %q, expected one of %s, %s or %s", c.Backend, BackendPrometheus, BackendStatsd, BackendOTLP)
+	}
+	if _, err := c.interval(); err != nil {
+		return err
+	}
+	if c.Backend == BackendOTLP {
+		if _, err := otlpOptions(*c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pushes returns true if the metrics are pushed to the backend, rather than scraped from the debug server.
+func (c *Config) Pushes() bool {
+	return c != nil && c.Backend != "" && c.Backend != BackendPrometheus
+}
+
+func (c *Config) interval() (time.Duration, error) {
+	if c.Interval == "" {
+		return DefaultInterval, nil
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid metrics interval %q: %w", c.Interval, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("metrics interval must be positive")
+	}
+	return d, nil
+}
+
+// Backend exports the metric families gathered from the registry of the relayer metrics.
+type Backend interface {
+	// Export exports the current values of the metric families.
+	Export(ctx context.Context, families []*dto.MetricFamily) error
+
+	// Close exports any buffered metrics and releases the resources of the backend.
+	Close(ctx context.Context) error
+}
+
+// New returns the Backend configured by c. Prometheus scrapes the registry from the debug server,
+// so no Backend is returned for it.
+func New(ctx context.Context, c Config) (Backend, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	switch c.Backend {
+	case BackendStatsd:
+		return newStatsdBackend(c)
+	case BackendOTLP:
+		return newOTLPBackend(ctx, c)
+	}
+	return nil, nil
+}
+
+// Push exports the metrics gathered from g to b every interval of c until ctx is done,
+// then exports them once more and closes b. Failed exports are logged and retried at the next interval.
+func Push(ctx context.Context, log *zap.Logger, c Config, g prometheus.Gatherer, b Backend) {
+	interval, err := c.interval()
+	if err != nil {
+		// invalid intervals are reported by config validation.
+		interval = DefaultInterval
+	}
+
+	export := func(ctx context.Context) {
+		families, err := g.Gather()
+		if err != nil {
+			log.Warn("Failed to gather metrics", zap.Error(err))
+		}
+		if err := b.Export(ctx, families); err != nil {
+			log.Warn("Failed to export metrics", zap.String("backend", c.Backend), zap.Error(err))
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			defer cancel()
+			export(ctx)
+			if err := b.Close(ctx); err != nil {
+				log.Warn("Failed to close metrics backend", zap.String("backend", c.Backend), zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+			export(ctx)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// testRegistry returns a registry with a counter, a gauge and a histogram of relayer metrics.
+func testRegistry() (*prometheus.Registry, *prometheus.CounterVec, *prometheus.GaugeVec, *prometheus.HistogramVec) {
+	registry := prometheus.NewRegistry()
+	registerer := promauto.With(registry)
+	counter := registerer.NewCounterVec(prometheus.CounterOpts{
+		Name: "cosmos_relayer_relayed_packets_total",
+		Help: "The total number of relayed packets",
+	}, []string{"path_name", "chain"})
+	gauge := registerer.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cosmos_relayer_chain_latest_height",
+		Help: "The current height of the chain",
+	}, []string{"chain"})
+	histogram := registerer.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cosmos_relayer_relay_stage_duration_seconds",
+		Help:    "Duration of the stages of the relay pipeline",
+		Buckets: []float64{1, 5},
+	}, []string{"stage"})
+	return registry, counter, gauge, histogram
+}
+
+func gather(t *testing.T, g prometheus.Gatherer) []*dto.MetricFamily {
+	families, err := g.Gather()
+	require.NoError(t, err)
+	return families
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, (*Config)(nil).Validate())
+	require.NoError(t, (&Config{}).Validate())
+	require.NoError(t, (&Config{Backend: BackendStatsd, Interval: "30s"}).Validate())
+	require.NoError(t, (&Config{Backend: BackendOTLP, Address: "https://otel.example.com:4318/v1/metrics"}).Validate())
+
+	require.Error(t, (&Config{Backend: "graphite"}).Validate())
+	require.Error(t, (&Config{Backend: BackendStatsd, Interval: "0s"}).Validate())
+	require.Error(t, (&Config{Backend: BackendOTLP, Address: "localhost:4318"}).Validate())
+
+	require.False(t, (*Config)(nil).Pushes())
+	require.False(t, (&Config{Backend: BackendPrometheus}).Pushes())
+	require.True(t, (&Config{Backend: BackendOTLP}).Pushes())
+}
+
+func TestStatsdBackend(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	b, err := New(context.Background(), Config{
+		Backend: BackendStatsd,
+		Address: conn.LocalAddr().String(),
+		Prefix:  "rly.",
+		Tags:    map[string]string{"env": "prod"},
+	})
+	require.NoError(t, err)
+	defer b.Close(context.Background())
+
+	registry, counter, gauge, histogram := testRegistry()
+	read := func() []string {
+		buf := make([]byte, statsdMaxPacketSize)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		return strings.Split(string(buf[:n]), "\n")
+	}
+
+	counter.WithLabelValues("demo-path", "chain-a").Add(3)
+	gauge.WithLabelValues("chain-a").Set(100)
+	histogram.WithLabelValues("proof").Observe(0.5)
+	require.NoError(t, b.Export(context.Background(), gather(t, registry)))
+	require.ElementsMatch(t, []string{
+		"rly.cosmos_relayer_relayed_packets_total:3|c|#env:prod,chain:chain-a,path_name:demo-path",
+		"rly.cosmos_relayer_chain_latest_height:100|g|#env:prod,chain:chain-a",
+		"rly.cosmos_relayer_relay_stage_duration_seconds_count:1|c|#env:prod,stage:proof",
+		"rly.cosmos_relayer_relay_stage_duration_seconds_sum:0.5|c|#env:prod,stage:proof",
+	}, read())
+
+	// counters are sent as their increase since the previous export, and only if they increased.
+	counter.WithLabelValues("demo-path", "chain-a").Add(2)
+	require.NoError(t, b.Export(context.Background(), gather(t, registry)))
+	require.ElementsMatch(t, []string{
+		"rly.cosmos_relayer_relayed_packets_total:2|c|#env:prod,chain:chain-a,path_name:demo-path",
+		"rly.cosmos_relayer_chain_latest_height:100|g|#env:prod,chain:chain-a",
+	}, read())
+}
+
+// recordingExporter records the metrics exported over OTLP.
+type recordingExporter struct {
+	exported []*metricdata.ResourceMetrics
+}
+
+func (e *recordingExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	e.exported = append(e.exported, rm)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+func TestOTLPBackend(t *testing.T) {
+	exporter := &recordingExporter{}
+	start := time.Now()
+	b := &otlpBackend{exporter: exporter, resource: otlpResource(map[string]string{"env": "prod"}), start: start}
+
+	registry, counter, gauge, histogram := testRegistry()
+	counter.WithLabelValues("demo-path", "chain-a").Add(3)
+	gauge.WithLabelValues("chain-a").Set(100)
+	for _, d := range []float64{0.5, 2, 3, 10} {
+		histogram.WithLabelValues("proof").Observe(d)
+	}
+	require.NoError(t, b.Export(context.Background(), gather(t, registry)))
+	require.Len(t, exporter.exported, 1)
+
+	rm := exporter.exported[0]
+	serviceName, ok := rm.Resource.Set().Value("service.name")
+	require.True(t, ok)
+	require.Equal(t, "rly", serviceName.AsString())
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	sum, ok := metrics["cosmos_relayer_relayed_packets_total"].(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.True(t, sum.IsMonotonic)
+	require.Equal(t, metricdata.CumulativeTemporality, sum.Temporality)
+	require.Equal(t, 3.0, sum.DataPoints[0].Value)
+	require.Equal(t, start, sum.DataPoints[0].StartTime)
+	require.Equal(t, attribute.NewSet(attribute.String("chain", "chain-a"), attribute.String("path_name", "demo-path")), sum.DataPoints[0].Attributes)
+
+	g, ok := metrics["cosmos_relayer_chain_latest_height"].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Equal(t, 100.0, g.DataPoints[0].Value)
+
+	hist, ok := metrics["cosmos_relayer_relay_stage_duration_seconds"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	dp := hist.DataPoints[0]
+	require.Equal(t, uint64(4), dp.Count)
+	require.Equal(t, 15.5, dp.Sum)
+	require.Equal(t, []float64{1, 5}, dp.Bounds)
+	require.Equal(t, []uint64{1, 2, 1}, dp.BucketCounts)
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// otlpScope is the instrumentation scope of the metrics exported over OTLP.
+var otlpScope = instrumentation.Scope{Name: "github.com/cosmos/relayer/v2"}
+
+// otlpExporter exports metrics over OTLP.
+type otlpExporter interface {
+	Export(ctx context.Context, rm *metricdata.ResourceMetrics) error
+	Shutdown(ctx context.Context) error
+}
+
+// otlpBackend exports the metrics to an OTLP HTTP endpoint, as cumulative sums, gauges and histograms
+// with their labels as attributes.
+type otlpBackend struct {
+	exporter otlpExporter
+	resource *resource.Resource
+
+	// start is when the relayer started recording the metrics, the start time of the cumulative metrics.
+	start time.Time
+}
+
+func newOTLPBackend(ctx context.Context, c Config) (*otlpBackend, error) {
+	opts, err := otlpOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &otlpBackend{exporter: exporter, resource: otlpResource(c.Tags), start: time.Now()}, nil
+}
+
+// otlpOptions returns the options of the OTLP HTTP exporter for the endpoint URL and headers of c.
+func otlpOptions(c Config) ([]otlpmetrichttp.Option, error) {
+	addr := c.Address
+	if addr == "" {
+		addr = DefaultOTLPAddress
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP address %q: %w", addr, err)
+	}
+	if u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid OTLP address %q, expected an http or https URL", addr)
+	}
+
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlpmetrichttp.WithURLPath(u.Path))
+	}
+	if len(c.Headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(c.Headers))
+	}
+	return opts, nil
+}
+
+func otlpResource(tags map[string]string) *resource.Resource {
+	attrs := []attribute.KeyValue{attribute.String("service.name", "rly")}
+	for k, v := range tags {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return resource.NewSchemaless(attrs...)
+}
+
+func (b *otlpBackend) Export(ctx context.Context, families []*dto.MetricFamily) error {
+	return b.exporter.Export(ctx, b.resourceMetrics(families, time.Now()))
+}
+
+// resourceMetrics converts the metric families gathered at now to OTLP metrics.
+func (b *otlpBackend) resourceMetrics(families []*dto.MetricFamily, now time.Time) *metricdata.ResourceMetrics {
+	sm := metricdata.ScopeMetrics{Scope: otlpScope}
+	for _, f := range families {
+		m := metricdata.Metrics{Name: f.GetName(), Description: f.GetHelp()}
+		switch f.GetType() {
+		case dto.MetricType_COUNTER:
+			sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+			for _, pm := range f.GetMetric() {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: otlpAttributes(pm),
+					StartTime:  b.start,
+					Time:       now,
+					Value:      pm.GetCounter().GetValue(),
+				})
+			}
+			m.Data = sum
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			var gauge metricdata.Gauge[float64]
+			for _, pm := range f.GetMetric() {
+				value := pm.GetGauge().GetValue()
+				if f.GetType() == dto.MetricType_UNTYPED {
+					value = pm.GetUntyped().GetValue()
+				}
+				gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: otlpAttributes(pm),
+					Time:       now,
+					Value:      value,
+				})
+			}
+			m.Data = gauge
+		case dto.MetricType_HISTOGRAM:
+			hist := metricdata.Histogram[float64]{Temporality: metricdata.CumulativeTemporality}
+			for _, pm := range f.GetMetric() {
+				dp := otlpHistogramDataPoint(pm.GetHistogram())
+				dp.Attributes, dp.StartTime, dp.Time = otlpAttributes(pm), b.start, now
+				hist.DataPoints = append(hist.DataPoints, dp)
+			}
+			m.Data = hist
+		default:
+			continue
+		}
+		sm.Metrics = append(sm.Metrics, m)
+	}
+	return &metricdata.ResourceMetrics{Resource: b.resource, ScopeMetrics: []metricdata.ScopeMetrics{sm}}
+}
+
+func otlpAttributes(m *dto.Metric) attribute.Set {
+	attrs := make([]attribute.KeyValue, len(m.GetLabel()))
+	for i, l := range m.GetLabel() {
+		attrs[i] = attribute.String(l.GetName(), l.GetValue())
+	}
+	return attribute.NewSet(attrs...)
+}
+
+// otlpHistogramDataPoint converts the cumulative buckets of a Prometheus histogram to the buckets of an OTLP
+// histogram, which count the observations between their bounds, and end with a bucket up to +Inf.
+func otlpHistogramDataPoint(h *dto.Histogram) metricdata.HistogramDataPoint[float64] {
+	buckets := append([]*dto.Bucket(nil), h.GetBucket()...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].GetUpperBound() < buckets[j].GetUpperBound() })
+
+	dp := metricdata.HistogramDataPoint[float64]{Count: h.GetSampleCount(), Sum: h.GetSampleSum()}
+	var cumulative uint64
+	for _, bucket := range buckets {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			continue
+		}
+		dp.Bounds = append(dp.Bounds, bucket.GetUpperBound())
+		dp.BucketCounts = append(dp.BucketCounts, bucket.GetCumulativeCount()-cumulative)
+		cumulative = bucket.GetCumulativeCount()
+	}
+	dp.BucketCounts = append(dp.BucketCounts, dp.Count-cumulative)
+	return dp
+}
+
+func (b *otlpBackend) Close(ctx context.Context) error {
+	return b.exporter.Shutdown(ctx)
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// statsdMaxPacketSize is the maximum size of the UDP datagrams sent to the StatsD server,
+// which fits the MTU of common networks.
+const statsdMaxPacketSize = 1432
+
+// statsdTagReplacer replaces the characters of tags which are delimiters of the DogStatsD protocol.
+var statsdTagReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+// statsdBackend sends the metrics to a StatsD server over UDP, with their labels as DogStatsD tags.
+// Gauges are sent as gauges, while counters and the count and sum of histograms are sent as counters
+// of their increase since the previous export.
+type statsdBackend struct {
+	conn   net.Conn
+	prefix string
+	tags   []string
+
+	mu sync.Mutex
+	// counters are the values of the counters at the previous export, by name and tags.
+	counters map[string]float64
+}
+
+func newStatsdBackend(c Config) (*statsdBackend, error) {
+	addr := c.Address
+	if addr == "" {
+		addr = DefaultStatsdAddress
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for k, v := range c.Tags {
+		tags = append(tags, statsdTag(k, v))
+	}
+	sort.Strings(tags)
+
+	return &statsdBackend{conn: conn, prefix: c.Prefix, tags: tags, counters: make(map[string]float64)}, nil
+}
+
+func statsdTag(k, v string) string {
+	return statsdTagReplacer.Replace(k) + ":" + statsdTagReplacer.Replace(v)
+}
+
+func (b *statsdBackend) Export(_ context.Context, families []*dto.MetricFamily) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var lines []string
+	for _, f := range families {
+		name := b.prefix + f.GetName()
+		for _, m := range f.GetMetric() {
+			tags := b.metricTags(m)
+			switch f.GetType() {
+			case dto.MetricType_COUNTER:
+				lines = b.appendCounter(lines, name, tags, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				lines = append(lines, statsdLine(name, m.GetGauge().GetValue(), "g", tags))
+			case dto.MetricType_UNTYPED:
+				lines = append(lines, statsdLine(name, m.GetUntyped().GetValue(), "g", tags))
+			case dto.MetricType_HISTOGRAM:
+				lines = b.appendCounter(lines, name+"_count", tags, float64(m.GetHistogram().GetSampleCount()))
+				lines = b.appendCounter(lines, name+"_sum", tags, m.GetHistogram().GetSampleSum())
+			}
+		}
+	}
+	return b.send(lines)
+}
+
+// appendCounter appends the line of the increase of the counter since the previous export, if it increased.
+// Counters which decreased were reset, so their value is their increase.
+func (b *statsdBackend) appendCounter(lines []string, name string, tags []string, value float64) []string {
+	key := name + "|" + strings.Join(tags, ",")
+	delta := value - b.counters[key]
+	if delta < 0 {
+		delta = value
+	}
+	b.counters[key] = value
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, statsdLine(name, delta, "c", tags))
+}
+
+func (b *statsdBackend) metricTags(m *dto.Metric) []string {
+	tags := append([]string(nil), b.tags...)
+	for _, l := range m.GetLabel() {
+		tags = append(tags, statsdTag(l.GetName(), l.GetValue()))
+	}
+	return tags
+}
+
+func statsdLine(name string, value float64, kind string, tags []string) string {
+	line := name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + kind
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line
+}
+
+// send sends the lines in as few datagrams of at most statsdMaxPacketSize as possible.
+func (b *statsdBackend) send(lines []string) error {
+	var buf bytes.Buffer
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		_, err := b.conn.Write(buf.Bytes())
+		buf.Reset()
+		return err
+	}
+	for _, line := range lines {
+		if buf.Len() > 0 && buf.Len()+1+len(line) > statsdMaxPacketSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(line)
+	}
+	return flush()
+}
+
+func (b *statsdBackend) Close(context.Context) error {
+	return b.conn.Close()
+}
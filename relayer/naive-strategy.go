@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -14,16 +15,38 @@ import (
 
 // UnrelayedSequences returns the unrelayed sequence numbers between two chains
 func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chantypes.IdentifiedChannel) RelaySequences {
+	rs, _ := unrelayedSequences(ctx, src, dst, srcChannel, true)
+	return rs
+}
+
+// unrelayedSequences returns the sequences of the packets sent in either direction on srcChannel which have not
+// been received by the counterparty. If nextOrdered is set, only the packet of the next sequence to receive is
+// returned for ordered channels, otherwise all of them are. Errors are logged, and returned along with the
+// sequences which could be queried.
+func unrelayedSequences(
+	ctx context.Context,
+	src, dst *Chain,
+	srcChannel *chantypes.IdentifiedChannel,
+	nextOrdered bool,
+) (RelaySequences, error) {
 	var (
 		srcPacketSeq = []uint64{}
 		dstPacketSeq = []uint64{}
 		rs           = RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+
+		errsMu sync.Mutex
+		errs   []error
 	)
+	failed := func(err error) {
+		errsMu.Lock()
+		defer errsMu.Unlock()
+		errs = append(errs, err)
+	}
 
 	srch, dsth, err := QueryLatestHeights(ctx, src, dst)
 	if err != nil {
 		src.log.Error("Error querying latest heights", zap.Error(err))
-		return rs
+		return rs, err
 	}
 
 	var wg sync.WaitGroup
@@ -55,6 +78,7 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 				zap.Error(err),
 			)
 		})); err != nil {
+			failed(err)
 			src.log.Error(
 				"Failed to query packet commitments after max retries",
 				zap.String("channel_id", srcChannel.ChannelId),
@@ -96,6 +120,7 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 				zap.Error(err),
 			)
 		})); err != nil {
+			failed(err)
 			dst.log.Error(
 				"Failed to query packet commitments after max retries",
 				zap.String("channel_id", srcChannel.Counterparty.ChannelId),
@@ -122,20 +147,12 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 		go func() {
 			defer wg.Done()
 			// Query all packets sent by src that have not been received by dst.
-			if err := retry.Do(func() error {
-				var err error
-				srcUnreceivedPackets, err = dst.ChainProvider.QueryUnreceivedPackets(ctx, uint64(dsth), srcChannel.Counterparty.ChannelId, srcChannel.Counterparty.PortId, srcPacketSeq)
-				return err
-			}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
-				dst.log.Info(
-					"Failed to query unreceived packets",
-					zap.String("channel_id", srcChannel.Counterparty.ChannelId),
-					zap.String("port_id", srcChannel.Counterparty.PortId),
-					zap.Uint("attempt", n+1),
-					zap.Uint("max_attempts", RtyAttNum),
-					zap.Error(err),
-				)
-			})); err != nil {
+			var err error
+			srcUnreceivedPackets, err = queryUnreceivedInBatches(ctx, srcPacketSeq, func(ctx context.Context, seqs []uint64) ([]uint64, error) {
+				return dst.ChainProvider.QueryUnreceivedPackets(ctx, uint64(dsth), srcChannel.Counterparty.ChannelId, srcChannel.Counterparty.PortId, seqs)
+			})
+			if err != nil {
+				failed(err)
 				dst.log.Error(
 					"Failed to query unreceived packets after max retries",
 					zap.String("channel_id", srcChannel.Counterparty.ChannelId),
@@ -152,20 +169,12 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 		go func() {
 			defer wg.Done()
 			// Query all packets sent by dst that have not been received by src.
-			if err := retry.Do(func() error {
-				var err error
-				dstUnreceivedPackets, err = src.ChainProvider.QueryUnreceivedPackets(ctx, uint64(srch), srcChannel.ChannelId, srcChannel.PortId, dstPacketSeq)
-				return err
-			}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
-				src.log.Info(
-					"Failed to query unreceived packets",
-					zap.String("channel_id", srcChannel.ChannelId),
-					zap.String("port_id", srcChannel.PortId),
-					zap.Uint("attempt", n+1),
-					zap.Uint("max_attempts", RtyAttNum),
-					zap.Error(err),
-				)
-			})); err != nil {
+			var err error
+			dstUnreceivedPackets, err = queryUnreceivedInBatches(ctx, dstPacketSeq, func(ctx context.Context, seqs []uint64) ([]uint64, error) {
+				return src.ChainProvider.QueryUnreceivedPackets(ctx, uint64(srch), srcChannel.ChannelId, srcChannel.PortId, seqs)
+			})
+			if err != nil {
+				failed(err)
 				src.log.Error(
 					"Failed to query unreceived packets after max retries",
 					zap.String("channel_id", srcChannel.ChannelId),
@@ -179,11 +188,11 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 	}
 	wg.Wait()
 
-	// If this is an UNORDERED channel we can return at this point.
-	if srcChannel.Ordering != chantypes.ORDERED {
+	// If this is an UNORDERED channel, or all unreceived packets are wanted, we can return at this point.
+	if srcChannel.Ordering != chantypes.ORDERED || !nextOrdered {
 		rs.Src = srcUnreceivedPackets
 		rs.Dst = dstUnreceivedPackets
-		return rs
+		return rs, errors.Join(errs...)
 	}
 
 	// For ordered channels we want to only relay the packet whose sequence number is equal to
@@ -194,6 +203,7 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 			defer wg.Done()
 			nextSeqResp, err := dst.ChainProvider.QueryNextSeqRecv(ctx, dsth, srcChannel.Counterparty.ChannelId, srcChannel.Counterparty.PortId)
 			if err != nil {
+				failed(err)
 				dst.log.Error(
 					"Failed to query next packet receive sequence",
 					zap.String("channel_id", srcChannel.Counterparty.ChannelId),
@@ -218,6 +228,7 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 			defer wg.Done()
 			nextSeqResp, err := src.ChainProvider.QueryNextSeqRecv(ctx, srch, srcChannel.ChannelId, srcChannel.PortId)
 			if err != nil {
+				failed(err)
 				src.log.Error(
 					"Failed to query next packet receive sequence",
 					zap.String("channel_id", srcChannel.ChannelId),
@@ -237,21 +248,42 @@ func UnrelayedSequences(ctx context.Context, src, dst *Chain, srcChannel *chanty
 	}
 	wg.Wait()
 
-	return rs
+	return rs, errors.Join(errs...)
 }
 
 // UnrelayedAcknowledgements returns the unrelayed sequence numbers between two chains
 func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel *chantypes.IdentifiedChannel) RelaySequences {
+	rs, _ := unrelayedAcknowledgements(ctx, src, dst, srcChannel)
+	return rs
+}
+
+// unrelayedAcknowledgements returns the sequences of the packets on srcChannel whose acknowledgement has been
+// written by the receiver, but not yet relayed to the sender, in either direction. Packets acknowledged
+// asynchronously have no acknowledgement until the application writes it. Errors are logged, and returned along
+// with the sequences which could be queried.
+func unrelayedAcknowledgements(
+	ctx context.Context,
+	src, dst *Chain,
+	srcChannel *chantypes.IdentifiedChannel,
+) (RelaySequences, error) {
 	var (
 		srcPacketSeq = []uint64{}
 		dstPacketSeq = []uint64{}
 		rs           = RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+
+		errsMu sync.Mutex
+		errs   []error
 	)
+	failed := func(err error) {
+		errsMu.Lock()
+		defer errsMu.Unlock()
+		errs = append(errs, err)
+	}
 
 	srch, dsth, err := QueryLatestHeights(ctx, src, dst)
 	if err != nil {
 		src.log.Error("Error querying latest heights", zap.Error(err))
-		return rs
+		return rs, err
 	}
 
 	var wg sync.WaitGroup
@@ -274,6 +306,7 @@ func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel
 				return nil
 			}
 		}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+			failed(err)
 			src.log.Error(
 				"Failed to query packet acknowledgement commitments after max attempts",
 				zap.String("channel_id", srcChannel.ChannelId),
@@ -305,6 +338,7 @@ func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel
 				return nil
 			}
 		}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+			failed(err)
 			dst.log.Error(
 				"Failed to query packet acknowledgement commitments after max attempts",
 				zap.String("channel_id", srcChannel.Counterparty.ChannelId),
@@ -326,11 +360,11 @@ func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel
 		go func() {
 			defer wg.Done()
 			// Query all packets sent by src that have been received by dst
-			var err error
-			if err := retry.Do(func() error {
-				rs.Src, err = dst.ChainProvider.QueryUnreceivedAcknowledgements(ctx, uint64(dsth), srcChannel.Counterparty.ChannelId, srcChannel.Counterparty.PortId, srcPacketSeq)
-				return err
-			}, retry.Context(ctx), RtyErr, RtyAtt, RtyDel); err != nil {
+			unreceived, err := queryUnreceivedInBatches(ctx, srcPacketSeq, func(ctx context.Context, seqs []uint64) ([]uint64, error) {
+				return dst.ChainProvider.QueryUnreceivedAcknowledgements(ctx, uint64(dsth), srcChannel.Counterparty.ChannelId, srcChannel.Counterparty.PortId, seqs)
+			})
+			if err != nil {
+				failed(err)
 				dst.log.Error(
 					"Failed to query unreceived acknowledgements after max attempts",
 					zap.String("channel_id", srcChannel.Counterparty.ChannelId),
@@ -338,7 +372,9 @@ func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel
 					zap.Uint("attempts", RtyAttNum),
 					zap.Error(err),
 				)
+				return
 			}
+			rs.Src = unreceived
 		}()
 	}
 
@@ -347,11 +383,11 @@ func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel
 		go func() {
 			defer wg.Done()
 			// Query all packets sent by dst that have been received by src
-			var err error
-			if err := retry.Do(func() error {
-				rs.Dst, err = src.ChainProvider.QueryUnreceivedAcknowledgements(ctx, uint64(srch), srcChannel.ChannelId, srcChannel.PortId, dstPacketSeq)
-				return err
-			}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+			unreceived, err := queryUnreceivedInBatches(ctx, dstPacketSeq, func(ctx context.Context, seqs []uint64) ([]uint64, error) {
+				return src.ChainProvider.QueryUnreceivedAcknowledgements(ctx, uint64(srch), srcChannel.ChannelId, srcChannel.PortId, seqs)
+			})
+			if err != nil {
+				failed(err)
 				src.log.Error(
 					"Failed to query unreceived acknowledgements after max attempts",
 					zap.String("channel_id", srcChannel.ChannelId),
@@ -359,13 +395,15 @@ func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, srcChannel
 					zap.Uint("attempts", RtyAttNum),
 					zap.Error(err),
 				)
+				return
 			}
+			rs.Dst = unreceived
 		}()
 	}
 
 	wg.Wait()
 
-	return rs
+	return rs, errors.Join(errs...)
 }
 
 // RelaySequences represents unrelayed packets on src and dst
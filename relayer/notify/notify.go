@@ -0,0 +1,523 @@
+// Package notify POSTs JSON notifications of conditions which need the attention of the operator of the relayer,
+// such as a client about to expire or a wallet running out of funds, to webhooks, e.g. of Slack, Discord or PagerDuty.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+)
+
+// Types of events.
+const (
+	// EventClientExpiring is sent when a client of a path expires within the client-expiry window.
+	EventClientExpiring = "client_expiring"
+
+	// EventPathStalled is sent when a packet message of a path has been queued for longer than path-stalled.
+	EventPathStalled = "path_stalled"
+
+	// EventWalletLow is sent when the balance of the wallet of a chain falls below its wallet-low threshold.
+	EventWalletLow = "wallet_low"
+
+	// EventTxFailing is sent when tx-failures consecutive txs of a path to a chain failed.
+	EventTxFailing = "tx_failing"
+)
+
+// Formats of webhooks.
+const (
+	FormatJSON      = "json"
+	FormatSlack     = "slack"
+	FormatDiscord   = "discord"
+	FormatPagerDuty = "pagerduty"
+)
+
+const (
+	// DefaultClientExpiry is how long before a client expires it is notified, unless configured otherwise.
+	DefaultClientExpiry = 24 * time.Hour
+
+	// DefaultPathStalled is how long a packet message is queued before its path is notified as stalled,
+	// unless configured otherwise.
+	DefaultPathStalled = 30 * time.Minute
+
+	// DefaultTxFailures is how many consecutive txs fail before they are notified, unless configured otherwise.
+	DefaultTxFailures = 5
+
+	// DefaultRepeatInterval is how long the same condition is not notified again, unless configured otherwise.
+	DefaultRepeatInterval = time.Hour
+
+	// queueSize is how many notifications wait for delivery before further notifications are dropped.
+	queueSize = 100
+
+	// webhookTimeout is how long a webhook may take to respond.
+	webhookTimeout = 10 * time.Second
+)
+
+// formatTemplates are the templates of the request bodies of the formats other than FormatJSON,
+// which posts the event itself.
+var formatTemplates = map[string]string{
+	FormatSlack:   `{"text": {{json .Summary}}}`,
+	FormatDiscord: `{"content": {{json .Summary}}}`,
+	FormatPagerDuty: `{"routing_key": {{json .RoutingKey}}, "event_action": "trigger", "dedup_key": {{json .Key}}, ` +
+		`"payload": {"summary": {{json .Summary}}, "source": "rly", "severity": "warning", "custom_details": {{json .Event}}}}`,
+}
+
+// Config configures the notifications of the relayer.
+type Config struct {
+	Webhooks []Webhook `yaml:"webhooks" json:"webhooks"`
+
+	// ClientExpiry is how long before a client expires it is notified, e.g. 48h. DefaultClientExpiry if empty.
+	ClientExpiry string `yaml:"client-expiry,omitempty" json:"client-expiry,omitempty"`
+
+	// PathStalled is how long a packet message is queued before its path is notified as stalled, e.g. 15m.
+	// DefaultPathStalled if empty.
+	PathStalled string `yaml:"path-stalled,omitempty" json:"path-stalled,omitempty"`
+
+	// TxFailures is how many consecutive txs of a path to a chain fail before they are notified.
+	// DefaultTxFailures if zero.
+	TxFailures int `yaml:"tx-failures,omitempty" json:"tx-failures,omitempty"`
+
+	// WalletLow is the balance, by chain ID, below which the wallet of the chain is notified, e.g. 1000000uatom.
+	WalletLow map[string]string `yaml:"wallet-low,omitempty" json:"wallet-low,omitempty"`
+
+	// RepeatInterval is how long the same condition is not notified again while it persists, e.g. 4h.
+	// DefaultRepeatInterval if empty.
+	RepeatInterval string `yaml:"repeat-interval,omitempty" json:"repeat-interval,omitempty"`
+}
+
+// Webhook is a URL to which notifications are posted.
+type Webhook struct {
+	URL string `yaml:"url" json:"url"`
+
+	// Format is the format of the request body, one of json, slack, discord or pagerduty. json if empty.
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+
+	// Template is a Go text/template of the request body, which overrides the format. The template is executed
+	// with the fields of the event, and provides the json function to encode values as JSON.
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+
+	// RoutingKey is the integration key of the PagerDuty service, for the pagerduty format.
+	RoutingKey string `yaml:"routing-key,omitempty" json:"routing-key,omitempty"`
+
+	// Headers are added to the requests, e.g. for authorization.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Events are the types of events posted to the webhook. All are posted if empty.
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"`
+
+	// MaxPerMinute is how many notifications are posted to the webhook per minute. Unlimited if zero.
+	MaxPerMinute int `yaml:"max-per-minute,omitempty" json:"max-per-minute,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	_, err := newSettings(*c)
+	return err
+}
+
+// settings are the parsed Config.
+type settings struct {
+	clientExpiry   time.Duration
+	pathStalled    time.Duration
+	txFailures     int
+	walletLow      map[string]sdk.Coins
+	repeatInterval time.Duration
+	webhooks       []*webhook
+}
+
+func newSettings(c Config) (settings, error) {
+	s := settings{
+		clientExpiry:   DefaultClientExpiry,
+		pathStalled:    DefaultPathStalled,
+		txFailures:     DefaultTxFailures,
+		walletLow:      make(map[string]sdk.Coins, len(c.WalletLow)),
+		repeatInterval: DefaultRepeatInterval,
+	}
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"client-expiry", c.ClientExpiry, &s.clientExpiry},
+		{"path-stalled", c.PathStalled, &s.pathStalled},
+		{"repeat-interval", c.RepeatInterval, &s.repeatInterval},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return settings{}, fmt.Errorf("invalid notifications %s: %q", d.name, d.value)
+		}
+		*d.dest = v
+	}
+
+	if c.TxFailures < 0 {
+		return settings{}, fmt.Errorf("invalid notifications tx-failures: %d", c.TxFailures)
+	}
+	if c.TxFailures > 0 {
+		s.txFailures = c.TxFailures
+	}
+
+	for chainID, balance := range c.WalletLow {
+		coins, err := sdk.ParseCoinsNormalized(balance)
+		if err != nil {
+			return settings{}, fmt.Errorf("invalid notifications wallet-low for chain %s: %w", chainID, err)
+		}
+		s.walletLow[chainID] = coins
+	}
+
+	if len(c.Webhooks) == 0 {
+		return settings{}, fmt.Errorf("notifications require at least one webhook")
+	}
+	for i, wc := range c.Webhooks {
+		w, err := newWebhook(wc)
+		if err != nil {
+			return settings{}, fmt.Errorf("invalid notifications webhook %d: %w", i, err)
+		}
+		s.webhooks = append(s.webhooks, w)
+	}
+
+	return s, nil
+}
+
+// Event is a notification.
+type Event struct {
+	Type     string            `json:"type"`
+	Time     time.Time         `json:"time"`
+	PathName string            `json:"path_name,omitempty"`
+	ChainID  string            `json:"chain_id,omitempty"`
+	Message  string            `json:"message"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// Key identifies the condition notified by the event, which is not notified again until the repeat interval passed.
+func (e Event) Key() string {
+	return strings.Join([]string{e.Type, e.PathName, e.ChainID}, "/")
+}
+
+// Summary is a single line description of the event.
+func (e Event) Summary() string {
+	var subject []string
+	for _, s := range []string{e.PathName, e.ChainID} {
+		if s != "" {
+			subject = append(subject, s)
+		}
+	}
+	if len(subject) == 0 {
+		return fmt.Sprintf("[rly] %s: %s", e.Type, e.Message)
+	}
+	return fmt.Sprintf("[rly] %s (%s): %s", e.Type, strings.Join(subject, ", "), e.Message)
+}
+
+// templateData is what the template of a webhook is executed with.
+type templateData struct {
+	Event
+	RoutingKey string
+}
+
+type webhook struct {
+	cfg    Webhook
+	tmpl   *template.Template
+	events map[string]bool
+
+	// the times of the notifications posted within the last minute, for MaxPerMinute.
+	sent []time.Time
+}
+
+func newWebhook(cfg Webhook) (*webhook, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if cfg.MaxPerMinute < 0 {
+		return nil, fmt.Errorf("invalid max-per-minute: %d", cfg.MaxPerMinute)
+	}
+
+	w := &webhook{cfg: cfg, events: make(map[string]bool, len(cfg.Events))}
+	for _, e := range cfg.Events {
+		switch e {
+		case EventClientExpiring, EventPathStalled, EventWalletLow, EventTxFailing:
+			w.events[e] = true
+		default:
+			return nil, fmt.Errorf("invalid event: %s, supports one of: [%s, %s, %s, %s]",
+				e, EventClientExpiring, EventPathStalled, EventWalletLow, EventTxFailing)
+		}
+	}
+
+	text := cfg.Template
+	if text == "" {
+		switch cfg.Format {
+		case "", FormatJSON:
+			return w, nil
+		case FormatSlack, FormatDiscord, FormatPagerDuty:
+			text = formatTemplates[cfg.Format]
+		default:
+			return nil, fmt.Errorf("invalid format: %s, supports one of: [%s, %s, %s, %s]",
+				cfg.Format, FormatJSON, FormatSlack, FormatDiscord, FormatPagerDuty)
+		}
+	}
+	if cfg.Format == FormatPagerDuty && cfg.RoutingKey == "" {
+		return nil, fmt.Errorf("routing-key is required by the %s format", FormatPagerDuty)
+	}
+
+	tmpl, err := template.New(cfg.URL).Funcs(template.FuncMap{"json": templateJSON}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	w.tmpl = tmpl
+	return w, nil
+}
+
+func templateJSON(v any) (string, error) {
+	bz, err := json.Marshal(v)
+	return string(bz), err
+}
+
+// body returns the request body of the event.
+func (w *webhook) body(e Event) ([]byte, error) {
+	if w.tmpl == nil {
+		return json.Marshal(e)
+	}
+	var buf bytes.Buffer
+	if err := w.tmpl.Execute(&buf, templateData{Event: e, RoutingKey: w.cfg.RoutingKey}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// allow returns true if the event is posted to the webhook, by its types of events and its rate limit.
+func (w *webhook) allow(e Event, now time.Time) bool {
+	if len(w.events) > 0 && !w.events[e.Type] {
+		return false
+	}
+	if w.cfg.MaxPerMinute == 0 {
+		return true
+	}
+
+	recent := w.sent[:0]
+	for _, t := range w.sent {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	w.sent = recent
+	if len(w.sent) >= w.cfg.MaxPerMinute {
+		return false
+	}
+	w.sent = append(w.sent, now)
+	return true
+}
+
+// Notifier sends notifications to the configured webhooks. A nil Notifier sends nothing.
+type Notifier struct {
+	log      *zap.Logger
+	settings settings
+	client   *http.Client
+	queue    chan Event
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time
+	txFailures map[string]int
+}
+
+// New returns a Notifier which sends notifications as configured by cfg, once Run is called.
+func New(log *zap.Logger, cfg Config) (*Notifier, error) {
+	s, err := newSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		log:        log,
+		settings:   s,
+		client:     &http.Client{Timeout: webhookTimeout},
+		queue:      make(chan Event, queueSize),
+		lastSent:   make(map[string]time.Time),
+		txFailures: make(map[string]int),
+	}, nil
+}
+
+// Run posts the notifications to the webhooks until ctx is done.
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-n.queue:
+			now := time.Now()
+			for _, w := range n.settings.webhooks {
+				if !w.allow(e, now) {
+					continue
+				}
+				if err := n.post(ctx, w, e); err != nil {
+					n.log.Warn("Failed to post notification",
+						zap.String("type", e.Type),
+						zap.String("url", w.cfg.URL),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, w *webhook, e Event) error {
+	body, err := w.body(e)
+	if err != nil {
+		return fmt.Errorf("failed to render notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", res.Status)
+	}
+	return nil
+}
+
+// Notify queues the event for delivery, unless the same condition was notified within the repeat interval.
+// The event is dropped if too many notifications await delivery.
+func (n *Notifier) Notify(e Event) {
+	if n == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if last, ok := n.lastSent[e.Key()]; ok && e.Time.Sub(last) < n.settings.repeatInterval {
+		return
+	}
+
+	select {
+	case n.queue <- e:
+		n.lastSent[e.Key()] = e.Time
+	default:
+		n.log.Warn("Dropping notification, too many notifications await delivery",
+			zap.String("type", e.Type),
+			zap.String("message", e.Message),
+		)
+	}
+}
+
+// ClientExpiration notifies the client of the path on the chain if it expires within the client-expiry window.
+func (n *Notifier) ClientExpiration(pathName, chainID, clientID string, timeToExpiration time.Duration) {
+	if n == nil || timeToExpiration > n.settings.clientExpiry {
+		return
+	}
+	msg := fmt.Sprintf("client %s expires in %s", clientID, timeToExpiration.Round(time.Minute))
+	if timeToExpiration <= 0 {
+		msg = fmt.Sprintf("client %s is expired", clientID)
+	}
+	n.Notify(Event{
+		Type:     EventClientExpiring,
+		PathName: pathName,
+		ChainID:  chainID,
+		Message:  msg,
+		Details: map[string]string{
+			"client_id":          clientID,
+			"time_to_expiration": timeToExpiration.String(),
+		},
+	})
+}
+
+// PacketQueue notifies the path as stalled if the oldest of the packet messages queued to the chain
+// has been queued for longer than path-stalled.
+func (n *Notifier) PacketQueue(pathName, chainID string, depth int, oldest time.Duration) {
+	if n == nil || depth == 0 || oldest < n.settings.pathStalled {
+		return
+	}
+	n.Notify(Event{
+		Type:     EventPathStalled,
+		PathName: pathName,
+		ChainID:  chainID,
+		Message:  fmt.Sprintf("%d packet messages are queued, the oldest for %s", depth, oldest.Round(time.Second)),
+		Details: map[string]string{
+			"queue_depth": fmt.Sprint(depth),
+			"oldest":      oldest.String(),
+		},
+	})
+}
+
+// TxResult counts the consecutive failed txs of the path to the chain, and notifies them once tx-failures
+// consecutive txs failed. err is nil for a tx which succeeded.
+func (n *Notifier) TxResult(pathName, chainID string, err error) {
+	if n == nil {
+		return
+	}
+	key := pathName + "/" + chainID
+
+	n.mu.Lock()
+	if err == nil {
+		delete(n.txFailures, key)
+		n.mu.Unlock()
+		return
+	}
+	n.txFailures[key]++
+	failures := n.txFailures[key]
+	n.mu.Unlock()
+
+	if failures < n.settings.txFailures {
+		return
+	}
+	n.Notify(Event{
+		Type:     EventTxFailing,
+		PathName: pathName,
+		ChainID:  chainID,
+		Message:  fmt.Sprintf("%d consecutive txs failed, the last with: %v", failures, err),
+		Details: map[string]string{
+			"failures": fmt.Sprint(failures),
+			"error":    err.Error(),
+		},
+	})
+}
+
+// WalletBalance notifies the wallet of the chain if its balance of a denom of the wallet-low threshold of the chain
+// is lower than the threshold.
+func (n *Notifier) WalletBalance(chainID, key, address string, balance sdk.Coins) {
+	if n == nil {
+		return
+	}
+	for _, threshold := range n.settings.walletLow[chainID] {
+		if amount := balance.AmountOf(threshold.Denom); amount.LT(threshold.Amount) {
+			n.Notify(Event{
+				Type:    EventWalletLow,
+				ChainID: chainID,
+				Message: fmt.Sprintf("balance %s%s of wallet %s is below %s", amount, threshold.Denom, address, threshold),
+				Details: map[string]string{
+					"key":     key,
+					"address": address,
+					"balance": amount.String() + threshold.Denom,
+					"minimum": threshold.String(),
+				},
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/relayer/v2/relayer/notify"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestConfigValidate(t *testing.T) {
+	webhooks := []notify.Webhook{{URL: "https://hooks.example.com"}}
+
+	var cfg *notify.Config
+	require.NoError(t, cfg.Validate())
+
+	for _, cfg := range []notify.Config{
+		{},
+		{Webhooks: webhooks, ClientExpiry: "tomorrow"},
+		{Webhooks: webhooks, TxFailures: -1},
+		{Webhooks: webhooks, WalletLow: map[string]string{"cosmoshub-4": "lots"}},
+		{Webhooks: []notify.Webhook{{URL: "https://hooks.example.com", Format: "email"}}},
+		{Webhooks: []notify.Webhook{{URL: "https://hooks.example.com", Format: notify.FormatPagerDuty}}},
+		{Webhooks: []notify.Webhook{{URL: "https://hooks.example.com", Events: []string{"client_updated"}}}},
+		{Webhooks: []notify.Webhook{{URL: "https://hooks.example.com", Template: "{{.Missing"}}},
+	} {
+		require.Error(t, cfg.Validate(), "%+v", cfg)
+	}
+
+	require.NoError(t, (&notify.Config{
+		Webhooks:     webhooks,
+		ClientExpiry: "48h",
+		WalletLow:    map[string]string{"cosmoshub-4": "1000000uatom"},
+	}).Validate())
+}
+
+func TestNotifier(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	n, err := notify.New(zap.NewNop(), notify.Config{
+		Webhooks: []notify.Webhook{
+			{URL: server.URL, Events: []string{notify.EventWalletLow, notify.EventTxFailing}},
+			{URL: server.URL, Format: notify.FormatSlack, Events: []string{notify.EventClientExpiring}},
+		},
+		TxFailures: 2,
+		WalletLow:  map[string]string{"cosmoshub-4": "1000000uatom"},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	receive := func() []byte {
+		select {
+		case body := <-bodies:
+			return body
+		case <-time.After(5 * time.Second):
+			t.Fatal("no notification received")
+			return nil
+		}
+	}
+
+	// consecutive tx failures are notified once the threshold is reached.
+	n.TxResult("demo-path", "cosmoshub-4", errors.New("out of gas"))
+	n.TxResult("demo-path", "cosmoshub-4", nil)
+	n.TxResult("demo-path", "cosmoshub-4", errors.New("out of gas"))
+	n.TxResult("demo-path", "cosmoshub-4", errors.New("insufficient funds"))
+
+	var e notify.Event
+	require.NoError(t, json.Unmarshal(receive(), &e))
+	require.Equal(t, notify.EventTxFailing, e.Type)
+	require.Equal(t, "demo-path", e.PathName)
+	require.Equal(t, "2", e.Details["failures"])
+
+	// the same condition is not notified again within the repeat interval.
+	n.TxResult("demo-path", "cosmoshub-4", errors.New("insufficient funds"))
+
+	// clients expiring later than the client expiry window are not notified.
+	n.ClientExpiration("demo-path", "osmosis-1", "07-tendermint-0", 7*24*time.Hour)
+	n.ClientExpiration("demo-path", "osmosis-1", "07-tendermint-0", time.Hour)
+
+	var slack struct {
+		Text string `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal(receive(), &slack))
+	require.Equal(t, "[rly] client_expiring (demo-path, osmosis-1): client 07-tendermint-0 expires in 1h0m0s", slack.Text)
+
+	n.WalletBalance("cosmoshub-4", "default", "cosmos1relayer", sdk.NewCoins(sdk.NewInt64Coin("uatom", 5000000)))
+	n.WalletBalance("osmosis-1", "default", "osmo1relayer", nil)
+	n.WalletBalance("cosmoshub-4", "default", "cosmos1relayer", sdk.NewCoins(sdk.NewInt64Coin("uatom", 500)))
+
+	require.NoError(t, json.Unmarshal(receive(), &e))
+	require.Equal(t, notify.EventWalletLow, e.Type)
+	require.Equal(t, "500uatom", e.Details["balance"])
+
+	select {
+	case body := <-bodies:
+		t.Fatalf("unexpected notification: %s", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	headers := make(chan http.Header, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		headers <- r.Header
+	}))
+	defer server.Close()
+
+	n, err := notify.New(zap.NewNop(), notify.Config{
+		Webhooks: []notify.Webhook{{
+			URL:          server.URL,
+			Template:     `{"alert": {{json .Type}}, "path": {{json .PathName}}, "oldest": {{json (index .Details "oldest")}}}`,
+			Headers:      map[string]string{"Authorization": "Bearer secret"},
+			MaxPerMinute: 1,
+		}},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	n.PacketQueue("demo-path", "osmosis-1", 3, time.Minute)
+	n.PacketQueue("demo-path", "osmosis-1", 3, time.Hour)
+	// rate limited by max-per-minute.
+	n.PacketQueue("other-path", "osmosis-1", 3, time.Hour)
+
+	select {
+	case body := <-bodies:
+		require.JSONEq(t, `{"alert": "path_stalled", "path": "demo-path", "oldest": "1h0m0s"}`, string(body))
+		require.Equal(t, "Bearer secret", (<-headers).Get("Authorization"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+
+	select {
+	case body := <-bodies:
+		t.Fatalf("unexpected notification: %s", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -21,11 +21,97 @@ func (c *Chain) SendTransferMsg(
 	log *zap.Logger,
 	dst *Chain,
 	amount sdk.Coin,
-	dstAddr, memo string,
+	dstAddr, packetMemo, memo string,
 	toHeightOffset uint64,
 	toTimeOffset time.Duration,
 	srcChannel *chantypes.IdentifiedChannel,
 ) error {
+	timeoutHeight, timeoutTimestamp, err := c.PacketTimeout(ctx, dst, toHeightOffset, toTimeOffset)
+	if err != nil {
+		return err
+	}
+
+	// MsgTransfer will call SendPacket on src chain
+	pi := provider.PacketInfo{
+		SourceChannel:    srcChannel.ChannelId,
+		SourcePort:       srcChannel.PortId,
+		TimeoutHeight:    timeoutHeight,
+		TimeoutTimestamp: timeoutTimestamp,
+	}
+
+	msg, err := c.ChainProvider.MsgTransfer(dstAddr, amount, packetMemo, pi)
+	if err != nil {
+		return err
+	}
+
+	txs := RelayMsgs{
+		Src: []provider.RelayerMessage{msg},
+	}
+
+	result := txs.Send(ctx, log, AsRelayMsgSender(c), AsRelayMsgSender(dst), memo)
+	if err := result.Error(); err != nil {
+		if result.PartiallySent() {
+			c.log.Info(
+				"Partial success when sending transfer",
+				zap.String("src_chain_id", c.ChainID()),
+				zap.String("dst_chain_id", dst.ChainID()),
+				zap.Object("send_result", result),
+			)
+		}
+		return err
+	} else if result.SuccessfullySent() {
+		c.log.Info(
+			"Successfully sent a transfer",
+			zap.String("src_chain_id", c.ChainID()),
+			zap.String("dst_chain_id", dst.ChainID()),
+			zap.Object("send_result", result),
+		)
+	}
+
+	return nil
+}
+
+// SendPacketMsg broadcasts a pre-assembled message to c which is expected to send an IBC packet to dst,
+// e.g. a MsgTransfer on a non-transfer port or a custom module message that calls SendPacket.
+func (c *Chain) SendPacketMsg(
+	ctx context.Context,
+	log *zap.Logger,
+	dst *Chain,
+	msg provider.RelayerMessage,
+	memo string,
+) error {
+	txs := RelayMsgs{
+		Src: []provider.RelayerMessage{msg},
+	}
+
+	result := txs.Send(ctx, log, AsRelayMsgSender(c), AsRelayMsgSender(dst), memo)
+	if err := result.Error(); err != nil {
+		return err
+	}
+
+	if result.SuccessfullySent() {
+		c.log.Info(
+			"Successfully sent a packet message",
+			zap.String("src_chain_id", c.ChainID()),
+			zap.String("dst_chain_id", dst.ChainID()),
+			zap.String("msg_type", msg.Type()),
+			zap.Object("send_result", result),
+		)
+	}
+
+	return nil
+}
+
+// PacketTimeout computes the timeout height and timestamp for a packet sent from c to dst.
+// The height offset is applied to the latest height of c's client tracking dst, and the time offset
+// is applied to the later of the local clock and the latest consensus timestamp of dst's client tracking c.
+// If neither offset is set, a default height offset is used.
+func (c *Chain) PacketTimeout(
+	ctx context.Context,
+	dst *Chain,
+	toHeightOffset uint64,
+	toTimeOffset time.Duration,
+) (clienttypes.Height, uint64, error) {
 	var (
 		timeoutHeight    uint64
 		timeoutTimestamp uint64
@@ -34,11 +120,11 @@ func (c *Chain) SendTransferMsg(
 	// get header representing dst to check timeouts
 	srch, dsth, err := QueryLatestHeights(ctx, c, dst)
 	if err != nil {
-		return err
+		return clienttypes.Height{}, 0, err
 	}
 	h, err := c.ChainProvider.QueryClientState(ctx, srch, c.PathEnd.ClientID)
 	if err != nil {
-		return err
+		return clienttypes.Height{}, 0, err
 	}
 
 	// if the timestamp offset is set we need to query the dst chains consensus state to get the current time
@@ -46,12 +132,12 @@ func (c *Chain) SendTransferMsg(
 	if toTimeOffset > 0 {
 		clientStateRes, err := dst.ChainProvider.QueryClientStateResponse(ctx, dsth, dst.ClientID())
 		if err != nil {
-			return fmt.Errorf("failed to query the client state response: %w", err)
+			return clienttypes.Height{}, 0, fmt.Errorf("failed to query the client state response: %w", err)
 		}
 
 		clientState, err := clienttypes.UnpackClientState(clientStateRes.ClientState)
 		if err != nil {
-			return fmt.Errorf("failed to unpack client state: %w", err)
+			return clienttypes.Height{}, 0, fmt.Errorf("failed to unpack client state: %w", err)
 		}
 
 		consensusStateRes, err := dst.ChainProvider.QueryClientConsensusState(
@@ -61,12 +147,12 @@ func (c *Chain) SendTransferMsg(
 			clientState.GetLatestHeight(),
 		)
 		if err != nil {
-			return fmt.Errorf("failed to query client consensus state: %w", err)
+			return clienttypes.Height{}, 0, fmt.Errorf("failed to query client consensus state: %w", err)
 		}
 
 		consensusState, err = clienttypes.UnpackConsensusState(consensusStateRes.ConsensusState)
 		if err != nil {
-			return fmt.Errorf("failed to unpack consensus state: %w", err)
+			return clienttypes.Height{}, 0, fmt.Errorf("failed to unpack consensus state: %w", err)
 		}
 
 		// use local clock time as reference time if it is later than the
@@ -98,45 +184,8 @@ func (c *Chain) SendTransferMsg(
 		timeoutTimestamp = 0
 	}
 
-	// MsgTransfer will call SendPacket on src chain
-	pi := provider.PacketInfo{
-		SourceChannel: srcChannel.ChannelId,
-		SourcePort:    srcChannel.PortId,
-		TimeoutHeight: clienttypes.Height{
-			RevisionNumber: h.GetLatestHeight().GetRevisionNumber(),
-			RevisionHeight: timeoutHeight,
-		},
-		TimeoutTimestamp: timeoutTimestamp,
-	}
-
-	msg, err := c.ChainProvider.MsgTransfer(dstAddr, amount, pi)
-	if err != nil {
-		return err
-	}
-
-	txs := RelayMsgs{
-		Src: []provider.RelayerMessage{msg},
-	}
-
-	result := txs.Send(ctx, log, AsRelayMsgSender(c), AsRelayMsgSender(dst), memo)
-	if err := result.Error(); err != nil {
-		if result.PartiallySent() {
-			c.log.Info(
-				"Partial success when sending transfer",
-				zap.String("src_chain_id", c.ChainID()),
-				zap.String("dst_chain_id", dst.ChainID()),
-				zap.Object("send_result", result),
-			)
-		}
-		return err
-	} else if result.SuccessfullySent() {
-		c.log.Info(
-			"Successfully sent a transfer",
-			zap.String("src_chain_id", c.ChainID()),
-			zap.String("dst_chain_id", dst.ChainID()),
-			zap.Object("send_result", result),
-		)
-	}
-
-	return nil
+	return clienttypes.Height{
+		RevisionNumber: h.GetLatestHeight().GetRevisionNumber(),
+		RevisionHeight: timeoutHeight,
+	}, timeoutTimestamp, nil
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
@@ -94,6 +96,40 @@ type Path struct {
 	Src    *PathEnd      `yaml:"src" json:"src"`
 	Dst    *PathEnd      `yaml:"dst" json:"dst"`
 	Filter ChannelFilter `yaml:"src-channel-filter" json:"src-channel-filter"`
+
+	// PriorityChannels are channels on the src chain whose packets are relayed ahead of those of other channels.
+	PriorityChannels []string `yaml:"priority-channels,omitempty" json:"priority-channels,omitempty"`
+
+	// RetryPolicy optionally configures how transactions are retried on this path.
+	RetryPolicy *RetryPolicy `yaml:"retry-policy,omitempty" json:"retry-policy,omitempty"`
+
+	// Concurrency optionally tunes how much work is done at once on this path.
+	Concurrency *Concurrency `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// ICS20MemoLimit optionally overrides the global limit of the size of the memo of ICS-20 packets relayed
+	// on this path. Zero disables the limit.
+	ICS20MemoLimit *int `yaml:"ics20-memo-limit,omitempty" json:"ics20-memo-limit,omitempty"`
+
+	// DenomPolicy optionally restricts the ICS-20 transfers relayed on this path by denom and amount.
+	DenomPolicy *DenomPolicy `yaml:"denom-policy,omitempty" json:"denom-policy,omitempty"`
+
+	// MemoFilter optionally refuses to relay the ICS-20 transfers on this path whose memo matches spam patterns.
+	MemoFilter *MemoFilter `yaml:"memo-filter,omitempty" json:"memo-filter,omitempty"`
+
+	// ClientTrust optionally configures the trust parameters of the clients created for this path.
+	ClientTrust *ClientTrustOptions `yaml:"client-trust,omitempty" json:"client-trust,omitempty"`
+
+	// History records the identifiers previously used by this path, e.g. before an expired client was recreated.
+	History []PathRecord `yaml:"history,omitempty" json:"history,omitempty"`
+}
+
+// PathRecord is a set of identifiers that a path no longer relays on, kept for audit purposes.
+type PathRecord struct {
+	Src         PathEnd   `yaml:"src" json:"src"`
+	Dst         PathEnd   `yaml:"dst" json:"dst"`
+	ChannelList []string  `yaml:"channel-list,omitempty" json:"channel-list,omitempty"`
+	ReplacedAt  time.Time `yaml:"replaced-at" json:"replaced-at"`
+	Reason      string    `yaml:"reason" json:"reason"`
 }
 
 // Named path wraps a Path with its name.
@@ -192,6 +228,54 @@ func GenPath(srcChainID, dstChainID string) *Path {
 	}
 }
 
+// LocalhostPath returns a path between the modules of a single chain over its 09-localhost client and connection,
+// which exist on every chain with ibc-go v7.1 or later and need not be created.
+func LocalhostPath(chainID string) *Path {
+	return &Path{
+		Src: &PathEnd{
+			ChainID:      chainID,
+			ClientID:     ibcexported.LocalhostClientID,
+			ConnectionID: ibcexported.LocalhostConnectionID,
+		},
+		Dst: &PathEnd{
+			ChainID:      chainID,
+			ClientID:     ibcexported.LocalhostClientID,
+			ConnectionID: ibcexported.LocalhostConnectionID,
+		},
+	}
+}
+
+// IsLocalhost returns true if the path relays between the modules of a single chain over its 09-localhost client.
+func (p *Path) IsLocalhost() bool {
+	return p.Src.ChainID == p.Dst.ChainID &&
+		p.Src.ClientID == ibcexported.LocalhostClientID && p.Dst.ClientID == ibcexported.LocalhostClientID
+}
+
+// ValidateLocalhost checks that a path whose src and dst are the same chain relays over the 09-localhost client
+// and connection of the chain, which are the only client and connection of a chain to itself.
+func (p *Path) ValidateLocalhost() error {
+	if p.Src.ChainID != p.Dst.ChainID {
+		for _, pe := range []*PathEnd{p.Src, p.Dst} {
+			if pe.ClientType == ClientTypeLocalhost {
+				return fmt.Errorf("client-type %s of chain %s requires a path from the chain to itself",
+					ClientTypeLocalhost, pe.ChainID)
+			}
+		}
+		return nil
+	}
+	for _, pe := range []*PathEnd{p.Src, p.Dst} {
+		if pe.ClientID != "" && pe.ClientID != ibcexported.LocalhostClientID {
+			return fmt.Errorf("path from chain %s to itself must use the %s client, got %s",
+				pe.ChainID, ibcexported.LocalhostClientID, pe.ClientID)
+		}
+		if pe.ConnectionID != "" && pe.ConnectionID != ibcexported.LocalhostConnectionID {
+			return fmt.Errorf("path from chain %s to itself must use the %s connection, got %s",
+				pe.ChainID, ibcexported.LocalhostConnectionID, pe.ConnectionID)
+		}
+	}
+	return nil
+}
+
 // PathStatus holds the status of the primitives in the path
 type PathStatus struct {
 	Chains     bool `yaml:"chains" json:"chains"`
@@ -252,21 +336,24 @@ func (p *Path) QueryPathStatus(ctx context.Context, src, dst *Chain) *PathWithSt
 		return out
 	}
 
-	srcExpiration, srcClientInfo, errSrc := QueryClientExpiration(ctx, src, dst)
-	if errSrc != nil {
-		return out
-	}
+	// the localhost client tracks the chain itself, so it never expires.
+	if !p.IsLocalhost() {
+		srcExpiration, srcClientInfo, errSrc := QueryClientExpiration(ctx, src, dst)
+		if errSrc != nil {
+			return out
+		}
 
-	dstExpiration, dstClientInfo, errDst := QueryClientExpiration(ctx, dst, src)
-	if errDst != nil {
-		return out
-	}
+		dstExpiration, dstClientInfo, errDst := QueryClientExpiration(ctx, dst, src)
+		if errDst != nil {
+			return out
+		}
 
-	srcData := SPrintClientExpiration(src, srcExpiration, srcClientInfo)
-	dstData := SPrintClientExpiration(dst, dstExpiration, dstClientInfo)
+		srcData := SPrintClientExpiration(src, srcExpiration, srcClientInfo)
+		dstData := SPrintClientExpiration(dst, dstExpiration, dstClientInfo)
 
-	if strings.Contains(srcData, Expired) || strings.Contains(dstData, Expired) {
-		return out
+		if strings.Contains(srcData, Expired) || strings.Contains(dstData, Expired) {
+			return out
+		}
 	}
 	out.Status.Clients = true
 
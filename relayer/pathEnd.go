@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 )
 
 // PathEnd represents the local connection identifiers for a relay path
@@ -12,6 +13,28 @@ type PathEnd struct {
 	ChainID      string `yaml:"chain-id,omitempty" json:"chain-id,omitempty"`
 	ClientID     string `yaml:"client-id,omitempty" json:"client-id,omitempty"`
 	ConnectionID string `yaml:"connection-id,omitempty" json:"connection-id,omitempty"`
+
+	// ConnectionHops are the connections, starting with ConnectionID, over which channels are opened on this chain
+	// to reach the counterparty chain through intermediary chains, for ICS-33 multihop channels.
+	// If empty, channels are opened over ConnectionID alone.
+	ConnectionHops []string `yaml:"connection-hops,omitempty" json:"connection-hops,omitempty"`
+
+	// ClientType is the type of the light client on this chain tracking the counterparty chain, by which the messages
+	// creating and updating the client are built: tendermint, wasm, solomachine or localhost.
+	// If empty, it is localhost for the 09-localhost client and tendermint otherwise.
+	ClientType string `yaml:"client-type,omitempty" json:"client-type,omitempty"`
+}
+
+// clientType returns the type of the client of the path end, defaulting as documented on ClientType.
+func (pe *PathEnd) clientType() string {
+	switch {
+	case pe.ClientType != "":
+		return pe.ClientType
+	case pe.ClientID == ibcexported.LocalhostClientID:
+		return ClientTypeLocalhost
+	default:
+		return ClientTypeTendermint
+	}
 }
 
 // OrderFromString parses a string into a channel order byte
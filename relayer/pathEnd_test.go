@@ -40,3 +40,42 @@ func TestStringFromOrder(t *testing.T) {
 	empty := StringFromOrder(none)
 	require.Equal(t, "", empty)
 }
+
+func TestPathEndConnectionHops(t *testing.T) {
+	pe := &PathEnd{ClientID: "07-tendermint-0", ConnectionID: "connection-0"}
+	require.NoError(t, pe.ValidateFull())
+
+	pe.ConnectionHops = []string{"connection-0", "connection-5"}
+	require.NoError(t, pe.ValidateFull())
+
+	// the first hop is the connection of the path end.
+	pe.ConnectionHops = []string{"connection-1", "connection-5"}
+	require.Error(t, pe.ValidateFull())
+
+	pe.ConnectionHops = []string{"connection-0", "channel-5"}
+	require.Error(t, pe.ValidateFull())
+}
+
+func TestPathEndClientType(t *testing.T) {
+	pe := &PathEnd{ClientID: "07-tendermint-0"}
+	require.NoError(t, pe.ValidateFull())
+	require.Equal(t, ClientTypeTendermint, pe.clientType())
+
+	pe = &PathEnd{ClientID: "09-localhost"}
+	require.Equal(t, ClientTypeLocalhost, pe.clientType())
+
+	pe = &PathEnd{ClientType: ClientTypeSolomachine}
+	require.NoError(t, pe.ValidateFull())
+	require.Equal(t, ClientTypeSolomachine, pe.clientType())
+
+	// wasm clients require a registered light client.
+	pe = &PathEnd{ClientType: ClientTypeWasm}
+	require.ErrorContains(t, pe.ValidateFull(), "invalid client-type wasm")
+
+	pe.ClientType = "beefy"
+	require.ErrorContains(t, pe.ValidateFull(), "invalid client-type beefy")
+
+	// the localhost client type only applies to the 09-localhost client.
+	pe = &PathEnd{ClientID: "07-tendermint-0", ClientType: ClientTypeLocalhost}
+	require.Error(t, pe.ValidateFull())
+}
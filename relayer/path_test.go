@@ -0,0 +1,28 @@
+package relayer
+
+import (
+	"testing"
+
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalhostPath(t *testing.T) {
+	p := LocalhostPath("ibc-0")
+	require.True(t, p.IsLocalhost())
+	require.NoError(t, p.ValidateLocalhost())
+	require.Equal(t, ibcexported.LocalhostConnectionID, p.Src.ConnectionID)
+	require.Equal(t, ibcexported.LocalhostConnectionID, p.Dst.ConnectionID)
+
+	require.False(t, GenPath("ibc-0", "ibc-1").IsLocalhost())
+	require.NoError(t, GenPath("ibc-0", "ibc-1").ValidateLocalhost())
+
+	// a chain has no client or connection to itself other than the localhost ones.
+	p.Dst.ClientID = "07-tendermint-0"
+	require.False(t, p.IsLocalhost())
+	require.Error(t, p.ValidateLocalhost())
+
+	p = LocalhostPath("ibc-0")
+	p.Src.ConnectionID = "connection-0"
+	require.Error(t, p.ValidateLocalhost())
+}
@@ -0,0 +1,139 @@
+package processor
+
+import (
+	"context"
+	"sort"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// unreceivedQueryBatchSize is how many packet sequences are checked for receipts by a single unreceived packets query
+// when detecting a backlog, so that the queries for channels with large backlogs stay within the limits of the node.
+const unreceivedQueryBatchSize = 1000
+
+// packetBacklog is the backlog of the packets sent on a channel, detected by diffing their commitments on the chain
+// which sent them against the receipts of the counterparty.
+type packetBacklog struct {
+	// unrecv are the sequences of the packets which have not been received by the counterparty, in order.
+	unrecv []uint64
+
+	// unacked are the sequences of the packets which have been received and acknowledged by the counterparty,
+	// but whose commitment has not been cleared by relaying their acknowledgement. Packets without an ack
+	// commitment on the counterparty, e.g. those acknowledged asynchronously, have no ack to relay yet.
+	unacked []uint64
+
+	// order is the ordering of the channel, which is only queried if there are unreceived packets.
+	order chantypes.Order
+}
+
+// queryPacketBacklog detects the backlog of the packets sent by src on channel k from their commitments seqs,
+// by querying which of them have not been received by dst. Only the state of the chains is queried, so the backlog
+// of packets of any age is detected in full, even from nodes which have tx indexing disabled.
+// The unrelayed packets and acks metrics of the channel are set from the backlog.
+func (pp *PathProcessor) queryPacketBacklog(
+	ctx context.Context,
+	src, dst *pathEndRuntime,
+	k ChannelKey,
+	seqs []uint64,
+) (packetBacklog, error) {
+	var backlog packetBacklog
+	if len(seqs) > 0 {
+		var err error
+		backlog, err = pp.queryUnreceivedPackets(ctx, dst, k.Counterparty(), seqs)
+		if err != nil {
+			return backlog, err
+		}
+	}
+
+	received := make(map[uint64]bool, len(seqs))
+	for _, seq := range seqs {
+		received[seq] = true
+	}
+	for _, seq := range backlog.unrecv {
+		delete(received, seq)
+	}
+	if len(received) > 0 {
+		ck := k.Counterparty()
+		acks, err := dst.chainProvider.QueryPacketAcknowledgements(ctx, dst.latestBlock.Height, ck.ChannelID, ck.PortID)
+		if err != nil {
+			return backlog, err
+		}
+		acked := make(map[uint64]bool, len(acks))
+		for _, ack := range acks {
+			acked[ack.Sequence] = true
+		}
+		for _, seq := range seqs {
+			if received[seq] && acked[seq] {
+				backlog.unacked = append(backlog.unacked, seq)
+			}
+		}
+	}
+
+	if pp.metrics != nil {
+		pp.metrics.SetUnrelayedPackets(pp.pathEnd1.info.PathName, src.info.ChainID, dst.info.ChainID, k.ChannelID, k.CounterpartyChannelID, len(backlog.unrecv))
+		pp.metrics.SetUnrelayedAcks(pp.pathEnd1.info.PathName, src.info.ChainID, dst.info.ChainID, k.ChannelID, k.CounterpartyChannelID, len(backlog.unacked))
+	}
+	return backlog, nil
+}
+
+// queryUnreceivedPackets queries which of the packets seqs sent to channel k of dst have not been received by it,
+// in batches of unreceivedQueryBatchSize sequences. Packets of ordered channels are received in order, so only
+// those from the next sequence to receive on dst are unreceived.
+func (pp *PathProcessor) queryUnreceivedPackets(
+	ctx context.Context,
+	dst *pathEndRuntime,
+	k ChannelKey,
+	seqs []uint64,
+) (packetBacklog, error) {
+	var backlog packetBacklog
+
+	batches := make([][]uint64, (len(seqs)+unreceivedQueryBatchSize-1)/unreceivedQueryBatchSize)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(pp.concurrency.queryLimit())
+	for i := range batches {
+		i := i
+		batch := seqs[i*unreceivedQueryBatchSize : min((i+1)*unreceivedQueryBatchSize, len(seqs))]
+		eg.Go(func() error {
+			var err error
+			batches[i], err = dst.chainProvider.QueryUnreceivedPackets(egCtx, dst.latestBlock.Height, k.ChannelID, k.PortID, batch)
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return backlog, err
+	}
+	for _, batch := range batches {
+		backlog.unrecv = append(backlog.unrecv, batch...)
+	}
+	if len(backlog.unrecv) == 0 {
+		return backlog, nil
+	}
+
+	dstHeight := int64(dst.latestBlock.Height)
+	channel, err := dst.chainProvider.QueryChannel(ctx, dstHeight, k.ChannelID, k.PortID)
+	if err != nil {
+		return backlog, err
+	}
+	backlog.order = channel.Channel.Ordering
+
+	if backlog.order == chantypes.ORDERED {
+		nextSeqRecv, err := dst.chainProvider.QueryNextSeqRecv(ctx, dstHeight, k.ChannelID, k.PortID)
+		if err != nil {
+			return backlog, err
+		}
+
+		var unrecv []uint64
+		for _, seq := range backlog.unrecv {
+			if seq >= nextSeqRecv.NextSequenceReceive {
+				unrecv = append(unrecv, seq)
+			}
+		}
+		backlog.unrecv = unrecv
+	}
+
+	sort.Slice(backlog.unrecv, func(i, j int) bool {
+		return backlog.unrecv[i] < backlog.unrecv[j]
+	})
+	return backlog, nil
+}
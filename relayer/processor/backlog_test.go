@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// receiptsProvider is a chain which has received the packets with the sequences received on a channel,
+// and records the number of sequences of each unreceived packets query.
+type receiptsProvider struct {
+	provider.ChainProvider
+
+	received    map[uint64]bool
+	acked       map[uint64]bool
+	order       chantypes.Order
+	nextSeqRecv uint64
+
+	mu      sync.Mutex
+	queries []int
+}
+
+func (p *receiptsProvider) QueryUnreceivedPackets(_ context.Context, _ uint64, _, _ string, seqs []uint64) ([]uint64, error) {
+	p.mu.Lock()
+	p.queries = append(p.queries, len(seqs))
+	p.mu.Unlock()
+
+	var unrecv []uint64
+	for _, seq := range seqs {
+		if !p.received[seq] {
+			unrecv = append(unrecv, seq)
+		}
+	}
+	return unrecv, nil
+}
+
+func (p *receiptsProvider) QueryPacketAcknowledgements(context.Context, uint64, string, string) ([]*chantypes.PacketState, error) {
+	var acks []*chantypes.PacketState
+	for seq := range p.acked {
+		acks = append(acks, &chantypes.PacketState{Sequence: seq})
+	}
+	return acks, nil
+}
+
+func (p *receiptsProvider) QueryChannel(context.Context, int64, string, string) (*chantypes.QueryChannelResponse, error) {
+	return &chantypes.QueryChannelResponse{Channel: &chantypes.Channel{Ordering: p.order}}, nil
+}
+
+func (p *receiptsProvider) QueryNextSeqRecv(context.Context, int64, string, string) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	return &chantypes.QueryNextSequenceReceiveResponse{NextSequenceReceive: p.nextSeqRecv}, nil
+}
+
+func TestQueryPacketBacklog(t *testing.T) {
+	seqs := func(from, to uint64) (s []uint64) {
+		for seq := from; seq <= to; seq++ {
+			s = append(s, seq)
+		}
+		return s
+	}
+	received := func(seqs ...uint64) map[uint64]bool {
+		r := make(map[uint64]bool)
+		for _, seq := range seqs {
+			r[seq] = true
+		}
+		return r
+	}
+
+	for _, tc := range []struct {
+		name            string
+		commitments     []uint64
+		provider        *receiptsProvider
+		expectedUnrecv  []uint64
+		expectedUnacked []uint64
+		expectedQueries []int
+	}{
+		{
+			name:        "no commitments",
+			provider:    &receiptsProvider{},
+			commitments: nil,
+		},
+		{
+			name:            "received packets are awaiting their acks",
+			commitments:     []uint64{3, 1, 2},
+			provider:        &receiptsProvider{received: received(2), acked: received(2), order: chantypes.UNORDERED},
+			expectedUnrecv:  []uint64{1, 3},
+			expectedUnacked: []uint64{2},
+			expectedQueries: []int{3},
+		},
+		{
+			name:            "received packets without ack commitment have no ack to relay",
+			commitments:     []uint64{1, 2, 3},
+			provider:        &receiptsProvider{received: received(1, 2, 3), acked: received(2), order: chantypes.UNORDERED},
+			expectedUnacked: []uint64{2},
+			expectedQueries: []int{3},
+		},
+		{
+			name:            "large backlogs are queried in batches",
+			commitments:     seqs(1, 2500),
+			provider:        &receiptsProvider{received: received(1, 2500), acked: received(1, 2500), order: chantypes.UNORDERED},
+			expectedUnrecv:  seqs(2, 2499),
+			expectedUnacked: []uint64{1, 2500},
+			expectedQueries: []int{1000, 1000, 500},
+		},
+		{
+			name:            "packets of ordered channels are unreceived from the next sequence to receive",
+			commitments:     seqs(1, 4),
+			provider:        &receiptsProvider{acked: received(1, 2), order: chantypes.ORDERED, nextSeqRecv: 3},
+			expectedUnrecv:  []uint64{3, 4},
+			expectedUnacked: []uint64{1, 2},
+			expectedQueries: []int{4},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pp := &PathProcessor{
+				log:      zap.NewNop(),
+				pathEnd1: &pathEndRuntime{info: PathEnd{PathName: "demo-path", ChainID: "chain-a"}},
+				pathEnd2: &pathEndRuntime{info: PathEnd{PathName: "demo-path", ChainID: "chain-b"}, chainProvider: tc.provider},
+			}
+			k := ChannelKey{ChannelID: "channel-0", PortID: "transfer", CounterpartyChannelID: "channel-1", CounterpartyPortID: "transfer"}
+
+			backlog, err := pp.queryPacketBacklog(context.Background(), pp.pathEnd1, pp.pathEnd2, k, tc.commitments)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedUnrecv, backlog.unrecv)
+			require.Equal(t, tc.expectedUnacked, backlog.unacked)
+
+			slices.Sort(tc.provider.queries)
+			slices.Sort(tc.expectedQueries)
+			require.Equal(t, tc.expectedQueries, tc.provider.queries)
+		})
+	}
+}
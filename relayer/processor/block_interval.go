@@ -0,0 +1,56 @@
+package processor
+
+import (
+	"sync"
+	"time"
+)
+
+// blockIntervalSmoothing is the weight of the latest measured block interval in the moving average of BlockInterval.
+const blockIntervalSmoothing = 0.2
+
+// BlockInterval measures the interval between the blocks of a chain from the heights and times of the blocks it
+// observes, as an exponential moving average which follows changes of the block time of the chain.
+// It is safe for concurrent use.
+type BlockInterval struct {
+	mu      sync.Mutex
+	height  uint64
+	time    time.Time
+	average time.Duration
+}
+
+// Observe measures the interval between the block and the previously observed block.
+// Blocks which are not later than the previously observed block are ignored.
+func (b *BlockInterval) Observe(height uint64, blockTime time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if height <= b.height || blockTime.IsZero() {
+		return
+	}
+	if b.height != 0 && blockTime.After(b.time) {
+		interval := blockTime.Sub(b.time) / time.Duration(height-b.height)
+		if b.average == 0 {
+			b.average = interval
+		} else {
+			b.average += time.Duration(blockIntervalSmoothing * float64(interval-b.average))
+		}
+	}
+	b.height, b.time = height, blockTime
+}
+
+// Duration returns the average interval between blocks, or 0 until two blocks have been observed.
+func (b *BlockInterval) Duration() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.average
+}
+
+// Blocks returns the duration of n blocks, bounded by minDuration and maxDuration,
+// or fallback until the interval between blocks has been measured.
+func (b *BlockInterval) Blocks(n float64, minDuration, maxDuration, fallback time.Duration) time.Duration {
+	average := b.Duration()
+	if average == 0 {
+		return fallback
+	}
+	return min(max(time.Duration(n*float64(average)), minDuration), maxDuration)
+}
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockInterval(t *testing.T) {
+	var b BlockInterval
+	start := time.Unix(1700000000, 0)
+
+	// the interval is unknown until two blocks are observed.
+	require.Zero(t, b.Duration())
+	require.Equal(t, time.Second, b.Blocks(1, 0, time.Hour, time.Second))
+	b.Observe(100, start)
+	require.Zero(t, b.Duration())
+
+	// intervals spanning several blocks are averaged over the blocks.
+	b.Observe(103, start.Add(18*time.Second))
+	require.Equal(t, 6*time.Second, b.Duration())
+
+	// earlier blocks and blocks already observed are ignored.
+	b.Observe(103, start.Add(time.Minute))
+	b.Observe(101, start.Add(time.Minute))
+	require.Equal(t, 6*time.Second, b.Duration())
+
+	// the average follows changes of the block time.
+	now := start.Add(18 * time.Second)
+	for h := uint64(104); h < 150; h++ {
+		now = now.Add(time.Second)
+		b.Observe(h, now)
+	}
+	require.InDelta(t, float64(time.Second), float64(b.Duration()), float64(10*time.Millisecond))
+
+	require.Equal(t, 250*time.Millisecond, b.Blocks(0.25, 100*time.Millisecond, 5*time.Second, 0).Round(time.Millisecond))
+	require.Equal(t, time.Minute, b.Blocks(50, time.Minute, 15*time.Minute, 0))
+	require.Equal(t, 5*time.Second, b.Blocks(10, 0, 5*time.Second, 0))
+}
@@ -22,5 +22,20 @@ type ChainProcessor interface {
 	SetPathProcessors(pathProcessors PathProcessors)
 }
 
+// StartHeightSetter is implemented by ChainProcessors which can be made to start querying blocks from a given height,
+// rather than initialBlockHistory blocks before the latest height, e.g. to replay the events of a chain after a crash.
+type StartHeightSetter interface {
+	// SetStartHeight sets the height of the first block to query when the ChainProcessor is run.
+	SetStartHeight(height uint64)
+}
+
+// QueryWaker is implemented by ChainProcessors which can be made to query the new blocks of their chain as soon as
+// they are produced, e.g. when a block with messages to relay is about to be produced.
+type QueryWaker interface {
+	// QueryUntil makes the ChainProcessor query new blocks immediately, and as fast as possible
+	// until it has queried the block at height.
+	QueryUntil(height uint64)
+}
+
 // ChainProcessors is a slice of ChainProcessor instances.
 type ChainProcessors []ChainProcessor
@@ -0,0 +1,38 @@
+package processor
+
+import "time"
+
+// ChainUpgrade is an upgrade of a chain scheduled by its upgrade module, at whose height the chain halts
+// until it is restarted with the upgraded binary.
+type ChainUpgrade struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+
+	// Time is when the chain is estimated to reach the upgrade height from its block interval,
+	// zero if the block interval is not yet known.
+	Time time.Time `json:"time,omitempty"`
+
+	// Quiesced is set once the chain is close enough to the upgrade height for relaying to it to be paused,
+	// until the chain produces blocks past the upgrade height.
+	Quiesced bool `json:"quiesced"`
+}
+
+// SetChainUpgrade is called by ChainProcessors with the upgrade scheduled on the chain with the given ID,
+// or nil once there is none. Messages are not sent to the chain while the upgrade is quiesced.
+func (pp *PathProcessor) SetChainUpgrade(chainID string, upgrade *ChainUpgrade) {
+	for _, pathEnd := range []*pathEndRuntime{pp.pathEnd1, pp.pathEnd2} {
+		if pathEnd.info.ChainID == chainID {
+			pathEnd.upgrade.Store(upgrade)
+		}
+	}
+}
+
+// paused returns true while messages must not be sent to the chain of the path end,
+// because it is halted or about to halt for an upgrade.
+func (pathEnd *pathEndRuntime) paused() bool {
+	if pathEnd.halted.Load() {
+		return true
+	}
+	upgrade := pathEnd.upgrade.Load()
+	return upgrade != nil && upgrade.Quiesced
+}
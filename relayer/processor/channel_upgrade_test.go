@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestChannelUpgradeMessages() pathEndChannelUpgradeMessages {
+	log := zap.NewNop()
+	src := newPathEndRuntime(log, PathEnd{ChainID: testChainID0}, nil)
+	dst := newPathEndRuntime(log, PathEnd{ChainID: testChainID1}, nil)
+	src.latestBlock = provider.LatestBlock{Height: 100, Time: time.Now()}
+	dst.latestBlock = provider.LatestBlock{Height: 100, Time: time.Now()}
+
+	return pathEndChannelUpgradeMessages{
+		Src:                         src,
+		Dst:                         dst,
+		SrcMsgChannelUpgradeInit:    make(ChannelMessageCache),
+		SrcMsgChannelUpgradeTry:     make(ChannelMessageCache),
+		SrcMsgChannelUpgradeAck:     make(ChannelMessageCache),
+		SrcMsgChannelUpgradeConfirm: make(ChannelMessageCache),
+		SrcMsgChannelFlushComplete:  make(ChannelMessageCache),
+		SrcMsgChannelUpgradeOpen:    make(ChannelMessageCache),
+		SrcMsgChannelUpgradeError:   make(ChannelMessageCache),
+		DstMsgChannelUpgradeInit:    make(ChannelMessageCache),
+		DstMsgChannelUpgradeTry:     make(ChannelMessageCache),
+		DstMsgChannelUpgradeAck:     make(ChannelMessageCache),
+		DstMsgChannelUpgradeConfirm: make(ChannelMessageCache),
+		DstMsgChannelFlushComplete:  make(ChannelMessageCache),
+		DstMsgChannelUpgradeOpen:    make(ChannelMessageCache),
+		DstMsgChannelUpgradeError:   make(ChannelMessageCache),
+	}
+}
+
+func testChannelUpgradeInfo(upgradeSequence uint64, state chantypes.State) (ChannelKey, provider.ChannelInfo) {
+	info := provider.ChannelInfo{
+		Height:                50,
+		PortID:                testPort,
+		ChannelID:             testChannel0,
+		CounterpartyPortID:    testPort,
+		CounterpartyChannelID: testChannel1,
+		UpgradeSequence:       upgradeSequence,
+		State:                 state,
+	}
+	return ChannelInfoChannelKey(info), info
+}
+
+func eventTypes(msgs []channelIBCMessage) (eventTypes []string) {
+	for _, msg := range msgs {
+		eventTypes = append(eventTypes, msg.eventType)
+	}
+	return eventTypes
+}
+
+func TestUnrelayedChannelUpgradeMessages(t *testing.T) {
+	pp := &PathProcessor{log: zap.NewNop()}
+
+	t.Run("init sends try", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.OPEN)
+		m.SrcMsgChannelUpgradeInit[chanKey] = info
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Empty(t, res.SrcMessages)
+		require.Equal(t, []string{chantypes.EventTypeChannelUpgradeTry}, eventTypes(res.DstMessages))
+	})
+
+	t.Run("init already tried", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.OPEN)
+		m.SrcMsgChannelUpgradeInit[chanKey] = info
+		_, dstInfo := testChannelUpgradeInfo(1, chantypes.FLUSHING)
+		m.DstMsgChannelUpgradeTry[chanKey.Counterparty()] = dstInfo
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Empty(t, res.DstMessages)
+	})
+
+	t.Run("try sends ack", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.FLUSHING)
+		m.SrcMsgChannelUpgradeTry[chanKey] = info
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Equal(t, []string{chantypes.EventTypeChannelUpgradeAck}, eventTypes(res.DstMessages))
+	})
+
+	t.Run("crossing hello try sends confirm", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.FLUSHING)
+		m.SrcMsgChannelUpgradeTry[chanKey] = info
+		m.DstMsgChannelUpgradeTry[chanKey.Counterparty()] = info
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Equal(t, []string{chantypes.EventTypeChannelUpgradeConfirm}, eventTypes(res.DstMessages))
+	})
+
+	t.Run("flush complete on both ends sends open", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.FLUSHCOMPLETE)
+		m.SrcMsgChannelUpgradeConfirm[chanKey] = info
+		m.DstMsgChannelUpgradeAck[chanKey.Counterparty()] = info
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Equal(t, []string{chantypes.EventTypeChannelUpgradeOpen}, eventTypes(res.DstMessages))
+	})
+
+	t.Run("error sends cancel", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.OPEN)
+		m.SrcMsgChannelUpgradeError[chanKey] = info
+		m.DstMsgChannelUpgradeTry[chanKey.Counterparty()] = info
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Equal(t, []string{chantypes.EventTypeChannelUpgradeCancel}, eventTypes(res.DstMessages))
+	})
+
+	t.Run("elapsed upgrade timeout sends timeout to src", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.FLUSHING)
+		info.UpgradeTimeoutHeight = clienttypes.NewHeight(clienttypes.ParseChainID(testChainID1), 90)
+		m.SrcMsgChannelUpgradeAck[chanKey] = info
+		m.DstMsgChannelUpgradeConfirm[chanKey.Counterparty()] = provider.ChannelInfo{State: chantypes.FLUSHING, UpgradeSequence: 1}
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Equal(t, []string{chantypes.EventTypeChannelUpgradeTimeout}, eventTypes(res.SrcMessages))
+		require.Equal(t, testChannel1, res.SrcMessages[0].info.ChannelID)
+	})
+
+	t.Run("open on both ends removes retention", func(t *testing.T) {
+		m := newTestChannelUpgradeMessages()
+		chanKey, info := testChannelUpgradeInfo(1, chantypes.OPEN)
+		m.Src.messageCache.ChannelHandshake.Retain(chanKey, chantypes.EventTypeChannelUpgradeOpen, info)
+		m.SrcMsgChannelUpgradeOpen[chanKey] = info
+		m.DstMsgChannelUpgradeOpen[chanKey.Counterparty()] = info
+
+		res := pp.unrelayedChannelUpgradeMessages(m)
+		require.Empty(t, res.SrcMessages)
+		require.Empty(t, res.DstMessages)
+		require.Empty(t, m.Src.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeOpen])
+	})
+}
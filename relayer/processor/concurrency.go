@@ -0,0 +1,61 @@
+package processor
+
+import "context"
+
+// Concurrency limits how much work a PathProcessor does at once, so that it can be tuned to the chains of its path,
+// e.g. a chain with fast blocks benefits from more transactions in flight than a chain with slow blocks.
+// Zero values do not limit the PathProcessor.
+type Concurrency struct {
+	// MaxInFlightTxs is how many transactions broadcast to each chain of the path may await inclusion at once.
+	MaxInFlightTxs int
+
+	// Workers is how many messages of the path are assembled at once, each of which queries proofs.
+	Workers int
+
+	// QueryConcurrency is how many channels are flushed at once, and how many packets are queried at once
+	// when flushing a channel.
+	QueryConcurrency int
+
+	// Weight is the share of the path in the transactions broadcast per block to a chain shared with other paths,
+	// relative to the weights of the other paths, if a tx budget per block is set. Zero is a weight of 1.
+	Weight int
+}
+
+// queryLimit returns the limit of an errgroup running queries, which is negative if queries are not limited.
+func (c Concurrency) queryLimit() int {
+	if c.QueryConcurrency <= 0 {
+		return -1
+	}
+	return c.QueryConcurrency
+}
+
+// semaphore limits how many holders hold it at once. A nil semaphore does not limit them.
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+// acquire blocks until the semaphore is acquired, or the context is done.
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release releases the semaphore, which must have been acquired.
+func (s semaphore) release() {
+	if s == nil {
+		return
+	}
+	<-s
+}
@@ -0,0 +1,33 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphore(t *testing.T) {
+	ctx := context.Background()
+
+	// an unset limit does not limit the holders.
+	unlimited := newSemaphore(0)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, unlimited.acquire(ctx))
+	}
+	unlimited.release()
+
+	s := newSemaphore(2)
+	require.NoError(t, s.acquire(ctx))
+	require.NoError(t, s.acquire(ctx))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, s.acquire(cancelled), context.Canceled)
+
+	s.release()
+	require.NoError(t, s.acquire(ctx))
+
+	require.Equal(t, -1, Concurrency{}.queryLimit())
+	require.Equal(t, 8, Concurrency{QueryConcurrency: 8}.queryLimit())
+}
@@ -0,0 +1,304 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// txResultsToRetain is how many of the most recent tx results of a PathProcessor are retained for inspection.
+const txResultsToRetain = 100
+
+// statusRecentErrors is how many of the most recent failed transactions are included in the status of a path.
+const statusRecentErrors = 5
+
+// QueuedPacket is a packet flow message observed on a chain which the PathProcessor has yet to relay.
+type QueuedPacket struct {
+	ChainID   string `json:"chain_id"`
+	EventType string `json:"event_type"`
+	Sequence  uint64 `json:"sequence"`
+	Height    uint64 `json:"height"`
+
+	SourceChannelID string `json:"source_channel_id"`
+	SourcePortID    string `json:"source_port_id"`
+	DestChannelID   string `json:"dest_channel_id"`
+	DestPortID      string `json:"dest_port_id"`
+}
+
+// TxResult is the result of a transaction broadcast by a PathProcessor.
+type TxResult struct {
+	Time      time.Time               `json:"time"`
+	ChainID   string                  `json:"chain_id"`
+	MsgTypes  []string                `json:"msg_types"`
+	TxHash    string                  `json:"tx_hash,omitempty"`
+	Height    int64                   `json:"height,omitempty"`
+	Code      uint32                  `json:"code"`
+	Codespace string                  `json:"codespace,omitempty"`
+	Log       string                  `json:"log,omitempty"`
+	Events    []provider.RelayerEvent `json:"events,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// PathEndStatus is the state of one end of a path.
+type PathEndStatus struct {
+	ChainID  string `json:"chain_id"`
+	ClientID string `json:"client_id"`
+	InSync   bool   `json:"in_sync"`
+
+	// ClientExpiry is when the client on the chain expires unless it is updated, zero if not yet known.
+	ClientExpiry time.Time `json:"client_expiry"`
+
+	// Balance is the balance of the relayer wallet on the chain. It is not known to the PathProcessor,
+	// and is filled in by the ControlAPI.
+	Balance string `json:"balance,omitempty"`
+
+	// Upgrade is the upgrade scheduled on the chain, if any.
+	Upgrade *ChainUpgrade `json:"upgrade,omitempty"`
+}
+
+// PathStatus is a summary of the state of a path, as shown by rly tui.
+type PathStatus struct {
+	PathName string          `json:"path_name"`
+	Ends     []PathEndStatus `json:"ends"`
+
+	// PacketsPending is the number of packets observed to be sent which are yet to be acknowledged or timed out.
+	PacketsPending int `json:"packets_pending"`
+
+	// LastRelayed is when a transaction of the path last succeeded, zero if none has yet.
+	LastRelayed time.Time `json:"last_relayed"`
+
+	// RecentErrors are the most recent failed transactions of the path, most recent first.
+	RecentErrors []TxResult `json:"recent_errors"`
+}
+
+// txResultHistory retains the most recent tx results of a PathProcessor.
+type txResultHistory struct {
+	mu      sync.Mutex
+	results []TxResult
+}
+
+func (h *txResultHistory) add(result TxResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.results = append(h.results, result)
+	if len(h.results) > txResultsToRetain {
+		h.results = h.results[len(h.results)-txResultsToRetain:]
+	}
+}
+
+// last returns up to n of the most recent tx results, most recent first.
+func (h *txResultHistory) last(n int) []TxResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n <= 0 || n > len(h.results) {
+		n = len(h.results)
+	}
+	out := make([]TxResult, n)
+	for i := range out {
+		out[i] = h.results[len(h.results)-1-i]
+	}
+	return out
+}
+
+// PathName returns the name of the path the PathProcessor relays on.
+func (pp *PathProcessor) PathName() string {
+	return pp.pathEnd1.info.PathName
+}
+
+// control runs fn in the goroutine which runs the PathProcessor, which owns its state, and waits for it to complete.
+func (pp *PathProcessor) control(ctx context.Context, fn func(ctx context.Context)) error {
+	done := make(chan struct{})
+	request := func(ctx context.Context) {
+		defer close(done)
+		fn(ctx)
+	}
+
+	select {
+	case pp.controlRequests <- request:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QueuedPackets returns the packet flow messages which the PathProcessor has yet to relay,
+// ordered by chain, channel and sequence.
+func (pp *PathProcessor) QueuedPackets(ctx context.Context) ([]QueuedPacket, error) {
+	var queued []QueuedPacket
+	if err := pp.control(ctx, func(context.Context) {
+		for _, pathEnd := range []*pathEndRuntime{pp.pathEnd1, pp.pathEnd2} {
+			for _, messages := range pathEnd.messageCache.PacketFlow {
+				for eventType, sequences := range messages {
+					for sequence, info := range sequences {
+						queued = append(queued, QueuedPacket{
+							ChainID:         pathEnd.info.ChainID,
+							EventType:       eventType,
+							Sequence:        sequence,
+							Height:          info.Height,
+							SourceChannelID: info.SourceChannel,
+							SourcePortID:    info.SourcePort,
+							DestChannelID:   info.DestChannel,
+							DestPortID:      info.DestPort,
+						})
+					}
+				}
+			}
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(queued, func(i, j int) bool {
+		a, b := queued[i], queued[j]
+		if a.ChainID != b.ChainID {
+			return a.ChainID < b.ChainID
+		}
+		if a.SourceChannelID != b.SourceChannelID {
+			return a.SourceChannelID < b.SourceChannelID
+		}
+		if a.Sequence != b.Sequence {
+			return a.Sequence < b.Sequence
+		}
+		return a.EventType < b.EventType
+	})
+	return queued, nil
+}
+
+// QuarantinedPackets returns the packet flow messages which the PathProcessor quarantined after isolating them
+// as the cause of failed batches, those to send to the first chain of the path first, ordered by channel and sequence.
+func (pp *PathProcessor) QuarantinedPackets() []QuarantinedPacket {
+	return append(pp.pathEnd1.quarantine.list(), pp.pathEnd2.quarantine.list()...)
+}
+
+// ForceClientUpdates makes the PathProcessor update the clients on both chains of the path the next time it
+// processes messages, regardless of the client update threshold.
+func (pp *PathProcessor) ForceClientUpdates(ctx context.Context) error {
+	if pp.isLocalhost {
+		return fmt.Errorf("localhost clients of path %s cannot be updated", pp.PathName())
+	}
+	return pp.control(ctx, func(context.Context) {
+		pp.pathEnd1.forceClientUpdate = true
+		pp.pathEnd2.forceClientUpdate = true
+		pp.log.Info("Forcing client updates", zap.String("path_name", pp.PathName()))
+	})
+}
+
+// RetryPacket makes the PathProcessor relay the packet with the given sequence, sent on the given channel of chainID,
+// again as soon as possible. Its retry state is cleared, unless a message for it is being broadcast, it is released
+// from quarantine, and the path is flushed so that the packet is queued again if it was given up on.
+func (pp *PathProcessor) RetryPacket(ctx context.Context, chainID, channelID, portID string, sequence uint64) error {
+	if chainID != pp.pathEnd1.info.ChainID && chainID != pp.pathEnd2.info.ChainID {
+		return fmt.Errorf("chain %s is not on path %s", chainID, pp.PathName())
+	}
+	return pp.control(ctx, func(ctx context.Context) {
+		for _, pathEnd := range []*pathEndRuntime{pp.pathEnd1, pp.pathEnd2} {
+			pathEnd.quarantine.release(channelID, portID, sequence)
+			for k, messages := range pathEnd.packetProcessing {
+				if !(k.ChannelID == channelID && k.PortID == portID) &&
+					!(k.CounterpartyChannelID == channelID && k.CounterpartyPortID == portID) {
+					continue
+				}
+				for _, inProgress := range messages {
+					inProgress.mu.Lock()
+					if m, ok := inProgress.m[sequence]; ok && !m.isProcessing() {
+						delete(inProgress.m, sequence)
+					}
+					inProgress.mu.Unlock()
+				}
+			}
+		}
+		pp.log.Info("Retrying packet",
+			zap.String("path_name", pp.PathName()),
+			zap.String("chain_id", chainID),
+			zap.String("channel_id", channelID),
+			zap.String("port_id", portID),
+			zap.Uint64("sequence", sequence),
+		)
+		pp.handleFlush(ctx)
+	})
+}
+
+// RelaysChannel returns true if the PathProcessor relays the open channel with channelID and portID on chainID.
+func (pp *PathProcessor) RelaysChannel(ctx context.Context, chainID, channelID, portID string) (bool, error) {
+	relays := false
+	if err := pp.control(ctx, func(context.Context) {
+		for _, ends := range [][2]*pathEndRuntime{{pp.pathEnd1, pp.pathEnd2}, {pp.pathEnd2, pp.pathEnd1}} {
+			pathEnd, counterparty := ends[0], ends[1]
+			if pathEnd.info.ChainID != chainID {
+				continue
+			}
+			for k, cs := range pathEnd.channelStateCache {
+				if k.ChannelID != channelID || k.PortID != portID || !cs.Open {
+					continue
+				}
+				relays = relays || pathEnd.ShouldRelayChannel(ChainChannelKey{
+					ChainID:             chainID,
+					CounterpartyChainID: counterparty.info.ChainID,
+					ChannelKey:          k,
+				})
+			}
+		}
+	}); err != nil {
+		return false, err
+	}
+	return relays, nil
+}
+
+// TxResults returns up to n of the most recent results of the transactions broadcast by the PathProcessor,
+// most recent first. All retained results are returned if n is not positive.
+func (pp *PathProcessor) TxResults(n int) []TxResult {
+	return pp.txResults.last(n)
+}
+
+// SLAStats returns the rolling statistics of how promptly the PathProcessor relays packets.
+func (pp *PathProcessor) SLAStats() SLAStats {
+	return pp.sla.stats(pp.PathName(), time.Now())
+}
+
+// Status returns a summary of the state of the path.
+func (pp *PathProcessor) Status(ctx context.Context) (PathStatus, error) {
+	status := PathStatus{
+		PathName:       pp.PathName(),
+		PacketsPending: pp.SLAStats().PacketsPending,
+	}
+	if err := pp.control(ctx, func(context.Context) {
+		for _, pathEnd := range []*pathEndRuntime{pp.pathEnd1, pp.pathEnd2} {
+			end := PathEndStatus{
+				ChainID:  pathEnd.info.ChainID,
+				ClientID: pathEnd.info.ClientID,
+				InSync:   pathEnd.inSync,
+				Upgrade:  pathEnd.upgrade.Load(),
+			}
+			if cs := pathEnd.clientState; !cs.ConsensusTime.IsZero() && cs.TrustingPeriod > 0 {
+				end.ClientExpiry = cs.ConsensusTime.Add(cs.TrustingPeriod)
+			}
+			status.Ends = append(status.Ends, end)
+		}
+	}); err != nil {
+		return PathStatus{}, err
+	}
+
+	for _, result := range pp.txResults.last(0) {
+		if result.Error == "" && result.Code == 0 {
+			if status.LastRelayed.IsZero() {
+				status.LastRelayed = result.Time
+			}
+			continue
+		}
+		if len(status.RecentErrors) < statusRecentErrors {
+			status.RecentErrors = append(status.RecentErrors, result)
+		}
+	}
+	return status, nil
+}
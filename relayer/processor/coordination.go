@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PacketClaim announces the packet messages which a relayer intends to relay to a chain, so that cooperating
+// relayers do not relay them as well while the claim has not expired.
+type PacketClaim struct {
+	RelayerID string     `json:"relayer_id"`
+	ChainID   string     `json:"chain_id"`
+	EventType string     `json:"event_type"`
+	Channel   ChannelKey `json:"channel"`
+	Sequences []uint64   `json:"sequences"`
+	Expires   time.Time  `json:"expires"`
+}
+
+// Coordinator exchanges PacketClaims with cooperating relayers, to reduce the duplicate txs of relayers which
+// relay the same channels.
+type Coordinator interface {
+	// Claim announces that this relayer intends to relay the packet messages of the claim. The coordinator sets
+	// the RelayerID and the expiry of the claim.
+	Claim(claim PacketClaim)
+
+	// ClaimedByPeer returns the ID of the cooperating relayer whose claim of the packet message takes precedence
+	// over the claim of this relayer, if any.
+	ClaimedByPeer(chainID, eventType string, k ChannelKey, sequence uint64) (string, bool)
+}
+
+// claimedByPeer returns true if a cooperating relayer claimed the packet flow message with eventType and sequence
+// on channel k, so that this relayer leaves it to that relayer until the claim expires.
+func (pathEnd *pathEndRuntime) claimedByPeer(eventType string, k ChannelKey, sequence uint64) bool {
+	if pathEnd.coordinator == nil {
+		return false
+	}
+	relayerID, ok := pathEnd.coordinator.ClaimedByPeer(pathEnd.info.ChainID, eventType, k, sequence)
+	if ok {
+		pathEnd.log.Debug("Skipping packet message claimed by another relayer",
+			zap.String("event_type", eventType),
+			zap.Uint64("sequence", sequence),
+			zap.String("relayer_id", relayerID),
+			zap.Inline(k),
+		)
+	}
+	return ok
+}
+
+// claimPackets announces the assembled packet flow messages of trackers, which are about to be sent to this chain,
+// to the cooperating relayers, with one claim per event type and channel.
+func (pathEnd *pathEndRuntime) claimPackets(trackers []messageToTrack) {
+	if pathEnd.coordinator == nil {
+		return
+	}
+	type claimKey struct {
+		eventType string
+		channel   ChannelKey
+	}
+	var (
+		keys   []claimKey
+		claims = make(map[claimKey]*PacketClaim)
+	)
+	for _, tracker := range trackers {
+		t, ok := tracker.(packetMessageToTrack)
+		if !ok || t.assembled == nil {
+			continue
+		}
+		k, err := t.msg.channelKey()
+		if err != nil {
+			continue
+		}
+		ck := claimKey{eventType: t.msg.eventType, channel: k}
+		c, ok := claims[ck]
+		if !ok {
+			c = &PacketClaim{ChainID: pathEnd.info.ChainID, EventType: t.msg.eventType, Channel: k}
+			claims[ck] = c
+			keys = append(keys, ck)
+		}
+		c.Sequences = append(c.Sequences, t.msg.info.Sequence)
+	}
+	for _, ck := range keys {
+		pathEnd.coordinator.Claim(*claims[ck])
+	}
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockCoordinator records the claims of this relayer, and reports the sequences in peerClaims as claimed by a peer.
+type mockCoordinator struct {
+	claims     []PacketClaim
+	peerClaims map[uint64]bool
+}
+
+func (c *mockCoordinator) Claim(claim PacketClaim) {
+	c.claims = append(c.claims, claim)
+}
+
+func (c *mockCoordinator) ClaimedByPeer(_, _ string, _ ChannelKey, sequence uint64) (string, bool) {
+	return "relayer-0", c.peerClaims[sequence]
+}
+
+func TestPacketClaims(t *testing.T) {
+	coordinator := &mockCoordinator{peerClaims: map[uint64]bool{2: true}}
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+	pathEnd.coordinator = coordinator
+	pathEnd.latestBlock.Height = 100
+
+	msg := func(eventType string, seq uint64) packetIBCMessage {
+		return packetIBCMessage{
+			eventType: eventType,
+			info: provider.PacketInfo{
+				Height:        10,
+				Sequence:      seq,
+				SourceChannel: "channel-0",
+				SourcePort:    "transfer",
+				DestChannel:   "channel-1",
+				DestPort:      "transfer",
+			},
+		}
+	}
+	k, err := msg(chantypes.EventTypeRecvPacket, 1).channelKey()
+	require.NoError(t, err)
+	pathEnd.channelStateCache.SetOpen(k, true, chantypes.UNORDERED)
+
+	// packets claimed by a peer are left to it.
+	require.True(t, pathEnd.shouldSendPacketMessage(msg(chantypes.EventTypeRecvPacket, 1), pathEnd))
+	require.False(t, pathEnd.shouldSendPacketMessage(msg(chantypes.EventTypeRecvPacket, 2), pathEnd))
+
+	// assembled messages are claimed with one claim per event type and channel.
+	pathEnd.claimPackets([]messageToTrack{
+		packetMessageToTrack{msg: msg(chantypes.EventTypeRecvPacket, 1), assembled: mockRelayerMessage{}},
+		packetMessageToTrack{msg: msg(chantypes.EventTypeRecvPacket, 3), assembled: mockRelayerMessage{}},
+		packetMessageToTrack{msg: msg(chantypes.EventTypeRecvPacket, 4)},
+		packetMessageToTrack{msg: msg(chantypes.EventTypeTimeoutPacket, 5), assembled: mockRelayerMessage{}},
+	})
+	require.Len(t, coordinator.claims, 2)
+	require.Equal(t, PacketClaim{
+		ChainID:   "chain-b",
+		EventType: chantypes.EventTypeRecvPacket,
+		Channel:   k,
+		Sequences: []uint64{1, 3},
+	}, coordinator.claims[0])
+	require.Equal(t, chantypes.EventTypeTimeoutPacket, coordinator.claims[1].EventType)
+	require.Equal(t, []uint64{5}, coordinator.claims[1].Sequences)
+}
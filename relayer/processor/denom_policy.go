@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"fmt"
+	"slices"
+
+	sdkmath "cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+)
+
+// DenomPolicy restricts the ICS-20 transfers relayed on a path by the denom and amount of their tokens,
+// e.g. to refuse to relay transfers of a known exploited token. Packets which are not ICS-20 transfers
+// are not restricted.
+//
+// Denoms match the denom of the packet data, i.e. its full trace such as transfer/channel-0/uatom,
+// its base denom such as uatom, or its ibc/ hash as held on the sending chain.
+type DenomPolicy struct {
+	// Rule is RuleAllowList to only relay transfers of Denoms, RuleDenyList to relay transfers of
+	// all denoms but Denoms, or empty to relay transfers of all denoms.
+	Rule   string
+	Denoms []string
+
+	// MaxAmounts limits the amount of tokens of a single transfer by denom.
+	MaxAmounts map[string]sdkmath.Int
+}
+
+// check returns an error if the packet is an ICS-20 transfer which must not be relayed by the policy.
+func (p DenomPolicy) check(packetData []byte) error {
+	if p.Rule == "" && len(p.MaxAmounts) == 0 {
+		// no policy
+		return nil
+	}
+
+	var packet transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(packetData, &packet); err != nil || packet.Denom == "" {
+		// not an ICS-20 packet
+		return nil
+	}
+
+	listed := slices.ContainsFunc(p.Denoms, func(denom string) bool {
+		return denomMatches(denom, packet.Denom)
+	})
+	switch {
+	case p.Rule == RuleAllowList && !listed:
+		return fmt.Errorf("packet denom: %s is not allowlisted", packet.Denom)
+	case p.Rule == RuleDenyList && listed:
+		return fmt.Errorf("packet denom: %s is denylisted", packet.Denom)
+	}
+
+	for denom, maxAmount := range p.MaxAmounts {
+		if !denomMatches(denom, packet.Denom) {
+			continue
+		}
+		amount, ok := sdkmath.NewIntFromString(packet.Amount)
+		if !ok {
+			return fmt.Errorf("packet amount: %s of denom: %s is invalid", packet.Amount, packet.Denom)
+		}
+		if amount.GT(maxAmount) {
+			return fmt.Errorf("packet amount: %s of denom: %s exceeds limit: %s", amount, packet.Denom, maxAmount)
+		}
+	}
+
+	return nil
+}
+
+// denomMatches returns true if denom is the denom of ICS-20 packet data, its base denom, or its ibc/ hash.
+func denomMatches(denom, packetDenom string) bool {
+	if denom == packetDenom {
+		return true
+	}
+	trace := transfertypes.ParseDenomTrace(packetDenom)
+	return denom == trace.BaseDenom || denom == trace.IBCDenom()
+}
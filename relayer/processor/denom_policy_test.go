@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenomPolicy(t *testing.T) {
+	transfer := func(denom, amount string) []byte {
+		return transfertypes.NewFungibleTokenPacketData(denom, amount, "sender", "receiver", "").GetBytes()
+	}
+	exploited := "transfer/channel-7/uexploit"
+	exploitedIBCDenom := transfertypes.ParseDenomTrace(exploited).IBCDenom()
+
+	for _, tc := range []struct {
+		name   string
+		policy DenomPolicy
+		data   []byte
+		err    string
+	}{
+		{
+			name: "no policy",
+			data: transfer(exploited, "100"),
+		},
+		{
+			name:   "denylisted base denom",
+			policy: DenomPolicy{Rule: RuleDenyList, Denoms: []string{"uexploit"}},
+			data:   transfer(exploited, "100"),
+			err:    "is denylisted",
+		},
+		{
+			name:   "denylisted ibc denom",
+			policy: DenomPolicy{Rule: RuleDenyList, Denoms: []string{exploitedIBCDenom}},
+			data:   transfer(exploited, "100"),
+			err:    "is denylisted",
+		},
+		{
+			name:   "not denylisted",
+			policy: DenomPolicy{Rule: RuleDenyList, Denoms: []string{"uexploit"}},
+			data:   transfer("uatom", "100"),
+		},
+		{
+			name:   "allowlisted",
+			policy: DenomPolicy{Rule: RuleAllowList, Denoms: []string{"uatom"}},
+			data:   transfer("uatom", "100"),
+		},
+		{
+			name:   "not allowlisted",
+			policy: DenomPolicy{Rule: RuleAllowList, Denoms: []string{"uatom"}},
+			data:   transfer(exploited, "100"),
+			err:    "is not allowlisted",
+		},
+		{
+			name:   "not an ics-20 packet",
+			policy: DenomPolicy{Rule: RuleAllowList, Denoms: []string{"uatom"}},
+			data:   []byte(`{"type":"TYPE_EXECUTE_TX","data":"AQ=="}`),
+		},
+		{
+			name:   "amount within limit",
+			policy: DenomPolicy{MaxAmounts: map[string]sdkmath.Int{"uatom": sdkmath.NewInt(100)}},
+			data:   transfer("uatom", "100"),
+		},
+		{
+			name:   "amount exceeds limit",
+			policy: DenomPolicy{MaxAmounts: map[string]sdkmath.Int{"uatom": sdkmath.NewInt(100)}},
+			data:   transfer("transfer/channel-0/uatom", "101"),
+			err:    "exceeds limit: 100",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.check(tc.data)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package processor
 import (
 	"context"
 
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -13,6 +15,17 @@ type EventProcessorBuilder struct {
 	pathProcessors      PathProcessors
 	messageLifecycle    MessageLifecycle
 	stuckPacket         *StuckPacket
+	startHeights        map[string]uint64
+	auditProofHeights   bool
+	monitorOnly         bool
+	skipRelayedPackets  bool
+	forceBisection      bool
+	sameBlockAcks       bool
+	txsPerBlock         int
+	processedEvents     *ProcessedEvents
+	coordinator         Coordinator
+	notifier            *notify.Notifier
+	relayEvents         *eventstream.Stream
 }
 
 // EventProcessor is a built instance that is ready to be executed with Run(ctx).
@@ -22,6 +35,17 @@ type EventProcessor struct {
 	pathProcessors      PathProcessors
 	messageLifecycle    MessageLifecycle
 	stuckPacket         *StuckPacket
+	startHeights        map[string]uint64
+	auditProofHeights   bool
+	monitorOnly         bool
+	skipRelayedPackets  bool
+	forceBisection      bool
+	sameBlockAcks       bool
+	txsPerBlock         int
+	processedEvents     *ProcessedEvents
+	coordinator         Coordinator
+	notifier            *notify.Notifier
+	relayEvents         *eventstream.Stream
 }
 
 // NewEventProcessor creates a builder than can be used to construct a multi-ChainProcessor, multi-PathProcessor topology for the relayer.
@@ -69,19 +93,120 @@ func (ep EventProcessorBuilder) WithStuckPacket(stuckPacket *StuckPacket) EventP
 	return ep
 }
 
+// WithStartHeights sets the heights, by chain ID, from which the ChainProcessors of those chains start querying blocks,
+// overriding the initial block history. ChainProcessors which do not implement StartHeightSetter ignore them.
+func (ep EventProcessorBuilder) WithStartHeights(startHeights map[string]uint64) EventProcessorBuilder {
+	ep.startHeights = startHeights
+	return ep
+}
+
+// WithProofHeightAudit enables proof height auditing for all PathProcessors.
+func (ep EventProcessorBuilder) WithProofHeightAudit(enabled bool) EventProcessorBuilder {
+	ep.auditProofHeights = enabled
+	return ep
+}
+
+// WithMonitorOnly sets all PathProcessors to observe the paths without sending any messages.
+func (ep EventProcessorBuilder) WithMonitorOnly(enabled bool) EventProcessorBuilder {
+	ep.monitorOnly = enabled
+	return ep
+}
+
+// WithSkipRelayedPackets sets all PathProcessors to skip packet messages which have already been relayed
+// by another relayer, checked against the destination chain immediately before broadcast.
+func (ep EventProcessorBuilder) WithSkipRelayedPackets(enabled bool) EventProcessorBuilder {
+	ep.skipRelayedPackets = enabled
+	return ep
+}
+
+// WithForceBisection sets all PathProcessors to catch up clients which fell too far behind to be updated
+// to the latest height in one update through intermediate heights, rather than failing to update them.
+func (ep EventProcessorBuilder) WithForceBisection(enabled bool) EventProcessorBuilder {
+	ep.forceBisection = enabled
+	return ep
+}
+
+// WithSameBlockAcks sets all PathProcessors to relay the acknowledgements of received packets as soon as
+// they can be proven, by querying the block after the one they were written in as soon as it is produced.
+func (ep EventProcessorBuilder) WithSameBlockAcks(enabled bool) EventProcessorBuilder {
+	ep.sameBlockAcks = enabled
+	return ep
+}
+
+// WithTxsPerBlock sets how many transactions are broadcast to each chain per block, allocated among the
+// PathProcessors relaying on the chain by the weights and backlogs of their paths. Zero does not limit them.
+func (ep EventProcessorBuilder) WithTxsPerBlock(txsPerBlock int) EventProcessorBuilder {
+	ep.txsPerBlock = txsPerBlock
+	return ep
+}
+
+// WithProcessedEvents sets where all PathProcessors remember the packet messages they relayed,
+// so that they are not relayed again after a restart.
+func (ep EventProcessorBuilder) WithProcessedEvents(processedEvents *ProcessedEvents) EventProcessorBuilder {
+	ep.processedEvents = processedEvents
+	return ep
+}
+
+// WithCoordinator sets the coordinator with which all PathProcessors claim the packet messages they relay,
+// and skip those claimed by cooperating relayers.
+func (ep EventProcessorBuilder) WithCoordinator(coordinator Coordinator) EventProcessorBuilder {
+	ep.coordinator = coordinator
+	return ep
+}
+
+// WithNotifier sets the notifier used by all PathProcessors to notify the operator of conditions which need attention.
+func (ep EventProcessorBuilder) WithNotifier(notifier *notify.Notifier) EventProcessorBuilder {
+	ep.notifier = notifier
+	return ep
+}
+
+// WithRelayEvents sets the stream to which all PathProcessors publish the lifecycle of the packets they observe
+// and the messages they relay.
+func (ep EventProcessorBuilder) WithRelayEvents(relayEvents *eventstream.Stream) EventProcessorBuilder {
+	ep.relayEvents = relayEvents
+	return ep
+}
+
 // Build links the relevant ChainProcessors and PathProcessors, then returns an EventProcessor that can be used to run the ChainProcessors and PathProcessors.
 func (ep EventProcessorBuilder) Build() EventProcessor {
 	for _, chainProcessor := range ep.chainProcessors {
 		pathProcessorsForThisChain := PathProcessors{}
+		// a single header synchronizer per chain serves the headers of the chain to all of its PathProcessors.
+		var headers *HeaderSynchronizer
+		// likewise, a single tx scheduler per chain allocates the txs per block of the chain among its PathProcessors.
+		txScheduler := NewTxScheduler(ep.txsPerBlock)
 		for _, pathProcessor := range ep.pathProcessors {
 			if pathProcessor.SetChainProviderIfApplicable(chainProcessor.Provider()) {
+				if headers == nil {
+					headers = NewHeaderSynchronizer(chainProcessor.Provider())
+				}
+				pathProcessor.SetHeaderSynchronizerIfApplicable(chainProcessor.Provider().ChainId(), headers)
+				pathProcessor.SetTxSchedulerIfApplicable(chainProcessor.Provider().ChainId(), txScheduler)
+				if waker, ok := chainProcessor.(QueryWaker); ok {
+					pathProcessor.SetQueryWakerIfApplicable(chainProcessor.Provider().ChainId(), waker)
+				}
 				pathProcessorsForThisChain = append(pathProcessorsForThisChain, pathProcessor)
 			}
 		}
 		chainProcessor.SetPathProcessors(pathProcessorsForThisChain)
+
+		if height, ok := ep.startHeights[chainProcessor.Provider().ChainId()]; ok {
+			if setter, ok := chainProcessor.(StartHeightSetter); ok {
+				setter.SetStartHeight(height)
+			}
+		}
 	}
 	for _, pathProcessor := range ep.pathProcessors {
 		pathProcessor.SetMessageLifecycle(ep.messageLifecycle)
+		pathProcessor.SetProofHeightAudit(ep.auditProofHeights)
+		pathProcessor.SetMonitorOnly(ep.monitorOnly)
+		pathProcessor.SetSkipRelayedPackets(ep.skipRelayedPackets)
+		pathProcessor.SetForceBisection(ep.forceBisection)
+		pathProcessor.SetSameBlockAcks(ep.sameBlockAcks)
+		pathProcessor.SetProcessedEvents(ep.processedEvents)
+		pathProcessor.SetCoordinator(ep.coordinator)
+		pathProcessor.SetNotifier(ep.notifier)
+		pathProcessor.SetRelayEvents(ep.relayEvents)
 	}
 
 	return EventProcessor(ep)
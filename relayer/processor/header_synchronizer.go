@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// How many of the most recent IBC headers observed on a chain to retain for all PathProcessors.
+	synchronizedHeadersToCache = 100
+
+	// How many IBC headers queried on demand, e.g. client trusted headers, to retain for all PathProcessors.
+	queriedHeadersToCache = 100
+)
+
+// HeaderQuerier queries the IBC header of a chain at a height, e.g. a provider.ChainProvider.
+type HeaderQuerier interface {
+	QueryIBCHeader(ctx context.Context, h int64) (provider.IBCHeader, error)
+}
+
+// HeaderSynchronizer is a long-lived store of the IBC headers of a single chain, shared by all PathProcessors
+// relaying on the chain. It is kept up to date with the headers of the new blocks observed by the ChainProcessor,
+// and queries a header which it does not have at most once, no matter how many PathProcessors request it.
+type HeaderSynchronizer struct {
+	querier HeaderQuerier
+
+	mu      sync.Mutex
+	latest  IBCHeaderCache
+	queried IBCHeaderCache
+
+	inFlight singleflight.Group
+}
+
+// NewHeaderSynchronizer returns a HeaderSynchronizer which queries headers which it does not have with querier.
+func NewHeaderSynchronizer(querier HeaderQuerier) *HeaderSynchronizer {
+	return &HeaderSynchronizer{
+		querier: querier,
+		latest:  make(IBCHeaderCache),
+		queried: make(IBCHeaderCache),
+	}
+}
+
+// Update stores the headers of newly observed blocks.
+func (hs *HeaderSynchronizer) Update(headers IBCHeaderCache) {
+	if len(headers) == 0 {
+		return
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.latest.Merge(headers)
+	hs.latest.Prune(synchronizedHeadersToCache)
+}
+
+// Cached returns the header at height if it has been observed or queried, without querying for it.
+func (hs *HeaderSynchronizer) Cached(height uint64) (provider.IBCHeader, bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if header, ok := hs.latest[height]; ok {
+		return header, true
+	}
+	header, ok := hs.queried[height]
+	return header, ok
+}
+
+// Header returns the header at height, querying for it if it has not been observed or queried before.
+// Concurrent requests for the same header share a single query.
+func (hs *HeaderSynchronizer) Header(ctx context.Context, height uint64) (provider.IBCHeader, error) {
+	if header, ok := hs.Cached(height); ok {
+		return header, nil
+	}
+
+	header, err, _ := hs.inFlight.Do(strconv.FormatUint(height, 10), func() (any, error) {
+		// the header may have been stored by a query which completed since it was looked up.
+		if header, ok := hs.Cached(height); ok {
+			return header, nil
+		}
+
+		header, err := hs.querier.QueryIBCHeader(ctx, int64(height))
+		if err != nil {
+			return nil, err
+		}
+
+		hs.mu.Lock()
+		hs.queried[height] = header
+		hs.queried.Prune(queriedHeadersToCache)
+		hs.mu.Unlock()
+
+		return header, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header.(provider.IBCHeader), nil
+}
@@ -0,0 +1,54 @@
+package processor_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHeaderQuerier struct {
+	queries atomic.Int64
+}
+
+func (q *mockHeaderQuerier) QueryIBCHeader(_ context.Context, _ int64) (provider.IBCHeader, error) {
+	q.queries.Add(1)
+	return mockIBCHeader{}, nil
+}
+
+func TestHeaderSynchronizer(t *testing.T) {
+	ctx := context.Background()
+	querier := new(mockHeaderQuerier)
+	hs := processor.NewHeaderSynchronizer(querier)
+
+	// observed headers are served without querying.
+	hs.Update(processor.IBCHeaderCache{10: mockIBCHeader{}, 11: mockIBCHeader{}})
+	_, err := hs.Header(ctx, 11)
+	require.NoError(t, err)
+	require.Zero(t, querier.queries.Load())
+
+	_, ok := hs.Cached(5)
+	require.False(t, ok)
+
+	// a header which was not observed is queried once for all requests.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := hs.Header(ctx, 5)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	_, err = hs.Header(ctx, 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), querier.queries.Load())
+
+	_, ok = hs.Cached(5)
+	require.True(t, ok)
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+)
+
+// MemoFilter refuses to relay the ICS-20 transfers whose memo matches any of its patterns, e.g. to avoid
+// paying the fees of waves of inscription spam. Packets which are not ICS-20 transfers are not filtered.
+type MemoFilter struct {
+	DenyPatterns []*regexp.Regexp
+}
+
+// check returns an error if the packet is an ICS-20 transfer whose memo matches a pattern of the filter.
+func (f MemoFilter) check(packetData []byte) error {
+	if len(f.DenyPatterns) == 0 {
+		// no filter
+		return nil
+	}
+
+	var packet transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(packetData, &packet); err != nil || packet.Memo == "" {
+		// not an ICS-20 packet, or no memo
+		return nil
+	}
+
+	for _, pattern := range f.DenyPatterns {
+		if pattern.MatchString(packet.Memo) {
+			return fmt.Errorf("packet memo matches denied pattern: %s", pattern)
+		}
+	}
+
+	return nil
+}
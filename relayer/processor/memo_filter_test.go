@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"regexp"
+	"testing"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoFilter(t *testing.T) {
+	transfer := func(memo string) []byte {
+		return transfertypes.NewFungibleTokenPacketData("uatom", "100", "sender", "receiver", memo).GetBytes()
+	}
+	filter := MemoFilter{DenyPatterns: []*regexp.Regexp{
+		regexp.MustCompile(`"p"\s*:\s*"ibc-20"`),
+		regexp.MustCompile(`^data:`),
+	}}
+
+	for _, tc := range []struct {
+		name   string
+		filter MemoFilter
+		data   []byte
+		err    string
+	}{
+		{
+			name: "no filter",
+			data: transfer(`{"p":"ibc-20","op":"mint"}`),
+		},
+		{
+			name:   "inscription",
+			filter: filter,
+			data:   transfer(`{"p": "ibc-20","op":"mint","tick":"spam","amt":"1000"}`),
+			err:    "matches denied pattern",
+		},
+		{
+			name:   "data uri",
+			filter: filter,
+			data:   transfer(`data:,{"op":"mint"}`),
+			err:    "matches denied pattern",
+		},
+		{
+			name:   "forward memo",
+			filter: filter,
+			data:   transfer(`{"forward":{"receiver":"receiver","port":"transfer","channel":"channel-1"}}`),
+		},
+		{
+			name:   "no memo",
+			filter: filter,
+			data:   transfer(""),
+		},
+		{
+			name:   "not ics-20",
+			filter: filter,
+			data:   []byte(`data:,{"op":"mint"}`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.filter.check(tc.data)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
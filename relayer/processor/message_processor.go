@@ -5,9 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/cometbft/cometbft/light"
 	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
@@ -21,19 +25,43 @@ type messageProcessor struct {
 	log     *zap.Logger
 	metrics *PrometheusMetrics
 
+	txResults *txResultHistory
+
 	memo string
 
-	msgUpdateClient           provider.RelayerMessage
+	// msgsUpdateClient update the client on the destination in order, with more than one
+	// if the client is caught up through intermediate heights.
+	msgsUpdateClient          []provider.RelayerMessage
 	clientUpdateThresholdTime time.Duration
 
+	// proofHeight is the height of the source at which the proofs of the messages are queried. With a client update,
+	// it is the height of the consensus state which the update installs on the destination, so that the proofs are
+	// verified against it rather than against a newer header observed while the messages are assembled.
+	proofHeight uint64
+
 	pktMsgs       []packetMessageToTrack
 	connMsgs      []connectionMessageToTrack
 	chanMsgs      []channelMessageToTrack
 	clientICQMsgs []clientICQMessageToTrack
 
 	isLocalhost bool
+
+	monitorOnly bool
+
+	// limits how many messages are assembled at once.
+	workers semaphore
+
+	// if true, packet state on the destination is checked immediately before broadcast.
+	skipRelayedPackets bool
+	skippedCount       int
+
+	// if true, clients which cannot be updated in one update are caught up through intermediate heights.
+	forceBisection bool
 }
 
+// packetProofConcurrency bounds how many proofs of the packet messages of a batch are queried from the source at once.
+const packetProofConcurrency = 16
+
 // categories of tx errors for a Prometheus counter. If the error doesn't fall into one of the below categories, it is labeled as "Tx Failure"
 var promErrorCatagories = []error{
 	chantypes.ErrRedundantTx,
@@ -41,6 +69,7 @@ var promErrorCatagories = []error{
 	legacyerrors.ErrInvalidCoins,
 	legacyerrors.ErrOutOfGas,
 	legacyerrors.ErrWrongSequence,
+	provider.ErrFeeCapExceeded,
 }
 
 // trackMessage stores the message tracker in the correct slice and index based on the type.
@@ -97,8 +126,15 @@ func (mp *messageProcessor) processMessages(
 	messages pathEndMessages,
 	src, dst *pathEndRuntime,
 ) error {
+	if mp.monitorOnly {
+		return mp.observeMessages(ctx, messages, src, dst)
+	}
+
 	var needsClientUpdate bool
 
+	// the latest block of the source may be merged while the messages are sent, so it is only read once.
+	mp.proofHeight = src.latestBlock.Height
+
 	// Localhost IBC does not permit client updates
 	if !isLocalhostClient(src.clientState.ClientID, dst.clientState.ClientID) {
 		var err error
@@ -107,6 +143,11 @@ func (mp *messageProcessor) processMessages(
 			return err
 		}
 
+		if dst.forceClientUpdate {
+			dst.forceClientUpdate = false
+			needsClientUpdate = true
+		}
+
 		if err := mp.assembleMsgUpdateClient(ctx, src, dst); err != nil {
 			return err
 		}
@@ -114,9 +155,44 @@ func (mp *messageProcessor) processMessages(
 
 	mp.assembleMessages(ctx, messages, src, dst)
 
+	if mp.skipRelayedPackets {
+		mp.skipRelayedPacketMessages(ctx, dst)
+	}
+
 	return mp.trackAndSendMessages(ctx, src, dst, needsClientUpdate)
 }
 
+// observeMessages is the monitor-only counterpart of processMessages.
+// It tracks the client expiration and logs the pending messages without assembling or sending anything.
+func (mp *messageProcessor) observeMessages(
+	ctx context.Context,
+	messages pathEndMessages,
+	src, dst *pathEndRuntime,
+) error {
+	if !isLocalhostClient(src.clientState.ClientID, dst.clientState.ClientID) {
+		if _, err := mp.shouldUpdateClientNow(ctx, src, dst); err != nil {
+			return err
+		}
+	}
+
+	if len(messages.connectionMessages) == 0 && len(messages.channelMessages) == 0 &&
+		len(messages.packetMessages) == 0 && len(messages.clientICQMessages) == 0 {
+		return nil
+	}
+
+	mp.log.Debug("Monitor only, not relaying pending messages",
+		zap.String("path_name", src.info.PathName),
+		zap.String("src_chain_id", src.info.ChainID),
+		zap.String("dst_chain_id", dst.info.ChainID),
+		zap.Int("connection_msgs", len(messages.connectionMessages)),
+		zap.Int("channel_msgs", len(messages.channelMessages)),
+		zap.Int("packet_msgs", len(messages.packetMessages)),
+		zap.Int("client_icq_msgs", len(messages.clientICQMessages)),
+	)
+
+	return nil
+}
+
 func isLocalhostClient(srcClientID, dstClientID string) bool {
 	if srcClientID == ibcexported.LocalhostClientID && dstClientID == ibcexported.LocalhostConnectionID {
 		return true
@@ -134,7 +210,7 @@ func (mp *messageProcessor) shouldUpdateClientNow(ctx context.Context, src, dst
 	var consensusHeightTime time.Time
 
 	if dst.clientState.ConsensusTime.IsZero() {
-		h, err := src.chainProvider.QueryIBCHeader(ctx, int64(dst.clientState.ConsensusHeight.RevisionHeight))
+		h, err := src.header(ctx, dst.clientState.ConsensusHeight.RevisionHeight)
 		if err != nil {
 			return false, fmt.Errorf("failed to get header height: %w", err)
 		}
@@ -165,6 +241,10 @@ func (mp *messageProcessor) shouldUpdateClientNow(ctx context.Context, src, dst
 		mp.metrics.SetClientExpiration(src.info.PathName, dst.info.ChainID, dst.clientState.ClientID, fmt.Sprint(dst.clientState.TrustingPeriod.String()), timeToExpiration)
 		mp.metrics.SetClientTrustingPeriod(src.info.PathName, dst.info.ChainID, dst.info.ClientID, time.Duration(dst.clientState.TrustingPeriod))
 	}
+	if dst.clientState.TrustingPeriod > 0 {
+		dst.notifier.ClientExpiration(src.info.PathName, dst.info.ChainID, dst.clientState.ClientID,
+			dst.clientState.TrustingPeriod-time.Since(consensusHeightTime))
+	}
 
 	if shouldUpdateClientNow {
 		mp.log.Info("Client update threshold condition met",
@@ -207,14 +287,51 @@ func (mp *messageProcessor) assembleMessages(ctx context.Context, messages pathE
 	}
 
 	mp.pktMsgs = make([]packetMessageToTrack, len(messages.packetMessages))
-	for i, msg := range messages.packetMessages {
-		wg.Add(1)
-		go mp.assembleMessage(ctx, msg, src, dst, i, &wg)
-	}
+	mp.assemblePacketMessages(ctx, messages.packetMessages, src, dst, &wg)
 
 	wg.Wait()
 }
 
+// assemblePacketMessages assembles the packet messages of a batch, whose proofs are all queried at the proof height,
+// with at most packetProofConcurrency proof queries in flight so that a large flush does not query the source for
+// every packet at once. The header at the proof height, which the proofs are verified against, is looked up once
+// ahead of the proof queries so that they share it.
+func (mp *messageProcessor) assemblePacketMessages(
+	ctx context.Context,
+	msgs []packetIBCMessage,
+	src, dst *pathEndRuntime,
+	wg *sync.WaitGroup,
+) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	if src.verifyProofs && src.clientState.ClientID != ibcexported.LocalhostClientID {
+		if _, err := src.header(ctx, mp.proofHeight); err != nil {
+			// each proof looks up the header again when it is verified.
+			dst.log.Debug("Failed to get header at proof height ahead of packet proof queries",
+				zap.Uint64("height", mp.proofHeight),
+				zap.Error(err),
+			)
+		}
+	}
+
+	proofs := newSemaphore(packetProofConcurrency)
+	for i, msg := range msgs {
+		if err := proofs.acquire(ctx); err != nil {
+			for j := i; j < len(msgs); j++ {
+				mp.trackMessage(msgs[j].tracker(nil), j)
+			}
+			return
+		}
+		wg.Add(1)
+		go func(i int, msg packetIBCMessage) {
+			defer proofs.release()
+			mp.assembleMessage(ctx, msg, src, dst, i, wg)
+		}(i, msg)
+	}
+}
+
 // assembledCount will return the number of assembled messages.
 // This must be called after assembleMessages has completed.
 func (mp *messageProcessor) assembledCount() int {
@@ -236,7 +353,13 @@ func (mp *messageProcessor) assembleMessage(
 	i int,
 	wg *sync.WaitGroup,
 ) {
-	assembled, err := msg.assemble(ctx, src, dst)
+	if err := mp.workers.acquire(ctx); err != nil {
+		mp.trackMessage(msg.tracker(nil), i)
+		wg.Done()
+		return
+	}
+	assembled, err := mp.assemble(ctx, msg, src, dst)
+	mp.workers.release()
 	mp.trackMessage(msg.tracker(assembled), i)
 	wg.Done()
 	if err != nil {
@@ -247,6 +370,78 @@ func (mp *messageProcessor) assembleMessage(
 		return
 	}
 	dst.log.Debug(fmt.Sprintf("Assembled %s message", msg.msgType()), zap.Object("msg", msg))
+	dst.publishMsgBuilt(msg.tracker(assembled))
+}
+
+// relayedPacketQuery identifies the packet state on the destination of a packet message.
+type relayedPacketQuery struct {
+	eventType string
+	channelID string
+	portID    string
+}
+
+func newRelayedPacketQuery(msg packetIBCMessage) relayedPacketQuery {
+	if msg.eventType == chantypes.EventTypeRecvPacket {
+		return relayedPacketQuery{eventType: msg.eventType, channelID: msg.info.DestChannel, portID: msg.info.DestPort}
+	}
+	// acknowledgements and timeouts are sent back to the packet sender
+	return relayedPacketQuery{eventType: msg.eventType, channelID: msg.info.SourceChannel, portID: msg.info.SourcePort}
+}
+
+// skipRelayedPacketMessages drops assembled packet messages which have already been relayed to dst,
+// e.g. by a redundant relayer instance serving the same path, so that they are not broadcast again.
+// If the packet state cannot be queried, the messages are sent anyways.
+func (mp *messageProcessor) skipRelayedPacketMessages(ctx context.Context, dst *pathEndRuntime) {
+	seqs := make(map[relayedPacketQuery][]uint64)
+	for _, t := range mp.pktMsgs {
+		if t.assembled == nil {
+			continue
+		}
+		q := newRelayedPacketQuery(t.msg)
+		seqs[q] = append(seqs[q], t.msg.info.Sequence)
+	}
+	if len(seqs) == 0 {
+		return
+	}
+
+	unrelayed := make(map[relayedPacketQuery]map[uint64]bool, len(seqs))
+	for q, s := range seqs {
+		var res []uint64
+		var err error
+		if q.eventType == chantypes.EventTypeRecvPacket {
+			res, err = dst.chainProvider.QueryUnreceivedPackets(ctx, dst.latestBlock.Height, q.channelID, q.portID, s)
+		} else {
+			// the packet commitment is deleted once the packet has been acknowledged or timed out.
+			res, err = dst.chainProvider.QueryUnreceivedAcknowledgements(ctx, dst.latestBlock.Height, q.channelID, q.portID, s)
+		}
+		if err != nil {
+			dst.log.Warn("Failed to query packet state before broadcast, will not skip relayed packets",
+				zap.String("event_type", q.eventType),
+				zap.String("channel_id", q.channelID),
+				zap.String("port_id", q.portID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		unrelayed[q] = make(map[uint64]bool, len(res))
+		for _, seq := range res {
+			unrelayed[q][seq] = true
+		}
+	}
+
+	pktMsgs := make([]packetMessageToTrack, 0, len(mp.pktMsgs))
+	for _, t := range mp.pktMsgs {
+		if t.assembled != nil {
+			if u, ok := unrelayed[newRelayedPacketQuery(t.msg)]; ok && !u[t.msg.info.Sequence] {
+				dst.log.Info("Skipping packet message already relayed by another relayer", zap.Object("msg", t.msg))
+				mp.skippedCount++
+				continue
+			}
+		}
+		pktMsgs = append(pktMsgs, t)
+	}
+	mp.pktMsgs = pktMsgs
 }
 
 // assembleMsgUpdateClient uses the ChainProvider from both pathEnds to assemble the client update header
@@ -271,7 +466,7 @@ func (mp *messageProcessor) assembleMsgUpdateClient(ctx context.Context, src, ds
 				trustedConsensusHeight.RevisionHeight, clientConsensusHeight.RevisionHeight)
 		}
 
-		header, err := src.chainProvider.QueryIBCHeader(ctx, int64(clientConsensusHeight.RevisionHeight+1))
+		header, err := src.header(ctx, clientConsensusHeight.RevisionHeight+1)
 		if err != nil {
 			return fmt.Errorf("error getting IBC header at height: %d for chain_id: %s, %w",
 				clientConsensusHeight.RevisionHeight+1, src.info.ChainID, err)
@@ -295,28 +490,72 @@ func (mp *messageProcessor) assembleMsgUpdateClient(ctx context.Context, src, ds
 		trustedNextValidatorsHash = header.NextValidatorsHash()
 	}
 
-	if src.latestHeader.Height() == trustedConsensusHeight.RevisionHeight &&
-		!bytes.Equal(src.latestHeader.NextValidatorsHash(), trustedNextValidatorsHash) {
+	// the header is only read once, so that the proofs are queried at the height of the header the client is updated to.
+	latestHeader := src.latestHeader
+	if latestHeader == nil {
+		return fmt.Errorf("no header observed for chain_id: %s, cannot assemble MsgUpdateClient", src.info.ChainID)
+	}
+
+	if latestHeader.Height() == trustedConsensusHeight.RevisionHeight &&
+		!bytes.Equal(latestHeader.NextValidatorsHash(), trustedNextValidatorsHash) {
 		return fmt.Errorf("latest header height is equal to the client trusted height: %d, "+
 			"need to wait for next block's header before we can assemble and send a new MsgUpdateClient",
 			trustedConsensusHeight.RevisionHeight)
 	}
 
-	msgUpdateClientHeader, err := src.chainProvider.MsgUpdateClientHeader(
-		src.latestHeader,
-		trustedConsensusHeight,
-		dst.clientTrustedState.IBCHeader,
-	)
-	if err != nil {
-		return fmt.Errorf("error assembling new client header: %w", err)
+	trustLevel := dst.clientState.TrustLevel
+	if trustLevel.Denominator == 0 {
+		trustLevel = light.DefaultTrustLevel
+	}
+	steps := []provider.ClientUpdateStep{{
+		TrustedHeight: trustedConsensusHeight,
+		TrustedHeader: dst.clientTrustedState.IBCHeader,
+		Header:        latestHeader,
+	}}
+	if err := provider.VerifyTrustLevel(src.info.ChainID, dst.clientTrustedState.IBCHeader, latestHeader, trustLevel); err != nil {
+		if !mp.forceBisection {
+			return fmt.Errorf("cannot update client %s to height %d: %w; catch it up through intermediate heights "+
+				"with rly start --force-bisection or rly tx update-clients --force-bisection",
+				clientID, latestHeader.Height(), err)
+		}
+		mp.log.Info("Client cannot be updated in one update, catching it up through intermediate heights",
+			zap.String("path_name", src.info.PathName),
+			zap.String("chain_id", src.info.ChainID),
+			zap.String("counterparty_chain_id", dst.info.ChainID),
+			zap.String("counterparty_client_id", clientID),
+			zap.Uint64("trusted_height", trustedConsensusHeight.RevisionHeight),
+			zap.Uint64("latest_height", latestHeader.Height()),
+			zap.String("reason", err.Error()),
+		)
+		steps, err = provider.BisectClientUpdate(src.info.ChainID, trustLevel, steps[0], func(height uint64) (provider.IBCHeader, error) {
+			return src.header(ctx, height)
+		})
+		if err != nil {
+			return fmt.Errorf("error bisecting client update: %w", err)
+		}
 	}
 
-	msgUpdateClient, err := dst.chainProvider.MsgUpdateClient(clientID, msgUpdateClientHeader)
-	if err != nil {
-		return fmt.Errorf("error assembling MsgUpdateClient: %w", err)
+	msgs := make([]provider.RelayerMessage, 0, len(steps))
+	for _, step := range steps {
+		msgUpdateClientHeader, err := dst.info.clientMessage(
+			src.chainProvider,
+			step.Header,
+			step.TrustedHeight,
+			step.TrustedHeader,
+		)
+		if err != nil {
+			return fmt.Errorf("error assembling new client header: %w", err)
+		}
+
+		msgUpdateClient, err := dst.chainProvider.MsgUpdateClient(clientID, msgUpdateClientHeader)
+		if err != nil {
+			return fmt.Errorf("error assembling MsgUpdateClient: %w", err)
+		}
+		msgs = append(msgs, msgUpdateClient)
 	}
 
-	mp.msgUpdateClient = msgUpdateClient
+	mp.msgsUpdateClient = msgs
+	mp.proofHeight = latestHeader.Height()
 
 	return nil
 }
@@ -332,60 +571,258 @@ func (mp *messageProcessor) trackAndSendMessages(
 	broadcastBatch := dst.chainProvider.ProviderConfig().BroadcastMode() == provider.BroadcastModeBatch
 	var batch []messageToTrack
 
+	// the messages are sent once the client update is broadcast, so that it precedes them in the account sequence.
+	// It is sent from its own goroutine, since waiting for a tx slot or the tx budget of the block of dst must not
+	// block the PathProcessor from observing the blocks which free them.
+	clientUpdateSent := make(chan struct{})
+	if !mp.isLocalhost && !mp.bundleClientUpdate(dst) && mp.assembledCount() > 0 {
+		go func() {
+			defer close(clientUpdateSent)
+			mp.sendClientUpdate(ctx, src, dst)
+		}()
+	} else {
+		close(clientUpdateSent)
+	}
+
+	// the packets are claimed before any of them is broadcast, so that the claims reach the cooperating relayers
+	// as early as possible.
+	dst.claimPackets(mp.trackers())
+
 	for _, t := range mp.trackers() {
 
 		retries := dst.trackProcessingMessage(t)
 		if t.assembledMsg() == nil {
-			dst.trackFinishedProcessingMessage(t)
+			dst.trackFinishedProcessingMessage(t, provider.TxFailureNone)
 			continue
 		}
 
-		ordered := false
-		if m, ok := t.(packetMessageToTrack); ok && m.msg.info.ChannelOrder == chantypes.ORDERED.String() {
-			ordered = true
-		}
-
-		if broadcastBatch && (retries == 0 || ordered) {
+		if broadcastBatch && (retries == 0 || isOrderedPacketTracker(t)) {
 			batch = append(batch, t)
 			continue
 		}
-		go mp.sendSingleMessage(ctx, src, dst, t)
+		go func(t messageToTrack) {
+			<-clientUpdateSent
+			mp.sendSingleMessage(ctx, src, dst, t)
+		}(t)
 	}
 
 	if len(batch) > 0 {
-		go mp.sendBatchMessages(ctx, src, dst, batch)
+		batches := splitBatch(batch, dst.chainProvider.ProviderConfig().MaxBatchMsgs())
+		go func() {
+			<-clientUpdateSent
+			// batches are sent in order, since ordered channel packets may be split across batches.
+			for _, b := range batches {
+				mp.sendBatchMessages(ctx, src, dst, b, false)
+			}
+		}()
 	}
 
 	if mp.assembledCount() > 0 {
 		return nil
 	}
 
-	if needsClientUpdate && mp.msgUpdateClient != nil {
+	if needsClientUpdate && len(mp.msgsUpdateClient) > 0 {
 		go mp.sendClientUpdate(ctx, src, dst)
 		return nil
 	}
 
+	if mp.skippedCount > 0 {
+		// all messages have already been relayed by another relayer
+		return nil
+	}
+
 	// only msgUpdateClient, don't need to send
 	return errors.New("all messages failed to assemble")
 }
 
+// bundleClientUpdate returns true if MsgUpdateClient should be sent in the same tx as the messages for dst.
+func (mp *messageProcessor) bundleClientUpdate(dst *pathEndRuntime) bool {
+	return !mp.isLocalhost && dst.chainProvider.ProviderConfig().ClientUpdateMode() != provider.ClientUpdateModeSeparate
+}
+
+// withClientUpdate prepends the MsgUpdateClients to msgs if they are bundled for dst.
+func (mp *messageProcessor) withClientUpdate(dst *pathEndRuntime, msgs ...provider.RelayerMessage) []provider.RelayerMessage {
+	if !mp.bundleClientUpdate(dst) {
+		return msgs
+	}
+	return append(slices.Clone(mp.msgsUpdateClient), msgs...)
+}
+
+// splitBatch splits batch into consecutive batches of at most maxMsgs messages. A maxMsgs of 0 does not split the batch.
+func splitBatch(batch []messageToTrack, maxMsgs uint64) [][]messageToTrack {
+	if maxMsgs == 0 || uint64(len(batch)) <= maxMsgs {
+		return [][]messageToTrack{batch}
+	}
+	var batches [][]messageToTrack
+	for len(batch) > 0 {
+		n := int(maxMsgs)
+		if n > len(batch) {
+			n = len(batch)
+		}
+		batches = append(batches, batch[:n])
+		batch = batch[n:]
+	}
+	return batches
+}
+
+// failedMsgIndexRegex matches the index of the message which caused a tx to fail in the tx log.
+var failedMsgIndexRegex = regexp.MustCompile(`message index: (\d+)`)
+
+// failedMessageIndex returns the index of the message within the tx which caused it to fail,
+// if it is reported by the chain in err or the tx log.
+func failedMessageIndex(rtr *provider.RelayerTxResponse, err error) (int, bool) {
+	logs := []string{err.Error()}
+	if rtr != nil {
+		logs = append(logs, rtr.Log)
+	}
+	for _, log := range logs {
+		m := failedMsgIndexRegex.FindStringSubmatch(log)
+		if m == nil {
+			continue
+		}
+		i, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// isolateFailedMessage assigns blame for a failed batch to the message reported by the chain as the cause.
+// The blamed message is released to be retried on its own, while the remaining messages are broadcast
+// again immediately as a new batch, so that a single failing message does not hold back the entire batch.
+// It returns false if blame could not be assigned to a single message.
+func (mp *messageProcessor) isolateFailedMessage(
+	ctx context.Context,
+	src, dst *pathEndRuntime,
+	batch []messageToTrack,
+	rtr *provider.RelayerTxResponse,
+	err error,
+) bool {
+	if len(batch) < 2 || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if provider.ClassifyTxFailure(err) == provider.TxFailureConsensusStateNotFound {
+		// the proof height of the batch is missing on the client, which affects all messages in the batch.
+		return false
+	}
+	i, ok := failedMessageIndex(rtr, err)
+	if !ok {
+		return false
+	}
+	if mp.bundleClientUpdate(dst) {
+		if i < len(mp.msgsUpdateClient) {
+			// the client update failed, which affects all messages in the batch.
+			return false
+		}
+		i -= len(mp.msgsUpdateClient)
+	}
+	if i >= len(batch) {
+		return false
+	}
+
+	blamed := batch[i]
+	if isOrderedPacketTracker(blamed) {
+		// later packets on an ordered channel cannot succeed without the blamed packet.
+		return false
+	}
+
+	dst.log.Warn("Isolating message which caused batch to fail, will retry it separately",
+		zap.String("path_name", src.info.PathName),
+		zap.Object("msg", blamed),
+		zap.Int("batch_size", len(batch)),
+		zap.Error(err),
+	)
+	dst.finishProcessing(blamed, err)
+
+	remaining := make([]messageToTrack, 0, len(batch)-1)
+	remaining = append(remaining, batch[:i]...)
+	remaining = append(remaining, batch[i+1:]...)
+	go mp.sendBatchMessages(ctx, src, dst, remaining, false)
+
+	return true
+}
+
+// bisectFailedBatch isolates the message which caused a batch to fail when the chain does not report it,
+// by broadcasting each half of the batch again as its own batch, so that the other messages are relayed
+// while the failing message is narrowed down by binary search. A single packet message of a bisected batch
+// which still fails is quarantined. It returns false if the failure is not attributable to a message of the batch,
+// in which case the batch is retried as usual. Only a tx which the chain executed and failed with a code is
+// bisected, a tx which did not reach the chain, e.g. because the node refused the connection or its mempool
+// is full, is retried whole.
+func (mp *messageProcessor) bisectFailedBatch(
+	ctx context.Context,
+	src, dst *pathEndRuntime,
+	batch []messageToTrack,
+	rtr *provider.RelayerTxResponse,
+	err error,
+	bisected bool,
+) bool {
+	if rtr == nil || rtr.Code == 0 {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if failure := provider.ClassifyTxFailure(err); failure != provider.TxFailureUnknown && failure != provider.TxFailureContract {
+		// the failure is not caused by the messages themselves.
+		return false
+	}
+	if i, ok := failedMessageIndex(rtr, err); ok && (len(batch) > 1 || (mp.bundleClientUpdate(dst) && i < len(mp.msgsUpdateClient))) {
+		// blame is assigned by the chain, or the client update failed, which affects all messages.
+		return false
+	}
+	if slices.ContainsFunc(batch, isOrderedPacketTracker) {
+		// later packets on an ordered channel cannot succeed without the earlier ones.
+		return false
+	}
+
+	if len(batch) == 1 {
+		t, ok := batch[0].(packetMessageToTrack)
+		if !ok || !bisected {
+			return false
+		}
+		dst.quarantinePacket(t, err)
+		dst.finishProcessing(t, err)
+		return true
+	}
+
+	half := len(batch) / 2
+	dst.log.Warn("Bisecting batch which failed without blaming a message",
+		zap.String("path_name", src.info.PathName),
+		zap.Int("batch_size", len(batch)),
+		zap.Error(err),
+	)
+	go func() {
+		mp.sendBatchMessages(ctx, src, dst, batch[:half], true)
+		mp.sendBatchMessages(ctx, src, dst, batch[half:], true)
+	}()
+	return true
+}
+
+// isOrderedPacketTracker returns true if the tracker is of a packet message on an ordered channel.
+func isOrderedPacketTracker(tracker messageToTrack) bool {
+	t, ok := tracker.(packetMessageToTrack)
+	return ok && t.msg.info.ChannelOrder == chantypes.ORDERED.String()
+}
+
 // sendClientUpdate will send an isolated client update message.
 func (mp *messageProcessor) sendClientUpdate(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
 ) {
-	broadcastCtx, cancel := context.WithTimeout(ctx, messageSendTimeout)
-	defer cancel()
-
 	dst.log.Debug("Will relay client update")
 
 	dst.lastClientUpdateHeightMu.Lock()
 	dst.lastClientUpdateHeight = dst.latestBlock.Height
 	dst.lastClientUpdateHeightMu.Unlock()
 
-	msgs := []provider.RelayerMessage{mp.msgUpdateClient}
+	msgs := mp.msgsUpdateClient
+
+	callbacks := []func(rtr *provider.RelayerTxResponse, err error){mp.txResultCallback(dst, msgs)}
 
-	if err := dst.chainProvider.SendMessagesToMempool(broadcastCtx, msgs, mp.memo, ctx, nil); err != nil {
+	if err := mp.sendMessages(ctx, dst, msgs, nil, callbacks); err != nil {
 		mp.log.Error("Error sending client update message",
 			zap.String("path_name", src.info.PathName),
 			zap.String("src_chain_id", src.info.ChainID),
@@ -412,41 +849,37 @@ var PathProcMessageCollector chan *PathProcessorMessageResp
 
 // sendBatchMessages will send a batch of messages,
 // then increment metrics counters for successful packet messages.
+// bisected is true if the batch is half of a failed batch, see bisectFailedBatch.
 func (mp *messageProcessor) sendBatchMessages(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
 	batch []messageToTrack,
+	bisected bool,
 ) {
-	broadcastCtx, cancel := context.WithTimeout(ctx, messageSendTimeout)
-	defer cancel()
-
 	var (
 		msgs   []provider.RelayerMessage
 		fields []zapcore.Field
 	)
 
-	if mp.isLocalhost {
-		msgs = make([]provider.RelayerMessage, len(batch))
-		for i, t := range batch {
-			msgs[i] = t.assembledMsg()
-			fields = append(fields, zap.Object(fmt.Sprintf("msg_%d", i), t))
-		}
-	} else {
-		// messages are batch with appended MsgUpdateClient
-		msgs = make([]provider.RelayerMessage, 1+len(batch))
-		msgs[0] = mp.msgUpdateClient
-
-		for i, t := range batch {
-			msgs[i+1] = t.assembledMsg()
-			fields = append(fields, zap.Object(fmt.Sprintf("msg_%d", i), t))
-		}
+	for i, t := range batch {
+		msgs = append(msgs, t.assembledMsg())
+		fields = append(fields, zap.Object(fmt.Sprintf("msg_%d", i), t))
 	}
+	// messages are batch with prepended MsgUpdateClient, unless it is sent separately
+	msgs = mp.withClientUpdate(dst, msgs...)
 
 	dst.log.Debug("Will relay messages", fields...)
 
-	callback := func(_ *provider.RelayerTxResponse, err error) {
+	callback := func(rtr *provider.RelayerTxResponse, err error) {
+		if err != nil && (mp.isolateFailedMessage(ctx, src, dst, batch, rtr, err) ||
+			mp.bisectFailedBatch(ctx, src, dst, batch, rtr, err, bisected)) {
+			return
+		}
 		for _, t := range batch {
-			dst.finishedProcessing <- t
+			dst.finishProcessing(t, err)
+		}
+		if err == nil && rtr != nil && slices.ContainsFunc(batch, isRecvPacketTracker) {
+			dst.awaitAckProofs(uint64(rtr.Height))
 		}
 		// only increment metrics counts for successful packets
 		if err != nil || mp.metrics == nil {
@@ -468,7 +901,7 @@ func (mp *messageProcessor) sendBatchMessages(
 			mp.metrics.IncPacketsRelayed(dst.info.PathName, dst.info.ChainID, channel, port, t.msg.eventType)
 		}
 	}
-	callbacks := []func(rtr *provider.RelayerTxResponse, err error){callback}
+	callbacks := []func(rtr *provider.RelayerTxResponse, err error){callback, mp.txResultCallback(dst, msgs)}
 
 	//During testing, this adds a callback so our test case can inspect the TX results
 	if PathProcMessageCollector != nil {
@@ -484,9 +917,11 @@ func (mp *messageProcessor) sendBatchMessages(
 		callbacks = append(callbacks, testCallback)
 	}
 
-	if err := dst.chainProvider.SendMessagesToMempool(broadcastCtx, msgs, mp.memo, ctx, callbacks); err != nil {
-		for _, t := range batch {
-			dst.finishedProcessing <- t
+	if err := mp.sendMessages(ctx, dst, msgs, batch, callbacks); err != nil {
+		if !mp.isolateFailedMessage(ctx, src, dst, batch, nil, err) {
+			for _, t := range batch {
+				dst.finishProcessing(t, err)
+			}
 		}
 		errFields := []zapcore.Field{
 			zap.String("path_name", src.info.PathName),
@@ -509,22 +944,20 @@ func (mp *messageProcessor) sendBatchMessages(
 	dst.log.Debug("Message broadcast completed", fields...)
 }
 
+// isRecvPacketTracker returns true if the tracker is of a MsgRecvPacket, whose acknowledgement is written
+// in the block which includes it, unless the acknowledgement is asynchronous.
+func isRecvPacketTracker(tracker messageToTrack) bool {
+	t, ok := tracker.(packetMessageToTrack)
+	return ok && t.msg.eventType == chantypes.EventTypeRecvPacket
+}
+
 // sendSingleMessage will send an isolated message.
 func (mp *messageProcessor) sendSingleMessage(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
 	tracker messageToTrack,
 ) {
-	var msgs []provider.RelayerMessage
-
-	if mp.isLocalhost {
-		msgs = []provider.RelayerMessage{tracker.assembledMsg()}
-	} else {
-		msgs = []provider.RelayerMessage{mp.msgUpdateClient, tracker.assembledMsg()}
-	}
-
-	broadcastCtx, cancel := context.WithTimeout(ctx, messageSendTimeout)
-	defer cancel()
+	msgs := mp.withClientUpdate(dst, tracker.assembledMsg())
 
 	msgType := tracker.msgType()
 
@@ -533,8 +966,11 @@ func (mp *messageProcessor) sendSingleMessage(
 	// Set callback for packet messages so that we increment prometheus metrics on successful relays.
 	callbacks := []func(rtr *provider.RelayerTxResponse, err error){}
 
-	callback := func(_ *provider.RelayerTxResponse, err error) {
-		dst.finishedProcessing <- tracker
+	callback := func(rtr *provider.RelayerTxResponse, err error) {
+		dst.finishProcessing(tracker, err)
+		if err == nil && rtr != nil && isRecvPacketTracker(tracker) {
+			dst.awaitAckProofs(uint64(rtr.Height))
+		}
 
 		t, ok := tracker.(packetMessageToTrack)
 		if !ok {
@@ -555,7 +991,7 @@ func (mp *messageProcessor) sendSingleMessage(
 		mp.metrics.IncPacketsRelayed(dst.info.PathName, dst.info.ChainID, channel, port, t.msg.eventType)
 	}
 
-	callbacks = append(callbacks, callback)
+	callbacks = append(callbacks, callback, mp.txResultCallback(dst, msgs))
 
 	//During testing, this adds a callback so our test case can inspect the TX results
 	if PathProcMessageCollector != nil {
@@ -571,9 +1007,9 @@ func (mp *messageProcessor) sendSingleMessage(
 		callbacks = append(callbacks, testCallback)
 	}
 
-	err := dst.chainProvider.SendMessagesToMempool(broadcastCtx, msgs, mp.memo, ctx, callbacks)
+	err := mp.sendMessages(ctx, dst, msgs, []messageToTrack{tracker}, callbacks)
 	if err != nil {
-		dst.finishedProcessing <- tracker
+		dst.finishProcessing(tracker, err)
 		errFields := []zapcore.Field{
 			zap.String("path_name", src.info.PathName),
 			zap.String("src_chain_id", src.info.ChainID),
@@ -597,6 +1033,78 @@ func (mp *messageProcessor) sendSingleMessage(
 	dst.log.Debug(fmt.Sprintf("Successfully broadcasted %s message", msgType), zap.Object("msg", tracker))
 }
 
+// sendMessages broadcasts msgs to dst in a transaction once fewer than the max in-flight transactions of dst
+// await inclusion and the path is granted a transaction from the per-block budget of dst, allowing the broadcast
+// the tx timeout of the retry policy. The transaction holds its in-flight slot until its callbacks are called,
+// and its result counts towards the consecutive tx failures notified for dst. The broadcast and result of the
+// messages, of which those of trackers are the last, are published to the relay events stream. Since it blocks
+// until a slot is free, it must be called from a send goroutine, never from the PathProcessor loop.
+func (mp *messageProcessor) sendMessages(
+	ctx context.Context,
+	dst *pathEndRuntime,
+	msgs []provider.RelayerMessage,
+	trackers []messageToTrack,
+	callbacks []func(rtr *provider.RelayerTxResponse, err error),
+) error {
+	if err := dst.txSlots.acquire(ctx); err != nil {
+		return err
+	}
+	release := sync.OnceFunc(dst.txSlots.release)
+	if err := dst.txScheduler.acquire(ctx, dst.info.PathName); err != nil {
+		release()
+		return err
+	}
+	callbacks = append(callbacks, func(rtr *provider.RelayerTxResponse, err error) {
+		release()
+		dst.notifyTxResult(err)
+		dst.publishTxResult(msgs, trackers, rtr, err)
+	})
+
+	if err := mp.broadcast(ctx, dst, msgs, callbacks); err != nil {
+		release()
+		dst.notifyTxResult(err)
+		dst.publishTxResult(msgs, trackers, nil, err)
+		return err
+	}
+	dst.publishBroadcast(msgs, trackers)
+	return nil
+}
+
+// txResultCallback returns a callback which retains the result of the transaction containing msgs,
+// broadcast to dst, for inspection through the control API.
+func (mp *messageProcessor) txResultCallback(
+	dst *pathEndRuntime,
+	msgs []provider.RelayerMessage,
+) func(*provider.RelayerTxResponse, error) {
+	msgTypes := make([]string, len(msgs))
+	for i, msg := range msgs {
+		msgTypes[i] = msg.Type()
+	}
+
+	return func(rtr *provider.RelayerTxResponse, err error) {
+		if mp.txResults == nil {
+			return
+		}
+		result := TxResult{
+			Time:     time.Now(),
+			ChainID:  dst.info.ChainID,
+			MsgTypes: msgTypes,
+		}
+		if rtr != nil {
+			result.TxHash = rtr.TxHash
+			result.Height = rtr.Height
+			result.Code = rtr.Code
+			result.Codespace = rtr.Codespace
+			result.Log = rtr.Log
+			result.Events = rtr.Events
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		mp.txResults.add(result)
+	}
+}
+
 func (mp *messageProcessor) metricParseTxFailureCatagory(err error, src *pathEndRuntime) {
 	if mp.metrics == nil {
 		return
@@ -0,0 +1,482 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSplitBatch(t *testing.T) {
+	batch := make([]messageToTrack, 5)
+	for i := range batch {
+		batch[i] = packetMessageToTrack{msg: packetIBCMessage{info: provider.PacketInfo{Sequence: uint64(i + 1)}}}
+	}
+
+	require.Equal(t, [][]messageToTrack{batch}, splitBatch(batch, 0))
+	require.Equal(t, [][]messageToTrack{batch}, splitBatch(batch, 5))
+	require.Equal(t, [][]messageToTrack{batch[:2], batch[2:4], batch[4:]}, splitBatch(batch, 2))
+}
+
+func TestFailedMessageIndex(t *testing.T) {
+	_, ok := failedMessageIndex(nil, errors.New("insufficient funds"))
+	require.False(t, ok)
+
+	i, ok := failedMessageIndex(nil, errors.New("failed to execute message; message index: 3: receive packet verification failed"))
+	require.True(t, ok)
+	require.Equal(t, 3, i)
+
+	i, ok = failedMessageIndex(&provider.RelayerTxResponse{
+		Log: "failed to execute message; message index: 1: panic in app callback",
+	}, errors.New("transaction failed to execute"))
+	require.True(t, ok)
+	require.Equal(t, 1, i)
+}
+
+type relayedPacketsProvider struct {
+	provider.ChainProvider
+
+	// unreceived packets and acknowledgements by channel, or err if the query fails.
+	unreceivedPackets map[string][]uint64
+	unreceivedAcks    map[string][]uint64
+	err               error
+}
+
+func (p relayedPacketsProvider) QueryUnreceivedPackets(_ context.Context, _ uint64, channelID, _ string, _ []uint64) ([]uint64, error) {
+	return p.unreceivedPackets[channelID], p.err
+}
+
+func (p relayedPacketsProvider) QueryUnreceivedAcknowledgements(_ context.Context, _ uint64, channelID, _ string, _ []uint64) ([]uint64, error) {
+	return p.unreceivedAcks[channelID], p.err
+}
+
+type mockRelayerMessage struct{}
+
+func (mockRelayerMessage) Type() string              { return "mock" }
+func (mockRelayerMessage) MsgBytes() ([]byte, error) { return nil, nil }
+
+func TestSkipRelayedPacketMessages(t *testing.T) {
+	recv := func(seq uint64, assembled bool) packetMessageToTrack {
+		m := packetMessageToTrack{msg: packetIBCMessage{
+			eventType: chantypes.EventTypeRecvPacket,
+			info:      provider.PacketInfo{Sequence: seq, SourceChannel: "channel-0", DestChannel: "channel-1"},
+		}}
+		if assembled {
+			m.assembled = mockRelayerMessage{}
+		}
+		return m
+	}
+	ack := func(seq uint64) packetMessageToTrack {
+		return packetMessageToTrack{
+			msg: packetIBCMessage{
+				eventType: chantypes.EventTypeAcknowledgePacket,
+				info:      provider.PacketInfo{Sequence: seq, SourceChannel: "channel-0", DestChannel: "channel-1"},
+			},
+			assembled: mockRelayerMessage{},
+		}
+	}
+	seqs := func(msgs []packetMessageToTrack) (s []uint64) {
+		for _, m := range msgs {
+			s = append(s, m.msg.info.Sequence)
+		}
+		return s
+	}
+
+	for _, tc := range []struct {
+		name            string
+		provider        relayedPacketsProvider
+		msgs            []packetMessageToTrack
+		expectedSeqs    []uint64
+		expectedSkipped int
+	}{
+		{
+			name:         "unrelayed packets are sent",
+			provider:     relayedPacketsProvider{unreceivedPackets: map[string][]uint64{"channel-1": {1, 2}}},
+			msgs:         []packetMessageToTrack{recv(1, true), recv(2, true)},
+			expectedSeqs: []uint64{1, 2},
+		},
+		{
+			name:            "packets received by another relayer are skipped",
+			provider:        relayedPacketsProvider{unreceivedPackets: map[string][]uint64{"channel-1": {2}}},
+			msgs:            []packetMessageToTrack{recv(1, true), recv(2, true)},
+			expectedSeqs:    []uint64{2},
+			expectedSkipped: 1,
+		},
+		{
+			name:            "acknowledgements relayed by another relayer are skipped",
+			provider:        relayedPacketsProvider{unreceivedAcks: map[string][]uint64{"channel-0": {4}}},
+			msgs:            []packetMessageToTrack{ack(3), ack(4)},
+			expectedSeqs:    []uint64{4},
+			expectedSkipped: 1,
+		},
+		{
+			name:         "messages which failed to assemble are kept",
+			provider:     relayedPacketsProvider{},
+			msgs:         []packetMessageToTrack{recv(1, false)},
+			expectedSeqs: []uint64{1},
+		},
+		{
+			name:         "packets are sent if the packet state cannot be queried",
+			provider:     relayedPacketsProvider{err: errors.New("rpc unavailable")},
+			msgs:         []packetMessageToTrack{recv(1, true), ack(3)},
+			expectedSeqs: []uint64{1, 3},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mp := &messageProcessor{pktMsgs: tc.msgs}
+			dst := &pathEndRuntime{log: zap.NewNop(), chainProvider: tc.provider}
+
+			mp.skipRelayedPacketMessages(context.Background(), dst)
+			require.Equal(t, tc.expectedSeqs, seqs(mp.pktMsgs))
+			require.Equal(t, tc.expectedSkipped, mp.skippedCount)
+		})
+	}
+}
+
+// seqMessage is a RelayerMessage for the packet with seq.
+type seqMessage uint64
+
+func (seqMessage) Type() string              { return "mock" }
+func (seqMessage) MsgBytes() ([]byte, error) { return nil, nil }
+
+// failingBatchProvider fails the txs which contain the message of the packet with seq bad with err,
+// and records the packet sequences of each tx sent. If broadcastErr is set, every broadcast fails with it
+// before the tx reaches the chain.
+type failingBatchProvider struct {
+	provider.ChainProvider
+
+	bad          uint64
+	err          error
+	broadcastErr error
+
+	mu  sync.Mutex
+	txs [][]uint64
+}
+
+func (p *failingBatchProvider) SendMessagesToMempool(
+	_ context.Context,
+	msgs []provider.RelayerMessage,
+	_ string,
+	_ context.Context,
+	callbacks []func(*provider.RelayerTxResponse, error),
+) error {
+	var seqs []uint64
+	var err error
+	for _, msg := range msgs {
+		seq := uint64(msg.(seqMessage))
+		seqs = append(seqs, seq)
+		if seq == p.bad {
+			err = p.err
+		}
+	}
+	p.mu.Lock()
+	p.txs = append(p.txs, seqs)
+	p.mu.Unlock()
+
+	if p.broadcastErr != nil {
+		return p.broadcastErr
+	}
+
+	rtr := &provider.RelayerTxResponse{}
+	if err != nil {
+		rtr.Code = 1
+	}
+	for _, cb := range callbacks {
+		cb(rtr, err)
+	}
+	return nil
+}
+
+func (p *failingBatchProvider) sent() [][]uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.txs)
+}
+
+func TestBisectFailedBatch(t *testing.T) {
+	batch := func(seqs ...uint64) (b []messageToTrack) {
+		for _, seq := range seqs {
+			b = append(b, packetMessageToTrack{
+				msg: packetIBCMessage{
+					eventType: chantypes.EventTypeRecvPacket,
+					info: provider.PacketInfo{
+						Sequence:      seq,
+						SourceChannel: "channel-0",
+						SourcePort:    "transfer",
+						DestChannel:   "channel-1",
+						DestPort:      "transfer",
+					},
+				},
+				assembled: seqMessage(seq),
+			})
+		}
+		return b
+	}
+
+	for _, tc := range []struct {
+		name               string
+		err                error
+		broadcastErr       error
+		expectedTxs        [][]uint64
+		expectedQuarantine []uint64
+	}{
+		{
+			name:               "failing message is isolated and quarantined",
+			err:                errors.New("transaction failed: app rejected packet"),
+			expectedTxs:        [][]uint64{{1, 2, 3, 4}, {1, 2}, {3, 4}, {3}, {4}},
+			expectedQuarantine: []uint64{3},
+		},
+		{
+			name:        "failure blamed on a message by the chain is not bisected",
+			err:         errors.New("failed to execute message; message index: 2: app rejected packet"),
+			expectedTxs: [][]uint64{{1, 2, 3, 4}, {1, 2, 4}},
+		},
+		{
+			name:        "failure not caused by the messages is not bisected",
+			err:         errors.New("insufficient funds"),
+			expectedTxs: [][]uint64{{1, 2, 3, 4}},
+		},
+		{
+			name:         "tx which did not reach the chain is not bisected",
+			broadcastErr: errors.New("post failed: dial tcp 127.0.0.1:26657: connect: connection refused"),
+			expectedTxs:  [][]uint64{{1, 2, 3, 4}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &failingBatchProvider{bad: 3, err: tc.err, broadcastErr: tc.broadcastErr}
+			mp := &messageProcessor{log: zap.NewNop(), isLocalhost: true}
+			src := &pathEndRuntime{log: zap.NewNop(), info: PathEnd{PathName: "demo-path", ChainID: "chain-a"}}
+			dst := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+			dst.chainProvider = cp
+
+			mp.sendBatchMessages(context.Background(), src, dst, batch(1, 2, 3, 4), false)
+
+			require.Eventually(t, func() bool {
+				return len(cp.sent()) == len(tc.expectedTxs)
+			}, time.Second, time.Millisecond)
+			require.ElementsMatch(t, tc.expectedTxs, cp.sent())
+
+			var quarantined []uint64
+			for _, p := range dst.quarantine.list() {
+				quarantined = append(quarantined, p.Sequence)
+			}
+			require.Equal(t, tc.expectedQuarantine, quarantined)
+		})
+	}
+}
+
+type packetProofProvider struct {
+	provider.ChainProvider
+
+	mu              sync.Mutex
+	inFlight        int
+	maxInFlight     int
+	headerHeights   []int64
+	commitmentQuery time.Duration
+}
+
+func (p *packetProofProvider) PacketCommitment(_ context.Context, _ provider.PacketInfo, height uint64) (provider.PacketProof, error) {
+	p.mu.Lock()
+	p.inFlight++
+	p.maxInFlight = max(p.maxInFlight, p.inFlight)
+	p.mu.Unlock()
+
+	time.Sleep(p.commitmentQuery)
+
+	p.mu.Lock()
+	p.inFlight--
+	p.mu.Unlock()
+	return provider.PacketProof{ProofHeight: clienttypes.NewHeight(0, height)}, nil
+}
+
+func (p *packetProofProvider) QueryIBCHeader(_ context.Context, h int64) (provider.IBCHeader, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.headerHeights = append(p.headerHeights, h)
+	return nil, errors.New("no header")
+}
+
+func (p *packetProofProvider) MsgRecvPacket(provider.PacketInfo, provider.PacketProof) (provider.RelayerMessage, error) {
+	return mockRelayerMessage{}, nil
+}
+
+func TestAssemblePacketMessages(t *testing.T) {
+	msgs := make([]packetIBCMessage, 3*packetProofConcurrency)
+	for i := range msgs {
+		msgs[i] = packetIBCMessage{
+			eventType: chantypes.EventTypeRecvPacket,
+			info:      provider.PacketInfo{Sequence: uint64(i + 1), SourceChannel: "channel-0", DestChannel: "channel-1"},
+		}
+	}
+
+	prov := &packetProofProvider{commitmentQuery: 10 * time.Millisecond}
+	src := &pathEndRuntime{
+		log:           zap.NewNop(),
+		chainProvider: prov,
+		clientState:   provider.ClientState{ClientID: "07-tendermint-0"},
+	}
+	src.headers = NewHeaderSynchronizer(prov)
+	dst := &pathEndRuntime{log: zap.NewNop(), chainProvider: prov}
+	mp := &messageProcessor{proofHeight: 100}
+
+	mp.assembleMessages(context.Background(), pathEndMessages{packetMessages: msgs}, src, dst)
+
+	require.Len(t, mp.pktMsgs, len(msgs))
+	for i, m := range mp.pktMsgs {
+		require.Equal(t, uint64(i+1), m.msg.info.Sequence)
+		require.NotNil(t, m.assembled)
+	}
+	require.Equal(t, packetProofConcurrency, prov.maxInFlight)
+	require.Empty(t, prov.headerHeights, "header is only needed to verify proofs")
+
+	// with proof verification, the header at the proof height is looked up once for the whole batch.
+	src.verifyProofs = true
+	mp.assembleMessages(context.Background(), pathEndMessages{packetMessages: msgs}, src, dst)
+	require.Equal(t, []int64{100}, prov.headerHeights)
+}
+
+// separateUpdateConfig configures separate client updates and batch broadcasts.
+type separateUpdateConfig struct {
+	provider.ProviderConfig
+}
+
+func (separateUpdateConfig) BroadcastMode() provider.BroadcastMode {
+	return provider.BroadcastModeBatch
+}
+func (separateUpdateConfig) ClientUpdateMode() provider.ClientUpdateMode {
+	return provider.ClientUpdateModeSeparate
+}
+func (separateUpdateConfig) MaxBatchMsgs() uint64 { return 0 }
+
+type separateUpdateProvider struct {
+	*failingBatchProvider
+}
+
+func (separateUpdateProvider) ProviderConfig() provider.ProviderConfig { return separateUpdateConfig{} }
+
+func TestTrackAndSendMessagesWithoutTxSlot(t *testing.T) {
+	cp := &failingBatchProvider{}
+	mp := &messageProcessor{
+		log:              zap.NewNop(),
+		msgsUpdateClient: []provider.RelayerMessage{seqMessage(0)},
+	}
+	for _, seq := range []uint64{1, 2} {
+		mp.pktMsgs = append(mp.pktMsgs, packetMessageToTrack{
+			msg: packetIBCMessage{
+				eventType: chantypes.EventTypeRecvPacket,
+				info: provider.PacketInfo{
+					Sequence:      seq,
+					SourceChannel: "channel-0",
+					SourcePort:    "transfer",
+					DestChannel:   "channel-1",
+					DestPort:      "transfer",
+				},
+			},
+			assembled: seqMessage(seq),
+		})
+	}
+	src := &pathEndRuntime{log: zap.NewNop(), info: PathEnd{PathName: "demo-path", ChainID: "chain-a"}}
+	dst := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+	dst.chainProvider = separateUpdateProvider{cp}
+
+	// the only tx slot of dst is held by a tx awaiting inclusion.
+	dst.txSlots = newSemaphore(1)
+	require.NoError(t, dst.txSlots.acquire(context.Background()))
+
+	done := make(chan error)
+	go func() {
+		done <- mp.trackAndSendMessages(context.Background(), src, dst, false)
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("waiting for a tx slot for the client update blocked the PathProcessor")
+	}
+	require.Empty(t, cp.sent())
+
+	// once the tx is included, the client update is sent before the messages.
+	dst.txSlots.release()
+	require.Eventually(t, func() bool {
+		return len(cp.sent()) == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, [][]uint64{{0}, {1, 2}}, cp.sent())
+}
+
+// sendlessProvider fails the test if any tx is sent with it.
+type sendlessProvider struct {
+	provider.ChainProvider
+
+	t *testing.T
+}
+
+func (p sendlessProvider) SendMessage(context.Context, provider.RelayerMessage, string) (*provider.RelayerTxResponse, bool, error) {
+	p.t.Error("monitor only processor sent a message")
+	return nil, false, errors.New("monitor only")
+}
+
+func (p sendlessProvider) SendMessages(context.Context, []provider.RelayerMessage, string) (*provider.RelayerTxResponse, bool, error) {
+	p.t.Error("monitor only processor sent messages")
+	return nil, false, errors.New("monitor only")
+}
+
+func (p sendlessProvider) SendMessagesToMempool(
+	context.Context,
+	[]provider.RelayerMessage,
+	string,
+	context.Context,
+	[]func(*provider.RelayerTxResponse, error),
+) error {
+	p.t.Error("monitor only processor sent messages to the mempool")
+	return errors.New("monitor only")
+}
+
+func TestProcessMessagesMonitorOnly(t *testing.T) {
+	const trustingPeriod = 21 * 24 * time.Hour
+
+	metrics := NewPrometheusMetrics()
+	mp := newMessageProcessor(zap.NewNop(), metrics, "", time.Hour, false)
+	mp.monitorOnly = true
+
+	cp := sendlessProvider{t: t}
+	src := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-a", ClientID: "07-tendermint-0"}, nil)
+	src.chainProvider = cp
+	src.clientState = provider.ClientState{ClientID: "07-tendermint-0"}
+	src.latestBlock = provider.LatestBlock{Height: 100}
+	dst := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b", ClientID: "07-tendermint-1"}, nil)
+	dst.chainProvider = cp
+	dst.latestBlock = provider.LatestBlock{Height: 100}
+
+	// the client is past the update threshold, so a relaying processor would update it along with the packet.
+	dst.clientState = provider.ClientState{
+		ClientID:        "07-tendermint-1",
+		ConsensusHeight: clienttypes.NewHeight(0, 90),
+		ConsensusTime:   time.Now().Add(-15 * 24 * time.Hour),
+		TrustingPeriod:  trustingPeriod,
+	}
+	messages := pathEndMessages{packetMessages: []packetIBCMessage{{
+		eventType: chantypes.EventTypeRecvPacket,
+		info:      provider.PacketInfo{Sequence: 1, SourceChannel: "channel-0", DestChannel: "channel-1"},
+	}}}
+
+	require.NoError(t, mp.processMessages(context.Background(), messages, src, dst))
+	require.Empty(t, mp.msgsUpdateClient)
+	require.Empty(t, mp.pktMsgs)
+
+	// the client metrics are still updated.
+	require.InDelta(t, (6 * 24 * time.Hour).Seconds(), testutil.ToFloat64(
+		metrics.ClientExpiration.WithLabelValues("demo-path", "chain-b", "07-tendermint-1", trustingPeriod.String()),
+	), 60)
+	require.Equal(t, trustingPeriod.Seconds(), testutil.ToFloat64(
+		metrics.ClientTrustingPeriod.WithLabelValues("demo-path", "chain-b", "07-tendermint-1"),
+	))
+}
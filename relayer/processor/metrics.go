@@ -18,8 +18,19 @@ type PrometheusMetrics struct {
 	BlockQueryFailure     *prometheus.CounterVec
 	ClientExpiration      *prometheus.GaugeVec
 	ClientTrustingPeriod  *prometheus.GaugeVec
+	ClientParamsUnsafe    *prometheus.GaugeVec
 	UnrelayedPackets      *prometheus.GaugeVec
 	UnrelayedAcks         *prometheus.GaugeVec
+	FailedAcks            *prometheus.CounterVec
+	FeeGrantAllowance     *prometheus.GaugeVec
+	PacketQueueDepth      *prometheus.GaugeVec
+	PacketQueueAge        *prometheus.GaugeVec
+	ChainHalted           *prometheus.GaugeVec
+	SLAMedianLatency      *prometheus.GaugeVec
+	SLAWithinMinute       *prometheus.GaugeVec
+	SLALongestStall       *prometheus.GaugeVec
+	RelayStageDuration    *prometheus.HistogramVec
+	RelayStageFailures    *prometheus.CounterVec
 }
 
 func (m *PrometheusMetrics) AddPacketsObserved(pathName, chain, channel, port, eventType string, count int) {
@@ -50,6 +61,14 @@ func (m *PrometheusMetrics) SetClientTrustingPeriod(pathName, chain, clientID st
 	m.ClientTrustingPeriod.WithLabelValues(pathName, chain, clientID).Set(trustingPeriod.Abs().Seconds())
 }
 
+func (m *PrometheusMetrics) SetClientParamsUnsafe(pathName, chain, clientID string, unsafe bool) {
+	var value float64
+	if unsafe {
+		value = 1
+	}
+	m.ClientParamsUnsafe.WithLabelValues(pathName, chain, clientID).Set(value)
+}
+
 func (m *PrometheusMetrics) IncBlockQueryFailure(chain, err string) {
 	m.BlockQueryFailure.WithLabelValues(chain, err).Inc()
 }
@@ -66,6 +85,40 @@ func (m *PrometheusMetrics) SetUnrelayedAcks(pathName, srcChain, destChain, srcC
 	m.UnrelayedAcks.WithLabelValues(pathName, srcChain, destChain, srcChannel, destChannel).Set(float64(UnrelayedAcks))
 }
 
+func (m *PrometheusMetrics) IncFailedAcks(pathName, chain, channel, port string) {
+	m.FailedAcks.WithLabelValues(pathName, chain, channel, port).Inc()
+}
+
+func (m *PrometheusMetrics) SetFeeGrantAllowance(chain, granter, grantee, denom string, amount float64) {
+	m.FeeGrantAllowance.WithLabelValues(chain, granter, grantee, denom).Set(amount)
+}
+
+func (m *PrometheusMetrics) SetPacketQueue(pathName, chain string, depth int, oldest time.Duration) {
+	m.PacketQueueDepth.WithLabelValues(pathName, chain).Set(float64(depth))
+	m.PacketQueueAge.WithLabelValues(pathName, chain).Set(oldest.Seconds())
+}
+
+func (m *PrometheusMetrics) SetChainHalted(chain string, halted bool) {
+	var value float64
+	if halted {
+		value = 1
+	}
+	m.ChainHalted.WithLabelValues(chain).Set(value)
+}
+
+func (m *PrometheusMetrics) SetSLAStats(stats SLAStats) {
+	m.SLAMedianLatency.WithLabelValues(stats.PathName).Set(stats.MedianLatencySeconds)
+	m.SLAWithinMinute.WithLabelValues(stats.PathName).Set(stats.RelayedWithinMinutePercent)
+	m.SLALongestStall.WithLabelValues(stats.PathName).Set(stats.LongestStallSeconds)
+}
+
+func (m *PrometheusMetrics) ObserveRelayStage(pathName, chain, stage string, d time.Duration, failed bool) {
+	m.RelayStageDuration.WithLabelValues(pathName, chain, stage).Observe(d.Seconds())
+	if failed {
+		m.RelayStageFailures.WithLabelValues(pathName, chain, stage).Inc()
+	}
+}
+
 func NewPrometheusMetrics() *PrometheusMetrics {
 	packetLabels := []string{"path_name", "chain", "channel", "port", "type"}
 	heightLabels := []string{"chain"}
@@ -75,6 +128,11 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 	clientExpirationLables := []string{"path_name", "chain", "client_id", "trusting_period"}
 	clientTrustingPeriodLables := []string{"path_name", "chain", "client_id"}
 	unrelayedSeqsLabels := []string{"path_name", "src_chain", "dest_chain", "src_channel", "dest_channel"}
+	failedAckLabels := []string{"path_name", "chain", "channel", "port"}
+	feeGrantLabels := []string{"chain", "granter", "grantee", "denom"}
+	packetQueueLabels := []string{"path_name", "chain"}
+	slaLabels := []string{"path_name"}
+	relayStageLabels := []string{"path_name", "chain", "stage"}
 	registry := prometheus.NewRegistry()
 	registerer := promauto.With(registry)
 	return &PrometheusMetrics{
@@ -115,6 +173,10 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			Name: "cosmos_relayer_client_trusting_period_seconds",
 			Help: "The trusting period (in seconds) of the client",
 		}, clientTrustingPeriodLables),
+		ClientParamsUnsafe: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_client_params_unsafe",
+			Help: "1 if the trusting period of the client is not shorter than the unbonding period of the chain it tracks, otherwise 0",
+		}, clientTrustingPeriodLables),
 		UnrelayedPackets: registerer.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "cosmos_relayer_unrelayed_packets",
 			Help: "Current number of unrelayed packets on both the source and destination chains for a specific path and channel",
@@ -123,5 +185,46 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			Name: "cosmos_relayer_unrelayed_acks",
 			Help: "Current number of unrelayed acknowledgements on both the source and destination chains for a specific path and channel",
 		}, unrelayedSeqsLabels),
+		FailedAcks: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "cosmos_relayer_failed_acks_total",
+			Help: "The total number of error acknowledgements written by the chain for received packets, e.g. for rejected transfers",
+		}, failedAckLabels),
+		FeeGrantAllowance: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_fee_grant_allowance",
+			Help: "The remaining spend limit of the allowance which the fee-granter of the chain granted to the relayer's wallet",
+		}, feeGrantLabels),
+		PacketQueueDepth: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_packet_queue_depth",
+			Help: "Current number of packet messages queued to be sent to the chain for a specific path",
+		}, packetQueueLabels),
+		PacketQueueAge: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_packet_queue_oldest_seconds",
+			Help: "Seconds since the oldest packet message queued to be sent to the chain for a specific path was first queued",
+		}, packetQueueLabels),
+		ChainHalted: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_chain_halted",
+			Help: "1 if the chain has stopped producing blocks and relaying to it is paused, otherwise 0",
+		}, heightLabels),
+		SLAMedianLatency: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_sla_median_latency_seconds",
+			Help: "Median seconds from observing a packet to observing its acknowledgement or timeout over the last day for a specific path",
+		}, slaLabels),
+		SLAWithinMinute: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_sla_relayed_within_minute_percent",
+			Help: "Percentage of the packets relayed over the last day for a specific path which were acknowledged or timed out within one minute of being observed",
+		}, slaLabels),
+		SLALongestStall: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cosmos_relayer_sla_longest_stall_seconds",
+			Help: "Longest period over the last day during which packets were pending but none were relayed for a specific path",
+		}, slaLabels),
+		RelayStageDuration: registerer.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cosmos_relayer_relay_stage_duration_seconds",
+			Help:    "Duration of the stages of the relay pipeline (observe, proof, build, broadcast, confirm) relaying to the chain for a specific path",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, relayStageLabels),
+		RelayStageFailures: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "cosmos_relayer_relay_stage_failures_total",
+			Help: "The total number of failures of the stages of the relay pipeline relaying to the chain for a specific path",
+		}, relayStageLabels),
 	}
 }
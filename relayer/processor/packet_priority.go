@@ -0,0 +1,165 @@
+package processor
+
+import (
+	"sort"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+const (
+	// How close to timing out on the destination chain a packet is received ahead of other packets.
+	packetTimeoutPriorityWindow = 10 * time.Minute
+
+	// How close to timing out on the destination chain, in blocks, a packet with a timeout height
+	// is received ahead of other packets.
+	packetTimeoutPriorityBlocks = 100
+)
+
+// Priorities of packet messages. They are combined, so that e.g. an incentivized packet on a priority channel
+// is relayed ahead of an incentivized packet on another channel, which is relayed ahead of any packet which is not
+// incentivized.
+const (
+	// the packet is on a channel which the path flags as a priority channel.
+	packetPriorityChannel = 1 << iota
+
+	// fees for relaying the packet were escrowed with the ICS-29 fee middleware.
+	packetPriorityIncentivized
+
+	// the packet times out on the destination chain soon.
+	packetPriorityNearTimeout
+)
+
+// packetQueueKey identifies a packet message queued to be sent to a chain.
+type packetQueueKey struct {
+	eventType string
+	channel   ChannelKey
+	sequence  uint64
+}
+
+// packetPriority returns the priority of sending msg to a chain at its latest block dst, higher first.
+func packetPriority(msg packetIBCMessage, dst provider.LatestBlock, priorityChannel bool) int {
+	priority := 0
+	if priorityChannel {
+		priority |= packetPriorityChannel
+	}
+	if msg.eventType != chantypes.EventTypeRecvPacket {
+		return priority
+	}
+	if msg.info.Incentivized {
+		priority |= packetPriorityIncentivized
+	}
+	if packetNearTimeout(msg.info, dst) {
+		priority |= packetPriorityNearTimeout
+	}
+	return priority
+}
+
+// packetNearTimeout returns true if the packet has not timed out at the latest block of the destination chain,
+// but will within the priority window.
+func packetNearTimeout(info provider.PacketInfo, dst provider.LatestBlock) bool {
+	if h := info.TimeoutHeight.RevisionHeight; h > dst.Height && h-dst.Height <= packetTimeoutPriorityBlocks {
+		return true
+	}
+	if info.TimeoutTimestamp == 0 || dst.Time.IsZero() {
+		return false
+	}
+	remaining := time.Unix(0, int64(info.TimeoutTimestamp)).Sub(dst.Time)
+	return remaining > 0 && remaining <= packetTimeoutPriorityWindow
+}
+
+// isPriorityChannel returns true if the channel on this chain is flagged as a priority channel.
+func (pathEnd *pathEndRuntime) isPriorityChannel(counterpartyChainID string, k ChannelKey) bool {
+	channelKey := ChainChannelKey{
+		ChainID:             pathEnd.info.ChainID,
+		CounterpartyChainID: counterpartyChainID,
+		ChannelKey:          k,
+	}
+	for _, priorityChannel := range pathEnd.info.PriorityList {
+		if pathEnd.info.shouldRelayChannelSingle(channelKey, priorityChannel, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// packetPriorities returns the priorities of msgs, the packet messages of a single channel to send to this chain.
+// The messages of an ordered channel all share the highest priority among them, so that they keep their
+// sequence order when sorted.
+func (pathEnd *pathEndRuntime) packetPriorities(msgs []packetIBCMessage, counterpartyChainID string, k ChannelKey) []int {
+	priorityChannel := pathEnd.isPriorityChannel(counterpartyChainID, k)
+
+	priorities := make([]int, len(msgs))
+	ordered := false
+	highest := 0
+	for i, msg := range msgs {
+		priorities[i] = packetPriority(msg, pathEnd.latestBlock, priorityChannel)
+		if priorities[i] > highest {
+			highest = priorities[i]
+		}
+		if msg.info.ChannelOrder == chantypes.ORDERED.String() {
+			ordered = true
+		}
+	}
+
+	if ordered {
+		for i := range priorities {
+			priorities[i] = highest
+		}
+	}
+	return priorities
+}
+
+// trackPacketQueue records when the packet messages to send to this chain were first queued,
+// and reports the depth of the queue and the age of its oldest message, which is notified once the path stalls.
+func (pathEnd *pathEndRuntime) trackPacketQueue(msgs []packetIBCMessage, now time.Time) {
+	queuedSince := make(map[packetQueueKey]time.Time, len(msgs))
+	var oldest time.Duration
+	for _, msg := range msgs {
+		k := packetQueueKey{
+			eventType: msg.eventType,
+			channel:   packetInfoChannelKey(msg.info),
+			sequence:  msg.info.Sequence,
+		}
+		since, ok := pathEnd.packetQueuedSince[k]
+		if !ok {
+			since = now
+		}
+		queuedSince[k] = since
+		if age := now.Sub(since); age > oldest {
+			oldest = age
+		}
+	}
+	pathEnd.packetQueuedSince = queuedSince
+	pathEnd.txScheduler.setBacklog(pathEnd.info.PathName, pathEnd.txWeight, len(msgs))
+
+	if pathEnd.metrics != nil {
+		pathEnd.metrics.SetPacketQueue(pathEnd.info.PathName, pathEnd.info.ChainID, len(msgs), oldest)
+	}
+	pathEnd.notifier.PacketQueue(pathEnd.info.PathName, pathEnd.info.ChainID, len(msgs), oldest)
+}
+
+// packetMessagesByPriority sorts packet messages by their priorities, highest first.
+type packetMessagesByPriority struct {
+	msgs       []packetIBCMessage
+	priorities []int
+}
+
+func (p packetMessagesByPriority) Len() int {
+	return len(p.msgs)
+}
+
+func (p packetMessagesByPriority) Less(i, j int) bool {
+	return p.priorities[i] > p.priorities[j]
+}
+
+func (p packetMessagesByPriority) Swap(i, j int) {
+	p.msgs[i], p.msgs[j] = p.msgs[j], p.msgs[i]
+	p.priorities[i], p.priorities[j] = p.priorities[j], p.priorities[i]
+}
+
+// sortPacketMessages orders msgs by priorities, highest first. Messages of equal priority keep their order.
+func sortPacketMessages(msgs []packetIBCMessage, priorities []int) {
+	sort.Stable(packetMessagesByPriority{msgs: msgs, priorities: priorities})
+}
@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPacketPriority(t *testing.T) {
+	now := time.Now()
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{
+		ChainID: "chain-b",
+		PriorityList: []ChainChannelKey{{
+			CounterpartyChainID: "chain-a",
+			ChannelKey:          ChannelKey{CounterpartyChannelID: "channel-1"},
+		}},
+	}, nil)
+	pathEnd.latestBlock = provider.LatestBlock{Height: 1000, Time: now}
+
+	recv := func(seq uint64, info provider.PacketInfo) packetIBCMessage {
+		info.Sequence = seq
+		return packetIBCMessage{eventType: chantypes.EventTypeRecvPacket, info: info}
+	}
+
+	channel0 := ChannelKey{ChannelID: "channel-0", CounterpartyChannelID: "channel-0"}
+	channel0Msgs := []packetIBCMessage{
+		recv(1, provider.PacketInfo{}),
+		recv(2, provider.PacketInfo{Incentivized: true}),
+		recv(3, provider.PacketInfo{TimeoutTimestamp: uint64(now.Add(time.Minute).UnixNano())}),
+		recv(4, provider.PacketInfo{TimeoutHeight: clienttypes.NewHeight(0, 1050)}),
+		recv(5, provider.PacketInfo{TimeoutHeight: clienttypes.NewHeight(0, 5000)}),
+	}
+	channel1 := ChannelKey{ChannelID: "channel-1", CounterpartyChannelID: "channel-1"}
+	channel1Msgs := []packetIBCMessage{
+		recv(6, provider.PacketInfo{}),
+		recv(7, provider.PacketInfo{Incentivized: true}),
+	}
+
+	msgs := append(channel0Msgs, channel1Msgs...)
+	priorities := append(
+		pathEnd.packetPriorities(channel0Msgs, "chain-a", channel0),
+		pathEnd.packetPriorities(channel1Msgs, "chain-a", channel1)...,
+	)
+	sortPacketMessages(msgs, priorities)
+
+	var seqs []uint64
+	for _, msg := range msgs {
+		seqs = append(seqs, msg.info.Sequence)
+	}
+	require.Equal(t, []uint64{3, 4, 7, 2, 6, 1, 5}, seqs)
+
+	// the messages of ordered channels keep their sequence order.
+	ordered := []packetIBCMessage{
+		recv(1, provider.PacketInfo{ChannelOrder: chantypes.ORDERED.String()}),
+		recv(2, provider.PacketInfo{ChannelOrder: chantypes.ORDERED.String(), Incentivized: true}),
+	}
+	priorities = pathEnd.packetPriorities(ordered, "chain-a", channel0)
+	sortPacketMessages(ordered, priorities)
+	require.Equal(t, uint64(1), ordered[0].info.Sequence)
+	require.Equal(t, uint64(2), ordered[1].info.Sequence)
+}
+
+func TestTrackPacketQueue(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, metrics)
+
+	msg := func(seq uint64) packetIBCMessage {
+		return packetIBCMessage{
+			eventType: chantypes.EventTypeRecvPacket,
+			info:      provider.PacketInfo{Sequence: seq, SourceChannel: "channel-0", SourcePort: "transfer"},
+		}
+	}
+
+	start := time.Now()
+	pathEnd.trackPacketQueue([]packetIBCMessage{msg(1), msg(2)}, start)
+	pathEnd.trackPacketQueue([]packetIBCMessage{msg(2), msg(3)}, start.Add(time.Minute))
+
+	require.Len(t, pathEnd.packetQueuedSince, 2)
+	require.Equal(t, start, pathEnd.packetQueuedSince[packetQueueKey{
+		eventType: chantypes.EventTypeRecvPacket,
+		channel:   ChannelKey{ChannelID: "channel-0", PortID: "transfer"},
+		sequence:  2,
+	}])
+}
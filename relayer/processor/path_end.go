@@ -1,5 +1,11 @@
 package processor
 
+import (
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
 // PathEnd references one chain involved in a path.
 // A path is composed of two PathEnds.
 type PathEnd struct {
@@ -11,6 +17,28 @@ type PathEnd struct {
 	// Can be either "allowlist" or "denylist"
 	Rule       string
 	FilterList []ChainChannelKey // which channels to allow or deny
+
+	// ConnectionHops are the connection hops of the multihop channels opened on this chain, if any.
+	ConnectionHops []string
+
+	// PriorityList are the channels whose packets are relayed ahead of the packets of other channels.
+	PriorityList []ChainChannelKey
+
+	// ClientMessages builds the messages updating the client on this chain, if set. Otherwise they are built by the
+	// provider of the counterparty chain.
+	ClientMessages ClientMessageBuilder
+}
+
+// ClientMessageBuilder builds the messages updating a client of a type of light client.
+type ClientMessageBuilder interface {
+	// ClientMessage returns the message updating a client tracking the counterparty chain from its trusted header,
+	// at trustedHeight, to its latest header.
+	ClientMessage(
+		counterparty provider.ChainProvider,
+		latestHeader provider.IBCHeader,
+		trustedHeight clienttypes.Height,
+		trustedHeader provider.IBCHeader,
+	) (ibcexported.ClientMessage, error)
 }
 
 type ChainChannelKey struct {
@@ -30,6 +58,37 @@ func NewPathEnd(pathName string, chainID string, clientID string, rule string, f
 	}
 }
 
+// WithConnectionHops returns the PathEnd with the connection hops over which it opens multihop channels.
+func (pe PathEnd) WithConnectionHops(hops []string) PathEnd {
+	pe.ConnectionHops = hops
+	return pe
+}
+
+// WithClientMessages returns the PathEnd with the builder of the messages updating its client.
+func (pe PathEnd) WithClientMessages(b ClientMessageBuilder) PathEnd {
+	pe.ClientMessages = b
+	return pe
+}
+
+// clientMessage returns the message updating the client on this chain, which tracks counterparty, to latestHeader.
+func (pe PathEnd) clientMessage(
+	counterparty provider.ChainProvider,
+	latestHeader provider.IBCHeader,
+	trustedHeight clienttypes.Height,
+	trustedHeader provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	if pe.ClientMessages == nil {
+		return counterparty.MsgUpdateClientHeader(latestHeader, trustedHeight, trustedHeader)
+	}
+	return pe.ClientMessages.ClientMessage(counterparty, latestHeader, trustedHeight, trustedHeader)
+}
+
+// WithPriorityChannels returns the PathEnd with the channels whose packets are relayed first.
+func (pe PathEnd) WithPriorityChannels(priorityList []ChainChannelKey) PathEnd {
+	pe.PriorityList = priorityList
+	return pe
+}
+
 const (
 	RuleAllowList = "allowlist"
 	RuleDenyList  = "denylist"
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
@@ -11,6 +12,8 @@ import (
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 )
@@ -25,8 +28,13 @@ type pathEndRuntime struct {
 
 	chainProvider provider.ChainProvider
 
+	// txInclusionTimeout is how long a message sent to this chain is waited on for inclusion in a block
+	// before it is sent again, from the config of chainProvider.
+	txInclusionTimeout provider.BlockTimeout
+
 	// cached data
 	latestBlock          provider.LatestBlock
+	blockInterval        BlockInterval
 	messageCache         IBCMessagesCache
 	clientState          provider.ClientState
 	clientTrustedState   provider.ClientTrustedState
@@ -37,6 +45,19 @@ type pathEndRuntime struct {
 	latestHeader         provider.IBCHeader
 	ibcHeaderCache       IBCHeaderCache
 
+	// headers of the chain shared by all PathProcessors relaying on it, if linked by the EventProcessor.
+	headers *HeaderSynchronizer
+
+	// the ChainProcessor of the chain, if it can be made to query new blocks as soon as they are produced.
+	queryWaker QueryWaker
+
+	// if true, acknowledgements written on this chain are relayed as soon as they can be proven.
+	sameBlockAcks bool
+
+	// restricts the ICS-20 transfers which are relayed by their denom and amount.
+	denomPolicy DenomPolicy
+	memoFilter  MemoFilter
+
 	// New messages and other data arriving from the handleNewMessagesForPathEnd method.
 	incomingCacheData chan ChainProcessorCacheData
 
@@ -49,6 +70,7 @@ type pathEndRuntime struct {
 
 	// Message subscriber callbacks
 	connSubscribers map[string][]func(provider.ConnectionInfo)
+	chanSubscribers map[string][]func(provider.ChannelInfo)
 
 	// inSync indicates whether queries are in sync with latest height of the chain.
 	inSync bool
@@ -56,9 +78,64 @@ type pathEndRuntime struct {
 	lastClientUpdateHeight   uint64
 	lastClientUpdateHeightMu sync.Mutex
 
+	// forceClientUpdate is set by the control API, or after a message failed because the client on this chain
+	// had no consensus state at its proof height, to update the client on this chain regardless of the threshold.
+	forceClientUpdate bool
+
+	// halted is set by the ChainProcessor of this chain while the chain is not producing blocks,
+	// during which no messages are sent to it.
+	halted atomic.Bool
+
+	// upgrade is set by the ChainProcessor of this chain while an upgrade is scheduled on it.
+	upgrade atomic.Pointer[ChainUpgrade]
+
 	metrics *PrometheusMetrics
 
-	finishedProcessing chan messageToTrack
+	// notifies the operator of conditions of this path end which need attention, if set.
+	notifier *notify.Notifier
+
+	// publishes the lifecycle of the messages relayed to this chain, and of the packets observed on it, if set.
+	relayEvents *eventstream.Stream
+
+	// when the packet messages to send to this chain were first queued, to report the age of the queue.
+	packetQueuedSince map[packetQueueKey]time.Time
+
+	// remembers the packet messages relayed to this chain, by the height at which they were included,
+	// so that they are not relayed again after a restart, if enabled.
+	processedEvents       *ProcessedEvents
+	coordinator           Coordinator
+	processedPackets      map[processedPacketKey]uint64
+	processedPrunedHeight uint64
+
+	// error acknowledgements already logged and counted, by the height of the block which wrote them,
+	// so that an ack is reported once even though it is merged again until it is relayed.
+	failedAcks map[failedAckKey]uint64
+
+	// packet flow messages to send to this chain which were isolated as the cause of failed batches.
+	quarantine packetQuarantine
+
+	// SLA statistics of the path, shared with the counterparty path end.
+	sla *slaTracker
+
+	// auditProofHeights enables logging and validation of the heights
+	// used for proofs in messages assembled for this path end.
+	auditProofHeights bool
+
+	// verifyProofs enables verifying the proofs queried from this chain against its headers
+	// before the messages which carry them are sent to the counterparty.
+	verifyProofs bool
+
+	retryPolicy MsgRetryPolicy
+
+	// limits how many transactions broadcast to this path end may await inclusion at once.
+	txSlots semaphore
+
+	// allocates the transactions broadcast to the chain in each block among all PathProcessors relaying on it,
+	// if linked by the EventProcessor, by txWeight and the packet backlog of each path.
+	txScheduler *TxScheduler
+	txWeight    int
+
+	finishedProcessing chan finishedMessage
 	retryCount         uint64
 }
 
@@ -71,7 +148,7 @@ func newPathEndRuntime(log *zap.Logger, pathEnd PathEnd, metrics *PrometheusMetr
 		),
 		info:                 pathEnd,
 		incomingCacheData:    make(chan ChainProcessorCacheData, 100),
-		finishedProcessing:   make(chan messageToTrack, 1000),
+		finishedProcessing:   make(chan finishedMessage, 1000),
 		connectionStateCache: make(ConnectionStateCache),
 		channelStateCache:    make(ChannelStateCache),
 		messageCache:         NewIBCMessagesCache(),
@@ -82,7 +159,11 @@ func newPathEndRuntime(log *zap.Logger, pathEnd PathEnd, metrics *PrometheusMetr
 		channelOrderCache:    make(map[string]chantypes.Order),
 		clientICQProcessing:  newClientICQProcessingCache(),
 		connSubscribers:      make(map[string][]func(provider.ConnectionInfo)),
+		chanSubscribers:      make(map[string][]func(provider.ChannelInfo)),
 		metrics:              metrics,
+		retryPolicy:          DefaultMsgRetryPolicy(),
+		processedPackets:     make(map[processedPacketKey]uint64),
+		failedAcks:           make(map[failedAckKey]uint64),
 	}
 }
 
@@ -144,6 +225,128 @@ func checkMaxReceiverSize(packetData []byte, maxReceiverSize int) error {
 	return nil
 }
 
+// checkRecvPacket returns an error if a packet sent from this chain must not be received on the counterparty,
+// as its memo or receiver exceed the limits, or it is refused by the denom policy or the memo filter. Refused
+// packets are still timed out, so that their senders are refunded.
+func (pathEnd *pathEndRuntime) checkRecvPacket(packetData []byte, memoLimit, maxReceiverSize int) error {
+	if err := checkMemoLimit(packetData, memoLimit); err != nil {
+		return err
+	}
+	if err := checkMaxReceiverSize(packetData, maxReceiverSize); err != nil {
+		return err
+	}
+	if err := pathEnd.denomPolicy.check(packetData); err != nil {
+		return err
+	}
+	return pathEnd.memoFilter.check(packetData)
+}
+
+// failedAckRetentionBlocks is how many blocks of a chain an error acknowledgement written by the chain
+// is remembered for after it was reported.
+const failedAckRetentionBlocks = 1000
+
+// failedAckKey identifies an error acknowledgement by the channel and sequence of the acknowledged packet.
+type failedAckKey struct {
+	channel  ChannelKey
+	sequence uint64
+}
+
+// checkFailedAck logs and counts an error acknowledgement written by this chain for a received packet,
+// so that the sender can learn that the packet was rejected, e.g. an ICS-20 transfer to an invalid receiver
+// or a transfer whose contract execution through the ibc-hooks middleware failed.
+// Each acknowledgement is reported only the first time it is observed.
+func (pathEnd *pathEndRuntime) checkFailedAck(counterpartyChainID string, p provider.PacketInfo) {
+	hook, hasHook := provider.PacketWasmHook(p.Data)
+
+	ackErr, ok := p.AckError()
+	if !ok {
+		if res, ok := provider.AcknowledgementWasmHookResult(p.Ack); ok && hasHook {
+			pathEnd.log.Debug("Packet executed wasm contract",
+				zap.String("counterparty_chain_id", counterpartyChainID),
+				zap.String("dst_channel", p.DestChannel),
+				zap.String("dst_port", p.DestPort),
+				zap.Uint64("sequence", p.Sequence),
+				zap.String("wasm_contract", hook.Contract),
+				zap.ByteString("contract_result", res.ContractResult),
+			)
+		}
+		return
+	}
+
+	k := failedAckKey{channel: packetInfoChannelKey(p), sequence: p.Sequence}
+	if _, seen := pathEnd.failedAcks[k]; seen {
+		return
+	}
+	pathEnd.failedAcks[k] = p.Height
+
+	fields := []zap.Field{
+		zap.String("counterparty_chain_id", counterpartyChainID),
+		zap.String("src_channel", p.SourceChannel),
+		zap.String("src_port", p.SourcePort),
+		zap.String("dst_channel", p.DestChannel),
+		zap.String("dst_port", p.DestPort),
+		zap.Uint64("sequence", p.Sequence),
+		zap.String("ack_error", ackErr),
+	}
+
+	var packet transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(p.Data, &packet); err == nil {
+		fields = append(fields,
+			zap.String("sender", packet.Sender),
+			zap.String("receiver", packet.Receiver),
+			zap.String("amount", packet.Amount+packet.Denom),
+		)
+	}
+	if hasHook {
+		fields = append(fields, zap.String("wasm_contract", hook.Contract))
+	}
+
+	pathEnd.log.Warn("Packet was rejected with an error acknowledgement", fields...)
+
+	if pathEnd.metrics != nil {
+		pathEnd.metrics.IncFailedAcks(pathEnd.info.PathName, pathEnd.info.ChainID, p.DestChannel, p.DestPort)
+	}
+}
+
+// pruneFailedAcks forgets the error acknowledgements written more than failedAckRetentionBlocks ago.
+func (pathEnd *pathEndRuntime) pruneFailedAcks(height uint64) {
+	if height <= failedAckRetentionBlocks {
+		return
+	}
+	below := height - failedAckRetentionBlocks
+	for k, h := range pathEnd.failedAcks {
+		if h < below {
+			delete(pathEnd.failedAcks, k)
+		}
+	}
+}
+
+// recoverMissingConsensusState recovers from a message sent to this chain failing because the client on this chain
+// has no consensus state at the proof height of the message, or at the trusted height of its client update,
+// e.g. because the consensus state was pruned. The client trusted state is assembled again from the current
+// client state, and the client is updated with the next messages, which are assembled with proofs at the height
+// of the update.
+func (pathEnd *pathEndRuntime) recoverMissingConsensusState() {
+	if pathEnd.forceClientUpdate {
+		return
+	}
+	pathEnd.log.Warn("Consensus state for message proof not found on client, updating client and rebuilding message",
+		zap.Stringer("client_consensus_height", pathEnd.clientState.ConsensusHeight),
+		zap.Stringer("trusted_height", pathEnd.clientTrustedState.ClientState.ConsensusHeight),
+	)
+	pathEnd.clientTrustedState = provider.ClientTrustedState{}
+	pathEnd.forceClientUpdate = true
+}
+
+// notifyTxResult counts the result of a transaction broadcast to this chain towards the consecutive tx failures
+// notified for the path. Messages which were already relayed by another relayer are not counted as failures.
+func (pathEnd *pathEndRuntime) notifyTxResult(err error) {
+	if provider.ClassifyTxFailure(err) == provider.TxFailurePacketReceived {
+		return
+	}
+	pathEnd.notifier.TxResult(pathEnd.info.PathName, pathEnd.info.ChainID, err)
+}
+
 // mergeMessageCache merges relevant IBC messages for packet flows, connection handshakes, and channel handshakes.
 // inSync indicates whether both involved ChainProcessors are in sync or not. When true, the observed packets
 // metrics will be counted so that observed vs relayed packets can be compared.
@@ -181,17 +384,23 @@ func (pathEnd *pathEndRuntime) mergeMessageCache(
 
 				newPc := make(PacketSequenceCache)
 				for seq, p := range pCache {
-					if err := checkMemoLimit(p.Data, memoLimit); err != nil {
-						pathEnd.log.Warn("Ignoring packet", zap.Error(err))
-						continue
-					}
-
-					if err := checkMaxReceiverSize(p.Data, maxReceiverSize); err != nil {
-						pathEnd.log.Warn("Ignoring packet", zap.Error(err))
-						continue
+					if eventType == chantypes.EventTypeSendPacket {
+						if err := pathEnd.checkRecvPacket(p.Data, memoLimit, maxReceiverSize); err != nil {
+							pathEnd.log.Warn("Packet will not be received, only timed out",
+								zap.String("channel_id", ch.ChannelID),
+								zap.String("port_id", ch.PortID),
+								zap.Uint64("sequence", seq),
+								zap.Error(err),
+							)
+						}
 					}
 
 					newPc[seq] = p
+					pathEnd.publishPacketObserved(eventType, p)
+
+					if eventType == chantypes.EventTypeWriteAck {
+						pathEnd.checkFailedAck(counterpartyChainID, p)
+					}
 				}
 
 				if len(newPc) > 0 {
@@ -211,6 +420,14 @@ func (pathEnd *pathEndRuntime) mergeMessageCache(
 
 	pathEnd.messageCache.PacketFlow.Merge(packetMessages)
 
+	if inSync && pathEnd.sla != nil {
+		now := time.Now()
+		pathEnd.sla.track(pathEnd.info.ChainID, packetMessages, now)
+		if pathEnd.metrics != nil {
+			pathEnd.metrics.SetSLAStats(pathEnd.sla.stats(pathEnd.info.PathName, now))
+		}
+	}
+
 	for eventType, cmc := range messageCache.ConnectionHandshake {
 		newCmc := make(ConnectionMessageCache)
 		for k, ci := range cmc {
@@ -235,8 +452,8 @@ func (pathEnd *pathEndRuntime) mergeMessageCache(
 			}
 			// can complete channel handshakes on this client
 			// since PathProcessor holds reference to the counterparty chain pathEndRuntime.
-			if eventType == chantypes.EventTypeChannelOpenInit {
-				// CounterpartyConnectionID is needed to construct MsgChannelOpenTry.
+			if eventType == chantypes.EventTypeChannelOpenInit || eventType == chantypes.EventTypeChannelUpgradeInit {
+				// CounterpartyConnectionID is needed to construct MsgChannelOpenTry and MsgChannelUpgradeTry.
 				for k := range pathEnd.connectionStateCache {
 					if k.ConnectionID == ci.ConnID {
 						ci.CounterpartyConnID = k.CounterpartyConnID
@@ -280,6 +497,19 @@ func (pathEnd *pathEndRuntime) handleCallbacks(c IBCMessagesCache) {
 			}
 		}
 	}
+	if len(pathEnd.chanSubscribers) > 0 {
+		for eventType, m := range c.ChannelHandshake {
+			subscribers, ok := pathEnd.chanSubscribers[eventType]
+			if !ok {
+				continue
+			}
+			for _, ci := range m {
+				for _, subscriber := range subscribers {
+					subscriber(ci)
+				}
+			}
+		}
+	}
 }
 
 func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache, messageLifecycle MessageLifecycle) bool {
@@ -324,6 +554,7 @@ func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache
 			return false
 		}
 		// check against m.Termination.Info
+		accepted := m.TerminationFilter == nil
 		foundChannelID := m.Termination.Info.ChannelID == ""
 		foundPortID := m.Termination.Info.PortID == ""
 		foundCounterpartyChannelID := m.Termination.Info.CounterpartyChannelID == ""
@@ -335,6 +566,10 @@ func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache
 				zap.String("termination_counterparty_port_id", m.Termination.Info.CounterpartyPortID),
 				zap.String("observed_counterparty_port_id", ci.CounterpartyPortID),
 			)
+			if m.TerminationFilter != nil && !m.TerminationFilter(ci) {
+				continue
+			}
+			accepted = true
 			if ci.ChannelID == m.Termination.Info.ChannelID {
 				foundChannelID = true
 			}
@@ -348,7 +583,7 @@ func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache
 				foundCounterpartyPortID = true
 			}
 		}
-		if foundChannelID && foundPortID && foundCounterpartyChannelID && foundCounterpartyPortID {
+		if accepted && foundChannelID && foundPortID && foundCounterpartyChannelID && foundCounterpartyPortID {
 			pathEnd.log.Info("Found termination condition for channel handshake")
 			return true
 		}
@@ -396,6 +631,7 @@ func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache
 			return false
 		}
 		// check against m.Termination.Info
+		accepted := m.TerminationFilter == nil
 		foundClientID := m.Termination.Info.ClientID == ""
 		foundConnectionID := m.Termination.Info.ConnID == ""
 		foundCounterpartyClientID := m.Termination.Info.CounterpartyClientID == ""
@@ -407,6 +643,10 @@ func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache
 				zap.String("termination_counterparty_client_id", m.Termination.Info.CounterpartyClientID),
 				zap.String("observed_counterparty_client_id", ci.CounterpartyClientID),
 			)
+			if m.TerminationFilter != nil && !m.TerminationFilter(ci) {
+				continue
+			}
+			accepted = true
 			if ci.ClientID == m.Termination.Info.ClientID {
 				foundClientID = true
 			}
@@ -420,7 +660,7 @@ func (pathEnd *pathEndRuntime) shouldTerminate(ibcMessagesCache IBCMessagesCache
 				foundCounterpartyConnectionID = true
 			}
 		}
-		if foundClientID && foundConnectionID && foundCounterpartyClientID && foundCounterpartyConnectionID {
+		if accepted && foundClientID && foundConnectionID && foundCounterpartyClientID && foundCounterpartyConnectionID {
 			pathEnd.log.Info("Found termination condition for connection handshake")
 			return true
 		}
@@ -462,6 +702,16 @@ func (pathEnd *pathEndRuntime) checkForMisbehaviour(
 	return true, nil
 }
 
+// awaitAckProofs makes the ChainProcessor of this chain query the block after height as soon as it is produced,
+// in same-block ack mode, so that the acknowledgements written on this chain up to height, whose proofs are
+// committed to by the header of the next block, are relayed as soon as they can be proven.
+func (pathEnd *pathEndRuntime) awaitAckProofs(height uint64) {
+	if !pathEnd.sameBlockAcks || pathEnd.queryWaker == nil {
+		return
+	}
+	pathEnd.queryWaker.QueryUntil(height + 1)
+}
+
 func (pathEnd *pathEndRuntime) mergeCacheData(
 	ctx context.Context,
 	cancel func(),
@@ -475,11 +725,19 @@ func (pathEnd *pathEndRuntime) mergeCacheData(
 	pathEnd.lastClientUpdateHeightMu.Lock()
 	pathEnd.latestBlock = d.LatestBlock
 	pathEnd.lastClientUpdateHeightMu.Unlock()
+	pathEnd.blockInterval.Observe(d.LatestBlock.Height, d.LatestBlock.Time)
+	pathEnd.txScheduler.newBlock(d.LatestBlock.Height)
+	pathEnd.pruneProcessedPackets(ctx)
+	pathEnd.pruneFailedAcks(d.LatestBlock.Height)
 
 	pathEnd.inSync = d.InSync
 	pathEnd.latestHeader = d.LatestHeader
 	pathEnd.clientState = d.ClientState
 
+	if d.IBCMessagesCache.PacketFlow.hasEventType(chantypes.EventTypeWriteAck) {
+		pathEnd.awaitAckProofs(d.LatestBlock.Height)
+	}
+
 	terminate, err := pathEnd.checkForMisbehaviour(ctx, pathEnd.clientState, counterParty)
 	if err != nil {
 		pathEnd.log.Error(
@@ -521,6 +779,77 @@ func (pathEnd *pathEndRuntime) mergeCacheData(
 
 	pathEnd.ibcHeaderCache.Merge(d.IBCHeaderCache)  // Update latest IBC header state
 	pathEnd.ibcHeaderCache.Prune(ibcHeadersToCache) // Only keep most recent IBC headers
+
+	if pathEnd.headers != nil {
+		pathEnd.headers.Update(d.IBCHeaderCache)
+	}
+}
+
+// header returns the IBC header of the chain at height, from the headers shared by all PathProcessors
+// relaying on the chain if available, so that it is only queried once.
+func (pathEnd *pathEndRuntime) header(ctx context.Context, height uint64) (provider.IBCHeader, error) {
+	if pathEnd.headers != nil {
+		return pathEnd.headers.Header(ctx, height)
+	}
+	return pathEnd.chainProvider.QueryIBCHeader(ctx, int64(height))
+}
+
+// checkTimeoutProofHeight returns an error if the packet has not timed out as of the proof height of its MsgTimeout,
+// by height or by the timestamp of the consensus state which the counterparty client will have for the proof height,
+// since core IBC would reject the MsgTimeout.
+func (pathEnd *pathEndRuntime) checkTimeoutProofHeight(info provider.PacketInfo, proofHeight clienttypes.Height) error {
+	block := provider.LatestBlock{Height: proofHeight.RevisionHeight}
+	if header, ok := pathEnd.ibcHeaderCache[proofHeight.RevisionHeight]; ok {
+		block.Time = time.Unix(0, int64(header.ConsensusState().GetTimestamp()))
+	} else if proofHeight.RevisionHeight == pathEnd.latestBlock.Height {
+		block.Time = pathEnd.latestBlock.Time
+	}
+
+	if err := info.TimeoutElapsed(proofHeight.RevisionNumber, block); err != nil {
+		return nil
+	}
+
+	if block.Time.IsZero() && info.TimeoutTimestamp > 0 {
+		// the consensus timestamp for the proof height is not known, leave it to core IBC.
+		return nil
+	}
+
+	return fmt.Errorf("packet timeout has not elapsed at proof height %d (timeout height %d, timeout timestamp %d)",
+		proofHeight.RevisionHeight, info.TimeoutHeight.RevisionHeight, info.TimeoutTimestamp)
+}
+
+// setChainProvider sets the provider of this chain.
+func (pathEnd *pathEndRuntime) setChainProvider(chainProvider provider.ChainProvider) {
+	pathEnd.chainProvider = chainProvider
+	pathEnd.txInclusionTimeout = chainProvider.ProviderConfig().TxInclusionTimeout()
+}
+
+// finishProcessing releases a message which finished processing to the path processor,
+// with the error of sending it if it failed.
+func (pathEnd *pathEndRuntime) finishProcessing(tracker messageToTrack, err error) {
+	pathEnd.finishedProcessing <- finishedMessage{tracker: tracker, failure: provider.ClassifyTxFailure(err)}
+}
+
+// sendRetryDue returns true if a message which was assembled and sent to this chain has been waited on
+// for inclusion in a block long enough to send it again. This is the block timeout of the chain if configured,
+// or blocksToRetrySendAfter blocks, unless sending it failed in a way which is corrected by sending it again.
+func (pathEnd *pathEndRuntime) sendRetryDue(inProgress *processingMessage) bool {
+	switch {
+	case inProgress.failure.RetryImmediately():
+		return true
+	case pathEnd.txInclusionTimeout.Blocks > 0:
+		return pathEnd.latestBlock.Height-inProgress.lastProcessedHeight >= pathEnd.txInclusionTimeout.Blocks
+	case pathEnd.txInclusionTimeout.Duration > 0:
+		return pathEnd.latestBlock.Time.Sub(inProgress.lastProcessedTime) >= pathEnd.txInclusionTimeout.Duration
+	default:
+		return pathEnd.latestBlock.Height-inProgress.lastProcessedHeight >= blocksToRetrySendAfter
+	}
+}
+
+// shouldGiveUp returns true if a message should no longer be sent, because it was retried the maximum number
+// of times, or sending it failed in a way which sending it again cannot recover from.
+func (pathEnd *pathEndRuntime) shouldGiveUp(inProgress *processingMessage) bool {
+	return inProgress.retryCount >= pathEnd.retryPolicy.MaxMsgRetries || !inProgress.failure.Retryable()
 }
 
 // shouldSendPacketMessage determines if the packet flow message should be sent now.
@@ -539,6 +868,16 @@ func (pathEnd *pathEndRuntime) shouldSendPacketMessage(message packetIBCMessage,
 		return false
 	}
 
+	if pathEnd.isQuarantined(eventType, k, sequence) {
+		// the message fails on its own, do not send it again until it is retried.
+		return false
+	}
+
+	if pathEnd.claimedByPeer(eventType, k, sequence) {
+		// a cooperating relayer intends to relay the message, leave it to that relayer until its claim expires.
+		return false
+	}
+
 	pathEndForHeight := counterparty
 	if eventType == chantypes.EventTypeTimeoutPacket {
 		pathEndForHeight = pathEnd
@@ -570,35 +909,37 @@ func (pathEnd *pathEndRuntime) shouldSendPacketMessage(message packetIBCMessage,
 	}
 	msgProcessCache, ok := pathEnd.packetProcessing[k]
 	if !ok {
-		// in progress cache does not exist for this channel, so can send.
-		return true
+		// in progress cache does not exist for this channel, so can send,
+		// unless it was relayed before a restart.
+		return !pathEnd.skipProcessedPacket(eventType, k, sequence)
 	}
 	channelProcessingCache, ok := msgProcessCache[eventType]
 	if !ok {
 		// in progress cache does not exist for this eventType, so can send
-		return true
+		return !pathEnd.skipProcessedPacket(eventType, k, sequence)
 	}
 	inProgress := channelProcessingCache.get(sequence)
 	if inProgress == nil {
 		// in progress cache does not exist for this sequence, so can send.
-		return true
+		return !pathEnd.skipProcessedPacket(eventType, k, sequence)
 	}
 	if inProgress.isProcessing() {
 		// this message is currently being processed (broadcasting), do not attempt to send again yet.
 		return false
 	}
-	blocksSinceLastProcessed := pathEnd.latestBlock.Height - inProgress.lastProcessedHeight
-	if inProgress.assembled && blocksSinceLastProcessed < blocksToRetrySendAfter {
-		// this message was sent less than blocksToRetrySendAfter ago, do not attempt to send again yet.
+	if inProgress.assembled && !pathEnd.sendRetryDue(inProgress) {
+		// this message was sent recently and may still be included in a block, do not attempt to send again yet.
 		return false
 	}
 
-	if inProgress.retryCount >= maxMessageSendRetries {
-		pathEnd.log.Error("Giving up on sending packet message after max retries",
+	if pathEnd.shouldGiveUp(inProgress) {
+		pathEnd.log.Error("Giving up on sending packet message",
 			zap.String("event_type", eventType),
 			zap.Uint64("sequence", sequence),
 			zap.Inline(k),
-			zap.Int("max_retries", maxMessageSendRetries),
+			zap.Uint64("retries", inProgress.retryCount),
+			zap.Uint64("max_retries", pathEnd.retryPolicy.MaxMsgRetries),
+			zap.Stringer("failure", inProgress.failure),
 		)
 		pathEnd.removePacketRetention(counterparty, eventType, k, sequence)
 		return false
@@ -672,16 +1013,27 @@ func (pathEnd *pathEndRuntime) shouldSendConnectionMessage(message connectionIBC
 	}
 	if inProgress.isProcessing() {
 		// this message is currently being processed (broadcasting), do not attempt to send again yet.
+		pathEnd.log.Debug("Waiting to relay connection message until it is broadcast",
+			zap.Inline(k),
+			zap.String("event_type", eventType),
+		)
 		return false
 	}
-	blocksSinceLastProcessed := pathEnd.latestBlock.Height - inProgress.lastProcessedHeight
-	if inProgress.assembled && blocksSinceLastProcessed < blocksToRetrySendAfter {
-		// this message was sent less than blocksToRetrySendAfter ago, do not attempt to send again yet.
+	if inProgress.assembled && !pathEnd.sendRetryDue(inProgress) {
+		// this message was sent recently and may still be included in a block, do not attempt to send again yet.
+		pathEnd.log.Debug("Waiting for connection message to be included in a block",
+			zap.Inline(k),
+			zap.String("event_type", eventType),
+			zap.Uint64("retries", inProgress.retryCount),
+		)
 		return false
 	}
-	if inProgress.retryCount >= maxMessageSendRetries {
-		pathEnd.log.Error("Giving up on sending connection message after max retries",
+	if pathEnd.shouldGiveUp(inProgress) {
+		pathEnd.log.Error("Giving up on sending connection message",
 			zap.String("event_type", eventType),
+			zap.Uint64("retries", inProgress.retryCount),
+			zap.Uint64("max_retries", pathEnd.retryPolicy.MaxMsgRetries),
+			zap.Stringer("failure", inProgress.failure),
 		)
 		// giving up on sending this connection handshake message
 		// remove all retention of this connection handshake in pathEnd.messagesCache.ConnectionHandshake and counterparty
@@ -751,17 +1103,27 @@ func (pathEnd *pathEndRuntime) shouldSendChannelMessage(message channelIBCMessag
 	}
 	if inProgress.isProcessing() {
 		// this message is currently being processed (broadcasting), do not attempt to send again yet.
+		pathEnd.log.Debug("Waiting to relay channel message until it is broadcast",
+			zap.Inline(channelKey),
+			zap.String("event_type", eventType),
+		)
 		return false
 	}
-	blocksSinceLastProcessed := pathEnd.latestBlock.Height - inProgress.lastProcessedHeight
-	if inProgress.assembled && blocksSinceLastProcessed < blocksToRetrySendAfter {
-		// this message was sent less than blocksToRetrySendAfter ago, do not attempt to send again yet.
+	if inProgress.assembled && !pathEnd.sendRetryDue(inProgress) {
+		// this message was sent recently and may still be included in a block, do not attempt to send again yet.
+		pathEnd.log.Debug("Waiting for channel message to be included in a block",
+			zap.Inline(channelKey),
+			zap.String("event_type", eventType),
+			zap.Uint64("retries", inProgress.retryCount),
+		)
 		return false
 	}
-	if inProgress.retryCount >= maxMessageSendRetries {
-		pathEnd.log.Error("Giving up on sending channel message after max retries",
+	if pathEnd.shouldGiveUp(inProgress) {
+		pathEnd.log.Error("Giving up on sending channel message",
 			zap.String("event_type", eventType),
-			zap.Int("max_retries", maxMessageSendRetries),
+			zap.Uint64("retries", inProgress.retryCount),
+			zap.Uint64("max_retries", pathEnd.retryPolicy.MaxMsgRetries),
+			zap.Stringer("failure", inProgress.failure),
 		)
 		// giving up on sending this channel handshake message
 		// remove all retention of this connection handshake in pathEnd.messagesCache.ConnectionHandshake and counterparty
@@ -786,6 +1148,14 @@ func (pathEnd *pathEndRuntime) shouldSendChannelMessage(message channelIBCMessag
 			toDelete[chantypes.EventTypeChannelOpenTry] = []ChannelKey{channelKey}
 			toDeleteCounterparty[chantypes.EventTypeChannelOpenInit] = []ChannelKey{counterpartyKey.MsgInitKey()}
 			toDeleteCounterparty[preInitKey] = []ChannelKey{counterpartyKey.MsgInitKey()}
+		case chantypes.EventTypeChannelUpgradeTry, chantypes.EventTypeChannelUpgradeAck,
+			chantypes.EventTypeChannelUpgradeConfirm, chantypes.EventTypeChannelUpgradeOpen,
+			chantypes.EventTypeChannelUpgradeTimeout, chantypes.EventTypeChannelUpgradeCancel:
+			// giving up on the channel upgrade, remove retention of all of its events on both ends
+			for _, upgradeEventType := range channelUpgradeEventTypes {
+				toDelete[upgradeEventType] = []ChannelKey{channelKey}
+				toDeleteCounterparty[upgradeEventType] = []ChannelKey{counterpartyKey}
+			}
 		case chantypes.EventTypeChannelCloseConfirm:
 			toDelete[chantypes.EventTypeChannelCloseConfirm] = []ChannelKey{channelKey}
 			toDeleteCounterparty[chantypes.EventTypeChannelCloseInit] = []ChannelKey{counterpartyKey}
@@ -844,14 +1214,16 @@ func (pathEnd *pathEndRuntime) shouldSendClientICQMessage(message provider.Clien
 		// this message is currently being processed (broadcasting), do not attempt to send again yet.
 		return false
 	}
-	blocksSinceLastProcessed := pathEnd.latestBlock.Height - inProgress.lastProcessedHeight
-	if inProgress.assembled && blocksSinceLastProcessed < blocksToRetrySendAfter {
-		// this message was sent less than blocksToRetrySendAfter ago, do not attempt to send again yet.
+	if inProgress.assembled && !pathEnd.sendRetryDue(inProgress) {
+		// this message was sent recently and may still be included in a block, do not attempt to send again yet.
 		return false
 	}
-	if inProgress.retryCount >= maxMessageSendRetries {
-		pathEnd.log.Error("Giving up on sending client ICQ message after max retries",
+	if pathEnd.shouldGiveUp(inProgress) {
+		pathEnd.log.Error("Giving up on sending client ICQ message",
 			zap.String("query_id", string(queryID)),
+			zap.Uint64("retries", inProgress.retryCount),
+			zap.Uint64("max_retries", pathEnd.retryPolicy.MaxMsgRetries),
+			zap.Stringer("failure", inProgress.failure),
 		)
 
 		// giving up on this query
@@ -950,7 +1322,12 @@ func (pathEnd *pathEndRuntime) trackProcessingMessage(tracker messageToTrack) ui
 	return retryCount
 }
 
-func (pathEnd *pathEndRuntime) trackFinishedProcessingMessage(tracker messageToTrack) {
+// trackFinishedProcessingMessage records that a message finished processing, and how sending it failed if it did.
+func (pathEnd *pathEndRuntime) trackFinishedProcessingMessage(tracker messageToTrack, failure provider.TxFailure) {
+	if failure == provider.TxFailureConsensusStateNotFound {
+		pathEnd.recoverMissingConsensusState()
+	}
+
 	switch t := tracker.(type) {
 	case packetMessageToTrack:
 		eventType := t.msg.eventType
@@ -970,7 +1347,7 @@ func (pathEnd *pathEndRuntime) trackFinishedProcessingMessage(tracker messageToT
 
 		inProgress := channelProcessingCache.get(sequence)
 		if inProgress != nil {
-			inProgress.setFinishedProcessing(pathEnd.latestBlock.Height)
+			inProgress.setFinishedProcessing(pathEnd.latestBlock, failure)
 		}
 	case channelMessageToTrack:
 		eventType := t.msg.eventType
@@ -985,7 +1362,7 @@ func (pathEnd *pathEndRuntime) trackFinishedProcessingMessage(tracker messageToT
 
 		inProgress := msgProcessCache.get(channelKey)
 		if inProgress != nil {
-			inProgress.setFinishedProcessing(pathEnd.latestBlock.Height)
+			inProgress.setFinishedProcessing(pathEnd.latestBlock, failure)
 		}
 	case connectionMessageToTrack:
 		eventType := t.msg.eventType
@@ -1000,14 +1377,14 @@ func (pathEnd *pathEndRuntime) trackFinishedProcessingMessage(tracker messageToT
 
 		inProgress := msgProcessCache.get(connectionKey)
 		if inProgress != nil {
-			inProgress.setFinishedProcessing(pathEnd.latestBlock.Height)
+			inProgress.setFinishedProcessing(pathEnd.latestBlock, failure)
 		}
 	case clientICQMessageToTrack:
 		queryID := t.msg.info.QueryID
 
 		inProgress := pathEnd.clientICQProcessing.get(queryID)
 		if inProgress != nil {
-			inProgress.setFinishedProcessing(pathEnd.latestBlock.Height)
+			inProgress.setFinishedProcessing(pathEnd.latestBlock, failure)
 		}
 	}
 }
@@ -3,7 +3,12 @@ package processor
 import (
 	"testing"
 
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 const (
@@ -305,3 +310,111 @@ func TestDenyChannelWithSpecificPort(t *testing.T) {
 	require.True(t, mockPathEndRuntime.ShouldRelayChannel(mocCounterpartykChannelWithAllowedPort), "does not allow port to be relayed on, even though portID is not in deny list")
 
 }
+
+func TestSendRetryByFailure(t *testing.T) {
+	pathEnd := pathEndRuntime{
+		latestBlock: provider.LatestBlock{Height: 101},
+		retryPolicy: DefaultMsgRetryPolicy(),
+	}
+
+	inProgress := &processingMessage{assembled: true, retryCount: 1}
+	inProgress.setFinishedProcessing(provider.LatestBlock{Height: 100}, provider.TxFailureTimeout)
+	require.False(t, pathEnd.sendRetryDue(inProgress))
+	require.False(t, pathEnd.shouldGiveUp(inProgress))
+
+	// the sequence is corrected when signing the tx again, so there is no need to wait for the failed one.
+	inProgress.setFinishedProcessing(provider.LatestBlock{Height: 100}, provider.TxFailureSequenceMismatch)
+	require.True(t, pathEnd.sendRetryDue(inProgress))
+	require.False(t, pathEnd.shouldGiveUp(inProgress))
+
+	inProgress.setFinishedProcessing(provider.LatestBlock{Height: 100}, provider.TxFailureClientExpired)
+	require.True(t, pathEnd.shouldGiveUp(inProgress))
+}
+
+type mockQueryWaker struct {
+	heights []uint64
+}
+
+func (w *mockQueryWaker) QueryUntil(height uint64) {
+	w.heights = append(w.heights, height)
+}
+
+func TestAwaitAckProofs(t *testing.T) {
+	waker := new(mockQueryWaker)
+	pathEnd := pathEndRuntime{queryWaker: waker}
+
+	pathEnd.awaitAckProofs(100)
+	require.Empty(t, waker.heights, "queried fast although same-block acks are disabled")
+
+	pathEnd.sameBlockAcks = true
+	pathEnd.awaitAckProofs(100)
+	require.Equal(t, []uint64{101}, waker.heights)
+
+	cache := make(ChannelPacketMessagesCache)
+	k := ChannelKey{ChannelID: testChannel0, PortID: testPort}
+	cache.Cache(chantypes.EventTypeRecvPacket, k, 1, provider.PacketInfo{Sequence: 1})
+	require.False(t, cache.hasEventType(chantypes.EventTypeWriteAck))
+	cache.Cache(chantypes.EventTypeWriteAck, k, 1, provider.PacketInfo{Sequence: 1})
+	require.True(t, cache.hasEventType(chantypes.EventTypeWriteAck))
+}
+
+func TestFailedAckCountedOnce(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: testChainID1}, metrics)
+
+	ack := provider.PacketInfo{
+		Height:        100,
+		Sequence:      1,
+		SourceChannel: testChannel0,
+		SourcePort:    testPort,
+		DestChannel:   testChannel1,
+		DestPort:      testPort,
+		Ack:           []byte(`{"error":"ABCI code: 1: error handling packet"}`),
+	}
+	pathEnd.channelStateCache.SetOpen(packetInfoChannelKey(ack), true, chantypes.UNORDERED)
+
+	failedAcks := func() float64 {
+		return testutil.ToFloat64(metrics.FailedAcks.WithLabelValues("demo-path", testChainID1, testChannel1, testPort))
+	}
+
+	merge := func(p provider.PacketInfo) {
+		cache := NewIBCMessagesCache()
+		cache.PacketFlow.Cache(chantypes.EventTypeWriteAck, packetInfoChannelKey(p), p.Sequence, p)
+		pathEnd.mergeMessageCache(cache, testChainID0, true, 0, 0)
+	}
+
+	merge(ack)
+	require.Equal(t, float64(1), failedAcks())
+
+	// the ack is merged again until it is relayed to the counterparty.
+	merge(ack)
+	require.Equal(t, float64(1), failedAcks())
+
+	next := ack
+	next.Sequence = 2
+	merge(next)
+	require.Equal(t, float64(2), failedAcks())
+
+	pathEnd.pruneFailedAcks(ack.Height + failedAckRetentionBlocks + 1)
+	require.Empty(t, pathEnd.failedAcks)
+}
+
+func TestRecoverMissingConsensusState(t *testing.T) {
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: testChainID1}, nil)
+	pathEnd.clientTrustedState = provider.ClientTrustedState{
+		ClientState: provider.ClientState{ConsensusHeight: clienttypes.NewHeight(0, 100)},
+	}
+
+	msg := packetMessageToTrack{msg: packetIBCMessage{
+		eventType: chantypes.EventTypeRecvPacket,
+		info:      provider.PacketInfo{Sequence: 1, SourceChannel: testChannel0, SourcePort: testPort},
+	}}
+
+	pathEnd.trackFinishedProcessingMessage(msg, provider.TxFailureUnknown)
+	require.False(t, pathEnd.forceClientUpdate)
+	require.False(t, pathEnd.clientTrustedState.ClientState.ConsensusHeight.IsZero())
+
+	pathEnd.trackFinishedProcessingMessage(msg, provider.TxFailureConsensusStateNotFound)
+	require.True(t, pathEnd.forceClientUpdate, "client is not updated")
+	require.True(t, pathEnd.clientTrustedState.ClientState.ConsensusHeight.IsZero(), "trusted state is not assembled again")
+}
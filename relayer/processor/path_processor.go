@@ -7,18 +7,22 @@ import (
 
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/accounting"
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 )
 
 const (
 	// durationErrorRetry determines how long to wait before retrying
-	// in the case of failure to send transactions with IBC messages.
+	// in the case of failure to send transactions with IBC messages,
+	// unless set by the MsgRetryPolicy.
 	durationErrorRetry = 5 * time.Second
 
 	// Amount of time to wait when sending transactions before giving up
 	// and continuing on. Messages will be retried later if they are still
-	// relevant.
+	// relevant. Unless set by the MsgRetryPolicy.
 	messageSendTimeout = 60 * time.Second
 
 	// Amount of time to wait for a proof to be queried before giving up.
@@ -32,11 +36,20 @@ const (
 	// Amount of time between flushes if the previous flush failed.
 	flushFailureRetry = 5 * time.Second
 
+	// With AdaptiveFlushInterval, how many blocks of the slower chain of the path pass between flushes,
+	// bounded by the min and max adaptive flush intervals. Until the block times of the chains are measured,
+	// the default adaptive flush interval is used.
+	adaptiveFlushBlocks          = 50
+	minAdaptiveFlushInterval     = 1 * time.Minute
+	maxAdaptiveFlushInterval     = 15 * time.Minute
+	defaultAdaptiveFlushInterval = 5 * time.Minute
+
 	// If the message was assembled successfully, but sending the message failed,
-	// how many blocks should pass before retrying.
+	// how many blocks should pass before retrying, unless the chain configures a block timeout.
 	blocksToRetrySendAfter = 5
 
-	// How many times to retry sending a message before giving up on it.
+	// How many times to retry sending a message before giving up on it,
+	// unless set by the MsgRetryPolicy.
 	maxMessageSendRetries = 5
 
 	// How many times to retry sending a message if channel is not opened.
@@ -51,6 +64,31 @@ const (
 	clientConsensusHeightUpdateThresholdBlocks = 2
 )
 
+// AdaptiveFlushInterval is a flush interval which makes a PathProcessor flush every adaptiveFlushBlocks blocks
+// of the slower chain of its path, as measured from the blocks observed on both chains.
+const AdaptiveFlushInterval time.Duration = -1
+
+// MsgRetryPolicy determines how a PathProcessor retries sending messages.
+type MsgRetryPolicy struct {
+	// MaxMsgRetries is how many times to retry sending a message before giving up on it.
+	MaxMsgRetries uint64
+
+	// MsgSendTimeout is how long to wait when sending a transaction before giving up and continuing on.
+	MsgSendTimeout time.Duration
+
+	// Backoff is how long to wait before processing the backlog again after sending messages failed.
+	Backoff time.Duration
+}
+
+// DefaultMsgRetryPolicy returns the MsgRetryPolicy used by a PathProcessor unless another is set.
+func DefaultMsgRetryPolicy() MsgRetryPolicy {
+	return MsgRetryPolicy{
+		MaxMsgRetries:  maxMessageSendRetries,
+		MsgSendTimeout: messageSendTimeout,
+		Backoff:        durationErrorRetry,
+	}
+}
+
 // PathProcessor is a process that handles incoming IBC messages from a pair of chains.
 // It determines what messages need to be relayed, and sends them.
 type PathProcessor struct {
@@ -63,11 +101,19 @@ type PathProcessor struct {
 
 	clientUpdateThresholdTime time.Duration
 
+	retryPolicy MsgRetryPolicy
+
+	concurrency Concurrency
+
+	// limits how many messages are assembled at once, shared by both directions of the path.
+	workers semaphore
+
 	messageLifecycle MessageLifecycle
 
 	initialFlushComplete bool
 	flushTimer           *time.Timer
 	flushInterval        time.Duration
+	adaptiveFlush        bool
 
 	// Signals to retry.
 	retryProcess chan struct{}
@@ -81,6 +127,25 @@ type PathProcessor struct {
 	memoLimit, maxReceiverSize int
 
 	metrics *PrometheusMetrics
+
+	// if true, messages are determined but never assembled or sent.
+	monitorOnly bool
+
+	// if true, packet messages already relayed by another relayer are not sent.
+	skipRelayedPackets bool
+
+	// if true, clients which fell too far behind to be updated in one update are caught up through
+	// intermediate heights.
+	forceBisection bool
+
+	// requests from the control API, which are run in the goroutine which runs the PathProcessor.
+	controlRequests chan func(ctx context.Context)
+
+	// most recent results of the transactions broadcast by the PathProcessor.
+	txResults *txResultHistory
+
+	// SLA statistics of the path.
+	sla *slaTracker
 }
 
 // PathProcessors is a slice of PathProcessor instances
@@ -113,8 +178,12 @@ func NewPathProcessor(
 		pathEnd1:                  newPathEndRuntime(log, pathEnd1, metrics),
 		pathEnd2:                  newPathEndRuntime(log, pathEnd2, metrics),
 		retryProcess:              make(chan struct{}, 2),
+		controlRequests:           make(chan func(ctx context.Context)),
+		txResults:                 new(txResultHistory),
+		sla:                       newSLATracker(),
 		memo:                      memo,
 		clientUpdateThresholdTime: clientUpdateThresholdTime,
+		retryPolicy:               DefaultMsgRetryPolicy(),
 		flushInterval:             flushInterval,
 		metrics:                   metrics,
 		isLocalhost:               isLocalhost,
@@ -122,8 +191,14 @@ func NewPathProcessor(
 		memoLimit:                 memoLimit,
 		maxReceiverSize:           maxReceiverSize,
 	}
-	if flushInterval == 0 {
+	pp.pathEnd1.sla = pp.sla
+	pp.pathEnd2.sla = pp.sla
+	switch flushInterval {
+	case 0:
 		pp.disablePeriodicFlush()
+	case AdaptiveFlushInterval:
+		pp.adaptiveFlush = true
+		pp.flushInterval = defaultAdaptiveFlushInterval
 	}
 	return pp
 }
@@ -131,6 +206,18 @@ func NewPathProcessor(
 // disablePeriodicFlush will "disable" periodic flushing by using a large value.
 func (pp *PathProcessor) disablePeriodicFlush() {
 	pp.flushInterval = 200 * 24 * 365 * time.Hour
+	pp.adaptiveFlush = false
+}
+
+// nextFlushInterval returns how long to wait until the next periodic flush.
+func (pp *PathProcessor) nextFlushInterval() time.Duration {
+	if !pp.adaptiveFlush {
+		return pp.flushInterval
+	}
+	return max(
+		pp.pathEnd1.blockInterval.Blocks(adaptiveFlushBlocks, minAdaptiveFlushInterval, maxAdaptiveFlushInterval, pp.flushInterval),
+		pp.pathEnd2.blockInterval.Blocks(adaptiveFlushBlocks, minAdaptiveFlushInterval, maxAdaptiveFlushInterval, pp.flushInterval),
+	)
 }
 
 func (pp *PathProcessor) SetMessageLifecycle(messageLifecycle MessageLifecycle) {
@@ -141,6 +228,104 @@ func (pp *PathProcessor) SetMessageLifecycle(messageLifecycle MessageLifecycle)
 	}
 }
 
+// SetProofHeightAudit enables or disables logging and validation of the queried height,
+// proof height, and client consensus height used for every message assembled with a proof.
+func (pp *PathProcessor) SetProofHeightAudit(enabled bool) {
+	pp.pathEnd1.auditProofHeights = enabled
+	pp.pathEnd2.auditProofHeights = enabled
+}
+
+// SetMsgRetryPolicy sets how this PathProcessor retries sending messages.
+func (pp *PathProcessor) SetMsgRetryPolicy(retryPolicy MsgRetryPolicy) {
+	pp.retryPolicy = retryPolicy
+	pp.pathEnd1.retryPolicy = retryPolicy
+	pp.pathEnd2.retryPolicy = retryPolicy
+}
+
+// SetConcurrency sets how much work this PathProcessor does at once.
+func (pp *PathProcessor) SetConcurrency(concurrency Concurrency) {
+	pp.concurrency = concurrency
+	pp.workers = newSemaphore(concurrency.Workers)
+	pp.pathEnd1.txSlots = newSemaphore(concurrency.MaxInFlightTxs)
+	pp.pathEnd2.txSlots = newSemaphore(concurrency.MaxInFlightTxs)
+	pp.pathEnd1.txWeight = concurrency.Weight
+	pp.pathEnd2.txWeight = concurrency.Weight
+}
+
+// SetMonitorOnly enables or disables monitor-only mode. In monitor-only mode, the PathProcessor
+// observes both chains and tracks backlogs and client expiration, but does not sign or send any messages.
+func (pp *PathProcessor) SetMonitorOnly(enabled bool) {
+	pp.monitorOnly = enabled
+}
+
+// SetSkipRelayedPackets enables or disables checking the packet state on the destination chain
+// immediately before broadcast, skipping packet messages which have already been relayed,
+// e.g. by a redundant relayer instance serving the same path.
+func (pp *PathProcessor) SetSkipRelayedPackets(enabled bool) {
+	pp.skipRelayedPackets = enabled
+}
+
+// SetForceBisection enables or disables catching up clients which fell so far behind that the validator set
+// rotated too much to update them to the latest height in one update, through intermediate heights.
+func (pp *PathProcessor) SetForceBisection(enabled bool) {
+	pp.forceBisection = enabled
+}
+
+// SetSameBlockAcks enables or disables relaying the acknowledgements of received packets as soon as they can be
+// proven, with the header of the block after the one they were written in, at the cost of querying the chain
+// as fast as possible until that block is produced.
+func (pp *PathProcessor) SetSameBlockAcks(enabled bool) {
+	pp.pathEnd1.sameBlockAcks = enabled
+	pp.pathEnd2.sameBlockAcks = enabled
+}
+
+// SetProofVerification enables or disables verifying the proofs of messages against the headers of the chains they
+// are queried from before the messages are sent, so that invalid proofs fail locally rather than on chain.
+func (pp *PathProcessor) SetProofVerification(enabled bool) {
+	pp.pathEnd1.verifyProofs = enabled
+	pp.pathEnd2.verifyProofs = enabled
+}
+
+// SetProcessedEvents sets where this PathProcessor remembers the packet messages it relayed, so that they are not
+// relayed again after a restart. Nil disables remembering them.
+func (pp *PathProcessor) SetProcessedEvents(processedEvents *ProcessedEvents) {
+	pp.pathEnd1.processedEvents = processedEvents
+	pp.pathEnd2.processedEvents = processedEvents
+}
+
+// SetCoordinator sets the coordinator with which this PathProcessor claims the packet messages it relays, and skips
+// those claimed by cooperating relayers. Nil disables coordination.
+func (pp *PathProcessor) SetCoordinator(coordinator Coordinator) {
+	pp.pathEnd1.coordinator = coordinator
+	pp.pathEnd2.coordinator = coordinator
+}
+
+// SetNotifier sets the notifier of the conditions of this PathProcessor which need the attention of the operator,
+// such as an expiring client or a stalled path. Nil disables notifications.
+func (pp *PathProcessor) SetNotifier(notifier *notify.Notifier) {
+	pp.pathEnd1.notifier = notifier
+	pp.pathEnd2.notifier = notifier
+}
+
+// SetRelayEvents sets the stream to which this PathProcessor publishes the lifecycle of the packets it observes
+// and the messages it relays. Nil disables the stream.
+func (pp *PathProcessor) SetRelayEvents(relayEvents *eventstream.Stream) {
+	pp.pathEnd1.relayEvents = relayEvents
+	pp.pathEnd2.relayEvents = relayEvents
+}
+
+// SetDenomPolicy sets which ICS-20 transfers this PathProcessor relays, by the denom and amount of their tokens.
+func (pp *PathProcessor) SetDenomPolicy(denomPolicy DenomPolicy) {
+	pp.pathEnd1.denomPolicy = denomPolicy
+	pp.pathEnd2.denomPolicy = denomPolicy
+}
+
+// SetMemoFilter sets which ICS-20 transfers this PathProcessor refuses to relay, by the content of their memo.
+func (pp *PathProcessor) SetMemoFilter(memoFilter MemoFilter) {
+	pp.pathEnd1.memoFilter = memoFilter
+	pp.pathEnd2.memoFilter = memoFilter
+}
+
 func (pp *PathProcessor) shouldFlush() bool {
 	if pp.messageLifecycle == nil {
 		return true
@@ -186,6 +371,15 @@ func (pp *PathProcessor) OnConnectionMessage(chainID string, eventType string, o
 	}
 }
 
+// OnChannelMessage allows the caller to handle channel handshake messages with a callback.
+func (pp *PathProcessor) OnChannelMessage(chainID string, eventType string, onMsg func(provider.ChannelInfo)) {
+	if pp.pathEnd1.info.ChainID == chainID {
+		pp.pathEnd1.chanSubscribers[eventType] = append(pp.pathEnd1.chanSubscribers[eventType], onMsg)
+	} else if pp.pathEnd2.info.ChainID == chainID {
+		pp.pathEnd2.chanSubscribers[eventType] = append(pp.pathEnd2.chanSubscribers[eventType], onMsg)
+	}
+}
+
 func (pp *PathProcessor) channelPairs() []channelPair {
 	// Channel keys are from pathEnd1's perspective
 	channels := make(map[ChannelKey]ChannelState)
@@ -214,18 +408,18 @@ func (pp *PathProcessor) SetChainProviderIfApplicable(chainProvider provider.Cha
 		return false
 	}
 	if pp.pathEnd1.info.ChainID == chainProvider.ChainId() {
-		pp.pathEnd1.chainProvider = chainProvider
+		pp.pathEnd1.setChainProvider(chainProvider)
 
 		if pp.isLocalhost {
-			pp.pathEnd2.chainProvider = chainProvider
+			pp.pathEnd2.setChainProvider(chainProvider)
 		}
 
 		return true
 	} else if pp.pathEnd2.info.ChainID == chainProvider.ChainId() {
-		pp.pathEnd2.chainProvider = chainProvider
+		pp.pathEnd2.setChainProvider(chainProvider)
 
 		if pp.isLocalhost {
-			pp.pathEnd1.chainProvider = chainProvider
+			pp.pathEnd1.setChainProvider(chainProvider)
 		}
 
 		return true
@@ -233,6 +427,52 @@ func (pp *PathProcessor) SetChainProviderIfApplicable(chainProvider provider.Cha
 	return false
 }
 
+// SetHeaderSynchronizerIfApplicable links the headers shared by all PathProcessors relaying on chainID
+// to the path end(s) on that chain.
+func (pp *PathProcessor) SetHeaderSynchronizerIfApplicable(chainID string, headers *HeaderSynchronizer) {
+	if pp.pathEnd1.info.ChainID == chainID {
+		pp.pathEnd1.headers = headers
+
+		if pp.isLocalhost {
+			pp.pathEnd2.headers = headers
+		}
+	} else if pp.pathEnd2.info.ChainID == chainID {
+		pp.pathEnd2.headers = headers
+
+		if pp.isLocalhost {
+			pp.pathEnd1.headers = headers
+		}
+	}
+}
+
+// SetTxSchedulerIfApplicable links the tx scheduler shared by all PathProcessors relaying on chainID
+// to the path end(s) on that chain.
+func (pp *PathProcessor) SetTxSchedulerIfApplicable(chainID string, txScheduler *TxScheduler) {
+	if pp.pathEnd1.info.ChainID == chainID {
+		pp.pathEnd1.txScheduler = txScheduler
+
+		if pp.isLocalhost {
+			pp.pathEnd2.txScheduler = txScheduler
+		}
+	} else if pp.pathEnd2.info.ChainID == chainID {
+		pp.pathEnd2.txScheduler = txScheduler
+
+		if pp.isLocalhost {
+			pp.pathEnd1.txScheduler = txScheduler
+		}
+	}
+}
+
+// SetQueryWakerIfApplicable links the ChainProcessor of chainID, which can be made to query new blocks
+// as soon as they are produced, to the path end(s) on that chain.
+func (pp *PathProcessor) SetQueryWakerIfApplicable(chainID string, waker QueryWaker) {
+	for _, pathEnd := range []*pathEndRuntime{pp.pathEnd1, pp.pathEnd2} {
+		if pathEnd.info.ChainID == chainID {
+			pathEnd.queryWaker = waker
+		}
+	}
+}
+
 func (pp *PathProcessor) IsRelayedChannel(chainID string, channelKey ChannelKey) bool {
 	if pp.pathEnd1.info.ChainID == chainID {
 		return pp.pathEnd1.ShouldRelayChannel(ChainChannelKey{ChainID: chainID, CounterpartyChainID: pp.pathEnd2.info.ChainID, ChannelKey: channelKey})
@@ -282,6 +522,16 @@ func (pp *PathProcessor) ProcessBacklogIfReady() {
 	}
 }
 
+// SetChainHalted is called by ChainProcessors when the chain with the given ID stops producing blocks,
+// to pause sending messages to it, and when it produces blocks again, to resume.
+func (pp *PathProcessor) SetChainHalted(chainID string, halted bool) {
+	for _, pathEnd := range []*pathEndRuntime{pp.pathEnd1, pp.pathEnd2} {
+		if pathEnd.info.ChainID == chainID {
+			pathEnd.halted.Store(halted)
+		}
+	}
+}
+
 // ChainProcessors call this method when they have new IBC messages
 func (pp *PathProcessor) HandleNewData(chainID string, cacheData ChainProcessorCacheData) {
 	if pp.isLocalhost {
@@ -297,7 +547,7 @@ func (pp *PathProcessor) HandleNewData(chainID string, cacheData ChainProcessorC
 }
 
 func (pp *PathProcessor) handleFlush(ctx context.Context) {
-	flushTimer := pp.flushInterval
+	flushTimer := pp.nextFlushInterval()
 	if err := pp.flush(ctx); err != nil {
 		pp.log.Warn("Flush not complete",
 			zap.String("chain_id_1", pp.pathEnd1.chainProvider.ChainId()),
@@ -324,10 +574,12 @@ func (pp *PathProcessor) processAvailableSignals(ctx context.Context, cancel fun
 			zap.Error(ctx.Err()),
 		)
 		return true
-	case t := <-pp.pathEnd1.finishedProcessing:
-		pp.pathEnd1.trackFinishedProcessingMessage(t)
-	case t := <-pp.pathEnd2.finishedProcessing:
-		pp.pathEnd2.trackFinishedProcessingMessage(t)
+	case m := <-pp.pathEnd1.finishedProcessing:
+		pp.pathEnd1.trackFinishedProcessingMessage(m.tracker, m.failure)
+		pp.pathEnd1.trackProcessedMessage(ctx, m.tracker, m.failure)
+	case m := <-pp.pathEnd2.finishedProcessing:
+		pp.pathEnd2.trackFinishedProcessingMessage(m.tracker, m.failure)
+		pp.pathEnd2.trackProcessedMessage(ctx, m.tracker, m.failure)
 	case d := <-pp.pathEnd1.incomingCacheData:
 		// we have new data from ChainProcessor for pathEnd1
 		pp.pathEnd1.mergeCacheData(
@@ -358,6 +610,8 @@ func (pp *PathProcessor) processAvailableSignals(ctx context.Context, cancel fun
 
 	case <-pp.retryProcess:
 		// No new data to merge in, just retry handling.
+	case request := <-pp.controlRequests:
+		request(ctx)
 	case <-pp.flushTimer.C:
 		for len(pp.pathEnd1.incomingCacheData) > 0 {
 			d := <-pp.pathEnd1.incomingCacheData
@@ -397,10 +651,16 @@ func (pp *PathProcessor) processAvailableSignals(ctx context.Context, cancel fun
 
 // Run executes the main path process.
 func (pp *PathProcessor) Run(ctx context.Context, cancel func()) {
+	// the txs broadcast by the path are attributed to it for fee accounting.
+	ctx = accounting.WithPathName(ctx, pp.pathEnd1.info.PathName)
+
 	var retryTimer *time.Timer
 
 	pp.flushTimer = time.NewTimer(time.Hour)
 
+	pp.pathEnd1.loadProcessedPackets(ctx)
+	pp.pathEnd2.loadProcessedPackets(ctx)
+
 	for {
 		// block until we have any signals to process
 		if pp.processAvailableSignals(ctx, cancel) {
@@ -428,12 +688,12 @@ func (pp *PathProcessor) Run(ctx context.Context, cancel func()) {
 
 		// process latest message cache state from both pathEnds
 		if err := pp.processLatestMessages(ctx, cancel); err != nil {
-			// in case of IBC message send errors, schedule retry after durationErrorRetry
+			// in case of IBC message send errors, schedule retry after the backoff of the retry policy
 			if retryTimer != nil {
 				retryTimer.Stop()
 			}
 			if ctx.Err() == nil {
-				retryTimer = time.AfterFunc(durationErrorRetry, pp.ProcessBacklogIfReady)
+				retryTimer = time.AfterFunc(pp.retryPolicy.Backoff, pp.ProcessBacklogIfReady)
 			}
 		}
 	}
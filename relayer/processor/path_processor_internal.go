@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"github.com/cosmos/relayer/v2/relayer/provider"
@@ -23,6 +25,19 @@ const (
 	preCloseKey = "pre_close"
 )
 
+// channelUpgradeEventTypes are all of the events retained for a channel upgrade.
+var channelUpgradeEventTypes = []string{
+	chantypes.EventTypeChannelUpgradeInit,
+	chantypes.EventTypeChannelUpgradeTry,
+	chantypes.EventTypeChannelUpgradeAck,
+	chantypes.EventTypeChannelUpgradeConfirm,
+	chantypes.EventTypeChannelFlushComplete,
+	chantypes.EventTypeChannelUpgradeOpen,
+	chantypes.EventTypeChannelUpgradeTimeout,
+	chantypes.EventTypeChannelUpgradeCancel,
+	chantypes.EventTypeChannelUpgradeError,
+}
+
 // getMessagesToSend returns only the lowest sequence message (if it should be sent) for ordered channels,
 // otherwise returns all which should be sent.
 func (pp *PathProcessor) getMessagesToSend(
@@ -297,6 +312,10 @@ func (pp *PathProcessor) unrelayedPacketFlowMessages(
 			}
 			continue
 		}
+		if err := pathEndPacketFlowMessages.Src.checkRecvPacket(info.Data, pp.memoLimit, pp.maxReceiverSize); err != nil {
+			// the packet is left to time out, see mergeMessageCache.
+			continue
+		}
 		recvPacketMsg := packetIBCMessage{
 			eventType: chantypes.EventTypeRecvPacket,
 			info:      info,
@@ -656,6 +675,292 @@ func (pp *PathProcessor) unrelayedChannelCloseMessages(
 	return res
 }
 
+func (pp *PathProcessor) unrelayedChannelUpgradeMessages(
+	pathEndChannelUpgradeMessages pathEndChannelUpgradeMessages,
+) pathEndChannelHandshakeResponse {
+	var (
+		res         pathEndChannelHandshakeResponse
+		toDeleteSrc = make(map[string][]ChannelKey)
+		toDeleteDst = make(map[string][]ChannelKey)
+	)
+	processRemovals := func() {
+		pathEndChannelUpgradeMessages.Src.messageCache.ChannelHandshake.DeleteMessages(toDeleteSrc)
+		pathEndChannelUpgradeMessages.Dst.messageCache.ChannelHandshake.DeleteMessages(toDeleteDst)
+		pathEndChannelUpgradeMessages.Src.channelProcessing.deleteMessages(toDeleteSrc)
+		pathEndChannelUpgradeMessages.Dst.channelProcessing.deleteMessages(toDeleteDst)
+		toDeleteSrc = make(map[string][]ChannelKey)
+		toDeleteDst = make(map[string][]ChannelKey)
+	}
+	// removeUpgrade removes all retention of the channel upgrade for the channel on both path ends.
+	removeUpgrade := func(chanKey ChannelKey) {
+		for _, eventType := range channelUpgradeEventTypes {
+			toDeleteSrc[eventType] = append(toDeleteSrc[eventType], chanKey)
+			toDeleteDst[eventType] = append(toDeleteDst[eventType], chanKey.Counterparty())
+		}
+	}
+
+	for chanKey := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeOpen {
+		if _, ok := pathEndChannelUpgradeMessages.DstMsgChannelUpgradeOpen[chanKey.Counterparty()]; ok {
+			// found upgrade open on both ends, channel upgrade complete. remove all retention
+			removeUpgrade(chanKey)
+		}
+	}
+
+	processRemovals()
+
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeError {
+		counterpartyKey := chanKey.Counterparty()
+		if upgradeSequenceReached(info.UpgradeSequence, counterpartyKey,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeError,
+		) || !pathEndChannelUpgradeMessages.dstUpgradeInProgress(counterpartyKey) {
+			// upgrade has been aborted on both ends, or there is no upgrade to cancel on dst. remove all retention
+			removeUpgrade(chanKey)
+			continue
+		}
+
+		// upgrade has been aborted on src, need to send an upgrade cancel to dst
+		msgUpgradeCancel := channelIBCMessage{
+			eventType: chantypes.EventTypeChannelUpgradeCancel,
+			info:      info,
+		}
+		if pathEndChannelUpgradeMessages.Dst.shouldSendChannelMessage(
+			msgUpgradeCancel, pathEndChannelUpgradeMessages.Src,
+		) {
+			res.DstMessages = append(res.DstMessages, msgUpgradeCancel)
+		}
+	}
+
+	processRemovals()
+
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeInit {
+		counterpartyKey := chanKey.Counterparty()
+		if pathEndChannelUpgradeMessages.aborted(chanKey, info.UpgradeSequence) {
+			continue
+		}
+		if upgradeSequenceReached(info.UpgradeSequence, counterpartyKey,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeTry,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeAck,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeConfirm,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeOpen,
+		) {
+			// upgrade try already completed on dst
+			continue
+		}
+
+		// need to send an upgrade try to dst
+		msgUpgradeTry := channelIBCMessage{
+			eventType: chantypes.EventTypeChannelUpgradeTry,
+			info:      info,
+		}
+		if pathEndChannelUpgradeMessages.Dst.shouldSendChannelMessage(
+			msgUpgradeTry, pathEndChannelUpgradeMessages.Src,
+		) {
+			res.DstMessages = append(res.DstMessages, msgUpgradeTry)
+		}
+	}
+
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeTry {
+		counterpartyKey := chanKey.Counterparty()
+		if pathEndChannelUpgradeMessages.aborted(chanKey, info.UpgradeSequence) {
+			continue
+		}
+
+		// in the crossing hello case, both ends have moved to FLUSHING with an upgrade try,
+		// so the next step is an upgrade confirm rather than an upgrade ack.
+		eventType := chantypes.EventTypeChannelUpgradeAck
+		if upgradeSequenceReached(info.UpgradeSequence, counterpartyKey,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeTry,
+		) {
+			eventType = chantypes.EventTypeChannelUpgradeConfirm
+		}
+
+		if upgradeSequenceReached(info.UpgradeSequence, counterpartyKey,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeAck,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeConfirm,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeOpen,
+		) {
+			// upgrade ack already completed on dst
+			continue
+		}
+
+		// need to send an upgrade ack to dst
+		msgUpgradeAck := channelIBCMessage{
+			eventType: eventType,
+			info:      info,
+		}
+		if pathEndChannelUpgradeMessages.Dst.shouldSendChannelMessage(
+			msgUpgradeAck, pathEndChannelUpgradeMessages.Src,
+		) {
+			res.DstMessages = append(res.DstMessages, msgUpgradeAck)
+		}
+	}
+
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeAck {
+		if pathEndChannelUpgradeMessages.aborted(chanKey, info.UpgradeSequence) {
+			continue
+		}
+		if upgradeSequenceReached(info.UpgradeSequence, chanKey.Counterparty(),
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeConfirm,
+			pathEndChannelUpgradeMessages.DstMsgChannelUpgradeOpen,
+		) {
+			// upgrade confirm already completed on dst
+			continue
+		}
+
+		// need to send an upgrade confirm to dst
+		msgUpgradeConfirm := channelIBCMessage{
+			eventType: chantypes.EventTypeChannelUpgradeConfirm,
+			info:      info,
+		}
+		if pathEndChannelUpgradeMessages.Dst.shouldSendChannelMessage(
+			msgUpgradeConfirm, pathEndChannelUpgradeMessages.Src,
+		) {
+			res.DstMessages = append(res.DstMessages, msgUpgradeConfirm)
+		}
+	}
+
+	// an upgrade open can be sent to dst once both ends have completed flushing.
+	srcFlushComplete := make(ChannelMessageCache)
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeAck {
+		if info.State == chantypes.FLUSHCOMPLETE {
+			srcFlushComplete[chanKey] = info
+		}
+	}
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeConfirm {
+		if info.State == chantypes.FLUSHCOMPLETE {
+			srcFlushComplete[chanKey] = info
+		}
+	}
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelFlushComplete {
+		srcFlushComplete[chanKey] = info
+	}
+	for chanKey, info := range pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeOpen {
+		srcFlushComplete[chanKey] = info
+	}
+
+	for chanKey, info := range srcFlushComplete {
+		counterpartyKey := chanKey.Counterparty()
+		if pathEndChannelUpgradeMessages.aborted(chanKey, info.UpgradeSequence) {
+			continue
+		}
+		if _, ok := pathEndChannelUpgradeMessages.DstMsgChannelUpgradeOpen[counterpartyKey]; ok {
+			// upgrade open already completed on dst
+			continue
+		}
+		if !pathEndChannelUpgradeMessages.dstFlushComplete(counterpartyKey) {
+			// dst is still flushing in-flight packets
+			continue
+		}
+
+		// need to send an upgrade open to dst
+		msgUpgradeOpen := channelIBCMessage{
+			eventType: chantypes.EventTypeChannelUpgradeOpen,
+			info:      info,
+		}
+		if pathEndChannelUpgradeMessages.Dst.shouldSendChannelMessage(
+			msgUpgradeOpen, pathEndChannelUpgradeMessages.Src,
+		) {
+			res.DstMessages = append(res.DstMessages, msgUpgradeOpen)
+		}
+	}
+
+	// src may time out its upgrade if dst has not completed flushing before the upgrade timeout.
+	dstLatestBlock := pathEndChannelUpgradeMessages.Dst.latestBlock
+	dstLatestHeight := clienttypes.NewHeight(
+		clienttypes.ParseChainID(pathEndChannelUpgradeMessages.Dst.info.ChainID),
+		dstLatestBlock.Height,
+	)
+	for _, flushing := range []ChannelMessageCache{
+		pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeTry,
+		pathEndChannelUpgradeMessages.SrcMsgChannelUpgradeAck,
+	} {
+		for chanKey, info := range flushing {
+			if pathEndChannelUpgradeMessages.aborted(chanKey, info.UpgradeSequence) {
+				continue
+			}
+			timeout := chantypes.NewTimeout(info.UpgradeTimeoutHeight, info.UpgradeTimeoutTimestamp)
+			if !timeout.IsValid() || !timeout.Elapsed(dstLatestHeight, uint64(dstLatestBlock.Time.UnixNano())) {
+				continue
+			}
+			counterpartyKey := chanKey.Counterparty()
+			if _, ok := pathEndChannelUpgradeMessages.DstMsgChannelUpgradeOpen[counterpartyKey]; ok {
+				// dst has already upgraded, can no longer time out
+				continue
+			}
+			if pathEndChannelUpgradeMessages.dstFlushComplete(counterpartyKey) {
+				// dst completed flushing before the timeout, can no longer time out
+				continue
+			}
+
+			// need to send an upgrade timeout to src, proving the dst channel state.
+			msgUpgradeTimeout := channelIBCMessage{
+				eventType: chantypes.EventTypeChannelUpgradeTimeout,
+				info: provider.ChannelInfo{
+					PortID:                info.CounterpartyPortID,
+					ChannelID:             info.CounterpartyChannelID,
+					CounterpartyPortID:    info.PortID,
+					CounterpartyChannelID: info.ChannelID,
+					UpgradeSequence:       info.UpgradeSequence,
+				},
+			}
+			if pathEndChannelUpgradeMessages.Src.shouldSendChannelMessage(
+				msgUpgradeTimeout, pathEndChannelUpgradeMessages.Dst,
+			) {
+				res.SrcMessages = append(res.SrcMessages, msgUpgradeTimeout)
+			}
+		}
+	}
+
+	return res
+}
+
+// aborted returns true if the channel upgrade with the given upgrade sequence has been aborted on either end.
+func (m pathEndChannelUpgradeMessages) aborted(chanKey ChannelKey, upgradeSequence uint64) bool {
+	return upgradeSequenceReached(upgradeSequence, chanKey, m.SrcMsgChannelUpgradeError) ||
+		upgradeSequenceReached(upgradeSequence, chanKey.Counterparty(), m.DstMsgChannelUpgradeError)
+}
+
+// dstUpgradeInProgress returns true if an upgrade handshake has been observed for the channel on dst.
+func (m pathEndChannelUpgradeMessages) dstUpgradeInProgress(chanKey ChannelKey) bool {
+	for _, c := range []ChannelMessageCache{
+		m.DstMsgChannelUpgradeInit,
+		m.DstMsgChannelUpgradeTry,
+		m.DstMsgChannelUpgradeAck,
+		m.DstMsgChannelUpgradeConfirm,
+		m.DstMsgChannelFlushComplete,
+	} {
+		if _, ok := c[chanKey]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dstFlushComplete returns true if the channel on dst has completed flushing in-flight packets for its upgrade.
+func (m pathEndChannelUpgradeMessages) dstFlushComplete(chanKey ChannelKey) bool {
+	if _, ok := m.DstMsgChannelFlushComplete[chanKey]; ok {
+		return true
+	}
+	if info, ok := m.DstMsgChannelUpgradeAck[chanKey]; ok && info.State == chantypes.FLUSHCOMPLETE {
+		return true
+	}
+	if info, ok := m.DstMsgChannelUpgradeConfirm[chanKey]; ok && info.State == chantypes.FLUSHCOMPLETE {
+		return true
+	}
+	return false
+}
+
+// upgradeSequenceReached returns true if any of the caches hold a channel upgrade event for chanKey
+// with at least the given upgrade sequence.
+func upgradeSequenceReached(upgradeSequence uint64, chanKey ChannelKey, caches ...ChannelMessageCache) bool {
+	for _, c := range caches {
+		if info, ok := c[chanKey]; ok && info.UpgradeSequence >= upgradeSequence {
+			return true
+		}
+	}
+	return false
+}
+
 func (pp *PathProcessor) getUnrelayedClientICQMessages(pathEnd *pathEndRuntime, queryMessages, responseMessages ClientICQMessageCache) (res []clientICQMessage) {
 	var doneQueryIDs []provider.ClientICQQueryID
 
@@ -733,6 +1038,10 @@ var observedEventTypeForDesiredMessage = map[string]string{
 	chantypes.EventTypeChannelOpenTry:     chantypes.EventTypeChannelOpenInit,
 	chantypes.EventTypeChannelOpenInit:    preInitKey,
 
+	chantypes.EventTypeChannelUpgradeConfirm: chantypes.EventTypeChannelUpgradeAck,
+	chantypes.EventTypeChannelUpgradeAck:     chantypes.EventTypeChannelUpgradeTry,
+	chantypes.EventTypeChannelUpgradeTry:     chantypes.EventTypeChannelUpgradeInit,
+
 	chantypes.EventTypeAcknowledgePacket: chantypes.EventTypeRecvPacket,
 	chantypes.EventTypeRecvPacket:        chantypes.EventTypeSendPacket,
 	chantypes.EventTypeSendPacket:        preInitKey,
@@ -912,8 +1221,43 @@ func (pp *PathProcessor) queuePreInitMessages(cancel func()) {
 	}
 }
 
-// messages from both pathEnds are needed in order to determine what needs to be relayed for a single pathEnd
+// messages from both pathEnds are needed in order to determine what needs to be relayed for a single pathEnd.
+// The messages are observed for both pathEnds, then relayed through the remaining stages of the relay pipeline
+// to each pathEnd in parallel.
 func (pp *PathProcessor) processLatestMessages(ctx context.Context, cancel func()) error {
+	start := time.Now()
+	pathEnd1Messages, pathEnd2Messages := pp.observeMessages(ctx, cancel)
+	if pp.metrics != nil {
+		d := time.Since(start)
+		pp.metrics.ObserveRelayStage(pp.pathEnd1.info.PathName, pp.pathEnd1.info.ChainID, string(RelayStageObserve), d, false)
+		pp.metrics.ObserveRelayStage(pp.pathEnd2.info.PathName, pp.pathEnd2.info.ChainID, string(RelayStageObserve), d, false)
+	}
+
+	// now assemble and send messages in parallel
+	// if sending messages fails to one pathEnd, we don't need to halt sending to the other pathEnd.
+	// Messages are not sent to a halted chain, or one about to halt for an upgrade,
+	// they remain queued until it produces blocks again.
+	var eg errgroup.Group
+	eg.Go(func() error {
+		if pp.pathEnd1.paused() {
+			return nil
+		}
+		mp := pp.newMessageProcessor()
+		return mp.processMessages(ctx, pathEnd1Messages, pp.pathEnd2, pp.pathEnd1)
+	})
+	eg.Go(func() error {
+		if pp.pathEnd2.paused() {
+			return nil
+		}
+		mp := pp.newMessageProcessor()
+		return mp.processMessages(ctx, pathEnd2Messages, pp.pathEnd1, pp.pathEnd2)
+	})
+	return eg.Wait()
+}
+
+// observeMessages is the observe stage of the relay pipeline, which determines the messages to relay to each
+// pathEnd from the messages observed on both of them.
+func (pp *PathProcessor) observeMessages(ctx context.Context, cancel func()) (pathEnd1Messages, pathEnd2Messages pathEndMessages) {
 	// Update trusted client state for both pathends
 	pp.updateClientTrustedState(pp.pathEnd1, pp.pathEnd2)
 	pp.updateClientTrustedState(pp.pathEnd2, pp.pathEnd1)
@@ -1028,17 +1372,61 @@ func (pp *PathProcessor) processLatestMessages(ctx context.Context, cancel func(
 	pathEnd1ChannelCloseRes := pp.unrelayedChannelCloseMessages(pathEnd1ChannelCloseMessages)
 	pathEnd2ChannelCloseRes := pp.unrelayedChannelCloseMessages(pathEnd2ChannelCloseMessages)
 
+	pathEnd1ChannelUpgradeMessages := pathEndChannelUpgradeMessages{
+		Src:                         pp.pathEnd1,
+		Dst:                         pp.pathEnd2,
+		SrcMsgChannelUpgradeInit:    pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeInit],
+		SrcMsgChannelUpgradeTry:     pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeTry],
+		SrcMsgChannelUpgradeAck:     pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeAck],
+		SrcMsgChannelUpgradeConfirm: pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeConfirm],
+		SrcMsgChannelFlushComplete:  pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelFlushComplete],
+		SrcMsgChannelUpgradeOpen:    pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeOpen],
+		SrcMsgChannelUpgradeError:   pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeError],
+		DstMsgChannelUpgradeInit:    pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeInit],
+		DstMsgChannelUpgradeTry:     pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeTry],
+		DstMsgChannelUpgradeAck:     pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeAck],
+		DstMsgChannelUpgradeConfirm: pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeConfirm],
+		DstMsgChannelFlushComplete:  pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelFlushComplete],
+		DstMsgChannelUpgradeOpen:    pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeOpen],
+		DstMsgChannelUpgradeError:   pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeError],
+	}
+	pathEnd2ChannelUpgradeMessages := pathEndChannelUpgradeMessages{
+		Src:                         pp.pathEnd2,
+		Dst:                         pp.pathEnd1,
+		SrcMsgChannelUpgradeInit:    pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeInit],
+		SrcMsgChannelUpgradeTry:     pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeTry],
+		SrcMsgChannelUpgradeAck:     pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeAck],
+		SrcMsgChannelUpgradeConfirm: pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeConfirm],
+		SrcMsgChannelFlushComplete:  pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelFlushComplete],
+		SrcMsgChannelUpgradeOpen:    pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeOpen],
+		SrcMsgChannelUpgradeError:   pp.pathEnd2.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeError],
+		DstMsgChannelUpgradeInit:    pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeInit],
+		DstMsgChannelUpgradeTry:     pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeTry],
+		DstMsgChannelUpgradeAck:     pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeAck],
+		DstMsgChannelUpgradeConfirm: pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeConfirm],
+		DstMsgChannelFlushComplete:  pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelFlushComplete],
+		DstMsgChannelUpgradeOpen:    pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeOpen],
+		DstMsgChannelUpgradeError:   pp.pathEnd1.messageCache.ChannelHandshake[chantypes.EventTypeChannelUpgradeError],
+	}
+	pathEnd1ChannelUpgradeRes := pp.unrelayedChannelUpgradeMessages(pathEnd1ChannelUpgradeMessages)
+	pathEnd2ChannelUpgradeRes := pp.unrelayedChannelUpgradeMessages(pathEnd2ChannelUpgradeMessages)
+
 	// concatenate applicable messages for pathend
 	pathEnd1ConnectionMessages, pathEnd2ConnectionMessages := pp.connectionMessagesToSend(pathEnd1ConnectionHandshakeRes, pathEnd2ConnectionHandshakeRes)
 	pathEnd1ChannelMessages, pathEnd2ChannelMessages := pp.channelMessagesToSend(
 		pathEnd1ChannelHandshakeRes, pathEnd2ChannelHandshakeRes,
 		pathEnd1ChannelCloseRes, pathEnd2ChannelCloseRes,
+		pathEnd1ChannelUpgradeRes, pathEnd2ChannelUpgradeRes,
 	)
 
 	pathEnd1PacketMessages, pathEnd2PacketMessages, pathEnd1ChanCloseMessages, pathEnd2ChanCloseMessages := pp.packetMessagesToSend(channelPairs, pathEnd1ProcessRes, pathEnd2ProcessRes)
 	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd1ChanCloseMessages...)
 	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd2ChanCloseMessages...)
 
+	now := time.Now()
+	pp.pathEnd1.trackPacketQueue(pathEnd1PacketMessages, now)
+	pp.pathEnd2.trackPacketQueue(pathEnd2PacketMessages, now)
+
 	pathEnd1ClientICQMessages := pp.getUnrelayedClientICQMessages(
 		pp.pathEnd1,
 		pp.pathEnd1.messageCache.ClientICQ[ClientICQTypeRequest],
@@ -1050,35 +1438,34 @@ func (pp *PathProcessor) processLatestMessages(ctx context.Context, cancel func(
 		pp.pathEnd2.messageCache.ClientICQ[ClientICQTypeResponse],
 	)
 
-	pathEnd1Messages := pathEndMessages{
+	pathEnd1Messages = pathEndMessages{
 		connectionMessages: pathEnd1ConnectionMessages,
 		channelMessages:    pathEnd1ChannelMessages,
 		packetMessages:     pathEnd1PacketMessages,
 		clientICQMessages:  pathEnd1ClientICQMessages,
 	}
 
-	pathEnd2Messages := pathEndMessages{
+	pathEnd2Messages = pathEndMessages{
 		connectionMessages: pathEnd2ConnectionMessages,
 		channelMessages:    pathEnd2ChannelMessages,
 		packetMessages:     pathEnd2PacketMessages,
 		clientICQMessages:  pathEnd2ClientICQMessages,
 	}
+	return pathEnd1Messages, pathEnd2Messages
+}
 
-	// now assemble and send messages in parallel
-	// if sending messages fails to one pathEnd, we don't need to halt sending to the other pathEnd.
-	var eg errgroup.Group
-	eg.Go(func() error {
-		mp := newMessageProcessor(pp.log, pp.metrics, pp.memo, pp.clientUpdateThresholdTime, pp.isLocalhost)
-		return mp.processMessages(ctx, pathEnd1Messages, pp.pathEnd2, pp.pathEnd1)
-	})
-	eg.Go(func() error {
-		mp := newMessageProcessor(pp.log, pp.metrics, pp.memo, pp.clientUpdateThresholdTime, pp.isLocalhost)
-		return mp.processMessages(ctx, pathEnd2Messages, pp.pathEnd1, pp.pathEnd2)
-	})
-	return eg.Wait()
+// newMessageProcessor returns a messageProcessor which assembles and sends messages with the settings of the path.
+func (pp *PathProcessor) newMessageProcessor() *messageProcessor {
+	mp := newMessageProcessor(pp.log, pp.metrics, pp.memo, pp.clientUpdateThresholdTime, pp.isLocalhost)
+	mp.txResults = pp.txResults
+	mp.monitorOnly = pp.monitorOnly
+	mp.skipRelayedPackets = pp.skipRelayedPackets
+	mp.forceBisection = pp.forceBisection
+	mp.workers = pp.workers
+	return mp
 }
 
-func (pp *PathProcessor) channelMessagesToSend(pathEnd1ChannelHandshakeRes, pathEnd2ChannelHandshakeRes, pathEnd1ChannelCloseRes, pathEnd2ChannelCloseRes pathEndChannelHandshakeResponse) ([]channelIBCMessage, []channelIBCMessage) {
+func (pp *PathProcessor) channelMessagesToSend(pathEnd1ChannelHandshakeRes, pathEnd2ChannelHandshakeRes, pathEnd1ChannelCloseRes, pathEnd2ChannelCloseRes, pathEnd1ChannelUpgradeRes, pathEnd2ChannelUpgradeRes pathEndChannelHandshakeResponse) ([]channelIBCMessage, []channelIBCMessage) {
 	pathEnd1ChannelOpenSrcLen := len(pathEnd1ChannelHandshakeRes.SrcMessages)
 	pathEnd1ChannelOpenDstLen := len(pathEnd1ChannelHandshakeRes.DstMessages)
 	pathEnd2ChannelOpenDstLen := len(pathEnd2ChannelHandshakeRes.DstMessages)
@@ -1089,20 +1476,29 @@ func (pp *PathProcessor) channelMessagesToSend(pathEnd1ChannelHandshakeRes, path
 	pathEnd2ChannelCloseDstLen := len(pathEnd2ChannelHandshakeRes.DstMessages)
 	pathEnd2ChannelCloseSrcLen := len(pathEnd2ChannelHandshakeRes.SrcMessages)
 
-	pathEnd1ChannelMessages := make([]channelIBCMessage, 0, pathEnd1ChannelOpenSrcLen+pathEnd2ChannelOpenDstLen+pathEnd1ChannelCloseSrcLen+pathEnd2ChannelCloseDstLen)
-	pathEnd2ChannelMessages := make([]channelIBCMessage, 0, pathEnd2ChannelOpenSrcLen+pathEnd1ChannelOpenDstLen+pathEnd2ChannelCloseSrcLen+pathEnd1ChannelCloseDstLen)
+	pathEnd1ChannelUpgradeSrcLen := len(pathEnd1ChannelUpgradeRes.SrcMessages)
+	pathEnd1ChannelUpgradeDstLen := len(pathEnd1ChannelUpgradeRes.DstMessages)
+	pathEnd2ChannelUpgradeDstLen := len(pathEnd2ChannelUpgradeRes.DstMessages)
+	pathEnd2ChannelUpgradeSrcLen := len(pathEnd2ChannelUpgradeRes.SrcMessages)
+
+	pathEnd1ChannelMessages := make([]channelIBCMessage, 0, pathEnd1ChannelOpenSrcLen+pathEnd2ChannelOpenDstLen+pathEnd1ChannelCloseSrcLen+pathEnd2ChannelCloseDstLen+pathEnd1ChannelUpgradeSrcLen+pathEnd2ChannelUpgradeDstLen)
+	pathEnd2ChannelMessages := make([]channelIBCMessage, 0, pathEnd2ChannelOpenSrcLen+pathEnd1ChannelOpenDstLen+pathEnd2ChannelCloseSrcLen+pathEnd1ChannelCloseDstLen+pathEnd2ChannelUpgradeSrcLen+pathEnd1ChannelUpgradeDstLen)
 
 	// pathEnd1 channel messages come from pathEnd1 src and pathEnd2 dst
 	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd2ChannelHandshakeRes.DstMessages...)
 	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd2ChannelCloseRes.DstMessages...)
 	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd1ChannelHandshakeRes.SrcMessages...)
 	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd1ChannelCloseRes.SrcMessages...)
+	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd2ChannelUpgradeRes.DstMessages...)
+	pathEnd1ChannelMessages = append(pathEnd1ChannelMessages, pathEnd1ChannelUpgradeRes.SrcMessages...)
 
 	// pathEnd2 channel messages come from pathEnd2 src and pathEnd1 dst
 	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd1ChannelHandshakeRes.DstMessages...)
 	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd1ChannelCloseRes.DstMessages...)
 	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd2ChannelHandshakeRes.SrcMessages...)
 	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd2ChannelCloseRes.SrcMessages...)
+	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd1ChannelUpgradeRes.DstMessages...)
+	pathEnd2ChannelMessages = append(pathEnd2ChannelMessages, pathEnd2ChannelUpgradeRes.SrcMessages...)
 
 	return pathEnd1ChannelMessages, pathEnd2ChannelMessages
 }
@@ -1149,17 +1545,33 @@ func (pp *PathProcessor) packetMessagesToSend(
 	pathEnd1ChannelMessage := make([]channelIBCMessage, 0, pathEnd1ChannelLen)
 	pathEnd2ChannelMessage := make([]channelIBCMessage, 0, pathEnd2ChannelLen)
 
-	for i := range channelPairs {
+	pathEnd1Priorities := make([]int, 0, pathEnd1PacketLen)
+	pathEnd2Priorities := make([]int, 0, pathEnd2PacketLen)
+
+	for i, pair := range channelPairs {
+		pathEnd1Start, pathEnd2Start := len(pathEnd1PacketMessages), len(pathEnd2PacketMessages)
+
 		pathEnd1PacketMessages = append(pathEnd1PacketMessages, pathEnd2ProcessRes[i].DstMessages...)
 		pathEnd1PacketMessages = append(pathEnd1PacketMessages, pathEnd1ProcessRes[i].SrcMessages...)
 
 		pathEnd2PacketMessages = append(pathEnd2PacketMessages, pathEnd1ProcessRes[i].DstMessages...)
 		pathEnd2PacketMessages = append(pathEnd2PacketMessages, pathEnd2ProcessRes[i].SrcMessages...)
 
+		pathEnd1Priorities = append(pathEnd1Priorities, pp.pathEnd1.packetPriorities(
+			pathEnd1PacketMessages[pathEnd1Start:], pp.pathEnd2.info.ChainID, pair.pathEnd1ChannelKey,
+		)...)
+		pathEnd2Priorities = append(pathEnd2Priorities, pp.pathEnd2.packetPriorities(
+			pathEnd2PacketMessages[pathEnd2Start:], pp.pathEnd1.info.ChainID, pair.pathEnd2ChannelKey,
+		)...)
+
 		pathEnd1ChannelMessage = append(pathEnd1ChannelMessage, pathEnd2ProcessRes[i].DstChannelMessage...)
 		pathEnd2ChannelMessage = append(pathEnd2ChannelMessage, pathEnd1ProcessRes[i].DstChannelMessage...)
 	}
 
+	// relay packets which time out soon, incentivized packets and packets on priority channels first.
+	sortPacketMessages(pathEnd1PacketMessages, pathEnd1Priorities)
+	sortPacketMessages(pathEnd2PacketMessages, pathEnd2Priorities)
+
 	return pathEnd1PacketMessages, pathEnd2PacketMessages, pathEnd1ChannelMessage, pathEnd2ChannelMessage
 }
 
@@ -1196,76 +1608,52 @@ type skippedPackets struct {
 	Ack  uint64
 }
 
-// queuePendingRecvAndAcks returns the number of packets skipped during a flush (nil if none).
+// queuePendingRecvAndAcks queries the packets of the backlog of channel k of src, which are sent or received
+// in txs found by event queries, and caches them to be relayed. Packets which cannot be queried, e.g. because
+// the node has tx indexing disabled and they are not in the packet index, are left to be relayed once observed
+// by the chain processors and do not fail the flush. It returns the number of packets skipped during a flush
+// (nil if none).
 func (pp *PathProcessor) queuePendingRecvAndAcks(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
 	k ChannelKey,
-	seqs []uint64,
+	backlog packetBacklog,
 	srcCache ChannelPacketMessagesCache,
 	dstCache ChannelPacketMessagesCache,
 	srcMu sync.Locker,
 	dstMu sync.Locker,
 ) (*skippedPackets, error) {
-
-	if len(seqs) == 0 {
+	unrecv, unacked, order := backlog.unrecv, backlog.unacked, backlog.order
+	if len(unrecv) == 0 && len(unacked) == 0 {
 		src.log.Debug("Nothing to flush", zap.String("channel", k.ChannelID), zap.String("port", k.PortID))
-		if pp.metrics != nil {
-			pp.metrics.SetUnrelayedPackets(pp.pathEnd1.info.PathName, src.info.ChainID, dst.info.ChainID, k.ChannelID, k.CounterpartyChannelID, 0)
-			pp.metrics.SetUnrelayedAcks(pp.pathEnd1.info.PathName, src.info.ChainID, dst.info.ChainID, k.ChannelID, k.CounterpartyChannelID, 0)
-		}
 		return nil, nil
 	}
 
-	dstChan, dstPort := k.CounterpartyChannelID, k.CounterpartyPortID
-
-	unrecv, err := dst.chainProvider.QueryUnreceivedPackets(ctx, dst.latestBlock.Height, dstChan, dstPort, seqs)
-	if err != nil {
-		return nil, err
-	}
-
-	if pp.metrics != nil {
-		pp.metrics.SetUnrelayedPackets(pp.pathEnd1.info.PathName, src.info.ChainID, dst.info.ChainID, k.ChannelID, k.CounterpartyChannelID, len(unrecv))
-	}
-
-	dstHeight := int64(dst.latestBlock.Height)
-
-	var order chantypes.Order
-
-	if len(unrecv) > 0 {
-		channel, err := dst.chainProvider.QueryChannel(ctx, dstHeight, dstChan, dstPort)
-		if err != nil {
-			return nil, err
-		}
-
-		order = channel.Channel.Ordering
-
-		if channel.Channel.Ordering == chantypes.ORDERED {
-			nextSeqRecv, err := dst.chainProvider.QueryNextSeqRecv(ctx, dstHeight, dstChan, dstPort)
-			if err != nil {
-				return nil, err
-			}
-
-			var newUnrecv []uint64
-
-			for _, seq := range unrecv {
-				if seq >= nextSeqRecv.NextSequenceReceive {
-					newUnrecv = append(newUnrecv, seq)
-				}
-			}
+	var eg errgroup.Group
+	eg.SetLimit(pp.concurrency.queryLimit())
 
-			unrecv = newUnrecv
+	var skipped *skippedPackets
 
-			sort.SliceStable(unrecv, func(i, j int) bool {
-				return unrecv[i] < unrecv[j]
-			})
+	var (
+		unavailableMu                   sync.Mutex
+		unavailableRecv, unavailableAck int
+	)
+	// unavailable counts the packet which failed to be queried with err, unless the flush has been cancelled.
+	unavailable := func(count *int, seq uint64, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		src.log.Debug("Failed to query packet of backlog",
+			zap.Object("channel", k),
+			zap.Uint64("sequence", seq),
+			zap.Error(err),
+		)
+		unavailableMu.Lock()
+		*count++
+		unavailableMu.Unlock()
+		return nil
 	}
 
-	var eg errgroup.Group
-
-	var skipped *skippedPackets
-
 	for i, seq := range unrecv {
 		if state, ok := dst.messageCache.PacketState.State(k, seq); ok && stateValue(state) >= stateValue(chantypes.EventTypeRecvPacket) {
 			continue // already recv'd by path processor
@@ -1296,7 +1684,7 @@ func (pp *PathProcessor) queuePendingRecvAndAcks(
 		eg.Go(func() error {
 			sendPacket, err := src.chainProvider.QuerySendPacket(ctx, k.ChannelID, k.PortID, seq)
 			if err != nil {
-				return err
+				return unavailable(&unavailableRecv, seq, err)
 			}
 			sendPacket.ChannelOrder = order.String()
 			srcMu.Lock()
@@ -1332,23 +1720,6 @@ func (pp *PathProcessor) queuePendingRecvAndAcks(
 		)
 	}
 
-	var unacked []uint64
-
-SeqLoop:
-	for _, seq := range seqs {
-		for _, unrecvSeq := range unrecv {
-			if seq == unrecvSeq {
-				continue SeqLoop
-			}
-		}
-		// does not exist in unrecv, so this is an ack that must be written
-		unacked = append(unacked, seq)
-	}
-
-	if pp.metrics != nil {
-		pp.metrics.SetUnrelayedAcks(pp.pathEnd1.info.PathName, src.info.ChainID, dst.info.ChainID, k.ChannelID, k.CounterpartyChannelID, len(unacked))
-	}
-
 	for i, seq := range unacked {
 		ck := k.Counterparty()
 
@@ -1382,7 +1753,7 @@ SeqLoop:
 		eg.Go(func() error {
 			recvPacket, err := dst.chainProvider.QueryRecvPacket(ctx, k.CounterpartyChannelID, k.CounterpartyPortID, seq)
 			if err != nil {
-				return err
+				return unavailable(&unavailableAck, seq, err)
 			}
 
 			ck := k.Counterparty()
@@ -1414,6 +1785,16 @@ SeqLoop:
 		)
 	}
 
+	if unavailableRecv > 0 || unavailableAck > 0 {
+		src.log.Warn(
+			"Failed to query packets of backlog, they are relayed once observed by the chain processors; "+
+				"enable tx indexing on the node or the packet index to relay them",
+			zap.Object("channel", k),
+			zap.Int("unavailable_recv", unavailableRecv),
+			zap.Int("unavailable_ack", unavailableAck),
+		)
+	}
+
 	return skipped, nil
 }
 
@@ -1432,6 +1813,7 @@ func (pp *PathProcessor) flush(ctx context.Context) error {
 
 	// Query remaining packet commitments on both chains
 	var eg errgroup.Group
+	eg.SetLimit(pp.concurrency.queryLimit())
 	for k, cs := range pp.pathEnd1.channelStateCache {
 		if !cs.Open {
 			continue
@@ -1466,13 +1848,19 @@ func (pp *PathProcessor) flush(ctx context.Context) error {
 	// From remaining packet commitments, determine if:
 	// 1. Packet commitment is on source, but MsgRecvPacket has not yet been relayed to destination
 	// 2. Packet commitment is on source, and MsgRecvPacket has been relayed to destination, but MsgAcknowledgement has not been written to source to clear the packet commitment.
-	// Based on above conditions, enqueue MsgRecvPacket and MsgAcknowledgement messages
+	// This backlog is detected from state queries only. Based on above conditions, enqueue MsgRecvPacket and
+	// MsgAcknowledgement messages for those packets of the backlog which can be queried, without failing the flush
+	// for those which cannot, e.g. on nodes with tx indexing disabled.
 	skipped := make(map[string]map[ChannelKey]skippedPackets)
 	for k, seqs := range commitments1 {
 		k := k
 		seqs := seqs
 		eg.Go(func() error {
-			s, err := pp.queuePendingRecvAndAcks(ctx, pp.pathEnd1, pp.pathEnd2, k, seqs, pathEnd1Cache.PacketFlow, pathEnd2Cache.PacketFlow, &pathEnd1CacheMu, &pathEnd2CacheMu)
+			backlog, err := pp.queryPacketBacklog(ctx, pp.pathEnd1, pp.pathEnd2, k, seqs)
+			if err != nil {
+				return err
+			}
+			s, err := pp.queuePendingRecvAndAcks(ctx, pp.pathEnd1, pp.pathEnd2, k, backlog, pathEnd1Cache.PacketFlow, pathEnd2Cache.PacketFlow, &pathEnd1CacheMu, &pathEnd2CacheMu)
 			if err != nil {
 				return err
 			}
@@ -1491,12 +1879,17 @@ func (pp *PathProcessor) flush(ctx context.Context) error {
 		seqs := seqs
 
 		eg.Go(func() error {
+			backlog, err := pp.queryPacketBacklog(ctx, pp.pathEnd2, pp.pathEnd1, k, seqs)
+			if err != nil {
+				return err
+			}
+
 			s, err := pp.queuePendingRecvAndAcks(
 				ctx,
 				pp.pathEnd2,
 				pp.pathEnd1,
 				k,
-				seqs,
+				backlog,
 				pathEnd2Cache.PacketFlow,
 				pathEnd1Cache.PacketFlow,
 				&pathEnd2CacheMu,
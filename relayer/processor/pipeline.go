@@ -0,0 +1,165 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// RelayStage is a stage of the pipeline which relays the pending messages of a path to one of its chains.
+// Each message passes through the stages in order, and a failure is attributed to the stage in which it happened,
+// so that only that stage is retried when it can be.
+type RelayStage string
+
+const (
+	// RelayStageObserve determines the pending messages from the events observed on both chains of the path.
+	RelayStageObserve RelayStage = "observe"
+
+	// RelayStageProof queries the proofs of the messages on their source chain.
+	RelayStageProof RelayStage = "proof"
+
+	// RelayStageBuild builds the messages for the destination chain from their proofs.
+	RelayStageBuild RelayStage = "build"
+
+	// RelayStageBroadcast broadcasts the messages in a transaction to the mempool of the destination chain.
+	RelayStageBroadcast RelayStage = "broadcast"
+
+	// RelayStageConfirm awaits the inclusion of the transaction in a block of the destination chain.
+	RelayStageConfirm RelayStage = "confirm"
+)
+
+const (
+	// proofStageAttempts is how many times the proof of a message is queried before its assembly fails.
+	// Proof queries have no side effects, so a transient failure of the node is retried right away.
+	proofStageAttempts = 2
+
+	// broadcastStageAttempts is how many times the same messages are broadcast when the account sequence of the
+	// transaction was stale, which the provider corrects when the broadcast fails.
+	broadcastStageAttempts = 2
+)
+
+// StageError is the error of a stage of the relay pipeline.
+type StageError struct {
+	Stage RelayStage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s stage: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// FailedStage returns the stage of the relay pipeline in which err happened, if it is a StageError.
+func FailedStage(err error) (RelayStage, bool) {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return stageErr.Stage, true
+	}
+	return "", false
+}
+
+// proofMessage is the proof stage of an ibcMessage.
+type proofMessage interface {
+	// proof queries the proof of the message on src at height, which the message for dst is built from.
+	// It returns nil for messages which are built without a proof.
+	proof(ctx context.Context, src, dst *pathEndRuntime, height uint64) (any, error)
+}
+
+// buildMessage is the build stage of an ibcMessage.
+type buildMessage interface {
+	// build builds the message for dst from the proof returned by the proof stage.
+	build(src, dst *pathEndRuntime, proof any) (provider.RelayerMessage, error)
+}
+
+// runStage runs fn as stage of the pipeline relaying to dst, recording its duration and whether it failed.
+// The error of fn is returned as a StageError.
+func (mp *messageProcessor) runStage(dst *pathEndRuntime, stage RelayStage, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	mp.recordStage(dst, stage, time.Since(start), err)
+	if err != nil {
+		return &StageError{Stage: stage, Err: err}
+	}
+	return nil
+}
+
+// recordStage records the duration and result of a stage of the pipeline relaying to dst.
+func (mp *messageProcessor) recordStage(dst *pathEndRuntime, stage RelayStage, d time.Duration, err error) {
+	if mp.metrics == nil {
+		return
+	}
+	mp.metrics.ObserveRelayStage(dst.info.PathName, dst.info.ChainID, string(stage), d, err != nil)
+}
+
+// assemble runs the proof and build stages of msg. The proof stage is retried on its own
+// if it fails, the build stage only depends on the proof so it is not retried.
+func (mp *messageProcessor) assemble(ctx context.Context, msg ibcMessage, src, dst *pathEndRuntime) (provider.RelayerMessage, error) {
+	var proof any
+	err := mp.runStage(dst, RelayStageProof, func() (err error) {
+		for attempt := 1; ; attempt++ {
+			proof, err = msg.proof(ctx, src, dst, mp.proofHeight)
+			if err == nil {
+				return mp.checkProofHeight(proof)
+			}
+			if attempt == proofStageAttempts || ctx.Err() != nil {
+				return err
+			}
+			dst.log.Debug("Retrying proof query",
+				zap.String("msg_type", msg.msgType()),
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var assembled provider.RelayerMessage
+	err = mp.runStage(dst, RelayStageBuild, func() (err error) {
+		assembled, err = msg.build(src, dst, proof)
+		return err
+	})
+	return assembled, err
+}
+
+// broadcast runs the broadcast stage of msgs to dst. The broadcast is attempted again with the same messages if the
+// account sequence of the transaction was stale, without assembling the messages again. The confirm stage is
+// recorded by a callback appended to callbacks once the inclusion of the transaction is awaited.
+func (mp *messageProcessor) broadcast(
+	ctx context.Context,
+	dst *pathEndRuntime,
+	msgs []provider.RelayerMessage,
+	callbacks []func(rtr *provider.RelayerTxResponse, err error),
+) error {
+	return mp.runStage(dst, RelayStageBroadcast, func() (err error) {
+		for attempt := 1; ; attempt++ {
+			// the callbacks of each attempt may be called asynchronously, so each attempt has its own confirm callback,
+			// which measures the confirmation from the broadcast of its own attempt.
+			broadcastAt := time.Now()
+			attemptCallbacks := append(slices.Clone(callbacks), func(_ *provider.RelayerTxResponse, err error) {
+				mp.recordStage(dst, RelayStageConfirm, time.Since(broadcastAt), err)
+			})
+
+			broadcastCtx, cancel := context.WithTimeout(ctx, dst.retryPolicy.MsgSendTimeout)
+			err = dst.chainProvider.SendMessagesToMempool(broadcastCtx, msgs, mp.memo, ctx, attemptCallbacks)
+			cancel()
+			if err == nil || attempt == broadcastStageAttempts || ctx.Err() != nil ||
+				provider.ClassifyTxFailure(err) != provider.TxFailureSequenceMismatch {
+				return err
+			}
+			dst.log.Debug("Broadcasting messages again with corrected account sequence",
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+		}
+	})
+}
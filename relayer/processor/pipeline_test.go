@@ -0,0 +1,276 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFailedStage(t *testing.T) {
+	err := fmt.Errorf("error assembling message: %w", &StageError{Stage: RelayStageProof, Err: errors.New("node unavailable")})
+	stage, ok := FailedStage(err)
+	require.True(t, ok)
+	require.Equal(t, RelayStageProof, stage)
+	require.EqualError(t, err, "error assembling message: proof stage: node unavailable")
+
+	_, ok = FailedStage(errors.New("node unavailable"))
+	require.False(t, ok)
+}
+
+// stagedMessage is a packet message whose proof stage fails with the next of proofErrs,
+// and whose build stage fails with buildErr.
+type stagedMessage struct {
+	packetIBCMessage
+
+	proofErrs []error
+	buildErr  error
+
+	proofs, builds int
+}
+
+func (m *stagedMessage) proof(context.Context, *pathEndRuntime, *pathEndRuntime, uint64) (any, error) {
+	m.proofs++
+	if len(m.proofErrs) > 0 {
+		err := m.proofErrs[0]
+		m.proofErrs = m.proofErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return provider.PacketProof{}, nil
+}
+
+func (m *stagedMessage) build(_, _ *pathEndRuntime, proof any) (provider.RelayerMessage, error) {
+	m.builds++
+	if _, ok := proof.(provider.PacketProof); !ok {
+		return nil, errors.New("missing proof")
+	}
+	if m.buildErr != nil {
+		return nil, m.buildErr
+	}
+	return mockRelayerMessage{}, nil
+}
+
+func TestAssembleStages(t *testing.T) {
+	queryErr := errors.New("node unavailable")
+	buildErr := errors.New("invalid packet")
+
+	for _, tc := range []struct {
+		name           string
+		msg            *stagedMessage
+		expectedStage  RelayStage
+		expectedProofs int
+		expectedBuilds int
+	}{
+		{
+			name:           "message is assembled",
+			msg:            &stagedMessage{},
+			expectedProofs: 1,
+			expectedBuilds: 1,
+		},
+		{
+			name:           "failed proof query is retried",
+			msg:            &stagedMessage{proofErrs: []error{queryErr}},
+			expectedProofs: 2,
+			expectedBuilds: 1,
+		},
+		{
+			name:           "proof stage fails after its attempts",
+			msg:            &stagedMessage{proofErrs: []error{queryErr, queryErr}},
+			expectedStage:  RelayStageProof,
+			expectedProofs: 2,
+		},
+		{
+			name:           "build stage failure does not query the proof again",
+			msg:            &stagedMessage{buildErr: buildErr},
+			expectedStage:  RelayStageBuild,
+			expectedProofs: 1,
+			expectedBuilds: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := NewPrometheusMetrics()
+			mp := &messageProcessor{log: zap.NewNop(), metrics: metrics}
+			src := &pathEndRuntime{log: zap.NewNop(), info: PathEnd{PathName: "demo-path", ChainID: "chain-a"}}
+			dst := &pathEndRuntime{log: zap.NewNop(), info: PathEnd{PathName: "demo-path", ChainID: "chain-b"}}
+
+			assembled, err := mp.assemble(context.Background(), tc.msg, src, dst)
+			require.Equal(t, tc.expectedProofs, tc.msg.proofs)
+			require.Equal(t, tc.expectedBuilds, tc.msg.builds)
+			if tc.expectedStage == "" {
+				require.NoError(t, err)
+				require.NotNil(t, assembled)
+				return
+			}
+			stage, ok := FailedStage(err)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedStage, stage)
+			require.Equal(t, float64(1), testutil.ToFloat64(
+				metrics.RelayStageFailures.WithLabelValues("demo-path", "chain-b", string(tc.expectedStage)),
+			))
+		})
+	}
+}
+
+// sequenceMismatchProvider fails the broadcast of the first mismatches txs with an account sequence mismatch,
+// and calls the callbacks of the txs broadcast after them with a successful response.
+type sequenceMismatchProvider struct {
+	provider.ChainProvider
+
+	mismatches int
+
+	mu  sync.Mutex
+	txs int
+}
+
+func (p *sequenceMismatchProvider) SendMessagesToMempool(
+	_ context.Context,
+	_ []provider.RelayerMessage,
+	_ string,
+	_ context.Context,
+	callbacks []func(*provider.RelayerTxResponse, error),
+) error {
+	p.mu.Lock()
+	p.txs++
+	txs := p.txs
+	p.mu.Unlock()
+
+	if txs <= p.mismatches {
+		return fmt.Errorf("failed to broadcast tx: %w", legacyerrors.ErrWrongSequence)
+	}
+	for _, cb := range callbacks {
+		cb(&provider.RelayerTxResponse{}, nil)
+	}
+	return nil
+}
+
+func TestBroadcastStage(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mismatches    int
+		expectedTxs   int
+		expectedStage RelayStage
+	}{
+		{
+			name:        "messages are broadcast",
+			expectedTxs: 1,
+		},
+		{
+			name:        "messages are broadcast again after a sequence mismatch",
+			mismatches:  1,
+			expectedTxs: 2,
+		},
+		{
+			name:          "broadcast stage fails after its attempts",
+			mismatches:    2,
+			expectedTxs:   2,
+			expectedStage: RelayStageBroadcast,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &sequenceMismatchProvider{mismatches: tc.mismatches}
+			metrics := NewPrometheusMetrics()
+			mp := &messageProcessor{log: zap.NewNop(), metrics: metrics}
+			dst := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+			dst.chainProvider = cp
+
+			var confirmed int
+			msgs := []provider.RelayerMessage{mockRelayerMessage{}}
+			err := mp.broadcast(context.Background(), dst, msgs, []func(*provider.RelayerTxResponse, error){
+				func(*provider.RelayerTxResponse, error) { confirmed++ },
+			})
+			require.Equal(t, tc.expectedTxs, cp.txs)
+			if tc.expectedStage != "" {
+				stage, ok := FailedStage(err)
+				require.True(t, ok)
+				require.Equal(t, tc.expectedStage, stage)
+				require.Equal(t, provider.TxFailureSequenceMismatch, provider.ClassifyTxFailure(err))
+				require.Zero(t, confirmed)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, confirmed)
+			require.Equal(t, 2, testutil.CollectAndCount(metrics.RelayStageDuration, "cosmos_relayer_relay_stage_duration_seconds"))
+		})
+	}
+}
+
+// asyncResultProvider calls the callbacks of every tx asynchronously, also of the first tx, whose broadcast fails with
+// an account sequence mismatch, as a provider may when it had already awaited the inclusion of the tx.
+type asyncResultProvider struct {
+	provider.ChainProvider
+
+	wg  sync.WaitGroup
+	txs int
+}
+
+func (p *asyncResultProvider) SendMessagesToMempool(
+	_ context.Context,
+	_ []provider.RelayerMessage,
+	_ string,
+	_ context.Context,
+	callbacks []func(*provider.RelayerTxResponse, error),
+) error {
+	p.txs++
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		time.Sleep(10 * time.Millisecond)
+		for _, cb := range callbacks {
+			cb(&provider.RelayerTxResponse{}, nil)
+		}
+	}()
+	if p.txs == 1 {
+		return fmt.Errorf("failed to broadcast tx: %w", legacyerrors.ErrWrongSequence)
+	}
+	return nil
+}
+
+func TestBroadcastStageAsyncCallbacks(t *testing.T) {
+	cp := &asyncResultProvider{}
+	mp := &messageProcessor{log: zap.NewNop(), metrics: NewPrometheusMetrics()}
+	dst := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+	dst.chainProvider = cp
+
+	// the confirm callback of the first attempt runs while the broadcast is attempted again, run with -race.
+	var confirmed atomic.Int32
+	err := mp.broadcast(context.Background(), dst, []provider.RelayerMessage{mockRelayerMessage{}}, []func(*provider.RelayerTxResponse, error){
+		func(*provider.RelayerTxResponse, error) { confirmed.Add(1) },
+	})
+	require.NoError(t, err)
+	cp.wg.Wait()
+	require.Equal(t, 2, cp.txs)
+	require.Equal(t, int32(2), confirmed.Load())
+}
+
+func TestProcessMessagesAssemblesThroughStages(t *testing.T) {
+	msg := &stagedMessage{
+		packetIBCMessage: packetIBCMessage{
+			eventType: chantypes.EventTypeRecvPacket,
+			info:      provider.PacketInfo{Sequence: 1},
+		},
+		proofErrs: []error{errors.New("node unavailable")},
+	}
+	mp := &messageProcessor{log: zap.NewNop(), workers: newSemaphore(1), pktMsgs: make([]packetMessageToTrack, 1)}
+	src := &pathEndRuntime{log: zap.NewNop(), info: PathEnd{PathName: "demo-path", ChainID: "chain-a"}}
+	dst := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	mp.assembleMessage(context.Background(), msg, src, dst, 0, &wg)
+	wg.Wait()
+
+	require.Equal(t, 2, msg.proofs)
+	require.NotNil(t, mp.pktMsgs[0].assembledMsg())
+}
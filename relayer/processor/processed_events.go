@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// DefaultProcessedRetentionBlocks is how many blocks of a chain a packet message relayed to the chain
+// is remembered for by default.
+const DefaultProcessedRetentionBlocks = 10_000
+
+// processedPruneInterval is how many blocks of a chain pass between prunes of the processed packet messages.
+const processedPruneInterval = 100
+
+// ProcessedPacket is a packet message which was included in a block of the chain it was relayed to.
+type ProcessedPacket struct {
+	// EventType is the type of the event of the message, e.g. recv_packet for a MsgRecvPacket.
+	EventType string
+	Channel   ChannelKey
+	Sequence  uint64
+
+	// Height is the height of the chain the message was relayed to, when its inclusion was observed.
+	Height uint64
+}
+
+// ProcessedEventStore persists the packet messages relayed by the PathProcessors.
+type ProcessedEventStore interface {
+	// MarkProcessed stores packets relayed to the chain on behalf of the path.
+	MarkProcessed(ctx context.Context, pathName, chainID string, packets []ProcessedPacket) error
+
+	// ProcessedPackets returns the packets relayed to the chain on behalf of the path which have not been pruned.
+	ProcessedPackets(ctx context.Context, pathName, chainID string) ([]ProcessedPacket, error)
+
+	// Prune deletes the packets relayed to the chain on behalf of the path below height.
+	Prune(ctx context.Context, pathName, chainID string, height uint64) error
+}
+
+// ProcessedEvents configures remembering the packet messages which were relayed, so that they are not relayed again
+// after a restart while the events which complete them have not been observed yet, e.g. while the initial block
+// history is queried.
+type ProcessedEvents struct {
+	Store ProcessedEventStore
+
+	// RetentionBlocks is how many blocks of a chain a packet message relayed to the chain is remembered for.
+	RetentionBlocks uint64
+}
+
+// processedPacketKey identifies a processed packet message within the path end it was relayed to.
+type processedPacketKey struct {
+	eventType string
+	channel   ChannelKey
+	sequence  uint64
+}
+
+// loadProcessedPackets loads the packet messages relayed to this path end before a restart.
+func (pathEnd *pathEndRuntime) loadProcessedPackets(ctx context.Context) {
+	if pathEnd.processedEvents == nil {
+		return
+	}
+	packets, err := pathEnd.processedEvents.Store.ProcessedPackets(ctx, pathEnd.info.PathName, pathEnd.info.ChainID)
+	if err != nil {
+		pathEnd.log.Error("Failed to load processed packet messages", zap.Error(err))
+		return
+	}
+	for _, p := range packets {
+		pathEnd.processedPackets[processedPacketKey{p.EventType, p.Channel, p.Sequence}] = p.Height
+	}
+	if len(packets) > 0 {
+		pathEnd.log.Info("Loaded processed packet messages", zap.Int("count", len(packets)))
+	}
+}
+
+// trackProcessedMessage remembers a packet message which finished processing without failure,
+// i.e. which was included in a block of this chain.
+func (pathEnd *pathEndRuntime) trackProcessedMessage(ctx context.Context, tracker messageToTrack, failure provider.TxFailure) {
+	t, ok := tracker.(packetMessageToTrack)
+	if pathEnd.processedEvents == nil || !ok || t.assembled == nil || failure != provider.TxFailureNone {
+		return
+	}
+	k, err := t.msg.channelKey()
+	if err != nil {
+		return
+	}
+
+	p := ProcessedPacket{
+		EventType: t.msg.eventType,
+		Channel:   k,
+		Sequence:  t.msg.info.Sequence,
+		Height:    pathEnd.latestBlock.Height,
+	}
+	pathEnd.processedPackets[processedPacketKey{p.EventType, p.Channel, p.Sequence}] = p.Height
+
+	store, pathName, chainID := pathEnd.processedEvents.Store, pathEnd.info.PathName, pathEnd.info.ChainID
+	go func() {
+		if err := store.MarkProcessed(ctx, pathName, chainID, []ProcessedPacket{p}); err != nil {
+			pathEnd.log.Error("Failed to store processed packet message",
+				zap.String("event_type", p.EventType),
+				zap.Uint64("sequence", p.Sequence),
+				zap.Inline(k),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// skipProcessedPacket returns true if the packet message was relayed to this path end and not pruned since,
+// so that it must not be sent again.
+func (pathEnd *pathEndRuntime) skipProcessedPacket(eventType string, k ChannelKey, sequence uint64) bool {
+	height, ok := pathEnd.processedPackets[processedPacketKey{eventType, k, sequence}]
+	if ok {
+		pathEnd.log.Debug("Skipping packet message which was already relayed",
+			zap.String("event_type", eventType),
+			zap.Uint64("sequence", sequence),
+			zap.Uint64("processed_height", height),
+			zap.Inline(k),
+		)
+	}
+	return ok
+}
+
+// pruneProcessedPackets forgets the packet messages relayed to this path end more than the retention blocks ago,
+// every processedPruneInterval blocks.
+func (pathEnd *pathEndRuntime) pruneProcessedPackets(ctx context.Context) {
+	if pathEnd.processedEvents == nil {
+		return
+	}
+	height := pathEnd.latestBlock.Height
+	if height < pathEnd.processedPrunedHeight+processedPruneInterval || height <= pathEnd.processedEvents.RetentionBlocks {
+		return
+	}
+	pathEnd.processedPrunedHeight = height
+
+	below := height - pathEnd.processedEvents.RetentionBlocks
+	for k, h := range pathEnd.processedPackets {
+		if h < below {
+			delete(pathEnd.processedPackets, k)
+		}
+	}
+
+	store, pathName, chainID := pathEnd.processedEvents.Store, pathEnd.info.PathName, pathEnd.info.ChainID
+	go func() {
+		if err := store.Prune(ctx, pathName, chainID, below); err != nil {
+			pathEnd.log.Error("Failed to prune processed packet messages", zap.Uint64("below_height", below), zap.Error(err))
+		}
+	}()
+}
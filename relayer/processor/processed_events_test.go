@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryProcessedEventStore is a ProcessedEventStore of a single path and chain.
+type memoryProcessedEventStore struct {
+	mu      sync.Mutex
+	packets []ProcessedPacket
+	pruned  uint64
+}
+
+func (s *memoryProcessedEventStore) MarkProcessed(_ context.Context, _, _ string, packets []ProcessedPacket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packets = append(s.packets, packets...)
+	return nil
+}
+
+func (s *memoryProcessedEventStore) ProcessedPackets(context.Context, string, string) ([]ProcessedPacket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ProcessedPacket(nil), s.packets...), nil
+}
+
+func (s *memoryProcessedEventStore) Prune(_ context.Context, _, _ string, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruned = height
+	return nil
+}
+
+type processedTestMessage struct{}
+
+func (processedTestMessage) Type() string { return "/ibc.core.channel.v1.MsgRecvPacket" }
+
+func (processedTestMessage) MsgBytes() ([]byte, error) { return nil, nil }
+
+func TestProcessedPackets(t *testing.T) {
+	ctx := context.Background()
+	channel := ChannelKey{ChannelID: "channel-5", PortID: "transfer", CounterpartyChannelID: "channel-0", CounterpartyPortID: "transfer"}
+	store := &memoryProcessedEventStore{
+		packets: []ProcessedPacket{{EventType: chantypes.EventTypeRecvPacket, Channel: channel, Sequence: 1, Height: 100}},
+	}
+
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+	pathEnd.processedEvents = &ProcessedEvents{Store: store, RetentionBlocks: 1000}
+
+	// packets relayed before a restart are skipped.
+	pathEnd.loadProcessedPackets(ctx)
+	require.True(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 1))
+	require.False(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 2))
+	require.False(t, pathEnd.skipProcessedPacket(chantypes.EventTypeAcknowledgePacket, channel, 1))
+
+	// only packet messages which were included in a block are remembered.
+	recv := packetMessageToTrack{
+		msg: packetIBCMessage{
+			eventType: chantypes.EventTypeRecvPacket,
+			info: provider.PacketInfo{
+				Sequence:      2,
+				SourceChannel: "channel-0",
+				SourcePort:    "transfer",
+				DestChannel:   "channel-5",
+				DestPort:      "transfer",
+			},
+		},
+	}
+	pathEnd.latestBlock = provider.LatestBlock{Height: 1050}
+	pathEnd.trackProcessedMessage(ctx, recv, provider.TxFailureNone)
+	require.False(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 2))
+
+	recv.assembled = processedTestMessage{}
+	pathEnd.trackProcessedMessage(ctx, recv, provider.TxFailureOutOfGas)
+	require.False(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 2))
+
+	pathEnd.trackProcessedMessage(ctx, recv, provider.TxFailureNone)
+	require.True(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 2))
+	require.Eventually(t, func() bool {
+		packets, _ := store.ProcessedPackets(ctx, "demo-path", "chain-b")
+		return len(packets) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// packets relayed more than the retention blocks ago are pruned.
+	pathEnd.latestBlock = provider.LatestBlock{Height: 1200}
+	pathEnd.pruneProcessedPackets(ctx)
+	require.False(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 1))
+	require.True(t, pathEnd.skipProcessedPacket(chantypes.EventTypeRecvPacket, channel, 2))
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.pruned == 200
+	}, time.Second, 10*time.Millisecond)
+}
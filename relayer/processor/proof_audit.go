@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"go.uber.org/zap"
+)
+
+// errProofHeightMismatch is returned for a message whose proof height is not the height of the consensus state
+// which the client update sent with it installs, so the destination could not verify its proof.
+var errProofHeightMismatch = errors.New("proof height does not match the height of the client update")
+
+// proofHeightAudit holds the heights involved in assembling a single IBC message
+// that carries a proof from the source chain for verification on the destination chain.
+type proofHeightAudit struct {
+	eventType string
+
+	// queriedHeight is the height of the source chain that the proof was queried for.
+	queriedHeight uint64
+
+	// proofHeight is the height returned alongside the proof, which the destination
+	// light client will use to look up the consensus state for proof verification.
+	proofHeight clienttypes.Height
+
+	// clientConsensusHeight is the latest consensus height of the destination's light client
+	// of the source chain, as currently observed on the destination chain.
+	clientConsensusHeight clienttypes.Height
+
+	// updateHeaderHeight is the height of the source chain header that will be used
+	// for the MsgUpdateClient sent alongside this message. Zero if unknown.
+	updateHeaderHeight uint64
+}
+
+// violations returns a description of each expected height relationship that does not hold.
+//
+// A proof queried at height H is generated from the IAVL version H-1, so it must be verified against
+// the app hash committed in the header at height H. Therefore the proof height must be equal to the
+// queried height, and the destination light client must have (or be given in the same transaction)
+// a consensus state at exactly the proof height.
+func (a proofHeightAudit) violations() []string {
+	var v []string
+
+	if a.proofHeight.IsZero() {
+		return append(v, "proof height is zero")
+	}
+
+	if a.queriedHeight != 0 && a.proofHeight.RevisionHeight != a.queriedHeight {
+		v = append(v, fmt.Sprintf(
+			"proof height %d does not equal queried height %d",
+			a.proofHeight.RevisionHeight, a.queriedHeight,
+		))
+	}
+
+	if a.updateHeaderHeight != 0 && a.proofHeight.RevisionHeight > a.updateHeaderHeight {
+		v = append(v, fmt.Sprintf(
+			"proof height %d is ahead of client update header height %d",
+			a.proofHeight.RevisionHeight, a.updateHeaderHeight,
+		))
+	}
+
+	// without a known update header height, the client may be given a consensus state at any height.
+	if a.updateHeaderHeight != 0 &&
+		a.proofHeight.RevisionHeight != a.updateHeaderHeight &&
+		!a.proofHeight.EQ(a.clientConsensusHeight) {
+		v = append(v, fmt.Sprintf(
+			"no consensus state will exist at proof height %s (client consensus height: %s, update header height: %d)",
+			a.proofHeight, a.clientConsensusHeight, a.updateHeaderHeight,
+		))
+	}
+
+	return v
+}
+
+// auditProofHeight logs the heights used to assemble a message for dst with a proof from src,
+// then logs an error for each expected height relationship that does not hold.
+// It is a no-op unless proof height auditing has been enabled for the path.
+func auditProofHeight(
+	eventType string,
+	queriedHeight uint64,
+	proofHeight clienttypes.Height,
+	src, dst *pathEndRuntime,
+) {
+	if !dst.auditProofHeights {
+		return
+	}
+
+	a := proofHeightAudit{
+		eventType:             eventType,
+		queriedHeight:         queriedHeight,
+		proofHeight:           proofHeight,
+		clientConsensusHeight: dst.clientState.ConsensusHeight,
+	}
+	if src.latestHeader != nil {
+		a.updateHeaderHeight = src.latestHeader.Height()
+	}
+
+	fields := []zap.Field{
+		zap.String("event_type", a.eventType),
+		zap.String("src_chain_id", src.info.ChainID),
+		zap.String("dst_client_id", dst.info.ClientID),
+		zap.Uint64("queried_height", a.queriedHeight),
+		zap.String("proof_height", a.proofHeight.String()),
+		zap.String("client_consensus_height", a.clientConsensusHeight.String()),
+		zap.Uint64("update_header_height", a.updateHeaderHeight),
+	}
+
+	dst.log.Info("Proof height audit", fields...)
+
+	for _, v := range a.violations() {
+		dst.log.Error("Proof height audit failed", append(fields, zap.String("violation", v))...)
+	}
+}
+
+// checkProofHeight returns an error if proof was not queried at the height of the consensus state which the
+// client update of mp installs on the destination, e.g. if a newer header of the source was observed in between.
+// Messages without a client update, and proofs without a proof height, are not checked.
+func (mp *messageProcessor) checkProofHeight(proof any) error {
+	if len(mp.msgsUpdateClient) == 0 {
+		return nil
+	}
+	var proofHeight clienttypes.Height
+	switch p := proof.(type) {
+	case provider.PacketProof:
+		proofHeight = p.ProofHeight
+	case provider.ChannelProof:
+		proofHeight = p.ProofHeight
+	case provider.ChannelUpgradeProof:
+		proofHeight = p.ProofHeight
+	case provider.ConnectionProof:
+		proofHeight = p.ProofHeight
+	default:
+		return nil
+	}
+	if proofHeight.RevisionHeight != mp.proofHeight {
+		return fmt.Errorf("%w: proof height %s, client update height %d", errProofHeightMismatch, proofHeight, mp.proofHeight)
+	}
+	return nil
+}
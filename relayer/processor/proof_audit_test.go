@@ -0,0 +1,227 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProofHeightAuditViolations(t *testing.T) {
+	h := func(height uint64) clienttypes.Height {
+		return clienttypes.NewHeight(1, height)
+	}
+
+	// proof at queried height, verified against the bundled client update
+	require.Empty(t, proofHeightAudit{
+		queriedHeight:         100,
+		proofHeight:           h(100),
+		clientConsensusHeight: h(90),
+		updateHeaderHeight:    100,
+	}.violations())
+
+	// proof at queried height, client already has a consensus state at the proof height
+	require.Empty(t, proofHeightAudit{
+		queriedHeight:         100,
+		proofHeight:           h(100),
+		clientConsensusHeight: h(100),
+		updateHeaderHeight:    101,
+	}.violations())
+
+	// proof height one ahead of the queried and update header heights
+	require.Len(t, proofHeightAudit{
+		queriedHeight:         100,
+		proofHeight:           h(101),
+		clientConsensusHeight: h(90),
+		updateHeaderHeight:    100,
+	}.violations(), 3)
+
+	// proof height behind the update header height without a consensus state
+	require.Len(t, proofHeightAudit{
+		queriedHeight:         99,
+		proofHeight:           h(99),
+		clientConsensusHeight: h(90),
+		updateHeaderHeight:    100,
+	}.violations(), 1)
+
+	// message sent without a bundled client update, so no consensus state can be checked
+	require.Empty(t, proofHeightAudit{
+		queriedHeight:         100,
+		proofHeight:           h(100),
+		clientConsensusHeight: h(90),
+	}.violations())
+
+	require.Len(t, proofHeightAudit{}.violations(), 1)
+}
+
+func TestCheckProofHeight(t *testing.T) {
+	mp := &messageProcessor{proofHeight: 100}
+
+	// without a client update, the proof is verified against an existing consensus state.
+	require.NoError(t, mp.checkProofHeight(provider.PacketProof{ProofHeight: clienttypes.NewHeight(1, 99)}))
+
+	mp.msgsUpdateClient = []provider.RelayerMessage{mockRelayerMessage{}}
+	require.NoError(t, mp.checkProofHeight(provider.PacketProof{ProofHeight: clienttypes.NewHeight(1, 100)}))
+	require.NoError(t, mp.checkProofHeight(provider.ICQProof{Height: 99}))
+	require.NoError(t, mp.checkProofHeight(nil))
+	require.ErrorIs(t, mp.checkProofHeight(provider.PacketProof{ProofHeight: clienttypes.NewHeight(1, 101)}), errProofHeightMismatch)
+	require.ErrorIs(t, mp.checkProofHeight(provider.ChannelProof{ProofHeight: clienttypes.NewHeight(1, 99)}), errProofHeightMismatch)
+	require.ErrorIs(t, mp.checkProofHeight(provider.ConnectionProof{ProofHeight: clienttypes.NewHeight(1, 101)}), errProofHeightMismatch)
+	require.ErrorIs(t, mp.checkProofHeight(provider.ChannelUpgradeProof{ProofHeight: clienttypes.NewHeight(1, 101)}), errProofHeightMismatch)
+}
+
+// clientUpdateTestProvider builds client updates and packet messages, with the packet proofs
+// returned offset from the queried heights.
+type clientUpdateTestProvider struct {
+	provider.ChainProvider
+
+	proofHeightOffset uint64
+	queriedHeights    []uint64
+	updateHeights     []uint64
+}
+
+func (p *clientUpdateTestProvider) MsgUpdateClientHeader(
+	latestHeader provider.IBCHeader,
+	_ clienttypes.Height,
+	_ provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	p.updateHeights = append(p.updateHeights, latestHeader.Height())
+	return nil, nil
+}
+
+func (p *clientUpdateTestProvider) MsgUpdateClient(string, ibcexported.ClientMessage) (provider.RelayerMessage, error) {
+	return mockRelayerMessage{}, nil
+}
+
+func (p *clientUpdateTestProvider) PacketCommitment(_ context.Context, _ provider.PacketInfo, height uint64) (provider.PacketProof, error) {
+	p.queriedHeights = append(p.queriedHeights, height)
+	return provider.PacketProof{ProofHeight: clienttypes.NewHeight(1, height+p.proofHeightOffset)}, nil
+}
+
+func (p *clientUpdateTestProvider) MsgRecvPacket(provider.PacketInfo, provider.PacketProof) (provider.RelayerMessage, error) {
+	return mockRelayerMessage{}, nil
+}
+
+// TestClientUpdateProofHeight checks that the proofs of the messages sent with a client update are queried at the
+// height of the header the client is updated to, even when a newer block of the source has been observed since.
+func TestClientUpdateProofHeight(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		proofHeightOffset uint64
+		expectedErr       error
+	}{
+		{name: "proof at the client update height"},
+		{name: "proof at a newer height", proofHeightOffset: 1, expectedErr: errProofHeightMismatch},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srcProvider := &clientUpdateTestProvider{proofHeightOffset: tc.proofHeightOffset}
+			dstProvider := &clientUpdateTestProvider{}
+			trustedHeight := clienttypes.NewHeight(1, 10)
+			src := &pathEndRuntime{
+				log:           zap.NewNop(),
+				info:          PathEnd{PathName: "demo-path", ChainID: "chain-a"},
+				chainProvider: srcProvider,
+				clientState:   provider.ClientState{ClientID: "07-tendermint-0"},
+				// the latest block is newer than the latest header, which the client is updated to.
+				latestBlock:  provider.LatestBlock{Height: 13},
+				latestHeader: proofTestHeader{height: 12},
+			}
+			dst := &pathEndRuntime{
+				log:           zap.NewNop(),
+				info:          PathEnd{PathName: "demo-path", ChainID: "chain-b", ClientID: "07-tendermint-0"},
+				chainProvider: dstProvider,
+				clientState:   provider.ClientState{ClientID: "07-tendermint-0", ConsensusHeight: trustedHeight},
+				clientTrustedState: provider.ClientTrustedState{
+					ClientState: provider.ClientState{ConsensusHeight: trustedHeight},
+					IBCHeader:   proofTestHeader{height: 11},
+				},
+			}
+
+			mp := &messageProcessor{log: zap.NewNop(), proofHeight: src.latestBlock.Height}
+			require.NoError(t, mp.assembleMsgUpdateClient(context.Background(), src, dst))
+			require.Equal(t, []uint64{12}, srcProvider.updateHeights)
+			require.Equal(t, uint64(12), mp.proofHeight)
+
+			msg := packetIBCMessage{eventType: chantypes.EventTypeRecvPacket, info: provider.PacketInfo{Sequence: 1}}
+			assembled, err := mp.assemble(context.Background(), msg, src, dst)
+			require.Equal(t, []uint64{12}, srcProvider.queriedHeights)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				stage, ok := FailedStage(err)
+				require.True(t, ok)
+				require.Equal(t, RelayStageProof, stage)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, assembled)
+		})
+	}
+}
+
+// recordingClientMessages records the heights of the client messages it builds.
+type recordingClientMessages struct {
+	heights []uint64
+}
+
+func (b *recordingClientMessages) ClientMessage(
+	_ provider.ChainProvider,
+	latestHeader provider.IBCHeader,
+	_ clienttypes.Height,
+	_ provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	b.heights = append(b.heights, latestHeader.Height())
+	return nil, nil
+}
+
+// TestClientUpdateClientMessages checks that client updates are built by the client message builder of the path end
+// of the client if it has one, and by the provider of the counterparty chain otherwise.
+func TestClientUpdateClientMessages(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		clientMessages *recordingClientMessages
+	}{
+		{name: "provider of the counterparty"},
+		{name: "client message builder", clientMessages: new(recordingClientMessages)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srcProvider := &clientUpdateTestProvider{}
+			trustedHeight := clienttypes.NewHeight(1, 10)
+			src := &pathEndRuntime{
+				log:           zap.NewNop(),
+				info:          PathEnd{PathName: "demo-path", ChainID: "chain-a"},
+				chainProvider: srcProvider,
+				latestBlock:   provider.LatestBlock{Height: 12},
+				latestHeader:  proofTestHeader{height: 12},
+			}
+			dstInfo := PathEnd{PathName: "demo-path", ChainID: "chain-b", ClientID: "08-wasm-0"}
+			if tc.clientMessages != nil {
+				dstInfo = dstInfo.WithClientMessages(tc.clientMessages)
+			}
+			dst := &pathEndRuntime{
+				log:           zap.NewNop(),
+				info:          dstInfo,
+				chainProvider: &clientUpdateTestProvider{},
+				clientState:   provider.ClientState{ClientID: "08-wasm-0", ConsensusHeight: trustedHeight},
+				clientTrustedState: provider.ClientTrustedState{
+					ClientState: provider.ClientState{ConsensusHeight: trustedHeight},
+					IBCHeader:   proofTestHeader{height: 11},
+				},
+			}
+
+			mp := &messageProcessor{log: zap.NewNop(), proofHeight: src.latestBlock.Height}
+			require.NoError(t, mp.assembleMsgUpdateClient(context.Background(), src, dst))
+			require.Len(t, mp.msgsUpdateClient, 1)
+			if tc.clientMessages != nil {
+				require.Equal(t, []uint64{12}, tc.clientMessages.heights)
+				require.Empty(t, srcProvider.updateHeights)
+				return
+			}
+			require.Equal(t, []uint64{12}, srcProvider.updateHeights)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// verifyPacketProof verifies the proof of the packet message for the counterparty, queried from this chain,
+// as core IBC on the counterparty will: the commitment of the packet for MsgRecvPacket, the commitment of its
+// acknowledgement for MsgAcknowledgement, and the absence of its receipt or its next sequence to receive for
+// MsgTimeout.
+func (pathEnd *pathEndRuntime) verifyPacketProof(
+	ctx context.Context,
+	eventType string,
+	info provider.PacketInfo,
+	proof provider.PacketProof,
+) error {
+	switch eventType {
+	case chantypes.EventTypeRecvPacket:
+		key := host.PacketCommitmentKey(info.SourcePort, info.SourceChannel, info.Sequence)
+		return pathEnd.verifyMembership(ctx, key, chantypes.CommitPacket(nil, info.Packet()), proof.Proof, proof.ProofHeight)
+	case chantypes.EventTypeAcknowledgePacket:
+		key := host.PacketAcknowledgementKey(info.DestPort, info.DestChannel, info.Sequence)
+		return pathEnd.verifyMembership(ctx, key, chantypes.CommitAcknowledgement(info.Ack), proof.Proof, proof.ProofHeight)
+	case chantypes.EventTypeTimeoutPacket:
+		if info.ChannelOrder == chantypes.ORDERED.String() {
+			key := host.NextSequenceRecvKey(info.DestPort, info.DestChannel)
+			return pathEnd.verifyMembership(ctx, key, nil, proof.Proof, proof.ProofHeight)
+		}
+		key := host.PacketReceiptKey(info.DestPort, info.DestChannel, info.Sequence)
+		return pathEnd.verifyNonMembership(ctx, key, proof.Proof, proof.ProofHeight)
+	}
+	return nil
+}
+
+// verifyChannelProof verifies the proof of the channel end of a channel handshake message for the counterparty,
+// queried from this chain.
+func (pathEnd *pathEndRuntime) verifyChannelProof(ctx context.Context, info provider.ChannelInfo, proof provider.ChannelProof) error {
+	key := host.ChannelKey(info.PortID, info.ChannelID)
+	return pathEnd.verifyMembership(ctx, key, nil, proof.Proof, proof.ProofHeight)
+}
+
+// verifyConnectionProof verifies the proofs of the connection end, and of the client state and consensus state
+// of the counterparty if included, of a connection handshake message for the counterparty, queried from this chain.
+func (pathEnd *pathEndRuntime) verifyConnectionProof(ctx context.Context, info provider.ConnectionInfo, proof provider.ConnectionProof) error {
+	if err := pathEnd.verifyMembership(ctx, host.ConnectionKey(info.ConnID), nil, proof.ConnectionStateProof, proof.ProofHeight); err != nil {
+		return err
+	}
+	if len(proof.ClientStateProof) == 0 || proof.ClientState == nil {
+		return nil
+	}
+	if err := pathEnd.verifyMembership(ctx, host.FullClientStateKey(info.ClientID), nil, proof.ClientStateProof, proof.ProofHeight); err != nil {
+		return err
+	}
+	consensusStateKey := host.FullConsensusStateKey(info.ClientID, proof.ClientState.GetLatestHeight())
+	return pathEnd.verifyMembership(ctx, consensusStateKey, nil, proof.ConsensusStateProof, proof.ProofHeight)
+}
+
+// verifyMembership verifies the proof of the value of the key in the IBC store of this chain against the commitment
+// root of the header of this chain at the proof height, which the counterparty client verifies the proof against.
+// If value is nil, the proof is verified for the value it holds, as long as it is a proof of the key.
+func (pathEnd *pathEndRuntime) verifyMembership(
+	ctx context.Context,
+	key, value, proofBz []byte,
+	proofHeight clienttypes.Height,
+) error {
+	proof, root, path, err := pathEnd.proofToVerify(ctx, key, proofBz, proofHeight)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		exist := proof.Proofs[0].GetExist()
+		if exist == nil {
+			return fmt.Errorf("proof of key %s at height %s is not a proof of existence", key, proofHeight)
+		}
+		if !bytes.Equal(exist.Key, key) {
+			return fmt.Errorf("proof of key %s at height %s is a proof of key %s", key, proofHeight, exist.Key)
+		}
+		value = exist.Value
+	}
+	if err := proof.VerifyMembership(commitmenttypes.GetSDKSpecs(), root, path, value); err != nil {
+		return pathEnd.invalidProofError(key, proof, root, proofHeight, err)
+	}
+	return nil
+}
+
+// verifyNonMembership verifies the proof of the absence of the key in the IBC store of this chain against the
+// commitment root of the header of this chain at the proof height.
+func (pathEnd *pathEndRuntime) verifyNonMembership(
+	ctx context.Context,
+	key, proofBz []byte,
+	proofHeight clienttypes.Height,
+) error {
+	proof, root, path, err := pathEnd.proofToVerify(ctx, key, proofBz, proofHeight)
+	if err != nil {
+		return err
+	}
+	if err := proof.VerifyNonMembership(commitmenttypes.GetSDKSpecs(), root, path); err != nil {
+		return pathEnd.invalidProofError(key, proof, root, proofHeight, err)
+	}
+	return nil
+}
+
+// proofToVerify decodes the proof of the key, and returns it with the commitment root of the header of this chain
+// at the proof height and the merkle path of the key.
+func (pathEnd *pathEndRuntime) proofToVerify(
+	ctx context.Context,
+	key, proofBz []byte,
+	proofHeight clienttypes.Height,
+) (commitmenttypes.MerkleProof, ibcexported.Root, commitmenttypes.MerklePath, error) {
+	var proof commitmenttypes.MerkleProof
+	if err := proof.Unmarshal(proofBz); err != nil {
+		return proof, nil, commitmenttypes.MerklePath{}, fmt.Errorf("failed to decode proof of key %s at height %s: %w", key, proofHeight, err)
+	}
+	if len(proof.Proofs) == 0 {
+		return proof, nil, commitmenttypes.MerklePath{}, fmt.Errorf("proof of key %s at height %s is empty", key, proofHeight)
+	}
+
+	header, err := pathEnd.header(ctx, proofHeight.RevisionHeight)
+	if err != nil {
+		return proof, nil, commitmenttypes.MerklePath{}, fmt.Errorf("failed to get header at proof height %s to verify proof: %w", proofHeight, err)
+	}
+	rooted, ok := header.ConsensusState().(interface{ GetRoot() ibcexported.Root })
+	if !ok {
+		return proof, nil, commitmenttypes.MerklePath{}, fmt.Errorf("consensus state of header at proof height %s has no commitment root", proofHeight)
+	}
+
+	path, err := commitmenttypes.ApplyPrefix(pathEnd.chainProvider.CommitmentPrefix(), commitmenttypes.NewMerklePath(string(key)))
+	if err != nil {
+		return proof, nil, commitmenttypes.MerklePath{}, err
+	}
+	return proof, rooted.GetRoot(), path, nil
+}
+
+// invalidProofError describes a proof which failed verification, with the root it was verified against and the root
+// which the proof commits to, which differ if the proof was queried for another height than its proof height.
+func (pathEnd *pathEndRuntime) invalidProofError(
+	key []byte,
+	proof commitmenttypes.MerkleProof,
+	root ibcexported.Root,
+	proofHeight clienttypes.Height,
+	err error,
+) error {
+	proofRoot, calcErr := proof.Proofs[len(proof.Proofs)-1].Calculate()
+	if calcErr != nil {
+		proofRoot = nil
+	}
+	return fmt.Errorf("proof of key %s is invalid against the app hash %X of the header of chain %s at proof height %s "+
+		"(root of proof: %X), the counterparty would reject it: %w",
+		key, root.GetHash(), pathEnd.info.ChainID, proofHeight, proofRoot, err)
+}
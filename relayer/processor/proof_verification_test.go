@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	ibctm "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	ics23 "github.com/cosmos/ics23/go"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+type proofTestHeader struct {
+	height  uint64
+	appHash []byte
+}
+
+func (h proofTestHeader) Height() uint64 { return h.height }
+func (h proofTestHeader) ConsensusState() ibcexported.ConsensusState {
+	return ibctm.NewConsensusState(time.Unix(0, 0), commitmenttypes.NewMerkleRoot(h.appHash), nil)
+}
+func (h proofTestHeader) NextValidatorsHash() []byte { return nil }
+
+type proofTestProvider struct {
+	provider.ChainProvider
+}
+
+func (proofTestProvider) CommitmentPrefix() commitmenttypes.MerklePrefix {
+	return commitmenttypes.NewMerklePrefix([]byte(ibcexported.StoreKey))
+}
+
+// existenceProof returns an ICS-23 existence proof of the key and value, as the only leaf of an IAVL tree
+// or of the multistore of the app when prefixed accordingly.
+func existenceProof(key, value, prefix []byte) *ics23.CommitmentProof {
+	return &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{
+		Key:   key,
+		Value: value,
+		Leaf: &ics23.LeafOp{
+			Hash:         ics23.HashOp_SHA256,
+			PrehashValue: ics23.HashOp_SHA256,
+			Length:       ics23.LengthOp_VAR_PROTO,
+			Prefix:       prefix,
+		},
+	}}}
+}
+
+// storeProof returns the proof of the key and value in the IBC store, and the app hash it commits to.
+func storeProof(t *testing.T, key, value []byte) ([]byte, []byte) {
+	iavlProof := existenceProof(key, value, []byte{0, 2, 2})
+	storeRoot, err := iavlProof.Calculate()
+	require.NoError(t, err)
+	multistoreProof := existenceProof([]byte(ibcexported.StoreKey), storeRoot, []byte{0})
+	appHash, err := multistoreProof.Calculate()
+	require.NoError(t, err)
+
+	proof := commitmenttypes.MerkleProof{Proofs: []*ics23.CommitmentProof{iavlProof, multistoreProof}}
+	proofBz, err := proof.Marshal()
+	require.NoError(t, err)
+	return proofBz, appHash
+}
+
+func TestVerifyPacketProof(t *testing.T) {
+	ctx := context.Background()
+	info := provider.PacketInfo{
+		Sequence:         1,
+		SourcePort:       "transfer",
+		SourceChannel:    "channel-0",
+		DestPort:         "transfer",
+		DestChannel:      "channel-1",
+		Data:             []byte("data"),
+		TimeoutTimestamp: 1,
+	}
+	key := host.PacketCommitmentKey(info.SourcePort, info.SourceChannel, info.Sequence)
+	proofBz, appHash := storeProof(t, key, chantypes.CommitPacket(nil, info.Packet()))
+
+	pathEnd := &pathEndRuntime{
+		info:          PathEnd{ChainID: "chain-a"},
+		chainProvider: proofTestProvider{},
+		headers:       NewHeaderSynchronizer(nil),
+	}
+	pathEnd.headers.Update(IBCHeaderCache{10: proofTestHeader{height: 10, appHash: appHash}})
+	proof := provider.PacketProof{Proof: proofBz, ProofHeight: clienttypes.NewHeight(0, 10)}
+
+	require.NoError(t, pathEnd.verifyPacketProof(ctx, chantypes.EventTypeRecvPacket, info, proof))
+
+	// the commitment of other packet data is not proven
+	altered := info
+	altered.Data = []byte("other data")
+	err := pathEnd.verifyPacketProof(ctx, chantypes.EventTypeRecvPacket, altered, proof)
+	require.ErrorContains(t, err, "is invalid against the app hash")
+
+	// the proof commits to another app hash than the header at the proof height
+	pathEnd.headers.Update(IBCHeaderCache{11: proofTestHeader{height: 11, appHash: []byte("other app hash")}})
+	proof.ProofHeight = clienttypes.NewHeight(0, 11)
+	err = pathEnd.verifyPacketProof(ctx, chantypes.EventTypeRecvPacket, info, proof)
+	require.ErrorContains(t, err, "is invalid against the app hash")
+
+	err = pathEnd.verifyPacketProof(ctx, chantypes.EventTypeRecvPacket, info, provider.PacketProof{ProofHeight: proof.ProofHeight})
+	require.ErrorContains(t, err, "is empty")
+}
+
+func TestVerifyChannelProof(t *testing.T) {
+	ctx := context.Background()
+	info := provider.ChannelInfo{PortID: "transfer", ChannelID: "channel-0"}
+	key := host.ChannelKey(info.PortID, info.ChannelID)
+	proofBz, appHash := storeProof(t, key, []byte("channel end"))
+
+	pathEnd := &pathEndRuntime{
+		info:          PathEnd{ChainID: "chain-a"},
+		chainProvider: proofTestProvider{},
+		headers:       NewHeaderSynchronizer(nil),
+	}
+	pathEnd.headers.Update(IBCHeaderCache{10: proofTestHeader{height: 10, appHash: appHash}})
+	proof := provider.ChannelProof{Proof: proofBz, ProofHeight: clienttypes.NewHeight(0, 10)}
+
+	require.NoError(t, pathEnd.verifyChannelProof(ctx, info, proof))
+
+	// the proof is of another channel
+	info.ChannelID = "channel-1"
+	err := pathEnd.verifyChannelProof(ctx, info, proof)
+	require.ErrorContains(t, err, "is a proof of key")
+}
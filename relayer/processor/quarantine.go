@@ -0,0 +1,116 @@
+package processor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// QuarantinedPacket is a packet flow message which was isolated by bisecting a failed batch as the cause of
+// its failure. The PathProcessor no longer relays it, until it is retried through the control API.
+type QuarantinedPacket struct {
+	ChainID   string    `json:"chain_id"`
+	EventType string    `json:"event_type"`
+	Sequence  uint64    `json:"sequence"`
+	Time      time.Time `json:"time"`
+	Error     string    `json:"error"`
+
+	SourceChannelID string `json:"source_channel_id"`
+	SourcePortID    string `json:"source_port_id"`
+	DestChannelID   string `json:"dest_channel_id"`
+	DestPortID      string `json:"dest_port_id"`
+}
+
+// packetQuarantine holds the packet flow messages to send to a chain which are quarantined.
+// It is safe for concurrent use, since messages are quarantined from the callbacks of their transactions.
+type packetQuarantine struct {
+	mu      sync.Mutex
+	packets map[packetQueueKey]QuarantinedPacket
+}
+
+func (q *packetQuarantine) add(k packetQueueKey, p QuarantinedPacket) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.packets == nil {
+		q.packets = make(map[packetQueueKey]QuarantinedPacket)
+	}
+	q.packets[k] = p
+}
+
+func (q *packetQuarantine) contains(k packetQueueKey) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.packets[k]
+	return ok
+}
+
+// release removes the messages of the packet with sequence, sent on either end of the channel, from quarantine.
+func (q *packetQuarantine) release(channelID, portID string, sequence uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for k := range q.packets {
+		if k.sequence != sequence {
+			continue
+		}
+		if (k.channel.ChannelID == channelID && k.channel.PortID == portID) ||
+			(k.channel.CounterpartyChannelID == channelID && k.channel.CounterpartyPortID == portID) {
+			delete(q.packets, k)
+		}
+	}
+}
+
+// list returns the quarantined messages, ordered by source channel and sequence.
+func (q *packetQuarantine) list() []QuarantinedPacket {
+	q.mu.Lock()
+	packets := make([]QuarantinedPacket, 0, len(q.packets))
+	for _, p := range q.packets {
+		packets = append(packets, p)
+	}
+	q.mu.Unlock()
+
+	sort.Slice(packets, func(i, j int) bool {
+		a, b := packets[i], packets[j]
+		if a.SourceChannelID != b.SourceChannelID {
+			return a.SourceChannelID < b.SourceChannelID
+		}
+		if a.Sequence != b.Sequence {
+			return a.Sequence < b.Sequence
+		}
+		return a.EventType < b.EventType
+	})
+	return packets
+}
+
+// quarantinePacket quarantines the packet flow message of t, whose failure to be sent to this chain with err
+// was isolated by bisecting a failed batch, so that it no longer holds back the batches of the other messages.
+func (pathEnd *pathEndRuntime) quarantinePacket(t packetMessageToTrack, err error) {
+	k, kErr := t.msg.channelKey()
+	if kErr != nil {
+		return
+	}
+	info := t.msg.info
+	pathEnd.quarantine.add(packetQueueKey{eventType: t.msg.eventType, channel: k, sequence: info.Sequence}, QuarantinedPacket{
+		ChainID:         pathEnd.info.ChainID,
+		EventType:       t.msg.eventType,
+		Sequence:        info.Sequence,
+		Time:            time.Now(),
+		Error:           err.Error(),
+		SourceChannelID: info.SourceChannel,
+		SourcePortID:    info.SourcePort,
+		DestChannelID:   info.DestChannel,
+		DestPortID:      info.DestPort,
+	})
+	pathEnd.log.Error("Quarantining packet message which fails on its own, will not relay it until it is retried",
+		zap.String("event_type", t.msg.eventType),
+		zap.Uint64("sequence", info.Sequence),
+		zap.Inline(k),
+		zap.Error(err),
+	)
+}
+
+// isQuarantined returns true if the packet flow message with eventType and sequence on channel k is quarantined.
+func (pathEnd *pathEndRuntime) isQuarantined(eventType string, k ChannelKey, sequence uint64) bool {
+	return pathEnd.quarantine.contains(packetQueueKey{eventType: eventType, channel: k, sequence: sequence})
+}
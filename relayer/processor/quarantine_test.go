@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPacketQuarantine(t *testing.T) {
+	pathEnd := newPathEndRuntime(zap.NewNop(), PathEnd{PathName: "demo-path", ChainID: "chain-b"}, nil)
+
+	msg := packetIBCMessage{
+		eventType: chantypes.EventTypeRecvPacket,
+		info: provider.PacketInfo{
+			Sequence:      7,
+			SourceChannel: "channel-0",
+			SourcePort:    "transfer",
+			DestChannel:   "channel-1",
+			DestPort:      "transfer",
+		},
+	}
+	k, err := msg.channelKey()
+	require.NoError(t, err)
+
+	pathEnd.quarantinePacket(packetMessageToTrack{msg: msg}, errors.New("app rejected packet"))
+	require.True(t, pathEnd.isQuarantined(chantypes.EventTypeRecvPacket, k, 7))
+	require.False(t, pathEnd.isQuarantined(chantypes.EventTypeRecvPacket, k, 8))
+	require.False(t, pathEnd.shouldSendPacketMessage(msg, pathEnd))
+
+	quarantined := pathEnd.quarantine.list()
+	require.Len(t, quarantined, 1)
+	require.Equal(t, "chain-b", quarantined[0].ChainID)
+	require.Equal(t, "app rejected packet", quarantined[0].Error)
+
+	// a packet is released by the channel of either chain.
+	pathEnd.quarantine.release("channel-1", "transfer", 7)
+	require.False(t, pathEnd.isQuarantined(chantypes.EventTypeRecvPacket, k, 7))
+	require.Empty(t, pathEnd.quarantine.list())
+}
@@ -0,0 +1,123 @@
+package processor
+
+import (
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// publishPacketObserved publishes the observation of the packet event in a block of this chain.
+func (pathEnd *pathEndRuntime) publishPacketObserved(eventType string, p provider.PacketInfo) {
+	if pathEnd.relayEvents == nil {
+		return
+	}
+	pathEnd.relayEvents.Publish(eventstream.Event{
+		Type:       eventstream.TypePacketObserved,
+		PathName:   pathEnd.info.PathName,
+		ChainID:    pathEnd.info.ChainID,
+		Height:     p.Height,
+		EventType:  eventType,
+		Sequence:   p.Sequence,
+		SrcChannel: p.SourceChannel,
+		SrcPort:    p.SourcePort,
+		DstChannel: p.DestChannel,
+		DstPort:    p.DestPort,
+	})
+}
+
+// relayEvent returns the event of the message of the tracker, sent to this chain.
+func (pathEnd *pathEndRuntime) relayEvent(eventType eventstream.Type, t messageToTrack) eventstream.Event {
+	e := eventstream.Event{
+		Type:     eventType,
+		PathName: pathEnd.info.PathName,
+		ChainID:  pathEnd.info.ChainID,
+		Height:   pathEnd.latestBlock.Height,
+	}
+	if msg := t.assembledMsg(); msg != nil {
+		e.MsgType = msg.Type()
+	}
+	switch t := t.(type) {
+	case packetMessageToTrack:
+		e.EventType = t.msg.eventType
+		e.Sequence = t.msg.info.Sequence
+		e.SrcChannel, e.SrcPort = t.msg.info.SourceChannel, t.msg.info.SourcePort
+		e.DstChannel, e.DstPort = t.msg.info.DestChannel, t.msg.info.DestPort
+	case channelMessageToTrack:
+		e.EventType = t.msg.eventType
+		e.SrcChannel, e.SrcPort = t.msg.info.ChannelID, t.msg.info.PortID
+		e.DstChannel, e.DstPort = t.msg.info.CounterpartyChannelID, t.msg.info.CounterpartyPortID
+	case connectionMessageToTrack:
+		e.EventType = t.msg.eventType
+		e.ConnectionID = t.msg.info.ConnID
+		e.CounterpartyConnectionID = t.msg.info.CounterpartyConnID
+	}
+	return e
+}
+
+// publishMsgBuilt publishes the assembly of the message of the tracker for this chain.
+func (pathEnd *pathEndRuntime) publishMsgBuilt(t messageToTrack) {
+	if pathEnd.relayEvents == nil {
+		return
+	}
+	pathEnd.relayEvents.Publish(pathEnd.relayEvent(eventstream.TypeMsgBuilt, t))
+}
+
+// txEvents returns the events of the messages of a transaction to this chain. The messages of the trackers
+// are the last of msgs, preceded by client updates, if any.
+func (pathEnd *pathEndRuntime) txEvents(
+	eventType eventstream.Type,
+	msgs []provider.RelayerMessage,
+	trackers []messageToTrack,
+) []eventstream.Event {
+	events := make([]eventstream.Event, 0, len(msgs))
+	for _, msg := range msgs[:len(msgs)-len(trackers)] {
+		events = append(events, eventstream.Event{
+			Type:     eventType,
+			PathName: pathEnd.info.PathName,
+			ChainID:  pathEnd.info.ChainID,
+			Height:   pathEnd.latestBlock.Height,
+			MsgType:  msg.Type(),
+		})
+	}
+	for _, t := range trackers {
+		events = append(events, pathEnd.relayEvent(eventType, t))
+	}
+	return events
+}
+
+// publishBroadcast publishes the broadcast of a transaction of msgs to this chain.
+func (pathEnd *pathEndRuntime) publishBroadcast(msgs []provider.RelayerMessage, trackers []messageToTrack) {
+	if pathEnd.relayEvents == nil {
+		return
+	}
+	for _, e := range pathEnd.txEvents(eventstream.TypeBroadcast, msgs, trackers) {
+		pathEnd.relayEvents.Publish(e)
+	}
+}
+
+// publishTxResult publishes the confirmation or failure of a transaction of msgs to this chain.
+// The response is nil if the broadcast failed or the inclusion of the transaction could not be observed.
+func (pathEnd *pathEndRuntime) publishTxResult(
+	msgs []provider.RelayerMessage,
+	trackers []messageToTrack,
+	rtr *provider.RelayerTxResponse,
+	err error,
+) {
+	if pathEnd.relayEvents == nil {
+		return
+	}
+	eventType := eventstream.TypeConfirmed
+	if err != nil {
+		eventType = eventstream.TypeFailed
+	}
+	for _, e := range pathEnd.txEvents(eventType, msgs, trackers) {
+		if rtr != nil {
+			e.Height = uint64(rtr.Height)
+			e.TxHash = rtr.TxHash
+		}
+		if err != nil {
+			e.Failure = provider.ClassifyTxFailure(err).String()
+			e.Error = err.Error()
+		}
+		pathEnd.relayEvents.Publish(e)
+	}
+}
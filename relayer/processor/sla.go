@@ -0,0 +1,187 @@
+package processor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+const (
+	// slaWindow is the period over which the SLA statistics of a path are computed.
+	slaWindow = 24 * time.Hour
+
+	// slaTargetLatency is the latency within which packets are expected to be relayed for the SLA statistics.
+	slaTargetLatency = time.Minute
+
+	// slaMaxSamples bounds how many relayed packets are retained for the SLA statistics of a path.
+	slaMaxSamples = 10000
+)
+
+// SLAStats are rolling statistics over the last day of how promptly a PathProcessor relays packets. The latency
+// of a packet is the time from when the send_packet event of the packet is observed until the acknowledge_packet
+// or timeout_packet event for it is observed on the source chain. A stall is a period during which packets were
+// pending but none of them were relayed.
+type SLAStats struct {
+	PathName      string  `json:"path_name"`
+	WindowSeconds float64 `json:"window_seconds"`
+
+	PacketsRelayed int `json:"packets_relayed"`
+	PacketsPending int `json:"packets_pending"`
+
+	MedianLatencySeconds       float64 `json:"median_latency_seconds"`
+	RelayedWithinMinutePercent float64 `json:"relayed_within_minute_percent"`
+	LongestStallSeconds        float64 `json:"longest_stall_seconds"`
+}
+
+type slaPacketKey struct {
+	chainID  string
+	channel  ChannelKey
+	sequence uint64
+}
+
+// slaSample is a latency or stall which ended at a time.
+type slaSample struct {
+	end      time.Time
+	duration time.Duration
+}
+
+// slaTracker keeps the SLA statistics of a path. It is shared by both path ends of the PathProcessor.
+type slaTracker struct {
+	mu sync.Mutex
+
+	// when the packets yet to be relayed were observed.
+	pending map[slaPacketKey]time.Time
+
+	latencies []slaSample
+	stalls    []slaSample
+
+	// the start of the current stall, zero if no packets are pending.
+	stallStart time.Time
+}
+
+func newSLATracker() *slaTracker {
+	return &slaTracker{
+		pending: make(map[slaPacketKey]time.Time),
+	}
+}
+
+// track records the packet flow messages newly observed on chainID at now.
+func (t *slaTracker) track(chainID string, packetFlow ChannelPacketMessagesCache, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for ch, pmc := range packetFlow {
+		for seq := range pmc[chantypes.EventTypeSendPacket] {
+			k := slaPacketKey{chainID: chainID, channel: ch, sequence: seq}
+			if _, ok := t.pending[k]; ok {
+				continue
+			}
+			if len(t.pending) == 0 {
+				t.stallStart = now
+			}
+			t.pending[k] = now
+		}
+
+		for _, eventType := range []string{chantypes.EventTypeAcknowledgePacket, chantypes.EventTypeTimeoutPacket} {
+			for seq := range pmc[eventType] {
+				k := slaPacketKey{chainID: chainID, channel: ch, sequence: seq}
+				observed, ok := t.pending[k]
+				if !ok {
+					// the packet was sent before the relayer was in sync, so its latency is unknown.
+					continue
+				}
+				delete(t.pending, k)
+				t.latencies = append(t.latencies, slaSample{end: now, duration: now.Sub(observed)})
+				t.endStall(now)
+			}
+		}
+	}
+
+	t.prune(now)
+}
+
+// endStall records the current stall as ended at now, and starts the next one if packets are still pending.
+func (t *slaTracker) endStall(now time.Time) {
+	if !t.stallStart.IsZero() {
+		t.stalls = append(t.stalls, slaSample{end: now, duration: now.Sub(t.stallStart)})
+	}
+	t.stallStart = time.Time{}
+	if len(t.pending) > 0 {
+		t.stallStart = now
+	}
+}
+
+// prune drops the samples which ended before the window, and the packets pending for longer than the window,
+// which have likely been relayed by someone else.
+func (t *slaTracker) prune(now time.Time) {
+	cutoff := now.Add(-slaWindow)
+	t.latencies = pruneSLASamples(t.latencies, cutoff)
+	t.stalls = pruneSLASamples(t.stalls, cutoff)
+	for k, observed := range t.pending {
+		if observed.Before(cutoff) {
+			delete(t.pending, k)
+		}
+	}
+	if len(t.pending) == 0 {
+		t.stallStart = time.Time{}
+	} else if t.stallStart.Before(cutoff) {
+		t.stallStart = cutoff
+	}
+}
+
+func pruneSLASamples(samples []slaSample, cutoff time.Time) []slaSample {
+	i := sort.Search(len(samples), func(i int) bool {
+		return !samples[i].end.Before(cutoff)
+	})
+	if n := len(samples) - i; n > slaMaxSamples {
+		i = len(samples) - slaMaxSamples
+	}
+	return samples[i:]
+}
+
+// stats returns the SLA statistics at now.
+func (t *slaTracker) stats(pathName string, now time.Time) SLAStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(now)
+
+	stats := SLAStats{
+		PathName:       pathName,
+		WindowSeconds:  slaWindow.Seconds(),
+		PacketsRelayed: len(t.latencies),
+		PacketsPending: len(t.pending),
+	}
+
+	if len(t.latencies) > 0 {
+		latencies := make([]time.Duration, len(t.latencies))
+		withinTarget := 0
+		for i, s := range t.latencies {
+			latencies[i] = s.duration
+			if s.duration <= slaTargetLatency {
+				withinTarget++
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		median := latencies[len(latencies)/2]
+		if len(latencies)%2 == 0 {
+			median = (latencies[len(latencies)/2-1] + median) / 2
+		}
+		stats.MedianLatencySeconds = median.Seconds()
+		stats.RelayedWithinMinutePercent = 100 * float64(withinTarget) / float64(len(latencies))
+	}
+
+	var longestStall time.Duration
+	for _, s := range t.stalls {
+		longestStall = max(longestStall, s.duration)
+	}
+	if !t.stallStart.IsZero() {
+		longestStall = max(longestStall, now.Sub(t.stallStart))
+	}
+	stats.LongestStallSeconds = longestStall.Seconds()
+
+	return stats
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLAStats(t *testing.T) {
+	k := ChannelKey{ChannelID: "channel-0", PortID: "transfer", CounterpartyChannelID: "channel-1", CounterpartyPortID: "transfer"}
+	observed := func(eventType string, seqs ...uint64) ChannelPacketMessagesCache {
+		c := make(PacketSequenceCache)
+		for _, seq := range seqs {
+			c[seq] = provider.PacketInfo{Sequence: seq}
+		}
+		return ChannelPacketMessagesCache{k: PacketMessagesCache{eventType: c}}
+	}
+
+	tracker := newSLATracker()
+	start := time.Now()
+
+	tracker.track("chain-a", observed(chantypes.EventTypeSendPacket, 1, 2), start)
+	tracker.track("chain-a", observed(chantypes.EventTypeAcknowledgePacket, 1), start.Add(30*time.Second))
+	// packets sent before the relayer was in sync are not counted.
+	tracker.track("chain-a", observed(chantypes.EventTypeAcknowledgePacket, 7), start.Add(time.Minute))
+	tracker.track("chain-a", observed(chantypes.EventTypeTimeoutPacket, 2), start.Add(3*time.Minute))
+
+	stats := tracker.stats("demo-path", start.Add(3*time.Minute))
+	require.Equal(t, "demo-path", stats.PathName)
+	require.Equal(t, 2, stats.PacketsRelayed)
+	require.Zero(t, stats.PacketsPending)
+	require.Equal(t, 105.0, stats.MedianLatencySeconds)
+	require.Equal(t, 50.0, stats.RelayedWithinMinutePercent)
+	require.Equal(t, 150.0, stats.LongestStallSeconds)
+
+	// a packet pending without progress is an ongoing stall.
+	tracker.track("chain-a", observed(chantypes.EventTypeSendPacket, 3), start.Add(4*time.Minute))
+	stats = tracker.stats("demo-path", start.Add(10*time.Minute))
+	require.Equal(t, 1, stats.PacketsPending)
+	require.Equal(t, 360.0, stats.LongestStallSeconds)
+
+	// statistics only cover the window.
+	stats = tracker.stats("demo-path", start.Add(slaWindow+5*time.Minute))
+	require.Zero(t, stats.PacketsRelayed)
+	require.Zero(t, stats.PacketsPending)
+	require.Zero(t, stats.MedianLatencySeconds)
+	require.Zero(t, stats.LongestStallSeconds)
+}
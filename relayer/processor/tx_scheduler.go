@@ -0,0 +1,155 @@
+package processor
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// TxScheduler allocates the transactions broadcast to a single chain in each block among the paths relaying
+// to the chain, which share the signer of the chain. Paths waiting to broadcast are granted the budget of
+// the block in proportion to their weight, scaled up sublinearly by the backlog of packets of the path,
+// so a path with a large backlog is granted more of the budget, but cannot starve the other paths.
+// A nil TxScheduler does not limit broadcasts.
+type TxScheduler struct {
+	txsPerBlock int
+
+	mu      sync.Mutex
+	height  uint64
+	granted int
+	paths   map[string]*scheduledPath
+
+	// closed and replaced whenever a waiting path may be granted a broadcast.
+	wake chan struct{}
+}
+
+// scheduledPath is the share of a path in the budget of the TxScheduler.
+type scheduledPath struct {
+	weight  int
+	backlog int
+	granted int
+	waiting int
+}
+
+// NewTxScheduler returns a TxScheduler which grants at most txsPerBlock broadcasts per block,
+// or nil if txsPerBlock is not positive.
+func NewTxScheduler(txsPerBlock int) *TxScheduler {
+	if txsPerBlock <= 0 {
+		return nil
+	}
+	return &TxScheduler{
+		txsPerBlock: txsPerBlock,
+		paths:       make(map[string]*scheduledPath),
+		wake:        make(chan struct{}),
+	}
+}
+
+// path returns the share of pathName, which has a weight of 1 until it is set.
+func (s *TxScheduler) path(pathName string) *scheduledPath {
+	p, ok := s.paths[pathName]
+	if !ok {
+		p = &scheduledPath{weight: 1}
+		s.paths[pathName] = p
+	}
+	return p
+}
+
+// setBacklog sets the weight of pathName and how many packet messages of the path await broadcast.
+func (s *TxScheduler) setBacklog(pathName string, weight, backlog int) {
+	if s == nil {
+		return
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.path(pathName)
+	if p.weight == weight && p.backlog == backlog {
+		return
+	}
+	p.weight, p.backlog = weight, backlog
+	s.wakeLocked()
+}
+
+// newBlock resets the budget once a block above the last observed height is observed on the chain.
+func (s *TxScheduler) newBlock(height uint64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if height <= s.height {
+		return
+	}
+	s.height = height
+	s.granted = 0
+	for _, p := range s.paths {
+		p.granted = 0
+	}
+	s.wakeLocked()
+}
+
+// acquire blocks until pathName is granted a broadcast from the budget of the current block, or the context is done.
+func (s *TxScheduler) acquire(ctx context.Context, pathName string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	p := s.path(pathName)
+	p.waiting++
+	for {
+		if s.granted < s.txsPerBlock && s.nextLocked() == p {
+			p.waiting--
+			p.granted++
+			s.granted++
+			// the next waiting path may be granted the remaining budget.
+			s.wakeLocked()
+			s.mu.Unlock()
+			return nil
+		}
+		wake := s.wake
+		s.mu.Unlock()
+
+		select {
+		case <-wake:
+			s.mu.Lock()
+		case <-ctx.Done():
+			s.mu.Lock()
+			p.waiting--
+			s.wakeLocked()
+			s.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+// nextLocked returns the waiting path which is furthest behind its share of the budget, i.e. with
+// the lowest grants in the current block relative to its share. Ties are broken by path name.
+func (s *TxScheduler) nextLocked() *scheduledPath {
+	var (
+		next     *scheduledPath
+		nextName string
+		nextCost float64
+	)
+	for name, p := range s.paths {
+		if p.waiting == 0 {
+			continue
+		}
+		cost := float64(p.granted+1) / p.share()
+		if next == nil || cost < nextCost || (cost == nextCost && name < nextName) {
+			next, nextName, nextCost = p, name, cost
+		}
+	}
+	return next
+}
+
+// share is the weight of the path scaled by the logarithm of its backlog.
+func (p *scheduledPath) share() float64 {
+	return float64(p.weight) * (1 + math.Log2(1+float64(p.backlog)))
+}
+
+func (s *TxScheduler) wakeLocked() {
+	close(s.wake)
+	s.wake = make(chan struct{})
+}
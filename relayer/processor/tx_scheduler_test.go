@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxSchedulerNil(t *testing.T) {
+	s := NewTxScheduler(0)
+	require.Nil(t, s)
+	require.NoError(t, s.acquire(context.Background(), "path"))
+	s.setBacklog("path", 1, 10)
+	s.newBlock(1)
+}
+
+func TestTxSchedulerBudget(t *testing.T) {
+	ctx := context.Background()
+	s := NewTxScheduler(2)
+	s.newBlock(1)
+
+	require.NoError(t, s.acquire(ctx, "path"))
+	require.NoError(t, s.acquire(ctx, "path"))
+
+	// the budget of the block is spent.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.acquire(timeoutCtx, "path"), context.DeadlineExceeded)
+
+	acquired := make(chan error)
+	go func() { acquired <- s.acquire(ctx, "path") }()
+
+	// observing the same block again does not reset the budget.
+	s.newBlock(1)
+	select {
+	case <-acquired:
+		t.Fatal("acquired beyond the budget of the block")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.newBlock(2)
+	require.NoError(t, <-acquired)
+}
+
+func TestTxSchedulerNext(t *testing.T) {
+	s := NewTxScheduler(13)
+	s.newBlock(1)
+
+	spammy := s.path("spammy")
+	quiet := s.path("quiet")
+	spammy.waiting, quiet.waiting = 1, 1
+
+	s.setBacklog("spammy", 1, 1000)
+	s.setBacklog("quiet", 1, 1)
+
+	// the spammy path has a larger share, but not 1000 times larger.
+	grants := map[*scheduledPath]int{}
+	for i := 0; i < 13; i++ {
+		next := s.nextLocked()
+		next.granted++
+		grants[next]++
+	}
+	require.Greater(t, grants[spammy], grants[quiet])
+	require.GreaterOrEqual(t, grants[quiet], 2)
+
+	// weights scale the shares.
+	s.newBlock(2)
+	s.setBacklog("spammy", 1, 0)
+	s.setBacklog("quiet", 3, 0)
+	grants = map[*scheduledPath]int{}
+	for i := 0; i < 8; i++ {
+		next := s.nextLocked()
+		next.granted++
+		grants[next]++
+	}
+	require.Equal(t, 2, grants[spammy])
+	require.Equal(t, 6, grants[quiet])
+
+	// paths which are not waiting are not granted.
+	quiet.waiting = 0
+	require.Equal(t, spammy, s.nextLocked())
+	spammy.waiting = 0
+	require.Nil(t, s.nextLocked())
+}
@@ -52,6 +52,11 @@ type ConnectionMessage struct {
 type ConnectionMessageLifecycle struct {
 	Initial     *ConnectionMessage
 	Termination *ConnectionMessage
+
+	// TerminationFilter, if set, must also accept an observed termination message for the PathProcessor to stop,
+	// e.g. to stop only on the connection of a handshake rather than on any between the same clients.
+	// It is called from the goroutine of the PathProcessor.
+	TerminationFilter func(provider.ConnectionInfo) bool
 }
 
 func (t *ConnectionMessageLifecycle) messageLifecycler() {}
@@ -68,6 +73,11 @@ type ChannelMessage struct {
 type ChannelMessageLifecycle struct {
 	Initial     *ChannelMessage
 	Termination *ChannelMessage
+
+	// TerminationFilter, if set, must also accept an observed termination message for the PathProcessor to stop,
+	// e.g. to stop only on the channel of a handshake rather than on any between the same ports.
+	// It is called from the goroutine of the PathProcessor.
+	TerminationFilter func(provider.ChannelInfo) bool
 }
 
 func (t *ChannelMessageLifecycle) messageLifecycler() {}
@@ -436,6 +446,16 @@ func (c ChannelPacketMessagesCache) IsCached(eventType string, k ChannelKey, seq
 	return true
 }
 
+// hasEventType returns true if messages of the event type are cached for any channel.
+func (c ChannelPacketMessagesCache) hasEventType(eventType string) bool {
+	for _, messages := range c {
+		if len(messages[eventType]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // Cache stores packet info safely, generating intermediate maps along the way if necessary.
 func (c ChannelPacketMessagesCache) Cache(
 	eventType string,
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
@@ -28,10 +29,11 @@ type pathEndMessages struct {
 	clientICQMessages  []clientICQMessage
 }
 
+// ibcMessage is a message relayed from src to dst, which is assembled by the proof and build stages of the relay
+// pipeline, see RelayStage.
 type ibcMessage interface {
-	// assemble executes the appropriate proof query function,
-	// then, if successful, assembles the message for the destination.
-	assemble(ctx context.Context, src, dst *pathEndRuntime) (provider.RelayerMessage, error)
+	proofMessage
+	buildMessage
 
 	// tracker creates a message tracker for message status
 	tracker(assembled provider.RelayerMessage) messageToTrack
@@ -50,29 +52,25 @@ type packetIBCMessage struct {
 	eventType string
 }
 
-// assemble executes the appropriate proof query function,
-// then, if successful, assembles the packet message for the destination.
-func (msg packetIBCMessage) assemble(
+// proof queries the proof of the packet on src at height which the packet message for dst is built from,
+// and verifies it if proof verification is enabled.
+func (msg packetIBCMessage) proof(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
-) (provider.RelayerMessage, error) {
+	height uint64,
+) (any, error) {
 	var packetProof func(context.Context, provider.PacketInfo, uint64) (provider.PacketProof, error)
-	var assembleMessage func(provider.PacketInfo, provider.PacketProof) (provider.RelayerMessage, error)
 	switch msg.eventType {
 	case chantypes.EventTypeRecvPacket:
 		packetProof = src.chainProvider.PacketCommitment
-		assembleMessage = dst.chainProvider.MsgRecvPacket
 	case chantypes.EventTypeAcknowledgePacket:
 		packetProof = src.chainProvider.PacketAcknowledgement
-		assembleMessage = dst.chainProvider.MsgAcknowledgement
 	case chantypes.EventTypeTimeoutPacket:
 		if msg.info.ChannelOrder == chantypes.ORDERED.String() {
 			packetProof = src.chainProvider.NextSeqRecv
 		} else {
 			packetProof = src.chainProvider.PacketReceipt
 		}
-
-		assembleMessage = dst.chainProvider.MsgTimeout
 	default:
 		return nil, fmt.Errorf("unexpected packet message eventType for message assembly: %s", msg.eventType)
 	}
@@ -83,13 +81,40 @@ func (msg packetIBCMessage) assemble(
 	ctx, cancel := context.WithTimeout(ctx, packetProofQueryTimeout)
 	defer cancel()
 
-	var proof provider.PacketProof
-	var err error
-	proof, err = packetProof(ctx, msg.info, src.latestBlock.Height)
+	proof, err := packetProof(ctx, msg.info, height)
 	if err != nil {
 		return nil, fmt.Errorf("error querying packet proof: %w", err)
 	}
-	return assembleMessage(msg.info, proof)
+	if src.clientState.ClientID != ibcexported.LocalhostClientID {
+		auditProofHeight(msg.eventType, height, proof.ProofHeight, src, dst)
+
+		if msg.eventType == chantypes.EventTypeTimeoutPacket {
+			if err := src.checkTimeoutProofHeight(msg.info, proof.ProofHeight); err != nil {
+				return nil, err
+			}
+		}
+
+		if src.verifyProofs {
+			if err := src.verifyPacketProof(ctx, msg.eventType, msg.info, proof); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return proof, nil
+}
+
+// build builds the packet message for dst from the proof of the packet on src.
+func (msg packetIBCMessage) build(_, dst *pathEndRuntime, proof any) (provider.RelayerMessage, error) {
+	packetProof, _ := proof.(provider.PacketProof)
+	switch msg.eventType {
+	case chantypes.EventTypeRecvPacket:
+		return dst.chainProvider.MsgRecvPacket(msg.info, packetProof)
+	case chantypes.EventTypeAcknowledgePacket:
+		return dst.chainProvider.MsgAcknowledgement(msg.info, packetProof)
+	case chantypes.EventTypeTimeoutPacket:
+		return dst.chainProvider.MsgTimeout(msg.info, packetProof)
+	}
+	return nil, fmt.Errorf("unexpected packet message eventType for message assembly: %s", msg.eventType)
 }
 
 // tracker creates a message tracker for message status
@@ -121,6 +146,9 @@ func (msg packetIBCMessage) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	))
 	enc.AddUint64("timeout_timestamp", msg.info.TimeoutTimestamp)
 	enc.AddString("data", base64.StdEncoding.EncodeToString(msg.info.Data))
+	if memo, ok := msg.info.ICS20Memo(); ok {
+		enc.AddString("memo", memo)
+	}
 	enc.AddString("ack", base64.StdEncoding.EncodeToString(msg.info.Ack))
 	return nil
 }
@@ -138,33 +166,42 @@ type channelIBCMessage struct {
 	info      provider.ChannelInfo
 }
 
-// assemble executes the appropriate proof query function,
-// then, if successful, assembles the message for the destination.
-func (msg channelIBCMessage) assemble(
+// withConnectionHops returns msg with the connection hops of dst, over which the channel is opened on dst,
+// unless the message already has them. The event on src does not include them.
+func (msg channelIBCMessage) withConnectionHops(dst *pathEndRuntime) channelIBCMessage {
+	switch msg.eventType {
+	case chantypes.EventTypeChannelOpenInit:
+		if len(msg.info.ConnectionHops) == 0 {
+			msg.info.ConnectionHops = dst.info.ConnectionHops
+		}
+	case chantypes.EventTypeChannelOpenTry:
+		if len(msg.info.CounterpartyConnectionHops) == 0 {
+			msg.info.CounterpartyConnectionHops = dst.info.ConnectionHops
+		}
+	}
+	return msg
+}
+
+// proof queries the proof of the channel on src at height which the channel message for dst is built from,
+// and verifies it if proof verification is enabled. Channel inits and close inits need no proof.
+func (msg channelIBCMessage) proof(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
-) (provider.RelayerMessage, error) {
+	height uint64,
+) (any, error) {
+	msg = msg.withConnectionHops(dst)
 	var chanProof func(context.Context, provider.ChannelInfo, uint64) (provider.ChannelProof, error)
-	var assembleMessage func(provider.ChannelInfo, provider.ChannelProof) (provider.RelayerMessage, error)
 	switch msg.eventType {
-	case chantypes.EventTypeChannelOpenInit:
-		// don't need proof for this message
-		assembleMessage = dst.chainProvider.MsgChannelOpenInit
-	case chantypes.EventTypeChannelOpenTry:
-		chanProof = src.chainProvider.ChannelProof
-		assembleMessage = dst.chainProvider.MsgChannelOpenTry
-	case chantypes.EventTypeChannelOpenAck:
-		chanProof = src.chainProvider.ChannelProof
-		assembleMessage = dst.chainProvider.MsgChannelOpenAck
-	case chantypes.EventTypeChannelOpenConfirm:
-		chanProof = src.chainProvider.ChannelProof
-		assembleMessage = dst.chainProvider.MsgChannelOpenConfirm
-	case chantypes.EventTypeChannelCloseInit:
+	case chantypes.EventTypeChannelOpenInit, chantypes.EventTypeChannelCloseInit:
 		// don't need proof for this message
-		assembleMessage = dst.chainProvider.MsgChannelCloseInit
-	case chantypes.EventTypeChannelCloseConfirm:
+		return nil, nil
+	case chantypes.EventTypeChannelOpenTry, chantypes.EventTypeChannelOpenAck,
+		chantypes.EventTypeChannelOpenConfirm, chantypes.EventTypeChannelCloseConfirm:
 		chanProof = src.chainProvider.ChannelProof
-		assembleMessage = dst.chainProvider.MsgChannelCloseConfirm
+	case chantypes.EventTypeChannelUpgradeTry, chantypes.EventTypeChannelUpgradeAck,
+		chantypes.EventTypeChannelUpgradeConfirm, chantypes.EventTypeChannelUpgradeOpen,
+		chantypes.EventTypeChannelUpgradeTimeout, chantypes.EventTypeChannelUpgradeCancel:
+		return msg.upgradeProof(ctx, src, dst, height)
 	default:
 		return nil, fmt.Errorf("unexpected channel message eventType for message assembly: %s", msg.eventType)
 	}
@@ -172,15 +209,84 @@ func (msg channelIBCMessage) assemble(
 		chanProof = src.localhostSentinelProofChannel
 	}
 
-	var proof provider.ChannelProof
-	var err error
-	if chanProof != nil {
-		proof, err = chanProof(ctx, msg.info, src.latestBlock.Height)
-		if err != nil {
-			return nil, fmt.Errorf("error querying channel proof: %w", err)
+	proof, err := chanProof(ctx, msg.info, height)
+	if err != nil {
+		return nil, fmt.Errorf("error querying channel proof: %w", err)
+	}
+	if src.clientState.ClientID != ibcexported.LocalhostClientID {
+		auditProofHeight(msg.eventType, height, proof.ProofHeight, src, dst)
+
+		if src.verifyProofs {
+			if err := src.verifyChannelProof(ctx, msg.info, proof); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return assembleMessage(msg.info, proof)
+	return proof, nil
+}
+
+// build builds the channel message for dst from the proof of the channel on src.
+func (msg channelIBCMessage) build(_, dst *pathEndRuntime, proof any) (provider.RelayerMessage, error) {
+	msg = msg.withConnectionHops(dst)
+	if upgradeProof, ok := proof.(provider.ChannelUpgradeProof); ok {
+		return msg.buildUpgrade(dst, upgradeProof)
+	}
+	chanProof, _ := proof.(provider.ChannelProof)
+	switch msg.eventType {
+	case chantypes.EventTypeChannelOpenInit:
+		return dst.chainProvider.MsgChannelOpenInit(msg.info, chanProof)
+	case chantypes.EventTypeChannelOpenTry:
+		return dst.chainProvider.MsgChannelOpenTry(msg.info, chanProof)
+	case chantypes.EventTypeChannelOpenAck:
+		return dst.chainProvider.MsgChannelOpenAck(msg.info, chanProof)
+	case chantypes.EventTypeChannelOpenConfirm:
+		return dst.chainProvider.MsgChannelOpenConfirm(msg.info, chanProof)
+	case chantypes.EventTypeChannelCloseInit:
+		return dst.chainProvider.MsgChannelCloseInit(msg.info, chanProof)
+	case chantypes.EventTypeChannelCloseConfirm:
+		return dst.chainProvider.MsgChannelCloseConfirm(msg.info, chanProof)
+	}
+	return nil, fmt.Errorf("unexpected channel message eventType for message assembly: %s", msg.eventType)
+}
+
+// upgradeProof queries the proof of the channel upgrade on src at height which the channel upgrade message for dst
+// is built from.
+func (msg channelIBCMessage) upgradeProof(
+	ctx context.Context,
+	src, dst *pathEndRuntime,
+	height uint64,
+) (any, error) {
+	upgradeProof := src.chainProvider.ChannelUpgradeProof
+	if msg.eventType == chantypes.EventTypeChannelUpgradeCancel {
+		upgradeProof = src.chainProvider.ChannelUpgradeErrorProof
+	}
+
+	proof, err := upgradeProof(ctx, msg.info, height)
+	if err != nil {
+		return nil, fmt.Errorf("error querying channel upgrade proof: %w", err)
+	}
+	auditProofHeight(msg.eventType, height, proof.ProofHeight, src, dst)
+
+	return proof, nil
+}
+
+// buildUpgrade builds the channel upgrade message for dst from the proof of the channel upgrade on src.
+func (msg channelIBCMessage) buildUpgrade(dst *pathEndRuntime, proof provider.ChannelUpgradeProof) (provider.RelayerMessage, error) {
+	switch msg.eventType {
+	case chantypes.EventTypeChannelUpgradeTry:
+		return dst.chainProvider.MsgChannelUpgradeTry(msg.info, proof)
+	case chantypes.EventTypeChannelUpgradeAck:
+		return dst.chainProvider.MsgChannelUpgradeAck(msg.info, proof)
+	case chantypes.EventTypeChannelUpgradeConfirm:
+		return dst.chainProvider.MsgChannelUpgradeConfirm(msg.info, proof)
+	case chantypes.EventTypeChannelUpgradeOpen:
+		return dst.chainProvider.MsgChannelUpgradeOpen(msg.info, proof)
+	case chantypes.EventTypeChannelUpgradeTimeout:
+		return dst.chainProvider.MsgChannelUpgradeTimeout(msg.info, proof)
+	case chantypes.EventTypeChannelUpgradeCancel:
+		return dst.chainProvider.MsgChannelUpgradeCancel(msg.info, proof)
+	}
+	return nil, fmt.Errorf("unexpected channel upgrade message eventType for message assembly: %s", msg.eventType)
 }
 
 // tracker creates a message tracker for message status
@@ -206,8 +312,17 @@ func (msg channelIBCMessage) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddString("counterparty_channel_id", msg.info.CounterpartyChannelID)
 	enc.AddString("connection_id", msg.info.ConnID)
 	enc.AddString("counterparty_connection_id", msg.info.CounterpartyConnID)
+	if len(msg.info.ConnectionHops) > 0 {
+		enc.AddString("connection_hops", strings.Join(msg.info.ConnectionHops, ","))
+	}
+	if len(msg.info.CounterpartyConnectionHops) > 0 {
+		enc.AddString("counterparty_connection_hops", strings.Join(msg.info.CounterpartyConnectionHops, ","))
+	}
 	enc.AddString("order", msg.info.Order.String())
 	enc.AddString("version", msg.info.Version)
+	if msg.info.UpgradeSequence != 0 {
+		enc.AddUint64("upgrade_sequence", msg.info.UpgradeSequence)
+	}
 	return nil
 }
 
@@ -218,43 +333,66 @@ type connectionIBCMessage struct {
 	info      provider.ConnectionInfo
 }
 
-// assemble executes the appropriate proof query function,
-// then, if successful, assembles the message for the destination.
-func (msg connectionIBCMessage) assemble(
+// withCounterpartyPrefix returns msg with the commitment prefix of src, which connection inits and tries for dst
+// include as the prefix of their counterparty.
+func (msg connectionIBCMessage) withCounterpartyPrefix(src *pathEndRuntime) connectionIBCMessage {
+	switch msg.eventType {
+	case conntypes.EventTypeConnectionOpenInit, conntypes.EventTypeConnectionOpenTry:
+		msg.info.CounterpartyCommitmentPrefix = src.chainProvider.CommitmentPrefix()
+	}
+	return msg
+}
+
+// proof queries the proof of the connection on src at height which the connection message for dst is built from,
+// and verifies it if proof verification is enabled. Connection inits need no proof.
+func (msg connectionIBCMessage) proof(
 	ctx context.Context,
 	src, dst *pathEndRuntime,
-) (provider.RelayerMessage, error) {
+	height uint64,
+) (any, error) {
+	msg = msg.withCounterpartyPrefix(src)
 	var connProof func(context.Context, provider.ConnectionInfo, uint64) (provider.ConnectionProof, error)
-	var assembleMessage func(provider.ConnectionInfo, provider.ConnectionProof) (provider.RelayerMessage, error)
 	switch msg.eventType {
 	case conntypes.EventTypeConnectionOpenInit:
 		// don't need proof for this message
-		msg.info.CounterpartyCommitmentPrefix = src.chainProvider.CommitmentPrefix()
-		assembleMessage = dst.chainProvider.MsgConnectionOpenInit
-	case conntypes.EventTypeConnectionOpenTry:
-		msg.info.CounterpartyCommitmentPrefix = src.chainProvider.CommitmentPrefix()
-		connProof = src.chainProvider.ConnectionHandshakeProof
-		assembleMessage = dst.chainProvider.MsgConnectionOpenTry
-	case conntypes.EventTypeConnectionOpenAck:
+		return nil, nil
+	case conntypes.EventTypeConnectionOpenTry, conntypes.EventTypeConnectionOpenAck:
 		connProof = src.chainProvider.ConnectionHandshakeProof
-		assembleMessage = dst.chainProvider.MsgConnectionOpenAck
 	case conntypes.EventTypeConnectionOpenConfirm:
 		connProof = src.chainProvider.ConnectionProof
-		assembleMessage = dst.chainProvider.MsgConnectionOpenConfirm
 	default:
 		return nil, fmt.Errorf("unexpected connection message eventType for message assembly: %s", msg.eventType)
 	}
 
-	var proof provider.ConnectionProof
-	var err error
-	if connProof != nil {
-		proof, err = connProof(ctx, msg.info, src.latestBlock.Height)
-		if err != nil {
-			return nil, fmt.Errorf("error querying connection proof: %w", err)
+	proof, err := connProof(ctx, msg.info, height)
+	if err != nil {
+		return nil, fmt.Errorf("error querying connection proof: %w", err)
+	}
+	auditProofHeight(msg.eventType, height, proof.ProofHeight, src, dst)
+
+	if src.verifyProofs {
+		if err := src.verifyConnectionProof(ctx, msg.info, proof); err != nil {
+			return nil, err
 		}
 	}
+	return proof, nil
+}
 
-	return assembleMessage(msg.info, proof)
+// build builds the connection message for dst from the proof of the connection on src.
+func (msg connectionIBCMessage) build(src, dst *pathEndRuntime, proof any) (provider.RelayerMessage, error) {
+	msg = msg.withCounterpartyPrefix(src)
+	connProof, _ := proof.(provider.ConnectionProof)
+	switch msg.eventType {
+	case conntypes.EventTypeConnectionOpenInit:
+		return dst.chainProvider.MsgConnectionOpenInit(msg.info, connProof)
+	case conntypes.EventTypeConnectionOpenTry:
+		return dst.chainProvider.MsgConnectionOpenTry(msg.info, connProof)
+	case conntypes.EventTypeConnectionOpenAck:
+		return dst.chainProvider.MsgConnectionOpenAck(msg.info, connProof)
+	case conntypes.EventTypeConnectionOpenConfirm:
+		return dst.chainProvider.MsgConnectionOpenConfirm(msg.info, connProof)
+	}
+	return nil, fmt.Errorf("unexpected connection message eventType for message assembly: %s", msg.eventType)
 }
 
 // tracker creates a message tracker for message status
@@ -293,21 +431,26 @@ type clientICQMessage struct {
 	info provider.ClientICQInfo
 }
 
-// assemble executes the query against the source chain,
-// then, if successful, assembles the response message for the destination.
-func (msg clientICQMessage) assemble(
+// proof executes the query against the source chain as of height, returning its result with the proof of it.
+func (msg clientICQMessage) proof(
 	ctx context.Context,
-	src, dst *pathEndRuntime,
-) (provider.RelayerMessage, error) {
+	src, _ *pathEndRuntime,
+	height uint64,
+) (any, error) {
 	ctx, cancel := context.WithTimeout(ctx, interchainQueryTimeout)
 	defer cancel()
 
-	proof, err := src.chainProvider.QueryICQWithProof(ctx, msg.info.Type, msg.info.Request, src.latestBlock.Height-1)
+	proof, err := src.chainProvider.QueryICQWithProof(ctx, msg.info.Type, msg.info.Request, height-1)
 	if err != nil {
 		return nil, fmt.Errorf("error during interchain query: %w", err)
 	}
+	return proof, nil
+}
 
-	return dst.chainProvider.MsgSubmitQueryResponse(msg.info.Chain, msg.info.QueryID, proof)
+// build builds the response message for dst from the result of the query.
+func (msg clientICQMessage) build(_, dst *pathEndRuntime, proof any) (provider.RelayerMessage, error) {
+	icqProof, _ := proof.(provider.ICQProof)
+	return dst.chainProvider.MsgSubmitQueryResponse(msg.info.Chain, msg.info.QueryID, icqProof)
 }
 
 // tracker creates a message tracker for message status
@@ -332,12 +475,22 @@ func (msg clientICQMessage) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	return nil
 }
 
+// finishedMessage is a message which finished processing, with the class of the failure of sending it if it failed.
+type finishedMessage struct {
+	tracker messageToTrack
+	failure provider.TxFailure
+}
+
 // processingMessage tracks the state of a IBC message currently being processed.
 type processingMessage struct {
 	retryCount          uint64
 	lastProcessedHeight uint64
+	lastProcessedTime   time.Time
 	assembled           bool
 
+	// failure is the class of the failure of the last attempt to send the message, if it failed.
+	failure provider.TxFailure
+
 	processing bool
 	mu         sync.Mutex
 }
@@ -356,10 +509,12 @@ func (m *processingMessage) setProcessing(assembled bool, retryCount uint64) {
 	m.assembled = assembled
 }
 
-func (m *processingMessage) setFinishedProcessing(height uint64) {
+func (m *processingMessage) setFinishedProcessing(block provider.LatestBlock, failure provider.TxFailure) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.lastProcessedHeight = height
+	m.lastProcessedHeight = block.Height
+	m.lastProcessedTime = block.Time
+	m.failure = failure
 	m.processing = false
 }
 
@@ -570,6 +725,25 @@ type pathEndChannelCloseMessages struct {
 	DstMsgChannelCloseConfirm ChannelMessageCache
 }
 
+type pathEndChannelUpgradeMessages struct {
+	Src                         *pathEndRuntime
+	Dst                         *pathEndRuntime
+	SrcMsgChannelUpgradeInit    ChannelMessageCache
+	SrcMsgChannelUpgradeTry     ChannelMessageCache
+	SrcMsgChannelUpgradeAck     ChannelMessageCache
+	SrcMsgChannelUpgradeConfirm ChannelMessageCache
+	SrcMsgChannelFlushComplete  ChannelMessageCache
+	SrcMsgChannelUpgradeOpen    ChannelMessageCache
+	SrcMsgChannelUpgradeError   ChannelMessageCache
+	DstMsgChannelUpgradeInit    ChannelMessageCache
+	DstMsgChannelUpgradeTry     ChannelMessageCache
+	DstMsgChannelUpgradeAck     ChannelMessageCache
+	DstMsgChannelUpgradeConfirm ChannelMessageCache
+	DstMsgChannelFlushComplete  ChannelMessageCache
+	DstMsgChannelUpgradeOpen    ChannelMessageCache
+	DstMsgChannelUpgradeError   ChannelMessageCache
+}
+
 type pathEndPacketFlowResponse struct {
 	SrcMessages []packetIBCMessage
 	DstMessages []packetIBCMessage
@@ -0,0 +1,146 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+)
+
+// hexKeyPrefix marks a store key given in hex, for keys which are not printable, e.g. those of the bank store.
+const hexKeyPrefix = "hex:"
+
+// StoreProof is the ICS-23 proof of the value of a key in a store of a chain, or of its absence if the value
+// is empty, verifiable against the commitment root of the consensus state of the chain at the proof height.
+type StoreProof struct {
+	ChainID     string             `json:"chain_id"`
+	Store       string             `json:"store"`
+	Key         []byte             `json:"key"`
+	Value       []byte             `json:"value,omitempty"`
+	Proof       []byte             `json:"proof"`
+	ProofHeight clienttypes.Height `json:"proof_height"`
+}
+
+// ParseStoreKey parses a store key given as a string, e.g. an ICS-24 path such as clients/07-tendermint-0/clientState,
+// or in hex with a "hex:" prefix.
+func ParseStoreKey(key string) ([]byte, error) {
+	if h, ok := strings.CutPrefix(key, hexKeyPrefix); ok {
+		bz, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex store key %s: %w", key, err)
+		}
+		return bz, nil
+	}
+	if key == "" {
+		return nil, fmt.Errorf("store key cannot be empty")
+	}
+	return []byte(key), nil
+}
+
+// QueryStoreProof queries the proof of the key in the store of the chain at the height, or the latest height if 0.
+func QueryStoreProof(ctx context.Context, c *Chain, store string, key []byte, height int64) (*StoreProof, error) {
+	cc, ok := c.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, fmt.Errorf("proof queries are not supported for chain %s of type %s", c.ChainID(), c.ChainProvider.Type())
+	}
+	value, proof, proofHeight, err := cc.QueryStoreProof(ctx, height, store, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query proof of key %q in store %s of chain %s: %w", key, store, c.ChainID(), err)
+	}
+	return &StoreProof{
+		ChainID:     c.ChainID(),
+		Store:       store,
+		Key:         key,
+		Value:       value,
+		Proof:       proof,
+		ProofHeight: proofHeight,
+	}, nil
+}
+
+// Verify verifies the proof of the value of the key, or of its absence if the value is empty, against the root.
+func (p *StoreProof) Verify(root ibcexported.Root) error {
+	var merkleProof commitmenttypes.MerkleProof
+	if err := merkleProof.Unmarshal(p.Proof); err != nil {
+		return fmt.Errorf("failed to decode merkle proof: %w", err)
+	}
+	path := commitmenttypes.NewMerklePath(p.Store, string(p.Key))
+	if len(p.Value) == 0 {
+		return merkleProof.VerifyNonMembership(commitmenttypes.GetSDKSpecs(), root, path)
+	}
+	return merkleProof.VerifyMembership(commitmenttypes.GetSDKSpecs(), root, path, p.Value)
+}
+
+// ProofVerification is the result of verifying a proof against the consensus state of a client.
+type ProofVerification struct {
+	ClientID string `json:"client_id"`
+
+	// ClientRoot is the commitment root of the consensus state of the client at the proof height.
+	ClientRoot []byte `json:"client_root"`
+
+	// ChainRoot is the app hash of the header of the proven chain at the proof height, which ClientRoot must match.
+	ChainRoot []byte `json:"chain_root"`
+
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// VerifyStoreProof verifies the proof of src against the commitment root of the consensus state at the proof
+// height of the client with clientID on dst, which must track src. An error is returned if the consensus state
+// cannot be queried, while a failed verification is described by the result.
+func VerifyStoreProof(ctx context.Context, src, dst *Chain, clientID string, proof *StoreProof) (*ProofVerification, error) {
+	res, err := dst.ChainProvider.QueryClientConsensusState(ctx, 0, clientID, proof.ProofHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consensus state of client %s on chain %s at height %s, "+
+			"which the client must have been updated to for the proof to be verified: %w",
+			clientID, dst.ChainID(), proof.ProofHeight, err)
+	}
+	consensusState, err := clienttypes.UnpackConsensusState(res.ConsensusState)
+	if err != nil {
+		return nil, err
+	}
+	clientRoot, err := commitmentRoot(consensusState)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := src.ChainProvider.QueryIBCHeader(ctx, int64(proof.ProofHeight.RevisionHeight))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query header of chain %s at height %d: %w", src.ChainID(), proof.ProofHeight.RevisionHeight, err)
+	}
+	chainRoot, err := commitmentRoot(header.ConsensusState())
+	if err != nil {
+		return nil, err
+	}
+
+	v := &ProofVerification{
+		ClientID:   clientID,
+		ClientRoot: clientRoot.GetHash(),
+		ChainRoot:  chainRoot.GetHash(),
+	}
+	if !bytes.Equal(v.ClientRoot, v.ChainRoot) {
+		v.Error = fmt.Sprintf("the consensus state of client %s at height %s does not match the header of chain %s, "+
+			"the client may track another chain or have been updated with a conflicting header", clientID, proof.ProofHeight, src.ChainID())
+		return v, nil
+	}
+	if err := proof.Verify(clientRoot); err != nil {
+		v.Error = err.Error()
+		return v, nil
+	}
+	v.Verified = true
+	return v, nil
+}
+
+// commitmentRoot returns the commitment root of the consensus state, for the types of consensus states which have one.
+func commitmentRoot(cs ibcexported.ConsensusState) (ibcexported.Root, error) {
+	rooted, ok := cs.(interface{ GetRoot() ibcexported.Root })
+	if !ok {
+		return nil, fmt.Errorf("consensus state of type %s has no commitment root", cs.ClientType())
+	}
+	return rooted.GetRoot(), nil
+}
@@ -0,0 +1,60 @@
+package relayer_test
+
+import (
+	"testing"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/store/metrics"
+	"cosmossdk.io/store/rootmulti"
+	storetypes "cosmossdk.io/store/types"
+	dbm "github.com/cosmos/cosmos-db"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStoreKey(t *testing.T) {
+	key, err := relayer.ParseStoreKey("clients/07-tendermint-0/clientState")
+	require.NoError(t, err)
+	require.Equal(t, []byte("clients/07-tendermint-0/clientState"), key)
+
+	key, err = relayer.ParseStoreKey("hex:0114c0ffee")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x14, 0xc0, 0xff, 0xee}, key)
+
+	_, err = relayer.ParseStoreKey("hex:xyz")
+	require.Error(t, err)
+	_, err = relayer.ParseStoreKey("")
+	require.Error(t, err)
+}
+
+func TestStoreProofVerify(t *testing.T) {
+	ms := rootmulti.NewStore(dbm.NewMemDB(), log.NewNopLogger(), metrics.NewNoOpMetrics())
+	storeKey := storetypes.NewKVStoreKey("ibc")
+	ms.MountStoreWithDB(storeKey, storetypes.StoreTypeIAVL, nil)
+	require.NoError(t, ms.LoadLatestVersion())
+	ms.GetCommitKVStore(storeKey).Set([]byte("clients/07-tendermint-0/clientState"), []byte("client state"))
+	root := commitmenttypes.NewMerkleRoot(ms.Commit().Hash)
+
+	proof := func(key string) *relayer.StoreProof {
+		res, err := ms.Query(&storetypes.RequestQuery{Path: "/ibc/key", Data: []byte(key), Prove: true})
+		require.NoError(t, err)
+		merkleProof, err := commitmenttypes.ConvertProofs(res.ProofOps)
+		require.NoError(t, err)
+		bz, err := merkleProof.Marshal()
+		require.NoError(t, err)
+		return &relayer.StoreProof{Store: "ibc", Key: []byte(key), Value: res.Value, Proof: bz}
+	}
+
+	p := proof("clients/07-tendermint-0/clientState")
+	require.NoError(t, p.Verify(root))
+
+	// the proof of a value does not prove another value.
+	p.Value = []byte("forged client state")
+	require.Error(t, p.Verify(root))
+
+	// the absence of a key is proven too.
+	require.NoError(t, proof("clients/07-tendermint-1/clientState").Verify(root))
+
+	require.Error(t, proof("clients/07-tendermint-0/clientState").Verify(commitmenttypes.NewMerkleRoot([]byte("wrong root"))))
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"encoding/json"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+// IBCAckErrorEventType is the event which the ibc-hooks middleware, and other osmosis middlewares, emit with the
+// full error of an error acknowledgement, since ibc-go only writes the ABCI code of the error to the acknowledgement.
+const IBCAckErrorEventType = "ibc-acknowledgement-error"
+
+// Attributes of IBCAckErrorEventType.
+const (
+	IBCAckErrorAttributeError   = "error"
+	IBCAckErrorAttributeContext = "error-context"
+)
+
+// WasmHook is the execution of a CosmWasm contract which the ibc-hooks middleware performs on the chain receiving
+// an ICS-20 transfer whose memo has a "wasm" key, with the transferred tokens as funds.
+type WasmHook struct {
+	Contract string          `json:"contract"`
+	Msg      json.RawMessage `json:"msg"`
+}
+
+type wasmHookMemo struct {
+	Wasm        *WasmHook `json:"wasm"`
+	IBCCallback string    `json:"ibc_callback"`
+}
+
+// packetHookMemo returns the ibc-hooks keys of the memo of the ICS-20 packet data,
+// or false if the packet is not an ICS-20 transfer or its memo is not a JSON object.
+func packetHookMemo(data []byte) (wasmHookMemo, bool) {
+	var packet transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(data, &packet); err != nil || packet.Memo == "" {
+		return wasmHookMemo{}, false
+	}
+	var memo wasmHookMemo
+	if err := json.Unmarshal([]byte(packet.Memo), &memo); err != nil {
+		return wasmHookMemo{}, false
+	}
+	return memo, true
+}
+
+// PacketWasmHook returns the contract execution which receiving the ICS-20 packet with the given data performs,
+// or false if it performs none.
+func PacketWasmHook(data []byte) (WasmHook, bool) {
+	memo, ok := packetHookMemo(data)
+	if !ok || memo.Wasm == nil || memo.Wasm.Contract == "" {
+		return WasmHook{}, false
+	}
+	return *memo.Wasm, true
+}
+
+// PacketWasmCallback returns the contract which the ibc-hooks middleware of the chain which sent the ICS-20 packet
+// with the given data calls back with its acknowledgement or timeout, or false if it calls back none.
+func PacketWasmCallback(data []byte) (string, bool) {
+	memo, ok := packetHookMemo(data)
+	if !ok || memo.IBCCallback == "" {
+		return "", false
+	}
+	return memo.IBCCallback, true
+}
+
+// WasmHookResult is the result acknowledgement which the ibc-hooks middleware writes for a packet whose contract
+// execution succeeded, wrapping the acknowledgement of the transfer.
+type WasmHookResult struct {
+	ContractResult []byte `json:"contract_result"`
+	IBCAck         []byte `json:"ibc_ack"`
+}
+
+// AcknowledgementWasmHookResult returns the result of the contract execution of a packet from its acknowledgement,
+// or false if the acknowledgement is not a result acknowledgement written by the ibc-hooks middleware.
+func AcknowledgementWasmHookResult(ack []byte) (WasmHookResult, bool) {
+	if len(ack) == 0 {
+		return WasmHookResult{}, false
+	}
+
+	var chanAck chantypes.Acknowledgement
+	if err := chantypes.SubModuleCdc.UnmarshalJSON(ack, &chanAck); err != nil {
+		return WasmHookResult{}, false
+	}
+
+	var res WasmHookResult
+	if err := json.Unmarshal(chanAck.GetResult(), &res); err != nil || res.IBCAck == nil {
+		return WasmHookResult{}, false
+	}
+	return res, true
+}
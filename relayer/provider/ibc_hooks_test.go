@@ -0,0 +1,56 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketWasmHook(t *testing.T) {
+	packetData := func(memo string) []byte {
+		return transfertypes.NewFungibleTokenPacketData("uosmo", "100", "cosmos1sender", "osmo1contract", memo).GetBytes()
+	}
+
+	hook, ok := provider.PacketWasmHook(packetData(`{"wasm":{"contract":"osmo1contract","msg":{"swap":{}}}}`))
+	require.True(t, ok)
+	require.Equal(t, "osmo1contract", hook.Contract)
+	require.JSONEq(t, `{"swap":{}}`, string(hook.Msg))
+
+	_, ok = provider.PacketWasmCallback(packetData(`{"wasm":{"contract":"osmo1contract","msg":{}}}`))
+	require.False(t, ok)
+
+	contract, ok := provider.PacketWasmCallback(packetData(`{"ibc_callback":"cosmos1contract"}`))
+	require.True(t, ok)
+	require.Equal(t, "cosmos1contract", contract)
+
+	for _, data := range [][]byte{
+		packetData(""),
+		packetData("not json"),
+		packetData(`{"forward":{"receiver":"osmo1receiver","port":"transfer","channel":"channel-1"}}`),
+		[]byte("not an ics-20 packet"),
+	} {
+		_, ok := provider.PacketWasmHook(data)
+		require.False(t, ok, string(data))
+	}
+}
+
+func TestAcknowledgementWasmHookResult(t *testing.T) {
+	ibcAck := chantypes.NewResultAcknowledgement([]byte{1}).Acknowledgement()
+	result, err := json.Marshal(provider.WasmHookResult{ContractResult: []byte(`{"amount":"42"}`), IBCAck: ibcAck})
+	require.NoError(t, err)
+
+	res, ok := provider.AcknowledgementWasmHookResult(chantypes.NewResultAcknowledgement(result).Acknowledgement())
+	require.True(t, ok)
+	require.Equal(t, `{"amount":"42"}`, string(res.ContractResult))
+	require.Equal(t, ibcAck, res.IBCAck)
+
+	_, ok = provider.AcknowledgementWasmHookResult(ibcAck)
+	require.False(t, ok)
+
+	_, ok = provider.AcknowledgementWasmHookResult(chantypes.NewErrorAcknowledgement(chantypes.ErrInvalidPacket).Acknowledgement())
+	require.False(t, ok)
+}
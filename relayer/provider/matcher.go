@@ -46,6 +46,8 @@ func ClientsMatch(ctx context.Context, src, dst ChainProvider, existingClient cl
 // CheckForMisbehaviour checks that a proposed header, for updating a light client, contains a consensus state that matches
 // the trusted consensus state from the counterparty for the same block height. If the consensus states for the proposed
 // header and the trusted header match then both returned values will be nil.
+// Headers of counterparty chains which implement MisbehaviourChecker are checked by the counterparty,
+// otherwise they are expected to be 07-tendermint headers.
 func CheckForMisbehaviour(
 	ctx context.Context,
 	counterparty ChainProvider,
@@ -53,6 +55,10 @@ func CheckForMisbehaviour(
 	proposedHeader []byte,
 	cachedHeader IBCHeader,
 ) (ibcexported.ClientMessage, error) {
+	if checker, ok := counterparty.(MisbehaviourChecker); ok {
+		return checker.CheckForMisbehaviour(ctx, clientID, proposedHeader, cachedHeader)
+	}
+
 	var (
 		misbehavior ibcexported.ClientMessage
 		err         error
@@ -0,0 +1,44 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+type tendermintProvider struct {
+	provider.ChainProvider
+}
+
+type grandpaProvider struct {
+	provider.ChainProvider
+	checked []byte
+}
+
+func (p *grandpaProvider) CheckForMisbehaviour(
+	_ context.Context,
+	_ string,
+	proposedHeader []byte,
+	_ provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	p.checked = proposedHeader
+	return nil, nil
+}
+
+func TestCheckForMisbehaviourClientTypes(t *testing.T) {
+	ctx := context.Background()
+	header := []byte("grandpa header")
+
+	// headers which are not 07-tendermint headers can only be checked by the counterparty.
+	_, err := provider.CheckForMisbehaviour(ctx, tendermintProvider{}, "08-wasm-0", header, nil)
+	require.Error(t, err)
+
+	counterparty := &grandpaProvider{}
+	misbehaviour, err := provider.CheckForMisbehaviour(ctx, counterparty, "08-wasm-0", header, nil)
+	require.NoError(t, err)
+	require.Nil(t, misbehaviour)
+	require.Equal(t, header, counterparty.checked)
+}
@@ -3,8 +3,10 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
+	cmtmath "github.com/cometbft/cometbft/libs/math"
 	"github.com/cometbft/cometbft/proto/tendermint/crypto"
 	"github.com/cometbft/cometbft/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -27,10 +29,54 @@ const (
 	BroadcastModeBatch  BroadcastMode = "batch"
 )
 
+// ClientUpdateMode determines how MsgUpdateClient is sent along with the IBC messages which depend on it.
+type ClientUpdateMode string
+
+const (
+	// ClientUpdateModeBundled prepends MsgUpdateClient to every tx of IBC messages.
+	ClientUpdateModeBundled ClientUpdateMode = "bundled"
+	// ClientUpdateModeSeparate sends MsgUpdateClient in its own tx ahead of the txs of IBC messages.
+	ClientUpdateModeSeparate ClientUpdateMode = "separate"
+)
+
+// BlockTimeout is how long a broadcast tx is waited on for inclusion in a block before it is treated as dropped
+// and its messages are sent again, either a duration or a number of blocks. The zero value is the default timeout.
+type BlockTimeout struct {
+	Duration time.Duration
+	Blocks   uint64
+}
+
+// ParseBlockTimeout parses a block timeout, either a duration, e.g. "90s", or a number of blocks, e.g. "20".
+// An empty string is the default timeout.
+func ParseBlockTimeout(s string) (BlockTimeout, error) {
+	if s == "" {
+		return BlockTimeout{}, nil
+	}
+	if blocks, err := strconv.ParseUint(s, 10, 64); err == nil {
+		if blocks == 0 {
+			return BlockTimeout{}, fmt.Errorf("block timeout must be at least one block")
+		}
+		return BlockTimeout{Blocks: blocks}, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return BlockTimeout{}, fmt.Errorf("block timeout %q is neither a duration nor a number of blocks", s)
+	}
+	if d <= 0 {
+		return BlockTimeout{}, fmt.Errorf("block timeout must be positive")
+	}
+	return BlockTimeout{Duration: d}, nil
+}
+
 type ProviderConfig interface {
 	NewProvider(log *zap.Logger, homepath string, debug bool, chainName string) (ChainProvider, error)
 	Validate() error
 	BroadcastMode() BroadcastMode
+	ClientUpdateMode() ClientUpdateMode
+	// MaxBatchMsgs is the maximum number of IBC messages in a single batch tx, or 0 for no limit.
+	MaxBatchMsgs() uint64
+	// TxInclusionTimeout is how long a broadcast tx is waited on for inclusion in a block.
+	TxInclusionTimeout() BlockTimeout
 }
 
 type RelayerMessage interface {
@@ -44,7 +90,11 @@ type RelayerTxResponse struct {
 	Codespace string
 	Code      uint32
 	Data      string
+	Log       string
 	Events    []RelayerEvent
+	GasWanted int64
+	GasUsed   int64
+	Fees      sdk.Coins
 }
 
 type RelayerEvent struct {
@@ -57,12 +107,25 @@ type LatestBlock struct {
 	Time   time.Time
 }
 
+// IBCHeader is a header of a chain, independent of the type of the light clients which track the chain.
 type IBCHeader interface {
 	Height() uint64
 	ConsensusState() ibcexported.ConsensusState
+	// NextValidatorsHash is the hash of the validator set for the next block, if the consensus of the chain has one.
+	// Chains without one return nil, and clients tracking them are not updated on validator set changes.
 	NextValidatorsHash() []byte
 }
 
+// MisbehaviourChecker is implemented by ChainProviders of chains which are tracked by light clients other than
+// 07-tendermint clients, e.g. GRANDPA or Ethereum sync committee clients, so that the client type agnostic code
+// which updates clients on counterparty chains can check the headers of these chains for misbehaviour.
+type MisbehaviourChecker interface {
+	// CheckForMisbehaviour checks that proposedHeader, an encoded ClientMessage of this chain used to update the
+	// client with the given ID on a counterparty chain, matches the header of this chain at the same height,
+	// which is cachedHeader unless nil. It returns the misbehaviour to submit if they conflict, otherwise nil.
+	CheckForMisbehaviour(ctx context.Context, clientID string, proposedHeader []byte, cachedHeader IBCHeader) (ibcexported.ClientMessage, error)
+}
+
 // ClientState holds the current state of a client from a single chain's perspective
 type ClientState struct {
 	ClientID        string
@@ -70,6 +133,9 @@ type ClientState struct {
 	TrustingPeriod  time.Duration
 	ConsensusTime   time.Time
 	Header          []byte
+
+	// TrustLevel is the trust level of the client, which is zero if unknown.
+	TrustLevel cmtmath.Fraction
 }
 
 // ClientTrustedState holds the current state of a client from the perspective of both involved chains,
@@ -93,6 +159,10 @@ type PacketInfo struct {
 	TimeoutHeight    clienttypes.Height
 	TimeoutTimestamp uint64
 	Ack              []byte
+
+	// Incentivized is true if fees for relaying the packet were escrowed with the ICS-29 fee middleware
+	// in the transaction which sent it.
+	Incentivized bool
 }
 
 func (pi PacketInfo) Packet() chantypes.Packet {
@@ -108,6 +178,62 @@ func (pi PacketInfo) Packet() chantypes.Packet {
 	}
 }
 
+// ICS20Memo returns the memo of the packet, if it is an ICS-20 packet with a memo.
+func (pi PacketInfo) ICS20Memo() (string, bool) {
+	var data transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(pi.Data, &data); err != nil {
+		return "", false
+	}
+	return data.Memo, data.Memo != ""
+}
+
+// TimeoutElapsed returns a TimeoutHeightError or TimeoutTimestampError if the packet has timed out on the destination
+// chain, with the given revision number, as of the block. Timeouts are evaluated as core IBC evaluates them for a
+// MsgTimeout, so the block's height and time can be used as the proof height and consensus timestamp of the proof.
+func (pi PacketInfo) TimeoutElapsed(revision uint64, block LatestBlock) error {
+	height := clienttypes.NewHeight(revision, block.Height)
+	if !pi.TimeoutHeight.IsZero() && height.GTE(pi.TimeoutHeight) {
+		return NewTimeoutHeightError(block.Height, pi.TimeoutHeight.RevisionHeight)
+	}
+
+	// the block time is not known, so the timeout timestamp cannot be evaluated.
+	if block.Time.IsZero() {
+		return nil
+	}
+
+	timestamp := uint64(block.Time.UnixNano())
+	if pi.TimeoutTimestamp > 0 && timestamp >= pi.TimeoutTimestamp {
+		return NewTimeoutTimestampError(timestamp, pi.TimeoutTimestamp)
+	}
+
+	return nil
+}
+
+// AckError returns the error of the acknowledgement written for the packet, if it is an error acknowledgement.
+func (pi PacketInfo) AckError() (string, bool) {
+	return AcknowledgementError(pi.Ack)
+}
+
+// AcknowledgementError returns the error of an ICS-4 error acknowledgement, such as ICS-20 writes when a transfer
+// is rejected on the destination chain. Acknowledgements which are not in the ICS-4 format are application
+// specific and are not considered errors.
+func AcknowledgementError(ack []byte) (string, bool) {
+	if len(ack) == 0 {
+		return "", false
+	}
+
+	var chanAck chantypes.Acknowledgement
+	if err := chantypes.SubModuleCdc.UnmarshalJSON(ack, &chanAck); err != nil {
+		return "", false
+	}
+
+	errAck, ok := chanAck.Response.(*chantypes.Acknowledgement_Error)
+	if !ok {
+		return "", false
+	}
+	return errAck.Error, true
+}
+
 // ConnectionInfo contains relevant properties from connection handshake messages
 // which may be necessary to construct the next message for the counterparty chain.
 type ConnectionInfo struct {
@@ -133,8 +259,39 @@ type ChannelInfo struct {
 	// MsgChannelOpenTry, so should be added manually for MsgChannelOpenInit.
 	CounterpartyConnID string
 
+	// ConnectionHops and CounterpartyConnectionHops are the connection hops of multihop channels,
+	// starting with ConnID and CounterpartyConnID respectively. They are empty for single hop channels.
+	ConnectionHops             []string
+	CounterpartyConnectionHops []string
+
 	Order   chantypes.Order
 	Version string
+
+	// UpgradeSequence and State are only populated by channel upgrade events.
+	UpgradeSequence uint64
+	State           chantypes.State
+
+	// UpgradeTimeoutHeight and UpgradeTimeoutTimestamp are the timeout of the channel's
+	// own upgrade, populated for the channel upgrade events that move the channel into FLUSHING.
+	UpgradeTimeoutHeight    clienttypes.Height
+	UpgradeTimeoutTimestamp uint64
+}
+
+// Hops returns the connection hops of the channel, which is ConnID alone unless ConnectionHops are set.
+func (ci ChannelInfo) Hops() []string {
+	if len(ci.ConnectionHops) > 0 {
+		return ci.ConnectionHops
+	}
+	return []string{ci.ConnID}
+}
+
+// CounterpartyHops returns the connection hops of the counterparty channel,
+// which is CounterpartyConnID alone unless CounterpartyConnectionHops are set.
+func (ci ChannelInfo) CounterpartyHops() []string {
+	if len(ci.CounterpartyConnectionHops) > 0 {
+		return ci.CounterpartyConnectionHops
+	}
+	return []string{ci.CounterpartyConnID}
 }
 
 // ClientICQQueryID string wrapper for query ID.
@@ -174,6 +331,17 @@ type ChannelProof struct {
 	Version     string
 }
 
+// ChannelUpgradeProof includes all of the proof parameters needed for the channel upgrade handshake.
+type ChannelUpgradeProof struct {
+	Channel           chantypes.Channel
+	ChannelProof      []byte
+	Upgrade           chantypes.Upgrade
+	UpgradeProof      []byte
+	ErrorReceipt      chantypes.ErrorReceipt
+	ErrorReceiptProof []byte
+	ProofHeight       clienttypes.Height
+}
+
 type ICQProof struct {
 	Result   []byte
 	ProofOps *crypto.ProofOps // TODO swap out tendermint type for third party chains.
@@ -222,7 +390,8 @@ type KeyProvider interface {
 	ListAddresses() (map[string]string, error)
 	DeleteKey(name string) error
 	KeyExists(name string) bool
-	ExportPrivKeyArmor(keyName string) (armor string, err error)
+	ExportPrivKeyArmor(keyName, passphrase string) (armor string, err error)
+	ImportPrivKeyArmor(name, armor, passphrase string) (address string, err error)
 }
 
 type ChainProvider interface {
@@ -266,7 +435,8 @@ type ChainProvider interface {
 	NextSeqRecv(ctx context.Context, msgTransfer PacketInfo, height uint64) (PacketProof, error)
 
 	// MsgTransfer constructs a MsgTransfer message ready to write to the chain.
-	MsgTransfer(dstAddr string, amount sdk.Coin, info PacketInfo) (RelayerMessage, error)
+	// The memo is included in the packet data, e.g. for packet forwarding.
+	MsgTransfer(dstAddr string, amount sdk.Coin, memo string, info PacketInfo) (RelayerMessage, error)
 
 	// MsgRecvPacket takes the packet information from a MsgTransfer along with the packet commitment,
 	// and assembles a full MsgRecvPacket ready to write to the chain.
@@ -355,6 +525,40 @@ type ChainProvider interface {
 
 	// [End] Channel handshake IBC message assembly
 
+	// [Begin] Channel upgrade IBC message assembly
+
+	// ChannelUpgradeProof queries for proof of a channel state along with its in progress upgrade, if any.
+	ChannelUpgradeProof(ctx context.Context, msg ChannelInfo, height uint64) (ChannelUpgradeProof, error)
+
+	// ChannelUpgradeErrorProof queries for proof of the latest upgrade error receipt of a channel.
+	ChannelUpgradeErrorProof(ctx context.Context, msgUpgradeError ChannelInfo, height uint64) (ChannelUpgradeProof, error)
+
+	// MsgChannelUpgradeTry takes channel info along with the proof that the channel upgrade has been initialized
+	// on the counterparty chain, and assembles a MsgChannelUpgradeTry message ready to write to the chain.
+	MsgChannelUpgradeTry(msgUpgradeInit ChannelInfo, proof ChannelUpgradeProof) (RelayerMessage, error)
+
+	// MsgChannelUpgradeAck takes channel info along with the proof that the channel upgrade try has occurred
+	// on the counterparty chain, and assembles a MsgChannelUpgradeAck message ready to write to the chain.
+	MsgChannelUpgradeAck(msgUpgradeTry ChannelInfo, proof ChannelUpgradeProof) (RelayerMessage, error)
+
+	// MsgChannelUpgradeConfirm takes channel info along with the proof that the channel upgrade ack has occurred
+	// on the counterparty chain, and assembles a MsgChannelUpgradeConfirm message ready to write to the chain.
+	MsgChannelUpgradeConfirm(msgUpgradeAck ChannelInfo, proof ChannelUpgradeProof) (RelayerMessage, error)
+
+	// MsgChannelUpgradeOpen takes channel info along with the proof that the counterparty channel has completed
+	// flushing, and assembles a MsgChannelUpgradeOpen message ready to write to the chain.
+	MsgChannelUpgradeOpen(info ChannelInfo, proof ChannelUpgradeProof) (RelayerMessage, error)
+
+	// MsgChannelUpgradeTimeout takes channel info along with the proof of the counterparty channel state
+	// after the upgrade timeout has elapsed, and assembles a MsgChannelUpgradeTimeout message ready to write to the chain.
+	MsgChannelUpgradeTimeout(info ChannelInfo, proof ChannelUpgradeProof) (RelayerMessage, error)
+
+	// MsgChannelUpgradeCancel takes channel info along with the proof of the upgrade error receipt written
+	// on the counterparty chain, and assembles a MsgChannelUpgradeCancel message ready to write to the chain.
+	MsgChannelUpgradeCancel(msgUpgradeError ChannelInfo, proof ChannelUpgradeProof) (RelayerMessage, error)
+
+	// [End] Channel upgrade IBC message assembly
+
 	// [Begin] Client IBC message assembly
 
 	// MsgUpdateClientHeader takes the latest chain header, in addition to the latest client trusted header
@@ -409,6 +613,21 @@ type ChainProvider interface {
 	Sprint(toPrint proto.Message) (string, error)
 
 	SetRpcAddr(rpcAddr string) error
+
+	// SetChainId sets the chain-id of the chain, e.g. after the chain relaunched with a new chain-id.
+	// The provider must be re-initialized for the new chain-id to take effect.
+	SetChainId(chainID string) error
+}
+
+// RPCEndpointSwitcher is implemented by ChainProviders which can switch to another RPC endpoint at runtime,
+// e.g. when their endpoint is decommissioned.
+type RPCEndpointSwitcher interface {
+	// RPCAddr returns the RPC endpoint in use.
+	RPCAddr() string
+
+	// SwitchRPCAddr switches to rpcAddr if it serves the chain, otherwise it returns an error
+	// and the endpoint in use is kept.
+	SwitchRPCAddr(ctx context.Context, rpcAddr string) error
 }
 
 // Do we need intermediate types? i.e. can we use the SDK types for both substrate and cosmos?
@@ -0,0 +1,26 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketInfoICS20Memo(t *testing.T) {
+	pi := provider.PacketInfo{
+		Data: []byte(`{"amount":"1","denom":"stake","memo":"{\"wasm\":{}}","receiver":"cosmos1r","sender":"cosmos1s"}`),
+	}
+	memo, ok := pi.ICS20Memo()
+	require.True(t, ok)
+	require.Equal(t, `{"wasm":{}}`, memo)
+
+	pi.Data = []byte(`{"amount":"1","denom":"stake","receiver":"cosmos1r","sender":"cosmos1s"}`)
+	_, ok = pi.ICS20Memo()
+	require.False(t, ok)
+
+	// packets of other applications have no ICS-20 memo.
+	pi.Data = []byte("not an ics-20 packet")
+	_, ok = pi.ICS20Memo()
+	require.False(t, ok)
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// QueryType is a type of query whose results a QueryCache caches.
+type QueryType string
+
+const (
+	QueryTypeClientState    QueryType = "client-state"
+	QueryTypeConsensusState QueryType = "consensus-state"
+	QueryTypeConnection     QueryType = "connection"
+	QueryTypeChannel        QueryType = "channel"
+)
+
+// queryCachePruneInterval is how often expired results are removed from a QueryCache.
+const queryCachePruneInterval = time.Minute
+
+// QueryCacheConfig are the TTLs of cached query results by query type, e.g. "6s".
+// Results of query types without a TTL are not cached.
+type QueryCacheConfig struct {
+	ClientState    string `yaml:"client-state,omitempty" json:"client-state,omitempty"`
+	ConsensusState string `yaml:"consensus-state,omitempty" json:"consensus-state,omitempty"`
+	Connection     string `yaml:"connection,omitempty" json:"connection,omitempty"`
+	Channel        string `yaml:"channel,omitempty" json:"channel,omitempty"`
+}
+
+// ttls returns the TTLs of the query types.
+func (c QueryCacheConfig) ttls() (map[QueryType]time.Duration, error) {
+	ttls := make(map[QueryType]time.Duration)
+	for queryType, value := range map[QueryType]string{
+		QueryTypeClientState:    c.ClientState,
+		QueryTypeConsensusState: c.ConsensusState,
+		QueryTypeConnection:     c.Connection,
+		QueryTypeChannel:        c.Channel,
+	} {
+		if value == "" {
+			continue
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid query-cache %s TTL: %s", queryType, value)
+		}
+		if ttl > 0 {
+			ttls[queryType] = ttl
+		}
+	}
+	return ttls, nil
+}
+
+// Validate returns an error if any TTL is invalid. A nil config is valid.
+func (c *QueryCacheConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	_, err := c.ttls()
+	return err
+}
+
+type queryCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// QueryCache caches the results of queries of a chain for a TTL per query type, so that workers which query
+// identical state, e.g. the same client state at the same height, share a single query. Concurrent identical
+// queries which are not cached yet are coalesced. Errors are not cached.
+//
+// Cached results are shared between callers, which must not modify them.
+type QueryCache struct {
+	ttls map[QueryType]time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]queryCacheEntry
+	nextPrune time.Time
+
+	inflight singleflight.Group
+}
+
+// NewQueryCache returns a QueryCache with the TTLs of cfg.
+func NewQueryCache(cfg QueryCacheConfig) (*QueryCache, error) {
+	ttls, err := cfg.ttls()
+	if err != nil {
+		return nil, err
+	}
+	return &QueryCache{
+		ttls:    ttls,
+		entries: make(map[string]queryCacheEntry),
+	}, nil
+}
+
+func (c *QueryCache) get(key string, now time.Time) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *QueryCache) set(key string, value any, expires, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = queryCacheEntry{value: value, expires: expires}
+
+	if now.Before(c.nextPrune) {
+		return
+	}
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.nextPrune = now.Add(queryCachePruneInterval)
+}
+
+// CachedQuery returns the cached result of the query of queryType at height identified by key, e.g. by its
+// arguments, or the result of query, which is cached for the TTL of queryType. If cache is nil, queryType has
+// no TTL or height is 0, i.e. the latest height, it returns the result of query.
+//
+// Coalesced queries run detached from the context of the caller which started them, so that cancelling one
+// caller does not fail the others, which still return as soon as their own context is done.
+func CachedQuery[T any](
+	ctx context.Context,
+	cache *QueryCache,
+	queryType QueryType,
+	height int64,
+	key string,
+	query func(ctx context.Context) (T, error),
+) (T, error) {
+	if cache == nil || height == 0 {
+		return query(ctx)
+	}
+	ttl, ok := cache.ttls[queryType]
+	if !ok {
+		return query(ctx)
+	}
+
+	key = fmt.Sprintf("%s/%d/%s", queryType, height, key)
+	if v, ok := cache.get(key, time.Now()); ok {
+		return v.(T), nil
+	}
+
+	ch := cache.inflight.DoChan(key, func() (any, error) {
+		res, err := query(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		cache.set(key, res, now.Add(ttl), now)
+		return res, nil
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return zero, r.Err
+		}
+		return r.Val.(T), nil
+	}
+}
@@ -0,0 +1,165 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCacheConfigValidate(t *testing.T) {
+	var cfg *provider.QueryCacheConfig
+	require.NoError(t, cfg.Validate())
+	require.NoError(t, (&provider.QueryCacheConfig{ClientState: "6s", Channel: "0s"}).Validate())
+	require.Error(t, (&provider.QueryCacheConfig{Connection: "1 minute"}).Validate())
+	require.Error(t, (&provider.QueryCacheConfig{ConsensusState: "-1s"}).Validate())
+}
+
+func TestCachedQuery(t *testing.T) {
+	ctx := context.Background()
+	cache, err := provider.NewQueryCache(provider.QueryCacheConfig{ClientState: "50ms"})
+	require.NoError(t, err)
+
+	var queries atomic.Int32
+	query := func(context.Context) (string, error) {
+		queries.Add(1)
+		return "07-tendermint-0", nil
+	}
+
+	// results are cached per key until the TTL.
+	for i := 0; i < 3; i++ {
+		res, err := provider.CachedQuery(ctx, cache, provider.QueryTypeClientState, 100, "07-tendermint-0", query)
+		require.NoError(t, err)
+		require.Equal(t, "07-tendermint-0", res)
+	}
+	require.Equal(t, int32(1), queries.Load())
+
+	_, err = provider.CachedQuery(ctx, cache, provider.QueryTypeClientState, 101, "07-tendermint-0", query)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), queries.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = provider.CachedQuery(ctx, cache, provider.QueryTypeClientState, 100, "07-tendermint-0", query)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), queries.Load())
+
+	// query types without a TTL, and a nil cache, are not cached.
+	_, err = provider.CachedQuery(ctx, cache, provider.QueryTypeChannel, 100, "transfer/channel-0", query)
+	require.NoError(t, err)
+	_, err = provider.CachedQuery(ctx, cache, provider.QueryTypeChannel, 100, "transfer/channel-0", query)
+	require.NoError(t, err)
+	_, err = provider.CachedQuery(ctx, nil, provider.QueryTypeClientState, 100, "07-tendermint-0", query)
+	require.NoError(t, err)
+	require.Equal(t, int32(6), queries.Load())
+
+	// errors are not cached.
+	failing := func(context.Context) (string, error) {
+		queries.Add(1)
+		return "", errors.New("rpc error")
+	}
+	_, err = provider.CachedQuery(ctx, cache, provider.QueryTypeClientState, 102, "07-tendermint-0", failing)
+	require.Error(t, err)
+	_, err = provider.CachedQuery(ctx, cache, provider.QueryTypeClientState, 102, "07-tendermint-0", query)
+	require.NoError(t, err)
+	require.Equal(t, int32(8), queries.Load())
+}
+
+func TestCachedQueryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	cache, err := provider.NewQueryCache(provider.QueryCacheConfig{Connection: "1m"})
+	require.NoError(t, err)
+
+	var queries atomic.Int32
+	release := make(chan struct{})
+	query := func(context.Context) (int, error) {
+		queries.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	// identical queries of concurrent workers are coalesced into one.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := provider.CachedQuery(ctx, cache, provider.QueryTypeConnection, 100, "connection-0", query)
+			require.NoError(t, err)
+			require.Equal(t, 42, res)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), queries.Load())
+}
+
+func TestCachedQueryLatestHeight(t *testing.T) {
+	ctx := context.Background()
+	cache, err := provider.NewQueryCache(provider.QueryCacheConfig{Channel: "1m"})
+	require.NoError(t, err)
+
+	state := "STATE_OPEN"
+	query := func(context.Context) (string, error) {
+		return state, nil
+	}
+
+	// queries of the latest height are never cached, so that state changes are observed.
+	res, err := provider.CachedQuery(ctx, cache, provider.QueryTypeChannel, 0, "transfer/channel-0", query)
+	require.NoError(t, err)
+	require.Equal(t, "STATE_OPEN", res)
+
+	state = "STATE_CLOSED"
+	res, err = provider.CachedQuery(ctx, cache, provider.QueryTypeChannel, 0, "transfer/channel-0", query)
+	require.NoError(t, err)
+	require.Equal(t, "STATE_CLOSED", res)
+}
+
+func TestCachedQueryCancelledCaller(t *testing.T) {
+	cache, err := provider.NewQueryCache(provider.QueryCacheConfig{ClientState: "1m"})
+	require.NoError(t, err)
+
+	var queries atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	query := func(ctx context.Context) (string, error) {
+		queries.Add(1)
+		close(started)
+		select {
+		case <-release:
+			return "07-tendermint-0", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	// the first caller starts the query, then gives up on it.
+	ctx1, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := provider.CachedQuery(ctx1, cache, provider.QueryTypeClientState, 100, "07-tendermint-0", query)
+		errs <- err
+	}()
+	<-started
+
+	// a coalesced caller still gets the result of the query.
+	results := make(chan string, 1)
+	go func() {
+		res, err := provider.CachedQuery(context.Background(), cache, provider.QueryTypeClientState, 100, "07-tendermint-0", query)
+		require.NoError(t, err)
+		results <- res
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+
+	close(release)
+	require.Equal(t, "07-tendermint-0", <-results)
+	require.Equal(t, int32(1), queries.Load())
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	"github.com/cometbft/cometbft/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+)
+
+// VerifyTrustLevel checks that the header can update a client from the trusted header, which is the header
+// following the trusted height of the update. Unless the header is adjacent to the trusted height, the validators
+// trusted by the client, which are the validators of the trusted header, must hold more than trustLevel of their
+// voting power among the signers of the header, or the client rejects the update. Headers of other than tendermint
+// chains are not checked.
+func VerifyTrustLevel(chainID string, trustedHeader, header IBCHeader, trustLevel cmtmath.Fraction) error {
+	trusted, ok := trustedHeader.(TendermintIBCHeader)
+	if !ok {
+		return nil
+	}
+	untrusted, ok := header.(TendermintIBCHeader)
+	if !ok {
+		return nil
+	}
+	if untrusted.Height() <= trusted.Height() {
+		// adjacent to the trusted height, the validators of the header are the trusted ones.
+		return nil
+	}
+
+	err := trusted.ValidatorSet.VerifyCommitLightTrusting(chainID, untrusted.SignedHeader.Commit, trustLevel)
+	var notEnough types.ErrNotEnoughVotingPowerSigned
+	if errors.As(err, &notEnough) {
+		return fmt.Errorf("the validator set of chain %s rotated too much between heights %d and %d: "+
+			"validators trusted by the client signed %d of the voting power of the trusted set, "+
+			"the trust level %s requires more than %d",
+			chainID, trusted.Height()-1, untrusted.Height(), notEnough.Got, trustLevel, notEnough.Needed)
+	}
+	return err
+}
+
+// ClientUpdateStep is an update of a client to a header from the trusted height, with the trusted header being
+// the header following the trusted height.
+type ClientUpdateStep struct {
+	TrustedHeight clienttypes.Height
+	TrustedHeader IBCHeader
+	Header        IBCHeader
+}
+
+// BisectClientUpdate splits the update into steps through intermediate headers of the chain, each of which
+// satisfies the trust level, so that a client which fell too far behind can be caught up with a chain of updates.
+// Like the skipping verification of light clients, each step goes to the highest height found by halving the
+// remaining range which satisfies the trust level, until the header of the update is reached.
+// The intermediate headers are queried with header.
+func BisectClientUpdate(
+	chainID string,
+	trustLevel cmtmath.Fraction,
+	update ClientUpdateStep,
+	header func(height uint64) (IBCHeader, error),
+) ([]ClientUpdateStep, error) {
+	var steps []ClientUpdateStep
+	trusted := update.TrustedHeight
+	trustedHeader := update.TrustedHeader
+	for trusted.RevisionHeight < update.Header.Height() {
+		h := update.Header
+		for VerifyTrustLevel(chainID, trustedHeader, h, trustLevel) != nil {
+			pivot := trusted.RevisionHeight + (h.Height()-trusted.RevisionHeight)/2
+			var err error
+			if h, err = header(pivot); err != nil {
+				return nil, err
+			}
+		}
+		steps = append(steps, ClientUpdateStep{TrustedHeight: trusted, TrustedHeader: trustedHeader, Header: h})
+
+		trusted = clienttypes.NewHeight(trusted.RevisionNumber, h.Height())
+		if trusted.RevisionHeight == update.Header.Height() {
+			break
+		}
+		var err error
+		if trustedHeader, err = header(trusted.RevisionHeight + 1); err != nil {
+			return nil, err
+		}
+	}
+	return steps, nil
+}
@@ -0,0 +1,99 @@
+package provider_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+var oneThird = cmtmath.Fraction{Numerator: 1, Denominator: 3}
+
+func TestVerifyTrustLevel(t *testing.T) {
+	const chainID = "chain-a"
+
+	vals, privVals := tmtypes.RandValidatorSet(4, 10)
+	rotatedVals, rotatedPrivVals := tmtypes.RandValidatorSet(4, 10)
+
+	trusted := signedIBCHeader(t, chainID, 11, vals, privVals)
+
+	// signed by the trusted validators.
+	require.NoError(t, provider.VerifyTrustLevel(chainID, trusted, signedIBCHeader(t, chainID, 20, vals, privVals), oneThird))
+
+	// adjacent to the trusted height, the validators are checked by the client against the trusted header.
+	require.NoError(t, provider.VerifyTrustLevel(chainID, trusted, signedIBCHeader(t, chainID, 11, rotatedVals, rotatedPrivVals), oneThird))
+
+	// signed by an entirely new validator set.
+	err := provider.VerifyTrustLevel(chainID, trusted, signedIBCHeader(t, chainID, 20, rotatedVals, rotatedPrivVals), oneThird)
+	require.ErrorContains(t, err, "rotated too much between heights 10 and 20")
+}
+
+func TestBisectClientUpdate(t *testing.T) {
+	const chainID = "chain-a"
+
+	// the validator set is entirely replaced at height 56.
+	vals, privVals := tmtypes.RandValidatorSet(4, 10)
+	rotatedVals, rotatedPrivVals := tmtypes.RandValidatorSet(4, 10)
+	header := func(height uint64) (provider.IBCHeader, error) {
+		if height < 56 {
+			return signedIBCHeader(t, chainID, int64(height), vals, privVals), nil
+		}
+		return signedIBCHeader(t, chainID, int64(height), rotatedVals, rotatedPrivVals), nil
+	}
+	update := func(trustedHeight, height uint64) provider.ClientUpdateStep {
+		trustedHeader, _ := header(trustedHeight + 1)
+		h, _ := header(height)
+		return provider.ClientUpdateStep{
+			TrustedHeight: clienttypes.NewHeight(1, trustedHeight),
+			TrustedHeader: trustedHeader,
+			Header:        h,
+		}
+	}
+
+	// an update satisfying the trust level is not split.
+	steps, err := provider.BisectClientUpdate(chainID, oneThird, update(10, 50), header)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	require.Equal(t, uint64(50), steps[0].Header.Height())
+
+	// the client is updated to the last height of the trusted validators, then trusts the new ones.
+	steps, err = provider.BisectClientUpdate(chainID, oneThird, update(10, 100), header)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	require.Equal(t, clienttypes.NewHeight(1, 10), steps[0].TrustedHeight)
+	require.Equal(t, uint64(55), steps[0].Header.Height())
+	require.Equal(t, clienttypes.NewHeight(1, 55), steps[1].TrustedHeight)
+	require.Equal(t, uint64(56), steps[1].TrustedHeader.Height())
+	require.Equal(t, uint64(100), steps[1].Header.Height())
+}
+
+// signedIBCHeader returns a header at height committed by all of the validators.
+func signedIBCHeader(
+	t *testing.T,
+	chainID string,
+	height int64,
+	vals *tmtypes.ValidatorSet,
+	privVals []tmtypes.PrivValidator,
+) provider.TendermintIBCHeader {
+	blockID := tmtypes.BlockID{
+		Hash:          tmhash.Sum([]byte("block")),
+		PartSetHeader: tmtypes.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+	}
+	voteSet := tmtypes.NewVoteSet(chainID, height, 0, cmtproto.PrecommitType, vals)
+	extCommit, err := tmtypes.MakeExtCommit(blockID, height, 0, voteSet, privVals, time.Now(), false)
+	require.NoError(t, err)
+
+	return provider.TendermintIBCHeader{
+		SignedHeader: &tmtypes.SignedHeader{
+			Header: &tmtypes.Header{ChainID: chainID, Height: height},
+			Commit: extCommit.ToCommit(),
+		},
+		ValidatorSet: vals,
+	}
+}
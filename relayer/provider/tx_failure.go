@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+// ErrFeeCapExceeded is returned instead of broadcasting a transaction whose fee is higher than the fee caps
+// configured for the chain.
+var ErrFeeCapExceeded = errors.New("fee cap exceeded")
+
+// TxFailure classifies why sending a transaction failed, which determines how the relayer recovers from it.
+type TxFailure int
+
+const (
+	// TxFailureNone means the transaction did not fail.
+	TxFailureNone TxFailure = iota
+	// TxFailureUnknown is any failure which does not fall into one of the other classes.
+	TxFailureUnknown
+	// TxFailureOutOfGas means the transaction ran out of gas, so it is simulated again before it is resent.
+	TxFailureOutOfGas
+	// TxFailureSequenceMismatch means the transaction was signed with a stale account sequence,
+	// so it is signed again with the sequence of the account on chain before it is resent.
+	TxFailureSequenceMismatch
+	// TxFailureClientExpired means the client updated by the transaction is expired or frozen,
+	// so the transaction cannot succeed until the client is recovered by governance.
+	TxFailureClientExpired
+	// TxFailurePacketReceived means the messages of the transaction were already relayed, e.g. by another relayer.
+	TxFailurePacketReceived
+	// TxFailureInsufficientFunds means the account cannot pay the fees of the transaction until it is topped up.
+	TxFailureInsufficientFunds
+	// TxFailureTimeout means the transaction was not included in a block before the timeout.
+	TxFailureTimeout
+	// TxFailureContract means a CosmWasm contract executed by the messages of the transaction failed,
+	// e.g. the contract called back by the ibc-hooks middleware with the acknowledgement of a packet.
+	TxFailureContract
+	// TxFailureConsensusStateNotFound means the client on the chain has no consensus state at the height of a proof,
+	// or at the trusted height of a client update, e.g. because it was pruned, so the client is updated
+	// and the messages are assembled again with a new proof height before they are resent.
+	TxFailureConsensusStateNotFound
+	// TxFailureFeeCapExceeded means the transaction was not broadcast since its fee is higher than the fee caps
+	// of the chain, e.g. during a gas price spike, so the messages are sent again once fees are within the caps.
+	TxFailureFeeCapExceeded
+)
+
+func (f TxFailure) String() string {
+	switch f {
+	case TxFailureNone:
+		return "none"
+	case TxFailureOutOfGas:
+		return "out of gas"
+	case TxFailureSequenceMismatch:
+		return "sequence mismatch"
+	case TxFailureClientExpired:
+		return "client expired"
+	case TxFailurePacketReceived:
+		return "packet already received"
+	case TxFailureInsufficientFunds:
+		return "insufficient funds"
+	case TxFailureTimeout:
+		return "timeout"
+	case TxFailureContract:
+		return "contract error"
+	case TxFailureConsensusStateNotFound:
+		return "consensus state not found"
+	case TxFailureFeeCapExceeded:
+		return "fee cap exceeded"
+	}
+	return "unknown"
+}
+
+// Retryable returns false if sending the same messages again cannot succeed.
+func (f TxFailure) Retryable() bool {
+	return f != TxFailureClientExpired && f != TxFailurePacketReceived
+}
+
+// RetryImmediately returns true if the messages can be sent again right away, rather than after waiting for
+// the failed transaction to possibly be included in a block, since the failure is corrected when resending.
+func (f TxFailure) RetryImmediately() bool {
+	return f == TxFailureOutOfGas || f == TxFailureSequenceMismatch || f == TxFailureConsensusStateNotFound
+}
+
+// txFailureErrors are the registered errors of each class of TxFailure,
+// which errors returned for ABCI codes of a registered codespace match.
+var txFailureErrors = []struct {
+	failure TxFailure
+	errs    []error
+}{
+	{TxFailureOutOfGas, []error{legacyerrors.ErrOutOfGas}},
+	{TxFailureSequenceMismatch, []error{legacyerrors.ErrWrongSequence}},
+	{TxFailureClientExpired, []error{clienttypes.ErrClientNotActive}},
+	{TxFailureConsensusStateNotFound, []error{clienttypes.ErrConsensusStateNotFound}},
+	{TxFailurePacketReceived, []error{chantypes.ErrRedundantTx, chantypes.ErrNoOpMsg, chantypes.ErrPacketReceived}},
+	{TxFailureInsufficientFunds, []error{legacyerrors.ErrInsufficientFunds, legacyerrors.ErrInsufficientFee}},
+	{TxFailureTimeout, []error{context.DeadlineExceeded}},
+	{TxFailureFeeCapExceeded, []error{ErrFeeCapExceeded}},
+}
+
+// txFailureLogs are substrings of the raw logs of each class of TxFailure, for chains which return
+// errors of codespaces that are not registered in the relayer.
+var txFailureLogs = []struct {
+	failure TxFailure
+	logs    []string
+}{
+	{TxFailureOutOfGas, []string{"out of gas"}},
+	{TxFailureSequenceMismatch, []string{"account sequence mismatch", "incorrect account sequence"}},
+	{TxFailureClientExpired, []string{"client state is not active", "client is not active"}},
+	{TxFailureConsensusStateNotFound, []string{"consensus state not found"}},
+	{TxFailurePacketReceived, []string{"packet messages are redundant", "packet already received"}},
+	{TxFailureInsufficientFunds, []string{"insufficient funds", "insufficient fee"}},
+	{TxFailureTimeout, []string{"timed out after waiting for tx"}},
+	{TxFailureContract, []string{"execute wasm contract failed"}},
+	{TxFailureFeeCapExceeded, []string{"fee cap exceeded"}},
+}
+
+// ClassifyTxFailure returns the class of the error returned for sending a transaction,
+// by its registered ABCI error if it has one, or else by its log.
+func ClassifyTxFailure(err error) TxFailure {
+	if err == nil {
+		return TxFailureNone
+	}
+	for _, c := range txFailureErrors {
+		for _, e := range c.errs {
+			if errors.Is(err, e) {
+				return c.failure
+			}
+		}
+	}
+	log := err.Error()
+	for _, c := range txFailureLogs {
+		for _, l := range c.logs {
+			if strings.Contains(log, l) {
+				return c.failure
+			}
+		}
+	}
+	return TxFailureUnknown
+}
@@ -0,0 +1,45 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	errorsmod "cosmossdk.io/errors"
+	legacyerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTxFailure(t *testing.T) {
+	for _, tc := range []struct {
+		err     error
+		failure provider.TxFailure
+	}{
+		{nil, provider.TxFailureNone},
+		{errors.New("connection refused"), provider.TxFailureUnknown},
+		{errorsmod.ABCIError(legacyerrors.RootCodespace, legacyerrors.ErrOutOfGas.ABCICode(), "out of gas in location: ReadFlat"), provider.TxFailureOutOfGas},
+		{fmt.Errorf("failed to send: %w", legacyerrors.ErrWrongSequence), provider.TxFailureSequenceMismatch},
+		{chantypes.ErrRedundantTx, provider.TxFailurePacketReceived},
+		{errors.New("client state is not active: Expired"), provider.TxFailureClientExpired},
+		{errors.New("spendable balance 10uatom is smaller than 30uatom: insufficient funds"), provider.TxFailureInsufficientFunds},
+		{fmt.Errorf("broadcast: %w", context.DeadlineExceeded), provider.TxFailureTimeout},
+		{errors.New("failed to execute message; message index: 0: ibc hooks: Execute: execute wasm contract failed"), provider.TxFailureContract},
+		{errors.New("execute wasm contract failed: out of gas in location: wasm contract"), provider.TxFailureOutOfGas},
+		{fmt.Errorf("verify membership: %w", clienttypes.ErrConsensusStateNotFound), provider.TxFailureConsensusStateNotFound},
+		{errors.New("failed to execute message; message index: 1: height 1-100: consensus state not found"), provider.TxFailureConsensusStateNotFound},
+		{fmt.Errorf("%w: fee 300uatom is higher than per-tx cap 200uatom", provider.ErrFeeCapExceeded), provider.TxFailureFeeCapExceeded},
+	} {
+		require.Equal(t, tc.failure, provider.ClassifyTxFailure(tc.err), "%v", tc.err)
+	}
+
+	require.False(t, provider.TxFailureClientExpired.Retryable())
+	require.False(t, provider.TxFailurePacketReceived.Retryable())
+	require.True(t, provider.TxFailureInsufficientFunds.Retryable())
+	require.True(t, provider.TxFailureSequenceMismatch.RetryImmediately())
+	require.False(t, provider.TxFailureTimeout.RetryImmediately())
+	require.True(t, provider.TxFailureConsensusStateNotFound.RetryImmediately())
+}
@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -13,11 +14,57 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer/indexer"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// unreceivedQueryBatchSize is how many sequences are checked for receipts or commitments by a single
+	// unreceived packets or unreceived acknowledgements query.
+	unreceivedQueryBatchSize = 1000
+
+	// unreceivedQueryConcurrency bounds how many batches of an unreceived packets or unreceived acknowledgements
+	// query are in flight to a chain at once.
+	unreceivedQueryConcurrency = 8
+)
+
+// queryUnreceivedInBatches splits seqs into batches of unreceivedQueryBatchSize sequences, runs query for the
+// batches in parallel with bounded concurrency, retrying each batch on its own, and returns the sequences returned
+// for every batch in order. Channels with large backlogs are scanned in seconds this way, where a single query over
+// all of their sequences may take minutes or exceed the limits of the node.
+func queryUnreceivedInBatches(
+	ctx context.Context,
+	seqs []uint64,
+	query func(ctx context.Context, seqs []uint64) ([]uint64, error),
+) ([]uint64, error) {
+	batches := make([][]uint64, (len(seqs)+unreceivedQueryBatchSize-1)/unreceivedQueryBatchSize)
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(unreceivedQueryConcurrency)
+	for i := range batches {
+		i := i
+		batch := seqs[i*unreceivedQueryBatchSize : min((i+1)*unreceivedQueryBatchSize, len(seqs))]
+		eg.Go(func() error {
+			return retry.Do(func() error {
+				var err error
+				batches[i], err = query(egCtx, batch)
+				return err
+			}, retry.Context(egCtx), RtyAtt, RtyDel, RtyErr)
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	unreceived := []uint64{}
+	for _, batch := range batches {
+		unreceived = append(unreceived, batch...)
+	}
+	return unreceived, nil
+}
+
 // QueryLatestHeights queries the heights of multiple chains at once
 func QueryLatestHeights(ctx context.Context, src, dst *Chain) (srch, dsth int64, err error) {
 	eg, egCtx := errgroup.WithContext(ctx)
@@ -276,6 +323,17 @@ func QueryBalance(ctx context.Context, chain *Chain, address string, showDenoms
 }
 
 func QueryClientExpiration(ctx context.Context, src, dst *Chain) (time.Time, ClientStateInfo, error) {
+	clientTime, clientInfo, err := QueryClientUpdateTime(ctx, src, dst)
+	if err != nil {
+		return time.Time{}, ClientStateInfo{}, err
+	}
+
+	return clientTime.Add(clientInfo.TrustingPeriod), clientInfo, nil
+}
+
+// QueryClientUpdateTime returns the time of the block of dst which the client of src tracking dst
+// was last updated to.
+func QueryClientUpdateTime(ctx context.Context, src, dst *Chain) (time.Time, ClientStateInfo, error) {
 	latestHeight, err := src.ChainProvider.QueryLatestHeight(ctx)
 	if err != nil {
 		return time.Time{}, ClientStateInfo{}, err
@@ -296,7 +354,7 @@ func QueryClientExpiration(ctx context.Context, src, dst *Chain) (time.Time, Cli
 		return time.Time{}, ClientStateInfo{}, err
 	}
 
-	return clientTime.Add(clientInfo.TrustingPeriod), clientInfo, nil
+	return clientTime, clientInfo, nil
 }
 
 func SPrintClientExpiration(chain *Chain, expiration time.Time, clientInfo ClientStateInfo) string {
@@ -354,3 +412,210 @@ func SPrintClientExpirationJson(chain *Chain, expiration time.Time, clientInfo C
 
 	return string(jsonOutput)
 }
+
+// FailedAck is an error acknowledgement written on a chain for a packet it received from the counterparty,
+// e.g. for an ICS-20 transfer which was rejected.
+type FailedAck struct {
+	ChainID    string `json:"chain_id"`
+	Height     int64  `json:"height"`
+	Sequence   uint64 `json:"sequence"`
+	SrcPort    string `json:"src_port"`
+	SrcChannel string `json:"src_channel"`
+	DstPort    string `json:"dst_port"`
+	DstChannel string `json:"dst_channel"`
+	Error      string `json:"error"`
+
+	// WasmContract is the contract which the packet executed through the ibc-hooks middleware, if any.
+	WasmContract string `json:"wasm_contract,omitempty"`
+	// HookError is the full error of the acknowledgement emitted by the ibc-hooks middleware, e.g. the error of
+	// the contract, since the acknowledgement only carries the ABCI code of the error.
+	HookError string `json:"hook_error,omitempty"`
+}
+
+// wasmContract returns the contract which the packet with the given data executed through ibc-hooks, if any.
+func wasmContract(packetData []byte) string {
+	hook, ok := provider.PacketWasmHook(packetData)
+	if !ok {
+		return ""
+	}
+	return hook.Contract
+}
+
+// QueryFailedAcks returns the error acknowledgements written on c for packets received on the connection
+// of its path end, searching the given page of txs which wrote acknowledgements.
+func QueryFailedAcks(ctx context.Context, c *Chain, page, limit int) ([]FailedAck, error) {
+	txs, err := c.ChainProvider.QueryTxs(ctx, page, limit, []string{
+		fmt.Sprintf("%s.%s='%s'", chantypes.EventTypeWriteAck, chantypes.AttributeKeyConnection, c.ConnectionID()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query acknowledgements on chain{%s}@connection{%s}: %w", c.ChainID(), c.ConnectionID(), err)
+	}
+	return failedAcksFromTxs(c.ChainID(), c.ConnectionID(), txs)
+}
+
+// failedAcksFromTxs returns the error acknowledgements written by the txs for packets received on the connection.
+func failedAcksFromTxs(chainID, connectionID string, txs []*provider.RelayerTxResponse) ([]FailedAck, error) {
+	var failed []FailedAck
+	for _, tx := range txs {
+		// the error event of an acknowledgement is emitted while receiving the packet, before it is written.
+		var hookErr string
+		for _, event := range tx.Events {
+			if event.EventType == provider.IBCAckErrorEventType {
+				hookErr = event.Attributes[provider.IBCAckErrorAttributeError]
+				if errCtx := event.Attributes[provider.IBCAckErrorAttributeContext]; errCtx != "" {
+					hookErr = errCtx + ": " + hookErr
+				}
+				continue
+			}
+			if event.EventType != chantypes.EventTypeWriteAck {
+				continue
+			}
+			ackHookErr := hookErr
+			hookErr = ""
+			if event.Attributes[chantypes.AttributeKeyConnection] != connectionID {
+				continue
+			}
+
+			ack, err := hex.DecodeString(event.Attributes[chantypes.AttributeKeyAckHex])
+			if err != nil {
+				return nil, fmt.Errorf("invalid acknowledgement in tx at height %d on chain{%s}: %w", tx.Height, chainID, err)
+			}
+
+			ackErr, ok := provider.AcknowledgementError(ack)
+			if !ok {
+				continue
+			}
+
+			seq, err := strconv.ParseUint(event.Attributes[chantypes.AttributeKeySequence], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid packet sequence in tx at height %d on chain{%s}: %w", tx.Height, chainID, err)
+			}
+
+			// packets whose data is not valid hex simply have no contract.
+			packetData, _ := hex.DecodeString(event.Attributes[chantypes.AttributeKeyDataHex])
+
+			failed = append(failed, FailedAck{
+				ChainID:      chainID,
+				Height:       tx.Height,
+				Sequence:     seq,
+				SrcPort:      event.Attributes[chantypes.AttributeKeySrcPort],
+				SrcChannel:   event.Attributes[chantypes.AttributeKeySrcChannel],
+				DstPort:      event.Attributes[chantypes.AttributeKeyDstPort],
+				DstChannel:   event.Attributes[chantypes.AttributeKeyDstChannel],
+				Error:        ackErr,
+				WasmContract: wasmContract(packetData),
+				HookError:    ackHookErr,
+			})
+		}
+	}
+	return failed, nil
+}
+
+// QueryIndexedFailedAcks returns the error acknowledgements written on c for packets received on the channels of the
+// connection of its path end, searching the given page of acknowledgements in the packet index instead of the chain.
+func QueryIndexedFailedAcks(ctx context.Context, c *Chain, index *indexer.Store, page, limit int) ([]FailedAck, error) {
+	height, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest height on chain{%s}: %w", c.ChainID(), err)
+	}
+
+	channels, err := c.ChainProvider.QueryConnectionChannels(ctx, height, c.ConnectionID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels on chain{%s}@connection{%s}: %w", c.ChainID(), c.ConnectionID(), err)
+	}
+
+	received := make([]indexer.Channel, len(channels))
+	for i, ch := range channels {
+		received[i] = indexer.Channel{ChannelID: ch.ChannelId, PortID: ch.PortId}
+	}
+
+	acks, err := index.Packets(ctx, c.ChainID(), chantypes.EventTypeWriteAck, received, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return failedAcksFromIndex(c.ChainID(), channels, acks), nil
+}
+
+// QueryIndexedUnrelayedSequences returns the sequences of the packets sent in either direction on srcChannel which
+// have not been relayed, from the packet index instead of the chains. Unlike UnrelayedSequences, all unrelayed
+// packets of ordered channels are returned.
+func QueryIndexedUnrelayedSequences(
+	ctx context.Context,
+	src, dst *Chain,
+	srcChannel *chantypes.IdentifiedChannel,
+	index *indexer.Store,
+) (RelaySequences, error) {
+	srcUnrelayed, err := index.UnrelayedPackets(ctx, src.ChainID(), dst.ChainID(), indexer.Channel{
+		ChannelID: srcChannel.ChannelId,
+		PortID:    srcChannel.PortId,
+	})
+	if err != nil {
+		return RelaySequences{}, err
+	}
+	dstUnrelayed, err := index.UnrelayedPackets(ctx, dst.ChainID(), src.ChainID(), indexer.Channel{
+		ChannelID: srcChannel.Counterparty.ChannelId,
+		PortID:    srcChannel.Counterparty.PortId,
+	})
+	if err != nil {
+		return RelaySequences{}, err
+	}
+	return RelaySequences{Src: srcUnrelayed, Dst: dstUnrelayed}, nil
+}
+
+// QueryIndexedUnrelayedAcknowledgements returns the sequences of the packets on srcChannel whose acknowledgement has
+// been written on either chain, but not relayed to the other, from the packet index instead of the chains.
+func QueryIndexedUnrelayedAcknowledgements(
+	ctx context.Context,
+	src, dst *Chain,
+	srcChannel *chantypes.IdentifiedChannel,
+	index *indexer.Store,
+) (RelaySequences, error) {
+	srcUnrelayed, err := index.UnrelayedAcks(ctx, src.ChainID(), dst.ChainID(), indexer.Channel{
+		ChannelID: srcChannel.ChannelId,
+		PortID:    srcChannel.PortId,
+	})
+	if err != nil {
+		return RelaySequences{}, err
+	}
+	dstUnrelayed, err := index.UnrelayedAcks(ctx, dst.ChainID(), src.ChainID(), indexer.Channel{
+		ChannelID: srcChannel.Counterparty.ChannelId,
+		PortID:    srcChannel.Counterparty.PortId,
+	})
+	if err != nil {
+		return RelaySequences{}, err
+	}
+	return RelaySequences{Src: srcUnrelayed, Dst: dstUnrelayed}, nil
+}
+
+// failedAcksFromIndex returns the error acknowledgements among the indexed acks for packets received on the channels.
+func failedAcksFromIndex(chainID string, channels []*chantypes.IdentifiedChannel, acks []provider.PacketInfo) []FailedAck {
+	received := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		received[ch.PortId+"/"+ch.ChannelId] = true
+	}
+
+	var failed []FailedAck
+	for _, ack := range acks {
+		if !received[ack.DestPort+"/"+ack.DestChannel] {
+			continue
+		}
+
+		ackErr, ok := provider.AcknowledgementError(ack.Ack)
+		if !ok {
+			continue
+		}
+
+		failed = append(failed, FailedAck{
+			ChainID:      chainID,
+			Height:       int64(ack.Height),
+			Sequence:     ack.Sequence,
+			SrcPort:      ack.SourcePort,
+			SrcChannel:   ack.SourceChannel,
+			DstPort:      ack.DestPort,
+			DstChannel:   ack.DestChannel,
+			Error:        ackErr,
+			WasmContract: wasmContract(ack.Data),
+		})
+	}
+	return failed
+}
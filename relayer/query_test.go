@@ -1,12 +1,20 @@
 package relayer
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/provider"
 
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	"github.com/stretchr/testify/require"
 )
@@ -133,6 +141,111 @@ func TestSPrintClientExpiration_LastUpdateHeight(t *testing.T) {
 	require.Contains(t, expiration, "100")
 }
 
+func TestFailedAcksFromTxs(t *testing.T) {
+	writeAck := func(seq string, ack chantypes.Acknowledgement) provider.RelayerEvent {
+		return provider.RelayerEvent{
+			EventType: chantypes.EventTypeWriteAck,
+			Attributes: map[string]string{
+				chantypes.AttributeKeySequence:   seq,
+				chantypes.AttributeKeySrcPort:    "transfer",
+				chantypes.AttributeKeySrcChannel: "channel-1",
+				chantypes.AttributeKeyDstPort:    "transfer",
+				chantypes.AttributeKeyDstChannel: "channel-0",
+				chantypes.AttributeKeyConnection: "connection-0",
+				chantypes.AttributeKeyAckHex:     hex.EncodeToString(ack.Acknowledgement()),
+			},
+		}
+	}
+
+	errAck := chantypes.NewErrorAcknowledgement(errors.New("invalid receiver"))
+	txs := []*provider.RelayerTxResponse{{
+		Height: 10,
+		Events: []provider.RelayerEvent{
+			writeAck("1", chantypes.NewResultAcknowledgement([]byte{1})),
+			writeAck("2", errAck),
+		},
+	}}
+
+	failed, err := failedAcksFromTxs("test-chain-id", "connection-0", txs)
+	require.NoError(t, err)
+	require.Equal(t, []FailedAck{{
+		ChainID:    "test-chain-id",
+		Height:     10,
+		Sequence:   2,
+		SrcPort:    "transfer",
+		SrcChannel: "channel-1",
+		DstPort:    "transfer",
+		DstChannel: "channel-0",
+		Error:      errAck.GetError(),
+	}}, failed)
+
+	failed, err = failedAcksFromTxs("test-chain-id", "connection-1", txs)
+	require.NoError(t, err)
+	require.Empty(t, failed)
+
+	// the contract and error of packets executing a contract through ibc-hooks are reported.
+	hookAck := writeAck("3", errAck)
+	hookAck.Attributes[chantypes.AttributeKeyDataHex] = hex.EncodeToString(transfertypes.NewFungibleTokenPacketData(
+		"uosmo", "100", "cosmos1sender", "osmo1contract", `{"wasm":{"contract":"osmo1contract","msg":{}}}`,
+	).GetBytes())
+	txs = []*provider.RelayerTxResponse{{
+		Height: 11,
+		Events: []provider.RelayerEvent{
+			{
+				EventType: provider.IBCAckErrorEventType,
+				Attributes: map[string]string{
+					provider.IBCAckErrorAttributeError:   "execute wasm contract failed",
+					provider.IBCAckErrorAttributeContext: "ibc hooks",
+				},
+			},
+			hookAck,
+			writeAck("4", errAck),
+		},
+	}}
+
+	failed, err = failedAcksFromTxs("test-chain-id", "connection-0", txs)
+	require.NoError(t, err)
+	require.Len(t, failed, 2)
+	require.Equal(t, "osmo1contract", failed[0].WasmContract)
+	require.Equal(t, "ibc hooks: execute wasm contract failed", failed[0].HookError)
+	require.Empty(t, failed[1].WasmContract)
+	require.Empty(t, failed[1].HookError)
+}
+
+func TestFailedAcksFromIndex(t *testing.T) {
+	errAck := chantypes.NewErrorAcknowledgement(errors.New("invalid receiver"))
+	ack := func(seq uint64, channel string, ack chantypes.Acknowledgement) provider.PacketInfo {
+		return provider.PacketInfo{
+			Height:        10,
+			Sequence:      seq,
+			SourcePort:    "transfer",
+			SourceChannel: "channel-1",
+			DestPort:      "transfer",
+			DestChannel:   channel,
+			Ack:           ack.Acknowledgement(),
+		}
+	}
+
+	acks := []provider.PacketInfo{
+		ack(1, "channel-0", chantypes.NewResultAcknowledgement([]byte{1})),
+		ack(2, "channel-0", errAck),
+		// received on a channel of another connection.
+		ack(3, "channel-5", errAck),
+	}
+	channels := []*chantypes.IdentifiedChannel{{PortId: "transfer", ChannelId: "channel-0"}}
+
+	require.Equal(t, []FailedAck{{
+		ChainID:    "test-chain-id",
+		Height:     10,
+		Sequence:   2,
+		SrcPort:    "transfer",
+		SrcChannel: "channel-1",
+		DstPort:    "transfer",
+		DstChannel: "channel-0",
+		Error:      errAck.GetError(),
+	}}, failedAcksFromIndex("test-chain-id", channels, acks))
+}
+
 func mockChain(chainId string, clientId string) *Chain {
 	return &Chain{
 		Chainid: chainId,
@@ -156,3 +269,40 @@ func mockClientStateInfo(chainID string, trustingPeriod time.Duration, latestHei
 		LatestHeight:   mockHeight,
 	}
 }
+
+func TestQueryUnreceivedInBatches(t *testing.T) {
+	seqs := make([]uint64, 2*unreceivedQueryBatchSize+10)
+	for i := range seqs {
+		seqs[i] = uint64(i + 1)
+	}
+
+	var mu sync.Mutex
+	queried := 0
+	// every even sequence is unreceived.
+	unreceived, err := queryUnreceivedInBatches(context.Background(), seqs, func(_ context.Context, batch []uint64) ([]uint64, error) {
+		require.LessOrEqual(t, len(batch), unreceivedQueryBatchSize)
+		mu.Lock()
+		queried += len(batch)
+		mu.Unlock()
+
+		var res []uint64
+		for _, seq := range batch {
+			if seq%2 == 0 {
+				res = append(res, seq)
+			}
+		}
+		return res, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(seqs), queried)
+	require.Len(t, unreceived, len(seqs)/2)
+	require.True(t, sort.SliceIsSorted(unreceived, func(i, j int) bool { return unreceived[i] < unreceived[j] }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = queryUnreceivedInBatches(ctx, seqs, func(context.Context, []uint64) ([]uint64, error) {
+		// stop retrying right away.
+		cancel()
+		return nil, errors.New("node unavailable")
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"go.uber.org/zap"
+)
+
+// ClientExpired returns true if the client on src tracking dst has passed its trusting period.
+func ClientExpired(ctx context.Context, src, dst *Chain) (bool, error) {
+	expiration, _, err := QueryClientExpiration(ctx, src, dst)
+	if err != nil {
+		return false, fmt.Errorf("failed to query expiration of client{%s} on chain{%s}: %w", src.ClientID(), src.ChainID(), err)
+	}
+	return !time.Now().Before(expiration), nil
+}
+
+// OpenChannelsOnConnection returns the channels in the OPEN state on the connection of c's path end.
+func (c *Chain) OpenChannelsOnConnection(ctx context.Context) ([]*chantypes.IdentifiedChannel, error) {
+	channels, err := queryChannelsOnConnection(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels on chain{%s}@connection{%s}: %w", c.ChainID(), c.ConnectionID(), err)
+	}
+
+	var open []*chantypes.IdentifiedChannel
+	for _, channel := range channels {
+		if channel.State == chantypes.OPEN {
+			open = append(open, channel)
+		}
+	}
+	return open, nil
+}
+
+// RecreateChannels opens a new channel on the current connections of c and dst for each of the given channels,
+// which are expected to belong to a previous connection of c, using the same ports, ordering and version.
+// It returns a mapping of the previous channel identifiers on c to the new ones, which are the open channels
+// between the same ports on the current connections of both chains.
+func (c *Chain) RecreateChannels(
+	ctx context.Context,
+	dst *Chain,
+	channels []*chantypes.IdentifiedChannel,
+	retryPolicy *RetryPolicy,
+	memo string,
+	pathName string,
+) (map[string]string, error) {
+	recreated := make(map[string]string, len(channels))
+	existing := make(map[string]bool)
+
+	for _, channel := range channels {
+		if err := c.CreateOpenChannels(
+			ctx,
+			dst,
+			retryPolicy,
+			channel.PortId,
+			channel.Counterparty.PortId,
+			StringFromOrder(channel.Ordering),
+			channel.Version,
+			"",
+			"",
+			// the connection is new, so any channel on the port was opened by a previous iteration.
+			true,
+			memo,
+			pathName,
+		); err != nil {
+			return recreated, fmt.Errorf("failed to recreate channel{%s} on port{%s}: %w", channel.ChannelId, channel.PortId, err)
+		}
+
+		newChannels, err := queryChannelsOnConnection(ctx, c)
+		if err != nil {
+			return recreated, err
+		}
+
+		for _, newChannel := range newChannels {
+			if existing[newChannel.ChannelId] || !recreatesChannel(channel, newChannel, c.ConnectionID()) {
+				continue
+			}
+			if err := verifyRecreatedCounterparty(ctx, dst, newChannel); err != nil {
+				c.log.Info("Ignoring channel which does not recreate the previous channel",
+					zap.String("chain_id", c.ChainID()),
+					zap.String("channel_id", newChannel.ChannelId),
+					zap.Error(err),
+				)
+				continue
+			}
+			existing[newChannel.ChannelId] = true
+			recreated[channel.ChannelId] = newChannel.ChannelId
+
+			c.log.Info(
+				"Recreated channel",
+				zap.String("chain_id", c.ChainID()),
+				zap.String("port_id", channel.PortId),
+				zap.String("previous_channel_id", channel.ChannelId),
+				zap.String("channel_id", newChannel.ChannelId),
+			)
+			break
+		}
+
+		if _, ok := recreated[channel.ChannelId]; !ok {
+			return recreated, fmt.Errorf("recreated channel for channel{%s} on port{%s} not found on chain{%s}@connection{%s}",
+				channel.ChannelId, channel.PortId, c.ChainID(), c.ConnectionID())
+		}
+	}
+
+	return recreated, nil
+}
+
+// recreatesChannel returns true if newChannel, on the current connection connectionID, can be the recreation of
+// the channel of a previous connection: it is open, and between the same ports.
+func recreatesChannel(channel, newChannel *chantypes.IdentifiedChannel, connectionID string) bool {
+	return newChannel.State == chantypes.OPEN &&
+		len(newChannel.ConnectionHops) > 0 && newChannel.ConnectionHops[0] == connectionID &&
+		newChannel.PortId == channel.PortId &&
+		newChannel.Counterparty.PortId == channel.Counterparty.PortId &&
+		newChannel.Counterparty.ChannelId != ""
+}
+
+// verifyRecreatedCounterparty returns an error unless the counterparty of newChannel on dst is open on the current
+// connection of dst, with newChannel as its counterparty.
+func verifyRecreatedCounterparty(ctx context.Context, dst *Chain, newChannel *chantypes.IdentifiedChannel) error {
+	portID, channelID := newChannel.Counterparty.PortId, newChannel.Counterparty.ChannelId
+	res, err := dst.ChainProvider.QueryChannel(ctx, 0, channelID, portID)
+	if err != nil {
+		return fmt.Errorf("failed to query channel{%s} with port{%s} on chain{%s}: %w", channelID, portID, dst.ChainID(), err)
+	}
+	channel := res.Channel
+	switch {
+	case channel == nil || channel.State != chantypes.OPEN:
+		return fmt.Errorf("channel{%s} with port{%s} on chain{%s} is not open", channelID, portID, dst.ChainID())
+	case len(channel.ConnectionHops) == 0 || channel.ConnectionHops[0] != dst.ConnectionID():
+		return fmt.Errorf("channel{%s} on chain{%s} uses connections %v, expected connection{%s}",
+			channelID, dst.ChainID(), channel.ConnectionHops, dst.ConnectionID())
+	case channel.Counterparty.PortId != newChannel.PortId || channel.Counterparty.ChannelId != newChannel.ChannelId:
+		return fmt.Errorf("channel{%s} on chain{%s} has counterparty channel{%s} with port{%s}, expected channel{%s} with port{%s}",
+			channelID, dst.ChainID(), channel.Counterparty.ChannelId, channel.Counterparty.PortId, newChannel.ChannelId, newChannel.PortId)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package relayer
+
+import (
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecreatesChannel(t *testing.T) {
+	previous := &chantypes.IdentifiedChannel{
+		PortId:         "transfer",
+		ChannelId:      "channel-0",
+		ConnectionHops: []string{"connection-0"},
+		Counterparty:   chantypes.Counterparty{PortId: "transfer", ChannelId: "channel-3"},
+		State:          chantypes.OPEN,
+	}
+	recreated := func(modify func(*chantypes.IdentifiedChannel)) *chantypes.IdentifiedChannel {
+		ch := &chantypes.IdentifiedChannel{
+			PortId:         "transfer",
+			ChannelId:      "channel-7",
+			ConnectionHops: []string{"connection-4"},
+			Counterparty:   chantypes.Counterparty{PortId: "transfer", ChannelId: "channel-9"},
+			State:          chantypes.OPEN,
+		}
+		if modify != nil {
+			modify(ch)
+		}
+		return ch
+	}
+
+	require.True(t, recreatesChannel(previous, recreated(nil), "connection-4"))
+
+	// a channel of another handshake which is not open yet.
+	require.False(t, recreatesChannel(previous, recreated(func(ch *chantypes.IdentifiedChannel) {
+		ch.State = chantypes.TRYOPEN
+	}), "connection-4"))
+
+	// a channel on another connection.
+	require.False(t, recreatesChannel(previous, recreated(nil), "connection-5"))
+
+	// a channel to another counterparty port.
+	require.False(t, recreatesChannel(previous, recreated(func(ch *chantypes.IdentifiedChannel) {
+		ch.Counterparty.PortId = "icahost"
+	}), "connection-4"))
+
+	// a channel on another port.
+	require.False(t, recreatesChannel(previous, recreated(func(ch *chantypes.IdentifiedChannel) {
+		ch.PortId = "icacontroller-1"
+	}), "connection-4"))
+}
@@ -0,0 +1,193 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"go.uber.org/zap"
+)
+
+// DefaultRelayOnceMaxDuration is how long RelayOnce relays by default before it gives up on the pending messages.
+const DefaultRelayOnceMaxDuration = 60 * time.Second
+
+// RelayOnceOptions configures RelayOnce.
+type RelayOnceOptions struct {
+	FlushOptions
+
+	// MaxDuration bounds how long RelayOnce relays, including the client updates. Zero does not bound it.
+	MaxDuration time.Duration
+
+	// ClientUpdateThreshold is the time after the previous client update after which the clients of a path are
+	// updated. Clients are always updated past 2/3 of their trusting period. Zero disables the threshold.
+	ClientUpdateThreshold time.Duration
+}
+
+// RelayOnceSummary is the machine-readable result of RelayOnce.
+type RelayOnceSummary struct {
+	// Complete is whether all pending messages were relayed and the clients updated as needed within the max duration.
+	Complete bool `json:"complete"`
+
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	Paths []*RelayOncePathSummary `json:"paths"`
+}
+
+// RelayOncePathSummary is what RelayOnce relayed on a path.
+type RelayOncePathSummary struct {
+	Path string `json:"path"`
+
+	// Relayed and Failed count the messages of the successful and failed txs, by message type, e.g. MsgRecvPacket.
+	Relayed map[string]int `json:"relayed"`
+	Failed  map[string]int `json:"failed"`
+
+	// TxHashes are the hashes of the successful txs.
+	TxHashes []string `json:"tx_hashes"`
+
+	// ClientsUpdated are the chain IDs of the chains whose client of the counterparty was updated because it was
+	// past the client update threshold or 2/3 of its trusting period.
+	ClientsUpdated []string `json:"clients_updated"`
+
+	Error string `json:"error,omitempty"`
+}
+
+func newRelayOnceSummary(names []string) *RelayOnceSummary {
+	summary := &RelayOnceSummary{Complete: true, Paths: make([]*RelayOncePathSummary, len(names))}
+	for i, name := range names {
+		summary.Paths[i] = &RelayOncePathSummary{
+			Path:           name,
+			Relayed:        make(map[string]int),
+			Failed:         make(map[string]int),
+			TxHashes:       []string{},
+			ClientsUpdated: []string{},
+		}
+	}
+	return summary
+}
+
+func (s *RelayOnceSummary) path(name string) *RelayOncePathSummary {
+	for _, p := range s.Paths {
+		if p.Path == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// record counts the message of a confirmed or failed tx on its path.
+func (s *RelayOnceSummary) record(e eventstream.Event) {
+	p := s.path(e.PathName)
+	if p == nil {
+		return
+	}
+	// the type URL of the message, e.g. /ibc.core.channel.v1.MsgRecvPacket, is summarized by its message name.
+	msgType := e.MsgType[strings.LastIndex(e.MsgType, ".")+1:]
+	switch e.Type {
+	case eventstream.TypeConfirmed:
+		p.Relayed[msgType]++
+		if e.TxHash != "" && !slices.Contains(p.TxHashes, e.TxHash) {
+			p.TxHashes = append(p.TxHashes, e.TxHash)
+		}
+	case eventstream.TypeFailed:
+		p.Failed[msgType]++
+	}
+}
+
+// RelayOnce relays the packets and acknowledgements which are pending on the paths with the given names, or on all
+// paths if none are given, then updates the clients of the paths which need it, and returns a summary of what it
+// relayed. Unlike Flush, it returns once opts.MaxDuration has passed even if messages are still pending, in which
+// case the summary is not complete, so that it suits scheduled executions such as cron jobs.
+func (s *Service) RelayOnce(ctx context.Context, names []string, opts RelayOnceOptions) (*RelayOnceSummary, error) {
+	start := time.Now()
+	if len(names) == 0 {
+		for name := range s.cfg.Paths {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxDuration)
+		defer cancel()
+	}
+
+	summary := newRelayOnceSummary(names)
+	relayEvents := eventstream.New()
+	events, unsubscribe := relayEvents.Subscribe()
+	recorded := make(chan struct{})
+	go func() {
+		defer close(recorded)
+		for e := range events {
+			summary.record(e)
+		}
+	}()
+
+	err := s.flush(ctx, names, opts.FlushOptions, StartOptions{RelayEvents: relayEvents})
+	unsubscribe()
+	<-recorded
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+
+	if ctx.Err() == nil {
+		for _, p := range summary.Paths {
+			updated, err := s.updateClientsIfNeeded(ctx, p.Path, opts.ClientUpdateThreshold)
+			if err != nil {
+				s.log.Warn("Failed to update clients", zap.String("path_name", p.Path), zap.Error(err))
+				p.Error = err.Error()
+				summary.Complete = false
+				continue
+			}
+			p.ClientsUpdated = append(p.ClientsUpdated, updated...)
+		}
+	}
+	if ctx.Err() != nil {
+		summary.Complete = false
+	}
+
+	summary.DurationSeconds = time.Since(start).Seconds()
+	return summary, nil
+}
+
+// updateClientsIfNeeded updates the clients of the path with the given name if either of them is past the
+// client update threshold or 2/3 of its trusting period, and returns the chain IDs of the updated clients.
+func (s *Service) updateClientsIfNeeded(ctx context.Context, name string, threshold time.Duration) ([]string, error) {
+	pth, chains, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if pth.IsLocalhost() {
+		return nil, nil
+	}
+	src, dst := chains[pth.Src.ChainID], chains[pth.Dst.ChainID]
+
+	needsUpdate := false
+	for _, c := range [][2]*Chain{{src, dst}, {dst, src}} {
+		updateTime, clientInfo, err := QueryClientUpdateTime(ctx, c[0], c[1])
+		if err != nil {
+			return nil, err
+		}
+		if clientNeedsUpdate(time.Since(updateTime), clientInfo.TrustingPeriod, threshold) {
+			needsUpdate = true
+		}
+	}
+	if !needsUpdate {
+		return nil, nil
+	}
+
+	if err := UpdateClients(ctx, src, dst, false, s.cfg.Memo); err != nil {
+		return nil, err
+	}
+	return []string{src.ChainID(), dst.ChainID()}, nil
+}
+
+// clientNeedsUpdate returns true if a client last updated sinceUpdate ago is past 2/3 of its trusting period,
+// or past the client update threshold if it is not zero, as the relayer updates clients when relaying continuously.
+func clientNeedsUpdate(sinceUpdate, trustingPeriod, threshold time.Duration) bool {
+	return (trustingPeriod > 0 && sinceUpdate > trustingPeriod*2/3) || (threshold > 0 && sinceUpdate > threshold)
+}
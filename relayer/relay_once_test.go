@@ -0,0 +1,63 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayOnceSummary(t *testing.T) {
+	summary := newRelayOnceSummary([]string{"demo-path"})
+	for _, e := range []eventstream.Event{
+		{Type: eventstream.TypeBroadcast, PathName: "demo-path", MsgType: "/ibc.core.channel.v1.MsgRecvPacket"},
+		{Type: eventstream.TypeConfirmed, PathName: "demo-path", MsgType: "/ibc.core.client.v1.MsgUpdateClient", TxHash: "AB"},
+		{Type: eventstream.TypeConfirmed, PathName: "demo-path", MsgType: "/ibc.core.channel.v1.MsgRecvPacket", TxHash: "AB"},
+		{Type: eventstream.TypeConfirmed, PathName: "demo-path", MsgType: "/ibc.core.channel.v1.MsgRecvPacket", TxHash: "CD"},
+		{Type: eventstream.TypeFailed, PathName: "demo-path", MsgType: "/ibc.core.channel.v1.MsgTimeout"},
+		{Type: eventstream.TypeConfirmed, PathName: "other-path", MsgType: "/ibc.core.channel.v1.MsgRecvPacket", TxHash: "EF"},
+	} {
+		summary.record(e)
+	}
+	summary.DurationSeconds = 1.5
+
+	out, err := json.Marshal(summary)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"complete": true,
+		"duration_seconds": 1.5,
+		"paths": [{
+			"path": "demo-path",
+			"relayed": {"MsgRecvPacket": 2, "MsgUpdateClient": 1},
+			"failed": {"MsgTimeout": 1},
+			"tx_hashes": ["AB", "CD"],
+			"clients_updated": []
+		}]
+	}`, string(out))
+}
+
+func TestClientNeedsUpdate(t *testing.T) {
+	day := 24 * time.Hour
+	for _, tc := range []struct {
+		sinceUpdate, trustingPeriod, threshold time.Duration
+		expected                               bool
+	}{
+		{sinceUpdate: day, trustingPeriod: 14 * day},
+		{sinceUpdate: 10 * day, trustingPeriod: 14 * day, expected: true},
+		{sinceUpdate: day, trustingPeriod: 14 * day, threshold: 12 * time.Hour, expected: true},
+		{sinceUpdate: time.Hour, trustingPeriod: 14 * day, threshold: 12 * time.Hour},
+		{sinceUpdate: 10 * day},
+	} {
+		require.Equal(t, tc.expected, clientNeedsUpdate(tc.sinceUpdate, tc.trustingPeriod, tc.threshold),
+			"since update %s, trusting period %s, threshold %s", tc.sinceUpdate, tc.trustingPeriod, tc.threshold)
+	}
+}
+
+func TestRelayOnceUnknownPath(t *testing.T) {
+	s := NewService(ServiceConfig{Chains: make(Chains), Paths: make(Paths)})
+	_, err := s.RelayOnce(context.Background(), []string{"unknown"}, RelayOnceOptions{MaxDuration: time.Second})
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"go.uber.org/zap"
+)
+
+// ErrPacketNotPending is returned by RelayPacket for a packet which has no commitment on the chain it was sent from,
+// i.e. which was already acknowledged or timed out, or was never sent.
+var ErrPacketNotPending = errors.New("packet is not pending")
+
+// RelayPacket relays whichever message the packet with the given sequence, sent from src on srcChannel, is waiting for:
+// its MsgRecvPacket to dst, or its MsgTimeout to src if it timed out, if dst has not received it,
+// otherwise the MsgAcknowledgement of the acknowledgement written by dst to src.
+func RelayPacket(
+	ctx context.Context,
+	log *zap.Logger,
+	src, dst *Chain,
+	srcChannel *chantypes.IdentifiedChannel,
+	sequence uint64,
+	maxTxSize, maxMsgLength uint64,
+	memo string,
+) error {
+	srch, dsth, err := QueryLatestHeights(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+
+	// the commitment of the packet is deleted once it is acknowledged or timed out.
+	pending, err := src.ChainProvider.QueryUnreceivedAcknowledgements(ctx, uint64(srch), srcChannel.ChannelId, srcChannel.PortId, []uint64{sequence})
+	if err != nil {
+		return fmt.Errorf("failed to query commitment of packet %d on chain %s: %w", sequence, src.ChainID(), err)
+	}
+	if len(pending) == 0 {
+		return fmt.Errorf("%w: packet %d sent on %s/%s has no commitment on chain %s, it was already acknowledged or timed out, or never sent",
+			ErrPacketNotPending, sequence, srcChannel.PortId, srcChannel.ChannelId, src.ChainID())
+	}
+
+	unrecv, err := dst.ChainProvider.QueryUnreceivedPackets(ctx, uint64(dsth), srcChannel.Counterparty.ChannelId, srcChannel.Counterparty.PortId, []uint64{sequence})
+	if err != nil {
+		return fmt.Errorf("failed to query receipt of packet %d on chain %s: %w", sequence, dst.ChainID(), err)
+	}
+
+	log = log.With(
+		zap.String("src_chain_id", src.ChainID()),
+		zap.String("src_channel_id", srcChannel.ChannelId),
+		zap.String("src_port_id", srcChannel.PortId),
+		zap.String("dst_chain_id", dst.ChainID()),
+		zap.Uint64("sequence", sequence),
+	)
+	if len(unrecv) > 0 {
+		log.Info("Relaying packet")
+		return RelayPackets(ctx, log, src, dst, RelaySequences{Src: []uint64{sequence}}, maxTxSize, maxMsgLength, memo, srcChannel)
+	}
+	log.Info("Relaying acknowledgement of packet")
+	return RelayAcknowledgements(ctx, log, src, dst, RelaySequences{Dst: []uint64{sequence}}, maxTxSize, maxMsgLength, memo, srcChannel)
+}
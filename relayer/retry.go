@@ -0,0 +1,126 @@
+package relayer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+const (
+	// DefaultHandshakeRetries is how many times each handshake message is retried unless set by the RetryPolicy.
+	DefaultHandshakeRetries = 3
+
+	// DefaultHandshakeTxTimeout is how long each handshake message is given unless set by the RetryPolicy.
+	DefaultHandshakeTxTimeout = 10 * time.Second
+)
+
+// RetryPolicy configures how transactions are retried on a path, independently of other paths.
+// Fields which are not set fall back to the defaults of the relayer.
+type RetryPolicy struct {
+	// HandshakeRetries is how many times each connection or channel handshake message is retried.
+	HandshakeRetries uint64 `yaml:"handshake-retries,omitempty" json:"handshake-retries,omitempty"`
+
+	// PacketRetries is how many times a message relayed by the event processor is retried before giving up on it.
+	PacketRetries uint64 `yaml:"packet-retries,omitempty" json:"packet-retries,omitempty"`
+
+	// HandshakeTxTimeout is how long each connection or channel handshake message is given, for every retry, e.g. 30s.
+	// It bounds how long a handshake runs, independently of TxTimeout.
+	HandshakeTxTimeout string `yaml:"handshake-tx-timeout,omitempty" json:"handshake-tx-timeout,omitempty"`
+
+	// TxTimeout is how long the event processor waits for a transaction to be included in a block before giving up
+	// on it, e.g. 30s. It applies to the broadcasts of handshakes as well, but does not bound their duration.
+	TxTimeout string `yaml:"tx-timeout,omitempty" json:"tx-timeout,omitempty"`
+
+	// Backoff is how long to wait before retrying after sending a transaction failed, e.g. 5s.
+	Backoff string `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+}
+
+// Validate checks that the durations of the RetryPolicy are valid.
+func (rp *RetryPolicy) Validate() error {
+	if rp == nil {
+		return nil
+	}
+	if _, err := parseRetryTimeout("handshake-tx-timeout", rp.HandshakeTxTimeout); err != nil {
+		return err
+	}
+	if _, err := rp.txTimeout(); err != nil {
+		return err
+	}
+	if _, err := rp.backoff(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rp *RetryPolicy) txTimeout() (time.Duration, error) {
+	if rp == nil {
+		return 0, nil
+	}
+	return parseRetryTimeout("tx-timeout", rp.TxTimeout)
+}
+
+// parseRetryTimeout parses the positive timeout named field, which is zero if not set.
+func parseRetryTimeout(field, timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %s: %w", field, timeout, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %s", field, timeout)
+	}
+	return d, nil
+}
+
+func (rp *RetryPolicy) backoff() (time.Duration, error) {
+	if rp == nil || rp.Backoff == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(rp.Backoff)
+	if err != nil {
+		return 0, fmt.Errorf("invalid backoff %s: %w", rp.Backoff, err)
+	}
+	// without a backoff, sending would be retried in a tight loop after every failure.
+	if d <= 0 {
+		return 0, fmt.Errorf("backoff must be positive, got %s", rp.Backoff)
+	}
+	return d, nil
+}
+
+// handshakeTimeout returns how long to run the event processor for a handshake of the given number of messages,
+// allowing each message the handshake tx timeout for every retry. The tx timeout of the processor is not used.
+func (rp *RetryPolicy) handshakeTimeout(msgs int) time.Duration {
+	retries := uint64(DefaultHandshakeRetries)
+	timeout := DefaultHandshakeTxTimeout
+	if rp != nil {
+		if rp.HandshakeRetries != 0 {
+			retries = rp.HandshakeRetries
+		}
+		if d, err := parseRetryTimeout("handshake-tx-timeout", rp.HandshakeTxTimeout); err == nil && d != 0 {
+			timeout = d
+		}
+	}
+	return timeout * time.Duration(msgs) * time.Duration(retries)
+}
+
+// ProcessorRetryPolicy returns the processor.MsgRetryPolicy with the fields of the RetryPolicy which are set,
+// and the defaults of the processor for the others. HandshakeRetries and HandshakeTxTimeout are not part of it.
+func (rp *RetryPolicy) ProcessorRetryPolicy() processor.MsgRetryPolicy {
+	policy := processor.DefaultMsgRetryPolicy()
+	if rp == nil {
+		return policy
+	}
+	if rp.PacketRetries != 0 {
+		policy.MaxMsgRetries = rp.PacketRetries
+	}
+	if timeout, err := rp.txTimeout(); err == nil && timeout != 0 {
+		policy.MsgSendTimeout = timeout
+	}
+	if backoff, err := rp.backoff(); err == nil && rp.Backoff != "" {
+		policy.Backoff = backoff
+	}
+	return policy
+}
@@ -0,0 +1,51 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var unset *RetryPolicy
+	require.NoError(t, unset.Validate())
+	require.Equal(t, processor.DefaultMsgRetryPolicy(), unset.ProcessorRetryPolicy())
+	require.Equal(t, 4*DefaultHandshakeRetries*DefaultHandshakeTxTimeout, unset.handshakeTimeout(4))
+
+	require.Error(t, (&RetryPolicy{HandshakeTxTimeout: "10"}).Validate())
+	require.Error(t, (&RetryPolicy{HandshakeTxTimeout: "0s"}).Validate())
+	require.Error(t, (&RetryPolicy{TxTimeout: "10"}).Validate())
+	require.Error(t, (&RetryPolicy{TxTimeout: "-1s"}).Validate())
+	require.Error(t, (&RetryPolicy{Backoff: "soon"}).Validate())
+	require.Error(t, (&RetryPolicy{Backoff: "0s"}).Validate())
+
+	rp := &RetryPolicy{
+		HandshakeRetries:   5,
+		PacketRetries:      10,
+		HandshakeTxTimeout: "20s",
+		TxTimeout:          "30s",
+		Backoff:            "1s",
+	}
+	require.NoError(t, rp.Validate())
+	require.Equal(t, processor.MsgRetryPolicy{
+		MaxMsgRetries:  10,
+		MsgSendTimeout: 30 * time.Second,
+		Backoff:        time.Second,
+	}, rp.ProcessorRetryPolicy())
+	require.Equal(t, 2*5*20*time.Second, rp.handshakeTimeout(2))
+
+	// the tx timeout of the processor does not bound handshakes, nor the handshake tx timeout broadcasts.
+	rp = &RetryPolicy{TxTimeout: "2m"}
+	require.Equal(t, 4*DefaultHandshakeRetries*DefaultHandshakeTxTimeout, rp.handshakeTimeout(4))
+	rp = &RetryPolicy{HandshakeTxTimeout: "1s"}
+	require.Equal(t, processor.DefaultMsgRetryPolicy(), rp.ProcessorRetryPolicy())
+	require.Equal(t, 4*DefaultHandshakeRetries*time.Second, rp.handshakeTimeout(4))
+
+	// unset fields fall back to the defaults.
+	rp = &RetryPolicy{PacketRetries: 1}
+	expected := processor.DefaultMsgRetryPolicy()
+	expected.MaxMsgRetries = 1
+	require.Equal(t, expected, rp.ProcessorRetryPolicy())
+}
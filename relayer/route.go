@@ -0,0 +1,213 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+)
+
+// RouteSeparator separates the chains of a route in its string representation, e.g. chain-a->chain-b->chain-c.
+const RouteSeparator = "->"
+
+// routeBalanceInterval is how often the balance of the receiver of a hop of a sequential route transfer is polled.
+const routeBalanceInterval = 2 * time.Second
+
+// Routes are multi-hop routes between chains, by name.
+type Routes map[string]*Route
+
+// Get returns the route with the given name, or else the route over the given chains, e.g. chain-a->chain-b->chain-c.
+func (r Routes) Get(name string) (*Route, error) {
+	if route, ok := r[name]; ok {
+		return route, nil
+	}
+	chains := strings.ReplaceAll(name, " ", "")
+	for _, route := range r {
+		if route.String() == chains {
+			return route, nil
+		}
+	}
+	return nil, fmt.Errorf("route with name %s does not exist", name)
+}
+
+// Route is a sequence of chains connected by paths, over which tokens are transferred from the first chain to the
+// last, and whose paths are relayed together.
+type Route struct {
+	// Chains are the names of the chains of the route, in the order tokens are transferred over them.
+	Chains []string `yaml:"chains" json:"chains"`
+
+	// Hops are the paths between each consecutive pair of chains.
+	Hops []RouteHop `yaml:"hops" json:"hops"`
+}
+
+// RouteHop is a hop of a route, over a channel of a path.
+type RouteHop struct {
+	// Path is the name of the path between the chains of the hop.
+	Path string `yaml:"path" json:"path"`
+
+	// SrcChannelID is the transfer channel of the hop on the src chain of the path, whichever direction the
+	// hop is in, as in the channel filter of the path.
+	SrcChannelID string `yaml:"src-channel-id" json:"src-channel-id"`
+}
+
+func (r *Route) String() string {
+	return strings.Join(r.Chains, RouteSeparator)
+}
+
+// Validate returns an error if the route is malformed. Whether its chains and paths exist and connect
+// is checked once the route is used, so that a route does not prevent its chains or paths from being removed.
+func (r *Route) Validate() error {
+	if len(r.Hops) == 0 {
+		return fmt.Errorf("route has no hops")
+	}
+	if len(r.Chains) != len(r.Hops)+1 {
+		return fmt.Errorf("route of %d hops must have %d chains, got %d", len(r.Hops), len(r.Hops)+1, len(r.Chains))
+	}
+	for i, hop := range r.Hops {
+		if hop.Path == "" || hop.SrcChannelID == "" {
+			return fmt.Errorf("hop %d of the route must have a path and a src-channel-id", i+1)
+		}
+	}
+	return nil
+}
+
+// paths returns the path of each hop of the route, after checking that it connects the chains of the hop.
+func (r *Route) paths(chains Chains, paths Paths) ([]*Path, error) {
+	hopPaths := make([]*Path, len(r.Hops))
+	for i, hop := range r.Hops {
+		path, err := paths.Get(hop.Path)
+		if err != nil {
+			return nil, err
+		}
+		from, ok := chains[r.Chains[i]]
+		if !ok {
+			return nil, fmt.Errorf("chain with name %s of route %s is not configured", r.Chains[i], r)
+		}
+		to, ok := chains[r.Chains[i+1]]
+		if !ok {
+			return nil, fmt.Errorf("chain with name %s of route %s is not configured", r.Chains[i+1], r)
+		}
+		if !(path.Src.ChainID == from.ChainID() && path.Dst.ChainID == to.ChainID()) &&
+			!(path.Src.ChainID == to.ChainID() && path.Dst.ChainID == from.ChainID()) {
+			return nil, fmt.Errorf("path %s of route %s does not connect chains %s and %s",
+				hop.Path, r, r.Chains[i], r.Chains[i+1])
+		}
+		hopPaths[i] = path
+	}
+	return hopPaths, nil
+}
+
+// NamedPaths returns the paths of the route to relay, each filtered to the channels of the route on it, so that
+// all the paths of the route relay its transfers alike whatever the channel filters of the paths themselves.
+func (r *Route) NamedPaths(chains Chains, paths Paths) ([]NamedPath, error) {
+	hopPaths, err := r.paths(chains, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var named []NamedPath
+	filters := make(map[string]*Path)
+	for i, hop := range r.Hops {
+		if path, ok := filters[hop.Path]; ok {
+			// the route passes over the same path more than once.
+			path.Filter.ChannelList = append(path.Filter.ChannelList, hop.SrcChannelID)
+			continue
+		}
+		path := *hopPaths[i]
+		path.Filter = ChannelFilter{
+			Rule:        processor.RuleAllowList,
+			ChannelList: []string{hop.SrcChannelID},
+		}
+		filters[hop.Path] = &path
+		named = append(named, NamedPath{Name: hop.Path, Path: &path})
+	}
+	return named, nil
+}
+
+// Channels queries the channel through which each hop of the route is sent, on the sending chain of the hop.
+func (r *Route) Channels(ctx context.Context, chains Chains, paths Paths) ([]*chantypes.IdentifiedChannel, error) {
+	hopPaths, err := r.paths(chains, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]*chantypes.IdentifiedChannel, len(r.Hops))
+	for i, hop := range r.Hops {
+		path := hopPaths[i]
+		sender := chains[r.Chains[i]]
+
+		channelID := hop.SrcChannelID
+		if path.Src.ChainID != sender.ChainID() {
+			// the hop is sent from the dst chain of the path, through the counterparty of the channel on the src chain.
+			channel, err := queryTransferChannel(ctx, chains[r.Chains[i+1]], hop.SrcChannelID, path.Src.ConnectionID)
+			if err != nil {
+				return nil, err
+			}
+			channelID = channel.Counterparty.ChannelId
+		}
+
+		channel, err := queryTransferChannel(ctx, sender, channelID, path.End(sender.ChainID()).ConnectionID)
+		if err != nil {
+			return nil, err
+		}
+		identified := chantypes.NewIdentifiedChannel(transfertypes.PortID, channelID, *channel)
+		channels[i] = &identified
+	}
+	return channels, nil
+}
+
+// queryTransferChannel queries the open transfer channel with the ID on the chain, which must be on the connection.
+func queryTransferChannel(ctx context.Context, c *Chain, channelID, connectionID string) (*chantypes.Channel, error) {
+	h, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.ChainProvider.QueryChannel(ctx, h, channelID, transfertypes.PortID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channel{%s} on chain{%s}: %w", channelID, c.ChainID(), err)
+	}
+	if res.Channel.State != chantypes.OPEN {
+		return nil, fmt.Errorf("channel{%s} on chain{%s} is not open", channelID, c.ChainID())
+	}
+	if len(res.Channel.ConnectionHops) == 0 || res.Channel.ConnectionHops[0] != connectionID {
+		return nil, fmt.Errorf("channel{%s} on chain{%s} is not on connection{%s}", channelID, c.ChainID(), connectionID)
+	}
+	return res.Channel, nil
+}
+
+// ReceivedDenom returns the full denom path on the receiving chain of a token with the full denom path sent through
+// the channel: without the prefix of the channel if the token returns to the chain it came from, or else prefixed
+// with the counterparty channel.
+func ReceivedDenom(channel *chantypes.IdentifiedChannel, fullDenomPath string) string {
+	if transfertypes.ReceiverChainIsSource(channel.PortId, channel.ChannelId, fullDenomPath) {
+		return strings.TrimPrefix(fullDenomPath, transfertypes.GetDenomPrefix(channel.PortId, channel.ChannelId))
+	}
+	return transfertypes.GetPrefixedDenom(channel.Counterparty.PortId, channel.Counterparty.ChannelId, fullDenomPath)
+}
+
+// WaitForBalance waits until the balance of addr on the chain reaches at least amount, e.g. until a transfer
+// to addr is received, up to the timeout.
+func WaitForBalance(ctx context.Context, c *Chain, addr string, amount sdk.Coin, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(routeBalanceInterval)
+	defer ticker.Stop()
+	for {
+		balance, err := c.ChainProvider.QueryBalanceWithAddress(ctx, addr)
+		if err == nil && balance.AmountOf(amount.Denom).GTE(amount.Amount) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not received by %s on chain{%s} after %s", amount, addr, c.ChainID(), timeout)
+		case <-ticker.C:
+		}
+	}
+}
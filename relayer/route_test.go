@@ -0,0 +1,76 @@
+package relayer
+
+import (
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute(t *testing.T) {
+	chains := Chains{
+		"osmosis":   mockChain("osmosis-1", "07-tendermint-0"),
+		"cosmoshub": mockChain("cosmoshub-4", "07-tendermint-1"),
+		"juno":      mockChain("juno-1", "07-tendermint-2"),
+	}
+	paths := Paths{
+		"hub-osmosis": {
+			Src:    &PathEnd{ChainID: "cosmoshub-4"},
+			Dst:    &PathEnd{ChainID: "osmosis-1"},
+			Filter: ChannelFilter{Rule: processor.RuleDenyList, ChannelList: []string{"channel-141"}},
+		},
+		"hub-juno": {
+			Src: &PathEnd{ChainID: "cosmoshub-4"},
+			Dst: &PathEnd{ChainID: "juno-1"},
+		},
+	}
+	route := &Route{
+		Chains: []string{"osmosis", "cosmoshub", "juno"},
+		Hops: []RouteHop{
+			{Path: "hub-osmosis", SrcChannelID: "channel-141"},
+			{Path: "hub-juno", SrcChannelID: "channel-207"},
+		},
+	}
+	require.NoError(t, route.Validate())
+	require.Equal(t, "osmosis->cosmoshub->juno", route.String())
+
+	routes := Routes{"demo-route": route}
+	for _, name := range []string{"demo-route", "osmosis->cosmoshub->juno", "osmosis -> cosmoshub -> juno"} {
+		got, err := routes.Get(name)
+		require.NoError(t, err)
+		require.Same(t, route, got)
+	}
+	_, err := routes.Get("osmosis->juno")
+	require.Error(t, err)
+
+	// each path is relayed on the channels of the route only, leaving the paths of the config untouched.
+	named, err := route.NamedPaths(chains, paths)
+	require.NoError(t, err)
+	require.Len(t, named, 2)
+	require.Equal(t, "hub-osmosis", named[0].Name)
+	require.Equal(t, ChannelFilter{Rule: processor.RuleAllowList, ChannelList: []string{"channel-141"}}, named[0].Path.Filter)
+	require.Equal(t, ChannelFilter{Rule: processor.RuleAllowList, ChannelList: []string{"channel-207"}}, named[1].Path.Filter)
+	require.Equal(t, processor.RuleDenyList, paths["hub-osmosis"].Filter.Rule)
+
+	// hops must be over paths between their chains.
+	route.Hops[1].Path = "hub-osmosis"
+	_, err = route.NamedPaths(chains, paths)
+	require.Error(t, err)
+
+	route.Chains = route.Chains[:2]
+	require.Error(t, route.Validate())
+}
+
+func TestReceivedDenom(t *testing.T) {
+	channel := &chantypes.IdentifiedChannel{
+		PortId:       "transfer",
+		ChannelId:    "channel-0",
+		Counterparty: chantypes.Counterparty{PortId: "transfer", ChannelId: "channel-1"},
+	}
+
+	require.Equal(t, "transfer/channel-1/uatom", ReceivedDenom(channel, "uatom"))
+	require.Equal(t, "transfer/channel-1/transfer/channel-5/uosmo", ReceivedDenom(channel, "transfer/channel-5/uosmo"))
+	// a token returning to the chain it came from is unwound.
+	require.Equal(t, "uosmo", ReceivedDenom(channel, "transfer/channel-0/uosmo"))
+}
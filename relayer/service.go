@@ -0,0 +1,399 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/processor"
+	"go.uber.org/zap"
+)
+
+// ServiceConfig configures a Service.
+type ServiceConfig struct {
+	// Log is the logger of the Service. Logging is disabled if nil.
+	Log *zap.Logger
+
+	// Chains are the chains which the Service relays between, keyed by chain ID.
+	Chains Chains
+
+	// Paths are the paths which the Service relays on, keyed by path name.
+	Paths Paths
+
+	// Memo is included in every transaction sent by the Service.
+	Memo string
+
+	// MaxReceiverSize and ICS20MemoLimit limit the size of the receiver and memo of ICS-20 packets which are relayed.
+	// Zero disables the limits.
+	MaxReceiverSize int
+	ICS20MemoLimit  int
+
+	// OnPathUpdated, if set, is called whenever the Service sets new client or connection identifiers on a path,
+	// e.g. to persist them. The path is updated in place before the call.
+	OnPathUpdated func(ctx context.Context, name string, path *Path) error
+}
+
+// Service relays between a set of chains over a set of paths. It is the entry point for embedding the relayer
+// in other Go programs, e.g. test frameworks, custodians or bots, and orchestrates chains and paths the same way
+// as the rly commands.
+type Service struct {
+	log *zap.Logger
+	cfg ServiceConfig
+}
+
+// NewService returns a Service for the chains and paths of cfg.
+func NewService(cfg ServiceConfig) *Service {
+	log := cfg.Log
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Service{
+		log: log,
+		cfg: cfg,
+	}
+}
+
+// LinkOptions configures how LinkPath creates the clients, connection and channel of a path.
+type LinkOptions struct {
+	// SrcPortID and DstPortID are the ports of the channel on the src and dst chain of the path.
+	SrcPortID, DstPortID string
+
+	// Order is the ordering of the channel, either ordered or unordered.
+	Order string
+
+	// Version is the version of the channel.
+	Version string
+
+	// SrcChannelID and DstChannelID are the channel ends of an interrupted handshake of the path to resume.
+	SrcChannelID, DstChannelID string
+
+	// Override creates new clients, connection and channel instead of reusing existing ones.
+	Override bool
+
+	// AllowUpdateAfterExpiry and AllowUpdateAfterMisbehaviour allow governance to recover the clients.
+	AllowUpdateAfterExpiry       bool
+	AllowUpdateAfterMisbehaviour bool
+
+	// ClientTrustingPeriod is the trusting period of the clients. If zero, it is derived from the unbonding period
+	// of the chains with ClientTrustingPeriodPercentage.
+	ClientTrustingPeriod           time.Duration
+	ClientTrustingPeriodPercentage int64
+
+	// MaxClockDrift is the max clock drift of the clients.
+	MaxClockDrift time.Duration
+
+	// TrustLevel is the trust level of the clients. If zero, the default trust level of 1/3 is used.
+	TrustLevel cmtmath.Fraction
+
+	// InitialBlockHistory is how many blocks to look back for handshake events when the handshake is started.
+	InitialBlockHistory uint64
+
+	// RetryPolicy is how handshake messages are retried. If nil, the retry policy of the path is used.
+	RetryPolicy *RetryPolicy
+}
+
+// DefaultLinkOptions returns the LinkOptions used by rly tx link without flags,
+// which link an unordered ICS-20 transfer channel.
+func DefaultLinkOptions() LinkOptions {
+	return LinkOptions{
+		SrcPortID:                      "transfer",
+		DstPortID:                      "transfer",
+		Order:                          "unordered",
+		Version:                        "ics20-1",
+		AllowUpdateAfterExpiry:         true,
+		AllowUpdateAfterMisbehaviour:   true,
+		ClientTrustingPeriodPercentage: 85,
+		MaxClockDrift:                  10 * time.Minute,
+	}
+}
+
+// path returns the path with the given name, with its path ends set on its chains.
+func (s *Service) path(name string) (*Path, map[string]*Chain, error) {
+	pth, err := s.cfg.Paths.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chains, err := s.cfg.Chains.Gets(pth.Src.ChainID, pth.Dst.ChainID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := EnsureKeysExist(chains); err != nil {
+		return nil, nil, err
+	}
+
+	chains[pth.Src.ChainID].PathEnd = pth.Src
+	chains[pth.Dst.ChainID].PathEnd = pth.Dst
+
+	return pth, chains, nil
+}
+
+func (s *Service) pathUpdated(ctx context.Context, name string, pth *Path) error {
+	if s.cfg.OnPathUpdated == nil {
+		return nil
+	}
+	return s.cfg.OnPathUpdated(ctx, name, pth)
+}
+
+// LinkPath creates the clients, connection and channel of the path with the given name, reusing those which
+// already exist unless overridden. The identifiers of the new clients and connection are set on the path.
+// Only the channel is created for a localhost path.
+func (s *Service) LinkPath(ctx context.Context, name string, opts LinkOptions) error {
+	pth, chains, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	src, dst := chains[pth.Src.ChainID], chains[pth.Dst.ChainID]
+
+	retryPolicy := opts.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = pth.RetryPolicy
+	}
+
+	if err := pth.ValidateLocalhost(); err != nil {
+		return err
+	}
+	// the localhost client and connection exist on every chain, only the channel is created.
+	if pth.IsLocalhost() {
+		return src.CreateOpenChannels(
+			ctx,
+			dst,
+			retryPolicy,
+			opts.SrcPortID,
+			opts.DstPortID,
+			opts.Order,
+			opts.Version,
+			opts.SrcChannelID,
+			opts.DstChannelID,
+			opts.Override,
+			s.cfg.Memo,
+			name,
+		)
+	}
+
+	clientSrc, clientDst, err := src.CreateClients(
+		ctx,
+		dst,
+		opts.AllowUpdateAfterExpiry,
+		opts.AllowUpdateAfterMisbehaviour,
+		opts.Override,
+		opts.ClientTrustingPeriod,
+		opts.MaxClockDrift,
+		opts.ClientTrustingPeriodPercentage,
+		opts.TrustLevel,
+		s.cfg.Memo,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating clients: %w", err)
+	}
+
+	if clientSrc != "" || clientDst != "" {
+		if err := s.pathUpdated(ctx, name, pth); err != nil {
+			return err
+		}
+	}
+
+	connectionSrc, connectionDst, err := src.CreateOpenConnections(
+		ctx,
+		dst,
+		retryPolicy,
+		opts.Override,
+		s.cfg.Memo,
+		opts.InitialBlockHistory,
+		name,
+	)
+	if err != nil {
+		// persist the connection ends of an interrupted handshake, so that it can be resumed.
+		if updateErr := s.pathUpdated(ctx, name, pth); updateErr != nil {
+			s.log.Warn("Failed to update path", zap.String("path_name", name), zap.Error(updateErr))
+		}
+		return fmt.Errorf("error creating connections: %w", err)
+	}
+
+	if connectionSrc != "" || connectionDst != "" {
+		if err := s.pathUpdated(ctx, name, pth); err != nil {
+			return err
+		}
+	}
+
+	return src.CreateOpenChannels(
+		ctx,
+		dst,
+		retryPolicy,
+		opts.SrcPortID,
+		opts.DstPortID,
+		opts.Order,
+		opts.Version,
+		opts.SrcChannelID,
+		opts.DstChannelID,
+		opts.Override,
+		s.cfg.Memo,
+		name,
+	)
+}
+
+// FlushOptions configures Flush.
+type FlushOptions struct {
+	// SrcChannelID limits the flush to a single channel on the src chain of the path. It requires a single path.
+	SrcChannelID string
+
+	// MaxMsgLength is the maximum number of messages per transaction. If zero, DefaultMaxMsgLength is used.
+	MaxMsgLength uint64
+
+	// StuckPacket optionally limits the flush to the packets of a block range on a chain.
+	StuckPacket *processor.StuckPacket
+}
+
+// Flush relays the packets and acknowledgements which are pending on the paths with the given names,
+// or on all paths if none are given, in both directions, and returns once they have been relayed or ctx is done.
+func (s *Service) Flush(ctx context.Context, names []string, opts FlushOptions) error {
+	return s.flush(ctx, names, opts, StartOptions{})
+}
+
+func (s *Service) flush(ctx context.Context, names []string, opts FlushOptions, startOpts StartOptions) error {
+	if len(names) == 0 {
+		for name := range s.cfg.Paths {
+			names = append(names, name)
+		}
+	}
+	if opts.SrcChannelID != "" && len(names) != 1 {
+		return errors.New("flushing a single channel requires a single path")
+	}
+	if opts.MaxMsgLength == 0 {
+		opts.MaxMsgLength = DefaultMaxMsgLength
+	}
+
+	var chainIDs []string
+	paths := make([]NamedPath, len(names))
+	for i, name := range names {
+		pth, err := s.cfg.Paths.Get(name)
+		if err != nil {
+			return err
+		}
+		if opts.SrcChannelID != "" {
+			// only relay on the given channel, without modifying the configured path.
+			filtered := *pth
+			filtered.Filter = ChannelFilter{
+				Rule:        processor.RuleAllowList,
+				ChannelList: []string{opts.SrcChannelID},
+			}
+			pth = &filtered
+		}
+		paths[i] = NamedPath{Name: name, Path: pth}
+		chainIDs = append(chainIDs, pth.Src.ChainID, pth.Dst.ChainID)
+	}
+
+	chains, err := s.cfg.Chains.Gets(chainIDs...)
+	if err != nil {
+		return err
+	}
+	if err := EnsureKeysExist(chains); err != nil {
+		return err
+	}
+
+	errCh := StartRelayer(
+		ctx,
+		s.log,
+		chains,
+		paths,
+		opts.MaxMsgLength,
+		s.cfg.MaxReceiverSize,
+		s.cfg.ICS20MemoLimit,
+		s.cfg.Memo,
+		0,
+		0,
+		&processor.FlushLifecycle{},
+		ProcessorEvents,
+		0,
+		nil,
+		opts.StuckPacket,
+		startOpts,
+	)
+
+	// Block until the relayer has stopped, which it does once the flush is complete or ctx is done.
+	if err := <-errCh; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// RelayPackets relays the packets and acknowledgements which are pending on the path with the given name,
+// in both directions, and returns once they have been relayed or ctx is done.
+func (s *Service) RelayPackets(ctx context.Context, name string) error {
+	return s.Flush(ctx, []string{name}, FlushOptions{})
+}
+
+// RelayPacket relays the packet with the given sequence, sent on the channel of the chain with chainID, or its
+// acknowledgement or timeout, whichever it is waiting for. It is relayed over the path, by name, whose end on the chain
+// is the connection of the channel, and returns once the message is sent or ctx is done.
+func (s *Service) RelayPacket(ctx context.Context, chainID, channelID, portID string, sequence uint64) error {
+	c, err := s.cfg.Chains.Get(chainID)
+	if err != nil {
+		return err
+	}
+	height, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := c.ChainProvider.QueryChannel(ctx, height, channelID, portID)
+	if err != nil {
+		return fmt.Errorf("failed to query channel %s/%s on chain %s: %w", portID, channelID, chainID, err)
+	}
+	channel := res.Channel
+	if len(channel.ConnectionHops) == 0 {
+		return fmt.Errorf("channel %s/%s on chain %s has no connection", portID, channelID, chainID)
+	}
+
+	srcChannel := &chantypes.IdentifiedChannel{
+		State:          channel.State,
+		Ordering:       channel.Ordering,
+		Counterparty:   channel.Counterparty,
+		ConnectionHops: channel.ConnectionHops,
+		Version:        channel.Version,
+		PortId:         portID,
+		ChannelId:      channelID,
+	}
+
+	names := make([]string, 0, len(s.cfg.Paths))
+	for name := range s.cfg.Paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if s.cfg.Paths[name].End(chainID).ConnectionID != channel.ConnectionHops[0] {
+			continue
+		}
+
+		pth, chains, err := s.path(name)
+		if err != nil {
+			return err
+		}
+		src, dst := chains[pth.Src.ChainID], chains[pth.Dst.ChainID]
+		filterChannel := &chantypes.IdentifiedChannel{ChannelId: channelID}
+		if pth.Dst.ChainID == chainID {
+			src, dst = dst, src
+			filterChannel.ChannelId = channel.Counterparty.ChannelId
+		}
+		if len(applyChannelFilterRule(pth.Filter, []*chantypes.IdentifiedChannel{filterChannel})) == 0 {
+			return fmt.Errorf("channel %s/%s on chain %s is filtered out of path %s", portID, channelID, chainID, name)
+		}
+
+		return RelayPacket(ctx, s.log, src, dst, srcChannel, sequence, TwoMB, DefaultMaxMsgLength, s.cfg.Memo)
+	}
+	return fmt.Errorf("no path relays connection %s of channel %s/%s on chain %s",
+		channel.ConnectionHops[0], portID, channelID, chainID)
+}
+
+// EnsureKeysExist returns an error if the configured key of any of the chains does not exist.
+func EnsureKeysExist(chains map[string]*Chain) error {
+	for _, c := range chains {
+		if exists := c.ChainProvider.KeyExists(c.ChainProvider.Key()); !exists {
+			return fmt.Errorf("key %s not found on chain %s", c.ChainProvider.Key(), c.ChainID())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServiceUnknownPath(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(ServiceConfig{
+		Chains: make(Chains),
+		Paths: Paths{
+			"a": &Path{Src: &PathEnd{ChainID: "chain-a"}, Dst: &PathEnd{ChainID: "chain-b"}},
+			"b": &Path{Src: &PathEnd{ChainID: "chain-b"}, Dst: &PathEnd{ChainID: "chain-c"}},
+		},
+	})
+
+	require.Error(t, s.LinkPath(ctx, "unknown", DefaultLinkOptions()))
+	require.Error(t, s.Flush(ctx, []string{"unknown"}, FlushOptions{}))
+
+	// a single channel can only be flushed on a single path.
+	require.Error(t, s.Flush(ctx, nil, FlushOptions{SrcChannelID: "channel-0"}))
+
+	// the chains of a known path must be configured.
+	require.Error(t, s.RelayPackets(ctx, "a"))
+}
+
+func TestServiceLinkPathAndFlush(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	src := newMemoryChain(ctx, t, "chain-a")
+	dst := newMemoryChain(ctx, t, "chain-b")
+	require.NoError(t, src.ChainProvider.WaitForNBlocks(ctx, 1))
+	require.NoError(t, dst.ChainProvider.WaitForNBlocks(ctx, 1))
+
+	pth := &Path{Src: &PathEnd{ChainID: "chain-a"}, Dst: &PathEnd{ChainID: "chain-b"}}
+	var updates int
+	s := NewService(ServiceConfig{
+		Log:    zaptest.NewLogger(t),
+		Chains: Chains{src.ChainID(): src, dst.ChainID(): dst},
+		Paths:  Paths{"memory": pth},
+		OnPathUpdated: func(_ context.Context, name string, updated *Path) error {
+			require.Equal(t, "memory", name)
+			require.Same(t, pth, updated)
+			updates++
+			return nil
+		},
+	})
+
+	require.NoError(t, s.LinkPath(ctx, "memory", DefaultLinkOptions()))
+
+	// the identifiers of the new clients and connection are set on the path and reported.
+	require.NotEmpty(t, pth.Src.ClientID)
+	require.NotEmpty(t, pth.Dst.ClientID)
+	require.NotEmpty(t, pth.Src.ConnectionID)
+	require.NotEmpty(t, pth.Dst.ConnectionID)
+	require.Equal(t, 2, updates)
+
+	channels, err := src.ChainProvider.QueryConnectionChannels(ctx, 0, pth.Src.ConnectionID)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	channel := channels[0]
+	require.Equal(t, chantypes.OPEN, channel.State)
+	require.Equal(t, "transfer", channel.PortId)
+	require.Equal(t, "ics20-1", channel.Version)
+
+	receiver, err := dst.ChainProvider.Address()
+	require.NoError(t, err)
+	transfer, err := src.ChainProvider.MsgTransfer(receiver, sdk.NewInt64Coin("stake", 100), "", provider.PacketInfo{
+		SourcePort:    channel.PortId,
+		SourceChannel: channel.ChannelId,
+		TimeoutHeight: clienttypes.NewHeight(0, 1_000_000),
+	})
+	require.NoError(t, err)
+	_, success, err := src.ChainProvider.SendMessage(ctx, transfer, "")
+	require.NoError(t, err)
+	require.True(t, success)
+
+	// the flush returns once the packet is received on dst and acknowledged on src.
+	require.NoError(t, s.Flush(ctx, []string{"memory"}, FlushOptions{SrcChannelID: channel.ChannelId}))
+
+	unreceived, err := dst.ChainProvider.QueryUnreceivedPackets(ctx, 0, channel.Counterparty.ChannelId, channel.Counterparty.PortId, []uint64{1})
+	require.NoError(t, err)
+	require.Empty(t, unreceived)
+
+	res, err := src.ChainProvider.QueryPacketCommitments(ctx, 0, channel.ChannelId, channel.PortId)
+	require.NoError(t, err)
+	require.Empty(t, res.Commitments)
+}
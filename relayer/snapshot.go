@@ -0,0 +1,114 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// snapshotConcurrency is how many channels have their packet state queried at once for a snapshot.
+const snapshotConcurrency = 8
+
+// IBCSnapshot is the IBC state of a chain, for debugging, audits and offline analysis of relay backlogs.
+// Clients, connections and channels are encoded with the codec of the chain.
+type IBCSnapshot struct {
+	ChainID     string            `json:"chain_id"`
+	Height      int64             `json:"height"`
+	Clients     []json.RawMessage `json:"clients"`
+	Connections []json.RawMessage `json:"connections"`
+	Channels    []ChannelSnapshot `json:"channels"`
+}
+
+// ChannelSnapshot is a channel end along with its packet state.
+type ChannelSnapshot struct {
+	Channel json.RawMessage `json:"channel"`
+
+	// PendingCommitments are the commitments of packets sent on the channel which have not been acknowledged or timed out.
+	PendingCommitments []*chantypes.PacketState `json:"pending_commitments"`
+
+	// Acknowledgements are the acknowledgements written for packets received on the channel.
+	Acknowledgements []*chantypes.PacketState `json:"acknowledgements"`
+}
+
+// QueryIBCSnapshot queries all clients, connections and channels on c, along with the pending packet commitments
+// and acknowledgements of every channel. Packet state is queried at the height of the snapshot.
+func QueryIBCSnapshot(ctx context.Context, c *Chain) (*IBCSnapshot, error) {
+	height, err := c.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest height on chain{%s}: %w", c.ChainID(), err)
+	}
+
+	snapshot := &IBCSnapshot{
+		ChainID: c.ChainID(),
+		Height:  height,
+	}
+
+	clients, err := c.ChainProvider.QueryClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clients on chain{%s}: %w", c.ChainID(), err)
+	}
+	snapshot.Clients = make([]json.RawMessage, len(clients))
+	for i := range clients {
+		s, err := c.ChainProvider.Sprint(&clients[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal client{%s}: %w", clients[i].ClientId, err)
+		}
+		snapshot.Clients[i] = json.RawMessage(s)
+	}
+
+	connections, err := c.ChainProvider.QueryConnections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connections on chain{%s}: %w", c.ChainID(), err)
+	}
+	snapshot.Connections = make([]json.RawMessage, len(connections))
+	for i, connection := range connections {
+		s, err := c.ChainProvider.Sprint(connection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal connection{%s}: %w", connection.Id, err)
+		}
+		snapshot.Connections[i] = json.RawMessage(s)
+	}
+
+	channels, err := c.ChainProvider.QueryChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels on chain{%s}: %w", c.ChainID(), err)
+	}
+	snapshot.Channels = make([]ChannelSnapshot, len(channels))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(snapshotConcurrency)
+	for i, channel := range channels {
+		i, channel := i, channel
+		eg.Go(func() error {
+			s, err := c.ChainProvider.Sprint(channel)
+			if err != nil {
+				return fmt.Errorf("failed to marshal channel{%s}: %w", channel.ChannelId, err)
+			}
+
+			commitments, err := c.ChainProvider.QueryPacketCommitments(egCtx, uint64(height), channel.ChannelId, channel.PortId)
+			if err != nil {
+				return fmt.Errorf("failed to query packet commitments for channel{%s} port{%s}: %w", channel.ChannelId, channel.PortId, err)
+			}
+
+			acks, err := c.ChainProvider.QueryPacketAcknowledgements(egCtx, uint64(height), channel.ChannelId, channel.PortId)
+			if err != nil {
+				return fmt.Errorf("failed to query packet acknowledgements for channel{%s} port{%s}: %w", channel.ChannelId, channel.PortId, err)
+			}
+
+			snapshot.Channels[i] = ChannelSnapshot{
+				Channel:            json.RawMessage(s),
+				PendingCommitments: commitments.Commitments,
+				Acknowledgements:   acks,
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
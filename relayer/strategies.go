@@ -12,7 +12,10 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"github.com/cosmos/relayer/v2/relayer/chains/memory"
 	penumbraprocessor "github.com/cosmos/relayer/v2/relayer/chains/penumbra"
+	"github.com/cosmos/relayer/v2/relayer/eventstream"
+	"github.com/cosmos/relayer/v2/relayer/notify"
 	"github.com/cosmos/relayer/v2/relayer/processor"
 	"go.uber.org/zap"
 )
@@ -32,6 +35,44 @@ const (
 	TwoMB                               = 2 * 1024 * 1024
 )
 
+// StartOptions are the optional settings of StartRelayer. The zero value relays packets as they are observed,
+// without any of the features below.
+type StartOptions struct {
+	// StartHeights are the heights to start querying blocks from, by chain ID, rather than the latest height.
+	StartHeights map[string]uint64
+
+	// MonitorOnly observes pending messages without sending any tx.
+	MonitorOnly bool
+
+	// SkipRelayedPackets checks packet state on the destination immediately before broadcast.
+	SkipRelayedPackets bool
+
+	// ForceBisection catches up clients which fell too far behind to be updated in one update
+	// through intermediate heights.
+	ForceBisection bool
+
+	// SameBlockAcks relays acknowledgements as soon as they can be proven.
+	SameBlockAcks bool
+
+	// TxsPerBlock limits how many txs are broadcast to each chain per block, if positive.
+	TxsPerBlock int
+
+	// ProcessedEvents, if set, deduplicates packets relayed by other instances.
+	ProcessedEvents *processor.ProcessedEvents
+
+	// Coordinator, if set, exchanges claims of packets with cooperating relayers to avoid duplicate txs.
+	Coordinator processor.Coordinator
+
+	// Notifier, if set, is notified of relay failures.
+	Notifier *notify.Notifier
+
+	// RelayEvents, if set, streams relay lifecycle events.
+	RelayEvents *eventstream.Stream
+
+	// Control, if set, is given access to the chains and path processors.
+	Control *ControlAPI
+}
+
 // StartRelayer starts the main relaying loop and returns a channel that will contain any control-flow related errors.
 func StartRelayer(
 	ctx context.Context,
@@ -49,17 +90,25 @@ func StartRelayer(
 	initialBlockHistory uint64,
 	metrics *processor.PrometheusMetrics,
 	stuckPacket *processor.StuckPacket,
+	opts StartOptions,
 ) chan error {
 	// prevent incorrect bech32 address prefixed addresses when calling AccAddress.String()
 	sdk.SetAddrCacheEnabled(false)
 	errorChan := make(chan error, 1)
 
+	if opts.Control != nil {
+		opts.Control.addChains(chains)
+		opts.Control.setRelayEvents(opts.RelayEvents)
+	}
+
 	switch processorType {
 	case ProcessorEvents:
 		chainProcessors := make([]processor.ChainProcessor, 0, len(chains))
+		auditProofHeights := false
 
 		for _, chain := range chains {
 			chainProcessors = append(chainProcessors, chain.chainProcessor(log, metrics))
+			auditProofHeights = auditProofHeights || proofHeightAudit(chain)
 		}
 
 		ePaths := make([]path, len(paths))
@@ -68,29 +117,28 @@ func StartRelayer(
 			p := np.Path
 
 			filter := p.Filter
-			var filterSrc, filterDst []processor.ChainChannelKey
-
-			for _, ch := range filter.ChannelList {
-				ruleSrc := processor.ChainChannelKey{
-					ChainID: p.Src.ChainID,
-					ChannelKey: processor.ChannelKey{
-						ChannelID: ch,
-					},
-				}
-
-				ruleDst := processor.ChainChannelKey{
-					CounterpartyChainID: p.Src.ChainID,
-					ChannelKey: processor.ChannelKey{
-						CounterpartyChannelID: ch,
-					},
-				}
-
-				filterSrc = append(filterSrc, ruleSrc)
-				filterDst = append(filterDst, ruleDst)
-			}
+			filterSrc, filterDst := srcChannelKeys(p.Src.ChainID, filter.ChannelList)
+			prioritySrc, priorityDst := srcChannelKeys(p.Src.ChainID, p.PriorityChannels)
+
 			ePaths[i] = path{
-				src: processor.NewPathEnd(pathName, p.Src.ChainID, p.Src.ClientID, filter.Rule, filterSrc),
-				dst: processor.NewPathEnd(pathName, p.Dst.ChainID, p.Dst.ClientID, filter.Rule, filterDst),
+				src: processor.NewPathEnd(pathName, p.Src.ChainID, p.Src.ClientID, filter.Rule, filterSrc).
+					WithConnectionHops(p.Src.ConnectionHops).
+					WithPriorityChannels(prioritySrc).
+					WithClientMessages(p.Src.processorClientMessages()),
+				dst: processor.NewPathEnd(pathName, p.Dst.ChainID, p.Dst.ClientID, filter.Rule, filterDst).
+					WithConnectionHops(p.Dst.ConnectionHops).
+					WithPriorityChannels(priorityDst).
+					WithClientMessages(p.Dst.processorClientMessages()),
+
+				retryPolicy:  p.RetryPolicy.ProcessorRetryPolicy(),
+				concurrency:  p.Concurrency.ProcessorConcurrency(),
+				denomPolicy:  p.DenomPolicy.ProcessorDenomPolicy(),
+				memoFilter:   p.MemoFilter.ProcessorMemoFilter(),
+				verifyProofs: proofVerification(chains[p.Src.ChainID], chains[p.Dst.ChainID]),
+				memoLimit:    memoLimit,
+			}
+			if p.ICS20MemoLimit != nil {
+				ePaths[i].memoLimit = *p.ICS20MemoLimit
 			}
 		}
 
@@ -102,7 +150,6 @@ func StartRelayer(
 			initialBlockHistory,
 			maxMsgLength,
 			maxReceiverSize,
-			memoLimit,
 			memo,
 			messageLifecycle,
 			clientUpdateThresholdTime,
@@ -110,6 +157,8 @@ func StartRelayer(
 			errorChan,
 			metrics,
 			stuckPacket,
+			auditProofHeights,
+			opts,
 		)
 		return errorChan
 	case ProcessorLegacy:
@@ -127,11 +176,41 @@ func StartRelayer(
 	}
 }
 
+// srcChannelKeys returns the keys which match channels on the src chain of a path, from the perspective
+// of the src and dst path ends.
+func srcChannelKeys(srcChainID string, channels []string) (src, dst []processor.ChainChannelKey) {
+	for _, ch := range channels {
+		src = append(src, processor.ChainChannelKey{
+			ChainID: srcChainID,
+			ChannelKey: processor.ChannelKey{
+				ChannelID: ch,
+			},
+		})
+
+		dst = append(dst, processor.ChainChannelKey{
+			CounterpartyChainID: srcChainID,
+			ChannelKey: processor.ChannelKey{
+				CounterpartyChannelID: ch,
+			},
+		})
+	}
+	return src, dst
+}
+
 // TODO: intermediate types. Should combine/replace with the relayer.Chain, relayer.Path, and relayer.PathEnd structs
 // as the stateless and stateful/event-based relaying mechanisms are consolidated.
 type path struct {
 	src processor.PathEnd
 	dst processor.PathEnd
+
+	retryPolicy processor.MsgRetryPolicy
+	concurrency processor.Concurrency
+	denomPolicy processor.DenomPolicy
+	memoFilter  processor.MemoFilter
+	memoLimit   int
+
+	// if true, proofs are verified before the messages which carry them are sent.
+	verifyProofs bool
 }
 
 // chainProcessor returns the corresponding ChainProcessor implementation instance for a pathChain.
@@ -143,6 +222,8 @@ func (c *Chain) chainProcessor(
 	switch p := c.ChainProvider.(type) {
 	case *penumbraprocessor.PenumbraProvider:
 		return penumbraprocessor.NewPenumbraChainProcessor(log, p)
+	case *memory.Provider:
+		return memory.NewChainProcessor(log, p)
 	case *cosmos.CosmosProvider:
 		return cosmos.NewCosmosChainProcessor(log, p, metrics)
 	default:
@@ -158,8 +239,7 @@ func relayerStartEventProcessor(
 	paths []path,
 	initialBlockHistory uint64,
 	maxMsgLength uint64,
-	maxReceiverSize,
-	memoLimit int,
+	maxReceiverSize int,
 	memo string,
 	messageLifecycle processor.MessageLifecycle,
 	clientUpdateThresholdTime time.Duration,
@@ -167,27 +247,48 @@ func relayerStartEventProcessor(
 	errCh chan<- error,
 	metrics *processor.PrometheusMetrics,
 	stuckPacket *processor.StuckPacket,
+	auditProofHeights bool,
+	opts StartOptions,
 ) {
 	defer close(errCh)
 
 	epb := processor.NewEventProcessor().
 		WithChainProcessors(chainProcessors...).
-		WithStuckPacket(stuckPacket)
+		WithStuckPacket(stuckPacket).
+		WithStartHeights(opts.StartHeights).
+		WithProofHeightAudit(auditProofHeights).
+		WithMonitorOnly(opts.MonitorOnly).
+		WithSkipRelayedPackets(opts.SkipRelayedPackets).
+		WithForceBisection(opts.ForceBisection).
+		WithSameBlockAcks(opts.SameBlockAcks).
+		WithTxsPerBlock(opts.TxsPerBlock).
+		WithProcessedEvents(opts.ProcessedEvents).
+		WithCoordinator(opts.Coordinator).
+		WithNotifier(opts.Notifier).
+		WithRelayEvents(opts.RelayEvents)
 
 	for _, p := range paths {
-		epb = epb.
-			WithPathProcessors(processor.NewPathProcessor(
-				log,
-				p.src,
-				p.dst,
-				metrics,
-				memo,
-				clientUpdateThresholdTime,
-				flushInterval,
-				maxMsgLength,
-				memoLimit,
-				maxReceiverSize,
-			))
+		pp := processor.NewPathProcessor(
+			log,
+			p.src,
+			p.dst,
+			metrics,
+			memo,
+			clientUpdateThresholdTime,
+			flushInterval,
+			maxMsgLength,
+			p.memoLimit,
+			maxReceiverSize,
+		)
+		pp.SetMsgRetryPolicy(p.retryPolicy)
+		pp.SetConcurrency(p.concurrency)
+		pp.SetDenomPolicy(p.denomPolicy)
+		pp.SetMemoFilter(p.memoFilter)
+		pp.SetProofVerification(p.verifyProofs)
+		if opts.Control != nil {
+			opts.Control.addPathProcessor(pp)
+		}
+		epb = epb.WithPathProcessors(pp)
 	}
 
 	if messageLifecycle != nil {
@@ -0,0 +1,32 @@
+package relayer
+
+import (
+	"fmt"
+	"strings"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+)
+
+// UnwindPath returns the transfers needed to return a token with the given denom trace to its origin chain,
+// in the order they must be made, starting from the chain holding the token.
+func UnwindPath(trace transfertypes.DenomTrace) ([]TransferHop, error) {
+	if trace.Path == "" {
+		return nil, fmt.Errorf("denom %s is native to the chain, there is nothing to unwind", trace.BaseDenom)
+	}
+
+	parts := strings.Split(trace.Path, "/")
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("invalid denom trace path %s", trace.Path)
+	}
+
+	// each port/channel pair in the path identifies the channel on which the token was received,
+	// with the most recent hop first, so it is also the channel to send it back through.
+	hops := make([]TransferHop, 0, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		hops = append(hops, TransferHop{
+			PortID:    parts[i],
+			ChannelID: parts[i+1],
+		})
+	}
+	return hops, nil
+}
@@ -0,0 +1,24 @@
+package relayer_test
+
+import (
+	"testing"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnwindPath(t *testing.T) {
+	_, err := relayer.UnwindPath(transfertypes.ParseDenomTrace("uatom"))
+	require.Error(t, err)
+
+	_, err = relayer.UnwindPath(transfertypes.DenomTrace{Path: "transfer/channel-0/transfer", BaseDenom: "uatom"})
+	require.Error(t, err)
+
+	hops, err := relayer.UnwindPath(transfertypes.ParseDenomTrace("transfer/channel-1/transfer/channel-0/uatom"))
+	require.NoError(t, err)
+	require.Equal(t, []relayer.TransferHop{
+		{PortID: "transfer", ChannelID: "channel-1"},
+		{PortID: "transfer", ChannelID: "channel-0"},
+	}, hops)
+}